@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/extractor"
+	"github.com/ioanzicu/asana-extractor/pkg/storage"
+)
+
+// Page sizes of the query API's list endpoints
+const (
+	defaultQueryLimit = 100
+	maxQueryLimit     = 1000
+)
+
+// queryReader is the storage the query API reads from; implemented by
+// *storage.JSONStorage
+type queryReader interface {
+	storage.Reader
+	ReadManifest(v any) error
+	Incomplete() bool
+}
+
+// queryPage is the body of a list answer. As in Asana's API, next_page
+// holds the offset of the following page and is null on the last one.
+type queryPage struct {
+	Data     []any          `json:"data"`
+	NextPage *queryNextPage `json:"next_page"`
+}
+
+type queryNextPage struct {
+	Offset string `json:"offset"`
+}
+
+// runAPI serves read-only query endpoints over the latest extracted data
+// until ctx is cancelled
+func runAPI(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("api")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(cfgFlags)
+	if err != nil {
+		return err
+	}
+	if cfg.StorageBackend != "json" {
+		return fmt.Errorf("the query API requires STORAGE_BACKEND=json (got %q)", cfg.StorageBackend)
+	}
+
+	opts, err := storageOptions(cfg)
+	if err != nil {
+		return err
+	}
+
+	// In snapshot mode the latest link moves after every run; opening the
+	// storage per request follows it
+	dir := cfg.OutputDirectory
+	if cfg.SnapshotsEnabled {
+		dir = filepath.Join(dir, storage.LatestLink)
+	}
+	open := func() (queryReader, error) {
+		return storage.OpenJSONStorage(dir, opts)
+	}
+
+	listener, err := net.Listen("tcp", cfg.APIAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", cfg.APIAddr, err)
+	}
+	server := &http.Server{Handler: queryHandler(open), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Failed to stop query API: %v", err)
+		}
+	}()
+
+	log.Printf("Query API available on http://%s", listener.Addr())
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("query API failed: %w", err)
+	}
+	return nil
+}
+
+// queryHandler serves GET /<resource>, GET /<resource>/<gid> and
+// GET /runs/latest from the storage returned by open
+func queryHandler(open func() (queryReader, error)) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /runs/latest", func(w http.ResponseWriter, r *http.Request) {
+		store, ok := openStore(w, open)
+		if !ok {
+			return
+		}
+		var manifest extractor.Manifest
+		if err := store.ReadManifest(&manifest); err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		writeQueryJSON(w, map[string]any{"data": manifest, "incomplete": store.Incomplete()})
+	})
+
+	mux.HandleFunc("GET /{resource}", func(w http.ResponseWriter, r *http.Request) {
+		resource := r.PathValue("resource")
+		store, ok := openStore(w, open)
+		if !ok {
+			return
+		}
+		read, ok := entityReader(store, resource)
+		if !ok {
+			http.Error(w, "unknown resource", http.StatusNotFound)
+			return
+		}
+
+		limit := defaultQueryLimit
+		if s := r.URL.Query().Get("limit"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 || n > maxQueryLimit {
+				http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxQueryLimit), http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+
+		page, err := listEntities(store, resource, read, r.URL.Query().Get("offset"), limit)
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		writeQueryJSON(w, page)
+	})
+
+	mux.HandleFunc("GET /{resource}/{gid}", func(w http.ResponseWriter, r *http.Request) {
+		store, ok := openStore(w, open)
+		if !ok {
+			return
+		}
+		read, ok := entityReader(store, r.PathValue("resource"))
+		if !ok {
+			http.Error(w, "unknown resource", http.StatusNotFound)
+			return
+		}
+		entity, err := read(r.PathValue("gid"))
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		writeQueryJSON(w, map[string]any{"data": entity})
+	})
+
+	return mux
+}
+
+// openStore opens the storage, answering 503 while there is no output yet
+func openStore(w http.ResponseWriter, open func() (queryReader, error)) (queryReader, bool) {
+	store, err := open()
+	if err != nil {
+		log.Printf("Query API cannot open the output: %v", err)
+		http.Error(w, "no extracted data available yet", http.StatusServiceUnavailable)
+		return nil, false
+	}
+	return store, true
+}
+
+// entityReader returns the read function of a resource
+func entityReader(store queryReader, resource string) (func(gid string) (any, error), bool) {
+	switch resource {
+	case extractor.ResourceUsers:
+		return func(gid string) (any, error) { return store.ReadUser(gid) }, true
+	case extractor.ResourceProjects:
+		return func(gid string) (any, error) { return store.ReadProject(gid) }, true
+	case extractor.ResourceTasks:
+		return func(gid string) (any, error) { return store.ReadTask(gid) }, true
+	case extractor.ResourceTeams:
+		return func(gid string) (any, error) { return store.ReadTeam(gid) }, true
+	}
+	return nil, false
+}
+
+// listEntities returns up to limit entities in GID order, starting after
+// the offset GID and skipping tombstones
+func listEntities(store queryReader, resource string, read func(string) (any, error), offset string, limit int) (queryPage, error) {
+	page := queryPage{Data: []any{}}
+
+	gids, err := store.ListGIDs(resource)
+	if err != nil {
+		return page, err
+	}
+	var last string
+	for _, gid := range gids {
+		if gid <= offset {
+			continue
+		}
+		if len(page.Data) == limit {
+			page.NextPage = &queryNextPage{Offset: last}
+			break
+		}
+		entity, err := read(gid)
+		if errors.Is(err, storage.ErrDeleted) {
+			continue
+		}
+		if err != nil {
+			return page, err
+		}
+		page.Data = append(page.Data, entity)
+		last = gid
+	}
+	return page, nil
+}
+
+// writeQueryJSON writes v as a JSON answer
+func writeQueryJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeQueryError maps storage errors to status codes: 404 for unknown
+// entities, 410 for deleted ones and 500 otherwise
+func writeQueryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, storage.ErrDeleted):
+		http.Error(w, "deleted", http.StatusGone)
+	default:
+		log.Printf("Query API error: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+	"github.com/ioanzicu/asana-extractor/pkg/storage"
+)
+
+func TestQueryHandler(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, gid := range []string{"u1", "u2", "u3", "u4"} {
+		store.WriteUser(asana.User{GID: gid, Name: "User " + gid})
+	}
+	store.WriteProject(asana.Project{GID: "p1", Name: "Roadmap"})
+	store.Reconcile("users", map[string]struct{}{"u1": {}, "u2": {}, "u4": {}}, true)
+	store.WriteManifest(map[string]any{"run_id": "r1", "status": "succeeded"})
+
+	open := func() (queryReader, error) { return storage.OpenJSONStorage(dir, storage.Options{}) }
+	handler := queryHandler(open)
+
+	tests := []struct {
+		name         string
+		path         string
+		expectStatus int
+		expectBody   string
+	}{
+		{name: "List", path: "/users", expectStatus: http.StatusOK, expectBody: `"gid":"u4"`},
+		{name: "First page", path: "/users?limit=2", expectStatus: http.StatusOK, expectBody: `"next_page":{"offset":"u2"}`},
+		{name: "Last page skips tombstones", path: "/users?limit=2&offset=u2", expectStatus: http.StatusOK, expectBody: `"next_page":null`},
+		{name: "Bad limit", path: "/users?limit=0", expectStatus: http.StatusBadRequest},
+		{name: "Entity", path: "/projects/p1", expectStatus: http.StatusOK, expectBody: `{"data":{"gid":"p1"`},
+		{name: "Missing entity", path: "/projects/p2", expectStatus: http.StatusNotFound},
+		{name: "Deleted entity", path: "/users/u3", expectStatus: http.StatusGone},
+		{name: "Unknown resource", path: "/goals", expectStatus: http.StatusNotFound},
+		{name: "Latest run", path: "/runs/latest", expectStatus: http.StatusOK, expectBody: `"run_id":"r1"`},
+		{name: "Latest run is complete", path: "/runs/latest", expectStatus: http.StatusOK, expectBody: `"incomplete":false`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.expectStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectStatus, rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectBody) {
+				t.Errorf("expected body to contain %s, got %s", tt.expectBody, rec.Body.String())
+			}
+		})
+	}
+
+	t.Run("Pages cover every live entity once", func(t *testing.T) {
+		var gids []string
+		offset := ""
+		for {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users?limit=1&offset="+offset, nil))
+			var page struct {
+				Data     []asana.User   `json:"data"`
+				NextPage *queryNextPage `json:"next_page"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+				t.Fatal(err)
+			}
+			for _, u := range page.Data {
+				gids = append(gids, u.GID)
+			}
+			if page.NextPage == nil {
+				break
+			}
+			offset = page.NextPage.Offset
+		}
+		if strings.Join(gids, ",") != "u1,u2,u4" {
+			t.Errorf("expected u1,u2,u4, got %v", gids)
+		}
+	})
+
+	t.Run("No output yet", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		failing := func() (queryReader, error) { return nil, errors.New("no such directory") }
+		queryHandler(failing).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", rec.Code)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ioanzicu/asana-extractor/pkg/storage"
+)
+
+// runVerifyAttachments hashes every stored attachment file again and fails
+// if any is missing or does not match its hash
+func runVerifyAttachments(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("verify-attachments")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(cfgFlags)
+	if err != nil {
+		return err
+	}
+	if cfg.StorageBackend != "json" {
+		return fmt.Errorf("attachments require STORAGE_BACKEND=json (got %q)", cfg.StorageBackend)
+	}
+
+	opts, err := storageOptions(cfg)
+	if err != nil {
+		return err
+	}
+	dir := cfg.OutputDirectory
+	if cfg.SnapshotsEnabled {
+		dir = filepath.Join(dir, storage.LatestLink)
+	}
+	s, err := storage.OpenJSONStorage(dir, opts)
+	if err != nil {
+		return err
+	}
+
+	report, err := s.VerifyAttachments()
+	if err != nil {
+		return err
+	}
+	for _, gid := range report.Missing {
+		fmt.Fprintf(stdout, "missing\t%s\n", gid)
+	}
+	for _, gid := range report.Corrupt {
+		fmt.Fprintf(stdout, "corrupt\t%s\n", gid)
+	}
+	fmt.Fprintf(stdout, "%d attachments in %d files checked\n", report.Attachments, report.Blobs)
+	if !report.OK() {
+		return fmt.Errorf("%d attachments missing, %d corrupt", len(report.Missing), len(report.Corrupt))
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+	"github.com/ioanzicu/asana-extractor/pkg/storage"
+)
+
+func TestRunVerifyAttachments(t *testing.T) {
+	dir := t.TempDir()
+	s, err := storage.NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.WriteAttachment(asana.Attachment{GID: "a1"}, strings.NewReader("one"))
+	s.WriteAttachment(asana.Attachment{GID: "a2"}, strings.NewReader("two"))
+	if err := s.WriteManifest(map[string]any{"run_id": "r1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	stdout = &out
+	defer func() { stdout = os.Stdout }()
+
+	args := []string{"--token", "x", "--workspace", "ws", "--output-dir", dir}
+	if err := runVerifyAttachments(context.Background(), args); err != nil {
+		t.Fatalf("runVerifyAttachments() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "2 attachments in 2 files checked") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	refs, _ := s.ReadAttachmentRefs()
+	hash := refs["a2"].SHA256
+	os.WriteFile(dir+"/attachments/blobs/"+hash[:2]+"/"+hash, []byte("tampered"), 0644)
+	out.Reset()
+	if err := runVerifyAttachments(context.Background(), args); err == nil {
+		t.Error("expected an error for a corrupt attachment")
+	}
+	if !strings.Contains(out.String(), "corrupt\ta2") {
+		t.Errorf("expected a2 to be reported corrupt, got %q", out.String())
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+	"github.com/ioanzicu/asana-extractor/pkg/extractor"
+	"github.com/ioanzicu/asana-extractor/pkg/storage"
+)
+
+// benchGIDBase keeps synthetic GIDs clear of real ones, which are far
+// shorter
+const benchGIDBase = 9_000_000_000_000_000
+
+// benchOptions describes the synthetic load of a storage benchmark
+type benchOptions struct {
+	Tasks       int
+	NotesBytes  int
+	BatchSize   int
+	Concurrency int
+}
+
+// benchResult is the outcome of a storage benchmark
+type benchResult struct {
+	Tasks    int
+	Duration time.Duration
+	// Latencies holds the duration of every write call, one task or one
+	// batch each
+	Latencies []time.Duration
+	Batched   bool
+	// Bytes is what the backend stored, or -1 when it cannot be measured
+	Bytes int64
+}
+
+// runBenchStorage writes synthetic tasks through the configured storage
+// backend and reports its throughput, latency and footprint
+func runBenchStorage(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("bench-storage")
+	tasks := flags.Int("tasks", 10000, "number of synthetic tasks to write")
+	notesBytes := flags.Int("notes-bytes", 1000, "size of each synthetic task's notes")
+	planTasks := flags.Int("plan-tasks", 0, "project the disk usage and write time of this many tasks")
+	dir := flags.String("dir", "", "directory for file backends (default: a temporary directory, removed afterwards)")
+	if err := flags.Parse(args); err != nil {
+		return withExitCode(exitConfig, err)
+	}
+	if *tasks < 1 {
+		return withExitCode(exitConfig, fmt.Errorf("--tasks must be at least 1 (got %d)", *tasks))
+	}
+
+	cfg, err := loadConfig(cfgFlags)
+	if err != nil {
+		return err
+	}
+	if cfg.StorageBackend == "singer" {
+		return fmt.Errorf("the singer backend writes to stdout and cannot be benchmarked")
+	}
+	opts, err := storageOptions(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Synthetic tasks never go to the real output directory
+	benchDir := *dir
+	if benchDir == "" {
+		if benchDir, err = os.MkdirTemp("", "asana-bench-"); err != nil {
+			return fmt.Errorf("failed to create benchmark directory: %w", err)
+		}
+		defer os.RemoveAll(benchDir)
+	}
+	backend, err := storage.Open(cfg.StorageBackend, storage.Settings{Dir: benchDir, Options: opts, Params: cfg.StorageParams})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Writing %d synthetic tasks through the %s backend", *tasks, cfg.StorageBackend)
+	result, err := benchStorage(ctx, backend, benchOptions{
+		Tasks:       *tasks,
+		NotesBytes:  *notesBytes,
+		BatchSize:   cfg.WriteBatchSize,
+		Concurrency: cfg.ExtractionConcurrency,
+	})
+	if err != nil {
+		return err
+	}
+	if counter, ok := backend.(extractor.ByteCounter); ok {
+		result.Bytes = counter.BytesWritten(extractor.ResourceTasks)
+	}
+	if result.Bytes <= 0 {
+		result.Bytes = dirSize(benchDir)
+	}
+
+	printBench(stdout, cfg.StorageBackend, result, *planTasks)
+	return nil
+}
+
+// benchStorage writes opts.Tasks synthetic tasks through backend, from
+// opts.Concurrency workers, in batches when it is a BatchWriter, then ends
+// the run the way an extraction does
+func benchStorage(ctx context.Context, backend storage.Backend, opts benchOptions) (benchResult, error) {
+	batcher, batched := backend.(extractor.BatchWriter)
+	size := 1
+	if batched {
+		size = max(opts.BatchSize, 1)
+	}
+
+	starts := make(chan int)
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		firstErr  error
+		wg        sync.WaitGroup
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	start := time.Now()
+	for range max(opts.Concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for first := range starts {
+				batch := make([]asana.Task, 0, size)
+				for i := first; i < min(first+size, opts.Tasks); i++ {
+					batch = append(batch, syntheticTask(i, opts.NotesBytes))
+				}
+
+				began := time.Now()
+				var err error
+				if batched {
+					err = batcher.WriteTasks(batch)
+				} else {
+					err = backend.WriteTask(batch[0])
+				}
+				took := time.Since(began)
+
+				mu.Lock()
+				latencies = append(latencies, took)
+				if err != nil && firstErr == nil {
+					firstErr = fmt.Errorf("failed to write synthetic tasks: %w", err)
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+feed:
+	for first := 0; first < opts.Tasks; first += size {
+		select {
+		case starts <- first:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(starts)
+	wg.Wait()
+	if firstErr != nil {
+		return benchResult{}, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return benchResult{}, err
+	}
+
+	// Backends that buffer or publish files per run do so here
+	if w, ok := backend.(extractor.ManifestWriter); ok {
+		manifest := map[string]any{"run_id": "bench", "status": "succeeded", "resources": []string{extractor.ResourceTasks}}
+		if err := w.WriteManifest(manifest); err != nil {
+			return benchResult{}, fmt.Errorf("failed to finish the benchmark run: %w", err)
+		}
+	}
+
+	return benchResult{
+		Tasks:     opts.Tasks,
+		Duration:  time.Since(start),
+		Latencies: latencies,
+		Batched:   batched,
+		Bytes:     -1,
+	}, nil
+}
+
+// syntheticTask returns the i-th benchmark task, shaped like one fetched
+// with the standard field preset
+func syntheticTask(i, notesBytes int) asana.Task {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(i) * time.Minute)
+	gid := strconv.Itoa(benchGIDBase + i)
+	return asana.Task{
+		GID:          gid,
+		ResourceType: "task",
+		Name:         "Synthetic task " + gid,
+		Notes:        strings.Repeat("x", notesBytes),
+		CreatedAt:    created,
+		ModifiedAt:   created.Add(time.Hour),
+		DueOn:        created.AddDate(0, 0, 14).Format(time.DateOnly),
+		Assignee:     &asana.User{GID: strconv.Itoa(benchGIDBase + i%100), Name: "Synthetic user"},
+		Projects:     []asana.Project{{GID: strconv.Itoa(benchGIDBase + i%50), Name: "Synthetic project"}},
+	}
+}
+
+// printBench writes the benchmark report, with a projection to planTasks
+// tasks when it is set
+func printBench(out io.Writer, backend string, r benchResult, planTasks int) {
+	perCall := "task"
+	if r.Batched {
+		perCall = "batch"
+	}
+	sorted := slices.Clone(r.Latencies)
+	slices.Sort(sorted)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "backend\t%s\n", backend)
+	fmt.Fprintf(w, "tasks\t%d\n", r.Tasks)
+	fmt.Fprintf(w, "duration\t%v\n", r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(w, "throughput\t%.0f tasks/s\n", float64(r.Tasks)/r.Duration.Seconds())
+	for _, p := range []int{50, 95, 99} {
+		fmt.Fprintf(w, "latency p%d per %s\t%v\n", p, perCall, percentile(sorted, p).Round(time.Microsecond))
+	}
+	fmt.Fprintf(w, "latency max per %s\t%v\n", perCall, percentile(sorted, 100).Round(time.Microsecond))
+	if r.Bytes >= 0 {
+		fmt.Fprintf(w, "bytes\t%s (%d B/task)\n", formatBytes(r.Bytes), r.Bytes/int64(r.Tasks))
+	}
+	if planTasks > 0 {
+		scale := float64(planTasks) / float64(r.Tasks)
+		projected := fmt.Sprintf("%v", time.Duration(float64(r.Duration)*scale).Round(time.Second))
+		if r.Bytes >= 0 {
+			projected = formatBytes(int64(float64(r.Bytes)*scale)) + ", " + projected
+		}
+		fmt.Fprintf(w, "projected for %d tasks\t%s\n", planTasks, projected)
+	}
+	w.Flush()
+}
+
+// percentile returns the p-th percentile of sorted durations
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[(len(sorted)*p+99)/100-1]
+}
+
+// dirSize returns the total size of the files under dir, or -1 when there
+// are none, as for backends storing outside it
+func dirSize(dir string) int64 {
+	var total int64
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	if total == 0 {
+		return -1
+	}
+	return total
+}
+
+// formatBytes renders n bytes with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+	"github.com/ioanzicu/asana-extractor/pkg/storage"
+)
+
+// failingBackend fails every write after the first few
+type failingBackend struct {
+	storage.Discard
+	writes int
+}
+
+func (b *failingBackend) WriteTask(asana.Task) error {
+	b.writes++
+	if b.writes > 3 {
+		return errors.New("disk full")
+	}
+	return nil
+}
+
+func TestBenchStorage(t *testing.T) {
+	dir := t.TempDir()
+	s, err := storage.NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := benchStorage(context.Background(), s, benchOptions{Tasks: 25, NotesBytes: 10, Concurrency: 4})
+	if err != nil {
+		t.Fatalf("benchStorage() error = %v", err)
+	}
+	if result.Tasks != 25 || len(result.Latencies) != 25 || result.Batched {
+		t.Errorf("Expected 25 single-task writes, got %+v", result)
+	}
+	if gids, _ := s.ListGIDs("tasks"); len(gids) != 25 {
+		t.Errorf("Expected 25 synthetic tasks stored, got %d", len(gids))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "manifest.json")); err != nil {
+		t.Errorf("Expected the run to be finished with a manifest: %v", err)
+	}
+
+	if _, err := benchStorage(context.Background(), &failingBackend{}, benchOptions{Tasks: 100, Concurrency: 1}); err == nil {
+		t.Error("Expected a failed write to fail the benchmark")
+	}
+}
+
+func TestPrintBench(t *testing.T) {
+	var out bytes.Buffer
+	printBench(&out, "csv", benchResult{
+		Tasks:     1000,
+		Duration:  2 * time.Second,
+		Latencies: []time.Duration{time.Millisecond, 3 * time.Millisecond},
+		Batched:   true,
+		Bytes:     2 << 20,
+	}, 1000000)
+
+	for _, want := range []string{"500 tasks/s", "latency max per batch", "2.0 MiB (2097 B/task)", "2.0 GiB, 33m20s"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in report:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunBenchStorage(t *testing.T) {
+	var out bytes.Buffer
+	stdout = &out
+	defer func() { stdout = os.Stdout }()
+
+	dir := t.TempDir()
+	args := []string{"--token", "x", "--workspace", "ws", "--storage", "csv", "--tasks", "10", "--dir", dir}
+	if err := runBenchStorage(context.Background(), args); err != nil {
+		t.Fatalf("runBenchStorage() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "backend") || !strings.Contains(out.String(), "csv") {
+		t.Errorf("Unexpected report %q", out.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tasks.csv")); err != nil {
+		t.Errorf("Expected the synthetic tasks in the benchmark directory: %v", err)
+	}
+
+	if err := runBenchStorage(context.Background(), []string{"--token", "x", "--workspace", "ws", "--storage", "singer"}); err == nil {
+		t.Error("Expected the singer backend to be refused")
+	}
+}
@@ -0,0 +1,779 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+	"github.com/ioanzicu/asana-extractor/pkg/client"
+	"github.com/ioanzicu/asana-extractor/pkg/config"
+	"github.com/ioanzicu/asana-extractor/pkg/extractor"
+	"github.com/ioanzicu/asana-extractor/pkg/notify"
+	"github.com/ioanzicu/asana-extractor/pkg/ratelimit"
+	"github.com/ioanzicu/asana-extractor/pkg/retry"
+	"github.com/ioanzicu/asana-extractor/pkg/scheduler"
+	"github.com/ioanzicu/asana-extractor/pkg/storage"
+	"github.com/ioanzicu/asana-extractor/pkg/tracing"
+)
+
+// stdout receives command output; swapped out in tests
+var stdout io.Writer = os.Stdout
+
+// command is a single CLI subcommand
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, args []string) error
+}
+
+// commands lists every subcommand; populated in init to break the
+// initialization cycle with runHelp.
+var commands []command
+
+func init() {
+	commands = []command{
+		{name: "serve", usage: "run an initial extraction, then extract on the configured schedule (default)", run: runServe},
+		{name: "extract", usage: "run a single extraction and exit", run: runExtract},
+		{name: "validate-config", usage: "load and validate the configuration, then exit", run: runValidateConfig},
+		{name: "config", usage: "print the effective configuration with secrets masked", run: runConfig},
+		{name: "api", usage: "serve read-only query endpoints over the latest extracted data", run: runAPI},
+		{name: "bench-storage", usage: "write synthetic tasks through the storage backend and report its throughput", run: runBenchStorage},
+		{name: "verify-attachments", usage: "check every stored attachment file against its hash", run: runVerifyAttachments},
+		{name: "schema", usage: "write the JSON Schema, Avro schema or protobuf definitions of every stored resource", run: runSchema},
+		{name: "list-workspaces", usage: "list the workspaces visible to ASANA_TOKEN", run: runListWorkspaces},
+		{name: "version", usage: "print the extractor version", run: runVersion},
+		{name: "help", usage: "show this help", run: runHelp},
+	}
+}
+
+// printUsage writes the list of subcommands to stderr
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: asana-extractor <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-18s %s\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Every configuration variable has a matching flag; run a command with -h to list them.")
+}
+
+// runHelp prints the usage text
+func runHelp(ctx context.Context, args []string) error {
+	printUsage()
+	return nil
+}
+
+// runVersion prints the build version
+func runVersion(ctx context.Context, args []string) error {
+	fmt.Fprintf(stdout, "asana-extractor %s\n", version)
+	return nil
+}
+
+// runValidateConfig loads the configuration and checks every cron expression
+func runValidateConfig(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("validate-config")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := cfgFlags.Load()
+	if err != nil {
+		return err
+	}
+
+	if cfg.ScheduleMode == "cron" {
+		if err := scheduler.ValidateExpression(cfg.ScheduleCron); err != nil {
+			return fmt.Errorf("invalid SCHEDULE_CRON %q: %w", cfg.ScheduleCron, err)
+		}
+	}
+	for resource, expr := range cfg.ResourceSchedules {
+		if err := scheduler.ValidateExpression(expr); err != nil {
+			return fmt.Errorf("invalid schedule for %s %q: %w", resource, expr, err)
+		}
+	}
+	if _, err := scheduler.ParseWindows(cfg.ScheduleBlackouts); err != nil {
+		return fmt.Errorf("invalid SCHEDULE_BLACKOUTS: %w", err)
+	}
+
+	fmt.Fprintln(stdout, "Configuration is valid")
+	return nil
+}
+
+// runConfig prints the fully resolved configuration, after defaults, the
+// configuration file, the environment and flags have been applied
+func runConfig(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("config")
+	asJSON := flags.Bool("json", false, "print the configuration as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := cfgFlags.Load()
+	if err != nil {
+		return err
+	}
+	redacted := cfg.Redacted()
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(redacted)
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	v := reflect.ValueOf(redacted)
+	for i := 0; i < v.NumField(); i++ {
+		fmt.Fprintf(w, "%s\t%v\n", v.Type().Field(i).Name, v.Field(i).Interface())
+	}
+	return w.Flush()
+}
+
+// runListWorkspaces prints the GID and name of every workspace the token can see
+func runListWorkspaces(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("list-workspaces")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := cfgFlags.LoadCredentials()
+	if err != nil {
+		return err
+	}
+
+	asanaClient, err := newAsanaClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	workspaces, err := asanaClient.GetAllWorkspaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GID\tNAME")
+	for _, ws := range workspaces {
+		fmt.Fprintf(w, "%s\t%s\n", ws.GID, ws.Name)
+	}
+	return w.Flush()
+}
+
+// runExtract performs a single extraction and exits
+func runExtract(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("extract")
+	if err := flags.Parse(args); err != nil {
+		return withExitCode(exitConfig, err)
+	}
+
+	cfg, err := loadConfig(cfgFlags)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockOutput(cfg)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	shutdown, err := setupTracing(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
+	return extractOnce(ctx, cfg)
+}
+
+// runServe runs an initial extraction and then blocks on the scheduler until
+// ctx is cancelled. --once / RUN_ONCE short-circuit to a single extraction.
+func runServe(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("serve")
+	once := flags.Bool("once", false, "run a single extraction and exit instead of starting the scheduler")
+	if err := flags.Parse(args); err != nil {
+		return withExitCode(exitConfig, err)
+	}
+
+	// 1. Load configuration
+	cfg, err := loadConfig(cfgFlags)
+	if err != nil {
+		return err
+	}
+
+	if *once {
+		cfg.RunOnce = true
+	}
+
+	unlock, err := lockOutput(cfg)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	shutdown, err := setupTracing(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
+	// Run-once mode performs a single extraction and exits, leaving
+	// scheduling to an external orchestrator (Kubernetes CronJob, Airflow).
+	if cfg.RunOnce {
+		return extractOnce(ctx, cfg)
+	}
+
+	// 2. Build Dependencies
+	notifier := newNotifier(cfg)
+	extract, err := newExtractFunc(cfg, notifier)
+	if err != nil {
+		return err
+	}
+
+	// Alert when no run has succeeded within NOTIFY_STALE_AFTER
+	go notifier.Watch(ctx)
+
+	// Resources with their own SCHEDULE_CRON_<RESOURCE> run as independent
+	// jobs; everything else shares the default schedule.
+	var defaultResources []string
+	for _, resource := range cfg.ExtractResources {
+		if _, ok := cfg.ResourceSchedules[resource]; !ok {
+			defaultResources = append(defaultResources, resource)
+		}
+	}
+
+	// 3. Define the Jobs
+	newJob := func(name string, resources []string) scheduler.Job {
+		return func(ctx context.Context) error {
+			_, err := extract(ctx, name, resources)
+			return err
+		}
+	}
+
+	sched, err := newScheduler(cfg, newJob)
+	if err != nil {
+		return err
+	}
+
+	if cfg.MetricsAddr != "" {
+		stopMetrics, err := startMetricsServer(cfg.MetricsAddr, map[string]http.Handler{
+			"/trigger": triggerHandler(sched),
+			"/healthz": healthHandler(sched),
+		})
+		if err != nil {
+			return err
+		}
+		defer stopMetrics()
+	}
+
+	// 4. Run initial extraction of every selected resource
+	log.Println("Running initial extraction...")
+	sched.RunNow(ctx, "initial", newJob("initial", cfg.ExtractResources))
+
+	// 5. Start Scheduler
+	var defaultJob scheduler.Job
+	if len(defaultResources) > 0 {
+		defaultJob = newJob("default", defaultResources)
+	}
+
+	// Webhook events re-extract just the resources they touched, under the
+	// scheduler's overlap policy. Waiting for the run lets the receiver batch
+	// the events arriving meanwhile.
+	if cfg.WebhookEnabled {
+		stopWebhooks, err := startWebhooks(ctx, cfg, func(resources []string) {
+			selected := selectedResources(cfg, resources)
+			if len(selected) == 0 {
+				return
+			}
+			done, err := sched.TriggerJob(ctx, "webhook", newJob("webhook", selected))
+			if err != nil {
+				log.Printf("Not running webhook extraction of %v: %v", selected, err)
+				return
+			}
+			<-done
+		})
+		if err != nil {
+			return err
+		}
+		defer stopWebhooks()
+	}
+
+	// SIGUSR1 runs the default job on demand
+	go triggerOnSignal(ctx, sched)
+
+	log.Println("Starting scheduler...")
+
+	// This will block until the context is canceled (via SIGINT/SIGTERM)
+	err = sched.Start(ctx, defaultJob)
+	if err == nil {
+		log.Println("Extractor stopped gracefully")
+	}
+	return err
+}
+
+// printDryRun writes what a dry run found per resource: the entities that
+// would be written and the API calls, pages and time it took
+func printDryRun(out io.Writer, stats *extractor.Stats) {
+	resources := make([]string, 0, len(stats.Resources))
+	for resource := range stats.Resources {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE\tENTITIES\tAPI CALLS\tPAGES\tRATE LIMIT WAIT")
+	for _, resource := range resources {
+		r := stats.Resources[resource]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%v\n", resource, r.Extracted, r.APICalls, r.Pages, r.RateLimitWait.Round(time.Millisecond))
+	}
+	fmt.Fprintf(w, "total\t%d\t%d\t%d\t%v\n", stats.UsersExtracted+stats.ProjectsExtracted+stats.TasksExtracted+stats.TeamsExtracted,
+		stats.APICalls, stats.Pages, stats.RateLimitWait.Round(time.Millisecond))
+	w.Flush()
+	fmt.Fprintf(out, "Dry run took %v; nothing was written.\n", stats.Duration.Round(time.Millisecond))
+}
+
+// previousCounts returns the entity counts of the last run recorded in dir,
+// from its manifest or the latest snapshot's, to estimate a run's progress.
+// It returns nil when there is none.
+func previousCounts(dir string) map[string]int {
+	for _, path := range []string{
+		filepath.Join(dir, "manifest.json"),
+		filepath.Join(dir, storage.LatestLink, "manifest.json"),
+	} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var m extractor.Manifest
+		if err := json.Unmarshal(data, &m); err == nil && m.Status == extractor.StatusSucceeded {
+			return m.Counts
+		}
+	}
+	return nil
+}
+
+// serveScheduler is the scheduler serve drives, selected by SCHEDULE_MODE
+type serveScheduler interface {
+	scheduler.Scheduler
+	RunNow(ctx context.Context, name string, job scheduler.Job)
+	Trigger(ctx context.Context, name string) error
+	TriggerJob(ctx context.Context, name string, job scheduler.Job) (<-chan struct{}, error)
+}
+
+// newScheduler creates the scheduler selected by SCHEDULE_MODE. In cron mode
+// every resource with its own SCHEDULE_CRON_<RESOURCE> gets a job from newJob.
+func newScheduler(cfg *config.Config, newJob func(name string, resources []string) scheduler.Job) (serveScheduler, error) {
+	blackouts, err := scheduler.ParseWindows(cfg.ScheduleBlackouts)
+	if err != nil {
+		return nil, err
+	}
+
+	schedCfg := scheduler.Config{
+		OverlapPolicy: scheduler.OverlapPolicy(cfg.ScheduleOverlapPolicy),
+		JobTimeout:    cfg.JobTimeout,
+		DrainTimeout:  cfg.DrainTimeout,
+
+		FailureBackoff:    cfg.ScheduleFailureBackoff,
+		MaxFailureBackoff: cfg.ScheduleMaxFailureBackoff,
+		Blackouts:         blackouts,
+	}
+
+	if cfg.ScheduleMode == "interval" {
+		return scheduler.NewIntervalScheduler(cfg.ScheduleInterval, schedCfg), nil
+	}
+
+	sched := scheduler.NewCronScheduler(cfg.ScheduleCron, schedCfg)
+	for _, resource := range cfg.ExtractResources {
+		if expr, ok := cfg.ResourceSchedules[resource]; ok {
+			if err := sched.AddJob(resource, expr, newJob(resource, []string{resource})); err != nil {
+				return nil, fmt.Errorf("invalid schedule for %s: %w", resource, err)
+			}
+		}
+	}
+	return sched, nil
+}
+
+// selectedResources filters resources down to those enabled in EXTRACT_RESOURCES
+func selectedResources(cfg *config.Config, resources []string) []string {
+	var selected []string
+	for _, resource := range resources {
+		for _, enabled := range cfg.ExtractResources {
+			if resource == enabled {
+				selected = append(selected, resource)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// newFlagSet returns a flag set for the named command with a flag for every
+// configuration variable registered on it
+func newFlagSet(name string) (*flag.FlagSet, *config.Flags) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	return flags, config.NewFlags(flags)
+}
+
+// loadConfig loads the full configuration and logs a summary of it
+func loadConfig(cfgFlags *config.Flags) (*config.Config, error) {
+	log.Println("Starting Asana Extractor...")
+
+	cfg, err := cfgFlags.Load()
+	if err != nil {
+		return nil, withExitCode(exitConfig, err)
+	}
+
+	log.Printf("Configuration loaded: workspace=%s, schedule=%s, output=%s, resources=%s",
+		cfg.AsanaWorkspace, cfg.ScheduleCron, cfg.OutputDirectory, strings.Join(cfg.ExtractResources, ","))
+
+	return cfg, nil
+}
+
+// lockOutput takes the OUTPUT_LOCK lock on the output directory when enabled.
+// The returned function releases it and is safe to defer unconditionally.
+func lockOutput(cfg *config.Config) (func(), error) {
+	if !cfg.OutputLock || cfg.DryRun {
+		return func() {}, nil
+	}
+
+	lock, err := storage.AcquireLock(cfg.OutputDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		if err := lock.Release(); err != nil {
+			log.Printf("Failed to release output lock: %v", err)
+		}
+	}, nil
+}
+
+// setupTracing installs the OTLP tracer provider when enabled. The returned
+// function flushes buffered spans and is safe to defer unconditionally.
+func setupTracing(ctx context.Context, cfg *config.Config) (func(), error) {
+	shutdown, err := tracing.Setup(ctx, tracing.Config{
+		Enabled:     cfg.TracingEnabled,
+		ServiceName: cfg.TracingServiceName,
+		Version:     version,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		// The run context may already be cancelled; give the flush its own deadline
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdown(flushCtx); err != nil {
+			log.Printf("Failed to flush traces: %v", err)
+		}
+	}, nil
+}
+
+// extractOnce runs a single extraction of every selected resource under
+// JobTimeout. On shutdown the run gets DrainTimeout to finish.
+func extractOnce(ctx context.Context, cfg *config.Config) error {
+	extract, err := newExtractFunc(cfg, newNotifier(cfg))
+	if err != nil {
+		summary, err := summarize(cfg, runResult{}, withExitCode(exitConfig, err))
+		printSummary(cfg, summary)
+		return err
+	}
+
+	log.Println("Running single extraction...")
+	ctx, cancelDrain := scheduler.WithDrain(ctx, cfg.DrainTimeout)
+	defer cancelDrain()
+	if cfg.JobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.JobTimeout)
+		defer cancel()
+	}
+	result, err := extract(ctx, "once", cfg.ExtractResources)
+	summary, err := summarize(cfg, result, err)
+	printSummary(cfg, summary)
+	return err
+}
+
+// extractFunc runs one named extraction over the given resources
+type extractFunc func(ctx context.Context, name string, resources []string) (runResult, error)
+
+// runResult is what an extraction produced, as far as it got
+type runResult struct {
+	stats *extractor.Stats
+	// snapshot is the published snapshot directory in snapshot mode
+	snapshot string
+}
+
+// newNotifier builds the failure notifier from config; nil when disabled
+func newNotifier(cfg *config.Config) *notify.Notifier {
+	return notify.New(notify.Config{
+		SlackWebhookURL: cfg.NotifySlackWebhookURL,
+		WebhookURL:      cfg.NotifyWebhookURL,
+		StaleAfter:      cfg.NotifyStaleAfter,
+	})
+}
+
+// newExtractFunc wires the Asana client and storage into an extractFunc.
+// Failed runs are reported through notifier, which may be nil.
+func newExtractFunc(cfg *config.Config, notifier *notify.Notifier) (extractFunc, error) {
+	asanaClient, err := newAsanaClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	storageOpts, err := storageOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	exporters := newExporters(cfg)
+
+	var stor extractor.Storage
+	if cfg.DryRun {
+		stor = storage.Discard{}
+	} else if !cfg.SnapshotsEnabled {
+		backend, err := storage.Open(cfg.StorageBackend, storage.Settings{
+			Dir:     cfg.OutputDirectory,
+			Options: storageOpts,
+			Params:  cfg.StorageParams,
+		})
+		if err != nil {
+			return nil, err
+		}
+		stor = backend
+	}
+
+	return func(ctx context.Context, name string, resources []string) (runResult, error) {
+		// In snapshot mode every run gets a fresh directory, published below
+		// only if the run succeeds
+		var snap *storage.Snapshot
+		stor := stor
+		if cfg.SnapshotsEnabled && !cfg.DryRun {
+			var err error
+			if snap, err = storage.NewSnapshot(cfg.OutputDirectory, time.Now(), storageOpts); err != nil {
+				return runResult{}, err
+			}
+			stor = snap
+		}
+
+		extCfg := extractor.Config{
+			Concurrency:    cfg.ExtractionConcurrency,
+			WriteBatchSize: cfg.WriteBatchSize,
+			Resources:      resources,
+			MaxErrorRate:   cfg.MaxErrorRate,
+			MaxAPICalls:    int64(cfg.MaxAPICallsPerRun),
+			Filters: extractor.Filters{
+				SkipArchivedProjects: cfg.SkipArchivedProjects,
+				ProjectTeams:         cfg.FilterProjectTeams,
+				UserEmailDomains:     cfg.FilterUserEmailDomains,
+			},
+			Verify: extractor.Verify{
+				Recount:    cfg.VerifyRecount,
+				SampleSize: cfg.VerifySampleSize,
+				Threshold:  cfg.VerifyThreshold,
+			},
+			Preflight: extractor.Preflight{
+				Enabled:     cfg.PreflightEnabled,
+				MaxAPICalls: int64(cfg.PreflightMaxAPICalls),
+			},
+			Reconcile:          cfg.ReconcileMode,
+			IncrementalTasks:   cfg.IncrementalTasks,
+			AuditEventLookback: cfg.AuditEventLookback,
+			AuditDir:           cfg.AuditLogDir,
+			ConfigSnapshot:     cfg.Redacted(),
+		}
+		switch cfg.HTMLNotes {
+		case "sanitize":
+			extCfg.Transformers.Tasks = append(extCfg.Transformers.Tasks, extractor.SanitizeHTMLNotes)
+		case "markdown":
+			extCfg.Transformers.Tasks = append(extCfg.Transformers.Tasks, extractor.MarkdownHTMLNotes)
+		}
+		if cfg.ProgressInterval > 0 {
+			extCfg.Progress = extractor.LogProgress
+			extCfg.ProgressInterval = cfg.ProgressInterval
+			extCfg.ExpectedCounts = previousCounts(cfg.OutputDirectory)
+		}
+		ext := extractor.New(asanaClient, stor, extCfg)
+
+		stats, err := ext.Extract(ctx)
+		result := runResult{stats: stats}
+		if err != nil {
+			log.Printf("Extraction %s (run %s) failed (timed_out=%t): %v", name, stats.RunID, stats.TimedOut, err)
+			kind := notify.KindFailure
+			if errors.Is(err, extractor.ErrErrorBudgetExceeded) {
+				kind = notify.KindErrorBudget
+			}
+			notifier.RunFailed(ctx, kind, name, stats.RunID, err)
+			return result, withExitCode(exitRunFailed, err)
+		}
+
+		log.Printf("Extraction %s (run %s) stats: users=%d, projects=%d, tasks=%d, teams=%d, errors=%d, skipped=%d, rejected=%d, unchanged=%d, orphaned=%d, api_calls=%d, retries=%d, rate_limited=%d, pages=%d, cache_hits=%d, bytes_saved=%d, bytes=%d, rate_limit_wait=%v, duration=%v",
+			name, stats.RunID, stats.UsersExtracted, stats.ProjectsExtracted, stats.TasksExtracted, stats.TeamsExtracted, stats.Errors, stats.Skipped, stats.Rejected, stats.Unchanged, stats.Orphaned,
+			stats.APICalls, stats.Retries, stats.RateLimited, stats.Pages, stats.CacheHits, stats.BytesSaved, stats.BytesWritten, stats.RateLimitWait, stats.Duration)
+		if cfg.DryRun {
+			printDryRun(stdout, stats)
+		}
+
+		if snap != nil {
+			if err := snap.Commit(); err != nil {
+				notifier.RunFailed(ctx, notify.KindFailure, name, stats.RunID, err)
+				return result, err
+			}
+			log.Printf("Published snapshot %s", snap.Dir())
+			result.snapshot = snap.Dir()
+		}
+
+		// Exporters read what the run just stored
+		exportDir := cfg.OutputDirectory
+		if snap != nil {
+			exportDir = snap.Dir()
+		}
+		runExporters(ctx, exporters, exportDir, storageOpts, stats.RunID)
+
+		notifier.RunSucceeded()
+		return result, nil
+	}, nil
+}
+
+// storageOptions builds the JSON storage options from config, loading the
+// encryption key if one is configured
+func storageOptions(cfg *config.Config) (storage.Options, error) {
+	opts := storage.Options{
+		Compression:   storage.Compression(cfg.OutputCompression),
+		ChangeLog:     cfg.ChangeLog,
+		DurableWrites: cfg.DurableWrites,
+		Layout:        cfg.OutputLayout,
+		Envelope:      cfg.OutputEnvelope,
+		WorkspaceGID:  cfg.AsanaWorkspace,
+	}
+
+	var err error
+	switch {
+	case cfg.OutputEncryptionKey != "":
+		opts.EncryptionKey, err = storage.ParseKey(cfg.OutputEncryptionKey)
+	case cfg.OutputEncryptionKeyFile != "":
+		opts.EncryptionKey, err = storage.LoadKeyFile(cfg.OutputEncryptionKeyFile)
+	}
+	return opts, err
+}
+
+// newAsanaClient builds the rate-limited, retrying Asana client from config.
+// Clients of the same workspace share its limiter from rateLimiters.
+func newAsanaClient(cfg *config.Config) (*asana.Client, error) {
+	cache, err := newResponseCache(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := client.NewTransport(client.TransportConfig{
+		ProxyURL:            cfg.HTTPProxyURL,
+		CAFile:              cfg.TLSCAFile,
+		ClientCertFile:      cfg.TLSClientCertFile,
+		ClientKeyFile:       cfg.TLSClientKeyFile,
+		MaxIdleConnsPerHost: cfg.HTTPMaxIdleConnsPerHost,
+		DialTimeout:         cfg.HTTPDialTimeout,
+		TLSHandshakeTimeout: cfg.HTTPTLSHandshakeTimeout,
+		MaxIdleConns:        cfg.HTTPMaxIdleConns,
+		MaxConnsPerHost:     cfg.HTTPMaxConnsPerHost,
+		IdleConnTimeout:     cfg.HTTPIdleConnTimeout,
+		ForceHTTP2:          cfg.HTTPForceHTTP2,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	retryConfig := retry.Config{
+		MaxRetries:     cfg.MaxRetries,
+		InitialBackoff: cfg.InitialBackoff,
+		MaxBackoff:     cfg.MaxBackoff,
+		OnRetry:        observeRetry,
+	}
+	if cfg.RetryBudgetPerMinute > 0 {
+		retryConfig.Budget = retry.NewBudget(cfg.RetryBudgetPerMinute)
+	}
+
+	var tokens client.TokenProvider = client.StaticToken(cfg.AsanaToken)
+	if cfg.AsanaTokenFile != "" {
+		tokens = client.NewFileToken(cfg.AsanaTokenFile)
+		if _, err := tokens.Token(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	limiterKey := cfg.AsanaWorkspace
+	if limiterKey == "" {
+		limiterKey = "default"
+	}
+	limiter := rateLimiters.Get(limiterKey, ratelimit.Config{
+		RequestsPerMinute:  cfg.RequestsPerMinute,
+		MaxConcurrentRead:  cfg.MaxConcurrentRead,
+		MaxConcurrentWrite: cfg.MaxConcurrentWrite,
+		Burst:              cfg.RateBurst,
+	})
+
+	httpClient := client.New(client.Config{
+		TokenProvider:  tokens,
+		RateLimiter:    limiter,
+		RetryConfig:    retryConfig,
+		Timeout:        cfg.HTTPTimeout,
+		AttemptTimeout: cfg.PerRequestTimeout,
+		BaseURL:        cfg.BaseURL,
+		Cache:          cache,
+		Compression:    cfg.HTTPCompression,
+		Hedge:          cfg.HTTPHedgeRequests,
+		Transport:      transport,
+		Middleware:     recordMiddleware(cfg),
+		Latencies:      clientLatencies,
+	})
+
+	return asana.NewClientWithOptions(httpClient, cfg.AsanaWorkspace, cfg.BaseURL, asana.ClientOptions{
+		UserPageSize: cfg.UserPageSize,
+		PageSizes:    cfg.PageSizes,
+		TaskFields:   cfg.TaskFields,
+		// Photos and attachments share the proxy and TLS settings, but not
+		// the token
+		PhotoClient: &http.Client{Transport: transport, Timeout: cfg.HTTPTimeout},
+	})
+}
+
+// recordMiddleware returns the HTTP_RECORD middleware recording or
+// replaying API responses, if enabled
+func recordMiddleware(cfg *config.Config) []client.Middleware {
+	switch cfg.HTTPRecord {
+	case "record":
+		return []client.Middleware{client.Record(cfg.HTTPRecordDir)}
+	case "replay":
+		return []client.Middleware{client.Replay(cfg.HTTPRecordDir)}
+	}
+	return nil
+}
+
+// memoryCacheEntries bounds the in-memory response cache
+const memoryCacheEntries = 10000
+
+// newResponseCache builds the HTTP_CACHE response cache; nil when disabled
+func newResponseCache(cfg *config.Config) (client.Cache, error) {
+	switch cfg.HTTPCache {
+	case "memory":
+		return client.NewMemoryCache(memoryCacheEntries), nil
+	case "disk":
+		cache, err := client.NewDiskCache(cfg.HTTPCacheDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create HTTP cache directory: %w", err)
+		}
+		return cache, nil
+	}
+	return nil, nil
+}
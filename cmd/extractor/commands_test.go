@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+	"github.com/ioanzicu/asana-extractor/pkg/asanatest"
+	"github.com/ioanzicu/asana-extractor/pkg/config"
+	"github.com/ioanzicu/asana-extractor/pkg/scheduler"
+	"github.com/ioanzicu/asana-extractor/pkg/storage"
+)
+
+func TestCommands_Table(t *testing.T) {
+	server := asanatest.NewServer(asanatest.Fixtures{
+		Workspaces: []asana.Workspace{{GID: "111", Name: "Acme"}},
+	})
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		args           []string
+		envVars        map[string]string
+		expectError    bool
+		outputContains string
+		outputExcludes string
+	}{
+		{
+			name:           "version prints build version",
+			args:           []string{"version"},
+			outputContains: "asana-extractor dev",
+		},
+		{
+			name: "validate-config accepts a valid configuration",
+			args: []string{"validate-config"},
+			envVars: map[string]string{
+				"ASANA_TOKEN":     "valid-token",
+				"ASANA_WORKSPACE": "123",
+				"SCHEDULE_CRON":   "0 */5 * * * *",
+			},
+			outputContains: "Configuration is valid",
+		},
+		{
+			name: "validate-config rejects a bad cron expression",
+			args: []string{"validate-config"},
+			envVars: map[string]string{
+				"ASANA_TOKEN":     "valid-token",
+				"ASANA_WORKSPACE": "123",
+				"SCHEDULE_CRON":   "*/5 * * * *",
+			},
+			expectError: true,
+		},
+		{
+			name: "validate-config rejects a bad blackout window",
+			args: []string{"validate-config"},
+			envVars: map[string]string{
+				"ASANA_TOKEN":        "valid-token",
+				"ASANA_WORKSPACE":    "123",
+				"SCHEDULE_BLACKOUTS": "Sun 01:00",
+			},
+			expectError: true,
+		},
+		{
+			name: "list-workspaces works without a workspace",
+			args: []string{"list-workspaces"},
+			envVars: map[string]string{
+				"ASANA_TOKEN":     "valid-token",
+				"ASANA_WORKSPACE": "",
+				"BASE_URL":        server.URL,
+			},
+			outputContains: "111  Acme",
+		},
+		{
+			name: "extract runs a single extraction and returns",
+			args: []string{"extract"},
+			envVars: map[string]string{
+				"ASANA_TOKEN":     "valid-token",
+				"ASANA_WORKSPACE": "123",
+				"BASE_URL":        server.URL,
+				"OUTPUT_DIR":      t.TempDir(),
+			},
+		},
+		{
+			name: "config prints the effective configuration",
+			args: []string{"config", "--rpm", "42"},
+			envVars: map[string]string{
+				"ASANA_TOKEN":     "secret-token",
+				"ASANA_WORKSPACE": "123",
+			},
+			outputContains: " 42\nMaxConcurrentRead",
+			outputExcludes: "secret-token",
+		},
+		{
+			name: "config prints JSON",
+			args: []string{"config", "--json"},
+			envVars: map[string]string{
+				"ASANA_TOKEN":     "secret-token",
+				"ASANA_WORKSPACE": "123",
+			},
+			outputContains: `"AsanaToken": "****"`,
+			outputExcludes: "secret-token",
+		},
+		{
+			name:        "unknown command fails",
+			args:        []string{"frobnicate"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.envVars {
+				t.Setenv(k, v)
+			}
+
+			var out bytes.Buffer
+			defer func(orig io.Writer) { stdout = orig }(stdout)
+			stdout = &out
+
+			err := run(context.Background(), tc.args)
+
+			if (err != nil) != tc.expectError {
+				t.Fatalf("expectError %v, got %v", tc.expectError, err)
+			}
+			if tc.outputContains != "" && !strings.Contains(out.String(), tc.outputContains) {
+				t.Errorf("expected output containing %q, got %q", tc.outputContains, out.String())
+			}
+			if tc.outputExcludes != "" && strings.Contains(out.String(), tc.outputExcludes) {
+				t.Errorf("expected output without %q, got %q", tc.outputExcludes, out.String())
+			}
+		})
+	}
+}
+
+func TestRunExtract_DryRun(t *testing.T) {
+	server := asanatest.NewServer(asanatest.Fixtures{
+		Users: []asana.User{{GID: "1", Name: "Ada"}},
+	})
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "out")
+	t.Setenv("ASANA_TOKEN", "valid-token")
+	t.Setenv("ASANA_WORKSPACE", "123")
+	t.Setenv("BASE_URL", server.URL)
+	t.Setenv("OUTPUT_DIR", dir)
+	t.Setenv("EXTRACT_RESOURCES", "users")
+
+	var out bytes.Buffer
+	defer func(orig io.Writer) { stdout = orig }(stdout)
+	stdout = &out
+
+	if err := run(context.Background(), []string{"extract", "--dry-run"}); err != nil {
+		t.Fatalf("extract --dry-run failed: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "users     1") || !strings.Contains(out.String(), "nothing was written") {
+		t.Errorf("expected a per-resource dry run report, got %q", out.String())
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected the output directory not to be created, got %v", err)
+	}
+}
+
+func TestRunExtract_RecordReplay(t *testing.T) {
+	server := asanatest.NewServer(asanatest.Fixtures{
+		Users:    []asana.User{{GID: "1", Name: "Ada"}, {GID: "2", Name: "Grace"}},
+		Projects: []asana.Project{{GID: "p1", Name: "Launch"}},
+		Tasks:    map[string][]asana.Task{"p1": {{GID: "t1", Name: "Ship"}}},
+	})
+	recordings := t.TempDir()
+	t.Setenv("ASANA_WORKSPACE", "123")
+	t.Setenv("HTTP_RECORD_DIR", recordings)
+	t.Setenv("EXTRACT_RESOURCES", "users,projects,tasks")
+
+	var out bytes.Buffer
+	defer func(orig io.Writer) { stdout = orig }(stdout)
+	stdout = &out
+
+	extract := func(mode, baseURL string) runSummary {
+		t.Helper()
+		out.Reset()
+		t.Setenv("HTTP_RECORD", mode)
+		t.Setenv("BASE_URL", baseURL)
+		t.Setenv("OUTPUT_DIR", t.TempDir())
+		if err := run(context.Background(), []string{"extract"}); err != nil {
+			t.Fatalf("extract with HTTP_RECORD=%s failed: %v", mode, err)
+		}
+		var s runSummary
+		if err := json.Unmarshal(out.Bytes(), &s); err != nil {
+			t.Fatalf("Failed to decode run summary %q: %v", out.String(), err)
+		}
+		return s
+	}
+
+	t.Setenv("ASANA_TOKEN", "valid-token")
+	recorded := extract("record", server.URL)
+	server.Close()
+
+	// Replay needs neither the server nor a token
+	t.Setenv("ASANA_TOKEN", "")
+	replayed := extract("replay", "http://asana.invalid")
+
+	if replayed.Stats.UsersExtracted != 2 || replayed.Stats.TasksExtracted != 1 ||
+		replayed.Stats.UsersExtracted != recorded.Stats.UsersExtracted {
+		t.Errorf("Expected the replayed run to match the recorded one, got %+v and %+v", replayed.Stats, recorded.Stats)
+	}
+}
+
+func TestSelectedResources(t *testing.T) {
+	cfg := &config.Config{ExtractResources: []string{"projects", "tasks"}}
+
+	tests := []struct {
+		name      string
+		resources []string
+		want      int
+	}{
+		{name: "All enabled", resources: []string{"projects", "tasks"}, want: 2},
+		{name: "Some disabled", resources: []string{"teams", "projects"}, want: 1},
+		{name: "None enabled", resources: []string{"users"}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectedResources(cfg, tt.resources); len(got) != tt.want {
+				t.Errorf("selectedResources(%v) = %v, want %d entries", tt.resources, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewScheduler(t *testing.T) {
+	newJob := func(name string, resources []string) scheduler.Job {
+		return func(context.Context) error { return nil }
+	}
+
+	tests := []struct {
+		name        string
+		cfg         config.Config
+		expectCron  bool
+		expectError bool
+	}{
+		{name: "Cron", cfg: config.Config{ScheduleMode: "cron", ScheduleCron: "0 */5 * * * *"}, expectCron: true},
+		{name: "Interval", cfg: config.Config{ScheduleMode: "interval", ScheduleInterval: time.Minute}},
+		{
+			name: "Invalid Resource Cron",
+			cfg: config.Config{
+				ScheduleMode:      "cron",
+				ExtractResources:  []string{"users"},
+				ResourceSchedules: map[string]string{"users": "bogus"},
+			},
+			expectError: true,
+		},
+		{name: "Invalid Blackout", cfg: config.Config{ScheduleMode: "cron", ScheduleCron: "0 */5 * * * *", ScheduleBlackouts: "Sun 1-3"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sched, err := newScheduler(&tt.cfg, newJob)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newScheduler() failed: %v", err)
+			}
+			if _, ok := sched.(*scheduler.CronScheduler); ok != tt.expectCron {
+				t.Errorf("expected a cron scheduler: %t, got %T", tt.expectCron, sched)
+			}
+		})
+	}
+}
+
+func TestPreviousCounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     int
+	}{
+		{name: "Succeeded", manifest: `{"status": "succeeded", "counts": {"tasks": 42}}`, want: 42},
+		{name: "Failed", manifest: `{"status": "failed", "counts": {"tasks": 3}}`, want: 0},
+		{name: "Corrupt", manifest: `{`, want: 0},
+		{name: "Missing", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.manifest != "" {
+				if err := os.WriteFile(filepath.Join(dir, "manifest.json"), []byte(tt.manifest), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := previousCounts(dir)["tasks"]; got != tt.want {
+				t.Errorf("expected %d tasks, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLockOutput(t *testing.T) {
+	cfg := &config.Config{OutputDirectory: t.TempDir(), OutputLock: true}
+
+	unlock, err := lockOutput(cfg)
+	if err != nil {
+		t.Fatalf("lockOutput() failed: %v", err)
+	}
+
+	if _, err := lockOutput(cfg); !errors.Is(err, storage.ErrLocked) {
+		t.Errorf("Expected second instance to get ErrLocked, got %v", err)
+	}
+
+	unlock()
+
+	disabled := &config.Config{OutputDirectory: cfg.OutputDirectory}
+	unlock, err = lockOutput(disabled)
+	if err != nil {
+		t.Fatalf("Expected no-op when OUTPUT_LOCK is off, got %v", err)
+	}
+	unlock()
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/ioanzicu/asana-extractor/pkg/config"
+	"github.com/ioanzicu/asana-extractor/pkg/extractor"
+)
+
+// Process exit codes, so orchestrators can branch on the outcome of a
+// run-once extraction
+const (
+	exitOK = 0
+	// exitFailure covers every failure without a more specific code
+	exitFailure = 1
+	// exitConfig means the configuration was invalid or the extractor could
+	// not be set up with it; retrying will not help
+	exitConfig = 2
+	// exitRunFailed means the extraction failed: Asana API errors, a
+	// timeout or an exceeded error budget
+	exitRunFailed = 3
+	// exitPartial means the run completed and was stored, but some
+	// entities failed
+	exitPartial = 4
+)
+
+// exitError attaches a process exit code to an error
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode attaches code to err; a nil err stays nil
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// exitCode returns the exit code for the outcome of a command
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	var ee *exitError
+	if errors.As(err, &ee) {
+		return ee.code
+	}
+	return exitFailure
+}
+
+// runSummary is the machine-readable outcome of a run-once extraction,
+// printed as JSON on stdout
+type runSummary struct {
+	RunID  string `json:"run_id,omitempty"`
+	Status string `json:"status"`
+	// ExitCode is the code the process exits with
+	ExitCode  int    `json:"exit_code"`
+	Error     string `json:"error,omitempty"`
+	OutputDir string `json:"output_dir"`
+	// Snapshot is the published snapshot directory in snapshot mode
+	Snapshot string           `json:"snapshot,omitempty"`
+	Stats    *extractor.Stats `json:"stats,omitempty"`
+}
+
+// Run-once outcomes reported in runSummary.Status
+const (
+	summarySucceeded = "succeeded"
+	summaryPartial   = "partial"
+	summaryFailed    = "failed"
+)
+
+// summarize classifies the outcome of a run-once extraction, returning its
+// summary and the error to exit with
+func summarize(cfg *config.Config, result runResult, err error) (runSummary, error) {
+	s := runSummary{Status: summarySucceeded, OutputDir: cfg.OutputDirectory, Snapshot: result.snapshot, Stats: result.stats}
+	if result.stats != nil {
+		s.RunID = result.stats.RunID
+	}
+
+	switch {
+	case err != nil:
+		s.Status = summaryFailed
+	case result.stats != nil && result.stats.APIBudgetExhausted:
+		s.Status = summaryPartial
+		err = withExitCode(exitPartial, fmt.Errorf("run %s stopped after %d API calls, the MAX_API_CALLS_PER_RUN limit", s.RunID, result.stats.APICalls))
+	case result.stats != nil && result.stats.Errors > 0:
+		s.Status = summaryPartial
+		err = withExitCode(exitPartial, fmt.Errorf("run %s completed with %d errors", s.RunID, result.stats.Errors))
+	}
+	s.ExitCode = exitCode(err)
+	if err != nil {
+		s.Error = err.Error()
+	}
+	return s, err
+}
+
+// printSummary writes s as one line of JSON to stdout. It stays silent
+// when the singer backend owns stdout.
+func printSummary(cfg *config.Config, s runSummary) {
+	if cfg.StorageBackend == "singer" && !cfg.DryRun {
+		return
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		log.Printf("Failed to encode run summary: %v", err)
+		return
+	}
+	fmt.Fprintln(stdout, string(data))
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/config"
+	"github.com/ioanzicu/asana-extractor/pkg/extractor"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "Success", err: nil, want: exitOK},
+		{name: "Plain error", err: errors.New("boom"), want: exitFailure},
+		{name: "Tagged error", err: withExitCode(exitConfig, errors.New("bad")), want: exitConfig},
+		{name: "Wrapped tagged error", err: fmt.Errorf("serve: %w", withExitCode(exitRunFailed, errors.New("401"))), want: exitRunFailed},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := exitCode(tc.err); got != tc.want {
+				t.Errorf("exitCode() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+
+	if withExitCode(exitConfig, nil) != nil {
+		t.Error("Expected withExitCode to keep nil errors nil")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	cfg := &config.Config{OutputDirectory: "/data/out"}
+	tests := []struct {
+		name       string
+		result     runResult
+		err        error
+		wantStatus string
+		wantCode   int
+	}{
+		{
+			name:       "Clean run",
+			result:     runResult{stats: &extractor.Stats{RunID: "r1", UsersExtracted: 3}, snapshot: "/data/out/20240102T030405Z"},
+			wantStatus: summarySucceeded,
+			wantCode:   exitOK,
+		},
+		{
+			name:       "Run with entity errors",
+			result:     runResult{stats: &extractor.Stats{RunID: "r1", UsersExtracted: 3, Errors: 1}},
+			wantStatus: summaryPartial,
+			wantCode:   exitPartial,
+		},
+		{
+			name:       "Run stopped by the API call limit",
+			result:     runResult{stats: &extractor.Stats{RunID: "r1", UsersExtracted: 3, APIBudgetExhausted: true}},
+			wantStatus: summaryPartial,
+			wantCode:   exitPartial,
+		},
+		{
+			name:       "Failed run",
+			result:     runResult{stats: &extractor.Stats{RunID: "r1"}},
+			err:        withExitCode(exitRunFailed, errors.New("user API failure")),
+			wantStatus: summaryFailed,
+			wantCode:   exitRunFailed,
+		},
+		{
+			name:       "Setup failure",
+			err:        withExitCode(exitConfig, errors.New("bad CA file")),
+			wantStatus: summaryFailed,
+			wantCode:   exitConfig,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := summarize(cfg, tc.result, tc.err)
+			if s.Status != tc.wantStatus || s.ExitCode != tc.wantCode || exitCode(err) != tc.wantCode {
+				t.Errorf("summarize() = %s/%d (err %v), want %s/%d", s.Status, s.ExitCode, err, tc.wantStatus, tc.wantCode)
+			}
+			if (err != nil) != (s.Error != "") {
+				t.Errorf("Summary error %q does not match %v", s.Error, err)
+			}
+			if s.OutputDir != "/data/out" || s.Snapshot != tc.result.snapshot {
+				t.Errorf("Unexpected paths %q %q", s.OutputDir, s.Snapshot)
+			}
+		})
+	}
+}
+
+func TestPrintSummary(t *testing.T) {
+	var buf bytes.Buffer
+	orig := stdout
+	stdout = &buf
+	defer func() { stdout = orig }()
+
+	s := runSummary{RunID: "r1", Status: summarySucceeded, OutputDir: "out", Stats: &extractor.Stats{RunID: "r1", TasksExtracted: 2}}
+	printSummary(&config.Config{StorageBackend: "json"}, s)
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Summary is not JSON: %v: %q", err, buf.String())
+	}
+	if got["run_id"] != "r1" || got["status"] != "succeeded" || got["stats"].(map[string]any)["tasks_extracted"] != 2.0 {
+		t.Errorf("Unexpected summary %v", got)
+	}
+
+	buf.Reset()
+	printSummary(&config.Config{StorageBackend: "singer"}, s)
+	if buf.Len() != 0 {
+		t.Errorf("Expected no summary while singer owns stdout, got %q", buf.String())
+	}
+}
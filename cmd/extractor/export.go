@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/ioanzicu/asana-extractor/pkg/config"
+	"github.com/ioanzicu/asana-extractor/pkg/export"
+	"github.com/ioanzicu/asana-extractor/pkg/storage"
+)
+
+// newExporters returns the post-run exporters enabled in config
+func newExporters(cfg *config.Config) []export.Exporter {
+	if cfg.DryRun {
+		return nil
+	}
+
+	var exporters []export.Exporter
+	if cfg.DuckDBPath != "" {
+		exporters = append(exporters, export.DuckDB{Path: cfg.DuckDBPath, Binary: cfg.DuckDBBinary})
+	}
+	if cfg.XLSXDir != "" {
+		exporters = append(exporters, export.XLSX{Dir: cfg.XLSXDir, Hook: cfg.XLSXHook})
+	}
+	if cfg.SheetsID != "" {
+		exporters = append(exporters, export.GoogleSheets{SpreadsheetID: cfg.SheetsID, CredentialsFile: cfg.SheetsCredentials})
+	}
+	return exporters
+}
+
+// runExporters hands the output of a successful run in dir to every
+// exporter. The run is already stored, so failures are logged, not returned.
+func runExporters(ctx context.Context, exporters []export.Exporter, dir string, opts storage.Options, runID string) {
+	if len(exporters) == 0 {
+		return
+	}
+
+	src, err := storage.OpenJSONStorage(dir, opts)
+	if err != nil {
+		log.Printf("Skipping exports of run %s: %v", runID, err)
+		return
+	}
+	for _, e := range exporters {
+		if err := e.Export(ctx, src, runID); err != nil {
+			log.Printf("Export %s of run %s failed: %v", e.Name(), runID, err)
+			continue
+		}
+		log.Printf("Exported run %s to %s", runID, e.Name())
+	}
+}
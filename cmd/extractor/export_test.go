@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+	"github.com/ioanzicu/asana-extractor/pkg/config"
+	"github.com/ioanzicu/asana-extractor/pkg/export"
+	"github.com/ioanzicu/asana-extractor/pkg/storage"
+)
+
+// recordingExporter counts the users it was handed
+type recordingExporter struct {
+	users int
+	runID string
+	err   error
+}
+
+func (r *recordingExporter) Name() string { return "recording" }
+
+func (r *recordingExporter) Export(ctx context.Context, src export.Source, runID string) error {
+	r.runID = runID
+	src.EachUser(func(asana.User) error { r.users++; return nil })
+	return r.err
+}
+
+func TestRunExporters(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.WriteUser(asana.User{GID: "u1"})
+	store.WriteUser(asana.User{GID: "u2"})
+
+	failing := &recordingExporter{err: errors.New("disk full")}
+	ok := &recordingExporter{}
+	runExporters(context.Background(), []export.Exporter{failing, ok}, dir, storage.Options{}, "run-1")
+
+	if ok.users != 2 || ok.runID != "run-1" {
+		t.Errorf("Expected the second exporter to see 2 users of run-1 after the first failed, got %d of %q", ok.users, ok.runID)
+	}
+}
+
+func TestNewExporters(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want int
+	}{
+		{name: "None configured", cfg: config.Config{}, want: 0},
+		{name: "DuckDB", cfg: config.Config{DuckDBPath: "out.duckdb"}, want: 1},
+		{name: "DuckDB and Excel", cfg: config.Config{DuckDBPath: "out.duckdb", XLSXDir: "reports"}, want: 2},
+		{name: "Google Sheets", cfg: config.Config{SheetsID: "1AbC", SheetsCredentials: "sa.json"}, want: 1},
+		{name: "Dry run exports nothing", cfg: config.Config{DuckDBPath: "out.duckdb", DryRun: true}, want: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := newExporters(&tc.cfg); len(got) != tc.want {
+				t.Errorf("newExporters() returned %d exporters, want %d", len(got), tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// scheduleStatus reports the scheduler's state; implemented by
+// *scheduler.CronScheduler
+type scheduleStatus interface {
+	NextRun() time.Time
+	LastRun() time.Time
+	IsRunning() bool
+}
+
+// healthResponse is the body of a GET /healthz answer. Times are omitted
+// until known.
+type healthResponse struct {
+	Status  string    `json:"status"`
+	Running bool      `json:"running"`
+	LastRun time.Time `json:"last_run,omitzero"`
+	NextRun time.Time `json:"next_run,omitzero"`
+}
+
+// healthHandler serves GET /healthz with when the last extraction started,
+// when the next one fires and whether one is running
+func healthHandler(sched scheduleStatus) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(healthResponse{
+			Status:  "ok",
+			Running: sched.IsRunning(),
+			LastRun: sched.LastRun(),
+			NextRun: sched.NextRun(),
+		})
+	})
+}
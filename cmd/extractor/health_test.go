@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeStatus is a fixed scheduleStatus
+type fakeStatus struct {
+	next, last time.Time
+	running    bool
+}
+
+func (f fakeStatus) NextRun() time.Time { return f.next }
+func (f fakeStatus) LastRun() time.Time { return f.last }
+func (f fakeStatus) IsRunning() bool    { return f.running }
+
+func TestHealthHandler(t *testing.T) {
+	next := time.Date(2026, 1, 1, 0, 5, 0, 0, time.UTC)
+	last := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		method       string
+		status       fakeStatus
+		expectStatus int
+		expectBody   string
+	}{
+		{name: "Before First Run", method: http.MethodGet, status: fakeStatus{next: next}, expectStatus: http.StatusOK, expectBody: `{"status":"ok","running":false,"next_run":"2026-01-01T00:05:00Z"}`},
+		{name: "Running", method: http.MethodGet, status: fakeStatus{next: next, last: last, running: true}, expectStatus: http.StatusOK, expectBody: `{"status":"ok","running":true,"last_run":"2026-01-01T00:00:00Z","next_run":"2026-01-01T00:05:00Z"}`},
+		{name: "Wrong Method", method: http.MethodPost, expectStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			healthHandler(tt.status).ServeHTTP(rec, httptest.NewRequest(tt.method, "/healthz", nil))
+
+			if rec.Code != tt.expectStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectStatus, rec.Code)
+			}
+			if tt.expectBody == "" {
+				return
+			}
+
+			var got, want healthResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			json.Unmarshal([]byte(tt.expectBody), &want)
+			if got != want {
+				t.Errorf("expected %s, got %s", tt.expectBody, rec.Body.String())
+			}
+		})
+	}
+}
@@ -4,14 +4,27 @@ import (
 	"context"
 	"testing"
 	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asanatest"
 )
 
 func TestRun_Table(t *testing.T) {
+	// Fake Asana API without any entities
+	okServer := asanatest.NewServer(asanatest.Fixtures{})
+	defer okServer.Close()
+
+	// Fake Asana API that rejects the test token
+	failServer := asanatest.NewServer(asanatest.Fixtures{Token: "other-token"})
+	defer failServer.Close()
+
 	tests := []struct {
 		name        string
 		envVars     map[string]string
+		args        []string
 		timeout     time.Duration
 		expectError bool
+		// wantCode is the expected exit code of a failed run
+		wantCode int
 	}{
 		{
 			name: "Missing Asana Token",
@@ -20,6 +33,7 @@ func TestRun_Table(t *testing.T) {
 				"ASANA_WORKSPACE": "123",
 			},
 			expectError: true,
+			wantCode:    exitConfig,
 		},
 		{
 			name: "Invalid Cron Expression",
@@ -27,6 +41,8 @@ func TestRun_Table(t *testing.T) {
 				"ASANA_TOKEN":     "valid-token",
 				"ASANA_WORKSPACE": "123",
 				"SCHEDULE_CRON":   "invalid-cron",
+				"BASE_URL":        okServer.URL,
+				"OUTPUT_DIR":      t.TempDir(),
 			},
 			expectError: true,
 		},
@@ -36,11 +52,45 @@ func TestRun_Table(t *testing.T) {
 				"ASANA_TOKEN":     "valid-token",
 				"ASANA_WORKSPACE": "123",
 				"SCHEDULE_CRON":   "0 0 0 1 1 *", // Jan 1st
-				"OUTPUT_DIR":      t.TempDir(),   // Use temp dir for tests
+				"BASE_URL":        okServer.URL,
+				"OUTPUT_DIR":      t.TempDir(), // Use temp dir for tests
 			},
 			timeout:     200 * time.Millisecond,
 			expectError: false,
 		},
+		{
+			name: "Run once via flag succeeds and returns",
+			envVars: map[string]string{
+				"ASANA_TOKEN":     "valid-token",
+				"ASANA_WORKSPACE": "123",
+				"BASE_URL":        okServer.URL,
+				"OUTPUT_DIR":      t.TempDir(),
+			},
+			args:        []string{"--once"},
+			expectError: false,
+		},
+		{
+			name: "Run once via env reports extraction failure",
+			envVars: map[string]string{
+				"ASANA_TOKEN":     "valid-token",
+				"ASANA_WORKSPACE": "123",
+				"BASE_URL":        failServer.URL,
+				"OUTPUT_DIR":      t.TempDir(),
+				"RUN_ONCE":        "true",
+			},
+			expectError: true,
+			wantCode:    exitRunFailed,
+		},
+		{
+			name: "Unknown flag is rejected",
+			envVars: map[string]string{
+				"ASANA_TOKEN":     "valid-token",
+				"ASANA_WORKSPACE": "123",
+			},
+			args:        []string{"--no-such-flag"},
+			expectError: true,
+			wantCode:    exitConfig,
+		},
 	}
 
 	for _, tc := range tests {
@@ -62,13 +112,16 @@ func TestRun_Table(t *testing.T) {
 			defer cancel()
 
 			// 3. Execute run()
-			err := run(ctx)
+			err := run(ctx, tc.args)
 
 			// 4. Assertions
 			if tc.expectError {
 				if err == nil {
 					t.Error("expected an error but got nil")
 				}
+				if tc.wantCode != 0 && exitCode(err) != tc.wantCode {
+					t.Errorf("exit code = %d, want %d", exitCode(err), tc.wantCode)
+				}
 			} else {
 				// For the success path, we expect a timeout or cancel error
 				// because run() blocks on the scheduler.
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/client"
+	"github.com/ioanzicu/asana-extractor/pkg/ratelimit"
+	"github.com/ioanzicu/asana-extractor/pkg/retry"
+)
+
+// rateLimiters holds one limiter per workspace, shared by every client of
+// the process and published in the ratelimit expvar
+var rateLimiters = ratelimit.NewRegistry()
+
+func init() {
+	expvar.Publish("ratelimit", expvar.Func(func() any {
+		return rateLimiters.Stats()
+	}))
+}
+
+// clientLatencies holds the latency and status histograms of every API
+// endpoint, shared by every client of the process and published in the
+// client_latency expvar
+var clientLatencies = client.NewLatencyHistograms()
+
+func init() {
+	expvar.Publish("client_latency", expvar.Func(func() any {
+		return clientLatencies.Snapshot()
+	}))
+}
+
+// clientRetries counts Asana API retries by status code, with "error" for
+// network failures
+var clientRetries = expvar.NewMap("client_retries")
+
+// observeRetry is the client's retry.Config.OnRetry hook: it counts the
+// retry in client_retries and logs it
+func observeRetry(a retry.Attempt) {
+	if a.Err != nil {
+		clientRetries.Add("error", 1)
+		log.Printf("Retrying Asana request after attempt %d in %v: %v", a.Number, a.Delay, a.Err)
+		return
+	}
+	clientRetries.Add(strconv.Itoa(a.Status), 1)
+	log.Printf("Retrying Asana request after attempt %d in %v: status %d", a.Number, a.Delay, a.Status)
+}
+
+// startMetricsServer serves the expvar metrics, including the last run's
+// report (extractor_last_run), on /debug/vars, plus handlers by path. The
+// returned function stops the server.
+func startMetricsServer(addr string, handlers map[string]http.Handler) (func(), error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	for path, handler := range handlers {
+		mux.Handle(path, handler)
+	}
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Metrics server error: %v", err)
+		}
+	}()
+	log.Printf("Metrics available on http://%s/debug/vars", listener.Addr())
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("Failed to stop metrics server: %v", err)
+		}
+	}, nil
+}
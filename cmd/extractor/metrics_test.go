@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/ratelimit"
+	"github.com/ioanzicu/asana-extractor/pkg/retry"
+)
+
+func TestStartMetricsServer(t *testing.T) {
+	stop, err := startMetricsServer("127.0.0.1:0", nil)
+	if err != nil {
+		t.Fatalf("startMetricsServer() failed: %v", err)
+	}
+	defer stop()
+
+	if _, err := startMetricsServer("127.0.0.1:-1", nil); err == nil {
+		t.Error("expected error for an invalid address")
+	}
+
+	for _, name := range []string{"extractor_last_run", "scheduler_skipped_runs", "client_retries", "client_latency", "ratelimit"} {
+		if expvar.Get(name) == nil {
+			t.Errorf("expected %s to be published", name)
+		}
+	}
+}
+
+func TestObserveRetry(t *testing.T) {
+	tests := []struct {
+		name    string
+		attempt retry.Attempt
+		key     string
+	}{
+		{name: "Status", attempt: retry.Attempt{Number: 1, Status: 503}, key: "503"},
+		{name: "Network Error", attempt: retry.Attempt{Number: 2, Err: errors.New("reset")}, key: "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var before int64
+			if v, ok := clientRetries.Get(tt.key).(*expvar.Int); ok {
+				before = v.Value()
+			}
+
+			observeRetry(tt.attempt)
+
+			v, ok := clientRetries.Get(tt.key).(*expvar.Int)
+			if !ok || v.Value() != before+1 {
+				t.Errorf("expected client_retries[%s] to be incremented", tt.key)
+			}
+		})
+	}
+}
+
+func TestRateLimiterExpvar(t *testing.T) {
+	rateLimiters.Get("test", ratelimit.Config{RequestsPerMinute: 60})
+
+	var published map[string]ratelimit.Stats
+	if err := json.Unmarshal([]byte(expvar.Get("ratelimit").String()), &published); err != nil {
+		t.Fatalf("failed to decode ratelimit expvar: %v", err)
+	}
+	stats, ok := published["test"]
+	if !ok {
+		t.Fatalf("expected the test client in %v", published)
+	}
+	if stats.TokensAvailable != ratelimit.DefaultBurst {
+		t.Errorf("expected a full bucket of %d tokens, got %v", ratelimit.DefaultBurst, stats.TokensAvailable)
+	}
+}
@@ -0,0 +1,4 @@
+package main
+
+// The MySQL driver of STORAGE_BACKEND=mysql
+import _ "github.com/go-sql-driver/mysql"
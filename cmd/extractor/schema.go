@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+	"github.com/ioanzicu/asana-extractor/pkg/avro"
+	"github.com/ioanzicu/asana-extractor/pkg/jsonschema"
+	"github.com/ioanzicu/asana-extractor/pkg/protobuf"
+	"github.com/ioanzicu/asana-extractor/pkg/storage"
+)
+
+// SchemasDir is the directory of the output root the schema command writes
+// to by default
+const SchemasDir = "schemas"
+
+// schemaResources lists the stored resources with the type of their
+// records. Audit log events are appended to logs, so they are neither
+// tombstoned nor wrapped in an envelope.
+var schemaResources = []struct {
+	name   string
+	record any
+	// entity is set for resources with one file per entity
+	entity bool
+	// avro is set for resources the avro and protobuf backends store
+	avro bool
+}{
+	{name: "users", record: asana.User{}, entity: true, avro: true},
+	{name: "projects", record: asana.Project{}, entity: true, avro: true},
+	{name: "tasks", record: asana.Task{}, entity: true, avro: true},
+	{name: "teams", record: asana.Team{}, entity: true, avro: true},
+	{name: "user_task_lists", record: asana.UserTaskList{}, entity: true},
+	{name: "status_updates", record: asana.StatusUpdate{}, entity: true},
+	{name: "custom_fields", record: asana.CustomField{}, entity: true},
+	{name: "audit_log_events", record: asana.AuditLogEvent{}},
+}
+
+// runSchema writes the schema of every stored resource, generated from the
+// pkg/asana types, so downstream consumers can generate their parsers
+func runSchema(ctx context.Context, args []string) error {
+	flags, cfgFlags := newFlagSet("schema")
+	format := flags.String("format", "json", "schema format: json (JSON Schema), avro or proto")
+	out := flags.String("out", "", "directory to write the schemas to (default: OUTPUT_DIR/"+SchemasDir+")")
+	if err := flags.Parse(args); err != nil {
+		return withExitCode(exitConfig, err)
+	}
+	if *format != "json" && *format != "avro" && *format != "proto" {
+		return withExitCode(exitConfig, fmt.Errorf("--format must be json, avro or proto (got %q)", *format))
+	}
+
+	cfg, err := loadConfig(cfgFlags)
+	if err != nil {
+		return err
+	}
+	dir := *out
+	if dir == "" {
+		dir = filepath.Join(cfg.OutputDirectory, SchemasDir)
+	}
+
+	files, err := generateSchemas(*format, cfg.OutputEnvelope)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create schema directory: %w", err)
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return fmt.Errorf("failed to write schema %s: %w", name, err)
+		}
+	}
+	fmt.Fprintf(stdout, "%d schemas written to %s\n", len(files), dir)
+	return nil
+}
+
+// generateSchemas returns the schema files of every resource the format
+// applies to, keyed by file name: <resource>.schema.json for JSON Schema,
+// describing entity files in an envelope when enabled, <resource>.avsc for
+// the avro backend's records, or a single asana.proto for the protobuf
+// backend's
+func generateSchemas(format string, envelope bool) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	var codecs []*protobuf.Codec
+	for _, r := range schemaResources {
+		var name string
+		var data []byte
+		var err error
+		switch format {
+		case "avro":
+			if !r.avro {
+				continue
+			}
+			var codec *avro.Codec
+			if codec, err = avro.NewCodec(r.record, storage.RunIDField); err == nil {
+				name, data = r.name+".avsc", []byte(codec.Schema())
+			}
+		case "proto":
+			if !r.avro {
+				continue
+			}
+			var codec *protobuf.Codec
+			if codec, err = protobuf.NewCodec(r.record, storage.RunIDField); err == nil {
+				codecs = append(codecs, codec)
+				continue
+			}
+		default:
+			var schema *jsonschema.Schema
+			if schema, err = resourceSchema(r.name, r.record, r.entity, envelope); err == nil {
+				name = r.name + ".schema.json"
+				data, err = json.MarshalIndent(schema, "", "  ")
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate %s schema: %w", r.name, err)
+		}
+		files[name] = data
+	}
+	if len(codecs) > 0 {
+		files[storage.ProtoFile] = []byte(protobuf.Proto(codecs...))
+	}
+	return files, nil
+}
+
+// resourceSchema returns the JSON Schema of one stored record of a
+// resource. An entity file holds the entity or its tombstone, in an
+// envelope when enabled.
+func resourceSchema(resource string, record any, entity, envelope bool) (*jsonschema.Schema, error) {
+	root, err := jsonschema.Generate(record)
+	if err != nil {
+		return nil, err
+	}
+	root.ID = resource + ".schema.json"
+	if !entity {
+		return root, nil
+	}
+
+	tombstone, err := jsonschema.Generate(storage.Tombstone{})
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(root.Defs, tombstone.Defs)
+	data := &jsonschema.Schema{AnyOf: []*jsonschema.Schema{{Ref: root.Ref}, {Ref: tombstone.Ref}}}
+	root.Ref = ""
+	if !envelope {
+		root.AnyOf = data.AnyOf
+		return root, nil
+	}
+
+	wrapper, err := jsonschema.Generate(storage.Envelope{})
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(root.Defs, wrapper.Defs)
+	root.Defs["Envelope"].Properties["data"] = data
+	root.Ref = wrapper.Ref
+	return root, nil
+}
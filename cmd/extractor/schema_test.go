@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		file     string
+		want     int
+		contains string
+	}{
+		{name: "JSON Schema", file: "tasks.schema.json", want: 8, contains: `"$ref": "#/$defs/Tombstone"`},
+		{name: "Envelope", args: []string{"--output-envelope"}, file: "tasks.schema.json", want: 8, contains: `"schema_version"`},
+		{name: "Avro", args: []string{"--format", "avro"}, file: "tasks.avsc", want: 4, contains: `"name":"run_id"`},
+		{name: "Protobuf", args: []string{"--format", "proto"}, file: "asana.proto", want: 1, contains: `message TaskRecord {`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			var out bytes.Buffer
+			stdout = &out
+			defer func() { stdout = os.Stdout }()
+
+			args := append([]string{"--token", "x", "--workspace", "ws", "--output-dir", dir}, tc.args...)
+			if err := runSchema(context.Background(), args); err != nil {
+				t.Fatalf("runSchema() error = %v", err)
+			}
+
+			files, _ := os.ReadDir(filepath.Join(dir, SchemasDir))
+			if len(files) != tc.want {
+				t.Errorf("Expected %d schemas, got %d", tc.want, len(files))
+			}
+			data, err := os.ReadFile(filepath.Join(dir, SchemasDir, tc.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.file != "asana.proto" && !json.Valid(data) || !strings.Contains(string(data), tc.contains) {
+				t.Errorf("Expected %s to contain %s, got %s", tc.file, tc.contains, data)
+			}
+		})
+	}
+
+	if err := runSchema(context.Background(), []string{"--format", "proto"}); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestResourceSchema(t *testing.T) {
+	files, err := generateSchemas("json", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(files["audit_log_events.schema.json"], &schema); err != nil {
+		t.Fatal(err)
+	}
+	if schema["$ref"] != "#/$defs/AuditLogEvent" || schema["anyOf"] != nil {
+		t.Errorf("Expected audit log events without tombstones, got %v", schema)
+	}
+}
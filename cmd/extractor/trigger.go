@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/ioanzicu/asana-extractor/pkg/extractor"
+	"github.com/ioanzicu/asana-extractor/pkg/scheduler"
+)
+
+// triggerer starts out-of-schedule runs; implemented by *scheduler.CronScheduler
+type triggerer interface {
+	Trigger(ctx context.Context, name string) error
+}
+
+// triggerRun starts an on-demand run of job and returns its run ID
+func triggerRun(ctx context.Context, sched triggerer, job string) (string, error) {
+	runID := extractor.NewRunID()
+	if err := sched.Trigger(extractor.WithRunID(ctx, runID), job); err != nil {
+		return "", err
+	}
+	log.Printf("Triggered job %s (run %s)", job, runID)
+	return runID, nil
+}
+
+// triggerResponse is the body of a POST /trigger answer
+type triggerResponse struct {
+	Job   string `json:"job"`
+	RunID string `json:"run_id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// triggerHandler serves POST /trigger, starting a run of the job named by
+// the job query parameter (default "default"). It answers 202 with the run
+// ID, or 409 when the overlap policy skipped the run.
+func triggerHandler(sched triggerer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		job := r.URL.Query().Get("job")
+		if job == "" {
+			job = "default"
+		}
+
+		resp := triggerResponse{Job: job}
+		status := http.StatusAccepted
+		runID, err := triggerRun(r.Context(), sched, job)
+		switch {
+		case err == nil:
+			resp.RunID = runID
+		case errors.Is(err, scheduler.ErrUnknownJob):
+			status = http.StatusNotFound
+		case errors.Is(err, scheduler.ErrRunSkipped):
+			status = http.StatusConflict
+		default:
+			status = http.StatusServiceUnavailable
+		}
+		if err != nil {
+			resp.Error = err.Error()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(resp)
+	})
+}
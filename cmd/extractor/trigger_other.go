@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+import "context"
+
+// triggerOnSignal is a no-op where SIGUSR1 does not exist; use POST /trigger
+func triggerOnSignal(ctx context.Context, sched triggerer) {}
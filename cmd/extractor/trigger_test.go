@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/scheduler"
+)
+
+// fakeTriggerer records triggered jobs and fails with err
+type fakeTriggerer struct {
+	jobs []string
+	err  error
+}
+
+func (f *fakeTriggerer) Trigger(ctx context.Context, name string) error {
+	f.jobs = append(f.jobs, name)
+	return f.err
+}
+
+func TestTriggerHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		err          error
+		expectStatus int
+		expectJob    string
+		expectRunID  bool
+	}{
+		{name: "Default Job", method: http.MethodPost, target: "/trigger", expectStatus: http.StatusAccepted, expectJob: "default", expectRunID: true},
+		{name: "Named Job", method: http.MethodPost, target: "/trigger?job=tasks", expectStatus: http.StatusAccepted, expectJob: "tasks", expectRunID: true},
+		{name: "Skipped By Overlap Policy", method: http.MethodPost, target: "/trigger", err: scheduler.ErrRunSkipped, expectStatus: http.StatusConflict, expectJob: "default"},
+		{name: "Unknown Job", method: http.MethodPost, target: "/trigger?job=goals", err: fmt.Errorf("%w: goals", scheduler.ErrUnknownJob), expectStatus: http.StatusNotFound, expectJob: "goals"},
+		{name: "Shutting Down", method: http.MethodPost, target: "/trigger", err: scheduler.ErrNotRunning, expectStatus: http.StatusServiceUnavailable, expectJob: "default"},
+		{name: "Wrong Method", method: http.MethodGet, target: "/trigger", expectStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sched := &fakeTriggerer{err: tt.err}
+			rec := httptest.NewRecorder()
+			triggerHandler(sched).ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+
+			if rec.Code != tt.expectStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectStatus, rec.Code, rec.Body)
+			}
+			if tt.expectJob == "" {
+				if len(sched.jobs) != 0 {
+					t.Errorf("expected no trigger, got %v", sched.jobs)
+				}
+				return
+			}
+
+			var resp triggerResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Job != tt.expectJob || len(sched.jobs) != 1 || sched.jobs[0] != tt.expectJob {
+				t.Errorf("expected job %s to be triggered, got %+v (triggered %v)", tt.expectJob, resp, sched.jobs)
+			}
+			if (resp.RunID != "") != tt.expectRunID {
+				t.Errorf("expected run ID %v, got %q", tt.expectRunID, resp.RunID)
+			}
+			if tt.err != nil && resp.Error == "" {
+				t.Error("expected the error in the response")
+			}
+		})
+	}
+}
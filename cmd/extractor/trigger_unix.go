@@ -0,0 +1,30 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// triggerOnSignal starts a run of the default job on every SIGUSR1 until
+// ctx is done
+func triggerOnSignal(ctx context.Context, sched triggerer) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			if _, err := triggerRun(ctx, sched, "default"); err != nil {
+				log.Printf("SIGUSR1 trigger failed: %v", err)
+			}
+		}
+	}
+}
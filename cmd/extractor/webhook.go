@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+	"github.com/ioanzicu/asana-extractor/pkg/config"
+	"github.com/ioanzicu/asana-extractor/pkg/webhook"
+)
+
+// webhookFilters are the event types requested for the workspace webhook.
+// Asana does not deliver task events at workspace level; tasks are picked up
+// through their project's events and the regular schedule.
+var webhookFilters = []asana.WebhookFilter{
+	{ResourceType: "project"},
+	{ResourceType: "team"},
+}
+
+// startWebhooks starts the webhook receiver and registers it with Asana.
+// The returned function unregisters the webhook and stops the server.
+func startWebhooks(ctx context.Context, cfg *config.Config, trigger func(resources []string)) (func(), error) {
+	receiver := webhook.NewReceiver(webhook.Config{
+		Trigger:  trigger,
+		Debounce: cfg.WebhookDebounce,
+	})
+
+	// Listen before registering: Asana calls the target during registration
+	listener, err := net.Listen("tcp", cfg.WebhookListenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", cfg.WebhookListenAddr, err)
+	}
+
+	server := &http.Server{Handler: receiver, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Webhook server error: %v", err)
+		}
+	}()
+	log.Printf("Webhook receiver listening on %s", listener.Addr())
+
+	asanaClient, err := newAsanaClient(cfg)
+	if err != nil {
+		server.Close()
+		receiver.Close()
+		return nil, err
+	}
+	var hook *asana.Webhook
+	err = receiver.Register(func() error {
+		hook, err = asanaClient.CreateWebhook(ctx, cfg.WebhookTargetURL, webhookFilters)
+		return err
+	})
+	if err != nil {
+		server.Close()
+		receiver.Close()
+		return nil, err
+	}
+	log.Printf("Registered webhook %s for %s", hook.GID, cfg.WebhookTargetURL)
+
+	return func() {
+		// The serve context is already cancelled; give cleanup its own deadline
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := asanaClient.DeleteWebhook(cleanupCtx, hook.GID); err != nil {
+			log.Printf("Failed to delete webhook %s: %v", hook.GID, err)
+		}
+		receiver.Close()
+		if err := server.Shutdown(cleanupCtx); err != nil {
+			log.Printf("Failed to stop webhook server: %v", err)
+		}
+	}, nil
+}
@@ -0,0 +1,118 @@
+package asana
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// The interfaces below each cover one kind of resource, so consumers can
+// depend on only what they call. API combines them; *Client implements it.
+// Stubs in package asanamock are generated from this file.
+
+// UserReader lists the workspace's users
+type UserReader interface {
+	GetUsers(ctx context.Context, limit int, offset string) ([]User, *NextPage, error)
+	GetAllUsers(ctx context.Context) ([]User, error)
+	StreamUsers(ctx context.Context, fn func(User) error) error
+}
+
+// ProjectReader lists the workspace's projects and counts their tasks
+type ProjectReader interface {
+	GetProjects(ctx context.Context, limit int, offset string) ([]Project, *NextPage, error)
+	GetAllProjects(ctx context.Context) ([]Project, error)
+	StreamProjects(ctx context.Context, fn func(Project) error) error
+	GetProjectTaskCount(ctx context.Context, projectGID string) (int, error)
+}
+
+// TaskReader lists the tasks of a project, all of them or those modified
+// since a time
+type TaskReader interface {
+	GetTasks(ctx context.Context, projectGID string, limit int, offset string) ([]Task, *NextPage, error)
+	StreamTasks(ctx context.Context, projectGID string, fn func(Task) error) error
+	GetTasksModifiedSince(ctx context.Context, projectGID string, since time.Time, limit int, offset string) ([]Task, *NextPage, error)
+	StreamTasksModifiedSince(ctx context.Context, projectGID string, since time.Time, fn func(Task) error) error
+}
+
+// TeamReader lists the workspace's teams
+type TeamReader interface {
+	GetTeams(ctx context.Context, limit int, offset string) ([]Team, *NextPage, error)
+	StreamTeams(ctx context.Context, fn func(Team) error) error
+}
+
+// WorkspaceReader lists the workspaces visible to the token
+type WorkspaceReader interface {
+	GetWorkspaces(ctx context.Context, limit int, offset string) ([]Workspace, *NextPage, error)
+	GetAllWorkspaces(ctx context.Context) ([]Workspace, error)
+}
+
+// UserTaskListReader reads users' My Tasks lists
+type UserTaskListReader interface {
+	GetUserTaskList(ctx context.Context, userGID string) (*UserTaskList, error)
+	GetUserTaskListTasks(ctx context.Context, listGID string, limit int, offset string) ([]Task, *NextPage, error)
+	StreamUserTaskListTasks(ctx context.Context, listGID string, fn func(Task) error) error
+}
+
+// StatusUpdateReader lists goals, portfolios and the status updates of a
+// project, goal or portfolio
+type StatusUpdateReader interface {
+	GetGoals(ctx context.Context, limit int, offset string) ([]Goal, *NextPage, error)
+	StreamGoals(ctx context.Context, fn func(Goal) error) error
+	GetPortfolios(ctx context.Context, limit int, offset string) ([]Portfolio, *NextPage, error)
+	StreamPortfolios(ctx context.Context, fn func(Portfolio) error) error
+	GetStatusUpdates(ctx context.Context, parentGID string, limit int, offset string) ([]StatusUpdate, *NextPage, error)
+	StreamStatusUpdates(ctx context.Context, parentGID string, fn func(StatusUpdate) error) error
+}
+
+// CustomFieldReader lists the workspace's custom field definitions
+type CustomFieldReader interface {
+	GetCustomFields(ctx context.Context, limit int, offset string) ([]CustomField, *NextPage, error)
+	StreamCustomFields(ctx context.Context, fn func(CustomField) error) error
+}
+
+// AuditLogReader lists an organization's audit log events
+type AuditLogReader interface {
+	GetAuditLogEvents(ctx context.Context, start, end time.Time, limit int, offset string) ([]AuditLogEvent, *NextPage, error)
+	StreamAuditLogEvents(ctx context.Context, start, end time.Time, fn func(AuditLogEvent) error) error
+}
+
+// EntityChecker checks whether single entities still exist
+type EntityChecker interface {
+	Exists(ctx context.Context, resource, gid string) (bool, error)
+}
+
+// PhotoDownloader downloads user photos
+type PhotoDownloader interface {
+	DownloadPhoto(ctx context.Context, photoURL string) ([]byte, error)
+}
+
+// AttachmentReader lists the attachments of tasks and downloads their files
+type AttachmentReader interface {
+	StreamAttachments(ctx context.Context, parentGID string, fn func(Attachment) error) error
+	DownloadAttachment(ctx context.Context, downloadURL string) (io.ReadCloser, error)
+}
+
+// WebhookManager creates and deletes webhooks
+type WebhookManager interface {
+	CreateWebhook(ctx context.Context, target string, filters []WebhookFilter) (*Webhook, error)
+	DeleteWebhook(ctx context.Context, gid string) error
+}
+
+// API is the whole Asana client surface
+type API interface {
+	UserReader
+	ProjectReader
+	TaskReader
+	TeamReader
+	WorkspaceReader
+	UserTaskListReader
+	StatusUpdateReader
+	CustomFieldReader
+	AuditLogReader
+	EntityChecker
+	PhotoDownloader
+	AttachmentReader
+	WebhookManager
+}
+
+var _ API = (*Client)(nil)
@@ -0,0 +1,36 @@
+// Package asanamock provides Client, a stub of asana.API for tests of code
+// that consumes the Asana client. Client is generated from the interfaces
+// in pkg/asana/api.go; run go generate after changing them.
+package asanamock
+
+//go:generate go run ./internal/gen -src ../api.go -out client.go
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrNotStubbed is returned by Client methods whose function field is nil
+var ErrNotStubbed = errors.New("asanamock: method not stubbed")
+
+// counter counts calls by method name
+type counter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (c *counter) add(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[string]int)
+	}
+	c.counts[name]++
+}
+
+// Calls returns how many times the named method was called
+func (m *Client) Calls(name string) int {
+	m.calls.mu.Lock()
+	defer m.calls.mu.Unlock()
+	return m.calls.counts[name]
+}
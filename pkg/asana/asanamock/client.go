@@ -0,0 +1,394 @@
+// Code generated by asanamock/internal/gen from pkg/asana/api.go; DO NOT EDIT.
+
+package asanamock
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+var _ asana.API = (*Client)(nil)
+
+// Client stubs asana.API. Each method calls the function field named after
+// it, e.g. StreamUsersFunc, and counts the call. A method without one
+// returns zero values and ErrNotStubbed.
+type Client struct {
+	calls counter
+
+	GetUsersFunc                 func(ctx context.Context, limit int, offset string) ([]asana.User, *asana.NextPage, error)
+	GetAllUsersFunc              func(ctx context.Context) ([]asana.User, error)
+	StreamUsersFunc              func(ctx context.Context, fn func(asana.User) error) error
+	GetProjectsFunc              func(ctx context.Context, limit int, offset string) ([]asana.Project, *asana.NextPage, error)
+	GetAllProjectsFunc           func(ctx context.Context) ([]asana.Project, error)
+	StreamProjectsFunc           func(ctx context.Context, fn func(asana.Project) error) error
+	GetProjectTaskCountFunc      func(ctx context.Context, projectGID string) (int, error)
+	GetTasksFunc                 func(ctx context.Context, projectGID string, limit int, offset string) ([]asana.Task, *asana.NextPage, error)
+	StreamTasksFunc              func(ctx context.Context, projectGID string, fn func(asana.Task) error) error
+	GetTasksModifiedSinceFunc    func(ctx context.Context, projectGID string, since time.Time, limit int, offset string) ([]asana.Task, *asana.NextPage, error)
+	StreamTasksModifiedSinceFunc func(ctx context.Context, projectGID string, since time.Time, fn func(asana.Task) error) error
+	GetTeamsFunc                 func(ctx context.Context, limit int, offset string) ([]asana.Team, *asana.NextPage, error)
+	StreamTeamsFunc              func(ctx context.Context, fn func(asana.Team) error) error
+	GetWorkspacesFunc            func(ctx context.Context, limit int, offset string) ([]asana.Workspace, *asana.NextPage, error)
+	GetAllWorkspacesFunc         func(ctx context.Context) ([]asana.Workspace, error)
+	GetUserTaskListFunc          func(ctx context.Context, userGID string) (*asana.UserTaskList, error)
+	GetUserTaskListTasksFunc     func(ctx context.Context, listGID string, limit int, offset string) ([]asana.Task, *asana.NextPage, error)
+	StreamUserTaskListTasksFunc  func(ctx context.Context, listGID string, fn func(asana.Task) error) error
+	GetGoalsFunc                 func(ctx context.Context, limit int, offset string) ([]asana.Goal, *asana.NextPage, error)
+	StreamGoalsFunc              func(ctx context.Context, fn func(asana.Goal) error) error
+	GetPortfoliosFunc            func(ctx context.Context, limit int, offset string) ([]asana.Portfolio, *asana.NextPage, error)
+	StreamPortfoliosFunc         func(ctx context.Context, fn func(asana.Portfolio) error) error
+	GetStatusUpdatesFunc         func(ctx context.Context, parentGID string, limit int, offset string) ([]asana.StatusUpdate, *asana.NextPage, error)
+	StreamStatusUpdatesFunc      func(ctx context.Context, parentGID string, fn func(asana.StatusUpdate) error) error
+	GetCustomFieldsFunc          func(ctx context.Context, limit int, offset string) ([]asana.CustomField, *asana.NextPage, error)
+	StreamCustomFieldsFunc       func(ctx context.Context, fn func(asana.CustomField) error) error
+	GetAuditLogEventsFunc        func(ctx context.Context, start time.Time, end time.Time, limit int, offset string) ([]asana.AuditLogEvent, *asana.NextPage, error)
+	StreamAuditLogEventsFunc     func(ctx context.Context, start time.Time, end time.Time, fn func(asana.AuditLogEvent) error) error
+	ExistsFunc                   func(ctx context.Context, resource string, gid string) (bool, error)
+	DownloadPhotoFunc            func(ctx context.Context, photoURL string) ([]byte, error)
+	StreamAttachmentsFunc        func(ctx context.Context, parentGID string, fn func(asana.Attachment) error) error
+	DownloadAttachmentFunc       func(ctx context.Context, downloadURL string) (io.ReadCloser, error)
+	CreateWebhookFunc            func(ctx context.Context, target string, filters []asana.WebhookFilter) (*asana.Webhook, error)
+	DeleteWebhookFunc            func(ctx context.Context, gid string) error
+}
+
+// GetUsers calls GetUsersFunc
+func (m *Client) GetUsers(ctx context.Context, limit int, offset string) ([]asana.User, *asana.NextPage, error) {
+	m.calls.add("GetUsers")
+	if m.GetUsersFunc == nil {
+		var r0 []asana.User
+		var r1 *asana.NextPage
+		return r0, r1, ErrNotStubbed
+	}
+	return m.GetUsersFunc(ctx, limit, offset)
+}
+
+// GetAllUsers calls GetAllUsersFunc
+func (m *Client) GetAllUsers(ctx context.Context) ([]asana.User, error) {
+	m.calls.add("GetAllUsers")
+	if m.GetAllUsersFunc == nil {
+		var r0 []asana.User
+		return r0, ErrNotStubbed
+	}
+	return m.GetAllUsersFunc(ctx)
+}
+
+// StreamUsers calls StreamUsersFunc
+func (m *Client) StreamUsers(ctx context.Context, fn func(asana.User) error) error {
+	m.calls.add("StreamUsers")
+	if m.StreamUsersFunc == nil {
+		return ErrNotStubbed
+	}
+	return m.StreamUsersFunc(ctx, fn)
+}
+
+// GetProjects calls GetProjectsFunc
+func (m *Client) GetProjects(ctx context.Context, limit int, offset string) ([]asana.Project, *asana.NextPage, error) {
+	m.calls.add("GetProjects")
+	if m.GetProjectsFunc == nil {
+		var r0 []asana.Project
+		var r1 *asana.NextPage
+		return r0, r1, ErrNotStubbed
+	}
+	return m.GetProjectsFunc(ctx, limit, offset)
+}
+
+// GetAllProjects calls GetAllProjectsFunc
+func (m *Client) GetAllProjects(ctx context.Context) ([]asana.Project, error) {
+	m.calls.add("GetAllProjects")
+	if m.GetAllProjectsFunc == nil {
+		var r0 []asana.Project
+		return r0, ErrNotStubbed
+	}
+	return m.GetAllProjectsFunc(ctx)
+}
+
+// StreamProjects calls StreamProjectsFunc
+func (m *Client) StreamProjects(ctx context.Context, fn func(asana.Project) error) error {
+	m.calls.add("StreamProjects")
+	if m.StreamProjectsFunc == nil {
+		return ErrNotStubbed
+	}
+	return m.StreamProjectsFunc(ctx, fn)
+}
+
+// GetProjectTaskCount calls GetProjectTaskCountFunc
+func (m *Client) GetProjectTaskCount(ctx context.Context, projectGID string) (int, error) {
+	m.calls.add("GetProjectTaskCount")
+	if m.GetProjectTaskCountFunc == nil {
+		var r0 int
+		return r0, ErrNotStubbed
+	}
+	return m.GetProjectTaskCountFunc(ctx, projectGID)
+}
+
+// GetTasks calls GetTasksFunc
+func (m *Client) GetTasks(ctx context.Context, projectGID string, limit int, offset string) ([]asana.Task, *asana.NextPage, error) {
+	m.calls.add("GetTasks")
+	if m.GetTasksFunc == nil {
+		var r0 []asana.Task
+		var r1 *asana.NextPage
+		return r0, r1, ErrNotStubbed
+	}
+	return m.GetTasksFunc(ctx, projectGID, limit, offset)
+}
+
+// StreamTasks calls StreamTasksFunc
+func (m *Client) StreamTasks(ctx context.Context, projectGID string, fn func(asana.Task) error) error {
+	m.calls.add("StreamTasks")
+	if m.StreamTasksFunc == nil {
+		return ErrNotStubbed
+	}
+	return m.StreamTasksFunc(ctx, projectGID, fn)
+}
+
+// GetTasksModifiedSince calls GetTasksModifiedSinceFunc
+func (m *Client) GetTasksModifiedSince(ctx context.Context, projectGID string, since time.Time, limit int, offset string) ([]asana.Task, *asana.NextPage, error) {
+	m.calls.add("GetTasksModifiedSince")
+	if m.GetTasksModifiedSinceFunc == nil {
+		var r0 []asana.Task
+		var r1 *asana.NextPage
+		return r0, r1, ErrNotStubbed
+	}
+	return m.GetTasksModifiedSinceFunc(ctx, projectGID, since, limit, offset)
+}
+
+// StreamTasksModifiedSince calls StreamTasksModifiedSinceFunc
+func (m *Client) StreamTasksModifiedSince(ctx context.Context, projectGID string, since time.Time, fn func(asana.Task) error) error {
+	m.calls.add("StreamTasksModifiedSince")
+	if m.StreamTasksModifiedSinceFunc == nil {
+		return ErrNotStubbed
+	}
+	return m.StreamTasksModifiedSinceFunc(ctx, projectGID, since, fn)
+}
+
+// GetTeams calls GetTeamsFunc
+func (m *Client) GetTeams(ctx context.Context, limit int, offset string) ([]asana.Team, *asana.NextPage, error) {
+	m.calls.add("GetTeams")
+	if m.GetTeamsFunc == nil {
+		var r0 []asana.Team
+		var r1 *asana.NextPage
+		return r0, r1, ErrNotStubbed
+	}
+	return m.GetTeamsFunc(ctx, limit, offset)
+}
+
+// StreamTeams calls StreamTeamsFunc
+func (m *Client) StreamTeams(ctx context.Context, fn func(asana.Team) error) error {
+	m.calls.add("StreamTeams")
+	if m.StreamTeamsFunc == nil {
+		return ErrNotStubbed
+	}
+	return m.StreamTeamsFunc(ctx, fn)
+}
+
+// GetWorkspaces calls GetWorkspacesFunc
+func (m *Client) GetWorkspaces(ctx context.Context, limit int, offset string) ([]asana.Workspace, *asana.NextPage, error) {
+	m.calls.add("GetWorkspaces")
+	if m.GetWorkspacesFunc == nil {
+		var r0 []asana.Workspace
+		var r1 *asana.NextPage
+		return r0, r1, ErrNotStubbed
+	}
+	return m.GetWorkspacesFunc(ctx, limit, offset)
+}
+
+// GetAllWorkspaces calls GetAllWorkspacesFunc
+func (m *Client) GetAllWorkspaces(ctx context.Context) ([]asana.Workspace, error) {
+	m.calls.add("GetAllWorkspaces")
+	if m.GetAllWorkspacesFunc == nil {
+		var r0 []asana.Workspace
+		return r0, ErrNotStubbed
+	}
+	return m.GetAllWorkspacesFunc(ctx)
+}
+
+// GetUserTaskList calls GetUserTaskListFunc
+func (m *Client) GetUserTaskList(ctx context.Context, userGID string) (*asana.UserTaskList, error) {
+	m.calls.add("GetUserTaskList")
+	if m.GetUserTaskListFunc == nil {
+		var r0 *asana.UserTaskList
+		return r0, ErrNotStubbed
+	}
+	return m.GetUserTaskListFunc(ctx, userGID)
+}
+
+// GetUserTaskListTasks calls GetUserTaskListTasksFunc
+func (m *Client) GetUserTaskListTasks(ctx context.Context, listGID string, limit int, offset string) ([]asana.Task, *asana.NextPage, error) {
+	m.calls.add("GetUserTaskListTasks")
+	if m.GetUserTaskListTasksFunc == nil {
+		var r0 []asana.Task
+		var r1 *asana.NextPage
+		return r0, r1, ErrNotStubbed
+	}
+	return m.GetUserTaskListTasksFunc(ctx, listGID, limit, offset)
+}
+
+// StreamUserTaskListTasks calls StreamUserTaskListTasksFunc
+func (m *Client) StreamUserTaskListTasks(ctx context.Context, listGID string, fn func(asana.Task) error) error {
+	m.calls.add("StreamUserTaskListTasks")
+	if m.StreamUserTaskListTasksFunc == nil {
+		return ErrNotStubbed
+	}
+	return m.StreamUserTaskListTasksFunc(ctx, listGID, fn)
+}
+
+// GetGoals calls GetGoalsFunc
+func (m *Client) GetGoals(ctx context.Context, limit int, offset string) ([]asana.Goal, *asana.NextPage, error) {
+	m.calls.add("GetGoals")
+	if m.GetGoalsFunc == nil {
+		var r0 []asana.Goal
+		var r1 *asana.NextPage
+		return r0, r1, ErrNotStubbed
+	}
+	return m.GetGoalsFunc(ctx, limit, offset)
+}
+
+// StreamGoals calls StreamGoalsFunc
+func (m *Client) StreamGoals(ctx context.Context, fn func(asana.Goal) error) error {
+	m.calls.add("StreamGoals")
+	if m.StreamGoalsFunc == nil {
+		return ErrNotStubbed
+	}
+	return m.StreamGoalsFunc(ctx, fn)
+}
+
+// GetPortfolios calls GetPortfoliosFunc
+func (m *Client) GetPortfolios(ctx context.Context, limit int, offset string) ([]asana.Portfolio, *asana.NextPage, error) {
+	m.calls.add("GetPortfolios")
+	if m.GetPortfoliosFunc == nil {
+		var r0 []asana.Portfolio
+		var r1 *asana.NextPage
+		return r0, r1, ErrNotStubbed
+	}
+	return m.GetPortfoliosFunc(ctx, limit, offset)
+}
+
+// StreamPortfolios calls StreamPortfoliosFunc
+func (m *Client) StreamPortfolios(ctx context.Context, fn func(asana.Portfolio) error) error {
+	m.calls.add("StreamPortfolios")
+	if m.StreamPortfoliosFunc == nil {
+		return ErrNotStubbed
+	}
+	return m.StreamPortfoliosFunc(ctx, fn)
+}
+
+// GetStatusUpdates calls GetStatusUpdatesFunc
+func (m *Client) GetStatusUpdates(ctx context.Context, parentGID string, limit int, offset string) ([]asana.StatusUpdate, *asana.NextPage, error) {
+	m.calls.add("GetStatusUpdates")
+	if m.GetStatusUpdatesFunc == nil {
+		var r0 []asana.StatusUpdate
+		var r1 *asana.NextPage
+		return r0, r1, ErrNotStubbed
+	}
+	return m.GetStatusUpdatesFunc(ctx, parentGID, limit, offset)
+}
+
+// StreamStatusUpdates calls StreamStatusUpdatesFunc
+func (m *Client) StreamStatusUpdates(ctx context.Context, parentGID string, fn func(asana.StatusUpdate) error) error {
+	m.calls.add("StreamStatusUpdates")
+	if m.StreamStatusUpdatesFunc == nil {
+		return ErrNotStubbed
+	}
+	return m.StreamStatusUpdatesFunc(ctx, parentGID, fn)
+}
+
+// GetCustomFields calls GetCustomFieldsFunc
+func (m *Client) GetCustomFields(ctx context.Context, limit int, offset string) ([]asana.CustomField, *asana.NextPage, error) {
+	m.calls.add("GetCustomFields")
+	if m.GetCustomFieldsFunc == nil {
+		var r0 []asana.CustomField
+		var r1 *asana.NextPage
+		return r0, r1, ErrNotStubbed
+	}
+	return m.GetCustomFieldsFunc(ctx, limit, offset)
+}
+
+// StreamCustomFields calls StreamCustomFieldsFunc
+func (m *Client) StreamCustomFields(ctx context.Context, fn func(asana.CustomField) error) error {
+	m.calls.add("StreamCustomFields")
+	if m.StreamCustomFieldsFunc == nil {
+		return ErrNotStubbed
+	}
+	return m.StreamCustomFieldsFunc(ctx, fn)
+}
+
+// GetAuditLogEvents calls GetAuditLogEventsFunc
+func (m *Client) GetAuditLogEvents(ctx context.Context, start time.Time, end time.Time, limit int, offset string) ([]asana.AuditLogEvent, *asana.NextPage, error) {
+	m.calls.add("GetAuditLogEvents")
+	if m.GetAuditLogEventsFunc == nil {
+		var r0 []asana.AuditLogEvent
+		var r1 *asana.NextPage
+		return r0, r1, ErrNotStubbed
+	}
+	return m.GetAuditLogEventsFunc(ctx, start, end, limit, offset)
+}
+
+// StreamAuditLogEvents calls StreamAuditLogEventsFunc
+func (m *Client) StreamAuditLogEvents(ctx context.Context, start time.Time, end time.Time, fn func(asana.AuditLogEvent) error) error {
+	m.calls.add("StreamAuditLogEvents")
+	if m.StreamAuditLogEventsFunc == nil {
+		return ErrNotStubbed
+	}
+	return m.StreamAuditLogEventsFunc(ctx, start, end, fn)
+}
+
+// Exists calls ExistsFunc
+func (m *Client) Exists(ctx context.Context, resource string, gid string) (bool, error) {
+	m.calls.add("Exists")
+	if m.ExistsFunc == nil {
+		var r0 bool
+		return r0, ErrNotStubbed
+	}
+	return m.ExistsFunc(ctx, resource, gid)
+}
+
+// DownloadPhoto calls DownloadPhotoFunc
+func (m *Client) DownloadPhoto(ctx context.Context, photoURL string) ([]byte, error) {
+	m.calls.add("DownloadPhoto")
+	if m.DownloadPhotoFunc == nil {
+		var r0 []byte
+		return r0, ErrNotStubbed
+	}
+	return m.DownloadPhotoFunc(ctx, photoURL)
+}
+
+// StreamAttachments calls StreamAttachmentsFunc
+func (m *Client) StreamAttachments(ctx context.Context, parentGID string, fn func(asana.Attachment) error) error {
+	m.calls.add("StreamAttachments")
+	if m.StreamAttachmentsFunc == nil {
+		return ErrNotStubbed
+	}
+	return m.StreamAttachmentsFunc(ctx, parentGID, fn)
+}
+
+// DownloadAttachment calls DownloadAttachmentFunc
+func (m *Client) DownloadAttachment(ctx context.Context, downloadURL string) (io.ReadCloser, error) {
+	m.calls.add("DownloadAttachment")
+	if m.DownloadAttachmentFunc == nil {
+		var r0 io.ReadCloser
+		return r0, ErrNotStubbed
+	}
+	return m.DownloadAttachmentFunc(ctx, downloadURL)
+}
+
+// CreateWebhook calls CreateWebhookFunc
+func (m *Client) CreateWebhook(ctx context.Context, target string, filters []asana.WebhookFilter) (*asana.Webhook, error) {
+	m.calls.add("CreateWebhook")
+	if m.CreateWebhookFunc == nil {
+		var r0 *asana.Webhook
+		return r0, ErrNotStubbed
+	}
+	return m.CreateWebhookFunc(ctx, target, filters)
+}
+
+// DeleteWebhook calls DeleteWebhookFunc
+func (m *Client) DeleteWebhook(ctx context.Context, gid string) error {
+	m.calls.add("DeleteWebhook")
+	if m.DeleteWebhookFunc == nil {
+		return ErrNotStubbed
+	}
+	return m.DeleteWebhookFunc(ctx, gid)
+}
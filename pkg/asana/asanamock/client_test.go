@@ -0,0 +1,42 @@
+package asanamock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestClient(t *testing.T) {
+	m := &Client{
+		StreamUsersFunc: func(ctx context.Context, fn func(asana.User) error) error {
+			return fn(asana.User{GID: "u1"})
+		},
+	}
+
+	// The stub can stand in for any of the narrower interfaces
+	var users asana.UserReader = m
+	var gids []string
+	err := users.StreamUsers(context.Background(), func(u asana.User) error {
+		gids = append(gids, u.GID)
+		return nil
+	})
+	if err != nil || len(gids) != 1 || gids[0] != "u1" {
+		t.Errorf("Expected the stubbed user, got %v (%v)", gids, err)
+	}
+
+	if _, _, err := m.GetUsers(context.Background(), 100, ""); !errors.Is(err, ErrNotStubbed) {
+		t.Errorf("Expected ErrNotStubbed from a method without a stub, got %v", err)
+	}
+	if ok, err := m.Exists(context.Background(), "users", "u1"); ok || !errors.Is(err, ErrNotStubbed) {
+		t.Errorf("Expected zero values and ErrNotStubbed, got %v, %v", ok, err)
+	}
+
+	if n := m.Calls("StreamUsers"); n != 1 {
+		t.Errorf("Expected 1 StreamUsers call, got %d", n)
+	}
+	if n := m.Calls("DeleteWebhook"); n != 0 {
+		t.Errorf("Expected no DeleteWebhook calls, got %d", n)
+	}
+}
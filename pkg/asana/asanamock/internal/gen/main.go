@@ -0,0 +1,225 @@
+// Command gen writes package asanamock's Client, a stub of every method of
+// the asana.API interface, from the interfaces in pkg/asana/api.go.
+//
+// Usage: go run ./internal/gen -src ../api.go -out client.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"strings"
+)
+
+// method is an interface method with its signature rendered for the stub
+type method struct {
+	name    string
+	params  []string // "name type"
+	args    []string // names, with ... for a variadic last parameter
+	results []string // types
+}
+
+func main() {
+	src := flag.String("src", "../api.go", "file declaring the asana.API interface")
+	out := flag.String("out", "client.go", "file to write")
+	flag.Parse()
+
+	code, err := generate(*src)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, code, 0644); err != nil {
+		log.Fatalf("failed to write %s: %v", *out, err)
+	}
+}
+
+// generate returns the formatted stub source for the API interface
+// declared in src
+func generate(src string) ([]byte, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), src, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", src, err)
+	}
+
+	interfaces := make(map[string]*ast.InterfaceType)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if it, ok := ts.Type.(*ast.InterfaceType); ok {
+				interfaces[ts.Name.Name] = it
+			}
+		}
+	}
+	methods, err := collect(interfaces, "API")
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := format.Source(render(methods))
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return code, nil
+}
+
+// collect returns the methods of the named interface, following embedded
+// interfaces declared in the same file, in declaration order
+func collect(interfaces map[string]*ast.InterfaceType, name string) ([]method, error) {
+	it, ok := interfaces[name]
+	if !ok {
+		return nil, fmt.Errorf("interface %s is not declared in the source file", name)
+	}
+
+	var methods []method
+	for _, field := range it.Methods.List {
+		switch t := field.Type.(type) {
+		case *ast.Ident:
+			embedded, err := collect(interfaces, t.Name)
+			if err != nil {
+				return nil, err
+			}
+			methods = append(methods, embedded...)
+		case *ast.FuncType:
+			m := method{name: field.Names[0].Name}
+			for i, p := range fieldsOf(t.Params) {
+				typ := qualify(p.Type)
+				names := p.Names
+				if len(names) == 0 {
+					names = []*ast.Ident{ast.NewIdent(fmt.Sprintf("arg%d", i))}
+				}
+				for _, n := range names {
+					m.params = append(m.params, n.Name+" "+typ)
+					arg := n.Name
+					if _, variadic := p.Type.(*ast.Ellipsis); variadic {
+						arg += "..."
+					}
+					m.args = append(m.args, arg)
+				}
+			}
+			for _, r := range fieldsOf(t.Results) {
+				for range max(len(r.Names), 1) {
+					m.results = append(m.results, qualify(r.Type))
+				}
+			}
+			methods = append(methods, m)
+		default:
+			return nil, fmt.Errorf("unsupported member of interface %s", name)
+		}
+	}
+	return methods, nil
+}
+
+// fieldsOf returns the fields of a possibly nil field list
+func fieldsOf(list *ast.FieldList) []*ast.Field {
+	if list == nil {
+		return nil
+	}
+	return list.List
+}
+
+// qualify renders a type expression of package asana as seen from another
+// package, prefixing its exported identifiers with "asana."
+func qualify(expr ast.Expr) string {
+	return types.ExprString(qualifyExpr(expr))
+}
+
+func qualifyExpr(expr ast.Expr) ast.Expr {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if ast.IsExported(t.Name) {
+			return &ast.SelectorExpr{X: ast.NewIdent("asana"), Sel: t}
+		}
+		return t
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: qualifyExpr(t.X)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: t.Len, Elt: qualifyExpr(t.Elt)}
+	case *ast.MapType:
+		return &ast.MapType{Key: qualifyExpr(t.Key), Value: qualifyExpr(t.Value)}
+	case *ast.Ellipsis:
+		return &ast.Ellipsis{Elt: qualifyExpr(t.Elt)}
+	case *ast.FuncType:
+		return &ast.FuncType{Params: qualifyFields(t.Params), Results: qualifyFields(t.Results)}
+	}
+	// Selectors such as context.Context are already qualified
+	return expr
+}
+
+func qualifyFields(list *ast.FieldList) *ast.FieldList {
+	if list == nil {
+		return nil
+	}
+	out := &ast.FieldList{}
+	for _, f := range list.List {
+		out.List = append(out.List, &ast.Field{Names: f.Names, Type: qualifyExpr(f.Type)})
+	}
+	return out
+}
+
+// resultList renders the method's results as written after its parameters
+func (m method) resultList() string {
+	if len(m.results) == 1 {
+		return m.results[0]
+	}
+	return "(" + strings.Join(m.results, ", ") + ")"
+}
+
+// render writes the asanamock source for methods
+func render(methods []method) []byte {
+	var b bytes.Buffer
+	b.WriteString(`// Code generated by asanamock/internal/gen from pkg/asana/api.go; DO NOT EDIT.
+
+package asanamock
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+var _ asana.API = (*Client)(nil)
+
+// Client stubs asana.API. Each method calls the function field named after
+// it, e.g. StreamUsersFunc, and counts the call. A method without one
+// returns zero values and ErrNotStubbed.
+type Client struct {
+	calls counter
+
+`)
+	for _, m := range methods {
+		fmt.Fprintf(&b, "\t%sFunc func(%s) %s\n", m.name, strings.Join(m.params, ", "), m.resultList())
+	}
+	b.WriteString("}\n")
+
+	for _, m := range methods {
+		fmt.Fprintf(&b, "\n// %s calls %sFunc\n", m.name, m.name)
+		fmt.Fprintf(&b, "func (m *Client) %s(%s) %s {\n", m.name, strings.Join(m.params, ", "), m.resultList())
+		fmt.Fprintf(&b, "\tm.calls.add(%q)\n", m.name)
+		fmt.Fprintf(&b, "\tif m.%sFunc == nil {\n", m.name)
+		var zeros []string
+		for i, r := range m.results {
+			if r == "error" && i == len(m.results)-1 {
+				zeros = append(zeros, "ErrNotStubbed")
+				continue
+			}
+			fmt.Fprintf(&b, "\t\tvar r%d %s\n", i, r)
+			zeros = append(zeros, fmt.Sprintf("r%d", i))
+		}
+		fmt.Fprintf(&b, "\t\treturn %s\n\t}\n", strings.Join(zeros, ", "))
+		fmt.Fprintf(&b, "\treturn m.%sFunc(%s)\n}\n", m.name, strings.Join(m.args, ", "))
+	}
+	return b.Bytes()
+}
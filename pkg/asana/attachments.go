@@ -0,0 +1,41 @@
+package asana
+
+import (
+	"context"
+	"io"
+	"net/url"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// attachmentFields are the attachment fields requested from Asana
+const attachmentFields = "gid,resource_subtype,name,host,size,download_url,created_at,parent,parent.name"
+
+// maxAttachmentSize bounds the size of a downloaded attachment. Asana
+// refuses uploads above 100 MB.
+const maxAttachmentSize = 100 << 20
+
+// GetAttachments retrieves one page of the attachments of a task
+func (c *Client) GetAttachments(ctx context.Context, parentGID string, limit int, offset string) ([]Attachment, *NextPage, error) {
+	query := url.Values{"parent": {parentGID}}
+	return getPage[Attachment](ctx, c, "/attachments?"+query.Encode(), "attachments", attachmentFields, limit, offset)
+}
+
+// StreamAttachments walks every page of the attachments of a task and
+// invokes fn for each attachment as the page arrives
+func (c *Client) StreamAttachments(ctx context.Context, parentGID string, fn func(Attachment) error) error {
+	fetch := func(ctx context.Context, limit int, offset string) ([]Attachment, *NextPage, error) {
+		return c.GetAttachments(ctx, parentGID, limit, offset)
+	}
+	return paginate(ctx, "asana.StreamAttachments", c.PageSize("attachments"), fetch, eachItem(fn),
+		attribute.String("asana.parent_gid", parentGID))
+}
+
+// DownloadAttachment starts fetching the file at an attachment's
+// DownloadURL and returns its body, which the caller must close. Files can
+// be large, so they are streamed rather than read into memory. Like
+// photos, files are served from outside the API, so the request goes
+// through ClientOptions.PhotoClient without the token or rate limiter.
+func (c *Client) DownloadAttachment(ctx context.Context, downloadURL string) (io.ReadCloser, error) {
+	return c.openDownload(ctx, downloadURL, "attachment", maxAttachmentSize)
+}
@@ -0,0 +1,62 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAttachments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/attachments" && r.URL.Query().Get("parent") == "t1":
+			json.NewEncoder(w).Encode(map[string]any{"data": []Attachment{
+				{GID: "a1", Name: "spec.pdf", Host: "asana", DownloadURL: "http://" + r.Host + "/files/a1"},
+				{GID: "a2", Name: "Design doc", Host: "gdrive"},
+			}})
+		case r.URL.Path == "/files/a1":
+			if r.Header.Get("Authorization") != "" {
+				t.Error("expected attachment downloads without the API token")
+			}
+			w.Write([]byte("%PDF"))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	asanaClient, err := NewClientWithOptions(setupMockClient(), "ws", server.URL, ClientOptions{PhotoClient: server.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	var attachments []Attachment
+	err = asanaClient.StreamAttachments(ctx, "t1", func(a Attachment) error {
+		attachments = append(attachments, a)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamAttachments() error = %v", err)
+	}
+	if len(attachments) != 2 || attachments[1].DownloadURL != "" {
+		t.Fatalf("expected a hosted and an external attachment, got %+v", attachments)
+	}
+
+	file, err := asanaClient.DownloadAttachment(ctx, attachments[0].DownloadURL)
+	if err != nil {
+		t.Fatalf("DownloadAttachment() error = %v", err)
+	}
+	data, err := io.ReadAll(file)
+	file.Close()
+	if err != nil || string(data) != "%PDF" {
+		t.Errorf("unexpected file: %q", data)
+	}
+	if _, err := asanaClient.DownloadAttachment(ctx, server.URL+"/files/expired"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden for an expired URL, got %v", err)
+	}
+}
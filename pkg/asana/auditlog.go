@@ -0,0 +1,34 @@
+package asana
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// GetAuditLogEvents retrieves one page of the workspace's audit log events
+// created at or after start and before end. The endpoint is only available
+// to Enterprise organizations and needs a service account token; other
+// workspaces get ErrPaymentRequired or ErrForbidden.
+func (c *Client) GetAuditLogEvents(ctx context.Context, start, end time.Time, limit int, offset string) ([]AuditLogEvent, *NextPage, error) {
+	query := url.Values{
+		"start_at": {start.UTC().Format(time.RFC3339Nano)},
+		"end_at":   {end.UTC().Format(time.RFC3339Nano)},
+	}
+	return getPage[AuditLogEvent](ctx, c, "/workspaces/"+c.workspace+"/audit_log_events?"+query.Encode(), "audit log events", "", limit, offset)
+}
+
+// StreamAuditLogEvents walks every page of the audit log events created in
+// [start, end) and invokes fn for each event as the page arrives. The API
+// keeps offering a next page for events still to come; the walk ends at the
+// first empty page.
+func (c *Client) StreamAuditLogEvents(ctx context.Context, start, end time.Time, fn func(AuditLogEvent) error) error {
+	fetch := func(ctx context.Context, limit int, offset string) ([]AuditLogEvent, *NextPage, error) {
+		return c.GetAuditLogEvents(ctx, start, end, limit, offset)
+	}
+	return paginate(ctx, "asana.StreamAuditLogEvents", c.PageSize("audit_log_events"), fetch, eachItem(fn),
+		attribute.String("asana.start_at", start.UTC().Format(time.RFC3339)),
+		attribute.String("asana.end_at", end.UTC().Format(time.RFC3339)))
+}
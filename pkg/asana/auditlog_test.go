@@ -0,0 +1,67 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStreamAuditLogEvents(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/workspaces/ws/audit_log_events" || q.Get("start_at") != "2024-03-01T00:00:00Z" ||
+			q.Get("end_at") != "2024-03-01T01:00:00Z" || q.Has("opt_fields") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// Like Asana, every page offers a next one, even the empty last page
+		page := []AuditLogEvent{}
+		next := "o1"
+		switch q.Get("offset") {
+		case "":
+			page = []AuditLogEvent{{GID: "e1", EventType: "user_login_succeeded"}}
+		case "o1":
+			page = []AuditLogEvent{{GID: "e2", Details: map[string]any{"new_value": "x"}}}
+			next = "o2"
+		default:
+			next = "o3"
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": page, "next_page": NextPage{Offset: next}})
+	}))
+	defer server.Close()
+
+	asanaClient := NewClient(setupMockClient(), "ws", server.URL, 100)
+
+	var events []AuditLogEvent
+	err := asanaClient.StreamAuditLogEvents(context.Background(), start, end, func(e AuditLogEvent) error {
+		events = append(events, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 2 || events[0].EventType != "user_login_succeeded" || events[1].Details["new_value"] != "x" {
+		t.Errorf("unexpected events: %+v", events)
+	}
+}
+
+func TestGetAuditLogEvents_NotEnterprise(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPaymentRequired)
+		w.Write([]byte(`{"errors":[{"message":"This endpoint is only available to Enterprise organizations."}]}`))
+	}))
+	defer server.Close()
+
+	asanaClient := NewClient(setupMockClient(), "ws", server.URL, 100)
+
+	_, _, err := asanaClient.GetAuditLogEvents(context.Background(), time.Now().Add(-time.Hour), time.Now(), 100, "")
+	if !errors.Is(err, ErrPaymentRequired) {
+		t.Errorf("expected ErrPaymentRequired, got %v", err)
+	}
+}
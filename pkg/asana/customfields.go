@@ -0,0 +1,16 @@
+package asana
+
+import "context"
+
+// GetCustomFields retrieves the custom field definitions of the workspace,
+// with their enum options, with pagination
+func (c *Client) GetCustomFields(ctx context.Context, limit int, offset string) ([]CustomField, *NextPage, error) {
+	return getPage[CustomField](ctx, c, "/workspaces/"+c.workspace+"/custom_fields", "custom fields",
+		"gid,name,resource_subtype,enum_options.name,enum_options.color,enum_options.enabled", limit, offset)
+}
+
+// StreamCustomFields walks every page of the workspace's custom fields and
+// invokes fn for each field as the page arrives
+func (c *Client) StreamCustomFields(ctx context.Context, fn func(CustomField) error) error {
+	return paginate(ctx, "asana.StreamCustomFields", c.PageSize("custom_fields"), c.GetCustomFields, eachItem(fn))
+}
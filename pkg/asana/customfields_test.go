@@ -0,0 +1,39 @@
+package asana
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStreamCustomFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/workspaces/ws/custom_fields" || !strings.Contains(r.URL.Query().Get("opt_fields"), "enum_options.color") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"data":[{"gid":"f1","name":"Priority","resource_subtype":"enum","enum_options":[
+			{"gid":"o1","name":"High","color":"red","enabled":true},
+			{"gid":"o2","name":"Old","color":"none","enabled":false}]}]}`))
+	}))
+	defer server.Close()
+
+	asanaClient := NewClient(setupMockClient(), "ws", server.URL, 100)
+
+	var fields []CustomField
+	err := asanaClient.StreamCustomFields(context.Background(), func(f CustomField) error {
+		fields = append(fields, f)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fields) != 1 || len(fields[0].EnumOptions) != 2 {
+		t.Fatalf("expected one field with two options, got %+v", fields)
+	}
+	if opt := fields[0].EnumOptions[1]; opt.Enabled == nil || *opt.Enabled || opt.Color != "none" {
+		t.Errorf("expected a disabled option, got %+v", opt)
+	}
+}
@@ -0,0 +1,98 @@
+package asana
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ioanzicu/asana-extractor/pkg/client"
+)
+
+// Error kinds returned by API calls. Match them with errors.Is; use
+// errors.As with *APIError for the status code and Asana's messages.
+var (
+	ErrBadRequest      = errors.New("bad request")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrPaymentRequired = errors.New("payment required")
+	ErrForbidden       = errors.New("forbidden")
+	ErrNotFound        = errors.New("not found")
+	ErrRateLimited     = errors.New("rate limited")
+	ErrServer          = errors.New("server error")
+)
+
+// APIError is a non-success response decoded from Asana's error body
+type APIError struct {
+	StatusCode int
+	Messages   []string
+	kind       error
+	cause      error
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if len(e.Messages) == 0 {
+		return fmt.Sprintf("asana: %v (status %d)", e.kind, e.StatusCode)
+	}
+	return fmt.Sprintf("asana: %v (status %d): %s", e.kind, e.StatusCode, strings.Join(e.Messages, "; "))
+}
+
+// Unwrap returns the error kind, so errors.Is(err, ErrNotFound) works, and
+// the underlying client error when retries were exhausted
+func (e *APIError) Unwrap() []error {
+	if e.cause != nil {
+		return []error{e.kind, e.cause}
+	}
+	return []error{e.kind}
+}
+
+// newAPIError decodes an Asana error body for the given status
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: statusCode, kind: kindFor(statusCode)}
+
+	var resp ErrorResponse
+	if err := json.Unmarshal(body, &resp); err == nil {
+		for _, e := range resp.Errors {
+			apiErr.Messages = append(apiErr.Messages, e.Message)
+		}
+	} else if len(body) > 0 {
+		apiErr.Messages = []string{string(body)}
+	}
+
+	return apiErr
+}
+
+// kindFor maps an HTTP status to an error kind
+func kindFor(statusCode int) error {
+	switch {
+	case statusCode == http.StatusBadRequest:
+		return ErrBadRequest
+	case statusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case statusCode == http.StatusPaymentRequired:
+		return ErrPaymentRequired
+	case statusCode == http.StatusForbidden:
+		return ErrForbidden
+	case statusCode == http.StatusNotFound:
+		return ErrNotFound
+	case statusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case statusCode >= 500:
+		return ErrServer
+	default:
+		return fmt.Errorf("unexpected status %d", statusCode)
+	}
+}
+
+// apiError converts a client status error into an *APIError, leaving other
+// errors (network failures, cancellation) untouched
+func apiError(err error) error {
+	var statusErr *client.StatusError
+	if errors.As(err, &statusErr) {
+		apiErr := newAPIError(statusErr.StatusCode, statusErr.Body)
+		apiErr.cause = statusErr.Err
+		return apiErr
+	}
+	return err
+}
@@ -0,0 +1,80 @@
+package asana
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPIErrors_Table(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		expectKind  error
+		expectInMsg string
+	}{
+		{
+			name:        "Not found with Asana message",
+			status:      http.StatusNotFound,
+			body:        `{"errors":[{"message":"project: Unknown object: 123"}]}`,
+			expectKind:  ErrNotFound,
+			expectInMsg: "Unknown object",
+		},
+		{
+			name:        "Forbidden",
+			status:      http.StatusForbidden,
+			body:        `{"errors":[{"message":"Forbidden"}]}`,
+			expectKind:  ErrForbidden,
+			expectInMsg: "Forbidden",
+		},
+		{
+			name:        "Payment required",
+			status:      http.StatusPaymentRequired,
+			body:        `{"errors":[{"message":"This feature requires a premium plan"}]}`,
+			expectKind:  ErrPaymentRequired,
+			expectInMsg: "premium",
+		},
+		{
+			name:        "Rate limited after retries",
+			status:      http.StatusTooManyRequests,
+			body:        `{"errors":[{"message":"You have made too many requests recently."}]}`,
+			expectKind:  ErrRateLimited,
+			expectInMsg: "too many requests",
+		},
+		{
+			name:        "Non-JSON body is kept verbatim",
+			status:      http.StatusBadRequest,
+			body:        "bad things",
+			expectKind:  ErrBadRequest,
+			expectInMsg: "bad things",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			asanaClient := NewClient(setupMockClient(), "ws1", server.URL, 100)
+			_, _, err := asanaClient.GetProjects(context.Background(), 10, "")
+
+			if !errors.Is(err, tt.expectKind) {
+				t.Fatalf("expected %v, got %v", tt.expectKind, err)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Fatalf("expected *APIError with status %d, got %v", tt.status, err)
+			}
+			if !strings.Contains(err.Error(), tt.expectInMsg) {
+				t.Errorf("expected message containing %q, got %q", tt.expectInMsg, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package asana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// lookupPaths maps the resources Exists accepts to their API collections
+var lookupPaths = map[string]string{
+	"users":           "/users/",
+	"projects":        "/projects/",
+	"tasks":           "/tasks/",
+	"teams":           "/teams/",
+	"user_task_lists": "/user_task_lists/",
+	"status_updates":  "/status_updates/",
+	"custom_fields":   "/custom_fields/",
+}
+
+// Exists reports whether the entity of the given resource ("users",
+// "projects", "tasks", "teams", "user_task_lists", "status_updates" or
+// "custom_fields") with gid is still visible in Asana. A deleted entity,
+// or one the token can no longer see, reports false.
+func (c *Client) Exists(ctx context.Context, resource, gid string) (bool, error) {
+	path, ok := lookupPaths[resource]
+	if !ok {
+		return false, fmt.Errorf("unknown resource %q", resource)
+	}
+
+	var resp struct {
+		Data struct {
+			GID string `json:"gid"`
+		} `json:"data"`
+	}
+	err := c.httpClient.GetJSON(ctx, c.baseURL+path+url.PathEscape(gid)+"?opt_fields=gid", &resp)
+	if err != nil {
+		err = apiError(err)
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to look up %s %s: %w", resource, gid, err)
+	}
+	return resp.Data.GID == gid, nil
+}
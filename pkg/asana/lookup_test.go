@@ -0,0 +1,78 @@
+package asana
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExists_Table(t *testing.T) {
+	tests := []struct {
+		name      string
+		resource  string
+		handler   http.HandlerFunc
+		want      bool
+		expectErr bool
+	}{
+		{
+			name:     "Entity exists",
+			resource: "tasks",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/tasks/123" || r.URL.Query().Get("opt_fields") != "gid" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				w.Write([]byte(`{"data":{"gid":"123"}}`))
+			},
+			want: true,
+		},
+		{
+			name:     "Entity deleted",
+			resource: "projects",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			want: false,
+		},
+		{
+			name:     "Entity no longer visible",
+			resource: "users",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			},
+			want: false,
+		},
+		{
+			name:     "Server error",
+			resource: "teams",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			expectErr: true,
+		},
+		{
+			name:      "Unknown resource",
+			resource:  "goals",
+			handler:   func(w http.ResponseWriter, r *http.Request) {},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			asanaClient := NewClient(setupMockClient(), "ws", server.URL, 100)
+
+			got, err := asanaClient.Exists(context.Background(), tt.resource, "123")
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("Exists() error = %v, expectErr %t", err, tt.expectErr)
+			}
+			if got != tt.want {
+				t.Errorf("Exists() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
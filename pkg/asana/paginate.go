@@ -0,0 +1,133 @@
+package asana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/ioanzicu/asana-extractor/pkg/client"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxPageSize is the largest limit the Asana API accepts
+const maxPageSize = 100
+
+// pageResponse is the envelope of every paginated list endpoint
+type pageResponse[T any] struct {
+	Data     []T       `json:"data"`
+	NextPage *NextPage `json:"next_page"`
+}
+
+// pageFetcher fetches one page of up to limit items starting at offset
+type pageFetcher[T any] func(ctx context.Context, limit int, offset string) ([]T, *NextPage, error)
+
+// getPage fetches one page of a list endpoint. path is relative to the base
+// URL and name is the resource used in error messages. An empty optFields
+// leaves the endpoint's default fields.
+func getPage[T any](ctx context.Context, c *Client, path, name, optFields string, limit int, offset string) ([]T, *NextPage, error) {
+	// Build URL with query parameters
+	u, err := url.Parse(c.baseURL + path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("limit", strconv.Itoa(pageSize(limit)))
+
+	if offset != "" {
+		q.Set("offset", offset)
+	}
+
+	if optFields != "" {
+		q.Set("opt_fields", optFields)
+	}
+	u.RawQuery = q.Encode()
+
+	// Make request, decoding the page as it streams in
+	var resp pageResponse[T]
+	if err := c.httpClient.GetJSON(ctx, u.String(), &resp); err != nil {
+		var decodeErr *client.DecodeError
+		if errors.As(err, &decodeErr) {
+			return nil, nil, fmt.Errorf("failed to parse %s response: %w", name, decodeErr.Err)
+		}
+		return nil, nil, fmt.Errorf("failed to get %s: %w", name, apiError(err))
+	}
+
+	return resp.Data, resp.NextPage, nil
+}
+
+// paginate walks every page returned by fetch and hands each non-empty page
+// to onPage as it arrives, so callers never hold more than one page in
+// memory. It stops on an empty page, a missing next offset or the first
+// error, and records the walk in a span named spanName.
+func paginate[T any](ctx context.Context, spanName string, limit int, fetch pageFetcher[T], onPage func([]T) error, attrs ...attribute.KeyValue) (err error) {
+	ctx, span := startPaginationSpan(ctx, spanName, attrs...)
+	pages, items := 0, 0
+	defer func() { endPaginationSpan(ctx, span, pages, items, err) }()
+
+	limit = pageSize(limit)
+	var offset string
+
+	for {
+		page, nextPage, err := fetch(ctx, limit, offset)
+		if err != nil {
+			return err
+		}
+
+		pages++
+
+		if len(page) == 0 {
+			break
+		}
+
+		if err := onPage(page); err != nil {
+			return err
+		}
+		items += len(page)
+
+		if nextPage == nil || nextPage.Offset == "" {
+			break
+		}
+
+		offset = nextPage.Offset
+	}
+
+	return nil
+}
+
+// eachItem adapts a per-item callback to paginate's per-page callback
+func eachItem[T any](fn func(T) error) func([]T) error {
+	return func(page []T) error {
+		for _, item := range page {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// collect paginates through every page and returns all items
+func collect[T any](ctx context.Context, spanName string, limit int, fetch pageFetcher[T]) ([]T, error) {
+	var all []T
+	err := paginate(ctx, spanName, limit, fetch, func(page []T) error {
+		all = append(all, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// pageSize clamps limit to the range the API accepts, defaulting to the
+// maximum
+func pageSize(limit int) int {
+	if limit <= 0 || limit > maxPageSize {
+		return maxPageSize
+	}
+	return limit
+}
@@ -0,0 +1,135 @@
+package asana
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// fakePages serves pages of ints keyed by offset, recording the limits asked for
+type fakePages struct {
+	pages  map[string][]int
+	next   map[string]string
+	limits []int
+	err    error
+}
+
+func (f *fakePages) fetch(ctx context.Context, limit int, offset string) ([]int, *NextPage, error) {
+	f.limits = append(f.limits, limit)
+	if f.err != nil {
+		return nil, nil, f.err
+	}
+	var next *NextPage
+	if o, ok := f.next[offset]; ok {
+		next = &NextPage{Offset: o}
+	}
+	return f.pages[offset], next, nil
+}
+
+func TestPaginate(t *testing.T) {
+	fetchErr := errors.New("fetch failed")
+	pageErr := errors.New("page rejected")
+
+	tests := []struct {
+		name      string
+		fake      *fakePages
+		limit     int
+		onPageErr error
+		wantItems []int
+		wantPages int
+		wantLimit int
+		wantErr   error
+	}{
+		{
+			name:      "Follows offsets until no next page",
+			fake:      &fakePages{pages: map[string][]int{"": {1, 2}, "a": {3, 4}, "b": {5}}, next: map[string]string{"": "a", "a": "b"}},
+			limit:     2,
+			wantItems: []int{1, 2, 3, 4, 5},
+			wantPages: 3,
+			wantLimit: 2,
+		},
+		{
+			name:      "Stops on an empty page",
+			fake:      &fakePages{pages: map[string][]int{"": {1}}, next: map[string]string{"": "a", "a": "b"}},
+			limit:     50,
+			wantItems: []int{1},
+			wantPages: 2,
+			wantLimit: 50,
+		},
+		{
+			name:      "Invalid page size falls back to the maximum",
+			fake:      &fakePages{pages: map[string][]int{"": {1}}},
+			limit:     500,
+			wantItems: []int{1},
+			wantPages: 1,
+			wantLimit: maxPageSize,
+		},
+		{
+			name:      "Fetch error is returned",
+			fake:      &fakePages{err: fetchErr},
+			wantPages: 1,
+			wantLimit: maxPageSize,
+			wantErr:   fetchErr,
+		},
+		{
+			name:      "Page callback error stops iteration",
+			fake:      &fakePages{pages: map[string][]int{"": {1}, "a": {2}}, next: map[string]string{"": "a"}},
+			onPageErr: pageErr,
+			wantPages: 1,
+			wantLimit: maxPageSize,
+			wantErr:   pageErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var items []int
+			err := paginate(context.Background(), "test", tt.limit, tt.fake.fetch, func(page []int) error {
+				if tt.onPageErr != nil {
+					return tt.onPageErr
+				}
+				items = append(items, page...)
+				return nil
+			})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(items, tt.wantItems) {
+				t.Errorf("Expected items %v, got %v", tt.wantItems, items)
+			}
+			if len(tt.fake.limits) != tt.wantPages {
+				t.Errorf("Expected %d fetches, got %d", tt.wantPages, len(tt.fake.limits))
+			}
+			if tt.fake.limits[0] != tt.wantLimit {
+				t.Errorf("Expected limit %d, got %d", tt.wantLimit, tt.fake.limits[0])
+			}
+		})
+	}
+}
+
+func TestEachItemAndCollect(t *testing.T) {
+	fake := &fakePages{pages: map[string][]int{"": {1, 2}, "a": {3}}, next: map[string]string{"": "a"}}
+
+	all, err := collect(context.Background(), "test", 2, fake.fetch)
+	if err != nil {
+		t.Fatalf("collect() failed: %v", err)
+	}
+	if !reflect.DeepEqual(all, []int{1, 2, 3}) {
+		t.Errorf("Expected [1 2 3], got %v", all)
+	}
+
+	var seen []int
+	stop := errors.New("stop")
+	err = eachItem(func(i int) error {
+		seen = append(seen, i)
+		if i == 2 {
+			return stop
+		}
+		return nil
+	})([]int{1, 2, 3})
+	if !errors.Is(err, stop) || !reflect.DeepEqual(seen, []int{1, 2}) {
+		t.Errorf("Expected iteration to stop at 2, got %v (err %v)", seen, err)
+	}
+}
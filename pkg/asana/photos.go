@@ -0,0 +1,74 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxPhotoSize bounds the size of a downloaded photo
+const maxPhotoSize = 10 << 20
+
+// DownloadPhoto fetches the image at photoURL, one of a Photo's URLs. The
+// request goes through ClientOptions.PhotoClient, without the API token or
+// rate limiter, since photos are served from Asana's CDN. A non-success
+// status is returned as an *APIError.
+func (c *Client) DownloadPhoto(ctx context.Context, photoURL string) ([]byte, error) {
+	return c.download(ctx, photoURL, "photo", maxPhotoSize)
+}
+
+// download fetches a file served outside the API through
+// ClientOptions.PhotoClient, failing if it exceeds limit bytes
+func (c *Client) download(ctx context.Context, fileURL, kind string, limit int64) ([]byte, error) {
+	body, err := c.openDownload(ctx, fileURL, kind, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", kind, err)
+	}
+	return data, nil
+}
+
+// openDownload starts fetching a file served outside the API through
+// ClientOptions.PhotoClient and returns its body, whose reads fail once it
+// exceeds limit bytes. The caller must close it.
+func (c *Client) openDownload(ctx context.Context, fileURL, kind string, limit int64) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s request: %w", kind, err)
+	}
+
+	resp, err := c.photoClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", kind, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, newAPIError(resp.StatusCode, nil)
+	}
+	return &limitedBody{ReadCloser: resp.Body, kind: kind, limit: limit}, nil
+}
+
+// limitedBody is a download body failing once more than limit bytes have
+// been read from it
+type limitedBody struct {
+	io.ReadCloser
+	kind  string
+	limit int64
+	read  int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if b.read > b.limit {
+		return n, fmt.Errorf("%s exceeds %d bytes", b.kind, b.limit)
+	}
+	return n, err
+}
@@ -0,0 +1,82 @@
+package asana
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDownloadPhoto(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Error("expected photo requests without the API token")
+		}
+		if r.URL.Path != "/photos/u1_1024x1024.png" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte("png"))
+	}))
+	defer server.Close()
+
+	asanaClient, err := NewClientWithOptions(setupMockClient(), "ws", server.URL, ClientOptions{PhotoClient: server.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := asanaClient.DownloadPhoto(context.Background(), server.URL+"/photos/u1_1024x1024.png")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "png" {
+		t.Errorf("unexpected photo: %q", data)
+	}
+
+	if _, err := asanaClient.DownloadPhoto(context.Background(), server.URL+"/photos/expired.png"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden for an expired URL, got %v", err)
+	}
+}
+
+func TestPhotoLargest(t *testing.T) {
+	tests := []struct {
+		name  string
+		photo *Photo
+		want  string
+	}{
+		{name: "no photo", photo: nil, want: ""},
+		{name: "all sizes", photo: &Photo{Image21x21: "s", Image128x128: "m", Image1024x1024: "l"}, want: "l"},
+		{name: "largest missing", photo: &Photo{Image21x21: "s", Image128x128: "m"}, want: "m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.photo.Largest(); got != tt.want {
+				t.Errorf("Largest() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitedBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{name: "under the limit", body: "png", wantErr: false},
+		{name: "at the limit", body: "four", wantErr: false},
+		{name: "over the limit", body: "fives", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &limitedBody{ReadCloser: io.NopCloser(strings.NewReader(tt.body)), kind: "photo", limit: 4}
+			_, err := io.ReadAll(body)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadAll() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
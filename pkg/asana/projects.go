@@ -2,68 +2,38 @@ package asana
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/url"
 )
 
 // GetProjects retrieves projects with pagination
 func (c *Client) GetProjects(ctx context.Context, limit int, offset string) ([]Project, *NextPage, error) {
-	// Build URL with query parameters
-	u, err := url.Parse(fmt.Sprintf("%s/workspaces/%s/projects", c.baseURL, c.workspace))
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to parse URL: %w", err)
-	}
-
-	q := u.Query()
-	q.Set("limit", fmt.Sprintf("%d", limit))
-
-	if offset != "" {
-		q.Set("offset", offset)
-	}
-
-	q.Set("opt_fields", "gid,name,archived,color,created_at,modified_at,owner,public,workspace,team")
-	u.RawQuery = q.Encode()
-
-	// Make request
-	body, err := c.httpClient.GetBody(ctx, u.String())
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get projects: %w", err)
-	}
-
-	// Parse response
-	var resp ProjectsResponse
-	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, nil, fmt.Errorf("failed to parse projects response: %w", err)
-	}
-
-	return resp.Data, resp.NextPage, nil
+	return getPage[Project](ctx, c, "/workspaces/"+c.workspace+"/projects", "projects",
+		"gid,name,archived,color,created_at,modified_at,owner,public,workspace,team", limit, offset)
 }
 
 // GetAllProjects retrieves all projects by automatically handling pagination
 func (c *Client) GetAllProjects(ctx context.Context) ([]Project, error) {
-	const pageSize = 100
-	var allProjects []Project
-	var currentOffset string
-
-	for {
-		projects, nextPage, err := c.GetProjects(ctx, pageSize, currentOffset)
-		if err != nil {
-			return nil, err
-		}
-
-		if len(projects) == 0 {
-			break
-		}
-
-		allProjects = append(allProjects, projects...)
+	return collect(ctx, "asana.GetAllProjects", c.PageSize("projects"), c.GetProjects)
+}
 
-		if nextPage == nil || nextPage.Offset == "" {
-			break
-		}
+// StreamProjects walks every page of projects and invokes fn for each project
+// as the page arrives. Iteration stops at the first error returned by fn.
+func (c *Client) StreamProjects(ctx context.Context, fn func(Project) error) error {
+	return paginate(ctx, "asana.StreamProjects", c.PageSize("projects"), c.GetProjects, eachItem(fn))
+}
 
-		currentOffset = nextPage.Offset
+// GetProjectTaskCount returns the number of tasks in a project in a single
+// request, without listing them
+func (c *Client) GetProjectTaskCount(ctx context.Context, projectGID string) (int, error) {
+	var resp struct {
+		Data struct {
+			NumTasks int `json:"num_tasks"`
+		} `json:"data"`
 	}
-
-	return allProjects, nil
+	err := c.httpClient.GetJSON(ctx, c.baseURL+"/projects/"+url.PathEscape(projectGID)+"/task_counts?opt_fields=num_tasks", &resp)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get task count of project %s: %w", projectGID, apiError(err))
+	}
+	return resp.Data.NumTasks, nil
 }
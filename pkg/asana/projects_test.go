@@ -3,6 +3,7 @@ package asana
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -168,3 +169,60 @@ func TestGetAllProjects_Table(t *testing.T) {
 		})
 	}
 }
+
+func TestStreamProjects_StopsOnCallbackError(t *testing.T) {
+	callCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		json.NewEncoder(w).Encode(ProjectsResponse{
+			Data:     []Project{{GID: "p1"}, {GID: "p2"}},
+			NextPage: &NextPage{Offset: "next"},
+		})
+	}))
+	defer server.Close()
+
+	asanaClient := NewClient(setupMockClient(), "ws", server.URL, 100)
+
+	seen := 0
+	err := asanaClient.StreamProjects(context.Background(), func(p Project) error {
+		seen++
+		return errors.New("storage full")
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "storage full") {
+		t.Fatalf("expected callback error, got %v", err)
+	}
+	if seen != 1 {
+		t.Errorf("expected callback to run once, got %d", seen)
+	}
+	if callCount != 1 {
+		t.Errorf("expected a single page request, got %d", callCount)
+	}
+}
+
+func TestGetProjectTaskCount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/projects/p1/task_counts" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if got := r.URL.Query().Get("opt_fields"); got != "num_tasks" {
+			t.Errorf("expected opt_fields=num_tasks, got %q", got)
+		}
+		w.Write([]byte(`{"data": {"num_tasks": 42}}`))
+	}))
+	defer server.Close()
+
+	asanaClient := NewClient(setupMockClient(), "ws", server.URL, 100)
+
+	n, err := asanaClient.GetProjectTaskCount(context.Background(), "p1")
+	if err != nil {
+		t.Fatalf("GetProjectTaskCount() error = %v", err)
+	}
+	if n != 42 {
+		t.Errorf("expected 42 tasks, got %d", n)
+	}
+	if _, err := asanaClient.GetProjectTaskCount(context.Background(), "gone"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package asana
+
+import (
+	"context"
+	"net/url"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// statusUpdateFields are the status update fields requested from Asana
+const statusUpdateFields = "gid,resource_subtype,title,text,status_type,created_at,created_by,created_by.name,parent,parent.name"
+
+// GetGoals retrieves the goals of the workspace with pagination. Goals need
+// a paid plan; other workspaces get ErrPaymentRequired.
+func (c *Client) GetGoals(ctx context.Context, limit int, offset string) ([]Goal, *NextPage, error) {
+	query := url.Values{"workspace": {c.workspace}}
+	return getPage[Goal](ctx, c, "/goals?"+query.Encode(), "goals", "gid,name", limit, offset)
+}
+
+// StreamGoals walks every page of the workspace's goals and invokes fn for
+// each goal as the page arrives
+func (c *Client) StreamGoals(ctx context.Context, fn func(Goal) error) error {
+	return paginate(ctx, "asana.StreamGoals", c.PageSize("status_updates"), c.GetGoals, eachItem(fn))
+}
+
+// GetPortfolios retrieves the portfolios in the workspace owned by the
+// token's user with pagination. Asana only lists portfolios by owner.
+func (c *Client) GetPortfolios(ctx context.Context, limit int, offset string) ([]Portfolio, *NextPage, error) {
+	query := url.Values{"workspace": {c.workspace}, "owner": {"me"}}
+	return getPage[Portfolio](ctx, c, "/portfolios?"+query.Encode(), "portfolios", "gid,name", limit, offset)
+}
+
+// StreamPortfolios walks every page of the token user's portfolios and
+// invokes fn for each portfolio as the page arrives
+func (c *Client) StreamPortfolios(ctx context.Context, fn func(Portfolio) error) error {
+	return paginate(ctx, "asana.StreamPortfolios", c.PageSize("status_updates"), c.GetPortfolios, eachItem(fn))
+}
+
+// GetStatusUpdates retrieves one page of the status updates of a project,
+// goal or portfolio
+func (c *Client) GetStatusUpdates(ctx context.Context, parentGID string, limit int, offset string) ([]StatusUpdate, *NextPage, error) {
+	query := url.Values{"parent": {parentGID}}
+	return getPage[StatusUpdate](ctx, c, "/status_updates?"+query.Encode(), "status updates", statusUpdateFields, limit, offset)
+}
+
+// StreamStatusUpdates walks every page of the status updates of a project,
+// goal or portfolio and invokes fn for each update as the page arrives
+func (c *Client) StreamStatusUpdates(ctx context.Context, parentGID string, fn func(StatusUpdate) error) error {
+	fetch := func(ctx context.Context, limit int, offset string) ([]StatusUpdate, *NextPage, error) {
+		return c.GetStatusUpdates(ctx, parentGID, limit, offset)
+	}
+	return paginate(ctx, "asana.StreamStatusUpdates", c.PageSize("status_updates"), fetch, eachItem(fn),
+		attribute.String("asana.parent_gid", parentGID))
+}
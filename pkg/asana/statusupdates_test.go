@@ -0,0 +1,58 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusUpdates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case r.URL.Path == "/goals" && q.Get("workspace") == "ws":
+			json.NewEncoder(w).Encode(map[string]any{"data": []Goal{{GID: "g1"}}})
+		case r.URL.Path == "/portfolios" && q.Get("workspace") == "ws" && q.Get("owner") == "me":
+			json.NewEncoder(w).Encode(map[string]any{"data": []Portfolio{{GID: "pf1"}}})
+		case r.URL.Path == "/status_updates" && q.Get("parent") == "g1":
+			json.NewEncoder(w).Encode(map[string]any{"data": []StatusUpdate{
+				{GID: "s1", StatusType: "on_track", Parent: &ParentRef{GID: "g1", ResourceType: "goal"}},
+			}})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	asanaClient := NewClient(setupMockClient(), "ws", server.URL, 100)
+	ctx := context.Background()
+
+	var gids []string
+	collect := func(gid string) error {
+		gids = append(gids, gid)
+		return nil
+	}
+	if err := asanaClient.StreamGoals(ctx, func(g Goal) error { return collect(g.GID) }); err != nil {
+		t.Fatalf("StreamGoals() error = %v", err)
+	}
+	if err := asanaClient.StreamPortfolios(ctx, func(p Portfolio) error { return collect(p.GID) }); err != nil {
+		t.Fatalf("StreamPortfolios() error = %v", err)
+	}
+	var updates []StatusUpdate
+	err := asanaClient.StreamStatusUpdates(ctx, "g1", func(s StatusUpdate) error {
+		updates = append(updates, s)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamStatusUpdates() error = %v", err)
+	}
+
+	if len(gids) != 2 || gids[0] != "g1" || gids[1] != "pf1" {
+		t.Errorf("expected goal g1 and portfolio pf1, got %v", gids)
+	}
+	if len(updates) != 1 || updates[0].Parent.ResourceType != "goal" {
+		t.Errorf("expected one goal status update, got %+v", updates)
+	}
+}
@@ -0,0 +1,76 @@
+package asana
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Task field presets, selecting how much of each task is requested
+const (
+	// TaskFieldsMinimal requests what incremental syncs and reconciliation
+	// need: names, completion and timestamps
+	TaskFieldsMinimal = "minimal"
+	// TaskFieldsStandard adds notes, due dates, assignee, projects and
+	// compact custom field values, whose enum options are referenced by GID
+	TaskFieldsStandard = "standard"
+	// TaskFieldsFull adds rich text notes, start and due times, assignee
+	// names, section memberships, named enum options, followers and likes
+	TaskFieldsFull = "full"
+)
+
+const (
+	minimalTaskFields  = "gid,name,completed,completed_at,created_at,modified_at"
+	standardTaskFields = minimalTaskFields + ",notes,due_on,assignee,projects," +
+		"custom_fields.name,custom_fields.resource_subtype,custom_fields.display_value,custom_fields.text_value," +
+		"custom_fields.number_value,custom_fields.enum_value.gid,custom_fields.multi_enum_values.gid"
+	fullTaskFields = standardTaskFields + ",html_notes,due_at,start_on,assignee.name,assignee.email,projects.name," +
+		"memberships.project.name,memberships.section.name,custom_fields.enum_value.name,custom_fields.enum_value.color," +
+		"custom_fields.multi_enum_values.name,custom_fields.multi_enum_values.color,followers.name,num_likes,likes.user.name"
+)
+
+// taskFieldPresets maps each preset to its opt_fields
+var taskFieldPresets = map[string]string{
+	TaskFieldsMinimal:  minimalTaskFields,
+	TaskFieldsStandard: standardTaskFields,
+	TaskFieldsFull:     fullTaskFields,
+}
+
+// GetTasks retrieves the tasks of a single project with pagination
+func (c *Client) GetTasks(ctx context.Context, projectGID string, limit int, offset string) ([]Task, *NextPage, error) {
+	return getPage[Task](ctx, c, "/projects/"+projectGID+"/tasks", "tasks", c.taskFields, limit, offset)
+}
+
+// StreamTasks walks every page of a project's tasks and invokes fn for each
+// task as the page arrives. Iteration stops at the first error returned by fn.
+func (c *Client) StreamTasks(ctx context.Context, projectGID string, fn func(Task) error) error {
+	fetch := func(ctx context.Context, limit int, offset string) ([]Task, *NextPage, error) {
+		return c.GetTasks(ctx, projectGID, limit, offset)
+	}
+	return paginate(ctx, "asana.StreamTasks", c.PageSize("tasks"), fetch, eachItem(fn),
+		attribute.String("asana.project_gid", projectGID))
+}
+
+// GetTasksModifiedSince retrieves one page of a project's tasks modified at
+// or after since. It uses the task query endpoint, which unlike task search
+// is available on every plan and paginates without a result cap.
+func (c *Client) GetTasksModifiedSince(ctx context.Context, projectGID string, since time.Time, limit int, offset string) ([]Task, *NextPage, error) {
+	query := url.Values{
+		"project":        {projectGID},
+		"modified_since": {since.UTC().Format(time.RFC3339Nano)},
+	}
+	return getPage[Task](ctx, c, "/tasks?"+query.Encode(), "tasks", c.taskFields, limit, offset)
+}
+
+// StreamTasksModifiedSince walks every page of a project's tasks modified
+// at or after since, invoking fn for each task as the page arrives
+func (c *Client) StreamTasksModifiedSince(ctx context.Context, projectGID string, since time.Time, fn func(Task) error) error {
+	fetch := func(ctx context.Context, limit int, offset string) ([]Task, *NextPage, error) {
+		return c.GetTasksModifiedSince(ctx, projectGID, since, limit, offset)
+	}
+	return paginate(ctx, "asana.StreamTasksModifiedSince", c.PageSize("tasks"), fetch, eachItem(fn),
+		attribute.String("asana.project_gid", projectGID),
+		attribute.String("asana.modified_since", since.UTC().Format(time.RFC3339)))
+}
@@ -0,0 +1,187 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetTasks_Table(t *testing.T) {
+	tests := []struct {
+		name          string
+		handler       http.HandlerFunc
+		expectErr     bool
+		expectedCount int
+		errContains   string
+	}{
+		{
+			name: "Successful retrieval scoped to project",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/projects/p1/tasks" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				json.NewEncoder(w).Encode(TasksResponse{
+					Data: []Task{{GID: "t1"}, {GID: "t2"}},
+				})
+			},
+			expectErr:     false,
+			expectedCount: 2,
+		},
+		{
+			name: "API 500 Error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			expectErr:   true,
+			errContains: "failed to get tasks",
+		},
+		{
+			name: "Malformed JSON response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{ "data": [ `))
+			},
+			expectErr:   true,
+			errContains: "failed to parse tasks response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			asanaClient := NewClient(setupMockClient(), "ws", server.URL, 100)
+
+			tasks, _, err := asanaClient.GetTasks(context.Background(), "p1", 100, "")
+
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("expected error: %v, got: %v", tt.expectErr, err)
+			}
+			if tt.expectErr && !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %q", tt.errContains, err.Error())
+			}
+			if !tt.expectErr && len(tasks) != tt.expectedCount {
+				t.Errorf("expected %d tasks, got %d", tt.expectedCount, len(tasks))
+			}
+		})
+	}
+}
+
+func TestStreamTasks_Pagination(t *testing.T) {
+	pages := []TasksResponse{
+		{Data: []Task{{GID: "1"}}, NextPage: &NextPage{Offset: "o1"}},
+		{Data: []Task{{GID: "2"}}, NextPage: nil},
+	}
+
+	callCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if callCount < len(pages) {
+			json.NewEncoder(w).Encode(pages[callCount])
+			callCount++
+		}
+	}))
+	defer server.Close()
+
+	asanaClient := NewClient(setupMockClient(), "ws", server.URL, 100)
+
+	var gids []string
+	err := asanaClient.StreamTasks(context.Background(), "p1", func(task Task) error {
+		gids = append(gids, task.GID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(gids) != 2 {
+		t.Errorf("expected 2 tasks, got %d", len(gids))
+	}
+}
+
+func TestStreamTasksModifiedSince(t *testing.T) {
+	since := time.Date(2024, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		queries = append(queries, q.Get("offset"))
+		if r.URL.Path != "/tasks" || q.Get("project") != "p1" || q.Get("modified_since") != "2024-03-01T11:00:00Z" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if q.Get("offset") == "" {
+			json.NewEncoder(w).Encode(TasksResponse{Data: []Task{{GID: "1"}}, NextPage: &NextPage{Offset: "o1"}})
+			return
+		}
+		json.NewEncoder(w).Encode(TasksResponse{Data: []Task{{GID: "2"}}})
+	}))
+	defer server.Close()
+
+	asanaClient := NewClient(setupMockClient(), "ws", server.URL, 100)
+
+	var gids []string
+	err := asanaClient.StreamTasksModifiedSince(context.Background(), "p1", since, func(task Task) error {
+		gids = append(gids, task.GID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(gids) != 2 || len(queries) != 2 || queries[1] != "o1" {
+		t.Errorf("expected 2 tasks over 2 pages, got %v from offsets %q", gids, queries)
+	}
+}
+
+func TestTaskFieldPresets(t *testing.T) {
+	tests := []struct {
+		preset   string
+		contains []string
+		excludes []string
+	}{
+		{preset: "", contains: []string{"notes", "custom_fields.enum_value.gid"}, excludes: []string{"html_notes"}},
+		{preset: TaskFieldsMinimal, contains: []string{"modified_at"}, excludes: []string{"notes", "custom_fields.name"}},
+		{preset: TaskFieldsStandard, contains: []string{"due_on", "assignee"}, excludes: []string{"followers"}},
+		{preset: TaskFieldsFull, contains: []string{"html_notes", "due_at", "memberships.section.name", "followers.name", "likes.user.name", "custom_fields.enum_value.name"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset, func(t *testing.T) {
+			var optFields string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				optFields = r.URL.Query().Get("opt_fields")
+				json.NewEncoder(w).Encode(TasksResponse{Data: []Task{}})
+			}))
+			defer server.Close()
+
+			asanaClient, err := NewClientWithOptions(setupMockClient(), "ws", server.URL, ClientOptions{TaskFields: tt.preset})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, _, err := asanaClient.GetTasks(context.Background(), "p1", 100, ""); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			fields := make(map[string]bool)
+			for _, f := range strings.Split(optFields, ",") {
+				fields[f] = true
+			}
+			for _, f := range tt.contains {
+				if !fields[f] {
+					t.Errorf("expected %s in opt_fields %q", f, optFields)
+				}
+			}
+			for _, f := range tt.excludes {
+				if fields[f] {
+					t.Errorf("did not expect %s in opt_fields %q", f, optFields)
+				}
+			}
+		})
+	}
+
+	if _, err := NewClientWithOptions(setupMockClient(), "ws", "", ClientOptions{TaskFields: "everything"}); err == nil {
+		t.Error("expected an error for an unknown preset")
+	}
+}
@@ -0,0 +1,14 @@
+package asana
+
+import "context"
+
+// GetTeams retrieves the teams of the workspace with pagination
+func (c *Client) GetTeams(ctx context.Context, limit int, offset string) ([]Team, *NextPage, error) {
+	return getPage[Team](ctx, c, "/workspaces/"+c.workspace+"/teams", "teams", "gid,name", limit, offset)
+}
+
+// StreamTeams walks every page of teams and invokes fn for each team as the
+// page arrives. Iteration stops at the first error returned by fn.
+func (c *Client) StreamTeams(ctx context.Context, fn func(Team) error) error {
+	return paginate(ctx, "asana.StreamTeams", c.PageSize("teams"), c.GetTeams, eachItem(fn))
+}
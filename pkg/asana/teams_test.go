@@ -0,0 +1,72 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetTeams_Table(t *testing.T) {
+	tests := []struct {
+		name          string
+		handler       http.HandlerFunc
+		expectErr     bool
+		expectedCount int
+		errContains   string
+	}{
+		{
+			name: "Successful retrieval",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/workspaces/ws/teams" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				json.NewEncoder(w).Encode(TeamsResponse{
+					Data: []Team{{GID: "team1", Name: "Platform"}},
+				})
+			},
+			expectErr:     false,
+			expectedCount: 1,
+		},
+		{
+			name: "API 500 Error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			expectErr:   true,
+			errContains: "failed to get teams",
+		},
+		{
+			name: "Malformed JSON response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{ "data": `))
+			},
+			expectErr:   true,
+			errContains: "failed to parse teams response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			asanaClient := NewClient(setupMockClient(), "ws", server.URL, 100)
+
+			teams, _, err := asanaClient.GetTeams(context.Background(), 100, "")
+
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("expected error: %v, got: %v", tt.expectErr, err)
+			}
+			if tt.expectErr && !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %q", tt.errContains, err.Error())
+			}
+			if !tt.expectErr && len(teams) != tt.expectedCount {
+				t.Errorf("expected %d teams, got %d", tt.expectedCount, len(teams))
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package asana
+
+import (
+	"context"
+
+	"github.com/ioanzicu/asana-extractor/pkg/client"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer emits one span per pagination loop; the HTTP spans of each page
+// request are nested beneath it
+var tracer = otel.Tracer("github.com/ioanzicu/asana-extractor/pkg/asana")
+
+// startPaginationSpan starts the span wrapping a full pagination walk
+func startPaginationSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endPaginationSpan records the pages and items walked and the outcome, then
+// ends span. Pages are also tallied into the run's client.Usage.
+func endPaginationSpan(ctx context.Context, span trace.Span, pages, items int, err error) {
+	client.RecordPages(ctx, pages)
+
+	span.SetAttributes(
+		attribute.Int("asana.pages", pages),
+		attribute.Int("asana.items", items),
+	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -9,6 +9,32 @@ type User struct {
 	Name         string      `json:"name"`
 	Email        string      `json:"email,omitempty"`
 	Workspaces   []Workspace `json:"workspaces,omitempty"`
+	Photo        *Photo      `json:"photo,omitempty"`
+}
+
+// Photo holds the URLs of a user's profile photo in each size. Sizes the
+// user has no photo in are empty.
+type Photo struct {
+	Image21x21     string `json:"image_21x21,omitempty"`
+	Image27x27     string `json:"image_27x27,omitempty"`
+	Image36x36     string `json:"image_36x36,omitempty"`
+	Image60x60     string `json:"image_60x60,omitempty"`
+	Image128x128   string `json:"image_128x128,omitempty"`
+	Image1024x1024 string `json:"image_1024x1024,omitempty"`
+}
+
+// Largest returns the URL of the largest size of the photo, or "" if there
+// is none
+func (p *Photo) Largest() string {
+	if p == nil {
+		return ""
+	}
+	for _, u := range []string{p.Image1024x1024, p.Image128x128, p.Image60x60, p.Image36x36, p.Image27x27, p.Image21x21} {
+		if u != "" {
+			return u
+		}
+	}
+	return ""
 }
 
 // Project represents an Asana project
@@ -26,6 +52,184 @@ type Project struct {
 	Team         *Team      `json:"team,omitempty"`
 }
 
+// Task represents an Asana task
+type Task struct {
+	GID          string     `json:"gid"`
+	ResourceType string     `json:"resource_type"`
+	Name         string     `json:"name"`
+	Notes        string     `json:"notes,omitempty"`
+	Completed    bool       `json:"completed"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ModifiedAt   time.Time  `json:"modified_at"`
+	DueOn        string     `json:"due_on,omitempty"`
+	Assignee     *User      `json:"assignee,omitempty"`
+	Projects     []Project  `json:"projects,omitempty"`
+
+	// Only requested with the full task field preset
+	HTMLNotes   string       `json:"html_notes,omitempty"`
+	DueAt       *time.Time   `json:"due_at,omitempty"`
+	StartOn     string       `json:"start_on,omitempty"`
+	Memberships []Membership `json:"memberships,omitempty"`
+	Followers   []User       `json:"followers,omitempty"`
+	NumLikes    int          `json:"num_likes,omitempty"`
+	Likes       []Like       `json:"likes,omitempty"`
+	// NotesMarkdown is not an Asana field: the extractor can replace
+	// HTMLNotes with it
+	NotesMarkdown string `json:"notes_markdown,omitempty"`
+
+	// CustomFields holds the task's custom field values. Enum values carry
+	// only option GIDs; resolve them with the workspace's CustomField
+	// definitions.
+	CustomFields []CustomFieldValue `json:"custom_fields,omitempty"`
+}
+
+// Membership places a task in a project and a section of it
+type Membership struct {
+	Project *Project `json:"project,omitempty"`
+	Section *Section `json:"section,omitempty"`
+}
+
+// Section is a section of a project
+type Section struct {
+	GID  string `json:"gid"`
+	Name string `json:"name,omitempty"`
+}
+
+// Like is a user's like on a task
+type Like struct {
+	GID  string `json:"gid"`
+	User *User  `json:"user,omitempty"`
+}
+
+// CustomFieldValue is the value of a custom field on a task
+type CustomFieldValue struct {
+	GID             string       `json:"gid"`
+	Name            string       `json:"name"`
+	ResourceSubtype string       `json:"resource_subtype"`
+	DisplayValue    *string      `json:"display_value"`
+	TextValue       *string      `json:"text_value,omitempty"`
+	NumberValue     *float64     `json:"number_value,omitempty"`
+	EnumValue       *EnumOption  `json:"enum_value,omitempty"`
+	MultiEnumValues []EnumOption `json:"multi_enum_values,omitempty"`
+}
+
+// CustomField is the definition of a custom field in a workspace
+type CustomField struct {
+	GID             string       `json:"gid"`
+	ResourceType    string       `json:"resource_type"`
+	Name            string       `json:"name"`
+	ResourceSubtype string       `json:"resource_subtype"`
+	EnumOptions     []EnumOption `json:"enum_options,omitempty"`
+}
+
+// EnumOption is an option of an enum or multi-enum custom field
+type EnumOption struct {
+	GID     string `json:"gid"`
+	Name    string `json:"name,omitempty"`
+	Color   string `json:"color,omitempty"`
+	Enabled *bool  `json:"enabled,omitempty"`
+}
+
+// Goal represents an Asana goal
+type Goal struct {
+	GID          string `json:"gid"`
+	ResourceType string `json:"resource_type"`
+	Name         string `json:"name"`
+}
+
+// Portfolio represents an Asana portfolio
+type Portfolio struct {
+	GID          string `json:"gid"`
+	ResourceType string `json:"resource_type"`
+	Name         string `json:"name"`
+}
+
+// StatusUpdate represents a status update posted on a project, goal or
+// portfolio, its Parent
+type StatusUpdate struct {
+	GID             string     `json:"gid"`
+	ResourceType    string     `json:"resource_type"`
+	ResourceSubtype string     `json:"resource_subtype,omitempty"`
+	Title           string     `json:"title"`
+	Text            string     `json:"text,omitempty"`
+	StatusType      string     `json:"status_type"`
+	CreatedAt       time.Time  `json:"created_at"`
+	CreatedBy       *User      `json:"created_by,omitempty"`
+	Parent          *ParentRef `json:"parent,omitempty"`
+}
+
+// Attachment is a file attached to a task. DownloadURL is only set for
+// files hosted by Asana, and expires a few minutes after it was fetched.
+type Attachment struct {
+	GID             string     `json:"gid"`
+	ResourceType    string     `json:"resource_type"`
+	ResourceSubtype string     `json:"resource_subtype,omitempty"`
+	Name            string     `json:"name"`
+	Host            string     `json:"host"`
+	Size            int64      `json:"size,omitempty"`
+	DownloadURL     string     `json:"download_url,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	Parent          *ParentRef `json:"parent,omitempty"`
+}
+
+// ParentRef is a compact reference to the object a resource belongs to
+type ParentRef struct {
+	GID          string `json:"gid"`
+	ResourceType string `json:"resource_type"`
+	Name         string `json:"name,omitempty"`
+}
+
+// UserTaskList represents a user's My Tasks list. Tasks holds the
+// incomplete tasks in the list when it was extracted.
+type UserTaskList struct {
+	GID          string     `json:"gid"`
+	ResourceType string     `json:"resource_type"`
+	Name         string     `json:"name"`
+	Owner        *User      `json:"owner,omitempty"`
+	Workspace    *Workspace `json:"workspace,omitempty"`
+	Tasks        []Task     `json:"tasks"`
+}
+
+// AuditLogEvent is an entry of an Enterprise organization's audit log.
+// Details vary by event type and are kept as returned.
+type AuditLogEvent struct {
+	GID           string               `json:"gid"`
+	CreatedAt     time.Time            `json:"created_at"`
+	EventType     string               `json:"event_type"`
+	EventCategory string               `json:"event_category"`
+	Actor         AuditLogActor        `json:"actor"`
+	Resource      AuditLogResource     `json:"resource"`
+	Details       map[string]any       `json:"details,omitempty"`
+	Context       AuditLogEventContext `json:"context"`
+}
+
+// AuditLogActor is who caused an audit log event
+type AuditLogActor struct {
+	ActorType string `json:"actor_type"`
+	GID       string `json:"gid,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Email     string `json:"email,omitempty"`
+}
+
+// AuditLogResource is the object an audit log event is about
+type AuditLogResource struct {
+	ResourceType    string `json:"resource_type"`
+	ResourceSubtype string `json:"resource_subtype,omitempty"`
+	GID             string `json:"gid"`
+	Name            string `json:"name,omitempty"`
+	Email           string `json:"email,omitempty"`
+}
+
+// AuditLogEventContext describes how an audit log event was triggered
+type AuditLogEventContext struct {
+	ContextType             string `json:"context_type"`
+	APIAuthenticationMethod string `json:"api_authentication_method,omitempty"`
+	ClientIPAddress         string `json:"client_ip_address,omitempty"`
+	UserAgent               string `json:"user_agent,omitempty"`
+	OAuthAppName            string `json:"oauth_app_name,omitempty"`
+}
+
 // Workspace represents an Asana workspace
 type Workspace struct {
 	GID          string `json:"gid"`
@@ -40,6 +244,41 @@ type Team struct {
 	Name         string `json:"name"`
 }
 
+// Webhook represents an Asana webhook subscription
+type Webhook struct {
+	GID          string          `json:"gid"`
+	ResourceType string          `json:"resource_type"`
+	Active       bool            `json:"active"`
+	Target       string          `json:"target"`
+	Resource     *Workspace      `json:"resource,omitempty"`
+	Filters      []WebhookFilter `json:"filters,omitempty"`
+}
+
+// WebhookFilter limits the events a webhook delivers
+type WebhookFilter struct {
+	ResourceType string `json:"resource_type"`
+	Action       string `json:"action,omitempty"`
+}
+
+// Event is a single change delivered to a webhook
+type Event struct {
+	Action    string         `json:"action"`
+	Resource  EventResource  `json:"resource"`
+	Parent    *EventResource `json:"parent,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// EventResource identifies the resource an event refers to
+type EventResource struct {
+	GID          string `json:"gid"`
+	ResourceType string `json:"resource_type"`
+}
+
+// EventsPayload is the body Asana posts to a webhook target
+type EventsPayload struct {
+	Events []Event `json:"events"`
+}
+
 // Response wraps API responses
 type Response struct {
 	Data any `json:"data"`
@@ -63,6 +302,29 @@ type ProjectsResponse struct {
 	NextPage *NextPage `json:"next_page"`
 }
 
+// TasksResponse wraps the tasks list response
+type TasksResponse struct {
+	Data     []Task    `json:"data"`
+	NextPage *NextPage `json:"next_page"`
+}
+
+// WorkspacesResponse wraps the workspaces list response
+type WorkspacesResponse struct {
+	Data     []Workspace `json:"data"`
+	NextPage *NextPage   `json:"next_page"`
+}
+
+// TeamsResponse wraps the teams list response
+type TeamsResponse struct {
+	Data     []Team    `json:"data"`
+	NextPage *NextPage `json:"next_page"`
+}
+
+// WebhookResponse wraps a single webhook response
+type WebhookResponse struct {
+	Data Webhook `json:"data"`
+}
+
 // ErrorResponse represents an API error response
 type ErrorResponse struct {
 	Errors []Error `json:"errors"`
@@ -2,87 +2,95 @@ package asana
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/url"
+	"net/http"
 
 	"github.com/ioanzicu/asana-extractor/pkg/client"
 )
 
 // Client is the Asana API client
 type Client struct {
-	httpClient   *client.Client
-	workspace    string
-	baseURL      string
-	userPageSize int
+	httpClient  *client.Client
+	workspace   string
+	baseURL     string
+	pageSizes   map[string]int
+	taskFields  string
+	photoClient *http.Client
 }
 
-// NewClient creates a new Asana API client
+// ClientOptions configures a Client beyond its HTTP client, workspace and
+// base URL
+type ClientOptions struct {
+	// UserPageSize is the page size of user queries, unless PageSizes sets
+	// one for users
+	UserPageSize int
+	// PageSizes maps a resource, such as "projects" or "status_updates", to
+	// the page size of its queries. Resources not listed get the largest
+	// page the API allows.
+	PageSizes map[string]int
+	// TaskFields is the task field preset requested from every task
+	// endpoint: TaskFieldsMinimal, TaskFieldsStandard or TaskFieldsFull.
+	// Empty means TaskFieldsStandard.
+	TaskFields string
+	// PhotoClient downloads user photos and attachment files, which are
+	// served outside the API and must not receive the token. Nil means
+	// http.DefaultClient.
+	PhotoClient *http.Client
+}
+
+// NewClient creates a new Asana API client requesting the standard task
+// fields
 func NewClient(httpClient *client.Client, workspace string, baseURL string, userPageSize int) *Client {
 	return &Client{
-		httpClient:   httpClient,
-		workspace:    workspace,
-		baseURL:      baseURL,
-		userPageSize: userPageSize,
+		httpClient:  httpClient,
+		workspace:   workspace,
+		baseURL:     baseURL,
+		pageSizes:   map[string]int{"users": userPageSize},
+		taskFields:  taskFieldPresets[TaskFieldsStandard],
+		photoClient: http.DefaultClient,
 	}
 }
 
-// GetUsers retrieves users with pagination
-func (c *Client) GetUsers(ctx context.Context, limit int, offset string) ([]User, *NextPage, error) {
-	// Build URL with query parameters
-	u, err := url.Parse(fmt.Sprintf("%s/workspaces/%s/users", c.baseURL, c.workspace))
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to parse URL: %w", err)
+// NewClientWithOptions creates a new Asana API client configured by opts
+func NewClientWithOptions(httpClient *client.Client, workspace string, baseURL string, opts ClientOptions) (*Client, error) {
+	c := NewClient(httpClient, workspace, baseURL, opts.UserPageSize)
+	if opts.TaskFields != "" {
+		fields, ok := taskFieldPresets[opts.TaskFields]
+		if !ok {
+			return nil, fmt.Errorf("unknown task field preset %q", opts.TaskFields)
+		}
+		c.taskFields = fields
 	}
-
-	q := u.Query()
-	q.Set("limit", fmt.Sprintf("%d", limit))
-
-	if offset != "" {
-		q.Set("offset", offset)
+	if opts.PhotoClient != nil {
+		c.photoClient = opts.PhotoClient
 	}
-	q.Set("opt_fields", "gid,name,email,workspaces")
-	u.RawQuery = q.Encode()
-
-	// Make request
-	body, err := c.httpClient.GetBody(ctx, u.String())
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get users: %w", err)
+	for resource, size := range opts.PageSizes {
+		c.pageSizes[resource] = size
 	}
+	return c, nil
+}
 
-	// Parse response
-	var resp UsersResponse
-	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, nil, fmt.Errorf("failed to parse users response: %w", err)
+// PageSize returns the page size of resource's queries
+func (c *Client) PageSize(resource string) int {
+	if size := c.pageSizes[resource]; size > 0 {
+		return size
 	}
+	return maxPageSize
+}
 
-	return resp.Data, resp.NextPage, nil
+// GetUsers retrieves users with pagination
+func (c *Client) GetUsers(ctx context.Context, limit int, offset string) ([]User, *NextPage, error) {
+	return getPage[User](ctx, c, "/workspaces/"+c.workspace+"/users", "users", "gid,name,email,workspaces,photo", limit, offset)
 }
 
 // GetAllUsers retrieves all users by automatically handling pagination
 func (c *Client) GetAllUsers(ctx context.Context) ([]User, error) {
-	var allUsers []User
-	var currentOffset string
-
-	for {
-		users, nextPage, err := c.GetUsers(ctx, c.userPageSize, currentOffset)
-		if err != nil {
-			return nil, err
-		}
-
-		if len(users) == 0 {
-			break
-		}
-
-		allUsers = append(allUsers, users...)
-
-		// If we got fewer results than the page size, we're done
-		if nextPage == nil || nextPage.Offset == "" {
-			break
-		}
-
-		currentOffset = nextPage.Offset
-	}
+	return collect(ctx, "asana.GetAllUsers", c.PageSize("users"), c.GetUsers)
+}
 
-	return allUsers, nil
+// StreamUsers walks every page of users and invokes fn for each user as the
+// page arrives, so callers never hold more than one page in memory.
+// Iteration stops at the first error returned by fn.
+func (c *Client) StreamUsers(ctx context.Context, fn func(User) error) error {
+	return paginate(ctx, "asana.StreamUsers", c.PageSize("users"), c.GetUsers, eachItem(fn))
 }
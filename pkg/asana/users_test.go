@@ -184,3 +184,94 @@ func TestGetAllUsers_Table(t *testing.T) {
 func contains(s, substr string) bool {
 	return fmt.Sprintf("%v", s) != "" && (len(s) >= len(substr))
 }
+
+func TestStreamUsers_Table(t *testing.T) {
+	pages := []UsersResponse{
+		{Data: []User{{GID: "1"}, {GID: "2"}}, NextPage: &NextPage{Offset: "off1"}},
+		{Data: []User{{GID: "3"}}},
+	}
+
+	tests := []struct {
+		name          string
+		failOn        string
+		expectErr     bool
+		expectedSeen  int
+		expectedCalls int
+	}{
+		{
+			name:          "Visits every user across pages",
+			expectErr:     false,
+			expectedSeen:  3,
+			expectedCalls: 2,
+		},
+		{
+			name:          "Callback error stops pagination",
+			failOn:        "2",
+			expectErr:     true,
+			expectedSeen:  2,
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			callIdx := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if callIdx < len(pages) {
+					json.NewEncoder(w).Encode(pages[callIdx])
+					callIdx++
+				}
+			}))
+			defer server.Close()
+
+			asanaClient := NewClient(setupMockClient(), "ws", server.URL, 2)
+
+			seen := 0
+			err := asanaClient.StreamUsers(context.Background(), func(u User) error {
+				seen++
+				if u.GID == tc.failOn {
+					return fmt.Errorf("stop")
+				}
+				return nil
+			})
+
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expectError %v, got %v", tc.expectErr, err)
+			}
+			if seen != tc.expectedSeen {
+				t.Errorf("expected %d users visited, got %d", tc.expectedSeen, seen)
+			}
+			if callIdx != tc.expectedCalls {
+				t.Errorf("expected %d page requests, got %d", tc.expectedCalls, callIdx)
+			}
+		})
+	}
+}
+
+func TestClient_PageSizes(t *testing.T) {
+	limits := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limits[r.URL.Path] = r.URL.Query().Get("limit")
+		w.Write([]byte(`{"data": []}`))
+	}))
+	defer server.Close()
+
+	c, err := NewClientWithOptions(setupMockClient(), "ws", server.URL, ClientOptions{
+		UserPageSize: 50,
+		PageSizes:    map[string]int{"projects": 20},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	c.StreamUsers(ctx, func(User) error { return nil })
+	c.StreamProjects(ctx, func(Project) error { return nil })
+	c.StreamTeams(ctx, func(Team) error { return nil })
+
+	want := map[string]string{"/workspaces/ws/users": "50", "/workspaces/ws/projects": "20", "/workspaces/ws/teams": "100"}
+	for path, limit := range want {
+		if limits[path] != limit {
+			t.Errorf("Expected limit %s for %s, got %q (all: %v)", limit, path, limits[path], limits)
+		}
+	}
+}
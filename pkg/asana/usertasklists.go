@@ -0,0 +1,43 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// GetUserTaskList retrieves the My Tasks list of a user in the workspace.
+// The returned list has no Tasks; see StreamUserTaskListTasks.
+func (c *Client) GetUserTaskList(ctx context.Context, userGID string) (*UserTaskList, error) {
+	query := url.Values{
+		"workspace":  {c.workspace},
+		"opt_fields": {"gid,name,owner,owner.name,workspace,workspace.name"},
+	}
+
+	var resp struct {
+		Data UserTaskList `json:"data"`
+	}
+	err := c.httpClient.GetJSON(ctx, c.baseURL+"/users/"+url.PathEscape(userGID)+"/user_task_list?"+query.Encode(), &resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user task list of %s: %w", userGID, apiError(err))
+	}
+	return &resp.Data, nil
+}
+
+// GetUserTaskListTasks retrieves one page of the incomplete tasks in a user
+// task list
+func (c *Client) GetUserTaskListTasks(ctx context.Context, listGID string, limit int, offset string) ([]Task, *NextPage, error) {
+	return getPage[Task](ctx, c, "/user_task_lists/"+listGID+"/tasks?completed_since=now", "user task list tasks", c.taskFields, limit, offset)
+}
+
+// StreamUserTaskListTasks walks every page of the incomplete tasks in a
+// user task list and invokes fn for each task as the page arrives
+func (c *Client) StreamUserTaskListTasks(ctx context.Context, listGID string, fn func(Task) error) error {
+	fetch := func(ctx context.Context, limit int, offset string) ([]Task, *NextPage, error) {
+		return c.GetUserTaskListTasks(ctx, listGID, limit, offset)
+	}
+	return paginate(ctx, "asana.StreamUserTaskListTasks", c.PageSize("user_task_lists"), fetch, eachItem(fn),
+		attribute.String("asana.user_task_list_gid", listGID))
+}
@@ -0,0 +1,66 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetUserTaskList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/u1/user_task_list" || r.URL.Query().Get("workspace") != "ws" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[{"message":"Unknown object"}]}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"gid":"l1","name":"My Tasks","owner":{"gid":"u1"}}}`))
+	}))
+	defer server.Close()
+
+	asanaClient := NewClient(setupMockClient(), "ws", server.URL, 100)
+
+	list, err := asanaClient.GetUserTaskList(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if list.GID != "l1" || list.Owner == nil || list.Owner.GID != "u1" {
+		t.Errorf("unexpected list: %+v", list)
+	}
+
+	if _, err := asanaClient.GetUserTaskList(context.Background(), "u2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a user without a list, got %v", err)
+	}
+}
+
+func TestStreamUserTaskListTasks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/user_task_lists/l1/tasks" || q.Get("completed_since") != "now" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if q.Get("offset") == "" {
+			json.NewEncoder(w).Encode(TasksResponse{Data: []Task{{GID: "1"}}, NextPage: &NextPage{Offset: "o1"}})
+			return
+		}
+		json.NewEncoder(w).Encode(TasksResponse{Data: []Task{{GID: "2"}}})
+	}))
+	defer server.Close()
+
+	asanaClient := NewClient(setupMockClient(), "ws", server.URL, 100)
+
+	var gids []string
+	err := asanaClient.StreamUserTaskListTasks(context.Background(), "l1", func(task Task) error {
+		gids = append(gids, task.GID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(gids) != 2 {
+		t.Errorf("expected 2 tasks over 2 pages, got %v", gids)
+	}
+}
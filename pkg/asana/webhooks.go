@@ -0,0 +1,38 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// CreateWebhook subscribes target to changes in the configured workspace.
+// Asana performs the X-Hook-Secret handshake against target before this call
+// returns, so the receiver must already be listening.
+func (c *Client) CreateWebhook(ctx context.Context, target string, filters []WebhookFilter) (*Webhook, error) {
+	body, err := c.httpClient.PostJSON(ctx, c.baseURL+"/webhooks", map[string]any{
+		"data": map[string]any{
+			"resource": c.workspace,
+			"target":   target,
+			"filters":  filters,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", apiError(err))
+	}
+
+	var webhookResp WebhookResponse
+	if err := json.Unmarshal(body, &webhookResp); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook response: %w", err)
+	}
+
+	return &webhookResp.Data, nil
+}
+
+// DeleteWebhook removes a webhook subscription
+func (c *Client) DeleteWebhook(ctx context.Context, gid string) error {
+	if _, err := c.httpClient.DeleteJSON(ctx, fmt.Sprintf("%s/webhooks/%s", c.baseURL, gid), nil); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", apiError(err))
+	}
+	return nil
+}
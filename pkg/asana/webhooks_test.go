@@ -0,0 +1,88 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateWebhook_Table(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		expectErr   bool
+		errContains string
+	}{
+		{name: "Created", status: http.StatusCreated},
+		{name: "Handshake failed", status: http.StatusBadRequest, expectErr: true, errContains: "failed to create webhook"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received map[string]map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/webhooks" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				json.NewDecoder(r.Body).Decode(&received)
+				w.WriteHeader(tt.status)
+				json.NewEncoder(w).Encode(WebhookResponse{Data: Webhook{GID: "wh1", Active: true}})
+			}))
+			defer server.Close()
+
+			asanaClient := NewClient(setupMockClient(), "ws1", server.URL, 100)
+			webhook, err := asanaClient.CreateWebhook(context.Background(), "https://example.com/hook",
+				[]WebhookFilter{{ResourceType: "project"}})
+
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("expected error: %v, got: %v", tt.expectErr, err)
+			}
+			if tt.expectErr {
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("expected error containing %q, got %q", tt.errContains, err.Error())
+				}
+				return
+			}
+			if webhook.GID != "wh1" {
+				t.Errorf("expected webhook wh1, got %s", webhook.GID)
+			}
+			if received["data"]["resource"] != "ws1" || received["data"]["target"] != "https://example.com/hook" {
+				t.Errorf("unexpected request body: %v", received)
+			}
+		})
+	}
+}
+
+func TestDeleteWebhook_Table(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		expectErr bool
+	}{
+		{name: "Deleted", status: http.StatusOK},
+		{name: "Not found", status: http.StatusNotFound, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodDelete || r.URL.Path != "/webhooks/wh1" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			asanaClient := NewClient(setupMockClient(), "ws1", server.URL, 100)
+			err := asanaClient.DeleteWebhook(context.Background(), "wh1")
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("expected error: %v, got: %v", tt.expectErr, err)
+			}
+		})
+	}
+}
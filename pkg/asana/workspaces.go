@@ -0,0 +1,14 @@
+package asana
+
+import "context"
+
+// GetWorkspaces retrieves the workspaces visible to the token with pagination.
+// Unlike the other resources it is not scoped to the configured workspace.
+func (c *Client) GetWorkspaces(ctx context.Context, limit int, offset string) ([]Workspace, *NextPage, error) {
+	return getPage[Workspace](ctx, c, "/workspaces", "workspaces", "gid,name", limit, offset)
+}
+
+// GetAllWorkspaces retrieves all workspaces by automatically handling pagination
+func (c *Client) GetAllWorkspaces(ctx context.Context) ([]Workspace, error) {
+	return collect(ctx, "asana.GetAllWorkspaces", maxPageSize, c.GetWorkspaces)
+}
@@ -0,0 +1,71 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetAllWorkspaces_Table(t *testing.T) {
+	tests := []struct {
+		name          string
+		pages         []WorkspacesResponse
+		status        int
+		expectErr     bool
+		errContains   string
+		expectedCount int
+	}{
+		{
+			name: "Two-page pagination",
+			pages: []WorkspacesResponse{
+				{Data: []Workspace{{GID: "1", Name: "Acme"}}, NextPage: &NextPage{Offset: "o1"}},
+				{Data: []Workspace{{GID: "2", Name: "Personal"}}},
+			},
+			expectedCount: 2,
+		},
+		{
+			name:        "API error",
+			status:      http.StatusUnauthorized,
+			expectErr:   true,
+			errContains: "failed to get workspaces",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			callCount := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/workspaces" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+					return
+				}
+				if callCount < len(tt.pages) {
+					json.NewEncoder(w).Encode(tt.pages[callCount])
+					callCount++
+				}
+			}))
+			defer server.Close()
+
+			asanaClient := NewClient(setupMockClient(), "", server.URL, 100)
+
+			workspaces, err := asanaClient.GetAllWorkspaces(context.Background())
+
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("expected error: %v, got: %v", tt.expectErr, err)
+			}
+			if tt.expectErr && !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("expected error containing %q, got %q", tt.errContains, err.Error())
+			}
+			if !tt.expectErr && len(workspaces) != tt.expectedCount {
+				t.Errorf("expected %d workspaces, got %d", tt.expectedCount, len(workspaces))
+			}
+		})
+	}
+}
@@ -0,0 +1,392 @@
+// Package asanatest provides a fake Asana API for tests of code that uses
+// pkg/asana or runs the extractor against Asana. The server serves paged
+// users, projects, tasks, teams, goals, portfolios, status updates, user
+// task lists, audit log events and workspaces from fixtures, including task queries by modified_since, and can simulate rate
+// limiting and inject errors.
+//
+//	srv := asanatest.NewServer(asanatest.Fixtures{
+//		Users: []asana.User{{GID: "1", Name: "Ada"}},
+//	})
+//	defer srv.Close()
+//	c := asana.NewClient(httpClient, "ws", srv.URL, 100)
+package asanatest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// Fixtures is the data a Server serves
+type Fixtures struct {
+	Workspaces []asana.Workspace
+	Users      []asana.User
+	Projects   []asana.Project
+	// Tasks holds the tasks of each project, by project GID
+	Tasks map[string][]asana.Task
+	Teams []asana.Team
+	// UserTaskLists holds the tasks in each user's My Tasks list, by user
+	// GID. A user's list has GID "list-<user GID>"; users without an entry
+	// have no list and get 404.
+	UserTaskLists map[string][]asana.Task
+	Goals         []asana.Goal
+	Portfolios    []asana.Portfolio
+	// StatusUpdates holds the status updates of each project, goal or
+	// portfolio, by parent GID
+	StatusUpdates map[string][]asana.StatusUpdate
+	CustomFields  []asana.CustomField
+	// AuditLogEvents are served by creation time for start_at and end_at
+	AuditLogEvents []asana.AuditLogEvent
+
+	// Workspace, if set, is the only workspace whose users, projects and
+	// teams are served; other workspaces get 404
+	Workspace string
+	// Token, if set, is the only bearer token accepted; other requests get
+	// 401
+	Token string
+	// PageSize caps the number of items per page below the requested
+	// limit. Zero serves the requested limit.
+	PageSize int
+}
+
+// Server is a fake Asana API listening on a local port. Its URL is the base
+// URL to configure clients with.
+type Server struct {
+	*httptest.Server
+
+	fixtures Fixtures
+
+	mu         sync.Mutex
+	limited    int
+	retryAfter time.Duration
+	failures   map[string][]failure
+	requests   map[string]int
+}
+
+// failure is an injected error response
+type failure struct {
+	status  int
+	message string
+	// retryAfter is sent as Retry-After with 429 responses
+	retryAfter time.Duration
+}
+
+// NewServer starts a fake Asana API serving fixtures. Close it when done.
+func NewServer(fixtures Fixtures) *Server {
+	s := &Server{
+		fixtures: fixtures,
+		failures: make(map[string][]failure),
+		requests: make(map[string]int),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// RateLimit makes the next n requests fail with 429 Too Many Requests and
+// a Retry-After header of retryAfter, rounded up to whole seconds
+func (s *Server) RateLimit(n int, retryAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limited = n
+	s.retryAfter = retryAfter
+}
+
+// Fail makes the next request for path, such as "/projects/1/tasks", fail
+// with status and an Asana error body carrying message. Calling it several
+// times for a path queues several failures.
+func (s *Server) Fail(path string, status int, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[path] = append(s.failures[path], failure{status: status, message: message})
+}
+
+// Requests returns the number of requests received for path, including
+// failed ones
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+// serveHTTP answers a request from the fixtures
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.fixtures.Token != "" && r.Header.Get("Authorization") != "Bearer "+s.fixtures.Token {
+		writeError(w, http.StatusUnauthorized, "Not Authorized")
+		return
+	}
+	if f, ok := s.next(r.URL.Path); ok {
+		if f.status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", strconv.Itoa(int((f.retryAfter+time.Second-1)/time.Second)))
+		}
+		writeError(w, f.status, f.message)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "workspaces":
+		s.writePage(w, r, s.fixtures.Workspaces)
+	case len(parts) == 3 && parts[0] == "workspaces":
+		if s.fixtures.Workspace != "" && parts[1] != s.fixtures.Workspace {
+			writeError(w, http.StatusNotFound, "workspace: Unknown object: "+parts[1])
+			return
+		}
+		switch parts[2] {
+		case "users":
+			s.writePage(w, r, s.fixtures.Users)
+		case "projects":
+			s.writePage(w, r, s.fixtures.Projects)
+		case "teams":
+			s.writePage(w, r, s.fixtures.Teams)
+		case "custom_fields":
+			s.writePage(w, r, s.fixtures.CustomFields)
+		case "audit_log_events":
+			s.writeAuditLogEvents(w, r)
+		default:
+			writeError(w, http.StatusNotFound, "no route for "+r.URL.Path)
+		}
+	case len(parts) == 3 && parts[0] == "projects" && parts[2] == "tasks":
+		s.writePage(w, r, s.fixtures.Tasks[parts[1]])
+	case len(parts) == 1 && parts[0] == "tasks":
+		s.writeTaskQuery(w, r)
+	case len(parts) == 1 && parts[0] == "goals":
+		s.writeWorkspaceQuery(w, r, s.fixtures.Goals)
+	case len(parts) == 1 && parts[0] == "portfolios":
+		if r.URL.Query().Get("owner") == "" {
+			writeError(w, http.StatusBadRequest, "owner: Missing input")
+			return
+		}
+		s.writeWorkspaceQuery(w, r, s.fixtures.Portfolios)
+	case len(parts) == 1 && parts[0] == "status_updates":
+		if r.URL.Query().Get("parent") == "" {
+			writeError(w, http.StatusBadRequest, "parent: Missing input")
+			return
+		}
+		s.writePage(w, r, s.fixtures.StatusUpdates[r.URL.Query().Get("parent")])
+	case len(parts) == 3 && parts[0] == "users" && parts[2] == "user_task_list":
+		s.writeEntity(w, "user_task_lists", listPrefix+parts[1])
+	case len(parts) == 3 && parts[0] == "user_task_lists" && parts[2] == "tasks":
+		s.writeUserTaskListTasks(w, r, parts[1])
+	case len(parts) == 2:
+		s.writeEntity(w, parts[0], parts[1])
+	default:
+		writeError(w, http.StatusNotFound, "no route for "+r.URL.Path)
+	}
+}
+
+// next counts a request for path and returns the failure to answer it
+// with, if any. Rate limiting applies before injected failures.
+func (s *Server) next(path string) (failure, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests[path]++
+	if s.limited > 0 {
+		s.limited--
+		return failure{status: http.StatusTooManyRequests, message: "You have made too many requests recently.", retryAfter: s.retryAfter}, true
+	}
+	if queued := s.failures[path]; len(queued) > 0 {
+		s.failures[path] = queued[1:]
+		return queued[0], true
+	}
+	return failure{}, false
+}
+
+// writePage writes the page of items selected by the limit and offset
+// query parameters. Offsets are item indices, opaque to clients.
+func (s *Server) writePage(w http.ResponseWriter, r *http.Request, items any) {
+	data, _ := json.Marshal(items)
+	var all []json.RawMessage
+	json.Unmarshal(data, &all)
+
+	start := 0
+	if offset := r.URL.Query().Get("offset"); offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 || n > len(all) {
+			writeError(w, http.StatusBadRequest, "offset: Your pagination token is invalid.")
+			return
+		}
+		start = n
+	}
+	limit := len(all)
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			writeError(w, http.StatusBadRequest, "limit: Must be between 1 and 100.")
+			return
+		}
+		limit = n
+	}
+	if s.fixtures.PageSize > 0 && s.fixtures.PageSize < limit {
+		limit = s.fixtures.PageSize
+	}
+
+	end := min(start+limit, len(all))
+	resp := struct {
+		Data     []json.RawMessage `json:"data"`
+		NextPage *asana.NextPage   `json:"next_page"`
+	}{Data: all[start:end]}
+	if resp.Data == nil {
+		resp.Data = []json.RawMessage{}
+	}
+	if end < len(all) {
+		resp.NextPage = &asana.NextPage{Offset: strconv.Itoa(end)}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// writeTaskQuery answers GET /tasks for a project, keeping the tasks
+// modified at or after modified_since when it is given
+func (s *Server) writeTaskQuery(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("project") == "" {
+		writeError(w, http.StatusBadRequest, "project: Must specify exactly one of project, tag, section, user task list, or assignee + workspace")
+		return
+	}
+	tasks := s.fixtures.Tasks[q.Get("project")]
+	if v := q.Get("modified_since"); v != "" {
+		since, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "modified_since: Not a valid date-time")
+			return
+		}
+		var modified []asana.Task
+		for _, task := range tasks {
+			if !task.ModifiedAt.Before(since) {
+				modified = append(modified, task)
+			}
+		}
+		tasks = modified
+	}
+	s.writePage(w, r, tasks)
+}
+
+// writeWorkspaceQuery writes a page of items listed by workspace query
+// parameter, such as goals, or 404 for a workspace other than
+// Fixtures.Workspace
+func (s *Server) writeWorkspaceQuery(w http.ResponseWriter, r *http.Request, items any) {
+	ws := r.URL.Query().Get("workspace")
+	if ws == "" {
+		writeError(w, http.StatusBadRequest, "workspace: Missing input")
+		return
+	}
+	if s.fixtures.Workspace != "" && ws != s.fixtures.Workspace {
+		writeError(w, http.StatusNotFound, "workspace: Unknown object: "+ws)
+		return
+	}
+	s.writePage(w, r, items)
+}
+
+// writeAuditLogEvents writes a page of the audit log events created at or
+// after start_at and before end_at, when given
+func (s *Server) writeAuditLogEvents(w http.ResponseWriter, r *http.Request) {
+	var bounds [2]time.Time
+	for i, param := range []string{"start_at", "end_at"} {
+		v := r.URL.Query().Get(param)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, param+": Not a valid date-time")
+			return
+		}
+		bounds[i] = t
+	}
+
+	var events []asana.AuditLogEvent
+	for _, event := range s.fixtures.AuditLogEvents {
+		if event.CreatedAt.Before(bounds[0]) || (!bounds[1].IsZero() && !event.CreatedAt.Before(bounds[1])) {
+			continue
+		}
+		events = append(events, event)
+	}
+	s.writePage(w, r, events)
+}
+
+// listPrefix turns a user GID into the GID of their task list
+const listPrefix = "list-"
+
+// writeUserTaskListTasks writes a page of the tasks in a user task list,
+// only the incomplete ones when completed_since is "now"
+func (s *Server) writeUserTaskListTasks(w http.ResponseWriter, r *http.Request, listGID string) {
+	userGID, isList := strings.CutPrefix(listGID, listPrefix)
+	tasks, ok := s.fixtures.UserTaskLists[userGID]
+	if !ok || !isList {
+		writeError(w, http.StatusNotFound, "user_task_list: Unknown object: "+listGID)
+		return
+	}
+	if r.URL.Query().Get("completed_since") == "now" {
+		var incomplete []asana.Task
+		for _, task := range tasks {
+			if !task.Completed {
+				incomplete = append(incomplete, task)
+			}
+		}
+		tasks = incomplete
+	}
+	s.writePage(w, r, tasks)
+}
+
+// writeEntity writes the entity of resource with gid, or 404
+func (s *Server) writeEntity(w http.ResponseWriter, resource, gid string) {
+	var found any
+	var ok bool
+	switch resource {
+	case "users":
+		found, ok = find(s.fixtures.Users, gid, func(u asana.User) string { return u.GID })
+	case "projects":
+		found, ok = find(s.fixtures.Projects, gid, func(p asana.Project) string { return p.GID })
+	case "teams":
+		found, ok = find(s.fixtures.Teams, gid, func(t asana.Team) string { return t.GID })
+	case "tasks":
+		for _, tasks := range s.fixtures.Tasks {
+			if found, ok = find(tasks, gid, func(t asana.Task) string { return t.GID }); ok {
+				break
+			}
+		}
+	case "user_task_lists":
+		userGID, isList := strings.CutPrefix(gid, listPrefix)
+		if _, ok = s.fixtures.UserTaskLists[userGID]; ok && isList {
+			found = asana.UserTaskList{GID: gid, ResourceType: "user_task_list", Name: "My Tasks", Owner: &asana.User{GID: userGID}}
+		}
+		ok = ok && isList
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, resource+": Unknown object: "+gid)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": found})
+}
+
+// find returns the item whose GID is gid
+func find[T any](items []T, gid string, gidOf func(T) string) (T, bool) {
+	for _, item := range items {
+		if gidOf(item) == gid {
+			return item, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// writeError writes an Asana error body
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, asana.ErrorResponse{Errors: []asana.Error{{Message: message}}})
+}
+
+// writeJSON writes v as the JSON response body
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
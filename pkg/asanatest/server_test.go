@@ -0,0 +1,297 @@
+package asanatest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+	"github.com/ioanzicu/asana-extractor/pkg/client"
+	"github.com/ioanzicu/asana-extractor/pkg/ratelimit"
+	"github.com/ioanzicu/asana-extractor/pkg/retry"
+)
+
+// newClient returns an Asana client for srv that retries maxRetries times
+func newClient(srv *Server, token string, maxRetries int) *asana.Client {
+	httpClient := client.New(client.Config{
+		Token: token,
+		RateLimitConfig: ratelimit.Config{
+			RequestsPerMinute:  6000,
+			MaxConcurrentRead:  10,
+			MaxConcurrentWrite: 10,
+		},
+		RetryConfig: retry.Config{MaxRetries: maxRetries, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+	})
+	return asana.NewClient(httpClient, "ws", srv.URL, 2)
+}
+
+func TestServer_Pagination(t *testing.T) {
+	srv := NewServer(Fixtures{
+		Users:    []asana.User{{GID: "u1"}, {GID: "u2"}, {GID: "u3"}},
+		Projects: []asana.Project{{GID: "p1"}, {GID: "p2"}},
+		Tasks:    map[string][]asana.Task{"p1": {{GID: "t1"}, {GID: "t2"}, {GID: "t3"}}},
+		PageSize: 1,
+	})
+	defer srv.Close()
+	c := newClient(srv, "token", 0)
+	ctx := context.Background()
+
+	var users []string
+	if err := c.StreamUsers(ctx, func(u asana.User) error {
+		users = append(users, u.GID)
+		return nil
+	}); err != nil {
+		t.Fatalf("StreamUsers() error = %v", err)
+	}
+	if len(users) != 3 || srv.Requests("/workspaces/ws/users") != 3 {
+		t.Errorf("Expected 3 users in 3 pages, got %v in %d", users, srv.Requests("/workspaces/ws/users"))
+	}
+
+	var tasks []string
+	if err := c.StreamTasks(ctx, "p1", func(task asana.Task) error {
+		tasks = append(tasks, task.GID)
+		return nil
+	}); err != nil {
+		t.Fatalf("StreamTasks() error = %v", err)
+	}
+	if len(tasks) != 3 || tasks[2] != "t3" {
+		t.Errorf("Expected tasks t1-t3, got %v", tasks)
+	}
+
+	if err := c.StreamTasks(ctx, "p2", func(asana.Task) error {
+		t.Error("Expected no tasks for p2")
+		return nil
+	}); err != nil {
+		t.Errorf("StreamTasks() for a project without tasks error = %v", err)
+	}
+}
+
+func TestServer_Exists(t *testing.T) {
+	srv := NewServer(Fixtures{
+		Projects: []asana.Project{{GID: "p1"}},
+		Tasks:    map[string][]asana.Task{"p1": {{GID: "t1"}}},
+	})
+	defer srv.Close()
+	c := newClient(srv, "token", 0)
+
+	tests := []struct {
+		resource string
+		gid      string
+		want     bool
+	}{
+		{"projects", "p1", true},
+		{"tasks", "t1", true},
+		{"tasks", "t2", false},
+		{"users", "u1", false},
+	}
+	for _, tc := range tests {
+		got, err := c.Exists(context.Background(), tc.resource, tc.gid)
+		if err != nil {
+			t.Fatalf("Exists(%s, %s) error = %v", tc.resource, tc.gid, err)
+		}
+		if got != tc.want {
+			t.Errorf("Exists(%s, %s) = %v, want %v", tc.resource, tc.gid, got, tc.want)
+		}
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fix     Fixtures
+		token   string
+		setup   func(*Server)
+		retries int
+		wantErr error
+	}{
+		{
+			name:    "wrong token is unauthorized",
+			fix:     Fixtures{Token: "secret"},
+			token:   "guess",
+			wantErr: asana.ErrUnauthorized,
+		},
+		{
+			name:    "other workspace is not found",
+			fix:     Fixtures{Workspace: "other"},
+			token:   "token",
+			wantErr: asana.ErrNotFound,
+		},
+		{
+			name:    "injected failure",
+			token:   "token",
+			setup:   func(s *Server) { s.Fail("/workspaces/ws/teams", http.StatusForbidden, "no access") },
+			wantErr: asana.ErrForbidden,
+		},
+		{
+			name:    "rate limit without retries",
+			token:   "token",
+			setup:   func(s *Server) { s.RateLimit(1, 0) },
+			wantErr: asana.ErrRateLimited,
+		},
+		{
+			name:    "rate limit is retried",
+			token:   "token",
+			setup:   func(s *Server) { s.RateLimit(2, 0) },
+			retries: 2,
+		},
+		{
+			name:    "injected failure is used once",
+			token:   "token",
+			setup:   func(s *Server) { s.Fail("/workspaces/ws/teams", http.StatusServiceUnavailable, "down") },
+			retries: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := NewServer(tc.fix)
+			defer srv.Close()
+			if tc.setup != nil {
+				tc.setup(srv)
+			}
+
+			err := newClient(srv, tc.token, tc.retries).StreamTeams(context.Background(), func(asana.Team) error { return nil })
+			if tc.wantErr == nil && err != nil {
+				t.Fatalf("StreamTeams() error = %v", err)
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Fatalf("StreamTeams() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestServer_TasksModifiedSince(t *testing.T) {
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	srv := NewServer(Fixtures{Tasks: map[string][]asana.Task{"p1": {
+		{GID: "old", ModifiedAt: since.Add(-time.Hour)},
+		{GID: "same", ModifiedAt: since},
+		{GID: "new", ModifiedAt: since.Add(time.Hour)},
+	}}})
+	defer srv.Close()
+
+	var gids []string
+	err := newClient(srv, "token", 0).StreamTasksModifiedSince(context.Background(), "p1", since, func(task asana.Task) error {
+		gids = append(gids, task.GID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamTasksModifiedSince() error = %v", err)
+	}
+	if len(gids) != 2 || gids[0] != "same" || gids[1] != "new" {
+		t.Errorf("Expected the tasks modified at or after since, got %v", gids)
+	}
+}
+
+func TestServer_UserTaskLists(t *testing.T) {
+	srv := NewServer(Fixtures{
+		Users:         []asana.User{{GID: "u1"}, {GID: "guest"}},
+		UserTaskLists: map[string][]asana.Task{"u1": {{GID: "t1"}, {GID: "t2", Completed: true}, {GID: "t3"}}},
+		PageSize:      1,
+	})
+	defer srv.Close()
+	c := newClient(srv, "token", 0)
+	ctx := context.Background()
+
+	list, err := c.GetUserTaskList(ctx, "u1")
+	if err != nil {
+		t.Fatalf("GetUserTaskList() error = %v", err)
+	}
+	var gids []string
+	if err := c.StreamUserTaskListTasks(ctx, list.GID, func(task asana.Task) error {
+		gids = append(gids, task.GID)
+		return nil
+	}); err != nil {
+		t.Fatalf("StreamUserTaskListTasks() error = %v", err)
+	}
+	if len(gids) != 2 || gids[1] != "t3" {
+		t.Errorf("Expected the incomplete tasks t1 and t3, got %v", gids)
+	}
+
+	if _, err := c.GetUserTaskList(ctx, "guest"); !errors.Is(err, asana.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a user without a list, got %v", err)
+	}
+	if ok, err := c.Exists(ctx, "user_task_lists", list.GID); !ok || err != nil {
+		t.Errorf("Exists() = %v, %v for an existing list", ok, err)
+	}
+}
+
+func TestServer_AuditLogEvents(t *testing.T) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	srv := NewServer(Fixtures{AuditLogEvents: []asana.AuditLogEvent{
+		{GID: "before", CreatedAt: start.Add(-time.Second)},
+		{GID: "first", CreatedAt: start},
+		{GID: "last", CreatedAt: start.Add(time.Hour - time.Second)},
+		{GID: "after", CreatedAt: start.Add(time.Hour)},
+	}})
+	defer srv.Close()
+
+	var gids []string
+	err := newClient(srv, "token", 0).StreamAuditLogEvents(context.Background(), start, start.Add(time.Hour), func(e asana.AuditLogEvent) error {
+		gids = append(gids, e.GID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamAuditLogEvents() error = %v", err)
+	}
+	if len(gids) != 2 || gids[0] != "first" || gids[1] != "last" {
+		t.Errorf("Expected the events in [start, end), got %v", gids)
+	}
+}
+
+func TestServer_StatusUpdates(t *testing.T) {
+	srv := NewServer(Fixtures{
+		Goals:      []asana.Goal{{GID: "g1"}},
+		Portfolios: []asana.Portfolio{{GID: "pf1"}},
+		StatusUpdates: map[string][]asana.StatusUpdate{
+			"g1": {{GID: "s1", StatusType: "on_track"}, {GID: "s2", StatusType: "at_risk"}},
+		},
+	})
+	defer srv.Close()
+	c := newClient(srv, "token", 0)
+	ctx := context.Background()
+
+	var parents []string
+	c.StreamGoals(ctx, func(g asana.Goal) error { parents = append(parents, g.GID); return nil })
+	c.StreamPortfolios(ctx, func(p asana.Portfolio) error { parents = append(parents, p.GID); return nil })
+	if len(parents) != 2 {
+		t.Fatalf("Expected goal and portfolio, got %v", parents)
+	}
+
+	var updates []string
+	for _, parent := range parents {
+		if err := c.StreamStatusUpdates(ctx, parent, func(s asana.StatusUpdate) error {
+			updates = append(updates, s.GID)
+			return nil
+		}); err != nil {
+			t.Fatalf("StreamStatusUpdates(%s) error = %v", parent, err)
+		}
+	}
+	if len(updates) != 2 || updates[1] != "s2" {
+		t.Errorf("Expected the goal's two status updates, got %v", updates)
+	}
+}
+
+func TestServer_CustomFields(t *testing.T) {
+	srv := NewServer(Fixtures{
+		CustomFields: []asana.CustomField{
+			{GID: "f1", ResourceSubtype: "enum", EnumOptions: []asana.EnumOption{{GID: "o1", Name: "High"}}},
+			{GID: "f2", ResourceSubtype: "number"},
+		},
+	})
+	defer srv.Close()
+	c := newClient(srv, "token", 0)
+
+	var fields []asana.CustomField
+	err := c.StreamCustomFields(context.Background(), func(f asana.CustomField) error {
+		fields = append(fields, f)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamCustomFields() error = %v", err)
+	}
+	if len(fields) != 2 || len(fields[0].EnumOptions) != 1 || fields[0].EnumOptions[0].Name != "High" {
+		t.Errorf("Expected both fields with their options, got %+v", fields)
+	}
+}
@@ -0,0 +1,218 @@
+// Package avro encodes Go structs as Apache Avro, writes Avro object
+// container files and registers schemas with a Confluent Schema Registry.
+// Schemas are derived from the structs' JSON tags, so Avro records carry the
+// same field names as the JSON output.
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Namespace is the namespace of every generated record schema
+const Namespace = "asana"
+
+// Codec encodes values of one struct type as Avro binary data
+type Codec struct {
+	typ    reflect.Type
+	schema string
+	enc    encoder
+	extra  int
+}
+
+// encoder appends the Avro encoding of v to b
+type encoder func(b []byte, v reflect.Value) []byte
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// NewCodec derives the schema and encoder of v's struct type. Fields map
+// as follows: strings, booleans, integers and floats to string, boolean,
+// long and double; time.Time to a timestamp-millis long; slices to arrays;
+// string-keyed maps to maps; pointers to a union with null; anything else
+// to its JSON encoding as a string. Each of extra adds a string field of
+// that name after v's own, for values that are not part of v, such as the
+// run that wrote the record; Append takes their values.
+func NewCodec(v any, extra ...string) (*Codec, error) {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("avro codecs need a struct, got %T", v)
+	}
+
+	b := &builder{encoders: make(map[reflect.Type]*encoder)}
+	schema, enc := b.build(t)
+	record := schema.(map[string]any)
+	for _, name := range extra {
+		record["fields"] = append(record["fields"].([]any), map[string]any{"name": name, "type": "string"})
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal avro schema: %w", err)
+	}
+	return &Codec{typ: t, schema: string(data), enc: enc, extra: len(extra)}, nil
+}
+
+// Schema returns the Avro schema as JSON
+func (c *Codec) Schema() string {
+	return c.schema
+}
+
+// Append appends the Avro encoding of v, which must be of the codec's type,
+// followed by the values of the codec's extra fields. Missing values are
+// empty strings.
+func (c *Codec) Append(b []byte, v any, extra ...string) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Type() != c.typ {
+		return b, fmt.Errorf("avro codec for %s cannot encode %T", c.typ, v)
+	}
+	if len(extra) > c.extra {
+		return b, fmt.Errorf("avro codec for %s has %d extra fields, got %d values", c.typ, c.extra, len(extra))
+	}
+	b = c.enc(b, rv)
+	for i := range c.extra {
+		var value string
+		if i < len(extra) {
+			value = extra[i]
+		}
+		b = appendString(b, value)
+	}
+	return b, nil
+}
+
+// builder derives schemas and encoders. A record type is defined in full
+// the first time it is used and referred to by name afterwards, as Avro
+// requires; this also makes recursive types work.
+type builder struct {
+	// encoders holds the encoder of each record type defined so far. It is
+	// filled in once the record is built, so recursive references look it
+	// up when encoding.
+	encoders map[reflect.Type]*encoder
+}
+
+// build returns the schema and encoder of t
+func (b *builder) build(t reflect.Type) (any, encoder) {
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "long", "logicalType": "timestamp-millis"}, func(buf []byte, v reflect.Value) []byte {
+			return appendLong(buf, v.Interface().(time.Time).UnixMilli())
+		}
+	case t.Kind() == reflect.String:
+		return "string", func(buf []byte, v reflect.Value) []byte {
+			return appendString(buf, v.String())
+		}
+	case t.Kind() == reflect.Bool:
+		return "boolean", func(buf []byte, v reflect.Value) []byte {
+			if v.Bool() {
+				return append(buf, 1)
+			}
+			return append(buf, 0)
+		}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		return "long", func(buf []byte, v reflect.Value) []byte {
+			return appendLong(buf, v.Int())
+		}
+	case t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint32:
+		return "long", func(buf []byte, v reflect.Value) []byte {
+			return appendLong(buf, int64(v.Uint()))
+		}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return "double", func(buf []byte, v reflect.Value) []byte {
+			return binary.LittleEndian.AppendUint64(buf, math.Float64bits(v.Float()))
+		}
+	case t.Kind() == reflect.Pointer:
+		schema, enc := b.build(t.Elem())
+		return []any{"null", schema}, func(buf []byte, v reflect.Value) []byte {
+			if v.IsNil() {
+				return appendLong(buf, 0)
+			}
+			return enc(appendLong(buf, 1), v.Elem())
+		}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8:
+		schema, enc := b.build(t.Elem())
+		return map[string]any{"type": "array", "items": schema}, func(buf []byte, v reflect.Value) []byte {
+			if v.Len() > 0 {
+				buf = appendLong(buf, int64(v.Len()))
+				for i := 0; i < v.Len(); i++ {
+					buf = enc(buf, v.Index(i))
+				}
+			}
+			return appendLong(buf, 0)
+		}
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+		schema, enc := b.build(t.Elem())
+		return map[string]any{"type": "map", "values": schema}, func(buf []byte, v reflect.Value) []byte {
+			if v.Len() > 0 {
+				buf = appendLong(buf, int64(v.Len()))
+				iter := v.MapRange()
+				for iter.Next() {
+					buf = appendString(buf, iter.Key().String())
+					buf = enc(buf, iter.Value())
+				}
+			}
+			return appendLong(buf, 0)
+		}
+	case t.Kind() == reflect.Struct:
+		return b.record(t)
+	default:
+		return "string", func(buf []byte, v reflect.Value) []byte {
+			data, err := json.Marshal(v.Interface())
+			if err != nil {
+				data = nil
+			}
+			return appendString(buf, string(data))
+		}
+	}
+}
+
+// record returns the schema and encoder of the struct type t
+func (b *builder) record(t reflect.Type) (any, encoder) {
+	if enc, ok := b.encoders[t]; ok {
+		return Namespace + "." + t.Name(), func(buf []byte, v reflect.Value) []byte {
+			return (*enc)(buf, v)
+		}
+	}
+	enc := new(encoder)
+	b.encoders[t] = enc
+
+	type field struct {
+		index int
+		enc   encoder
+	}
+	var fields []field
+	var schemas []any
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		schema, fenc := b.build(f.Type)
+		schemas = append(schemas, map[string]any{"name": name, "type": schema})
+		fields = append(fields, field{index: i, enc: fenc})
+	}
+
+	*enc = func(buf []byte, v reflect.Value) []byte {
+		for _, f := range fields {
+			buf = f.enc(buf, v.Field(f.index))
+		}
+		return buf
+	}
+	return map[string]any{"type": "record", "name": t.Name(), "namespace": Namespace, "fields": schemas}, *enc
+}
+
+// appendLong appends n as a zig-zag varint
+func appendLong(b []byte, n int64) []byte {
+	return binary.AppendUvarint(b, uint64((n<<1)^(n>>63)))
+}
+
+// appendString appends s with its length
+func appendString(b []byte, s string) []byte {
+	return append(appendLong(b, int64(len(s))), s...)
+}
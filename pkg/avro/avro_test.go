@@ -0,0 +1,151 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestCodec_Append(t *testing.T) {
+	type inner struct {
+		N int `json:"n"`
+	}
+	type record struct {
+		Name   string         `json:"name"`
+		Done   bool           `json:"done"`
+		Count  int            `json:"count"`
+		Ratio  float64        `json:"ratio"`
+		At     time.Time      `json:"at"`
+		Parent *inner         `json:"parent,omitempty"`
+		Tags   []string       `json:"tags"`
+		Extra  map[string]int `json:"extra"`
+		Any    any            `json:"any"`
+		hidden string
+	}
+
+	codec, err := NewCodec(record{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := codec.Append(nil, record{
+		Name:  "ab",
+		Done:  true,
+		Count: -2,
+		Ratio: 1,
+		At:    time.UnixMilli(1),
+		Tags:  []string{"x"},
+		Any:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		4, 'a', 'b', // name
+		1,                            // done
+		3,                            // count -2 zig-zagged
+		0, 0, 0, 0, 0, 0, 0xf0, 0x3f, // ratio 1.0
+		2,            // at 1ms
+		0,            // parent null
+		2, 2, 'x', 0, // tags
+		0,                     // extra empty
+		8, 't', 'r', 'u', 'e', // any as JSON
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Append() = %v, want %v", got, want)
+	}
+
+	if _, err := codec.Append(nil, inner{}); err == nil {
+		t.Error("Expected an error encoding another type")
+	}
+}
+
+func TestCodec_ExtraFields(t *testing.T) {
+	type record struct {
+		Name string `json:"name"`
+	}
+	codec, err := NewCodec(record{}, "run_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains([]byte(codec.Schema()), []byte(`{"name":"run_id","type":"string"}`)) {
+		t.Errorf("Expected a run_id string field, got %s", codec.Schema())
+	}
+
+	got, err := codec.Append(nil, record{Name: "a"}, "r1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{2, 'a', 4, 'r', '1'}; !bytes.Equal(got, want) {
+		t.Errorf("Append() = %v, want %v", got, want)
+	}
+	// A missing value is an empty string
+	if got, _ := codec.Append(nil, record{Name: "a"}); !bytes.Equal(got, []byte{2, 'a', 0}) {
+		t.Errorf("Append() without a value = %v", got)
+	}
+	if _, err := codec.Append(nil, record{}, "r1", "extra"); err == nil {
+		t.Error("Expected an error for more values than extra fields")
+	}
+}
+
+func TestNewCodec_Schema(t *testing.T) {
+	codec, err := NewCodec(asana.Task{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+		Fields    []struct {
+			Name string          `json:"name"`
+			Type json.RawMessage `json:"type"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(codec.Schema()), &schema); err != nil {
+		t.Fatalf("Schema is not JSON: %v", err)
+	}
+	if schema.Name != "Task" || schema.Namespace != Namespace {
+		t.Errorf("Expected record asana.Task, got %s.%s", schema.Namespace, schema.Name)
+	}
+
+	types := make(map[string]string)
+	for _, f := range schema.Fields {
+		types[f.Name] = string(f.Type)
+	}
+	if types["gid"] != `"string"` || types["completed"] != `"boolean"` {
+		t.Errorf("Unexpected primitive fields: %v", types)
+	}
+	if !bytes.Contains([]byte(types["assignee"]), []byte(`"name":"User"`)) {
+		t.Errorf("Expected the assignee to define the User record, got %s", types["assignee"])
+	}
+	// Project.owner reuses the User record defined by assignee
+	if !bytes.Contains([]byte(types["projects"]), []byte(`["null","asana.User"]`)) {
+		t.Errorf("Expected project owners to refer to asana.User by name, got %s", types["projects"])
+	}
+
+	if _, err := NewCodec("not a struct"); err == nil {
+		t.Error("Expected an error for a non-struct")
+	}
+}
+
+func TestAppendLong(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want []byte
+	}{
+		{0, []byte{0}},
+		{-1, []byte{1}},
+		{1, []byte{2}},
+		{-64, []byte{0x7f}},
+		{64, []byte{0x80, 0x01}},
+	}
+	for _, tc := range tests {
+		if got := appendLong(nil, tc.n); !bytes.Equal(got, tc.want) {
+			t.Errorf("appendLong(%d) = %v, want %v", tc.n, got, tc.want)
+		}
+	}
+}
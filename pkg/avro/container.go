@@ -0,0 +1,79 @@
+package avro
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+)
+
+// blockSize is how many records a container block holds before it is
+// written out
+const blockSize = 1000
+
+// magic starts every object container file
+var magic = []byte{'O', 'b', 'j', 1}
+
+// Writer writes an Avro object container file: a header carrying the
+// schema, then blocks of records separated by a sync marker. Records are
+// buffered and written a block at a time; Flush writes a partial block.
+type Writer struct {
+	w     io.Writer
+	codec *Codec
+	sync  [16]byte
+	block []byte
+	count int
+}
+
+// NewWriter writes the container header for codec's schema to w
+func NewWriter(w io.Writer, codec *Codec) (*Writer, error) {
+	cw := &Writer{w: w, codec: codec}
+	rand.Read(cw.sync[:])
+
+	header := append([]byte(nil), magic...)
+	// File metadata is a map of bytes values, in a single block
+	header = appendLong(header, 2)
+	header = appendString(header, "avro.schema")
+	header = appendString(header, codec.Schema())
+	header = appendString(header, "avro.codec")
+	header = appendString(header, "null")
+	header = appendLong(header, 0)
+	header = append(header, cw.sync[:]...)
+
+	if _, err := w.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write avro header: %w", err)
+	}
+	return cw, nil
+}
+
+// Write appends v, which must be of the codec's type, and the values of the
+// codec's extra fields to the current block
+func (cw *Writer) Write(v any, extra ...string) error {
+	block, err := cw.codec.Append(cw.block, v, extra...)
+	if err != nil {
+		return err
+	}
+	cw.block = block
+	cw.count++
+	if cw.count >= blockSize {
+		return cw.Flush()
+	}
+	return nil
+}
+
+// Flush writes the buffered records as a block
+func (cw *Writer) Flush() error {
+	if cw.count == 0 {
+		return nil
+	}
+
+	buf := appendLong(nil, int64(cw.count))
+	buf = appendLong(buf, int64(len(cw.block)))
+	buf = append(buf, cw.block...)
+	buf = append(buf, cw.sync[:]...)
+	if _, err := cw.w.Write(buf); err != nil {
+		return fmt.Errorf("failed to write avro block: %w", err)
+	}
+	cw.block = cw.block[:0]
+	cw.count = 0
+	return nil
+}
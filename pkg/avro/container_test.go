@@ -0,0 +1,92 @@
+package avro
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+// readLong reads a zig-zag varint
+func readLong(t *testing.T, r *bufio.Reader) int64 {
+	t.Helper()
+	u, err := binary.ReadUvarint(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+// readBytes reads a length-prefixed byte string
+func readBytes(t *testing.T, r *bufio.Reader) []byte {
+	t.Helper()
+	b := make([]byte, readLong(t, r))
+	if _, err := io.ReadFull(r, b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestWriter(t *testing.T) {
+	type row struct {
+		ID string `json:"id"`
+	}
+	codec, err := NewCodec(row{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, codec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < blockSize+1; i++ {
+		if err := w.Write(row{ID: "a"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := bufio.NewReader(&buf)
+	head := make([]byte, 4)
+	io.ReadFull(r, head)
+	if !bytes.Equal(head, magic) {
+		t.Fatalf("Expected the container magic, got %v", head)
+	}
+	meta := make(map[string]string)
+	for n := readLong(t, r); n > 0; n-- {
+		key := readBytes(t, r)
+		meta[string(key)] = string(readBytes(t, r))
+	}
+	if readLong(t, r) != 0 {
+		t.Fatal("Expected the metadata map to end")
+	}
+	if meta["avro.schema"] != codec.Schema() || meta["avro.codec"] != "null" {
+		t.Errorf("Unexpected metadata %v", meta)
+	}
+	sync := make([]byte, 16)
+	io.ReadFull(r, sync)
+
+	// A full block, then the remainder flushed by Flush
+	for _, want := range []int64{blockSize, 1} {
+		if count := readLong(t, r); count != want {
+			t.Fatalf("Block has %d records, want %d", count, want)
+		}
+		data := readBytes(t, r)
+		if int64(len(data)) != want*2 {
+			t.Errorf("Block holds %d bytes, want %d", len(data), want*2)
+		}
+		marker := make([]byte, 16)
+		io.ReadFull(r, marker)
+		if !bytes.Equal(marker, sync) {
+			t.Error("Expected every block to end with the sync marker")
+		}
+	}
+	if _, err := r.ReadByte(); err != io.EOF {
+		t.Error("Expected nothing after the last block")
+	}
+}
@@ -0,0 +1,59 @@
+package avro
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegistry_Register(t *testing.T) {
+	var gotPath, gotSchema, gotUser string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotUser, _, _ = r.BasicAuth()
+		var body struct {
+			Schema string `json:"schema"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		gotSchema = body.Schema
+		if body.Schema == `"bad"` {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"error_code":42201,"message":"Invalid schema"}`))
+			return
+		}
+		w.Write([]byte(`{"id":7}`))
+	}))
+	defer srv.Close()
+
+	u := "http://svc:secret@" + srv.Listener.Addr().String() + "/"
+	reg := NewRegistry(u)
+	id, err := reg.Register(context.Background(), "asana.users-value", `"string"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 7 || gotPath != "/subjects/asana.users-value/versions" || gotSchema != `"string"` || gotUser != "svc" {
+		t.Errorf("Register() = %d via %s with %q as %q", id, gotPath, gotSchema, gotUser)
+	}
+
+	if _, err := reg.Register(context.Background(), "asana.users-value", `"bad"`); err == nil {
+		t.Error("Expected an error for a rejected schema")
+	}
+}
+
+func TestAppendMessage(t *testing.T) {
+	type row struct {
+		ID string `json:"id"`
+	}
+	codec, _ := NewCodec(row{})
+
+	got, err := AppendMessage(nil, 258, codec, row{ID: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0, 0, 0, 1, 2, 2, 'a'}
+	if string(got) != string(want) {
+		t.Errorf("AppendMessage() = %v, want %v", got, want)
+	}
+}
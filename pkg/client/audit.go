@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// RequestIDHeader carries the correlation ID of every outbound request
+const RequestIDHeader = "X-Request-Id"
+
+// AuditRecord describes one logical API call, covering all its attempts
+type AuditRecord struct {
+	Time      time.Time     `json:"time"`
+	RequestID string        `json:"request_id"`
+	Method    string        `json:"method"`
+	Endpoint  string        `json:"endpoint"`
+	Status    int           `json:"status,omitempty"`
+	Latency   time.Duration `json:"latency_ns"`
+	Retries   int           `json:"retries"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// Auditor receives a record for every request made under a context it is
+// attached to with WithAuditor. Implementations must be safe for concurrent use.
+type Auditor interface {
+	Audit(record AuditRecord)
+}
+
+// AuditLog is an Auditor writing one JSON record per line
+type AuditLog struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewAuditLog creates an audit log writing to w
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{enc: json.NewEncoder(w)}
+}
+
+// Audit writes record. Write errors are dropped so auditing never fails a
+// request; callers needing guarantees should check the underlying writer.
+func (l *AuditLog) Audit(record AuditRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.enc.Encode(record)
+}
+
+// auditorKey is the context key for the run's Auditor
+type auditorKey struct{}
+
+// WithAuditor returns a context whose requests are reported to a
+func WithAuditor(ctx context.Context, a Auditor) context.Context {
+	return context.WithValue(ctx, auditorKey{}, a)
+}
+
+// auditorFrom returns the Auditor attached to ctx, or nil
+func auditorFrom(ctx context.Context) Auditor {
+	a, _ := ctx.Value(auditorKey{}).(Auditor)
+	return a
+}
+
+// newRequestID returns a random correlation ID
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/ratelimit"
+	"github.com/ioanzicu/asana-extractor/pkg/retry"
+)
+
+func TestClient_Audit(t *testing.T) {
+	tests := []struct {
+		name          string
+		statuses      []int
+		expectStatus  int
+		expectRetries int
+		expectErr     bool
+	}{
+		{name: "Success", statuses: []int{http.StatusOK}, expectStatus: http.StatusOK},
+		{name: "Retried", statuses: []int{http.StatusServiceUnavailable, http.StatusOK}, expectStatus: http.StatusOK, expectRetries: 1},
+		{name: "Client Error", statuses: []int{http.StatusNotFound}, expectStatus: http.StatusNotFound, expectErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var ids []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ids = append(ids, r.Header.Get(RequestIDHeader))
+				w.WriteHeader(tc.statuses[len(ids)-1])
+			}))
+			defer server.Close()
+
+			c := New(Config{
+				Token:           "token",
+				RateLimitConfig: ratelimit.Config{RequestsPerMinute: 6000, MaxConcurrentRead: 5, MaxConcurrentWrite: 5},
+				RetryConfig:     retry.Config{MaxRetries: 2},
+			})
+
+			var buf bytes.Buffer
+			ctx := WithAuditor(context.Background(), NewAuditLog(&buf))
+			_, err := c.GetBody(ctx, server.URL+"/users?limit=10")
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("GetBody() error = %v, expectErr %v", err, tc.expectErr)
+			}
+
+			// Every attempt carries the same correlation ID
+			if ids[0] == "" {
+				t.Fatal("Expected a request ID header")
+			}
+			for _, id := range ids {
+				if id != ids[0] {
+					t.Errorf("Expected request ID %q on every attempt, got %q", ids[0], id)
+				}
+			}
+			if err != nil && !strings.Contains(err.Error(), ids[0]) {
+				t.Errorf("Expected error to name request %s, got %v", ids[0], err)
+			}
+
+			var record AuditRecord
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("Failed to decode audit record %q: %v", buf.String(), err)
+			}
+			if record.RequestID != ids[0] || record.Method != http.MethodGet || record.Endpoint != "/users?limit=10" {
+				t.Errorf("Unexpected audit record %+v", record)
+			}
+			if record.Status != tc.expectStatus || record.Retries != tc.expectRetries {
+				t.Errorf("Expected status %d with %d retries, got %+v", tc.expectStatus, tc.expectRetries, record)
+			}
+			if record.Latency <= 0 || record.Time.IsZero() {
+				t.Errorf("Expected timing in audit record, got %+v", record)
+			}
+		})
+	}
+}
+
+func TestClient_AuditTransportError(t *testing.T) {
+	failed := errors.New("connection refused")
+	c := New(Config{
+		RateLimitConfig: ratelimit.Config{RequestsPerMinute: 6000, MaxConcurrentRead: 5, MaxConcurrentWrite: 5},
+		Middleware: []Middleware{func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				return nil, failed
+			}
+		}},
+	})
+
+	var buf bytes.Buffer
+	ctx := WithAuditor(context.Background(), NewAuditLog(&buf))
+	if _, err := c.Get(ctx, "http://example.invalid/tasks"); !errors.Is(err, failed) {
+		t.Fatalf("Expected transport error, got %v", err)
+	}
+
+	var record AuditRecord
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Failed to decode audit record: %v", err)
+	}
+	if record.Status != 0 || !strings.Contains(record.Error, "connection refused") {
+		t.Errorf("Expected failed record, got %+v", record)
+	}
+}
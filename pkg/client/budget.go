@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrCallBudgetExhausted is returned, without sending the request, once the
+// CallBudget attached to the context is spent
+var ErrCallBudgetExhausted = errors.New("API call budget exhausted")
+
+// CallBudget caps the HTTP attempts made under one context, typically a
+// single extraction run, whatever Usage each request is tallied into.
+// Every attempt, retries included, spends the budget; requests already
+// admitted may finish their retries, so the cap can be overshot by a few
+// attempts per concurrent request. It is safe for concurrent use.
+type CallBudget struct {
+	limit int64
+	spent atomic.Int64
+}
+
+// NewCallBudget returns a budget of limit attempts
+func NewCallBudget(limit int64) *CallBudget {
+	return &CallBudget{limit: limit}
+}
+
+// Spent returns the number of attempts made under the budget
+func (b *CallBudget) Spent() int64 {
+	return b.spent.Load()
+}
+
+// Exhausted reports whether new requests are refused
+func (b *CallBudget) Exhausted() bool {
+	return b.spent.Load() >= b.limit
+}
+
+// budgetKey is the context key for the run's CallBudget
+type budgetKey struct{}
+
+// WithCallBudget returns a context whose requests spend b
+func WithCallBudget(ctx context.Context, b *CallBudget) context.Context {
+	return context.WithValue(ctx, budgetKey{}, b)
+}
+
+// budgetFrom returns the CallBudget attached to ctx, or nil
+func budgetFrom(ctx context.Context) *CallBudget {
+	b, _ := ctx.Value(budgetKey{}).(*CallBudget)
+	return b
+}
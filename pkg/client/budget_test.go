@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/ratelimit"
+	"github.com/ioanzicu/asana-extractor/pkg/retry"
+)
+
+func TestCallBudget(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := New(Config{
+		RateLimitConfig: ratelimit.Config{RequestsPerMinute: 600, MaxConcurrentRead: 10, MaxConcurrentWrite: 10},
+		RetryConfig:     retry.Config{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		Timeout:         time.Second,
+	})
+
+	budget := NewCallBudget(3)
+	ctx := WithCallBudget(context.Background(), budget)
+
+	// The first request retries once, spending two attempts
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetBody(ctx, server.URL); err != nil {
+			t.Fatalf("GetBody() error = %v", err)
+		}
+	}
+	if !budget.Exhausted() || budget.Spent() != 3 {
+		t.Fatalf("expected the budget spent after 3 attempts, got %d", budget.Spent())
+	}
+
+	if _, err := c.GetBody(ctx, server.URL); !errors.Is(err, ErrCallBudgetExhausted) {
+		t.Errorf("expected ErrCallBudgetExhausted, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected the refused request not to be sent, got %d attempts", attempts)
+	}
+
+	// Requests without a budget on the context are never refused
+	if _, err := c.GetBody(context.Background(), server.URL); err != nil {
+		t.Errorf("GetBody() error = %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CacheEntry is a cached response body with the validators needed to
+// revalidate it
+type CacheEntry struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	Body         []byte `json:"body"`
+}
+
+// Cache stores GET responses by URL for conditional requests.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(url string) (CacheEntry, bool)
+	Set(url string, entry CacheEntry)
+}
+
+// MemoryCache is a Cache held in process memory. It only helps within one
+// process, e.g. across the scheduled runs of serve.
+type MemoryCache struct {
+	mu         sync.RWMutex
+	entries    map[string]CacheEntry
+	maxEntries int
+}
+
+// NewMemoryCache creates an in-memory cache holding at most maxEntries
+// responses; zero means unbounded
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		entries:    make(map[string]CacheEntry),
+		maxEntries: maxEntries,
+	}
+}
+
+// Get returns the entry cached for url
+func (m *MemoryCache) Get(url string) (CacheEntry, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entry, ok := m.entries[url]
+	return entry, ok
+}
+
+// Set caches entry for url, evicting an arbitrary entry when full
+func (m *MemoryCache) Set(url string, entry CacheEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.entries[url]; !exists && m.maxEntries > 0 && len(m.entries) >= m.maxEntries {
+		for k := range m.entries {
+			delete(m.entries, k)
+			break
+		}
+	}
+	m.entries[url] = entry
+}
+
+// DiskCache is a Cache persisted as one JSON file per URL under a
+// directory, so it survives restarts
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache creates a cache stored in dir, creating it if needed
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+// path returns the file holding the entry for url
+func (d *DiskCache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get returns the entry cached for url. Unreadable entries are misses.
+func (d *DiskCache) Get(url string) (CacheEntry, bool) {
+	data, err := os.ReadFile(d.path(url))
+	if err != nil {
+		return CacheEntry{}, false
+	}
+
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return CacheEntry{}, false
+	}
+	return entry, true
+}
+
+// Set caches entry for url. The cache is best effort, so write failures
+// are ignored; the rename keeps concurrent readers from seeing partial files.
+func (d *DiskCache) Set(url string, entry CacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(d.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), d.path(url))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/ratelimit"
+	"github.com/ioanzicu/asana-extractor/pkg/retry"
+)
+
+func TestCaches(t *testing.T) {
+	disk, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskCache() failed: %v", err)
+	}
+
+	caches := map[string]Cache{
+		"Memory": NewMemoryCache(0),
+		"Disk":   disk,
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			if _, ok := cache.Get("https://example.com/a"); ok {
+				t.Fatal("Expected miss on empty cache")
+			}
+
+			cache.Set("https://example.com/a", CacheEntry{ETag: `"v1"`, Body: []byte("body")})
+
+			entry, ok := cache.Get("https://example.com/a")
+			if !ok || entry.ETag != `"v1"` || string(entry.Body) != "body" {
+				t.Errorf("Unexpected entry %+v (ok=%t)", entry, ok)
+			}
+		})
+	}
+}
+
+func TestMemoryCache_Evicts(t *testing.T) {
+	cache := NewMemoryCache(2)
+	cache.Set("a", CacheEntry{})
+	cache.Set("b", CacheEntry{})
+	cache.Set("c", CacheEntry{})
+
+	if len(cache.entries) != 2 {
+		t.Errorf("Expected cache bounded to 2 entries, got %d", len(cache.entries))
+	}
+	if _, ok := cache.Get("c"); !ok {
+		t.Error("Expected newest entry to be kept")
+	}
+}
+
+func TestClient_ConditionalGet(t *testing.T) {
+	tests := []struct {
+		name          string
+		etag          string
+		lastModified  string
+		wantCacheHits int64
+	}{
+		{name: "ETag revalidation", etag: `"v1"`, wantCacheHits: 1},
+		{name: "Last-Modified revalidation", lastModified: "Wed, 14 Oct 2026 10:00:00 GMT", wantCacheHits: 1},
+		{name: "No validators are not cached", wantCacheHits: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.etag != "" && r.Header.Get("If-None-Match") == tt.etag ||
+					tt.lastModified != "" && r.Header.Get("If-Modified-Since") == tt.lastModified {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				if tt.etag != "" {
+					w.Header().Set("ETag", tt.etag)
+				}
+				if tt.lastModified != "" {
+					w.Header().Set("Last-Modified", tt.lastModified)
+				}
+				bodies++
+				w.Write([]byte(`{"data":[]}`))
+			}))
+			defer server.Close()
+
+			c := New(Config{
+				Token:           "token",
+				RateLimitConfig: ratelimit.Config{RequestsPerMinute: 6000, MaxConcurrentRead: 5, MaxConcurrentWrite: 5},
+				RetryConfig:     retry.Config{MaxRetries: 0},
+				Cache:           NewMemoryCache(0),
+			})
+
+			usage := &Usage{}
+			ctx := WithUsage(context.Background(), usage)
+
+			for i := 0; i < 2; i++ {
+				body, err := c.GetBody(ctx, server.URL)
+				if err != nil {
+					t.Fatalf("GetBody() failed: %v", err)
+				}
+				if string(body) != `{"data":[]}` {
+					t.Errorf("Unexpected body %q", body)
+				}
+			}
+
+			if usage.CacheHits() != tt.wantCacheHits {
+				t.Errorf("Expected %d cache hits, got %d", tt.wantCacheHits, usage.CacheHits())
+			}
+			if want := 2 - int(tt.wantCacheHits); bodies != want {
+				t.Errorf("Expected %d full responses, got %d", want, bodies)
+			}
+		})
+	}
+}
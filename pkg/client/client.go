@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,65 +10,315 @@ import (
 
 	"github.com/ioanzicu/asana-extractor/pkg/ratelimit"
 	"github.com/ioanzicu/asana-extractor/pkg/retry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracer emits one client span per logical request, covering rate limiting
+// and every retry attempt
+var tracer = otel.Tracer("github.com/ioanzicu/asana-extractor/pkg/client")
+
 // Client wraps http.Client with rate limiting and retry logic
 type Client struct {
 	httpClient  *http.Client
 	rateLimiter *ratelimit.Limiter
 	retryConfig retry.Config
-	token       string
+	tokens      TokenProvider
+	cache       Cache
+	compression bool
+	// attemptTimeout bounds the wait for each attempt's response headers
+	attemptTimeout time.Duration
+	// hedge enables request hedging of GETs, timed by latencies
+	hedge     bool
+	latencies latencies
+	// send performs one attempt through the middleware chain
+	send RoundTripFunc
 }
 
 // Config holds client configuration
 type Config struct {
-	Token           string
+	Token string
+	// TokenProvider, when set, supplies the token instead of Token and is
+	// refreshed once when a request is rejected with 401 Unauthorized
+	TokenProvider   TokenProvider
 	RateLimitConfig ratelimit.Config
-	RetryConfig     retry.Config
-	Timeout         time.Duration
-	BaseURL         string
+	// RateLimiter, when set, is used instead of a limiter built from
+	// RateLimitConfig, letting clients share a quota; see ratelimit.Registry
+	RateLimiter *ratelimit.Limiter
+	RetryConfig retry.Config
+	Timeout     time.Duration
+	// AttemptTimeout, when set, fails an attempt whose response headers
+	// have not arrived within it, so a stuck attempt is retried rather than
+	// using up Timeout. Reading the body is bounded by Timeout alone.
+	AttemptTimeout time.Duration
+	BaseURL        string
+	// Cache, when set, stores GET responses carrying an ETag or
+	// Last-Modified header and revalidates them with conditional requests
+	Cache Cache
+	// Middleware wraps every request attempt; the first entry is outermost
+	Middleware []Middleware
+	// Latencies, when set, records the latency and status of every attempt
+	// per endpoint, outside Middleware; clients can share it
+	Latencies *LatencyHistograms
+	// Compression requests gzip-encoded responses and decodes them
+	// transparently, counting the bytes saved into Usage
+	Compression bool
+	// Hedge sends a duplicate of a GET that has not answered within the
+	// P99 latency of recent GETs, and takes whichever answers first. The
+	// duplicate counts as an attempt and is only sent when the rate limiter
+	// has a slot to spare at once.
+	Hedge bool
+	// Transport, when set, is used instead of Go's default transport;
+	// see NewTransport. It is cloned, not modified.
+	Transport *http.Transport
 }
 
 // New creates a new HTTP client with rate limiting and retry logic
 func New(cfg Config) *Client {
-	return &Client{
+	// Compression is negotiated here rather than by the transport so the
+	// saving can be measured
+	base := cfg.Transport
+	if base == nil {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	transport := base.Clone()
+	transport.DisableCompression = true
+
+	limiter := cfg.RateLimiter
+	if limiter == nil {
+		limiter = ratelimit.NewLimiter(cfg.RateLimitConfig)
+	}
+
+	tokens := cfg.TokenProvider
+	if tokens == nil {
+		tokens = StaticToken(cfg.Token)
+	}
+
+	c := &Client{
 		httpClient: &http.Client{
-			Timeout: cfg.Timeout,
+			Timeout:   cfg.Timeout,
+			Transport: transport,
 		},
-		rateLimiter: ratelimit.NewLimiter(cfg.RateLimitConfig),
-		retryConfig: cfg.RetryConfig,
-		token:       cfg.Token,
+		rateLimiter:    limiter,
+		retryConfig:    cfg.RetryConfig,
+		tokens:         tokens,
+		cache:          cfg.Cache,
+		compression:    cfg.Compression,
+		attemptTimeout: cfg.AttemptTimeout,
+		hedge:          cfg.Hedge,
+	}
+	middleware := cfg.Middleware
+	if cfg.Latencies != nil {
+		middleware = append([]Middleware{cfg.Latencies.Middleware()}, middleware...)
 	}
+	c.send = chain(c.httpClient.Do, middleware)
+	return c
 }
 
-// Do executes an HTTP request with rate limiting and retry logic
-func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+// Do executes an HTTP request with rate limiting and retry logic. Every
+// call gets a correlation ID, sent as RequestIDHeader on each attempt and
+// included in returned errors and audit records.
+func (c *Client) Do(ctx context.Context, req *http.Request) (resp *http.Response, err error) {
+	requestID := newRequestID()
+	req.Header.Set(RequestIDHeader, requestID)
+
+	ctx, span := tracer.Start(ctx, "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.path", req.URL.Path),
+			attribute.String("http.request.id", requestID),
+		),
+	)
+	defer span.End()
+
+	attempts := 0
+	if auditor := auditorFrom(ctx); auditor != nil {
+		start := time.Now()
+		defer func() {
+			record := AuditRecord{
+				Time:      start.UTC(),
+				RequestID: requestID,
+				Method:    req.Method,
+				Endpoint:  req.URL.RequestURI(),
+				Latency:   time.Since(start),
+				Retries:   max(attempts-1, 0),
+			}
+			if resp != nil {
+				record.Status = resp.StatusCode
+			}
+			if err != nil {
+				record.Error = err.Error()
+			}
+			auditor.Audit(record)
+		}()
+	}
+
 	// Determine request type for rate limiting
 	reqType := ratelimit.RequestTypeRead
 	if req.Method != http.MethodGet && req.Method != http.MethodHead {
 		reqType = ratelimit.RequestTypeWrite
 	}
 
+	usage := usageFrom(ctx)
+
+	// A spent budget refuses the request before it waits for a slot
+	budget := budgetFrom(ctx)
+	if budget != nil && budget.Exhausted() {
+		span.SetStatus(codes.Error, "call budget exhausted")
+		return nil, ErrCallBudgetExhausted
+	}
+
 	// Acquire rate limit slot
-	if err := c.rateLimiter.Acquire(ctx, reqType); err != nil {
+	waitStart := time.Now()
+	err = c.rateLimiter.Acquire(ctx, reqType)
+	if usage != nil {
+		usage.rateLimitWait.Add(int64(time.Since(waitStart)))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "rate limiter error")
 		return nil, fmt.Errorf("rate limiter error: %w", err)
 	}
 	defer c.rateLimiter.Release(reqType)
+	span.AddEvent("rate limit slot acquired")
 
 	// Add authentication header
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "token error")
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Accept", "application/json")
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	// Execute with retry logic. A body without GetBody is consumed by the
+	// first attempt and cannot be resent, so such requests are tried once.
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	retryConfig := c.retryConfig
+	if !replayable {
+		retryConfig.MaxRetries = 0
+	}
+	send := func() (*http.Response, error) {
+		if usage != nil {
+			usage.recordAttempt(req)
+			if attempts > 0 {
+				usage.retries.Add(1)
+			}
+		}
+		if budget != nil {
+			budget.spent.Add(1)
+		}
+		attempts++
+
+		// Clone the request for retry attempts, rewinding any body
+		attemptCtx, answered, release := c.attemptContext(ctx)
+		reqClone := req.Clone(attemptCtx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				release()
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			reqClone.Body = body
+		}
+		if c.compression {
+			reqClone.Header.Set("Accept-Encoding", "gzip")
+		}
 
-	// Execute with retry logic
-	resp, err := retry.Do(ctx, c.retryConfig, func() (*http.Response, error) {
-		// Clone the request for retry attempts
-		reqClone := req.Clone(ctx)
-		return c.httpClient.Do(reqClone)
-	})
+		var resp *http.Response
+		var err error
+		if c.hedge && req.Method == http.MethodGet {
+			resp, err = c.sendHedged(reqClone, usage, budget)
+		} else {
+			resp, err = c.send(reqClone)
+		}
+		if err != nil {
+			release()
+			if cause := context.Cause(attemptCtx); errors.Is(cause, ErrAttemptTimeout) && ctx.Err() == nil {
+				err = fmt.Errorf("%w after %v: %w", cause, c.attemptTimeout, err)
+			}
+			return resp, err
+		}
+		// The body is read after the attempt, under its context
+		answered()
+		if c.attemptTimeout > 0 {
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: release}
+		}
+		// Hold back every request, not just this one, for the penalty
+		if resp.StatusCode == http.StatusTooManyRequests {
+			if usage != nil {
+				usage.rateLimited.Add(1)
+			}
+			if retryAfter := retry.GetRetryAfter(resp); retryAfter > 0 {
+				c.rateLimiter.Pause(retryAfter)
+				span.AddEvent("rate limiter paused")
+			}
+		}
+		if err := decompress(resp, usage); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+	resp, err = retry.Do(ctx, retryConfig, send)
+
+	// A rotated token fails every request until it is reloaded: refresh it
+	// once and retry. If the refresh fails, the 401 is returned with its error.
+	if err == nil && resp.StatusCode == http.StatusUnauthorized && replayable {
+		fresh, refreshErr := c.tokens.Refresh(ctx)
+		switch {
+		case refreshErr != nil:
+			err = fmt.Errorf("failed to refresh token: %w", refreshErr)
+		case fresh != token:
+			span.AddEvent("token refreshed")
+			retry.DrainBody(resp.Body)
+			req.Header.Set("Authorization", "Bearer "+fresh)
+			resp, err = retry.Do(ctx, retryConfig, send)
+		}
+	}
+
+	if resp != nil {
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	}
+	if err != nil {
+		err = fmt.Errorf("request %s: %w", requestID, err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
 
 	return resp, err
 }
 
+// ErrAttemptTimeout is the cause of an attempt cancelled by
+// Config.AttemptTimeout
+var ErrAttemptTimeout = errors.New("attempt timed out waiting for a response")
+
+// attemptContext returns the context of one attempt, cancelled with
+// ErrAttemptTimeout unless its response headers arrive within
+// c.attemptTimeout. answered stops the timer once they do; release
+// cancels the context once the response is done with.
+func (c *Client) attemptContext(ctx context.Context) (attemptCtx context.Context, answered, release func()) {
+	if c.attemptTimeout <= 0 {
+		return ctx, func() {}, func() {}
+	}
+	attemptCtx, cancel := context.WithCancelCause(ctx)
+	timer := time.AfterFunc(c.attemptTimeout, func() { cancel(ErrAttemptTimeout) })
+	return attemptCtx, func() { timer.Stop() }, func() {
+		timer.Stop()
+		cancel(nil)
+	}
+}
+
+// RateLimiterStats returns a snapshot of the client's rate limiter
+func (c *Client) RateLimiterStats() ratelimit.Stats {
+	return c.rateLimiter.Stats()
+}
+
 // Get performs a GET request
 func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -78,18 +329,93 @@ func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
 	return c.Do(ctx, req)
 }
 
-// GetBody performs a GET request and returns the response body as bytes
+// GetBody performs a GET request and returns the response body as bytes.
+// With a Cache configured, a cached response is revalidated and a 304 Not
+// Modified answer returns the cached body.
 func (c *Client) GetBody(ctx context.Context, url string) ([]byte, error) {
-	resp, err := c.Get(ctx, url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	var entry CacheEntry
+	var cached bool
+	if c.cache != nil {
+		if entry, cached = c.cache.Get(url); cached {
+			if entry.ETag != "" {
+				req.Header.Set("If-None-Match", entry.ETag)
+			}
+			if entry.LastModified != "" {
+				req.Header.Set("If-Modified-Since", entry.LastModified)
+			}
+		}
+	}
+
+	resp, err := c.Do(ctx, req)
+	if err != nil {
+		return nil, statusError(req, resp, err)
+	}
+	defer retry.DrainBody(resp.Body)
+
+	if cached && resp.StatusCode == http.StatusNotModified {
+		if usage := usageFrom(ctx); usage != nil {
+			usage.cacheHits.Add(1)
+		}
+		return entry.Body, nil
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+		return nil, statusError(req, resp, nil)
 	}
 
-	return io.ReadAll(resp.Body)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if etag != "" || lastModified != "" {
+			c.cache.Set(url, CacheEntry{ETag: etag, LastModified: lastModified, Body: body})
+		}
+	}
+
+	return body, nil
+}
+
+// statusError converts a failed or unexpected response into a *StatusError,
+// consuming and closing its body. Retries exhausted on a retryable status
+// still carry the response; without one, err is returned as is.
+func statusError(req *http.Request, resp *http.Response, err error) error {
+	if resp == nil {
+		return err
+	}
+	defer retry.DrainBody(resp.Body)
+	body, _ := io.ReadAll(resp.Body)
+	return &StatusError{StatusCode: resp.StatusCode, Body: body, RequestID: req.Header.Get(RequestIDHeader), Err: err}
+}
+
+// StatusError reports a response whose status was not the one expected.
+// Body holds the raw response so API layers can decode their error format.
+// Err is set when the status persisted through every retry.
+type StatusError struct {
+	StatusCode int
+	Body       []byte
+	// RequestID is the correlation ID sent with the request
+	RequestID string
+	Err       error
+}
+
+// Error implements the error interface
+func (e *StatusError) Error() string {
+	if e.Err != nil {
+		// Err already names the request
+		return fmt.Sprintf("%v: %s", e.Err, string(e.Body))
+	}
+	return fmt.Sprintf("request %s: unexpected status code %d: %s", e.RequestID, e.StatusCode, string(e.Body))
+}
+
+// Unwrap returns the retry error, if any
+func (e *StatusError) Unwrap() error {
+	return e.Err
 }
@@ -2,9 +2,15 @@ package client
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -110,6 +116,40 @@ func TestClient_Table(t *testing.T) {
 			expectedCalls: 3,
 			expectError:   false,
 		},
+		{
+			name:  "POST body is replayed on retry",
+			token: "test",
+			serverHandler: func(attempts *int) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					*attempts++
+					body, _ := io.ReadAll(r.Body)
+					if string(body) != "payload" {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					if *attempts < 2 {
+						w.WriteHeader(http.StatusServiceUnavailable)
+						return
+					}
+					w.WriteHeader(http.StatusCreated)
+				}
+			},
+			retryCfg: retry.Config{
+				MaxRetries:     2,
+				InitialBackoff: 1 * time.Millisecond,
+				MaxBackoff:     2 * time.Millisecond,
+			},
+			call: func(ctx context.Context, c *Client, url string) (interface{}, error) {
+				req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader("payload"))
+				resp, err := c.Do(ctx, req)
+				if err == nil && resp.StatusCode != http.StatusCreated {
+					return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+				}
+				return resp, err
+			},
+			expectedCalls: 2,
+			expectError:   false,
+		},
 		{
 			name:  "Invalid URL error in Get",
 			token: "test",
@@ -180,3 +220,143 @@ func TestClient_Table(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_ReusesConnections(t *testing.T) {
+	var mu sync.Mutex
+	connections, calls := 0, 0
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body := strings.Repeat("x", 32<<10)
+		switch {
+		case r.URL.Path == "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case calls%3 != 0:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		w.Write([]byte(body))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			connections++
+			mu.Unlock()
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	c := New(Config{
+		RateLimitConfig: ratelimit.Config{RequestsPerMinute: 6000, MaxConcurrentRead: 5, MaxConcurrentWrite: 5},
+		RetryConfig:     retry.Config{MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+	})
+
+	// Retried, successful and failed responses all release their connection
+	for i := 0; i < 3; i++ {
+		if _, err := c.GetBody(context.Background(), server.URL+"/ok"); err != nil {
+			t.Fatalf("GetBody() failed: %v", err)
+		}
+		if _, err := c.GetBody(context.Background(), server.URL+"/missing"); err == nil {
+			t.Fatal("Expected error for 404")
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if connections != 1 {
+		t.Errorf("Expected a single reused connection, got %d", connections)
+	}
+}
+
+func TestClient_PausesLimiterOn429(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	c := New(Config{
+		RateLimitConfig: ratelimit.Config{RequestsPerMinute: 6000, MaxConcurrentRead: 5, MaxConcurrentWrite: 5},
+		RetryConfig:     retry.Config{MaxRetries: 0},
+	})
+
+	if _, err := c.GetBody(context.Background(), server.URL); err == nil {
+		t.Fatal("Expected error for 429")
+	}
+
+	// Every other request now waits out the penalty
+	if paused := c.RateLimiterStats().PausedFor; paused < 25*time.Second {
+		t.Errorf("Expected limiter paused for the Retry-After window, got %v", paused)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.GetBody(ctx, server.URL); err == nil {
+		t.Error("Expected the paused limiter to hold back the next request")
+	}
+}
+
+func TestClient_AttemptTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(attempt int32, w http.ResponseWriter, r *http.Request)
+		wantErr bool
+	}{
+		{
+			name: "Stuck attempt retried",
+			handler: func(attempt int32, w http.ResponseWriter, r *http.Request) {
+				if attempt == 1 {
+					<-r.Context().Done()
+					return
+				}
+				w.Write([]byte("ok"))
+			},
+		},
+		{
+			name: "Every attempt stuck",
+			handler: func(attempt int32, w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+			},
+			wantErr: true,
+		},
+		{
+			name: "Slow body after the headers",
+			handler: func(attempt int32, w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.(http.Flusher).Flush()
+				time.Sleep(150 * time.Millisecond)
+				w.Write([]byte("ok"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.handler(attempts.Add(1), w, r)
+			}))
+			defer server.Close()
+
+			c := New(Config{
+				RateLimitConfig: ratelimit.Config{RequestsPerMinute: 6000, MaxConcurrentRead: 5, MaxConcurrentWrite: 5},
+				RetryConfig:     retry.Config{MaxRetries: 1, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+				Timeout:         5 * time.Second,
+				AttemptTimeout:  50 * time.Millisecond,
+			})
+
+			start := time.Now()
+			body, err := c.GetBody(context.Background(), server.URL)
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("Expected stuck attempts to fail fast, took %v", elapsed)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrAttemptTimeout) {
+					t.Errorf("Expected ErrAttemptTimeout, got %v", err)
+				}
+				return
+			}
+			if err != nil || string(body) != "ok" {
+				t.Errorf("GetBody() = %q, %v", body, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ioanzicu/asana-extractor/pkg/retry"
+)
+
+// decompress replaces a gzip-encoded response body with a reader of the
+// decoded content. The bytes saved on the wire are added to usage once the
+// body is closed.
+func decompress(resp *http.Response, usage *Usage) error {
+	if resp.Header.Get("Content-Encoding") != "gzip" ||
+		resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified ||
+		resp.Request != nil && resp.Request.Method == http.MethodHead {
+		return nil
+	}
+
+	wire := &countingReader{r: resp.Body}
+	gz, err := gzip.NewReader(wire)
+	if err != nil {
+		retry.DrainBody(resp.Body)
+		return fmt.Errorf("failed to decompress response: %w", err)
+	}
+
+	resp.Body = &gzipBody{gz: gz, wire: wire, body: resp.Body, usage: usage}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// gzipBody is a decompressing response body
+type gzipBody struct {
+	gz      *gzip.Reader
+	wire    *countingReader
+	body    io.ReadCloser
+	usage   *Usage
+	decoded int64
+}
+
+func (b *gzipBody) Read(p []byte) (int, error) {
+	n, err := b.gz.Read(p)
+	b.decoded += int64(n)
+	return n, err
+}
+
+func (b *gzipBody) Close() error {
+	if b.usage != nil {
+		b.usage.bytesSaved.Add(b.decoded - b.wire.n)
+	}
+	return retry.DrainBody(b.body)
+}
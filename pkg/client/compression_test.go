@@ -0,0 +1,70 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/ratelimit"
+	"github.com/ioanzicu/asana-extractor/pkg/retry"
+)
+
+func TestClient_Compression(t *testing.T) {
+	payload := strings.Repeat(`{"gid":"1","name":"task"},`, 200)
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte(payload))
+	gz.Close()
+
+	tests := []struct {
+		name        string
+		compression bool
+		wantGzip    bool
+	}{
+		{name: "Enabled requests and decodes gzip", compression: true, wantGzip: true},
+		{name: "Disabled sends identity", compression: false, wantGzip: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+					w.Header().Set("Content-Encoding", "gzip")
+					w.Write(compressed.Bytes())
+					return
+				}
+				w.Write([]byte(payload))
+			}))
+			defer server.Close()
+
+			c := New(Config{
+				Token:           "token",
+				RateLimitConfig: ratelimit.Config{RequestsPerMinute: 6000, MaxConcurrentRead: 5, MaxConcurrentWrite: 5},
+				RetryConfig:     retry.Config{MaxRetries: 0},
+				Compression:     tt.compression,
+			})
+
+			usage := &Usage{}
+			body, err := c.GetBody(WithUsage(context.Background(), usage), server.URL)
+			if err != nil {
+				t.Fatalf("GetBody() failed: %v", err)
+			}
+			if string(body) != payload {
+				t.Errorf("Expected decoded payload, got %d bytes", len(body))
+			}
+
+			wantSaved := int64(0)
+			if tt.wantGzip {
+				wantSaved = int64(len(payload) - compressed.Len())
+			}
+			if usage.BytesSaved() != wantSaved {
+				t.Errorf("Expected %d bytes saved, got %d", wantSaved, usage.BytesSaved())
+			}
+		})
+	}
+}
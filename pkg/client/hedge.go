@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/ratelimit"
+	"github.com/ioanzicu/asana-extractor/pkg/retry"
+)
+
+const (
+	// latencySamples is how many recent GET latencies the hedge delay is
+	// computed from
+	latencySamples = 1024
+	// hedgeMinSamples is how many latencies must be known before requests
+	// are hedged; until then the P99 is too noisy to go by
+	hedgeMinSamples = 100
+	// hedgeRefresh is how many new latencies are recorded between
+	// recomputations of the P99
+	hedgeRefresh = 64
+)
+
+// latencies tracks recent GET latencies, up to response headers, and their
+// 99th percentile. It is safe for concurrent use.
+type latencies struct {
+	mu     sync.Mutex
+	recent []time.Duration
+	next   int
+	fresh  int
+	p99    time.Duration
+}
+
+// record adds the latency of a successful attempt
+func (l *latencies) record(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.recent) < latencySamples {
+		l.recent = append(l.recent, d)
+	} else {
+		l.recent[l.next] = d
+		l.next = (l.next + 1) % latencySamples
+	}
+	l.fresh++
+	if len(l.recent) >= hedgeMinSamples && (l.p99 == 0 || l.fresh >= hedgeRefresh) {
+		sorted := slices.Clone(l.recent)
+		slices.Sort(sorted)
+		l.p99 = sorted[(len(sorted)*99+99)/100-1]
+		l.fresh = 0
+	}
+}
+
+// hedgeDelay returns how long to wait for a response before hedging, or
+// zero while too few latencies are known
+func (l *latencies) hedgeDelay() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.recent) < hedgeMinSamples {
+		return 0
+	}
+	return l.p99
+}
+
+// hedgeResult is the outcome of one of the attempts of a hedged send
+type hedgeResult struct {
+	attempt int
+	resp    *http.Response
+	err     error
+}
+
+// sendHedged sends a GET attempt and, if it has not answered within the
+// P99 latency, a duplicate, returning whichever answers first. The
+// duplicate is only sent when the rate limiter has a token and a slot to
+// spare right now, and the budget and usage count it as an attempt. The
+// losing attempt is cancelled.
+func (c *Client) sendHedged(req *http.Request, usage *Usage, budget *CallBudget) (*http.Response, error) {
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelFunc
+	launch := func(release bool) {
+		ctx, cancel := context.WithCancel(req.Context())
+		attempt, clone := len(cancels), req.Clone(ctx)
+		cancels = append(cancels, cancel)
+		go func() {
+			start := time.Now()
+			resp, err := c.send(clone)
+			if err == nil {
+				c.latencies.record(time.Since(start))
+			}
+			if release {
+				c.rateLimiter.Release(ratelimit.RequestTypeRead)
+			}
+			results <- hedgeResult{attempt: attempt, resp: resp, err: err}
+		}()
+	}
+
+	launch(false)
+	inFlight := 1
+
+	var timeout <-chan time.Time
+	if delay := c.latencies.hedgeDelay(); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	for {
+		select {
+		case <-timeout:
+			timeout = nil
+			if budget != nil && budget.Exhausted() || !c.rateLimiter.TryAcquire(ratelimit.RequestTypeRead) {
+				continue
+			}
+			if usage != nil {
+				usage.recordAttempt(req)
+				usage.hedges.Add(1)
+			}
+			if budget != nil {
+				budget.spent.Add(1)
+			}
+			inFlight++
+			launch(true)
+
+		case result := <-results:
+			inFlight--
+			// A failed attempt leaves the other to answer
+			if result.err != nil && inFlight > 0 {
+				continue
+			}
+			for i, cancel := range cancels {
+				if i != result.attempt {
+					cancel()
+				}
+			}
+			if inFlight > 0 {
+				go discardHedge(results)
+			}
+			if result.err != nil {
+				cancels[result.attempt]()
+				return nil, result.err
+			}
+			// The body is read after this returns, under the winner's context
+			result.resp.Body = &cancelOnClose{ReadCloser: result.resp.Body, cancel: cancels[result.attempt]}
+			return result.resp, nil
+		}
+	}
+}
+
+// discardHedge releases the response of the cancelled attempt of a hedged
+// send
+func discardHedge(results <-chan hedgeResult) {
+	if result := <-results; result.resp != nil {
+		retry.DrainBody(result.resp.Body)
+	}
+}
+
+// cancelOnClose cancels the context of a hedged attempt once its response
+// body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/ratelimit"
+)
+
+func TestLatencies_HedgeDelay(t *testing.T) {
+	var l latencies
+	for i := 1; i < hedgeMinSamples; i++ {
+		l.record(time.Duration(i) * time.Millisecond)
+	}
+	if d := l.hedgeDelay(); d != 0 {
+		t.Fatalf("expected no hedging before %d samples, got %v", hedgeMinSamples, d)
+	}
+
+	l.record(100 * time.Millisecond)
+	if d := l.hedgeDelay(); d != 99*time.Millisecond {
+		t.Errorf("expected the P99 of 1-100ms, got %v", d)
+	}
+}
+
+func TestClient_Hedge(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxReads   int
+		wantHedges int64
+		maxElapsed time.Duration
+	}{
+		{name: "Slow request hedged", maxReads: 2, wantHedges: 1, maxElapsed: 400 * time.Millisecond},
+		{name: "No slot to spare", maxReads: 1, wantHedges: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The first request stalls; any later one answers at once
+			var requests atomic.Int32
+			cancelled := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if requests.Add(1) == 1 {
+					select {
+					case <-r.Context().Done():
+						close(cancelled)
+						return
+					case <-time.After(time.Second):
+					}
+				}
+				w.Write([]byte("ok"))
+			}))
+			defer server.Close()
+
+			c := New(Config{
+				RateLimitConfig: ratelimit.Config{RequestsPerMinute: 6000, MaxConcurrentRead: tt.maxReads, MaxConcurrentWrite: 1},
+				Hedge:           true,
+				Timeout:         5 * time.Second,
+			})
+			for range hedgeMinSamples {
+				c.latencies.record(20 * time.Millisecond)
+			}
+
+			usage := &Usage{}
+			start := time.Now()
+			body, err := c.GetBody(WithUsage(context.Background(), usage), server.URL)
+			elapsed := time.Since(start)
+			if err != nil || string(body) != "ok" {
+				t.Fatalf("GetBody() = %q, %v", body, err)
+			}
+
+			if got := usage.Hedges(); got != tt.wantHedges {
+				t.Errorf("expected %d hedges, got %d", tt.wantHedges, got)
+			}
+			if got := usage.Requests(); got != 1+tt.wantHedges {
+				t.Errorf("expected hedges to count as attempts, got %d", got)
+			}
+			if tt.wantHedges > 0 {
+				if elapsed > tt.maxElapsed {
+					t.Errorf("expected the hedge to answer first, took %v", elapsed)
+				}
+				select {
+				case <-cancelled:
+				case <-time.After(time.Second):
+					t.Error("expected the stalled request to be cancelled")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ioanzicu/asana-extractor/pkg/retry"
+)
+
+// GetJSON performs a GET request and decodes the JSON response into v
+// straight from the body, without buffering it. With a Cache configured the
+// body is buffered for the cache and decoded from memory instead. A body
+// that cannot be decoded is reported as a *DecodeError.
+func (c *Client) GetJSON(ctx context.Context, url string, v any) error {
+	if c.cache != nil {
+		body, err := c.GetBody(ctx, url)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(body, v); err != nil {
+			return &DecodeError{Err: err}
+		}
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.Do(ctx, req)
+	if err != nil {
+		return statusError(req, resp, err)
+	}
+	defer retry.DrainBody(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(req, resp, nil)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return &DecodeError{Err: err}
+	}
+	return nil
+}
+
+// DecodeError reports a response body that is not the expected JSON
+type DecodeError struct {
+	Err error
+}
+
+// Error implements the error interface
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("failed to decode response: %v", e.Err)
+}
+
+// Unwrap returns the decoding error
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// PostJSON sends payload as a JSON POST request and returns the response body
+func (c *Client) PostJSON(ctx context.Context, url string, payload any) ([]byte, error) {
+	return c.sendJSON(ctx, http.MethodPost, url, payload)
+}
+
+// PutJSON sends payload as a JSON PUT request and returns the response body
+func (c *Client) PutJSON(ctx context.Context, url string, payload any) ([]byte, error) {
+	return c.sendJSON(ctx, http.MethodPut, url, payload)
+}
+
+// DeleteJSON sends a DELETE request, with payload as its JSON body unless
+// nil, and returns the response body
+func (c *Client) DeleteJSON(ctx context.Context, url string, payload any) ([]byte, error) {
+	return c.sendJSON(ctx, http.MethodDelete, url, payload)
+}
+
+// sendJSON performs a write request. The payload is marshalled once and
+// replayed from memory on every retry attempt. Any 2xx status is a success.
+func (c *Client) sendJSON(ctx context.Context, method, url string, payload any) ([]byte, error) {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		// bytes.Reader bodies get a GetBody func, letting Do rewind them
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.Do(ctx, req)
+	if err != nil {
+		return nil, statusError(req, resp, err)
+	}
+	defer retry.DrainBody(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, statusError(req, resp, nil)
+	}
+
+	return io.ReadAll(resp.Body)
+}
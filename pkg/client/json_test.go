@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/ratelimit"
+	"github.com/ioanzicu/asana-extractor/pkg/retry"
+)
+
+func TestClient_SendJSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		call         func(ctx context.Context, c *Client, url string) ([]byte, error)
+		payload      bool
+		expectMethod string
+		statuses     []int
+		expectBody   string
+		expectCalls  int
+		expectStatus int
+		expectErr    bool
+	}{
+		{
+			name: "POST Created",
+			call: func(ctx context.Context, c *Client, url string) ([]byte, error) {
+				return c.PostJSON(ctx, url, map[string]string{"name": "hook"})
+			},
+			payload:      true,
+			expectMethod: http.MethodPost,
+			statuses:     []int{http.StatusCreated},
+			expectBody:   "ok",
+			expectCalls:  1,
+		},
+		{
+			name: "PUT Retried With Same Body",
+			call: func(ctx context.Context, c *Client, url string) ([]byte, error) {
+				return c.PutJSON(ctx, url, map[string]string{"name": "hook"})
+			},
+			payload:      true,
+			expectMethod: http.MethodPut,
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			expectBody:   "ok",
+			expectCalls:  3,
+		},
+		{
+			name:         "DELETE Without Body",
+			call:         func(ctx context.Context, c *Client, url string) ([]byte, error) { return c.DeleteJSON(ctx, url, nil) },
+			expectMethod: http.MethodDelete,
+			statuses:     []int{http.StatusNoContent},
+			expectCalls:  1,
+		},
+		{
+			name: "Client Error",
+			call: func(ctx context.Context, c *Client, url string) ([]byte, error) {
+				return c.PostJSON(ctx, url, map[string]string{"name": "hook"})
+			},
+			payload:      true,
+			expectMethod: http.MethodPost,
+			statuses:     []int{http.StatusBadRequest},
+			expectCalls:  1,
+			expectStatus: http.StatusBadRequest,
+			expectErr:    true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				body, _ := io.ReadAll(r.Body)
+				if r.Method != tc.expectMethod {
+					t.Errorf("Expected method %s, got %s", tc.expectMethod, r.Method)
+				}
+				if tc.payload {
+					if string(body) != `{"name":"hook"}` {
+						t.Errorf("Attempt %d sent body %q", calls, body)
+					}
+					if r.Header.Get("Content-Type") != "application/json" {
+						t.Errorf("Expected JSON content type, got %q", r.Header.Get("Content-Type"))
+					}
+				} else if len(body) != 0 || r.Header.Get("Content-Type") != "" {
+					t.Errorf("Expected no body, got %q", body)
+				}
+
+				w.WriteHeader(tc.statuses[calls-1])
+				if tc.statuses[calls-1] != http.StatusNoContent {
+					w.Write([]byte("ok"))
+				}
+			}))
+			defer server.Close()
+
+			c := New(Config{
+				Token:           "token",
+				RateLimitConfig: ratelimit.Config{RequestsPerMinute: 6000, MaxConcurrentRead: 5, MaxConcurrentWrite: 5},
+				RetryConfig:     retry.Config{MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+			})
+
+			body, err := tc.call(context.Background(), c, server.URL)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("Expected error %v, got %v", tc.expectErr, err)
+			}
+			if calls != tc.expectCalls {
+				t.Errorf("Expected %d calls, got %d", tc.expectCalls, calls)
+			}
+			if tc.expectErr {
+				var statusErr *StatusError
+				if !errors.As(err, &statusErr) || statusErr.StatusCode != tc.expectStatus {
+					t.Errorf("Expected status error %d, got %v", tc.expectStatus, err)
+				}
+				return
+			}
+			if string(body) != tc.expectBody {
+				t.Errorf("Expected body %q, got %q", tc.expectBody, body)
+			}
+		})
+	}
+}
+
+func TestClient_DoUnrewindableBody(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c := New(Config{
+		RateLimitConfig: ratelimit.Config{RequestsPerMinute: 6000, MaxConcurrentRead: 5, MaxConcurrentWrite: 5},
+		RetryConfig:     retry.Config{MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+	})
+
+	// io.NopCloser hides the reader type, so no GetBody is derived
+	req, _ := http.NewRequest(http.MethodPost, server.URL, io.NopCloser(strings.NewReader("{}")))
+	resp, err := c.Do(context.Background(), req)
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+	if calls != 1 {
+		t.Errorf("Expected a single attempt, got %d", calls)
+	}
+}
+
+func TestClient_GetJSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		cache        Cache
+		status       int
+		body         string
+		expectName   string
+		expectDecode bool
+		expectStatus int
+	}{
+		{name: "Streamed", status: http.StatusOK, body: `{"name":"alice"}`, expectName: "alice"},
+		{name: "Cached", cache: NewMemoryCache(10), status: http.StatusOK, body: `{"name":"bob"}`, expectName: "bob"},
+		{name: "Invalid JSON", status: http.StatusOK, body: `{"name":`, expectDecode: true},
+		{name: "Invalid Cached JSON", cache: NewMemoryCache(10), status: http.StatusOK, body: `[`, expectDecode: true},
+		{name: "Not Found", status: http.StatusNotFound, body: `{"errors":[]}`, expectStatus: http.StatusNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			c := New(Config{
+				Token:           "token",
+				RateLimitConfig: ratelimit.Config{RequestsPerMinute: 6000, MaxConcurrentRead: 5, MaxConcurrentWrite: 5},
+				Cache:           tc.cache,
+			})
+
+			var v struct {
+				Name string `json:"name"`
+			}
+			err := c.GetJSON(context.Background(), server.URL, &v)
+
+			var decodeErr *DecodeError
+			if errors.As(err, &decodeErr) != tc.expectDecode {
+				t.Errorf("Expected decode error %v, got %v", tc.expectDecode, err)
+			}
+			var statusErr *StatusError
+			if tc.expectStatus != 0 && (!errors.As(err, &statusErr) || statusErr.StatusCode != tc.expectStatus) {
+				t.Errorf("Expected status error %d, got %v", tc.expectStatus, err)
+			}
+			if tc.expectName != "" {
+				if err != nil {
+					t.Fatalf("GetJSON() failed: %v", err)
+				}
+				if v.Name != tc.expectName {
+					t.Errorf("Expected name %q, got %q", tc.expectName, v.Name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds of the latency histograms; slower
+// attempts only count towards the +Inf bucket
+var LatencyBuckets = []time.Duration{
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// EndpointLatency is the histogram of one endpoint's attempts
+type EndpointLatency struct {
+	Count int64         `json:"count"`
+	Sum   time.Duration `json:"sum_ns"`
+	// Buckets counts attempts at or below each bound of LatencyBuckets,
+	// keyed like "250ms", cumulatively as in Prometheus, plus "+Inf"
+	Buckets map[string]int64 `json:"buckets"`
+	// Statuses counts attempts by status code, "error" for those without
+	// a response
+	Statuses map[string]int64 `json:"statuses"`
+}
+
+// LatencyHistograms records the latency and status of every attempt per
+// endpoint, keyed by method and path with GIDs replaced by {gid} so the
+// number of series stays bounded. It is safe for concurrent use and can be
+// shared by several clients.
+type LatencyHistograms struct {
+	mu        sync.Mutex
+	endpoints map[string]*endpointHistogram
+}
+
+// endpointHistogram holds the per-bucket counts of one endpoint, not
+// cumulative; guarded by LatencyHistograms.mu
+type endpointHistogram struct {
+	count    int64
+	sum      time.Duration
+	buckets  []int64
+	statuses map[string]int64
+}
+
+// NewLatencyHistograms returns empty histograms
+func NewLatencyHistograms() *LatencyHistograms {
+	return &LatencyHistograms{endpoints: make(map[string]*endpointHistogram)}
+}
+
+// Middleware returns middleware observing every attempt into h
+func (h *LatencyHistograms) Middleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+			status := "error"
+			if err == nil {
+				status = strconv.Itoa(resp.StatusCode)
+			}
+			h.Observe(endpointKey(req), status, time.Since(start))
+			return resp, err
+		}
+	}
+}
+
+// Observe records one attempt of endpoint answered with status after d
+func (h *LatencyHistograms) Observe(endpoint, status string, d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	e, ok := h.endpoints[endpoint]
+	if !ok {
+		e = &endpointHistogram{buckets: make([]int64, len(LatencyBuckets)), statuses: make(map[string]int64)}
+		h.endpoints[endpoint] = e
+	}
+	e.count++
+	e.sum += d
+	for i, bound := range LatencyBuckets {
+		if d <= bound {
+			e.buckets[i]++
+			break
+		}
+	}
+	e.statuses[status]++
+}
+
+// Snapshot returns the histograms of every endpoint seen so far
+func (h *LatencyHistograms) Snapshot() map[string]EndpointLatency {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	out := make(map[string]EndpointLatency, len(h.endpoints))
+	for endpoint, e := range h.endpoints {
+		l := EndpointLatency{
+			Count:    e.count,
+			Sum:      e.sum,
+			Buckets:  make(map[string]int64, len(LatencyBuckets)+1),
+			Statuses: make(map[string]int64, len(e.statuses)),
+		}
+		var cumulative int64
+		for i, bound := range LatencyBuckets {
+			cumulative += e.buckets[i]
+			l.Buckets[bound.String()] = cumulative
+		}
+		l.Buckets["+Inf"] = e.count
+		for status, n := range e.statuses {
+			l.Statuses[status] = n
+		}
+		out[endpoint] = l
+	}
+	return out
+}
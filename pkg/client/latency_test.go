@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/ratelimit"
+)
+
+func TestLatencyHistograms_Observe(t *testing.T) {
+	h := NewLatencyHistograms()
+	h.Observe("GET /users", "200", 40*time.Millisecond)
+	h.Observe("GET /users", "200", 300*time.Millisecond)
+	h.Observe("GET /users", "503", 20*time.Second)
+
+	got := h.Snapshot()["GET /users"]
+	if got.Count != 3 || got.Sum != 20340*time.Millisecond {
+		t.Errorf("Unexpected count and sum: %d, %v", got.Count, got.Sum)
+	}
+	wantBuckets := map[string]int64{
+		"50ms": 1, "100ms": 1, "250ms": 1, "500ms": 2, "1s": 2, "2.5s": 2, "5s": 2, "10s": 2, "+Inf": 3,
+	}
+	if !reflect.DeepEqual(got.Buckets, wantBuckets) {
+		t.Errorf("Buckets = %v, want %v", got.Buckets, wantBuckets)
+	}
+	if want := map[string]int64{"200": 2, "503": 1}; !reflect.DeepEqual(got.Statuses, want) {
+		t.Errorf("Statuses = %v, want %v", got.Statuses, want)
+	}
+}
+
+func TestClient_Latencies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/tasks/999" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	latencies := NewLatencyHistograms()
+	c := New(Config{
+		RateLimitConfig: ratelimit.Config{RequestsPerMinute: 600, MaxConcurrentRead: 10, MaxConcurrentWrite: 10},
+		Latencies:       latencies,
+	})
+	for _, path := range []string{"/projects/1/tasks", "/projects/2/tasks", "/tasks/999"} {
+		c.GetBody(context.Background(), server.URL+path)
+	}
+
+	got := latencies.Snapshot()
+	if e := got["GET /projects/{gid}/tasks"]; e.Count != 2 || e.Statuses["200"] != 2 {
+		t.Errorf("Expected 2 OK task listings under one endpoint, got %+v", e)
+	}
+	if e := got["GET /tasks/{gid}"]; e.Statuses["404"] != 1 {
+		t.Errorf("Expected the 404 to be recorded, got %+v", e)
+	}
+}
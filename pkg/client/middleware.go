@@ -0,0 +1,30 @@
+package client
+
+import "net/http"
+
+// RoundTripFunc sends a single HTTP attempt
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps a RoundTripFunc to observe or modify requests and
+// responses, e.g. for logging, metrics or header injection. Middleware runs
+// once per attempt, inside rate limiting and retries, after the client has
+// set its own headers.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// chain wraps rt in middleware; the first middleware is the outermost
+func chain(rt RoundTripFunc, middleware []Middleware) RoundTripFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		rt = middleware[i](rt)
+	}
+	return rt
+}
+
+// SetHeader returns middleware setting a header on every request
+func SetHeader(key, value string) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			req.Header.Set(key, value)
+			return next(req)
+		}
+	}
+}
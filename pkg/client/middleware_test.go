@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/ratelimit"
+	"github.com/ioanzicu/asana-extractor/pkg/retry"
+)
+
+func TestClient_Middleware(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.Header.Get("X-Team") != "data" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				order = append(order, name+">")
+				resp, err := next(req)
+				order = append(order, "<"+name)
+				return resp, err
+			}
+		}
+	}
+
+	c := New(Config{
+		Token:           "token",
+		RateLimitConfig: ratelimit.Config{RequestsPerMinute: 6000, MaxConcurrentRead: 5, MaxConcurrentWrite: 5},
+		RetryConfig:     retry.Config{MaxRetries: 1},
+		Middleware:      []Middleware{trace("outer"), SetHeader("X-Team", "data"), trace("inner")},
+	})
+
+	body, err := c.GetBody(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("GetBody() failed: %v", err)
+	}
+	if string(body) != "ok" {
+		t.Errorf("Unexpected body %q", body)
+	}
+
+	// Middleware runs once per attempt, first entry outermost
+	want := []string{"outer>", "inner>", "<inner", "<outer", "outer>", "inner>", "<inner", "<outer"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("Expected order %v, got %v", want, order)
+	}
+}
+
+func TestMiddleware_ShortCircuit(t *testing.T) {
+	blocked := errors.New("blocked")
+	c := New(Config{
+		RateLimitConfig: ratelimit.Config{RequestsPerMinute: 6000, MaxConcurrentRead: 5, MaxConcurrentWrite: 5},
+		Middleware: []Middleware{func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				return nil, blocked
+			}
+		}},
+	})
+
+	if _, err := c.Get(context.Background(), "http://example.invalid"); !errors.Is(err, blocked) {
+		t.Errorf("Expected middleware error, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// TokenProvider supplies the bearer token sent with every request. When a
+// request is rejected with 401 Unauthorized, the client calls Refresh once
+// and retries with the new token, so a token rotated mid-run is picked up
+// without a restart. Implementations must be safe for concurrent use.
+type TokenProvider interface {
+	// Token returns the current token
+	Token(ctx context.Context) (string, error)
+	// Refresh reloads the token after it was rejected and returns it
+	Refresh(ctx context.Context) (string, error)
+}
+
+// StaticToken is a TokenProvider for a fixed token. Refreshing returns the
+// same token, so a 401 is reported as is.
+type StaticToken string
+
+// Token returns the token
+func (t StaticToken) Token(ctx context.Context) (string, error) {
+	return string(t), nil
+}
+
+// Refresh returns the same token
+func (t StaticToken) Refresh(ctx context.Context) (string, error) {
+	return string(t), nil
+}
+
+// FileToken is a TokenProvider reading the token from a file, such as a
+// mounted secret, and re-reading it on Refresh
+type FileToken struct {
+	path string
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewFileToken creates a provider for the token stored at path
+func NewFileToken(path string) *FileToken {
+	return &FileToken{path: path}
+}
+
+// Token returns the token, reading the file on first use
+func (f *FileToken) Token(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.token != "" {
+		return f.token, nil
+	}
+	return f.load()
+}
+
+// Refresh re-reads the file
+func (f *FileToken) Refresh(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.load()
+}
+
+// load reads the token file; f.mu must be held
+func (f *FileToken) load() (string, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", f.path)
+	}
+
+	f.token = token
+	return token, nil
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/ratelimit"
+)
+
+func TestFileToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("old\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := NewFileToken(path)
+	if token, err := tokens.Token(context.Background()); err != nil || token != "old" {
+		t.Fatalf("Token() = %q, %v, want old", token, err)
+	}
+
+	os.WriteFile(path, []byte("new"), 0o600)
+	if token, _ := tokens.Token(context.Background()); token != "old" {
+		t.Errorf("Token() = %q, want the cached token until refreshed", token)
+	}
+	if token, err := tokens.Refresh(context.Background()); err != nil || token != "new" {
+		t.Errorf("Refresh() = %q, %v, want new", token, err)
+	}
+
+	os.WriteFile(path, []byte("  \n"), 0o600)
+	if _, err := tokens.Refresh(context.Background()); err == nil {
+		t.Error("Refresh() expected error for an empty file")
+	}
+	if _, err := NewFileToken(filepath.Join(t.TempDir(), "missing")).Token(context.Background()); err == nil {
+		t.Error("Token() expected error for a missing file")
+	}
+}
+
+// rotatingToken serves old until refreshed, then new
+type rotatingToken struct {
+	refreshed  int
+	refreshErr error
+	rotated    bool
+}
+
+func (r *rotatingToken) Token(ctx context.Context) (string, error) {
+	return "old", nil
+}
+
+func (r *rotatingToken) Refresh(ctx context.Context) (string, error) {
+	r.refreshed++
+	if r.refreshErr != nil {
+		return "", r.refreshErr
+	}
+	if r.rotated {
+		return "new", nil
+	}
+	return "old", nil
+}
+
+func TestClient_RefreshOn401(t *testing.T) {
+	refreshErr := errors.New("secret store unavailable")
+	tests := []struct {
+		name          string
+		tokens        *rotatingToken
+		expectCalls   int
+		expectErr     bool
+		errContains   string
+		expectRefresh int
+	}{
+		{name: "Rotated Token", tokens: &rotatingToken{rotated: true}, expectCalls: 2, expectRefresh: 1},
+		{name: "Unchanged Token", tokens: &rotatingToken{}, expectCalls: 1, expectErr: true, errContains: "401", expectRefresh: 1},
+		{name: "Refresh Fails", tokens: &rotatingToken{refreshErr: refreshErr}, expectCalls: 1, expectErr: true, errContains: "secret store unavailable", expectRefresh: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if r.Header.Get("Authorization") != "Bearer new" {
+					w.WriteHeader(http.StatusUnauthorized)
+					w.Write([]byte("401 not authorized"))
+					return
+				}
+				w.Write([]byte("ok"))
+			}))
+			defer server.Close()
+
+			c := New(Config{
+				TokenProvider:   tc.tokens,
+				RateLimitConfig: ratelimit.Config{RequestsPerMinute: 6000, MaxConcurrentRead: 5, MaxConcurrentWrite: 5},
+			})
+
+			body, err := c.GetBody(context.Background(), server.URL)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("GetBody() error = %v, expectErr %v", err, tc.expectErr)
+			}
+			if calls != tc.expectCalls {
+				t.Errorf("Expected %d calls, got %d", tc.expectCalls, calls)
+			}
+			if tc.tokens.refreshed != tc.expectRefresh {
+				t.Errorf("Expected %d refreshes, got %d", tc.expectRefresh, tc.tokens.refreshed)
+			}
+			if tc.expectErr {
+				var statusErr *StatusError
+				if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+					t.Errorf("Expected 401 status error, got %v", err)
+				}
+				if !strings.Contains(err.Error(), tc.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tc.errContains, err)
+				}
+				return
+			}
+			if string(body) != "ok" {
+				t.Errorf("Expected body ok, got %q", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewTransport_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte("via proxy"))
+	}))
+	defer proxy.Close()
+
+	transport, err := NewTransport(TransportConfig{ProxyURL: proxy.URL, DialTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("NewTransport() failed: %v", err)
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Get("http://asana.example/api/1.0/users")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if string(body) != "via proxy" || proxied != "http://asana.example/api/1.0/users" {
+		t.Errorf("Expected request through proxy, got %q for %q", body, proxied)
+	}
+}
+
+func TestNewTransport_CAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	transport, err := NewTransport(TransportConfig{CAFile: caFile})
+	if err != nil {
+		t.Fatalf("NewTransport() failed: %v", err)
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected custom CA to be trusted, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestNewTransport_Errors(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "bad.pem")
+	os.WriteFile(notPEM, []byte("not a certificate"), 0644)
+
+	tests := []struct {
+		name string
+		cfg  TransportConfig
+	}{
+		{name: "Invalid proxy URL", cfg: TransportConfig{ProxyURL: "://bad"}},
+		{name: "Missing CA file", cfg: TransportConfig{CAFile: filepath.Join(dir, "missing.pem")}},
+		{name: "CA file without certificates", cfg: TransportConfig{CAFile: notPEM}},
+		{name: "Unloadable client certificate", cfg: TransportConfig{ClientCertFile: notPEM, ClientKeyFile: notPEM}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTransport(tt.cfg); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestNewTransport_Pool(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       TransportConfig
+		wantProto int
+	}{
+		{name: "HTTP/2", cfg: TransportConfig{MaxIdleConns: 10, MaxIdleConnsPerHost: 8, MaxConnsPerHost: 16, IdleConnTimeout: time.Minute, ForceHTTP2: true}, wantProto: 2},
+		{name: "HTTP/1.1 only", cfg: TransportConfig{}, wantProto: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			server.EnableHTTP2 = true
+			server.StartTLS()
+			defer server.Close()
+
+			transport, err := NewTransport(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewTransport() failed: %v", err)
+			}
+			if tt.cfg.MaxIdleConns > 0 && (transport.MaxIdleConns != 10 || transport.MaxIdleConnsPerHost != 8 ||
+				transport.MaxConnsPerHost != 16 || transport.IdleConnTimeout != time.Minute) {
+				t.Errorf("Expected the pool settings to be applied, got %+v", transport)
+			}
+
+			transport.TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+			resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.ProtoMajor != tt.wantProto {
+				t.Errorf("Expected HTTP/%d, got %s", tt.wantProto, resp.Proto)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Usage accumulates API usage for every request made under one context,
+// typically a single extraction run. It is safe for concurrent use.
+type Usage struct {
+	requests      atomic.Int64
+	retries       atomic.Int64
+	rateLimitWait atomic.Int64
+	pages         atomic.Int64
+	cacheHits     atomic.Int64
+	bytesSaved    atomic.Int64
+	rateLimited   atomic.Int64
+	hedges        atomic.Int64
+
+	// mu guards endpoints, the attempts per endpoint
+	mu        sync.Mutex
+	endpoints map[string]int64
+}
+
+// Requests returns the number of HTTP attempts sent, retries included
+func (u *Usage) Requests() int64 {
+	return u.requests.Load()
+}
+
+// Retries returns the number of attempts beyond the first for each request
+func (u *Usage) Retries() int64 {
+	return u.retries.Load()
+}
+
+// RateLimitWait returns the total time spent waiting for rate limit slots
+func (u *Usage) RateLimitWait() time.Duration {
+	return time.Duration(u.rateLimitWait.Load())
+}
+
+// Pages returns the number of result pages fetched
+func (u *Usage) Pages() int64 {
+	return u.pages.Load()
+}
+
+// CacheHits returns the number of requests answered 304 Not Modified and
+// served from the response cache
+func (u *Usage) CacheHits() int64 {
+	return u.cacheHits.Load()
+}
+
+// BytesSaved returns how many fewer response bytes were transferred thanks
+// to gzip compression
+func (u *Usage) BytesSaved() int64 {
+	return u.bytesSaved.Load()
+}
+
+// RateLimited returns the number of attempts answered 429 Too Many Requests
+func (u *Usage) RateLimited() int64 {
+	return u.rateLimited.Load()
+}
+
+// Hedges returns the number of duplicate attempts sent by request hedging
+func (u *Usage) Hedges() int64 {
+	return u.hedges.Load()
+}
+
+// Endpoints returns the number of attempts per endpoint, keyed by method
+// and path with GIDs replaced by {gid}, such as "GET /projects/{gid}/tasks"
+func (u *Usage) Endpoints() map[string]int64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return maps.Clone(u.endpoints)
+}
+
+// recordAttempt counts one attempt of req
+func (u *Usage) recordAttempt(req *http.Request) {
+	u.requests.Add(1)
+
+	key := endpointKey(req)
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.endpoints == nil {
+		u.endpoints = make(map[string]int64)
+	}
+	u.endpoints[key]++
+}
+
+// endpointKey identifies the endpoint of req, such as
+// "GET /projects/{gid}/tasks"
+func endpointKey(req *http.Request) string {
+	return req.Method + " " + endpointPath(req.URL.Path)
+}
+
+// endpointPath replaces the GIDs in path, its all-digit segments, by {gid}
+// so the calls for every entity add up under one endpoint
+func endpointPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if segment != "" && strings.Trim(segment, "0123456789") == "" {
+			segments[i] = "{gid}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// RecordPages adds n fetched pages to the Usage attached to ctx, if any.
+// API layers call it since pagination is invisible at the HTTP level.
+func RecordPages(ctx context.Context, n int) {
+	if u := usageFrom(ctx); u != nil {
+		u.pages.Add(int64(n))
+	}
+}
+
+// usageKey is the context key for the run's Usage
+type usageKey struct{}
+
+// WithUsage returns a context whose requests are tallied into u
+func WithUsage(ctx context.Context, u *Usage) context.Context {
+	return context.WithValue(ctx, usageKey{}, u)
+}
+
+// usageFrom returns the Usage attached to ctx, or nil
+func usageFrom(ctx context.Context) *Usage {
+	u, _ := ctx.Value(usageKey{}).(*Usage)
+	return u
+}
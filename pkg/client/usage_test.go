@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/ratelimit"
+	"github.com/ioanzicu/asana-extractor/pkg/retry"
+)
+
+func TestUsage_CountsAttempts(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := New(Config{
+		RateLimitConfig: ratelimit.Config{RequestsPerMinute: 600, MaxConcurrentRead: 10, MaxConcurrentWrite: 10},
+		RetryConfig:     retry.Config{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		Timeout:         time.Second,
+	})
+
+	usage := &Usage{}
+	ctx := WithUsage(context.Background(), usage)
+
+	// Two logical requests, the first needing one retry
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetBody(ctx, server.URL); err != nil {
+			t.Fatalf("GetBody() error = %v", err)
+		}
+	}
+
+	// Requests without a Usage on the context are not counted anywhere
+	if _, err := c.GetBody(context.Background(), server.URL); err != nil {
+		t.Fatalf("GetBody() error = %v", err)
+	}
+
+	if got := usage.Requests(); got != 3 {
+		t.Errorf("expected 3 attempts recorded, got %d", got)
+	}
+	if got := usage.Retries(); got != 1 {
+		t.Errorf("expected 1 retry recorded, got %d", got)
+	}
+
+	RecordPages(ctx, 2)
+	RecordPages(context.Background(), 5)
+	if got := usage.Pages(); got != 2 {
+		t.Errorf("expected 2 pages recorded, got %d", got)
+	}
+}
+
+func TestUsage_Endpoints(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := New(Config{
+		RateLimitConfig: ratelimit.Config{RequestsPerMinute: 600, MaxConcurrentRead: 10, MaxConcurrentWrite: 10},
+		RetryConfig:     retry.Config{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		Timeout:         time.Second,
+	})
+
+	usage := &Usage{}
+	ctx := WithUsage(context.Background(), usage)
+	for _, path := range []string{"/projects/1201/tasks", "/projects/1202/tasks?offset=abc", "/users"} {
+		if _, err := c.GetBody(ctx, server.URL+path); err != nil {
+			t.Fatalf("GetBody() error = %v", err)
+		}
+	}
+
+	want := map[string]int64{"GET /projects/{gid}/tasks": 3, "GET /users": 1}
+	if got := usage.Endpoints(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Endpoints() = %v, want %v", got, want)
+	}
+	if got := usage.RateLimited(); got != 1 {
+		t.Errorf("expected 1 rate-limited attempt, got %d", got)
+	}
+}
+
+func TestEndpointPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/1.0/tasks/12345/subtasks", "/api/1.0/tasks/{gid}/subtasks"},
+		{"/workspaces/1/audit_log_events", "/workspaces/{gid}/audit_log_events"},
+		{"/users/me", "/users/me"},
+		{"/", "/"},
+	}
+	for _, tc := range tests {
+		if got := endpointPath(tc.path); got != tc.want {
+			t.Errorf("endpointPath(%q) = %q, want %q", tc.path, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ioanzicu/asana-extractor/pkg/retry"
+)
+
+// Recording is a response captured by Record, stored as one JSON file per
+// request so fixtures can be read and edited by hand
+type Recording struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+// recordingPath returns the file holding the recording of the request.
+// Requests are keyed by method, path and query, not host, so recordings
+// replay against any base URL.
+func recordingPath(dir string, req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Method + " " + req.URL.RequestURI()))
+	return filepath.Join(dir, hex.EncodeToString(sum[:16])+".json")
+}
+
+// Record returns middleware that saves every response to dir, replacing
+// an earlier recording of the same request. Rate-limited and server error
+// responses are transient and not recorded. Bodies are stored decoded.
+func Record(dir string) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			if err != nil || retry.ShouldRetry(resp, nil) {
+				return resp, err
+			}
+
+			body, err := readDecoded(resp)
+			if err != nil {
+				return nil, fmt.Errorf("failed to record response: %w", err)
+			}
+			rec := Recording{
+				Method: req.Method,
+				URL:    req.URL.RequestURI(),
+				Status: resp.StatusCode,
+				Header: resp.Header,
+				Body:   string(body),
+			}
+			if err := saveRecording(recordingPath(dir, req), rec); err != nil {
+				return nil, fmt.Errorf("failed to record response: %w", err)
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			return resp, nil
+		}
+	}
+}
+
+// Replay returns middleware that answers every request from the recordings
+// in dir without contacting the server. A request that was never recorded
+// gets a 400 response naming it, which is not retried.
+func Replay(dir string) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			rec, err := loadRecording(recordingPath(dir, req))
+			if os.IsNotExist(err) {
+				msg, _ := json.Marshal(map[string]any{"errors": []map[string]string{{
+					"message": "no recording of " + req.Method + " " + req.URL.RequestURI() + " in " + dir,
+				}}})
+				rec = Recording{Status: http.StatusBadRequest, Header: http.Header{"Content-Type": {"application/json"}}, Body: string(msg)}
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to load recording: %w", err)
+			}
+
+			return &http.Response{
+				Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+				StatusCode:    rec.Status,
+				Proto:         "HTTP/1.1",
+				ProtoMajor:    1,
+				ProtoMinor:    1,
+				Header:        rec.Header.Clone(),
+				Body:          io.NopCloser(strings.NewReader(rec.Body)),
+				ContentLength: int64(len(rec.Body)),
+				Request:       req,
+			}, nil
+		}
+	}
+}
+
+// readDecoded reads and closes the response body, decoding gzip so the
+// recording is readable. The encoding headers are removed to match.
+func readDecoded(resp *http.Response) ([]byte, error) {
+	defer retry.DrainBody(resp.Body)
+	var r io.Reader = resp.Body
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		r = gz
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+	}
+	return io.ReadAll(r)
+}
+
+// saveRecording writes rec to path through a temporary file
+func saveRecording(path string, rec Recording) error {
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// loadRecording reads the recording at path
+func loadRecording(path string) (Recording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Recording{}, err
+	}
+	var rec Recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return Recording{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return rec, nil
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/ratelimit"
+	"github.com/ioanzicu/asana-extractor/pkg/retry"
+)
+
+func TestRecordReplay(t *testing.T) {
+	dir := t.TempDir()
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path == "/busy" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[{"message":"Unknown object"}]}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(`{"data":"` + r.URL.Query().Get("q") + `"}`))
+		gz.Close()
+	}))
+
+	newClient := func(mw Middleware) *Client {
+		return New(Config{
+			Token:           "token",
+			RateLimitConfig: ratelimit.Config{RequestsPerMinute: 6000, MaxConcurrentRead: 5, MaxConcurrentWrite: 5},
+			RetryConfig:     retry.Config{MaxRetries: 0},
+			Compression:     true,
+			Middleware:      []Middleware{mw},
+		})
+	}
+	ctx := context.Background()
+
+	recorder := newClient(Record(dir))
+	for _, q := range []string{"a", "b"} {
+		body, err := recorder.GetBody(ctx, server.URL+"/items?q="+q)
+		if err != nil || string(body) != `{"data":"`+q+`"}` {
+			t.Fatalf("Recording GetBody() = %q, %v", body, err)
+		}
+	}
+	recorder.GetBody(ctx, server.URL+"/missing")
+	recorder.GetBody(ctx, server.URL+"/busy")
+	server.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 3 {
+		t.Fatalf("Expected 3 recordings (not the 503), got %d", len(files))
+	}
+	req := httptest.NewRequest(http.MethodGet, "/items?q=a", nil)
+	rec, err := loadRecording(recordingPath(dir, req))
+	if err != nil || rec.Body != `{"data":"a"}` || rec.Header.Get("Content-Encoding") != "" {
+		t.Errorf("Expected a readable, decoded recording, got %+v, %v", rec, err)
+	}
+
+	replayer := newClient(Replay(dir))
+	body, err := replayer.GetBody(ctx, "http://elsewhere.invalid/items?q=b")
+	if err != nil || string(body) != `{"data":"b"}` {
+		t.Errorf("Replay GetBody() = %q, %v", body, err)
+	}
+
+	var statusErr *StatusError
+	_, err = replayer.GetBody(ctx, "http://elsewhere.invalid/missing")
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected the recorded 404, got %v", err)
+	}
+
+	_, err = replayer.GetBody(ctx, "http://elsewhere.invalid/items?q=c")
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadRequest ||
+		!strings.Contains(string(statusErr.Body), "no recording of GET /items?q=c") {
+		t.Errorf("Expected a 400 naming the unrecorded request, got %v", err)
+	}
+	if calls != 4 {
+		t.Errorf("Expected replay not to contact the server, got %d calls", calls)
+	}
+}
@@ -1,10 +1,12 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"log"
-	"os"
+	"io/fs"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -15,68 +17,691 @@ type Config struct {
 	// Asana API configuration
 	AsanaToken     string
 	AsanaWorkspace string
+	// AsanaTokenFile names a file holding the token instead, re-read when
+	// Asana rejects the token so it can be rotated without a restart
+	AsanaTokenFile string
 
 	// Scheduling configuration
+	// RunOnce performs a single extraction and exits instead of scheduling
+	RunOnce bool
+	// DryRun walks the API and counts entities without writing to storage
+	DryRun       bool
 	ScheduleCron string
+	// ResourceSchedules maps a resource to its own cron expression, taken
+	// from SCHEDULE_CRON_<RESOURCE>. Resources not listed follow ScheduleCron.
+	ResourceSchedules map[string]string
+	// ScheduleMode is "cron" (ScheduleCron) or "interval", which runs the
+	// default job ScheduleInterval after the previous run completes
+	ScheduleMode     string
+	ScheduleInterval time.Duration
+	// ScheduleBlackouts lists windows such as "Sun 01:00-03:00 UTC" during
+	// which scheduled runs are skipped, separated by commas
+	ScheduleBlackouts string
+	// ScheduleOverlapPolicy is one of "skip", "queue" or "allow"
+	ScheduleOverlapPolicy string
+	// JobTimeout bounds a single extraction run; zero disables it
+	JobTimeout time.Duration
+	// DrainTimeout is how long shutdown waits for a running extraction to
+	// finish before cancelling it
+	DrainTimeout time.Duration
+	// ScheduleFailureBackoff holds a job's scheduled runs back after it
+	// fails, doubling per consecutive failure up to ScheduleMaxFailureBackoff.
+	// Zero disables it.
+	ScheduleFailureBackoff    time.Duration
+	ScheduleMaxFailureBackoff time.Duration
+
+	// Webhook configuration. When enabled, serve registers a workspace
+	// webhook pointing at WebhookTargetURL and re-extracts changed resources.
+	WebhookEnabled    bool
+	WebhookListenAddr string
+	WebhookTargetURL  string
+	WebhookDebounce   time.Duration
 
 	// Output configuration
+	// StorageBackend names the registered storage backend to write to
+	StorageBackend string
+	// StorageParams holds backend-specific settings from STORAGE_PARAMS
+	// (key=value,key=value)
+	StorageParams   map[string]string
 	OutputDirectory string
+	// SnapshotsEnabled writes each run to OutputDirectory/<timestamp>/ and
+	// repoints OutputDirectory/latest once the run succeeds
+	SnapshotsEnabled bool
+	// ReconcileMode is one of "off", "delete" or "tombstone" and controls
+	// what happens to stored entities that no longer exist in Asana
+	ReconcileMode string
+	// OutputCompression is one of "none", "gzip" or "zstd"
+	OutputCompression string
+	// OutputEncryptionKey is a base64 AES key enabling encryption at rest.
+	// OutputEncryptionKeyFile names a file holding the key instead.
+	OutputEncryptionKey     string
+	OutputEncryptionKeyFile string
+	// OutputLock takes an exclusive lock on OutputDirectory so a second
+	// instance pointed at it refuses to start
+	OutputLock bool
+	// ChangeLog writes the entities each run created, updated or deleted to
+	// OutputDirectory/changes/<run_id>.jsonl
+	ChangeLog bool
+	// DurableWrites fsyncs every output file and its directory, so written
+	// entities survive a crash or power loss
+	DurableWrites bool
+	// OutputLayout maps resources to templates placing their entity files
+	// in partition directories, e.g. tasks=dt={date}/{gid}
+	OutputLayout map[string]string
+	// OutputEnvelope wraps entity files in {schema_version, extracted_at,
+	// workspace_gid, data}
+	OutputEnvelope bool
+	// IncrementalTasks fetches only the tasks modified since each project's
+	// watermark, kept in OutputDirectory/checkpoint.json
+	IncrementalTasks bool
+	// TaskFields is the task field preset requested from Asana: "minimal",
+	// "standard" or "full"
+	TaskFields string
+	// HTMLNotes is what happens to html_notes of the full task fields:
+	// "raw" keeps Asana's HTML, "sanitize" strips it to safe rich text and
+	// "markdown" replaces it with notes_markdown
+	HTMLNotes string
+	// AuditEventLookback is how far back the first window of audit log
+	// events reaches when audit_log_events is extracted
+	AuditEventLookback time.Duration
+	// DuckDBPath, when set, receives a DuckDB database with one table per
+	// resource after every successful run, built with the DuckDBBinary CLI
+	DuckDBPath   string
+	DuckDBBinary string
+	// XLSXDir, when set, receives an Excel workbook per successful run;
+	// XLSXHook is a shell command run on each workbook
+	XLSXDir  string
+	XLSXHook string
+	// SheetsID, when set, is the Google Sheet whose Users and Projects tabs
+	// are replaced after every successful run, authenticating with the
+	// service account key in SheetsCredentials
+	SheetsID          string
+	SheetsCredentials string
+
+	// Extraction configuration
+	ExtractionConcurrency int
+	ExtractResources      []string
+	// WriteBatchSize is the number of entities handed at once to backends
+	// that store batches (csv, avro, singer)
+	WriteBatchSize int
+	// Filters drop entities before they are stored: archived projects,
+	// projects outside FilterProjectTeams (GIDs or names) and users whose
+	// email is outside FilterUserEmailDomains
+	SkipArchivedProjects   bool
+	FilterProjectTeams     []string
+	FilterUserEmailDomains []string
+	// MaxErrorRate fails a run when more than this share (0-1) of entities
+	// could not be stored; zero disables the check
+	MaxErrorRate float64
+	// MaxAPICallsPerRun stops a run gracefully, marking it partial, once
+	// it has made this many API calls; zero means no limit
+	MaxAPICallsPerRun int
+	// VerifyRecount and VerifySampleSize cross-check a finished run against
+	// Asana; snapshots diverging by more than VerifyThreshold are flagged
+	// as suspect in the manifest
+	VerifyRecount    bool
+	VerifySampleSize int
+	VerifyThreshold  float64
+	// PreflightEnabled logs an estimate of the run's entities and API calls
+	// before it starts; runs estimated above PreflightMaxAPICalls are
+	// aborted, and a budget enables the pre-flight on its own
+	PreflightEnabled     bool
+	PreflightMaxAPICalls int
+	// AuditLogDir receives a per-run JSONL record of every API call; empty
+	// disables the audit log
+	AuditLogDir string
 
 	// Rate limiting configuration
 	RequestsPerMinute  int
 	MaxConcurrentRead  int
 	MaxConcurrentWrite int
+	// RateBurst is how many requests may be sent back to back; it is capped
+	// at RequestsPerMinute
+	RateBurst int
 
 	// HTTP client configuration
-	HTTPTimeout  time.Duration
-	BaseURL      string
-	UserPageSize int
+	// HTTPCache is one of "none", "memory" or "disk" and enables ETag /
+	// Last-Modified revalidation of GET responses
+	HTTPCache    string
+	HTTPCacheDir string
+	// HTTPRecord is one of "off", "record" or "replay": record saves API
+	// responses to HTTPRecordDir, replay answers requests from them offline
+	HTTPRecord    string
+	HTTPRecordDir string
+	// HTTPCompression requests gzip-compressed responses
+	HTTPCompression bool
+	HTTPTimeout     time.Duration
+	// PerRequestTimeout fails and retries an attempt whose response headers
+	// have not arrived within it; zero leaves attempts to HTTPTimeout
+	PerRequestTimeout time.Duration
+	// HTTPHedgeRequests duplicates GETs slower than the P99 latency when
+	// the rate limiter has a slot to spare
+	HTTPHedgeRequests bool
+
+	// Transport configuration. HTTPProxyURL overrides the standard
+	// HTTP_PROXY/HTTPS_PROXY variables; zero values keep Go's defaults.
+	HTTPProxyURL            string
+	TLSCAFile               string
+	TLSClientCertFile       string
+	TLSClientKeyFile        string
+	HTTPMaxIdleConnsPerHost int
+	HTTPDialTimeout         time.Duration
+	HTTPTLSHandshakeTimeout time.Duration
+	// Connection pool settings; raise them with EXTRACTION_CONCURRENCY
+	HTTPMaxIdleConns    int
+	HTTPMaxConnsPerHost int
+	HTTPIdleConnTimeout time.Duration
+	HTTPForceHTTP2      bool
+	BaseURL             string
+	UserPageSize        int
+	// PageSizes maps a resource to the page size of its queries, taken from
+	// PAGE_SIZE_<RESOURCE>. Users not listed get UserPageSize, other
+	// resources the API's maximum of 100.
+	PageSizes map[string]int
 
 	// Retry configuration
 	MaxRetries     int
 	InitialBackoff time.Duration
 	MaxBackoff     time.Duration
+	// RetryBudgetPerMinute caps retries across all requests; zero disables it
+	RetryBudgetPerMinute int
+
+	// MetricsAddr serves expvar metrics on /debug/vars in serve mode when set
+	MetricsAddr string
+	// APIAddr is where the api command serves the query API
+	APIAddr string
+	// ProgressInterval is how often a running extraction logs its progress;
+	// zero disables it
+	ProgressInterval time.Duration
+
+	// Notification configuration. Failed runs are posted to the Slack and/or
+	// generic webhook URL; NotifyStaleAfter alerts when no run has succeeded
+	// for that long (zero disables it).
+	NotifySlackWebhookURL string
+	NotifyWebhookURL      string
+	NotifyStaleAfter      time.Duration
+
+	// Tracing configuration. The OTLP exporter itself is configured through
+	// the standard OTEL_EXPORTER_OTLP_* variables.
+	TracingEnabled     bool
+	TracingServiceName string
 }
 
 // Load loads configuration from environment variables with defaults
 func Load() (*Config, error) {
-	err := godotenv.Load()
+	cfg, err := LoadCredentials()
 	if err != nil {
-		log.Println("No .env file found, fetching from system environment")
+		return nil, err
+	}
+
+	cfg.AsanaWorkspace = lookupEnv("ASANA_WORKSPACE")
+	if cfg.AsanaWorkspace == "" {
+		return nil, fmt.Errorf("ASANA_WORKSPACE environment variable is required")
+	}
+
+	switch cfg.ScheduleMode {
+	case "cron":
+	case "interval":
+		if cfg.ScheduleInterval <= 0 {
+			return nil, fmt.Errorf("SCHEDULE_INTERVAL must be positive (got %s)", cfg.ScheduleInterval)
+		}
+		if len(cfg.ResourceSchedules) > 0 {
+			return nil, fmt.Errorf("SCHEDULE_CRON_<RESOURCE> requires SCHEDULE_MODE=cron")
+		}
+	default:
+		return nil, fmt.Errorf("SCHEDULE_MODE must be one of cron, interval (got %q)", cfg.ScheduleMode)
+	}
+
+	switch cfg.ScheduleOverlapPolicy {
+	case "skip", "queue", "allow":
+	default:
+		return nil, fmt.Errorf("SCHEDULE_OVERLAP_POLICY must be one of skip, queue, allow (got %q)", cfg.ScheduleOverlapPolicy)
+	}
+
+	if cfg.ScheduleFailureBackoff < 0 {
+		return nil, fmt.Errorf("SCHEDULE_FAILURE_BACKOFF must not be negative (got %s)", cfg.ScheduleFailureBackoff)
+	}
+	if cfg.ScheduleFailureBackoff > 0 && cfg.ScheduleMaxFailureBackoff < cfg.ScheduleFailureBackoff {
+		return nil, fmt.Errorf("SCHEDULE_MAX_FAILURE_BACKOFF must be at least SCHEDULE_FAILURE_BACKOFF (got %s < %s)", cfg.ScheduleMaxFailureBackoff, cfg.ScheduleFailureBackoff)
+	}
+
+	switch cfg.ReconcileMode {
+	case "off", "delete", "tombstone":
+	default:
+		return nil, fmt.Errorf("RECONCILE_MODE must be one of off, delete, tombstone (got %q)", cfg.ReconcileMode)
+	}
+
+	if cfg.MaxErrorRate < 0 || cfg.MaxErrorRate > 1 {
+		return nil, fmt.Errorf("MAX_ERROR_RATE must be between 0 and 1 (got %v)", cfg.MaxErrorRate)
+	}
+
+	if cfg.PerRequestTimeout < 0 {
+		return nil, fmt.Errorf("PER_REQUEST_TIMEOUT must not be negative (got %v)", cfg.PerRequestTimeout)
+	}
+	if cfg.PerRequestTimeout > 0 && cfg.HTTPTimeout > 0 && cfg.PerRequestTimeout >= cfg.HTTPTimeout {
+		return nil, fmt.Errorf("PER_REQUEST_TIMEOUT (%v) must be shorter than HTTP_TIMEOUT (%v)", cfg.PerRequestTimeout, cfg.HTTPTimeout)
+	}
+
+	if cfg.MaxAPICallsPerRun < 0 {
+		return nil, fmt.Errorf("MAX_API_CALLS_PER_RUN must not be negative (got %d)", cfg.MaxAPICallsPerRun)
+	}
+
+	if cfg.VerifySampleSize < 0 {
+		return nil, fmt.Errorf("VERIFY_SAMPLE_SIZE must not be negative (got %d)", cfg.VerifySampleSize)
+	}
+	if cfg.VerifyThreshold <= 0 || cfg.VerifyThreshold > 1 {
+		return nil, fmt.Errorf("VERIFY_THRESHOLD must be above 0 and at most 1 (got %v)", cfg.VerifyThreshold)
+	}
+
+	if cfg.PreflightMaxAPICalls < 0 {
+		return nil, fmt.Errorf("PREFLIGHT_MAX_API_CALLS must not be negative (got %d)", cfg.PreflightMaxAPICalls)
+	}
+
+	if cfg.RateBurst < 1 {
+		return nil, fmt.Errorf("RATE_BURST must be at least 1 (got %d)", cfg.RateBurst)
+	}
+
+	if cfg.WriteBatchSize < 1 {
+		return nil, fmt.Errorf("WRITE_BATCH_SIZE must be at least 1 (got %d)", cfg.WriteBatchSize)
+	}
+
+	if cfg.RetryBudgetPerMinute < 0 {
+		return nil, fmt.Errorf("RETRY_BUDGET_PER_MINUTE must not be negative (got %d)", cfg.RetryBudgetPerMinute)
+	}
+
+	if cfg.SnapshotsEnabled && cfg.StorageBackend != "json" {
+		return nil, fmt.Errorf("SNAPSHOTS_ENABLED requires STORAGE_BACKEND=json (got %q)", cfg.StorageBackend)
+	}
+	if cfg.SnapshotsEnabled {
+		// Every run publishes its snapshot as latest, so every run must
+		// cover all the configured resources
+		switch {
+		case len(cfg.ResourceSchedules) > 0:
+			return nil, fmt.Errorf("SNAPSHOTS_ENABLED cannot be combined with SCHEDULE_CRON_<RESOURCE>, since a resource's run would publish a snapshot holding only that resource")
+		case cfg.WebhookEnabled:
+			return nil, fmt.Errorf("SNAPSHOTS_ENABLED cannot be combined with WEBHOOK_ENABLED, since a webhook run would publish a snapshot holding only the resources it touched")
+		}
+	}
+
+	if cfg.ChangeLog {
+		switch {
+		case cfg.StorageBackend != "json":
+			return nil, fmt.Errorf("CHANGE_LOG requires STORAGE_BACKEND=json (got %q)", cfg.StorageBackend)
+		case cfg.SnapshotsEnabled:
+			return nil, fmt.Errorf("CHANGE_LOG cannot be combined with SNAPSHOTS_ENABLED, since every snapshot starts empty")
+		case cfg.OutputEncryptionKey != "" || cfg.OutputEncryptionKeyFile != "":
+			return nil, fmt.Errorf("CHANGE_LOG cannot be combined with output encryption, since change payloads are plain JSON")
+		}
+	}
+
+	if cfg.DurableWrites {
+		switch cfg.StorageBackend {
+		case "json", "csv", "avro", "protobuf":
+		default:
+			return nil, fmt.Errorf("DURABLE_WRITES requires a file backend, json, csv, avro or protobuf (got %q)", cfg.StorageBackend)
+		}
+	}
+
+	if len(cfg.OutputLayout) > 0 && cfg.StorageBackend != "json" {
+		return nil, fmt.Errorf("OUTPUT_LAYOUT requires STORAGE_BACKEND=json (got %q)", cfg.StorageBackend)
+	}
+
+	if cfg.OutputEnvelope && cfg.StorageBackend != "json" {
+		return nil, fmt.Errorf("OUTPUT_ENVELOPE requires STORAGE_BACKEND=json (got %q)", cfg.StorageBackend)
+	}
+
+	if cfg.IncrementalTasks {
+		switch {
+		case cfg.StorageBackend != "json":
+			return nil, fmt.Errorf("TASKS_INCREMENTAL requires STORAGE_BACKEND=json (got %q)", cfg.StorageBackend)
+		case cfg.SnapshotsEnabled:
+			return nil, fmt.Errorf("TASKS_INCREMENTAL cannot be combined with SNAPSHOTS_ENABLED, since every snapshot starts empty")
+		}
+	}
+
+	switch cfg.TaskFields {
+	case "minimal", "standard", "full":
+	default:
+		return nil, fmt.Errorf("TASK_FIELDS must be one of minimal, standard, full (got %q)", cfg.TaskFields)
+	}
+
+	switch cfg.HTMLNotes {
+	case "raw":
+	case "sanitize", "markdown":
+		if cfg.TaskFields != "full" {
+			return nil, fmt.Errorf("HTML_NOTES=%s requires TASK_FIELDS=full, the only preset requesting html_notes (got %q)", cfg.HTMLNotes, cfg.TaskFields)
+		}
+	default:
+		return nil, fmt.Errorf("HTML_NOTES must be one of raw, sanitize, markdown (got %q)", cfg.HTMLNotes)
+	}
+
+	if cfg.DuckDBPath != "" && cfg.StorageBackend != "json" {
+		return nil, fmt.Errorf("DUCKDB_PATH requires STORAGE_BACKEND=json (got %q)", cfg.StorageBackend)
+	}
+	if cfg.XLSXDir != "" && cfg.StorageBackend != "json" {
+		return nil, fmt.Errorf("XLSX_DIR requires STORAGE_BACKEND=json (got %q)", cfg.StorageBackend)
+	}
+	if cfg.XLSXHook != "" && cfg.XLSXDir == "" {
+		return nil, fmt.Errorf("XLSX_HOOK requires XLSX_DIR")
+	}
+	if cfg.SheetsID != "" {
+		switch {
+		case cfg.SheetsCredentials == "":
+			return nil, fmt.Errorf("GOOGLE_SHEETS_ID requires GOOGLE_SHEETS_CREDENTIALS_FILE")
+		case cfg.StorageBackend != "json":
+			return nil, fmt.Errorf("GOOGLE_SHEETS_ID requires STORAGE_BACKEND=json (got %q)", cfg.StorageBackend)
+		}
+	}
+
+	if cfg.WebhookEnabled && cfg.WebhookTargetURL == "" {
+		return nil, fmt.Errorf("WEBHOOK_TARGET_URL is required when WEBHOOK_ENABLED is set")
+	}
+
+	if cfg.OutputEncryptionKey != "" && cfg.OutputEncryptionKeyFile != "" {
+		return nil, fmt.Errorf("set only one of OUTPUT_ENCRYPTION_KEY and OUTPUT_ENCRYPTION_KEY_FILE")
+	}
+
+	switch cfg.HTTPCache {
+	case "none", "memory", "disk":
+	default:
+		return nil, fmt.Errorf("HTTP_CACHE must be one of none, memory, disk (got %q)", cfg.HTTPCache)
+	}
+
+	switch cfg.OutputCompression {
+	case "none", "gzip", "zstd":
+	default:
+		return nil, fmt.Errorf("OUTPUT_COMPRESSION must be one of none, gzip, zstd (got %q)", cfg.OutputCompression)
+	}
+
+	for _, resource := range cfg.ExtractResources {
+		if !isSupportedResource(resource) {
+			return nil, fmt.Errorf("EXTRACT_RESOURCES contains unknown resource %q (supported: %s)",
+				resource, strings.Join(append(SupportedResources, OptionalResources...), ","))
+		}
+		if isOptionalResource(resource) && cfg.StorageBackend != "json" {
+			return nil, fmt.Errorf("extracting %s requires STORAGE_BACKEND=json (got %q)", resource, cfg.StorageBackend)
+		}
+		if resource == "audit_log_events" {
+			switch {
+			case cfg.SnapshotsEnabled:
+				return nil, fmt.Errorf("extracting audit_log_events cannot be combined with SNAPSHOTS_ENABLED, since every snapshot starts without a checkpoint")
+			case cfg.OutputEncryptionKey != "" || cfg.OutputEncryptionKeyFile != "":
+				return nil, fmt.Errorf("extracting audit_log_events cannot be combined with output encryption, since events are written as plain NDJSON")
+			case cfg.AuditEventLookback <= 0:
+				return nil, fmt.Errorf("AUDIT_LOG_EVENTS_LOOKBACK must be positive (got %s)", cfg.AuditEventLookback)
+			}
+		}
+		if resource == "avatars" && (cfg.OutputEncryptionKey != "" || cfg.OutputEncryptionKeyFile != "") {
+			return nil, fmt.Errorf("extracting avatars cannot be combined with output encryption, since avatars are written as plain images")
+		}
+		if resource == "attachments" && (cfg.OutputEncryptionKey != "" || cfg.OutputEncryptionKeyFile != "") {
+			return nil, fmt.Errorf("extracting attachments cannot be combined with output encryption, since attachment files are written as is")
+		}
+	}
+
+	return cfg, nil
+}
+
+// LoadCredentials loads configuration like Load but only requires ASANA_TOKEN.
+// It serves commands such as workspace discovery that run before a workspace
+// has been chosen.
+func LoadCredentials() (*Config, error) {
+	if err := loadDotenv(); err != nil {
+		return nil, err
 	}
 
 	cfg := &Config{
 		// Defaults
-		ScheduleCron:       getEnv("SCHEDULE_CRON", "*/5 * * * *"), // Every 5 minutes
-		OutputDirectory:    getEnv("OUTPUT_DIR", "./output"),
-		RequestsPerMinute:  getEnvInt("REQUESTS_PER_MINUTE", 150),
-		MaxConcurrentRead:  getEnvInt("MAX_CONCURRENT_READ", 50),
-		MaxConcurrentWrite: getEnvInt("MAX_CONCURRENT_WRITE", 15),
-		HTTPTimeout:        getEnvDuration("HTTP_TIMEOUT", 30*time.Second),
-		BaseURL:            getEnv("BASE_URL", "https://app.asana.com/api/1.0"),
-		UserPageSize:       getEnvInt("USER_PAGE_SIZE", 100),
-		MaxRetries:         getEnvInt("MAX_RETRIES", 5),
-		InitialBackoff:     getEnvDuration("INITIAL_BACKOFF", 1*time.Second),
-		MaxBackoff:         getEnvDuration("MAX_BACKOFF", 60*time.Second),
-	}
-
-	// Required fields
-	cfg.AsanaToken = os.Getenv("ASANA_TOKEN")
-	if cfg.AsanaToken == "" {
+		RunOnce:                   getEnvBool("RUN_ONCE", false),
+		DryRun:                    getEnvBool("DRY_RUN", false),
+		ScheduleCron:              getEnv("SCHEDULE_CRON", "0 */5 * * * *"), // Every 5 minutes
+		ScheduleMode:              getEnv("SCHEDULE_MODE", "cron"),
+		ScheduleInterval:          getEnvDuration("SCHEDULE_INTERVAL", 5*time.Minute),
+		ScheduleBlackouts:         lookupEnv("SCHEDULE_BLACKOUTS"),
+		ScheduleOverlapPolicy:     getEnv("SCHEDULE_OVERLAP_POLICY", "skip"),
+		JobTimeout:                getEnvDuration("JOB_TIMEOUT", 0),
+		DrainTimeout:              getEnvDuration("DRAIN_TIMEOUT", 30*time.Second),
+		ScheduleFailureBackoff:    getEnvDuration("SCHEDULE_FAILURE_BACKOFF", 5*time.Minute),
+		ScheduleMaxFailureBackoff: getEnvDuration("SCHEDULE_MAX_FAILURE_BACKOFF", time.Hour),
+		WebhookEnabled:            getEnvBool("WEBHOOK_ENABLED", false),
+		WebhookListenAddr:         getEnv("WEBHOOK_LISTEN_ADDR", ":8080"),
+		WebhookTargetURL:          lookupEnv("WEBHOOK_TARGET_URL"),
+		WebhookDebounce:           getEnvDuration("WEBHOOK_DEBOUNCE", 30*time.Second),
+		StorageBackend:            getEnv("STORAGE_BACKEND", "json"),
+		StorageParams:             getEnvMap("STORAGE_PARAMS"),
+		OutputDirectory:           getEnv("OUTPUT_DIR", "./output"),
+		SnapshotsEnabled:          getEnvBool("SNAPSHOTS_ENABLED", false),
+		ReconcileMode:             getEnv("RECONCILE_MODE", "off"),
+		OutputCompression:         getEnv("OUTPUT_COMPRESSION", "none"),
+		OutputEncryptionKey:       lookupEnv("OUTPUT_ENCRYPTION_KEY"),
+		OutputEncryptionKeyFile:   lookupEnv("OUTPUT_ENCRYPTION_KEY_FILE"),
+		OutputLock:                getEnvBool("OUTPUT_LOCK", false),
+		ChangeLog:                 getEnvBool("CHANGE_LOG", false),
+		DurableWrites:             getEnvBool("DURABLE_WRITES", false),
+		OutputLayout:              getEnvMap("OUTPUT_LAYOUT"),
+		OutputEnvelope:            getEnvBool("OUTPUT_ENVELOPE", false),
+		IncrementalTasks:          getEnvBool("TASKS_INCREMENTAL", false),
+		TaskFields:                getEnv("TASK_FIELDS", "standard"),
+		HTMLNotes:                 getEnv("HTML_NOTES", "raw"),
+		AuditEventLookback:        getEnvDuration("AUDIT_LOG_EVENTS_LOOKBACK", 24*time.Hour),
+		DuckDBPath:                lookupEnv("DUCKDB_PATH"),
+		DuckDBBinary:              getEnv("DUCKDB_BINARY", "duckdb"),
+		XLSXDir:                   lookupEnv("XLSX_DIR"),
+		XLSXHook:                  lookupEnv("XLSX_HOOK"),
+		SheetsID:                  lookupEnv("GOOGLE_SHEETS_ID"),
+		SheetsCredentials:         lookupEnv("GOOGLE_SHEETS_CREDENTIALS_FILE"),
+		ExtractionConcurrency:     getEnvInt("EXTRACTION_CONCURRENCY", 4),
+		ExtractResources:          getEnvList("EXTRACT_RESOURCES", SupportedResources),
+		WriteBatchSize:            getEnvInt("WRITE_BATCH_SIZE", 100),
+		SkipArchivedProjects:      getEnvBool("FILTER_SKIP_ARCHIVED_PROJECTS", false),
+		FilterProjectTeams:        getEnvList("FILTER_PROJECT_TEAMS", nil),
+		FilterUserEmailDomains:    getEnvList("FILTER_USER_EMAIL_DOMAINS", nil),
+		MaxErrorRate:              getEnvFloat("MAX_ERROR_RATE", 0),
+		MaxAPICallsPerRun:         getEnvInt("MAX_API_CALLS_PER_RUN", 0),
+		VerifyRecount:             getEnvBool("VERIFY_RECOUNT", false),
+		VerifySampleSize:          getEnvInt("VERIFY_SAMPLE_SIZE", 0),
+		VerifyThreshold:           getEnvFloat("VERIFY_THRESHOLD", 0.01),
+		PreflightEnabled:          getEnvBool("PREFLIGHT_ENABLED", false),
+		PreflightMaxAPICalls:      getEnvInt("PREFLIGHT_MAX_API_CALLS", 0),
+		AuditLogDir:               lookupEnv("AUDIT_LOG_DIR"),
+		RequestsPerMinute:         getEnvInt("REQUESTS_PER_MINUTE", 150),
+		RateBurst:                 getEnvInt("RATE_BURST", 10),
+		MaxConcurrentRead:         getEnvInt("MAX_CONCURRENT_READ", 50),
+		MaxConcurrentWrite:        getEnvInt("MAX_CONCURRENT_WRITE", 15),
+		HTTPCache:                 getEnv("HTTP_CACHE", "none"),
+		HTTPCacheDir:              getEnv("HTTP_CACHE_DIR", "./.http-cache"),
+		HTTPRecord:                getEnv("HTTP_RECORD", "off"),
+		HTTPRecordDir:             lookupEnv("HTTP_RECORD_DIR"),
+		HTTPCompression:           getEnvBool("HTTP_COMPRESSION", true),
+		HTTPHedgeRequests:         getEnvBool("HTTP_HEDGE_REQUESTS", false),
+		HTTPTimeout:               getEnvDuration("HTTP_TIMEOUT", 30*time.Second),
+		PerRequestTimeout:         getEnvDuration("PER_REQUEST_TIMEOUT", 0),
+		HTTPProxyURL:              lookupEnv("HTTP_PROXY_URL"),
+		TLSCAFile:                 lookupEnv("TLS_CA_FILE"),
+		TLSClientCertFile:         lookupEnv("TLS_CLIENT_CERT_FILE"),
+		TLSClientKeyFile:          lookupEnv("TLS_CLIENT_KEY_FILE"),
+		HTTPMaxIdleConnsPerHost:   getEnvInt("HTTP_MAX_IDLE_CONNS_PER_HOST", 0),
+		HTTPDialTimeout:           getEnvDuration("HTTP_DIAL_TIMEOUT", 0),
+		HTTPTLSHandshakeTimeout:   getEnvDuration("HTTP_TLS_HANDSHAKE_TIMEOUT", 0),
+		HTTPMaxIdleConns:          getEnvInt("HTTP_MAX_IDLE_CONNS", 0),
+		HTTPMaxConnsPerHost:       getEnvInt("HTTP_MAX_CONNS_PER_HOST", 0),
+		HTTPIdleConnTimeout:       getEnvDuration("HTTP_IDLE_CONN_TIMEOUT", 0),
+		HTTPForceHTTP2:            getEnvBool("HTTP_FORCE_HTTP2", true),
+		BaseURL:                   getEnv("BASE_URL", "https://app.asana.com/api/1.0"),
+		UserPageSize:              getEnvInt("USER_PAGE_SIZE", 100),
+		MaxRetries:                getEnvInt("MAX_RETRIES", 5),
+		InitialBackoff:            getEnvDuration("INITIAL_BACKOFF", 1*time.Second),
+		MaxBackoff:                getEnvDuration("MAX_BACKOFF", 60*time.Second),
+		RetryBudgetPerMinute:      getEnvInt("RETRY_BUDGET_PER_MINUTE", 0),
+		MetricsAddr:               lookupEnv("METRICS_ADDR"),
+		APIAddr:                   getEnv("API_ADDR", ":8090"),
+		ProgressInterval:          getEnvDuration("PROGRESS_INTERVAL", 30*time.Second),
+		NotifySlackWebhookURL:     lookupEnv("NOTIFY_SLACK_WEBHOOK_URL"),
+		NotifyWebhookURL:          lookupEnv("NOTIFY_WEBHOOK_URL"),
+		NotifyStaleAfter:          getEnvDuration("NOTIFY_STALE_AFTER", 0),
+		TracingEnabled:            getEnvBool("TRACING_ENABLED", false),
+		TracingServiceName:        getEnv("OTEL_SERVICE_NAME", "asana-extractor"),
+	}
+
+	if (cfg.TLSClientCertFile == "") != (cfg.TLSClientKeyFile == "") {
+		return nil, fmt.Errorf("TLS_CLIENT_CERT_FILE and TLS_CLIENT_KEY_FILE must be set together")
+	}
+	if cfg.RequestsPerMinute < 1 {
+		return nil, fmt.Errorf("REQUESTS_PER_MINUTE must be at least 1 (got %d)", cfg.RequestsPerMinute)
+	}
+
+	switch cfg.HTTPRecord {
+	case "off", "record", "replay":
+	default:
+		return nil, fmt.Errorf("HTTP_RECORD must be one of off, record, replay (got %q)", cfg.HTTPRecord)
+	}
+	if cfg.HTTPRecord != "off" && cfg.HTTPRecordDir == "" {
+		return nil, fmt.Errorf("HTTP_RECORD=%s requires HTTP_RECORD_DIR", cfg.HTTPRecord)
+	}
+
+	// Required fields; replayed requests need no token
+	cfg.AsanaToken = lookupEnv("ASANA_TOKEN")
+	cfg.AsanaTokenFile = lookupEnv("ASANA_TOKEN_FILE")
+	if cfg.AsanaToken == "" && cfg.AsanaTokenFile == "" && cfg.HTTPRecord != "replay" {
 		return nil, fmt.Errorf("ASANA_TOKEN environment variable is required")
 	}
+	if cfg.AsanaToken != "" && cfg.AsanaTokenFile != "" {
+		return nil, fmt.Errorf("set only one of ASANA_TOKEN and ASANA_TOKEN_FILE")
+	}
 
-	cfg.AsanaWorkspace = os.Getenv("ASANA_WORKSPACE")
-	if cfg.AsanaWorkspace == "" {
-		return nil, fmt.Errorf("ASANA_WORKSPACE environment variable is required")
+	cfg.ResourceSchedules = make(map[string]string)
+	for _, resource := range SupportedResources {
+		if expr := lookupEnv("SCHEDULE_CRON_" + strings.ToUpper(resource)); expr != "" {
+			cfg.ResourceSchedules[resource] = expr
+		}
+	}
+
+	cfg.PageSizes = make(map[string]int)
+	for _, resource := range PagedResources() {
+		key := "PAGE_SIZE_" + strings.ToUpper(resource)
+		value := lookupEnv(key)
+		if value == "" {
+			continue
+		}
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 || size > 100 {
+			return nil, fmt.Errorf("%s must be between 1 and 100 (got %q)", key, value)
+		}
+		cfg.PageSizes[resource] = size
 	}
 
 	return cfg, nil
 }
 
+// loadDotenv loads the file named by ENV_FILE, or ./.env if it exists.
+// Variables already set in the environment are never overridden. A missing
+// ./.env is not an error; a missing ENV_FILE is.
+func loadDotenv() error {
+	if path := lookupEnv("ENV_FILE"); path != "" {
+		if err := godotenv.Load(path); err != nil {
+			return fmt.Errorf("failed to load env file %s: %w", path, err)
+		}
+		return nil
+	}
+
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to load .env: %w", err)
+	}
+	return nil
+}
+
+// Redacted returns a copy of the configuration that is safe to log or persist,
+// with the Asana token, encryption key, notification URLs, storage
+// parameter values, the Excel hook and the proxy URL's user info, which may
+// carry credentials, masked
+func (c Config) Redacted() Config {
+	if c.AsanaToken != "" {
+		c.AsanaToken = "****"
+	}
+	if c.OutputEncryptionKey != "" {
+		c.OutputEncryptionKey = "****"
+	}
+	if c.NotifySlackWebhookURL != "" {
+		c.NotifySlackWebhookURL = "****"
+	}
+	if c.NotifyWebhookURL != "" {
+		c.NotifyWebhookURL = "****"
+	}
+	if c.XLSXHook != "" {
+		c.XLSXHook = "****"
+	}
+	if c.HTTPProxyURL != "" {
+		c.HTTPProxyURL = redactUserinfo(c.HTTPProxyURL)
+	}
+	if len(c.StorageParams) > 0 {
+		params := make(map[string]string, len(c.StorageParams))
+		for k := range c.StorageParams {
+			params[k] = "****"
+		}
+		c.StorageParams = params
+	}
+	return c
+}
+
+// redactUserinfo masks the user info of rawURL, keeping the rest readable.
+// A URL that does not parse is masked entirely.
+func redactUserinfo(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "****"
+	}
+	if u.User == nil {
+		return rawURL
+	}
+	// url.User would escape the mask
+	u.User = nil
+	return strings.Replace(u.String(), "//", "//****@", 1)
+}
+
+// SupportedResources lists the resource types extracted by default
+var SupportedResources = []string{"users", "projects", "tasks", "teams"}
+
+// OptionalResources lists the resource types EXTRACT_RESOURCES also accepts
+// but that are only extracted when listed
+var OptionalResources = []string{"user_task_lists", "avatars", "attachments", "status_updates", "custom_fields", "audit_log_events"}
+
+// PagedResources lists the resources PAGE_SIZE_<RESOURCE> applies to: all
+// but avatars, which are downloaded one at a time
+func PagedResources() []string {
+	var paged []string
+	for _, r := range append(SupportedResources, OptionalResources...) {
+		if r != "avatars" {
+			paged = append(paged, r)
+		}
+	}
+	return paged
+}
+
+// isSupportedResource reports whether name is one of SupportedResources or
+// OptionalResources
+func isSupportedResource(name string) bool {
+	for _, r := range append(SupportedResources, OptionalResources...) {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isOptionalResource reports whether name is one of OptionalResources,
+// which only the json backend can store
+func isOptionalResource(name string) bool {
+	for _, r := range OptionalResources {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
 // getEnv gets an environment variable or returns a default value
 func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+	if value := lookupEnv(key); value != "" {
 		return value
 	}
 	return defaultValue
@@ -84,7 +709,7 @@ func getEnv(key, defaultValue string) string {
 
 // getEnvInt gets an integer environment variable or returns a default value
 func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
+	if value := lookupEnv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
 			return intValue
 		}
@@ -92,12 +717,70 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvFloat gets a float environment variable or returns a default value
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := lookupEnv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvBool gets a boolean environment variable or returns a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := lookupEnv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 // getEnvDuration gets a duration environment variable or returns a default value
 func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
+	if value := lookupEnv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
 			return duration
 		}
 	}
 	return defaultValue
 }
+
+// getEnvList gets a comma-separated environment variable or returns a default value.
+// Entries are trimmed, lower-cased and empty entries are dropped.
+func getEnvList(key string, defaultValue []string) []string {
+	value := lookupEnv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			list = append(list, item)
+		}
+	}
+	if len(list) == 0 {
+		return defaultValue
+	}
+	return list
+}
+
+// getEnvMap parses a comma-separated list of key=value pairs. Keys and values
+// are trimmed; entries without "=" are ignored. Returns nil when unset.
+func getEnvMap(key string) map[string]string {
+	value := lookupEnv(key)
+	if value == "" {
+		return nil
+	}
+
+	m := make(map[string]string)
+	for _, item := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(item, "=")
+		if k = strings.TrimSpace(k); ok && k != "" {
+			m[k] = strings.TrimSpace(v)
+		}
+	}
+	return m
+}
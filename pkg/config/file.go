@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileValues holds the settings read by LoadFromFile while it runs Load.
+// Keys are in environment-variable form (ASANA_TOKEN).
+var fileValues map[string]string
+
+// lookupEnv returns the value for the environment variable key: a flag set
+// through Flags, else the environment, else the configuration file loaded by
+// LoadFromFile
+func lookupEnv(key string) string {
+	if value, ok := flagValues[key]; ok {
+		return value
+	}
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fileValues[key]
+}
+
+// LoadFromFile loads configuration like Load, reading settings from a YAML
+// file as well. Precedence is flags > environment (including .env) > file >
+// defaults; flags apply when loading through Flags.
+//
+// Keys are the environment variable names, in any case, and nested maps
+// are joined with underscores, so these are equivalent:
+//
+//	schedule_cron: "0 0 * * * *"
+//
+//	schedule:
+//	  cron: "0 0 * * * *"
+//
+// Lists are joined with commas (extract_resources: [users, tasks]).
+func LoadFromFile(path string) (*Config, error) {
+	values, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fileValues = values
+	defer func() { fileValues = nil }()
+
+	return Load()
+}
+
+// readConfigFile parses a YAML configuration file into env-style keys
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	flatten("", doc, values)
+	return values, nil
+}
+
+// flatten writes every leaf of doc into values under its env-style key
+func flatten(prefix string, doc map[string]any, values map[string]string) {
+	for k, v := range doc {
+		key := strings.ToUpper(strings.ReplaceAll(k, "-", "_"))
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+
+		switch v := v.(type) {
+		case nil:
+		case map[string]any:
+			flatten(key, v, values)
+		case []any:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			values[key] = strings.Join(items, ",")
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadFromFile(t *testing.T) {
+	path := writeConfigFile(t, `
+asana_token: file-token
+asana_workspace: "12345"
+schedule:
+  cron: "0 0 * * * *"
+extract_resources: [users, teams]
+MAX_ERROR_RATE: 0.05
+job-timeout: 10m
+`)
+
+	clearEnv := func(t *testing.T) {
+		for _, key := range []string{"ASANA_TOKEN", "ASANA_WORKSPACE", "SCHEDULE_CRON", "EXTRACT_RESOURCES", "MAX_ERROR_RATE", "JOB_TIMEOUT", "REQUESTS_PER_MINUTE"} {
+			t.Setenv(key, "")
+		}
+	}
+
+	t.Run("File values apply over defaults", func(t *testing.T) {
+		clearEnv(t)
+
+		cfg, err := LoadFromFile(path)
+		if err != nil {
+			t.Fatalf("LoadFromFile() failed: %v", err)
+		}
+
+		if cfg.AsanaToken != "file-token" || cfg.AsanaWorkspace != "12345" {
+			t.Errorf("Expected credentials from file, got %q/%q", cfg.AsanaToken, cfg.AsanaWorkspace)
+		}
+		if cfg.ScheduleCron != "0 0 * * * *" {
+			t.Errorf("Expected nested key to map to SCHEDULE_CRON, got %q", cfg.ScheduleCron)
+		}
+		if !reflect.DeepEqual(cfg.ExtractResources, []string{"users", "teams"}) {
+			t.Errorf("Expected list to be joined, got %v", cfg.ExtractResources)
+		}
+		if cfg.MaxErrorRate != 0.05 || cfg.JobTimeout != 10*time.Minute {
+			t.Errorf("Expected typed values, got rate=%v timeout=%v", cfg.MaxErrorRate, cfg.JobTimeout)
+		}
+		if cfg.RequestsPerMinute != 150 {
+			t.Errorf("Expected unset values to keep defaults, got %d", cfg.RequestsPerMinute)
+		}
+	})
+
+	t.Run("Environment overrides file", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv("SCHEDULE_CRON", "0 */10 * * * *")
+
+		cfg, err := LoadFromFile(path)
+		if err != nil {
+			t.Fatalf("LoadFromFile() failed: %v", err)
+		}
+		if cfg.ScheduleCron != "0 */10 * * * *" {
+			t.Errorf("Expected env to win, got %q", cfg.ScheduleCron)
+		}
+	})
+
+	t.Run("File values do not leak into later loads", func(t *testing.T) {
+		clearEnv(t)
+		if _, err := Load(); err == nil {
+			t.Error("Expected Load() to ignore the previously loaded file")
+		}
+	})
+}
+
+func TestLoadFromFile_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "Missing file", path: filepath.Join(t.TempDir(), "missing.yaml")},
+		{name: "Invalid YAML", path: writeConfigFile(t, "asana_token: [unclosed")},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := LoadFromFile(tc.path); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// flagValues holds the command-line overrides applied by Flags while it
+// runs a loader. Keys are in environment-variable form.
+var flagValues map[string]string
+
+// valueKind describes how a flag value is validated when parsed
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindBool
+	kindInt
+	kindFloat
+	kindDuration
+)
+
+// flagSpec maps a command-line flag to the environment variable it overrides
+type flagSpec struct {
+	name  string
+	env   string
+	kind  valueKind
+	usage string
+}
+
+// flagSpecs lists a flag for every configuration variable. Per-resource
+// schedules (--schedule-<resource>) and page sizes (--page-size-<resource>)
+// are added in NewFlags.
+var flagSpecs = []flagSpec{
+	{"env-file", "ENV_FILE", kindString, "dotenv file to load instead of ./.env"},
+	{"token", "ASANA_TOKEN", kindString, "Asana personal access token (prefer the environment, flags are visible in ps)"},
+	{"token-file", "ASANA_TOKEN_FILE", kindString, "file holding the Asana token, re-read when the token is rejected"},
+	{"workspace", "ASANA_WORKSPACE", kindString, "Asana workspace GID"},
+	{"run-once", "RUN_ONCE", kindBool, "run a single extraction and exit"},
+	{"dry-run", "DRY_RUN", kindBool, "walk the API and count entities without writing anything"},
+	{"schedule-mode", "SCHEDULE_MODE", kindString, "cron or interval"},
+	{"schedule", "SCHEDULE_CRON", kindString, "6-field cron expression for the default schedule"},
+	{"schedule-interval", "SCHEDULE_INTERVAL", kindDuration, "time between the end of a run and the next one in interval mode"},
+	{"blackouts", "SCHEDULE_BLACKOUTS", kindString, "comma-separated windows without scheduled runs, e.g. \"Sun 01:00-03:00 UTC\""},
+	{"overlap-policy", "SCHEDULE_OVERLAP_POLICY", kindString, "skip, queue or allow overlapping runs"},
+	{"job-timeout", "JOB_TIMEOUT", kindDuration, "maximum duration of one run (0 disables)"},
+	{"drain-timeout", "DRAIN_TIMEOUT", kindDuration, "how long shutdown waits for a running extraction"},
+	{"failure-backoff", "SCHEDULE_FAILURE_BACKOFF", kindDuration, "hold scheduled runs back after a failed run (0 disables)"},
+	{"max-failure-backoff", "SCHEDULE_MAX_FAILURE_BACKOFF", kindDuration, "upper bound of the failure backoff"},
+	{"webhook", "WEBHOOK_ENABLED", kindBool, "enable the Asana webhook receiver"},
+	{"webhook-listen-addr", "WEBHOOK_LISTEN_ADDR", kindString, "address the webhook receiver listens on"},
+	{"webhook-target-url", "WEBHOOK_TARGET_URL", kindString, "public URL Asana posts webhook events to"},
+	{"webhook-debounce", "WEBHOOK_DEBOUNCE", kindDuration, "time to collect webhook events before extracting"},
+	{"storage", "STORAGE_BACKEND", kindString, "registered storage backend to write to"},
+	{"storage-params", "STORAGE_PARAMS", kindString, "backend-specific key=value,key=value settings"},
+	{"output-dir", "OUTPUT_DIR", kindString, "output directory"},
+	{"snapshots", "SNAPSHOTS_ENABLED", kindBool, "write each run to its own timestamped directory"},
+	{"reconcile", "RECONCILE_MODE", kindString, "off, delete or tombstone entities deleted in Asana"},
+	{"compression", "OUTPUT_COMPRESSION", kindString, "none, gzip or zstd"},
+	{"encryption-key", "OUTPUT_ENCRYPTION_KEY", kindString, "base64 AES key for encryption at rest"},
+	{"encryption-key-file", "OUTPUT_ENCRYPTION_KEY_FILE", kindString, "file holding the encryption key"},
+	{"lock", "OUTPUT_LOCK", kindBool, "lock the output directory against other instances"},
+	{"change-log", "CHANGE_LOG", kindBool, "write the entities each run created, updated or deleted"},
+	{"durable-writes", "DURABLE_WRITES", kindBool, "fsync output files and directories before reporting them written"},
+	{"output-layout", "OUTPUT_LAYOUT", kindString, "resource=template,... partitioned file layouts, e.g. tasks=dt={date}/{gid}"},
+	{"output-envelope", "OUTPUT_ENVELOPE", kindBool, "wrap entity files in a schema-versioned envelope"},
+	{"tasks-incremental", "TASKS_INCREMENTAL", kindBool, "fetch only tasks modified since the last successful run"},
+	{"task-fields", "TASK_FIELDS", kindString, "minimal, standard or full task fields"},
+	{"html-notes", "HTML_NOTES", kindString, "raw, sanitize or markdown html_notes"},
+	{"audit-log-events-lookback", "AUDIT_LOG_EVENTS_LOOKBACK", kindDuration, "how far back the first window of audit log events reaches"},
+	{"duckdb-path", "DUCKDB_PATH", kindString, "DuckDB database file written after every successful run"},
+	{"duckdb-binary", "DUCKDB_BINARY", kindString, "duckdb executable used for DUCKDB_PATH"},
+	{"xlsx-dir", "XLSX_DIR", kindString, "directory receiving an Excel workbook per successful run"},
+	{"xlsx-hook", "XLSX_HOOK", kindString, "shell command run on each workbook, e.g. to email or upload it"},
+	{"google-sheets-id", "GOOGLE_SHEETS_ID", kindString, "Google Sheet receiving users and projects after every successful run"},
+	{"google-sheets-credentials-file", "GOOGLE_SHEETS_CREDENTIALS_FILE", kindString, "service account key file for GOOGLE_SHEETS_ID"},
+	{"concurrency", "EXTRACTION_CONCURRENCY", kindInt, "projects whose tasks are fetched in parallel"},
+	{"resources", "EXTRACT_RESOURCES", kindString, "comma-separated resources to extract"},
+	{"write-batch-size", "WRITE_BATCH_SIZE", kindInt, "entities handed at once to backends that store batches"},
+	{"skip-archived-projects", "FILTER_SKIP_ARCHIVED_PROJECTS", kindBool, "do not store archived projects or their tasks"},
+	{"project-teams", "FILTER_PROJECT_TEAMS", kindString, "comma-separated teams (GIDs or names) whose projects are stored"},
+	{"user-email-domains", "FILTER_USER_EMAIL_DOMAINS", kindString, "comma-separated email domains of the users stored"},
+	{"max-error-rate", "MAX_ERROR_RATE", kindFloat, "fail runs above this error fraction (0 disables)"},
+	{"max-api-calls", "MAX_API_CALLS_PER_RUN", kindInt, "stop runs after this many API calls, marking them partial (0 disables)"},
+	{"verify-recount", "VERIFY_RECOUNT", kindBool, "recount users, teams and projects after a run"},
+	{"verify-sample", "VERIFY_SAMPLE_SIZE", kindInt, "random GIDs per resource spot-checked after a run"},
+	{"verify-threshold", "VERIFY_THRESHOLD", kindFloat, "divergence above which a snapshot is flagged suspect"},
+	{"preflight", "PREFLIGHT_ENABLED", kindBool, "estimate entities and API calls before a run"},
+	{"preflight-max-api-calls", "PREFLIGHT_MAX_API_CALLS", kindInt, "abort runs estimated to make more API calls than this"},
+	{"audit-log-dir", "AUDIT_LOG_DIR", kindString, "directory receiving a per-run API audit log"},
+	{"rpm", "REQUESTS_PER_MINUTE", kindInt, "Asana requests per minute"},
+	{"rate-burst", "RATE_BURST", kindInt, "requests that may be sent back to back"},
+	{"max-concurrent-read", "MAX_CONCURRENT_READ", kindInt, "simultaneous GET requests"},
+	{"max-concurrent-write", "MAX_CONCURRENT_WRITE", kindInt, "simultaneous POST/PUT/DELETE requests"},
+	{"http-cache", "HTTP_CACHE", kindString, "none, memory or disk ETag response cache"},
+	{"http-cache-dir", "HTTP_CACHE_DIR", kindString, "directory of the disk response cache"},
+	{"http-record", "HTTP_RECORD", kindString, "off, record or replay API responses"},
+	{"http-record-dir", "HTTP_RECORD_DIR", kindString, "directory of recorded API responses"},
+	{"http-compression", "HTTP_COMPRESSION", kindBool, "request gzip-compressed responses"},
+	{"http-hedge", "HTTP_HEDGE_REQUESTS", kindBool, "duplicate GETs slower than the P99 latency"},
+	{"http-timeout", "HTTP_TIMEOUT", kindDuration, "timeout for a single HTTP request"},
+	{"per-request-timeout", "PER_REQUEST_TIMEOUT", kindDuration, "retry attempts without response headers after this long"},
+	{"proxy-url", "HTTP_PROXY_URL", kindString, "proxy for Asana requests (overrides HTTP_PROXY/HTTPS_PROXY)"},
+	{"tls-ca-file", "TLS_CA_FILE", kindString, "PEM CA bundle trusted in addition to the system roots"},
+	{"tls-client-cert-file", "TLS_CLIENT_CERT_FILE", kindString, "client certificate for mutual TLS"},
+	{"tls-client-key-file", "TLS_CLIENT_KEY_FILE", kindString, "client key for mutual TLS"},
+	{"max-idle-conns-per-host", "HTTP_MAX_IDLE_CONNS_PER_HOST", kindInt, "kept-alive connections per host"},
+	{"dial-timeout", "HTTP_DIAL_TIMEOUT", kindDuration, "timeout for establishing connections"},
+	{"tls-handshake-timeout", "HTTP_TLS_HANDSHAKE_TIMEOUT", kindDuration, "timeout for TLS handshakes"},
+	{"max-idle-conns", "HTTP_MAX_IDLE_CONNS", kindInt, "kept-alive connections across all hosts"},
+	{"max-conns-per-host", "HTTP_MAX_CONNS_PER_HOST", kindInt, "connections per host, idle or not (0 means no limit)"},
+	{"idle-conn-timeout", "HTTP_IDLE_CONN_TIMEOUT", kindDuration, "close kept-alive connections idle for this long"},
+	{"force-http2", "HTTP_FORCE_HTTP2", kindBool, "attempt HTTP/2 (false keeps to HTTP/1.1)"},
+	{"base-url", "BASE_URL", kindString, "Asana API base URL"},
+	{"user-page-size", "USER_PAGE_SIZE", kindInt, "results per page for user queries"},
+	{"max-retries", "MAX_RETRIES", kindInt, "attempts per request before failing"},
+	{"initial-backoff", "INITIAL_BACKOFF", kindDuration, "first retry backoff"},
+	{"max-backoff", "MAX_BACKOFF", kindDuration, "maximum retry backoff"},
+	{"retry-budget", "RETRY_BUDGET_PER_MINUTE", kindInt, "retries allowed per minute across all requests (0 disables)"},
+	{"metrics-addr", "METRICS_ADDR", kindString, "serve expvar metrics on this address"},
+	{"api-addr", "API_ADDR", kindString, "address the api command serves the query API on"},
+	{"progress-interval", "PROGRESS_INTERVAL", kindDuration, "how often a running extraction logs its progress (0 disables)"},
+	{"notify-slack-webhook-url", "NOTIFY_SLACK_WEBHOOK_URL", kindString, "Slack webhook for failure notifications"},
+	{"notify-webhook-url", "NOTIFY_WEBHOOK_URL", kindString, "URL receiving failure notifications as JSON"},
+	{"notify-stale-after", "NOTIFY_STALE_AFTER", kindDuration, "alert when no run has succeeded for this long"},
+	{"tracing", "TRACING_ENABLED", kindBool, "export OpenTelemetry traces"},
+	{"service-name", "OTEL_SERVICE_NAME", kindString, "OpenTelemetry service name"},
+}
+
+// flagValue is a flag.Value recording a raw override after validating it
+type flagValue struct {
+	spec  flagSpec
+	value *string
+}
+
+func (v flagValue) String() string {
+	if v.value == nil {
+		return ""
+	}
+	return *v.value
+}
+
+func (v flagValue) Set(s string) error {
+	var err error
+	switch v.spec.kind {
+	case kindBool:
+		_, err = strconv.ParseBool(s)
+	case kindInt:
+		_, err = strconv.Atoi(s)
+	case kindFloat:
+		_, err = strconv.ParseFloat(s, 64)
+	case kindDuration:
+		_, err = time.ParseDuration(s)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid value for %s", v.spec.env)
+	}
+	*v.value = s
+	return nil
+}
+
+// IsBoolFlag lets boolean flags be given without a value (--snapshots)
+func (v flagValue) IsBoolFlag() bool {
+	return v.spec.kind == kindBool
+}
+
+// Flags registers a command-line flag for every configuration variable and
+// applies those that were set as the highest-precedence source
+type Flags struct {
+	fs         *flag.FlagSet
+	configFile string
+	values     map[string]*string
+}
+
+// NewFlags registers the configuration flags on fs, plus --config naming a
+// YAML configuration file
+func NewFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{fs: fs, values: make(map[string]*string)}
+
+	fs.StringVar(&f.configFile, "config", "", "YAML configuration file (overrides CONFIG_FILE)")
+
+	specs := flagSpecs
+	for _, resource := range SupportedResources {
+		specs = append(specs, flagSpec{
+			name:  "schedule-" + resource,
+			env:   "SCHEDULE_CRON_" + strings.ToUpper(resource),
+			usage: "cron expression for extracting " + resource + " on its own schedule",
+		})
+	}
+	for _, resource := range PagedResources() {
+		specs = append(specs, flagSpec{
+			name:  "page-size-" + strings.ReplaceAll(resource, "_", "-"),
+			env:   "PAGE_SIZE_" + strings.ToUpper(resource),
+			kind:  kindInt,
+			usage: "results per page for " + strings.ReplaceAll(resource, "_", " ") + " queries",
+		})
+	}
+
+	for _, spec := range specs {
+		value := new(string)
+		f.values[spec.env] = value
+		fs.Var(flagValue{spec: spec, value: value}, spec.name, spec.usage+" ("+spec.env+")")
+	}
+	return f
+}
+
+// Load loads the full configuration like Load, with set flags taking
+// precedence over the environment and the configuration file
+func (f *Flags) Load() (*Config, error) {
+	defer f.apply()()
+
+	if path := f.configPath(); path != "" {
+		return LoadFromFile(path)
+	}
+	return Load()
+}
+
+// LoadCredentials is LoadCredentials with set flags applied
+func (f *Flags) LoadCredentials() (*Config, error) {
+	defer f.apply()()
+	return LoadCredentials()
+}
+
+// configPath returns the configuration file from --config or CONFIG_FILE
+func (f *Flags) configPath() string {
+	if f.configFile != "" {
+		return f.configFile
+	}
+	return os.Getenv("CONFIG_FILE")
+}
+
+// apply installs the flags that were set as overrides and returns a
+// function removing them again
+func (f *Flags) apply() func() {
+	set := make(map[string]string)
+	f.fs.Visit(func(fl *flag.Flag) {
+		if v, ok := fl.Value.(flagValue); ok {
+			set[v.spec.env] = *v.value
+		}
+	})
+
+	flagValues = set
+	return func() { flagValues = nil }
+}
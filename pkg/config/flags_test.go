@@ -0,0 +1,111 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestFlags() (*flag.FlagSet, *Flags) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs, NewFlags(fs)
+}
+
+func TestFlags_Load(t *testing.T) {
+	for _, key := range []string{"ASANA_TOKEN", "ASANA_WORKSPACE", "REQUESTS_PER_MINUTE", "OUTPUT_DIR", "OUTPUT_LOCK", "EXTRACT_RESOURCES", "SCHEDULE_CRON_USERS", "JOB_TIMEOUT", "CONFIG_FILE"} {
+		t.Setenv(key, "")
+	}
+	t.Setenv("ASANA_TOKEN", "env-token")
+	t.Setenv("ASANA_WORKSPACE", "env-ws")
+	t.Setenv("REQUESTS_PER_MINUTE", "100")
+
+	fs, flags := newTestFlags()
+	err := fs.Parse([]string{
+		"--workspace", "flag-ws",
+		"--output-dir", "/tmp/out",
+		"--lock",
+		"--resources", "users,teams",
+		"--schedule-users", "0 0 * * * *",
+		"--page-size-status-updates", "20",
+		"--job-timeout", "5m",
+	})
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	cfg, err := flags.Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if cfg.AsanaWorkspace != "flag-ws" {
+		t.Errorf("Expected flag to override env, got %q", cfg.AsanaWorkspace)
+	}
+	if cfg.AsanaToken != "env-token" || cfg.RequestsPerMinute != 100 {
+		t.Errorf("Expected unset flags to leave env values, got token=%q rpm=%d", cfg.AsanaToken, cfg.RequestsPerMinute)
+	}
+	if cfg.OutputDirectory != "/tmp/out" || !cfg.OutputLock || cfg.JobTimeout != 5*time.Minute {
+		t.Errorf("Unexpected values: output=%q lock=%t timeout=%v", cfg.OutputDirectory, cfg.OutputLock, cfg.JobTimeout)
+	}
+	if !reflect.DeepEqual(cfg.ExtractResources, []string{"users", "teams"}) {
+		t.Errorf("Unexpected resources: %v", cfg.ExtractResources)
+	}
+	if cfg.ResourceSchedules["users"] != "0 0 * * * *" {
+		t.Errorf("Expected per-resource schedule flag, got %v", cfg.ResourceSchedules)
+	}
+	if cfg.PageSizes["status_updates"] != 20 {
+		t.Errorf("Expected per-resource page size flag, got %v", cfg.PageSizes)
+	}
+
+	// Overrides only last for the load they were applied to
+	if _, ok := flagValues["ASANA_WORKSPACE"]; ok {
+		t.Error("Expected flag overrides to be removed after loading")
+	}
+}
+
+func TestFlags_ConfigFile(t *testing.T) {
+	for _, key := range []string{"ASANA_TOKEN", "ASANA_WORKSPACE", "SCHEDULE_CRON", "EXTRACT_RESOURCES", "CONFIG_FILE"} {
+		t.Setenv(key, "")
+	}
+	path := writeConfigFile(t, "asana_token: file-token\nasana_workspace: file-ws\nschedule_cron: \"0 0 * * * *\"\n")
+
+	fs, flags := newTestFlags()
+	if err := fs.Parse([]string{"--config", path, "--schedule", "0 */5 * * * *"}); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	cfg, err := flags.Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.AsanaWorkspace != "file-ws" {
+		t.Errorf("Expected value from --config file, got %q", cfg.AsanaWorkspace)
+	}
+	if cfg.ScheduleCron != "0 */5 * * * *" {
+		t.Errorf("Expected flag to override file, got %q", cfg.ScheduleCron)
+	}
+}
+
+func TestFlags_InvalidValues(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "Invalid int", args: []string{"--rpm", "fast"}},
+		{name: "Invalid duration", args: []string{"--job-timeout", "10"}},
+		{name: "Invalid float", args: []string{"--max-error-rate", "high"}},
+		{name: "Invalid bool", args: []string{"--snapshots=maybe"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fs, _ := newTestFlags()
+			if err := fs.Parse(tc.args); err == nil {
+				t.Error("Expected parse error, got nil")
+			}
+		})
+	}
+}
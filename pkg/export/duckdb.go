@@ -0,0 +1,99 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// DuckDB materializes a run into a DuckDB database file with one table per
+// resource, replacing the previous file once the new one is complete. It
+// drives the duckdb CLI, so no database driver is linked in.
+type DuckDB struct {
+	// Path is the database file to write
+	Path string
+	// Binary is the duckdb executable; empty means "duckdb" from PATH
+	Binary string
+}
+
+// Name returns "duckdb"
+func (d DuckDB) Name() string {
+	return "duckdb"
+}
+
+// Export writes every resource as newline-delimited JSON to a temporary
+// directory and loads it into a fresh database, which is then renamed over
+// Path. Empty resources get no table. Every row carries the run ID in a
+// run_id column, and a run table records it too.
+func (d DuckDB) Export(ctx context.Context, src Source, runID string) error {
+	binary := d.Binary
+	if binary == "" {
+		binary = "duckdb"
+	}
+
+	staging, err := os.MkdirTemp(filepath.Dir(d.Path), ".duckdb-export-")
+	if err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	var sql strings.Builder
+	for _, r := range resources {
+		file := filepath.Join(staging, r.name+".jsonl")
+		n, err := writeJSONLines(file, func(fn func(any) error) error { return r.each(src, fn) })
+		if err != nil {
+			return fmt.Errorf("failed to export %s: %w", r.name, err)
+		}
+		if n == 0 {
+			continue
+		}
+		fmt.Fprintf(&sql, "CREATE TABLE %s AS SELECT *, %s AS run_id FROM read_json_auto(%s, format = 'newline_delimited');\n", r.name, sqlString(runID), sqlString(file))
+	}
+	fmt.Fprintf(&sql, "CREATE TABLE run AS SELECT %s AS run_id, current_timestamp AS exported_at;\n", sqlString(runID))
+
+	db := filepath.Join(staging, "export.duckdb")
+	cmd := exec.CommandContext(ctx, binary, db)
+	cmd.Stdin = strings.NewReader(sql.String())
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("duckdb failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	if err := os.Rename(db, d.Path); err != nil {
+		return fmt.Errorf("failed to publish %s: %w", d.Path, err)
+	}
+	return nil
+}
+
+// writeJSONLines writes every item walk yields to filename, one JSON
+// document per line, and returns how many it wrote
+func writeJSONLines(filename string, walk func(fn func(any) error) error) (int, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	n := 0
+	if err := walk(func(item any) error {
+		n++
+		return enc.Encode(item)
+	}); err != nil {
+		return n, err
+	}
+	if err := w.Flush(); err != nil {
+		return n, err
+	}
+	return n, f.Close()
+}
+
+// sqlString quotes s as an SQL string literal
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,110 @@
+package export
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// memorySource is a fixed Source
+type memorySource struct {
+	users    []asana.User
+	projects []asana.Project
+	tasks    []asana.Task
+	teams    []asana.Team
+}
+
+func (m memorySource) EachUser(fn func(asana.User) error) error       { return each(m.users, fn) }
+func (m memorySource) EachProject(fn func(asana.Project) error) error { return each(m.projects, fn) }
+func (m memorySource) EachTask(fn func(asana.Task) error) error       { return each(m.tasks, fn) }
+func (m memorySource) EachTeam(fn func(asana.Team) error) error       { return each(m.teams, fn) }
+
+func each[T any](items []T, fn func(T) error) error {
+	for _, item := range items {
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fakeDuckDB writes a duckdb stand-in that saves the SQL it receives, and
+// the users file it loads, next to the database it is asked to create
+func fakeDuckDB(t *testing.T, exitCode int) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake duckdb is a shell script")
+	}
+	dir := t.TempDir()
+	script := filepath.Join(dir, "duckdb")
+	body := `#!/bin/sh
+cat > "` + dir + `/input.sql"
+cp "$(dirname "$1")/users.jsonl" "` + dir + `/users.jsonl" 2>/dev/null
+echo db > "$1"
+exit ` + strconv.Itoa(exitCode) + "\n"
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+func TestDuckDB_Export(t *testing.T) {
+	src := memorySource{
+		users:    []asana.User{{GID: "u1", Name: "Ada"}, {GID: "u2", Name: "O'Brien"}},
+		projects: []asana.Project{{GID: "p1"}},
+	}
+
+	t.Run("Loads one table per non-empty resource", func(t *testing.T) {
+		binary := fakeDuckDB(t, 0)
+		out := filepath.Join(t.TempDir(), "asana.duckdb")
+
+		if err := (DuckDB{Path: out, Binary: binary}).Export(context.Background(), src, "run-1"); err != nil {
+			t.Fatalf("Export() failed: %v", err)
+		}
+
+		if data, err := os.ReadFile(out); err != nil || string(data) != "db\n" {
+			t.Fatalf("Expected the database at %s, got %q (%v)", out, data, err)
+		}
+		sql, _ := os.ReadFile(filepath.Join(filepath.Dir(binary), "input.sql"))
+		for _, want := range []string{"CREATE TABLE users AS", "CREATE TABLE projects AS", "SELECT 'run-1' AS run_id"} {
+			if !strings.Contains(string(sql), want) {
+				t.Errorf("Expected SQL to contain %q, got:\n%s", want, sql)
+			}
+		}
+		if strings.Contains(string(sql), "CREATE TABLE tasks") {
+			t.Errorf("Expected no table for empty tasks, got:\n%s", sql)
+		}
+		users, _ := os.ReadFile(filepath.Join(filepath.Dir(binary), "users.jsonl"))
+		if lines := strings.Split(strings.TrimSpace(string(users)), "\n"); len(lines) != 2 || !strings.Contains(lines[1], "O'Brien") {
+			t.Errorf("Expected 2 users as JSON lines, got %q", users)
+		}
+		if entries, _ := os.ReadDir(filepath.Dir(out)); len(entries) != 1 {
+			t.Errorf("Expected only the database to remain, got %v", entries)
+		}
+	})
+
+	t.Run("Failure keeps the previous database", func(t *testing.T) {
+		binary := fakeDuckDB(t, 1)
+		out := filepath.Join(t.TempDir(), "asana.duckdb")
+		os.WriteFile(out, []byte("previous"), 0644)
+
+		if err := (DuckDB{Path: out, Binary: binary}).Export(context.Background(), src, "run-2"); err == nil {
+			t.Fatal("Expected an error when duckdb fails")
+		}
+		if data, _ := os.ReadFile(out); string(data) != "previous" {
+			t.Errorf("Expected the previous database to be kept, got %q", data)
+		}
+	})
+}
+
+func TestSQLString(t *testing.T) {
+	if got := sqlString("/tmp/o'brien/users.jsonl"); got != "'/tmp/o''brien/users.jsonl'" {
+		t.Errorf("sqlString() = %s", got)
+	}
+}
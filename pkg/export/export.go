@@ -0,0 +1,46 @@
+// Package export materializes finished runs into other formats, such as a
+// DuckDB database, an Excel workbook or a Google Sheet, after they have been
+// written to storage.
+package export
+
+import (
+	"context"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// Source is the stored output of a run; implemented by
+// *storage.JSONStorage
+type Source interface {
+	EachUser(fn func(asana.User) error) error
+	EachProject(fn func(asana.Project) error) error
+	EachTask(fn func(asana.Task) error) error
+	EachTeam(fn func(asana.Team) error) error
+}
+
+// Exporter materializes the output of a finished run
+type Exporter interface {
+	// Name identifies the exporter in logs
+	Name() string
+	Export(ctx context.Context, src Source, runID string) error
+}
+
+// resources lists the resources in export order, with the function walking
+// each one in a Source
+var resources = []struct {
+	name string
+	each func(src Source, fn func(any) error) error
+}{
+	{"users", func(src Source, fn func(any) error) error {
+		return src.EachUser(func(u asana.User) error { return fn(u) })
+	}},
+	{"projects", func(src Source, fn func(any) error) error {
+		return src.EachProject(func(p asana.Project) error { return fn(p) })
+	}},
+	{"tasks", func(src Source, fn func(any) error) error {
+		return src.EachTask(func(t asana.Task) error { return fn(t) })
+	}},
+	{"teams", func(src Source, fn func(any) error) error {
+		return src.EachTeam(func(t asana.Team) error { return fn(t) })
+	}},
+}
@@ -0,0 +1,278 @@
+package export
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// sheetsScope is the OAuth scope granting access to spreadsheets
+const sheetsScope = "https://www.googleapis.com/auth/spreadsheets"
+
+// GoogleSheets replaces the Users and Projects tabs of a Google Sheet with
+// the run's users and projects, for teams that use the sheet as a
+// lightweight directory. It authenticates as a service account, which
+// needs edit access to the sheet. Missing tabs are added; other tabs are
+// left alone.
+type GoogleSheets struct {
+	// SpreadsheetID is the ID in the sheet's URL
+	SpreadsheetID string
+	// CredentialsFile is the service account's JSON key file
+	CredentialsFile string
+
+	// baseURL overrides the Sheets API endpoint in tests
+	baseURL string
+}
+
+// Name returns "google-sheets"
+func (g GoogleSheets) Name() string {
+	return "google-sheets"
+}
+
+// serviceAccount holds the fields of a service account key file that are
+// needed to obtain access tokens
+type serviceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// Export clears the report tabs and writes a header row and a row per
+// entity to each
+func (g GoogleSheets) Export(ctx context.Context, src Source, runID string) error {
+	client := &http.Client{Timeout: time.Minute}
+	token, err := g.accessToken(ctx, client)
+	if err != nil {
+		return err
+	}
+	api := sheetsAPI{
+		base:   strings.TrimSuffix(cmp.Or(g.baseURL, "https://sheets.googleapis.com"), "/") + "/v4/spreadsheets/" + url.PathEscape(g.SpreadsheetID),
+		token:  token,
+		client: client,
+	}
+
+	var tabs []string
+	var data []map[string]any
+	for _, s := range reportSheets {
+		if s.name != "Users" && s.name != "Projects" {
+			continue
+		}
+		rows := [][]any{toAny(s.columns)}
+		if err := s.rows(src, func(row []any) error {
+			rows = append(rows, sheetRow(row))
+			return nil
+		}); err != nil {
+			return fmt.Errorf("failed to read %s: %w", s.name, err)
+		}
+		tabs = append(tabs, s.name)
+		data = append(data, map[string]any{"range": s.name, "majorDimension": "ROWS", "values": rows})
+	}
+
+	if err := api.addMissingTabs(ctx, tabs); err != nil {
+		return err
+	}
+	if err := api.call(ctx, http.MethodPost, "/values:batchClear", map[string]any{"ranges": tabs}, nil); err != nil {
+		return fmt.Errorf("failed to clear sheet: %w", err)
+	}
+	if err := api.call(ctx, http.MethodPost, "/values:batchUpdate", map[string]any{"valueInputOption": "RAW", "data": data}, nil); err != nil {
+		return fmt.Errorf("failed to write sheet: %w", err)
+	}
+	return nil
+}
+
+// sheetRow converts a report row to cell values: times become UTC text,
+// everything else is written as is
+func sheetRow(row []any) []any {
+	out := make([]any, len(row))
+	for i, v := range row {
+		if t, ok := v.(time.Time); ok {
+			if t.IsZero() {
+				v = ""
+			} else {
+				v = t.UTC().Format(time.DateTime)
+			}
+		}
+		out[i] = v
+	}
+	return out
+}
+
+// toAny converts strings to a row of cells
+func toAny(values []string) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
+
+// accessToken exchanges a JWT signed with the service account's key for
+// an access token, following Google's two-legged OAuth flow
+func (g GoogleSheets) accessToken(ctx context.Context, client *http.Client) (string, error) {
+	data, err := os.ReadFile(g.CredentialsFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read credentials: %w", err)
+	}
+	var sa serviceAccount
+	if err := json.Unmarshal(data, &sa); err != nil {
+		return "", fmt.Errorf("failed to parse credentials: %w", err)
+	}
+	if sa.ClientEmail == "" || sa.PrivateKey == "" || sa.TokenURI == "" {
+		return "", fmt.Errorf("credentials are not a service account key")
+	}
+
+	assertion, err := signJWT(sa, time.Now())
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sa.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get access token: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get access token: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var out struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil || out.AccessToken == "" {
+		return "", fmt.Errorf("token response has no access token")
+	}
+	return out.AccessToken, nil
+}
+
+// signJWT builds the RS256-signed assertion for a token request
+func signJWT(sa serviceAccount, now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(sa.PrivateKey))
+	if block == nil {
+		return "", fmt.Errorf("service account private key is not PEM")
+	}
+	var key *rsa.PrivateKey
+	if parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rsaKey, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return "", fmt.Errorf("service account private key is not RSA")
+		}
+		key = rsaKey
+	} else if key, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+		return "", fmt.Errorf("failed to parse service account private key: %w", err)
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{
+		"iss":   sa.ClientEmail,
+		"scope": sheetsScope,
+		"aud":   sa.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	enc := base64.RawURLEncoding
+	signed := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(nil, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token request: %w", err)
+	}
+	return signed + "." + enc.EncodeToString(sig), nil
+}
+
+// sheetsAPI calls the Sheets API for one spreadsheet
+type sheetsAPI struct {
+	base   string
+	token  string
+	client *http.Client
+}
+
+// addMissingTabs adds the tabs the spreadsheet does not have yet
+func (a sheetsAPI) addMissingTabs(ctx context.Context, tabs []string) error {
+	var meta struct {
+		Sheets []struct {
+			Properties struct {
+				Title string `json:"title"`
+			} `json:"properties"`
+		} `json:"sheets"`
+	}
+	if err := a.call(ctx, http.MethodGet, "?fields=sheets.properties.title", nil, &meta); err != nil {
+		return fmt.Errorf("failed to read spreadsheet: %w", err)
+	}
+	existing := make(map[string]bool)
+	for _, s := range meta.Sheets {
+		existing[s.Properties.Title] = true
+	}
+
+	var requests []map[string]any
+	for _, tab := range tabs {
+		if !existing[tab] {
+			requests = append(requests, map[string]any{"addSheet": map[string]any{"properties": map[string]string{"title": tab}}})
+		}
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+	if err := a.call(ctx, http.MethodPost, ":batchUpdate", map[string]any{"requests": requests}, nil); err != nil {
+		return fmt.Errorf("failed to add tabs: %w", err)
+	}
+	return nil
+}
+
+// call sends body as JSON to the spreadsheet's path and decodes the
+// response into out, if given
+func (a sheetsAPI) call(ctx context.Context, method, path string, body, out any) error {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.base+path, r)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,183 @@
+package export
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// fakeGoogle serves the token endpoint and the Sheets API for one
+// spreadsheet, verifying the service account's signed assertions
+type fakeGoogle struct {
+	t   *testing.T
+	key *rsa.PublicKey
+
+	mu      sync.Mutex
+	tabs    []string
+	cleared []string
+	values  map[string][][]any
+}
+
+func (f *fakeGoogle) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == "/token" {
+		r.ParseForm()
+		parts := strings.Split(r.Form.Get("assertion"), ".")
+		if len(parts) != 3 {
+			http.Error(w, "bad assertion", http.StatusBadRequest)
+			return
+		}
+		sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(f.key, crypto.SHA256, digest[:], sig); err != nil {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"access_token":"tok","expires_in":3600}`))
+		return
+	}
+
+	if r.Header.Get("Authorization") != "Bearer tok" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	switch strings.TrimPrefix(r.URL.Path, "/v4/spreadsheets/sheet-1") {
+	case "":
+		var meta struct {
+			Sheets []map[string]map[string]string `json:"sheets"`
+		}
+		for _, tab := range f.tabs {
+			meta.Sheets = append(meta.Sheets, map[string]map[string]string{"properties": {"title": tab}})
+		}
+		json.NewEncoder(w).Encode(meta)
+	case ":batchUpdate":
+		var body struct {
+			Requests []struct {
+				AddSheet struct {
+					Properties struct {
+						Title string `json:"title"`
+					} `json:"properties"`
+				} `json:"addSheet"`
+			} `json:"requests"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		for _, req := range body.Requests {
+			f.tabs = append(f.tabs, req.AddSheet.Properties.Title)
+		}
+		w.Write([]byte(`{}`))
+	case "/values:batchClear":
+		var body struct {
+			Ranges []string `json:"ranges"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		f.cleared = body.Ranges
+		w.Write([]byte(`{}`))
+	case "/values:batchUpdate":
+		var body struct {
+			ValueInputOption string `json:"valueInputOption"`
+			Data             []struct {
+				Range  string  `json:"range"`
+				Values [][]any `json:"values"`
+			} `json:"data"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.ValueInputOption != "RAW" {
+			f.t.Errorf("valueInputOption = %q, want RAW", body.ValueInputOption)
+		}
+		for _, d := range body.Data {
+			f.values[d.Range] = d.Values
+		}
+		w.Write([]byte(`{}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// writeServiceAccount writes a service account key file using tokenURI
+func writeServiceAccount(t *testing.T, key *rsa.PrivateKey, tokenURI string) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, _ := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "extractor@project.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    tokenURI,
+	})
+	path := filepath.Join(t.TempDir(), "sa.json")
+	if err := os.WriteFile(path, creds, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGoogleSheets_Export(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	google := &fakeGoogle{t: t, key: &key.PublicKey, tabs: []string{"Users", "Notes"}, values: make(map[string][][]any)}
+	srv := httptest.NewServer(google)
+	defer srv.Close()
+
+	g := GoogleSheets{
+		SpreadsheetID:   "sheet-1",
+		CredentialsFile: writeServiceAccount(t, key, srv.URL+"/token"),
+		baseURL:         srv.URL,
+	}
+	src := memorySource{
+		users:    []asana.User{{GID: "u1", Name: "Ada", Email: "ada@example.com"}},
+		projects: []asana.Project{{GID: "p1", Name: "Budget", Archived: true, Team: &asana.Team{Name: "Finance"}}},
+		teams:    []asana.Team{{GID: "t1"}},
+	}
+	if err := g.Export(context.Background(), src, "run-1"); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if strings.Join(google.tabs, ",") != "Users,Notes,Projects" {
+		t.Errorf("Expected the Projects tab to be added, got %v", google.tabs)
+	}
+	if strings.Join(google.cleared, ",") != "Users,Projects" {
+		t.Errorf("Expected only the report tabs to be cleared, got %v", google.cleared)
+	}
+	users := google.values["Users"]
+	if len(users) != 2 || users[0][0] != "GID" || users[1][2] != "ada@example.com" {
+		t.Errorf("Unexpected Users values %v", users)
+	}
+	projects := google.values["Projects"]
+	if len(projects) != 2 || projects[1][2] != "Finance" || projects[1][4] != true || projects[1][6] != "" {
+		t.Errorf("Unexpected Projects values %v", projects)
+	}
+	if _, ok := google.values["Teams"]; ok {
+		t.Error("Expected teams not to be exported")
+	}
+}
+
+func TestGoogleSheets_ExportBadCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sa.json")
+	os.WriteFile(path, []byte(`{"type":"authorized_user","client_id":"x"}`), 0600)
+
+	g := GoogleSheets{SpreadsheetID: "sheet-1", CredentialsFile: path}
+	if err := g.Export(context.Background(), memorySource{}, "run-1"); err == nil || !strings.Contains(err.Error(), "service account") {
+		t.Errorf("Expected an error for non-service-account credentials, got %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// XLSX writes a run as an Excel workbook, <Dir>/asana-<run_id>.xlsx, with
+// Users, Projects and Teams sheets for readers without a JSON toolchain.
+// The workbook is written by hand as Office Open XML, so no spreadsheet
+// library is linked in.
+type XLSX struct {
+	// Dir is the directory workbooks are written to
+	Dir string
+	// Hook is a shell command run after each workbook is written, for
+	// instance to email or upload it. It gets the workbook's path in
+	// XLSX_PATH and the run ID in RUN_ID. Empty runs nothing.
+	Hook string
+}
+
+// Name returns "xlsx"
+func (x XLSX) Name() string {
+	return "xlsx"
+}
+
+// reportSheet is one sheet of a spreadsheet report: a header row of columns
+// and a row per entity
+type reportSheet struct {
+	name    string
+	columns []string
+	rows    func(src Source, fn func(row []any) error) error
+}
+
+// reportSheets lists the sheets of spreadsheet reports in order
+var reportSheets = []reportSheet{
+	{"Users", []string{"GID", "Name", "Email"}, func(src Source, fn func([]any) error) error {
+		return src.EachUser(func(u asana.User) error {
+			return fn([]any{u.GID, u.Name, u.Email})
+		})
+	}},
+	{"Projects", []string{"GID", "Name", "Team", "Owner", "Archived", "Public", "Created", "Modified"}, func(src Source, fn func([]any) error) error {
+		return src.EachProject(func(p asana.Project) error {
+			var team, owner string
+			if p.Team != nil {
+				team = p.Team.Name
+			}
+			if p.Owner != nil {
+				owner = p.Owner.Name
+			}
+			return fn([]any{p.GID, p.Name, team, owner, p.Archived, p.Public, p.CreatedAt, p.ModifiedAt})
+		})
+	}},
+	{"Teams", []string{"GID", "Name"}, func(src Source, fn func([]any) error) error {
+		return src.EachTeam(func(t asana.Team) error {
+			return fn([]any{t.GID, t.Name})
+		})
+	}},
+}
+
+// Export writes the workbook of the run, renaming it into place once
+// complete, and then runs the hook
+func (x XLSX) Export(ctx context.Context, src Source, runID string) error {
+	path := filepath.Join(x.Dir, "asana-"+runID+".xlsx")
+	if err := os.MkdirAll(x.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create workbook directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(x.Dir, ".xlsx-export-")
+	if err != nil {
+		return fmt.Errorf("failed to create workbook: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	err = writeWorkbook(tmp, src)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write workbook: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to publish %s: %w", path, err)
+	}
+
+	if x.Hook == "" {
+		return nil
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", x.Hook)
+	cmd.Env = append(os.Environ(), "XLSX_PATH="+path, "RUN_ID="+runID)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("xlsx hook failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// writeWorkbook writes the workbook package to w
+func writeWorkbook(w io.Writer, src Source) error {
+	z := zip.NewWriter(w)
+
+	var sheets, rels, types strings.Builder
+	for i, s := range reportSheets {
+		n := strconv.Itoa(i + 1)
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%s" r:id="rId%s"/>`, s.name, n, n)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%s" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%s.xml"/>`, n, n)
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%s.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+	}
+	stylesID := strconv.Itoa(len(reportSheets) + 1)
+
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			types.String() + `</Types>`},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + sheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + rels.String() +
+			`<Relationship Id="rId` + stylesID + `" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+			`</Relationships>`},
+		// Style 1 is the bold header row
+		{"xl/styles.xml", `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+			`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+			`<borders count="1"><border/></borders>` +
+			`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+			`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+			`</styleSheet>`},
+	}
+	for _, p := range parts {
+		f, err := z.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, xml.Header+p.body); err != nil {
+			return err
+		}
+	}
+
+	for i, s := range reportSheets {
+		f, err := z.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1))
+		if err != nil {
+			return err
+		}
+		if err := writeSheet(f, src, s); err != nil {
+			return fmt.Errorf("failed to write %s sheet: %w", s.name, err)
+		}
+	}
+	return z.Close()
+}
+
+// writeSheet writes the worksheet XML of s, with the header row frozen
+func writeSheet(w io.Writer, src Source, s reportSheet) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(xml.Header)
+	bw.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	bw.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	bw.WriteString(`<sheetData>`)
+
+	header := make([]any, len(s.columns))
+	for i, c := range s.columns {
+		header[i] = c
+	}
+	writeRow(bw, 1, header, ` s="1"`)
+
+	n := 1
+	if err := s.rows(src, func(row []any) error {
+		n++
+		writeRow(bw, n, row, "")
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	bw.WriteString(`</sheetData></worksheet>`)
+	return bw.Flush()
+}
+
+// writeRow writes row number n. Strings are inline, booleans native and
+// times UTC text, so no shared string table or date styles are needed.
+func writeRow(w *bufio.Writer, n int, row []any, style string) {
+	fmt.Fprintf(w, `<row r="%d">`, n)
+	for i, v := range row {
+		ref := columnName(i) + strconv.Itoa(n)
+		switch v := v.(type) {
+		case bool:
+			b := 0
+			if v {
+				b = 1
+			}
+			fmt.Fprintf(w, `<c r="%s" t="b"%s><v>%d</v></c>`, ref, style, b)
+		case time.Time:
+			if !v.IsZero() {
+				fmt.Fprintf(w, `<c r="%s" t="inlineStr"%s><is><t>%s</t></is></c>`, ref, style, v.UTC().Format(time.DateTime))
+			}
+		case string:
+			if v != "" {
+				fmt.Fprintf(w, `<c r="%s" t="inlineStr"%s><is><t xml:space="preserve">`, ref, style)
+				xml.EscapeText(w, []byte(v))
+				w.WriteString(`</t></is></c>`)
+			}
+		}
+	}
+	w.WriteString(`</row>`)
+}
+
+// columnName returns the spreadsheet name of the zero-based column i:
+// A to Z, then AA and on
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
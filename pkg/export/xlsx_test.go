@@ -0,0 +1,125 @@
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// readZipFile returns the contents of name in the zip archive at path
+func readZipFile(t *testing.T, path, name string) string {
+	t.Helper()
+	z, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer z.Close()
+
+	f, err := z.Open(name)
+	if err != nil {
+		t.Fatalf("Workbook has no %s: %v", name, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestXLSX_Export(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook runs through sh")
+	}
+	dir := t.TempDir()
+	src := memorySource{
+		users: []asana.User{{GID: "u1", Name: "Ada & <Co>", Email: "ada@example.com"}},
+		projects: []asana.Project{{
+			GID:       "p1",
+			Name:      "Budget",
+			Archived:  true,
+			CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			Team:      &asana.Team{GID: "t1", Name: "Finance"},
+		}},
+		tasks: []asana.Task{{GID: "task1"}},
+	}
+	x := XLSX{Dir: dir, Hook: `echo "$RUN_ID $XLSX_PATH" > "` + dir + `/hook.out"`}
+
+	if err := x.Export(context.Background(), src, "run-1"); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	path := filepath.Join(dir, "asana-run-1.xlsx")
+
+	workbook := readZipFile(t, path, "xl/workbook.xml")
+	for _, name := range []string{`name="Users"`, `name="Projects"`, `name="Teams"`} {
+		if !strings.Contains(workbook, name) {
+			t.Errorf("Expected sheet %s in %s", name, workbook)
+		}
+	}
+
+	users := readZipFile(t, path, "xl/worksheets/sheet1.xml")
+	if !strings.Contains(users, `<c r="A1" t="inlineStr" s="1"><is><t xml:space="preserve">GID</t>`) {
+		t.Errorf("Expected a bold GID header, got %s", users)
+	}
+	if !strings.Contains(users, "Ada &amp; &lt;Co&gt;") || !strings.Contains(users, "ada@example.com") {
+		t.Errorf("Expected the escaped user row, got %s", users)
+	}
+
+	projects := readZipFile(t, path, "xl/worksheets/sheet2.xml")
+	for _, want := range []string{"Finance", `<c r="E2" t="b"><v>1</v></c>`, "2024-01-02 03:04:05"} {
+		if !strings.Contains(projects, want) {
+			t.Errorf("Expected %q in the projects sheet, got %s", want, projects)
+		}
+	}
+
+	out, err := os.ReadFile(filepath.Join(dir, "hook.out"))
+	if err != nil {
+		t.Fatalf("Hook did not run: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "run-1 "+path {
+		t.Errorf("Hook got %q, want run ID and workbook path", got)
+	}
+}
+
+func TestXLSX_ExportHookFails(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook runs through sh")
+	}
+	dir := t.TempDir()
+	x := XLSX{Dir: dir, Hook: "echo upload refused; exit 3"}
+
+	err := x.Export(context.Background(), memorySource{}, "run-1")
+	if err == nil || !strings.Contains(err.Error(), "upload refused") {
+		t.Errorf("Expected the hook's output in the error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "asana-run-1.xlsx")); err != nil {
+		t.Errorf("Expected the workbook to be kept when the hook fails: %v", err)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	tests := []struct {
+		i    int
+		want string
+	}{
+		{0, "A"},
+		{25, "Z"},
+		{26, "AA"},
+		{27, "AB"},
+		{701, "ZZ"},
+		{702, "AAA"},
+	}
+	for _, tc := range tests {
+		if got := columnName(tc.i); got != tc.want {
+			t.Errorf("columnName(%d) = %q, want %q", tc.i, got, tc.want)
+		}
+	}
+}
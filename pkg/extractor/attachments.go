@@ -0,0 +1,117 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// AttachmentClient is implemented by Asana clients that can list the
+// attachments of a task and download their files
+type AttachmentClient interface {
+	StreamAttachments(ctx context.Context, parentGID string, fn func(asana.Attachment) error) error
+	DownloadAttachment(ctx context.Context, downloadURL string) (io.ReadCloser, error)
+}
+
+// AttachmentWriter is implemented by storage backends that can store
+// attachment files. Asana never changes the file of an attachment, so one
+// already stored is not downloaded again.
+type AttachmentWriter interface {
+	AttachmentStored(gid string) bool
+	WriteAttachment(attachment asana.Attachment, file io.Reader) error
+}
+
+// taskGIDs collects the GIDs of the tasks walked by concurrent workers.
+// A nil *taskGIDs collects nothing.
+type taskGIDs struct {
+	mu   sync.Mutex
+	gids []string
+}
+
+func (t *taskGIDs) add(gid string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.gids = append(t.gids, gid)
+}
+
+func (t *taskGIDs) list() []string {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.gids
+}
+
+// extractAttachments stores the files attached to every task walked,
+// listing Config.Concurrency tasks in parallel. Attachments hosted outside
+// Asana, such as Google Drive links, have no file to download and are
+// skipped.
+func (e *Extractor) extractAttachments(ctx context.Context, results chan<- func(*Stats), gids []string) error {
+	ctx, span := tracer.Start(ctx, "extractor.attachments")
+	defer span.End()
+
+	client, canRead := e.asanaClient.(AttachmentClient)
+	writer, canWrite := e.storage.(AttachmentWriter)
+	if !canRead || !canWrite {
+		return fmt.Errorf("attachments are not supported by this client or storage backend")
+	}
+
+	return e.fanOut(ctx, gids, func(ctx context.Context, taskGID string) error {
+		err := client.StreamAttachments(ctx, taskGID, func(attachment asana.Attachment) error {
+			if attachment.DownloadURL == "" {
+				return nil
+			}
+			if attachment.Parent == nil {
+				attachment.Parent = &asana.ParentRef{GID: taskGID, ResourceType: "task"}
+			}
+			if writer.AttachmentStored(attachment.GID) {
+				results <- func(s *Stats) { s.AttachmentsExtracted++ }
+				return nil
+			}
+
+			// A failed download only loses this attachment, like avatars
+			file, err := client.DownloadAttachment(ctx, attachment.DownloadURL)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				logf(ctx, "Error downloading attachment %s of task %s: %v", attachment.GID, taskGID, err)
+				results <- func(s *Stats) { s.recordError(ResourceAttachments) }
+				return nil
+			}
+			// The file is streamed into storage, so a failed read surfaces
+			// as a failed write
+			err = writer.WriteAttachment(attachment, file)
+			file.Close()
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				logf(ctx, "Error writing attachment %s: %v", attachment.GID, err)
+				results <- func(s *Stats) { s.recordError(ResourceAttachments) }
+				return nil
+			}
+			results <- func(s *Stats) { s.AttachmentsExtracted++ }
+			return nil
+		})
+		// A task deleted or made private since it was listed only loses
+		// its own attachments
+		if errors.Is(err, asana.ErrNotFound) || errors.Is(err, asana.ErrForbidden) {
+			logf(ctx, "Skipping attachments of task %s: %v", taskGID, err)
+			results <- func(s *Stats) { s.recordError(ResourceAttachments); s.partial = true }
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("attachment API failure for task %s: %w", taskGID, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,127 @@
+package extractor
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// attachmentClient lists attachments per task and serves their files by
+// URL; other URLs fail like expired ones
+type attachmentClient struct {
+	mockAsanaClient
+	attachments map[string][]asana.Attachment
+	files       map[string][]byte
+	downloads   atomic.Int32
+}
+
+func (m *attachmentClient) StreamAttachments(ctx context.Context, parentGID string, fn func(asana.Attachment) error) error {
+	if parentGID == "gone" {
+		return asana.ErrNotFound
+	}
+	for _, a := range m.attachments[parentGID] {
+		if err := fn(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *attachmentClient) DownloadAttachment(ctx context.Context, downloadURL string) (io.ReadCloser, error) {
+	m.downloads.Add(1)
+	data, ok := m.files[downloadURL]
+	if !ok {
+		return nil, asana.ErrForbidden
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// attachmentStorage keeps attachment files by GID
+type attachmentStorage struct {
+	mockStorage
+	attachmentMu sync.Mutex
+	files        map[string][]byte
+	tasks        map[string]string
+}
+
+func (m *attachmentStorage) AttachmentStored(gid string) bool {
+	m.attachmentMu.Lock()
+	defer m.attachmentMu.Unlock()
+	_, ok := m.files[gid]
+	return ok
+}
+
+func (m *attachmentStorage) WriteAttachment(attachment asana.Attachment, file io.Reader) error {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	m.attachmentMu.Lock()
+	defer m.attachmentMu.Unlock()
+	m.files[attachment.GID] = data
+	m.tasks[attachment.GID] = attachment.Parent.GID
+	return nil
+}
+
+func TestExtractor_Attachments(t *testing.T) {
+	client := &attachmentClient{
+		mockAsanaClient: mockAsanaClient{
+			projects: []asana.Project{{GID: "p1"}},
+			tasks:    map[string][]asana.Task{"p1": {{GID: "t1"}, {GID: "t2"}, {GID: "gone"}}},
+		},
+		attachments: map[string][]asana.Attachment{
+			"t1": {
+				{GID: "a1", DownloadURL: "https://files/a1"},
+				{GID: "a2", Host: "gdrive"},
+			},
+			"t2": {
+				{GID: "a3", DownloadURL: "https://files/a3"},
+				{GID: "a4", DownloadURL: "https://files/expired"},
+			},
+		},
+		files: map[string][]byte{
+			"https://files/a1": []byte("spec"),
+			"https://files/a3": []byte("spec"),
+		},
+	}
+	store := &attachmentStorage{files: make(map[string][]byte), tasks: make(map[string]string)}
+
+	cfg := Config{Resources: []string{ResourceAttachments}, Concurrency: 2}
+	stats, err := New(client, store, cfg).Extract(context.Background())
+	if err != nil {
+		t.Fatalf("Extract() failed: %v", err)
+	}
+
+	if stats.AttachmentsExtracted != 2 || string(store.files["a1"]) != "spec" || store.tasks["a3"] != "t2" {
+		t.Fatalf("Expected the two hosted files with their task, got %d: %v %v", stats.AttachmentsExtracted, store.files, store.tasks)
+	}
+	if r := stats.Resources[ResourceAttachments]; r == nil || r.Errors != 2 {
+		t.Errorf("Expected the failed download and the missing task to count as errors, got %+v", r)
+	}
+	if stats.TasksExtracted != 0 || len(store.mockStorage.tasks) != 0 {
+		t.Error("Expected tasks to be walked without being written")
+	}
+
+	// A second run downloads nothing new
+	client.downloads.Store(0)
+	stats, err = New(client, store, cfg).Extract(context.Background())
+	if err != nil {
+		t.Fatalf("Extract() failed: %v", err)
+	}
+	if n := client.downloads.Load(); n != 1 || stats.AttachmentsExtracted != 2 {
+		t.Errorf("Expected only the failed attachment to be downloaded again, got %d downloads", n)
+	}
+}
+
+func TestExtractor_AttachmentsUnsupported(t *testing.T) {
+	client := &attachmentClient{mockAsanaClient: mockAsanaClient{projects: []asana.Project{{GID: "p1"}}}}
+	cfg := Config{Resources: []string{ResourceAttachments}}
+	if _, err := New(client, &mockStorage{}, cfg).Extract(context.Background()); err == nil {
+		t.Error("Expected an error when storage cannot write attachments")
+	}
+}
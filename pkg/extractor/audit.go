@@ -0,0 +1,25 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ioanzicu/asana-extractor/pkg/client"
+)
+
+// openAuditLog creates <dir>/<runID>.jsonl and attaches it to ctx, so that
+// every API call of the run is recorded. The returned close func flushes it.
+func openAuditLog(ctx context.Context, dir, runID string) (context.Context, func() error, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return ctx, nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, runID+".jsonl"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return ctx, nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	return client.WithAuditor(ctx, client.NewAuditLog(f)), f.Close, nil
+}
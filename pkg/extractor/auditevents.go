@@ -0,0 +1,102 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// DefaultAuditEventLookback is how far back the first window of audit log
+// events reaches when Config.AuditEventLookback is not set
+const DefaultAuditEventLookback = 24 * time.Hour
+
+// auditEventBatch is the number of audit log events handed to storage at once
+const auditEventBatch = 100
+
+// AuditEventClient is implemented by Asana clients that can read the audit
+// log of an Enterprise organization
+type AuditEventClient interface {
+	StreamAuditLogEvents(ctx context.Context, start, end time.Time, fn func(asana.AuditLogEvent) error) error
+}
+
+// AuditEventWriter is implemented by storage backends that can store audit
+// log events. Each call appends a batch to the log of the run.
+type AuditEventWriter interface {
+	WriteAuditLogEvents(runID string, events []asana.AuditLogEvent) error
+}
+
+// auditWindow is the span of audit log events a run fetches: created at or
+// after start and before end
+type auditWindow struct {
+	runID      string
+	start, end time.Time
+}
+
+// nextAuditWindow returns the window of a run starting at now. It picks up
+// where the previous successful run ended, or reaches back by
+// Config.AuditEventLookback.
+func (e *Extractor) nextAuditWindow(runID string, now, prevEnd time.Time) auditWindow {
+	w := auditWindow{runID: runID, start: prevEnd, end: now}
+	if w.start.IsZero() || !w.start.Before(now) {
+		lookback := e.cfg.AuditEventLookback
+		if lookback <= 0 {
+			lookback = DefaultAuditEventLookback
+		}
+		w.start = now.Add(-lookback)
+	}
+	return w
+}
+
+// extractAuditEvents stores the audit log events of the window in batches.
+// In a run whose events were all stored the window end becomes the start
+// of the next window.
+func (e *Extractor) extractAuditEvents(ctx context.Context, results chan<- func(*Stats), w auditWindow) error {
+	ctx, span := tracer.Start(ctx, "extractor.audit_log_events")
+	defer span.End()
+
+	client, canRead := e.asanaClient.(AuditEventClient)
+	writer, canWrite := e.storage.(AuditEventWriter)
+	if !canRead || !canWrite {
+		return fmt.Errorf("audit log events are not supported by this client or storage backend")
+	}
+
+	var batch []asana.AuditLogEvent
+	failed := false
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		n := len(batch)
+		if err := writer.WriteAuditLogEvents(w.runID, batch); err != nil {
+			logf(ctx, "Error writing %d audit log events: %v", n, err)
+			failed = true
+			results <- func(s *Stats) {
+				for range n {
+					s.recordError(ResourceAuditLogEvents)
+				}
+			}
+		} else {
+			results <- func(s *Stats) { s.AuditLogEventsExtracted += n }
+		}
+		batch = nil
+	}
+
+	err := client.StreamAuditLogEvents(ctx, w.start, w.end, func(event asana.AuditLogEvent) error {
+		batch = append(batch, event)
+		if len(batch) >= auditEventBatch {
+			flush()
+		}
+		return nil
+	})
+	flush()
+	if err != nil {
+		return fmt.Errorf("audit log API failure: %w", err)
+	}
+
+	if !failed {
+		results <- func(s *Stats) { s.auditEventsEnd = w.end }
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package extractor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// auditClient serves events by creation time and records the windows asked
+// for
+type auditClient struct {
+	mockAsanaClient
+	events  []asana.AuditLogEvent
+	windows [][2]time.Time
+}
+
+func (m *auditClient) StreamAuditLogEvents(ctx context.Context, start, end time.Time, fn func(asana.AuditLogEvent) error) error {
+	m.windows = append(m.windows, [2]time.Time{start, end})
+	for _, event := range m.events {
+		if event.CreatedAt.Before(start) || !event.CreatedAt.Before(end) {
+			continue
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditStorage keeps a checkpoint and the audit log events of each run
+type auditStorage struct {
+	checkpointStorage
+	logs       map[string][]asana.AuditLogEvent
+	failAppend bool
+}
+
+func (m *auditStorage) WriteAuditLogEvents(runID string, events []asana.AuditLogEvent) error {
+	if m.failAppend {
+		return fmt.Errorf("disk error")
+	}
+	m.logs[runID] = append(m.logs[runID], events...)
+	return nil
+}
+
+func TestExtractor_AuditEvents(t *testing.T) {
+	now := time.Now()
+	client := &auditClient{events: []asana.AuditLogEvent{
+		{GID: "old", CreatedAt: now.Add(-48 * time.Hour)},
+		{GID: "e1", CreatedAt: now.Add(-2 * time.Hour)},
+	}}
+	for i := range 150 {
+		client.events = append(client.events, asana.AuditLogEvent{GID: fmt.Sprint("bulk", i), CreatedAt: now.Add(-time.Hour)})
+	}
+	store := &auditStorage{logs: make(map[string][]asana.AuditLogEvent)}
+	store.checkpoint = []byte(`{"task_watermarks":{"p1":"2024-01-01T00:00:00Z"}}`)
+	cfg := Config{Resources: []string{ResourceAuditLogEvents}, AuditEventLookback: 24 * time.Hour, Reconcile: ReconcileDelete}
+
+	// The first window reaches back by the lookback
+	stats, err := New(client, store, cfg).Extract(context.Background())
+	if err != nil {
+		t.Fatalf("First run failed: %v", err)
+	}
+	if stats.AuditLogEventsExtracted != 151 || len(store.logs[stats.RunID]) != 151 {
+		t.Fatalf("Expected the 151 events of the last day, got %d", stats.AuditLogEventsExtracted)
+	}
+	if _, ok := store.live[ResourceAuditLogEvents]; ok {
+		t.Error("Expected audit log events not to be reconciled")
+	}
+	first := client.windows[0]
+	if got := first[1].Sub(first[0]); got != 24*time.Hour {
+		t.Errorf("Expected a 24h first window, got %v", got)
+	}
+
+	var cp Checkpoint
+	json.Unmarshal(store.checkpoint, &cp)
+	if !cp.AuditEventsEnd.Equal(first[1]) || len(cp.TaskWatermarks) != 1 {
+		t.Fatalf("Expected the window end saved next to the task watermarks, got %+v", cp)
+	}
+
+	// The next window starts where the first ended; a failed write keeps it
+	// from moving on
+	client.events = append(client.events, asana.AuditLogEvent{GID: "e2", CreatedAt: first[1]})
+	store.failAppend = true
+	stats, err = New(client, store, cfg).Extract(context.Background())
+	if err != nil {
+		t.Fatalf("Second run failed: %v", err)
+	}
+	if !client.windows[1][0].Equal(first[1]) || stats.Errors != 1 {
+		t.Errorf("Expected the second window to start at %v with e2 failing, got %v and %d errors", first[1], client.windows[1][0], stats.Errors)
+	}
+	cp = Checkpoint{}
+	json.Unmarshal(store.checkpoint, &cp)
+	if !cp.AuditEventsEnd.Equal(first[1]) {
+		t.Errorf("Expected the window end to stay put after a failed write, got %v", cp.AuditEventsEnd)
+	}
+}
+
+func TestExtractor_AuditEventsUnsupported(t *testing.T) {
+	cfg := Config{Resources: []string{ResourceAuditLogEvents}}
+	if _, err := New(&auditClient{}, &mockStorage{}, cfg).Extract(context.Background()); err == nil {
+		t.Error("Expected an error when storage cannot write the audit log")
+	}
+}
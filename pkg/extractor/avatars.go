@@ -0,0 +1,71 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+)
+
+// AvatarClient is implemented by Asana clients that can download user
+// photos
+type AvatarClient interface {
+	DownloadPhoto(ctx context.Context, photoURL string) ([]byte, error)
+}
+
+// AvatarWriter is implemented by storage backends that can store user
+// avatars, remembering the photo URL each was downloaded from
+type AvatarWriter interface {
+	// AvatarCurrent reports whether the stored avatar of the user was
+	// downloaded from photoURL
+	AvatarCurrent(userGID, photoURL string) bool
+	WriteAvatar(userGID, photoURL string, image []byte) error
+}
+
+// extractAvatars stores the largest photo of every user with one,
+// downloading Config.Concurrency photos in parallel. Avatars already
+// downloaded from the same URL are not fetched again.
+func (e *Extractor) extractAvatars(ctx context.Context, results chan<- func(*Stats), users []userRef) error {
+	ctx, span := tracer.Start(ctx, "extractor.avatars")
+	defer span.End()
+
+	client, canRead := e.asanaClient.(AvatarClient)
+	writer, canWrite := e.storage.(AvatarWriter)
+	if !canRead || !canWrite {
+		return fmt.Errorf("avatars are not supported by this client or storage backend")
+	}
+
+	photos := make(map[string]string)
+	var gids []string
+	for _, user := range users {
+		if user.photoURL != "" {
+			photos[user.gid] = user.photoURL
+			gids = append(gids, user.gid)
+		}
+	}
+
+	return e.fanOut(ctx, gids, func(ctx context.Context, userGID string) error {
+		url := photos[userGID]
+		if writer.AvatarCurrent(userGID, url) {
+			results <- func(s *Stats) { s.AvatarsExtracted++ }
+			return nil
+		}
+
+		// A failed download only loses this user's avatar: photos are
+		// served from a CDN whose errors say nothing about the API
+		image, err := client.DownloadPhoto(ctx, url)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			logf(ctx, "Error downloading avatar of user %s: %v", userGID, err)
+			results <- func(s *Stats) { s.recordError(ResourceAvatars) }
+			return nil
+		}
+		if err := writer.WriteAvatar(userGID, url, image); err != nil {
+			logf(ctx, "Error writing avatar of user %s: %v", userGID, err)
+			results <- func(s *Stats) { s.recordError(ResourceAvatars) }
+			return nil
+		}
+		results <- func(s *Stats) { s.AvatarsExtracted++ }
+		return nil
+	})
+}
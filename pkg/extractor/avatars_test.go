@@ -0,0 +1,98 @@
+package extractor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// photoClient serves photos by URL; other URLs fail like expired ones
+type photoClient struct {
+	mockAsanaClient
+	photos    map[string][]byte
+	downloads atomic.Int32
+}
+
+func (m *photoClient) DownloadPhoto(ctx context.Context, photoURL string) ([]byte, error) {
+	m.downloads.Add(1)
+	image, ok := m.photos[photoURL]
+	if !ok {
+		return nil, asana.ErrForbidden
+	}
+	return image, nil
+}
+
+// avatarStorage keeps avatars with the URL they came from
+type avatarStorage struct {
+	mockStorage
+	avatarMu sync.Mutex
+	urls     map[string]string
+	images   map[string][]byte
+}
+
+func (m *avatarStorage) AvatarCurrent(userGID, photoURL string) bool {
+	m.avatarMu.Lock()
+	defer m.avatarMu.Unlock()
+	return m.urls[userGID] == photoURL
+}
+
+func (m *avatarStorage) WriteAvatar(userGID, photoURL string, image []byte) error {
+	m.avatarMu.Lock()
+	defer m.avatarMu.Unlock()
+	m.urls[userGID] = photoURL
+	m.images[userGID] = image
+	return nil
+}
+
+func TestExtractor_Avatars(t *testing.T) {
+	client := &photoClient{
+		mockAsanaClient: mockAsanaClient{users: []asana.User{
+			{GID: "u1", Photo: &asana.Photo{Image128x128: "https://cdn/u1_128.png", Image1024x1024: "https://cdn/u1_1024.png"}},
+			{GID: "u2", Photo: &asana.Photo{Image128x128: "https://cdn/u2_128.png"}},
+			{GID: "u3"},
+			{GID: "u4", Photo: &asana.Photo{Image1024x1024: "https://cdn/expired.png"}},
+		}},
+		photos: map[string][]byte{
+			"https://cdn/u1_1024.png": []byte("large"),
+			"https://cdn/u2_128.png":  []byte("medium"),
+		},
+	}
+	store := &avatarStorage{urls: make(map[string]string), images: make(map[string][]byte)}
+
+	cfg := Config{Resources: []string{ResourceAvatars}, Concurrency: 2}
+	stats, err := New(client, store, cfg).Extract(context.Background())
+	if err != nil {
+		t.Fatalf("Extract() failed: %v", err)
+	}
+
+	if stats.AvatarsExtracted != 2 || string(store.images["u1"]) != "large" || string(store.images["u2"]) != "medium" {
+		t.Fatalf("Expected the largest photo of both users, got %d: %v", stats.AvatarsExtracted, store.images)
+	}
+	if r := stats.Resources[ResourceAvatars]; r == nil || r.Errors != 1 {
+		t.Errorf("Expected the failed download to count as an error, got %+v", r)
+	}
+	if stats.UsersExtracted != 0 {
+		t.Error("Expected users to be walked without being written")
+	}
+
+	// A second run downloads nothing new
+	client.downloads.Store(0)
+	stats, err = New(client, store, cfg).Extract(context.Background())
+	if err != nil {
+		t.Fatalf("Extract() failed: %v", err)
+	}
+	if n := client.downloads.Load(); n != 1 || stats.AvatarsExtracted != 2 {
+		t.Errorf("Expected only the failed avatar to be downloaded again, got %d downloads", n)
+	}
+}
+
+func TestExtractor_AvatarsUnsupported(t *testing.T) {
+	client := &photoClient{mockAsanaClient: mockAsanaClient{users: []asana.User{{GID: "u1"}}}}
+	cfg := Config{Resources: []string{ResourceAvatars}}
+	if _, err := New(client, &mockStorage{}, cfg).Extract(context.Background()); err == nil {
+		t.Error("Expected an error when storage cannot write avatars")
+	}
+}
@@ -0,0 +1,152 @@
+package extractor
+
+import (
+	"context"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// DefaultWriteBatchSize is the number of entities handed to a BatchWriter at
+// once when Config.WriteBatchSize is not set: one Asana page
+const DefaultWriteBatchSize = 100
+
+// BatchWriter is implemented by storage backends that store several
+// entities faster in one call than one at a time, e.g. by appending a whole
+// page to a file. A failed call counts every entity in it as failed.
+// Backends without it get one Write call per entity.
+type BatchWriter interface {
+	WriteUsers(users []asana.User) error
+	WriteProjects(projects []asana.Project) error
+	WriteTasks(tasks []asana.Task) error
+	WriteTeams(teams []asana.Team) error
+}
+
+// entityBatch buffers the entities of one resource until it holds size of
+// them, then writes them in one call. Without a batch function each entity
+// is written as it is added. A batch belongs to a single goroutine.
+type entityBatch[T any] struct {
+	// ctx is the run's context, for its log lines
+	ctx      context.Context
+	resource string
+	kind     string // singular, for log messages
+	size     int
+	results  chan<- func(*Stats)
+	gid      func(T) string
+	one      func(T) error
+	all      func([]T) error
+	written  func(s *Stats, n int)
+
+	items []T
+	// failed is set once any entity failed to store
+	failed bool
+}
+
+// add stores v, or queues it until the batch is full
+func (b *entityBatch[T]) add(v T) {
+	if b.all == nil {
+		gid := b.gid(v)
+		if err := b.one(v); err != nil {
+			logf(b.ctx, "Error writing %s %s: %v", b.kind, gid, err)
+			b.failed = true
+			b.results <- func(s *Stats) { s.recordError(b.resource); s.markLive(b.resource, gid) }
+			return
+		}
+		b.results <- func(s *Stats) { b.written(s, 1); s.markLive(b.resource, gid) }
+		return
+	}
+
+	b.items = append(b.items, v)
+	if len(b.items) >= b.size {
+		b.flush()
+	}
+}
+
+// flush writes the queued entities. It must be called once the stream
+// ends, whether or not it failed, so entities already fetched are kept.
+func (b *entityBatch[T]) flush() {
+	if len(b.items) == 0 {
+		return
+	}
+	gids := make([]string, len(b.items))
+	for i, v := range b.items {
+		gids[i] = b.gid(v)
+	}
+	err := b.all(b.items)
+	b.items = nil
+
+	if err != nil {
+		logf(b.ctx, "Error writing %d %s: %v", len(gids), b.resource, err)
+		b.failed = true
+		b.results <- func(s *Stats) {
+			for _, gid := range gids {
+				s.recordError(b.resource)
+				s.markLive(b.resource, gid)
+			}
+		}
+		return
+	}
+	b.results <- func(s *Stats) {
+		b.written(s, len(gids))
+		for _, gid := range gids {
+			s.markLive(b.resource, gid)
+		}
+	}
+}
+
+// batchWriter returns the storage's BatchWriter, or nil when it has none
+func (e *Extractor) batchWriter() BatchWriter {
+	w, _ := e.storage.(BatchWriter)
+	return w
+}
+
+func (e *Extractor) userBatch(ctx context.Context, results chan<- func(*Stats)) *entityBatch[asana.User] {
+	b := &entityBatch[asana.User]{
+		ctx: ctx, resource: ResourceUsers, kind: "user", size: e.cfg.WriteBatchSize, results: results,
+		gid:     func(u asana.User) string { return u.GID },
+		one:     e.storage.WriteUser,
+		written: func(s *Stats, n int) { s.UsersExtracted += n },
+	}
+	if w := e.batchWriter(); w != nil {
+		b.all = w.WriteUsers
+	}
+	return b
+}
+
+func (e *Extractor) projectBatch(ctx context.Context, results chan<- func(*Stats)) *entityBatch[asana.Project] {
+	b := &entityBatch[asana.Project]{
+		ctx: ctx, resource: ResourceProjects, kind: "project", size: e.cfg.WriteBatchSize, results: results,
+		gid:     func(p asana.Project) string { return p.GID },
+		one:     e.storage.WriteProject,
+		written: func(s *Stats, n int) { s.ProjectsExtracted += n },
+	}
+	if w := e.batchWriter(); w != nil {
+		b.all = w.WriteProjects
+	}
+	return b
+}
+
+func (e *Extractor) taskBatch(ctx context.Context, results chan<- func(*Stats)) *entityBatch[asana.Task] {
+	b := &entityBatch[asana.Task]{
+		ctx: ctx, resource: ResourceTasks, kind: "task", size: e.cfg.WriteBatchSize, results: results,
+		gid:     func(t asana.Task) string { return t.GID },
+		one:     e.storage.WriteTask,
+		written: func(s *Stats, n int) { s.TasksExtracted += n },
+	}
+	if w := e.batchWriter(); w != nil {
+		b.all = w.WriteTasks
+	}
+	return b
+}
+
+func (e *Extractor) teamBatch(ctx context.Context, results chan<- func(*Stats)) *entityBatch[asana.Team] {
+	b := &entityBatch[asana.Team]{
+		ctx: ctx, resource: ResourceTeams, kind: "team", size: e.cfg.WriteBatchSize, results: results,
+		gid:     func(t asana.Team) string { return t.GID },
+		one:     e.storage.WriteTeam,
+		written: func(s *Stats, n int) { s.TeamsExtracted += n },
+	}
+	if w := e.batchWriter(); w != nil {
+		b.all = w.WriteTeams
+	}
+	return b
+}
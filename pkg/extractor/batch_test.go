@@ -0,0 +1,102 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// batchStorage records the size of every batch and fails batches holding
+// a task in failTasks
+type batchStorage struct {
+	mockStorage
+	batches   map[string][]int
+	failTasks map[string]bool
+}
+
+func (m *batchStorage) record(resource string, n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.batches[resource] = append(m.batches[resource], n)
+}
+
+func (m *batchStorage) WriteUsers(users []asana.User) error {
+	m.record(ResourceUsers, len(users))
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.users = append(m.users, users...)
+	return nil
+}
+
+func (m *batchStorage) WriteProjects(projects []asana.Project) error {
+	m.record(ResourceProjects, len(projects))
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.projects = append(m.projects, projects...)
+	return nil
+}
+
+func (m *batchStorage) WriteTasks(tasks []asana.Task) error {
+	m.record(ResourceTasks, len(tasks))
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, task := range tasks {
+		if m.failTasks[task.GID] {
+			return fmt.Errorf("disk error")
+		}
+	}
+	m.tasks = append(m.tasks, tasks...)
+	return nil
+}
+
+func (m *batchStorage) WriteTeams(teams []asana.Team) error {
+	m.record(ResourceTeams, len(teams))
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.teams = append(m.teams, teams...)
+	return nil
+}
+
+func TestExtractor_WriteBatches(t *testing.T) {
+	client := &mockAsanaClient{
+		users:    []asana.User{{GID: "u1"}, {GID: "u2"}, {GID: "u3"}},
+		projects: []asana.Project{{GID: "p1"}, {GID: "p2"}},
+		tasks:    map[string][]asana.Task{"p1": {}, "p2": {}},
+		teams:    []asana.Team{{GID: "team1"}},
+	}
+	for i := range 5 {
+		client.tasks["p1"] = append(client.tasks["p1"], asana.Task{GID: fmt.Sprintf("a%d", i)})
+	}
+	client.tasks["p2"] = []asana.Task{{GID: "b0"}, {GID: "bad"}, {GID: "b1"}}
+	store := &batchStorage{batches: make(map[string][]int), failTasks: map[string]bool{"bad": true}}
+
+	stats, err := New(client, store, Config{WriteBatchSize: 2}).Extract(context.Background())
+	if err != nil {
+		t.Fatalf("Extract() failed: %v", err)
+	}
+
+	want := map[string][]int{
+		ResourceUsers:    {2, 1},
+		ResourceProjects: {2},
+		ResourceTasks:    {2, 2, 1, 2, 1},
+		ResourceTeams:    {1},
+	}
+	for resource, sizes := range want {
+		if got := store.batches[resource]; fmt.Sprint(got) != fmt.Sprint(sizes) {
+			t.Errorf("Expected %s batches %v, got %v", resource, sizes, got)
+		}
+	}
+
+	if stats.UsersExtracted != 3 || stats.ProjectsExtracted != 2 || stats.TeamsExtracted != 1 {
+		t.Errorf("Unexpected counts: %+v", stats)
+	}
+	// The failed batch takes b0 down with bad
+	if stats.TasksExtracted != 6 || stats.Errors != 2 {
+		t.Errorf("Expected 6 tasks stored and 2 failed, got %d and %d", stats.TasksExtracted, stats.Errors)
+	}
+	if len(store.users) != 3 || len(store.tasks) != 6 {
+		t.Errorf("Expected every batch to reach storage, got %d users and %d tasks", len(store.users), len(store.tasks))
+	}
+}
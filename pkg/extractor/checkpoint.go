@@ -0,0 +1,106 @@
+package extractor
+
+import (
+	"context"
+	"maps"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// watermarkOverlap keeps a task watermark this far behind the start of the
+// fetch that set it. A task modified mid-fetch, on a page already read,
+// can have an older modified_at than tasks on later pages; the overlap,
+// which also absorbs clock skew, makes the next run fetch it again.
+const watermarkOverlap = time.Minute
+
+// Checkpoint is the state an incremental run carries over to the next one
+type Checkpoint struct {
+	// TaskWatermarks holds, per project GID, the latest modified_at of the
+	// project's tasks stored so far
+	TaskWatermarks map[string]time.Time `json:"task_watermarks"`
+	// AuditEventsEnd is the end of the last window of audit log events
+	// stored, where the next window starts
+	AuditEventsEnd time.Time `json:"audit_log_events_end,omitzero"`
+}
+
+// CheckpointStore is implemented by storage backends that persist the
+// checkpoint between runs. LoadCheckpoint leaves v untouched when no
+// checkpoint has been saved yet.
+type CheckpointStore interface {
+	LoadCheckpoint(v any) error
+	SaveCheckpoint(v any) error
+}
+
+// IncrementalTaskClient is implemented by Asana clients that can list only
+// the tasks of a project modified since a point in time
+type IncrementalTaskClient interface {
+	StreamTasksModifiedSince(ctx context.Context, projectGID string, since time.Time, fn func(asana.Task) error) error
+}
+
+// incremental reports whether tasks are fetched by watermark, which needs
+// both a checkpoint store and a client that can filter by modification
+func (e *Extractor) incremental() bool {
+	if !e.cfg.IncrementalTasks {
+		return false
+	}
+	_, stores := e.storage.(CheckpointStore)
+	_, filters := e.asanaClient.(IncrementalTaskClient)
+	return stores && filters
+}
+
+// checkpointing reports whether this run reads and updates the checkpoint:
+// for incremental tasks or the audit log, given a checkpoint store
+func (e *Extractor) checkpointing() bool {
+	if _, ok := e.storage.(CheckpointStore); !ok {
+		return false
+	}
+	return (e.incremental() && e.enabled(ResourceTasks)) || e.enabled(ResourceAuditLogEvents)
+}
+
+// loadCheckpoint returns the checkpoint of the last successful run. An
+// unreadable checkpoint is logged and costs one full task sync and one
+// audit log lookback.
+func (e *Extractor) loadCheckpoint(ctx context.Context) Checkpoint {
+	var cp Checkpoint
+	if err := e.storage.(CheckpointStore).LoadCheckpoint(&cp); err != nil {
+		logf(ctx, "Error loading checkpoint, starting over: %v", err)
+		return Checkpoint{}
+	}
+	return cp
+}
+
+// saveCheckpoint updates cp, as loaded at the start of the run, with what
+// this run advanced and persists it. Task watermarks are replaced by those
+// of the projects this run fetched tasks for, so projects that are gone
+// drop out; state of phases that did not run is kept. Failures are logged;
+// the next run then starts from the older checkpoint.
+func (e *Extractor) saveCheckpoint(ctx context.Context, cp Checkpoint, stats *Stats) {
+	switch {
+	case stats.APIBudgetExhausted:
+		// The run did not reach every project; the others keep theirs
+		marks := maps.Clone(cp.TaskWatermarks)
+		if marks == nil {
+			marks = make(map[string]time.Time)
+		}
+		maps.Copy(marks, stats.watermarks)
+		cp.TaskWatermarks = marks
+	case stats.watermarks != nil:
+		cp.TaskWatermarks = stats.watermarks
+	}
+	if !stats.auditEventsEnd.IsZero() {
+		cp.AuditEventsEnd = stats.auditEventsEnd
+	}
+	if err := e.storage.(CheckpointStore).SaveCheckpoint(cp); err != nil {
+		logf(ctx, "Error saving checkpoint: %v", err)
+	}
+}
+
+// setWatermark records the watermark of a project whose tasks were all
+// fetched and stored. It must only be called from the stats collector.
+func (s *Stats) setWatermark(projectGID string, mark time.Time) {
+	if s.watermarks == nil {
+		s.watermarks = make(map[string]time.Time)
+	}
+	s.watermarks[projectGID] = mark
+}
@@ -0,0 +1,211 @@
+package extractor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+	"github.com/ioanzicu/asana-extractor/pkg/client"
+)
+
+// incrementalClient serves a project's tasks modified since a time and
+// records the watermark each project was asked for
+type incrementalClient struct {
+	mockAsanaClient
+	since map[string]time.Time
+}
+
+func (m *incrementalClient) StreamTasksModifiedSince(ctx context.Context, projectGID string, since time.Time, fn func(asana.Task) error) error {
+	m.since[projectGID] = since
+	for _, task := range m.tasks[projectGID] {
+		if task.ModifiedAt.Before(since) {
+			continue
+		}
+		if err := fn(task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkpointStorage keeps the checkpoint as JSON in memory and reconciles
+type checkpointStorage struct {
+	reconcilingStorage
+	checkpoint []byte
+}
+
+func (m *checkpointStorage) LoadCheckpoint(v any) error {
+	if m.checkpoint == nil {
+		return nil
+	}
+	return json.Unmarshal(m.checkpoint, v)
+}
+
+func (m *checkpointStorage) SaveCheckpoint(v any) error {
+	data, err := json.Marshal(v)
+	m.checkpoint = data
+	return err
+}
+
+func TestExtractor_IncrementalTasks(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour).UTC()
+	recent := time.Now().Add(-2 * time.Hour).UTC()
+	client := &incrementalClient{
+		mockAsanaClient: mockAsanaClient{
+			projects: []asana.Project{{GID: "p1"}, {GID: "p2"}, {GID: "p3"}},
+			tasks: map[string][]asana.Task{
+				"p1": {{GID: "t1", ModifiedAt: old}, {GID: "t2", ModifiedAt: recent}},
+				"p2": {{GID: "t3", ModifiedAt: old}},
+			},
+		},
+		since: make(map[string]time.Time),
+	}
+	store := &checkpointStorage{}
+	cfg := Config{Resources: []string{ResourceProjects, ResourceTasks}, IncrementalTasks: true, Reconcile: ReconcileDelete}
+
+	// The first run has no watermarks: every task is fetched and reconciled
+	stats, err := New(client, store, cfg).Extract(context.Background())
+	if err != nil {
+		t.Fatalf("First run failed: %v", err)
+	}
+	if stats.TasksExtracted != 3 || len(client.since) != 0 || store.live[ResourceTasks] == nil {
+		t.Fatalf("Expected a full, reconciled first run, got %d tasks, since %v", stats.TasksExtracted, client.since)
+	}
+	var cp Checkpoint
+	json.Unmarshal(store.checkpoint, &cp)
+	if !cp.TaskWatermarks["p1"].Equal(recent) || !cp.TaskWatermarks["p2"].Equal(old) || !cp.TaskWatermarks["p3"].IsZero() {
+		t.Fatalf("Expected the latest modified_at per project, got %v", cp.TaskWatermarks)
+	}
+
+	// The second run asks only for changes; p3 never had tasks, so it is
+	// fetched in full, and tasks are not reconciled
+	client.tasks["p2"] = append(client.tasks["p2"], asana.Task{GID: "t4", ModifiedAt: time.Now().UTC()})
+	client.projects = client.projects[:2]
+	store.live = nil
+	stats, err = New(client, store, cfg).Extract(context.Background())
+	if err != nil {
+		t.Fatalf("Second run failed: %v", err)
+	}
+	if stats.TasksExtracted != 3 {
+		t.Errorf("Expected t2 and t3 (at their watermarks) and t4, got %d tasks", stats.TasksExtracted)
+	}
+	if !client.since["p1"].Equal(recent) || !client.since["p2"].Equal(old) {
+		t.Errorf("Expected the saved watermarks to be used, got %v", client.since)
+	}
+	if _, ok := store.live[ResourceTasks]; ok {
+		t.Error("Expected tasks not to be reconciled after an incremental run")
+	}
+
+	cp = Checkpoint{}
+	json.Unmarshal(store.checkpoint, &cp)
+	if _, ok := cp.TaskWatermarks["p3"]; ok {
+		t.Errorf("Expected the watermark of a removed project to be dropped, got %v", cp.TaskWatermarks)
+	}
+	if limit := time.Now().Add(-watermarkOverlap); cp.TaskWatermarks["p2"].After(limit) {
+		t.Errorf("Expected the p2 watermark to stay behind the fetch, got %v", cp.TaskWatermarks["p2"])
+	}
+}
+
+func TestExtractor_IncrementalTasksWriteFailure(t *testing.T) {
+	client := &incrementalClient{
+		mockAsanaClient: mockAsanaClient{
+			projects: []asana.Project{{GID: "p1"}},
+			tasks:    map[string][]asana.Task{"p1": {{GID: "t1", ModifiedAt: time.Now().Add(-time.Hour)}}},
+		},
+		since: make(map[string]time.Time),
+	}
+	since := time.Now().Add(-24 * time.Hour).UTC()
+	store := &checkpointStorage{checkpoint: []byte(`{"task_watermarks":{"p1":"` + since.Format(time.RFC3339Nano) + `"}}`)}
+	store.failWrite = true
+
+	cfg := Config{Resources: []string{ResourceTasks}, IncrementalTasks: true}
+	if _, err := New(client, store, cfg).Extract(context.Background()); err != nil {
+		t.Fatalf("Extract() failed: %v", err)
+	}
+
+	var cp Checkpoint
+	json.Unmarshal(store.checkpoint, &cp)
+	if !cp.TaskWatermarks["p1"].Equal(since) {
+		t.Errorf("Expected the watermark to stay put after a failed write, got %v", cp.TaskWatermarks["p1"])
+	}
+}
+
+func TestExtractor_IncrementalTasksUnsupported(t *testing.T) {
+	// Without a checkpoint store every run fetches all tasks
+	client := &incrementalClient{
+		mockAsanaClient: mockAsanaClient{
+			projects: []asana.Project{{GID: "p1"}},
+			tasks:    map[string][]asana.Task{"p1": {{GID: "t1"}}},
+		},
+		since: make(map[string]time.Time),
+	}
+	e := New(client, &mockStorage{}, Config{IncrementalTasks: true})
+	if e.incremental() {
+		t.Fatal("Expected incremental extraction to need a checkpoint store")
+	}
+	stats, err := e.Extract(context.Background())
+	if err != nil || stats.TasksExtracted != 1 {
+		t.Errorf("Expected a full task fetch, got %d tasks, %v", stats.TasksExtracted, err)
+	}
+}
+
+// budgetedClient refuses task listings once its budget of projects is
+// spent, as the Asana client does once MaxAPICalls is reached
+type budgetedClient struct {
+	incrementalClient
+	projects int
+}
+
+func (m *budgetedClient) StreamTasksModifiedSince(ctx context.Context, projectGID string, since time.Time, fn func(asana.Task) error) error {
+	if m.projects == 0 {
+		return fmt.Errorf("failed to get tasks: %w", client.ErrCallBudgetExhausted)
+	}
+	m.projects--
+	return m.incrementalClient.StreamTasksModifiedSince(ctx, projectGID, since, fn)
+}
+
+func TestExtractor_APIBudgetExhausted(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour).UTC()
+	kept := time.Now().Add(-72 * time.Hour).UTC()
+	asanaClient := &budgetedClient{
+		incrementalClient: incrementalClient{
+			mockAsanaClient: mockAsanaClient{
+				projects: []asana.Project{{GID: "p1"}, {GID: "p2"}},
+				tasks: map[string][]asana.Task{
+					"p1": {{GID: "t1", ModifiedAt: old}},
+					"p2": {{GID: "t2", ModifiedAt: old}},
+				},
+			},
+			since: make(map[string]time.Time),
+		},
+		projects: 1,
+	}
+	store := &checkpointStorage{checkpoint: []byte(`{"task_watermarks": {"p1": "` + kept.Format(time.RFC3339Nano) + `", "p2": "` + kept.Format(time.RFC3339Nano) + `"}}`)}
+	cfg := Config{Resources: []string{ResourceProjects, ResourceTasks}, IncrementalTasks: true, Reconcile: ReconcileDelete, Concurrency: 1}
+
+	stats, err := New(asanaClient, store, cfg).Extract(context.Background())
+	if err != nil {
+		t.Fatalf("Expected a graceful stop, got %v", err)
+	}
+	if !stats.APIBudgetExhausted || stats.TasksExtracted != 1 {
+		t.Fatalf("Expected a partial run with 1 task, got exhausted=%t, %d tasks", stats.APIBudgetExhausted, stats.TasksExtracted)
+	}
+	if store.live != nil {
+		t.Error("Expected a partial run not to be reconciled")
+	}
+
+	// The project reached advances; the other keeps its watermark
+	var cp Checkpoint
+	json.Unmarshal(store.checkpoint, &cp)
+	if len(cp.TaskWatermarks) != 2 {
+		t.Fatalf("Expected both watermarks kept, got %v", cp.TaskWatermarks)
+	}
+	for gid, mark := range cp.TaskWatermarks {
+		if _, reached := asanaClient.since[gid]; reached == mark.Equal(kept) {
+			t.Errorf("Unexpected watermark for %s: %v", gid, mark)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// CustomFieldClient is implemented by Asana clients that can list the
+// workspace's custom field definitions
+type CustomFieldClient interface {
+	StreamCustomFields(ctx context.Context, fn func(asana.CustomField) error) error
+}
+
+// CustomFieldWriter is implemented by storage backends that can store
+// custom field definitions and the lookup of their enum options, keyed by
+// field GID, then option GID
+type CustomFieldWriter interface {
+	WriteCustomField(field asana.CustomField) error
+	WriteCustomFieldOptions(options map[string]map[string]asana.EnumOption) error
+}
+
+// extractCustomFields stores every custom field definition, then the enum
+// option lookup built from them, so consumers can resolve the option GIDs
+// in task custom field values without parsing the definitions
+func (e *Extractor) extractCustomFields(ctx context.Context, results chan<- func(*Stats)) error {
+	ctx, span := tracer.Start(ctx, "extractor.custom_fields")
+	defer span.End()
+
+	client, canRead := e.asanaClient.(CustomFieldClient)
+	writer, canWrite := e.storage.(CustomFieldWriter)
+	if !canRead || !canWrite {
+		return fmt.Errorf("custom fields are not supported by this client or storage backend")
+	}
+
+	options := make(map[string]map[string]asana.EnumOption)
+	err := client.StreamCustomFields(ctx, func(field asana.CustomField) error {
+		if len(field.EnumOptions) > 0 {
+			byGID := make(map[string]asana.EnumOption, len(field.EnumOptions))
+			for _, option := range field.EnumOptions {
+				byGID[option.GID] = option
+			}
+			options[field.GID] = byGID
+		}
+
+		if err := writer.WriteCustomField(field); err != nil {
+			logf(ctx, "Error writing custom field %s: %v", field.GID, err)
+			results <- func(s *Stats) { s.recordError(ResourceCustomFields); s.markLive(ResourceCustomFields, field.GID) }
+			return nil
+		}
+		results <- func(s *Stats) { s.CustomFieldsExtracted++; s.markLive(ResourceCustomFields, field.GID) }
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("custom field API failure: %w", err)
+	}
+
+	// The lookup is only replaced once every field was listed
+	if err := writer.WriteCustomFieldOptions(options); err != nil {
+		logf(ctx, "Error writing custom field options: %v", err)
+		results <- func(s *Stats) { s.recordError(ResourceCustomFields) }
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package extractor
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// fieldClient serves custom field definitions
+type fieldClient struct {
+	mockAsanaClient
+	fields []asana.CustomField
+}
+
+func (m *fieldClient) StreamCustomFields(ctx context.Context, fn func(asana.CustomField) error) error {
+	for _, f := range m.fields {
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldStorage keeps the custom fields and the option lookup it is given
+type fieldStorage struct {
+	mockStorage
+	fieldMu sync.Mutex
+	fields  map[string]asana.CustomField
+	options map[string]map[string]asana.EnumOption
+}
+
+func (m *fieldStorage) WriteCustomField(field asana.CustomField) error {
+	m.fieldMu.Lock()
+	defer m.fieldMu.Unlock()
+	m.fields[field.GID] = field
+	return nil
+}
+
+func (m *fieldStorage) WriteCustomFieldOptions(options map[string]map[string]asana.EnumOption) error {
+	m.options = options
+	return nil
+}
+
+func TestExtractor_CustomFields(t *testing.T) {
+	enabled := true
+	client := &fieldClient{fields: []asana.CustomField{
+		{GID: "f1", ResourceSubtype: "enum", EnumOptions: []asana.EnumOption{
+			{GID: "o1", Name: "High", Color: "red", Enabled: &enabled},
+			{GID: "o2", Name: "Low", Color: "green", Enabled: &enabled},
+		}},
+		{GID: "f2", ResourceSubtype: "text"},
+	}}
+	store := &fieldStorage{fields: make(map[string]asana.CustomField)}
+
+	cfg := Config{Resources: []string{ResourceCustomFields}}
+	stats, err := New(client, store, cfg).Extract(context.Background())
+	if err != nil {
+		t.Fatalf("Extract() failed: %v", err)
+	}
+
+	if stats.CustomFieldsExtracted != 2 || len(store.fields) != 2 {
+		t.Fatalf("Expected 2 custom fields, got %d", stats.CustomFieldsExtracted)
+	}
+	if len(store.options) != 1 || len(store.options["f1"]) != 2 {
+		t.Fatalf("Expected options of the enum field only, got %+v", store.options)
+	}
+	if o := store.options["f1"]["o1"]; o.Name != "High" || o.Color != "red" || o.Enabled == nil || !*o.Enabled {
+		t.Errorf("Expected option o1 with its name, color and state, got %+v", o)
+	}
+}
+
+func TestExtractor_CustomFieldsUnsupported(t *testing.T) {
+	cfg := Config{Resources: []string{ResourceCustomFields}}
+	if _, err := New(&fieldClient{}, &mockStorage{}, cfg).Extract(context.Background()); err == nil {
+		t.Error("Expected an error when storage cannot write custom fields")
+	}
+}
@@ -2,59 +2,302 @@ package extractor
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"sync"
 	"time"
 
 	"github.com/ioanzicu/asana-extractor/pkg/asana"
+	"github.com/ioanzicu/asana-extractor/pkg/client"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// Stats holds extraction statistics
-type Stats struct {
-	UsersExtracted    int
-	ProjectsExtracted int
-	Errors            int
-	Duration          time.Duration
-}
+// tracer emits a root span per run and one span per extraction phase
+var tracer = otel.Tracer("github.com/ioanzicu/asana-extractor/pkg/extractor")
 
 // AsanaClient defines the subset of Asana operations the extractor needs.
+// Resources are streamed page by page so memory stays bounded regardless of
+// workspace size.
 type AsanaClient interface {
-	GetAllUsers(ctx context.Context) ([]asana.User, error)
-	GetAllProjects(ctx context.Context) ([]asana.Project, error)
+	StreamUsers(ctx context.Context, fn func(asana.User) error) error
+	StreamProjects(ctx context.Context, fn func(asana.Project) error) error
+	StreamTasks(ctx context.Context, projectGID string, fn func(asana.Task) error) error
+	StreamTeams(ctx context.Context, fn func(asana.Team) error) error
 }
 
 // Storage defines the interface for storing extracted data
 type Storage interface {
 	WriteUser(user asana.User) error
 	WriteProject(project asana.Project) error
+	WriteTask(task asana.Task) error
+	WriteTeam(team asana.Team) error
+}
+
+// ChangeCounter is implemented by storage backends that skip writing
+// entities identical to what they already hold
+type ChangeCounter interface {
+	Unchanged() int64
+}
+
+// Resource names accepted in Config.Resources
+const (
+	ResourceUsers    = "users"
+	ResourceProjects = "projects"
+	ResourceTasks    = "tasks"
+	ResourceTeams    = "teams"
+
+	// ResourceUserTaskLists, ResourceAvatars, ResourceAttachments,
+	// ResourceStatusUpdates, ResourceCustomFields and
+	// ResourceAuditLogEvents are optional: they only run when selected
+	// explicitly. Task lists, avatars, attachments and status updates cost
+	// at least one request per user, task or project, and audit log events
+	// need an Enterprise organization.
+	ResourceUserTaskLists  = "user_task_lists"
+	ResourceAvatars        = "avatars"
+	ResourceAttachments    = "attachments"
+	ResourceStatusUpdates  = "status_updates"
+	ResourceCustomFields   = "custom_fields"
+	ResourceAuditLogEvents = "audit_log_events"
+)
+
+// Config holds extractor configuration
+type Config struct {
+	// Concurrency is the number of projects whose tasks, users whose task
+	// lists or parents whose status updates are fetched in parallel. All workers share the client's
+	// rate limiter.
+	Concurrency int
+
+	// WriteBatchSize is the number of entities handed to a storage
+	// implementing BatchWriter at once (default DefaultWriteBatchSize)
+	WriteBatchSize int
+
+	// Resources selects which extraction phases run. Empty means all but
+	// the optional ResourceUserTaskLists, ResourceAvatars,
+	// ResourceAttachments, ResourceStatusUpdates, ResourceCustomFields and
+	// ResourceAuditLogEvents.
+	Resources []string
+
+	// MaxErrorRate fails a run whose share of failed entities, out of all
+	// entities processed, exceeds it. Zero disables the check.
+	MaxErrorRate float64
+
+	// MaxAPICalls stops a run once it has made this many API calls. What
+	// was stored so far is kept and checkpointed, and the run is marked
+	// partial rather than failed. Zero means no limit.
+	MaxAPICalls int64
+
+	// Reconcile selects what happens to stored entities that are missing from
+	// a complete run: ReconcileOff (default), ReconcileDelete or
+	// ReconcileTombstone. It requires a storage implementing Reconciler.
+	Reconcile string
+
+	// Filters drop entities before they are stored
+	Filters Filters
+
+	// Transformers rewrite entities that passed the Filters before they
+	// are stored
+	Transformers Transformers
+
+	// Verify cross-checks a successful run against Asana
+	Verify Verify
+
+	// Preflight estimates the run's size before it starts, and can abort a
+	// run that would use more API calls than budgeted
+	Preflight Preflight
+
+	// IncrementalTasks fetches only the tasks modified since each project's
+	// watermark from the previous successful run. It needs a storage
+	// implementing CheckpointStore and an IncrementalTaskClient; deleted
+	// tasks are then only reconciled by runs without it.
+	IncrementalTasks bool
+
+	// AuditEventLookback is how far back the first window of audit log
+	// events reaches (default DefaultAuditEventLookback). Later windows
+	// start where the previous successful run's ended, given a storage
+	// implementing CheckpointStore.
+	AuditEventLookback time.Duration
+
+	// AuditDir, when set, receives a <run_id>.jsonl audit log recording every
+	// API call of the run: endpoint, status, latency and retry count.
+	AuditDir string
+
+	// ConfigSnapshot is recorded verbatim in the run manifest. Callers are
+	// responsible for masking secrets.
+	ConfigSnapshot any
+
+	// Progress, when set, receives a progress report every
+	// ProgressInterval (default DefaultProgressInterval) and when the run
+	// ends. ExpectedCounts, such as a previous manifest's counts, estimates
+	// the entities per resource; without it the previous run of this
+	// process is used.
+	Progress         ProgressSink
+	ProgressInterval time.Duration
+	ExpectedCounts   map[string]int
 }
 
+// ErrErrorBudgetExceeded is returned when a run's error rate exceeds
+// Config.MaxErrorRate
+var ErrErrorBudgetExceeded = errors.New("error budget exceeded")
+
 // Extractor orchestrates the extraction process
 type Extractor struct {
 	asanaClient AsanaClient
 	storage     Storage
+	cfg         Config
+	resources   map[string]bool
 }
 
 // New creates a new extractor
-func New(asanaClient AsanaClient, storage Storage) *Extractor {
+func New(asanaClient AsanaClient, storage Storage, cfg Config) *Extractor {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.WriteBatchSize <= 0 {
+		cfg.WriteBatchSize = DefaultWriteBatchSize
+	}
+
+	if len(cfg.Resources) == 0 {
+		cfg.Resources = []string{ResourceUsers, ResourceProjects, ResourceTasks, ResourceTeams}
+	}
+
+	resources := make(map[string]bool, len(cfg.Resources))
+	for _, r := range cfg.Resources {
+		resources[r] = true
+	}
+
 	return &Extractor{
 		asanaClient: asanaClient,
 		storage:     storage,
+		cfg:         cfg,
+		resources:   resources,
 	}
 }
 
-// Extract performs a full extraction of users and projects
+// enabled reports whether the given resource phase was selected
+func (e *Extractor) enabled(resource string) bool {
+	return e.resources[resource]
+}
+
+// walks reports whether the given phase runs. Projects, tasks and users are
+// still walked, without writing, when only the tasks, attachments, status
+// updates, user task lists or avatars they are the entry point for were
+// selected.
+func (e *Extractor) walks(phase string) bool {
+	switch phase {
+	case ResourceProjects:
+		return e.enabled(phase) || e.enabled(ResourceTasks) || e.enabled(ResourceAttachments) || e.enabled(ResourceStatusUpdates)
+	case ResourceTasks:
+		return e.enabled(phase) || e.enabled(ResourceAttachments)
+	case ResourceUsers:
+		return e.enabled(phase) || e.enabled(ResourceUserTaskLists) || e.enabled(ResourceAvatars)
+	}
+	return e.enabled(phase)
+}
+
+// Extract performs a full extraction of the selected resources
 func (e *Extractor) Extract(ctx context.Context) (*Stats, error) {
 	startTime := time.Now()
-	stats := &Stats{}
+	stats := &Stats{
+		RunID:     runIDFor(ctx, startTime),
+		StartedAt: startTime,
+	}
+	// Everything the run logs carries its ID
+	ctx = WithRunID(ctx, stats.RunID)
+	if stamper, ok := e.storage.(RunStamper); ok {
+		stamper.StartRun(stats.RunID)
+	}
+
+	if e.cfg.AuditDir != "" {
+		var closeAudit func() error
+		var err error
+		ctx, closeAudit, err = openAuditLog(ctx, e.cfg.AuditDir, stats.RunID)
+		if err != nil {
+			return stats, err
+		}
+		defer closeAudit()
+	}
+
+	// Tally API usage and bytes written per phase
+	var phases []string
+	for _, phase := range []string{ResourceUsers, ResourceTeams, ResourceProjects, ResourceTasks, ResourceUserTaskLists, ResourceAvatars, ResourceAttachments, ResourceStatusUpdates, ResourceCustomFields, ResourceAuditLogEvents} {
+		if e.walks(phase) {
+			phases = append(phases, phase)
+		}
+	}
+	usage := newPhaseUsage(e.storage, phases)
+
+	if e.reconciling() {
+		stats.live = make(map[string]map[string]struct{})
+	}
+	stats.sample = e.newSampler()
+
+	counter, countsUnchanged := e.storage.(ChangeCounter)
+	var unchangedBefore int64
+	if countsUnchanged {
+		unchangedBefore = counter.Unchanged()
+	}
+
+	ctx, span := tracer.Start(ctx, "extractor.Extract", trace.WithAttributes(
+		attribute.StringSlice("extractor.resources", e.cfg.Resources),
+		attribute.Int("extractor.concurrency", e.cfg.Concurrency),
+		attribute.String("extractor.run_id", stats.RunID),
+	))
+	defer span.End()
+
+	// The limit covers the whole run, pre-flight included
+	if e.cfg.MaxAPICalls > 0 {
+		ctx = client.WithCallBudget(ctx, client.NewCallBudget(e.cfg.MaxAPICalls))
+	}
+
+	// A run over budget fails before it has extracted anything
+	if e.cfg.Preflight.enabled() {
+		estimate, err := e.preflight(ctx)
+		stats.Estimate = estimate
+		if err != nil {
+			stats.Duration = time.Since(startTime)
+			e.recordRun(ctx, e.newManifest(stats, err))
+			return stats, err
+		}
+	}
+
+	// A fatal error in any worker cancels the rest of the run
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	// results channel carries functions to update the stats struct safely
 	results := make(chan func(*Stats), 100)
-	// errChan captures fatal API errors
-	errChan := make(chan error, 2)
+	// errChan captures the first fatal API error; later ones are dropped
+	errChan := make(chan error, 1)
+	fail := func(err error) {
+		select {
+		case errChan <- err:
+		default:
+		}
+		cancel()
+	}
+
+	// The checkpoint is read-only while the run is in progress
+	var cp Checkpoint
+	if e.checkpointing() {
+		cp = e.loadCheckpoint(ctx)
+	}
+	var since map[string]time.Time
+	if e.incremental() && e.enabled(ResourceTasks) {
+		since = cp.TaskWatermarks
+		stats.watermarks = make(map[string]time.Time)
+		stats.watermarked = len(since) > 0
+	}
+	audit := e.nextAuditWindow(stats.RunID, startTime, cp.AuditEventsEnd)
+
+	plan, err := planPhases(e.phases(usage, results, since, audit))
+	if err != nil {
+		return stats, err
+	}
+	plan = selectPhases(plan, e.walks)
 
-	var wg sync.WaitGroup
 	doneProcessing := make(chan struct{})
 
 	// 1. THE ACTOR: Centralized Stats Collector
@@ -66,67 +309,407 @@ func (e *Extractor) Extract(ctx context.Context) (*Stats, error) {
 		close(doneProcessing)
 	}()
 
-	// 2. WORKER: User Extraction & Storage
-	wg.Add(1)
+	progress := e.startProgress(results, e.expectedEntities(), usage.pages)
+
+	// 2. THE PLAN: every phase starts once its dependencies finish, and
+	// independent phases run in parallel. Wait for it in the background so
+	// we can check errChan immediately.
 	go func() {
-		defer wg.Done()
-		users, err := e.asanaClient.GetAllUsers(ctx)
-		if err != nil {
-			errChan <- fmt.Errorf("user API failure: %w", err)
-			return
+		runPlan(runCtx, plan, fail)
+		progress.stopTicking()
+		close(results)
+		close(errChan)
+	}()
+
+	// Block until a worker reports a fatal API error or all workers finish.
+	// A fatal error cancels ctx, so the remaining workers wind down quickly.
+	runErr := <-errChan
+
+	// Wait for the stats collector to finish processing the last updates
+	<-doneProcessing
+
+	// A deadline on the caller's context means the run was cut short
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		stats.TimedOut = true
+	}
+
+	// Running out of API calls stops the run like a fatal error, but what
+	// was stored is kept
+	if errors.Is(runErr, client.ErrCallBudgetExhausted) {
+		logf(ctx, "Stopped at the limit of %d API calls per run; the run is partial", e.cfg.MaxAPICalls)
+		stats.APIBudgetExhausted = true
+		stats.partial = true
+		runErr = nil
+	}
+
+	if runErr == nil {
+		runErr = e.checkErrorBudget(stats)
+	}
+
+	// Orphans can only be identified from a run that saw everything
+	if runErr == nil && !stats.partial && e.reconciling() {
+		e.reconcile(ctx, stats)
+	}
+
+	// Only a run that finished is worth checking for completeness
+	if runErr == nil && !stats.APIBudgetExhausted && e.cfg.Verify.enabled() {
+		stats.Verification = e.verify(ctx, stats, usage)
+	}
+
+	if runErr == nil && e.checkpointing() {
+		e.saveCheckpoint(ctx, cp, stats)
+	}
+
+	stats.Duration = time.Since(startTime)
+	usage.apply(stats)
+	if countsUnchanged {
+		stats.Unchanged = counter.Unchanged() - unchangedBefore
+	}
+
+	logf(ctx, "API quota used: %s", newQuota(stats))
+	e.recordRun(ctx, e.newManifest(stats, runErr))
+	progress.report(stats, true)
+
+	span.SetAttributes(
+		attribute.Int("extractor.users", stats.UsersExtracted),
+		attribute.Int("extractor.projects", stats.ProjectsExtracted),
+		attribute.Int("extractor.tasks", stats.TasksExtracted),
+		attribute.Int("extractor.teams", stats.TeamsExtracted),
+		attribute.Int("extractor.user_task_lists", stats.UserTaskListsExtracted),
+		attribute.Int("extractor.avatars", stats.AvatarsExtracted),
+		attribute.Int("extractor.attachments", stats.AttachmentsExtracted),
+		attribute.Int("extractor.status_updates", stats.StatusUpdatesExtracted),
+		attribute.Int("extractor.custom_fields", stats.CustomFieldsExtracted),
+		attribute.Int("extractor.audit_log_events", stats.AuditLogEventsExtracted),
+		attribute.Int("extractor.errors", stats.Errors),
+		attribute.Int("extractor.skipped", stats.Skipped),
+		attribute.Bool("extractor.timed_out", stats.TimedOut),
+		attribute.Int64("extractor.api_calls", stats.APICalls),
+		attribute.Int64("extractor.retries", stats.Retries),
+		attribute.Int64("extractor.pages", stats.Pages),
+		attribute.Int64("extractor.bytes_written", stats.BytesWritten),
+		attribute.Int64("extractor.unchanged", stats.Unchanged),
+		attribute.Int("extractor.orphaned", stats.Orphaned),
+	)
+	if runErr != nil {
+		span.RecordError(runErr)
+		span.SetStatus(codes.Error, runErr.Error())
+	}
+
+	lastRun.Store(stats)
+	return stats, runErr
+}
+
+// checkErrorBudget fails the run when too many entities could not be stored
+func (e *Extractor) checkErrorBudget(stats *Stats) error {
+	if e.cfg.MaxErrorRate <= 0 {
+		return nil
+	}
+	if rate := stats.ErrorRate(); rate > e.cfg.MaxErrorRate {
+		return fmt.Errorf("%w: %d errors (%.1f%%) exceeds the %.1f%% threshold",
+			ErrErrorBudgetExceeded, stats.Errors, rate*100, e.cfg.MaxErrorRate*100)
+	}
+	return nil
+}
+
+// phases returns the extraction plan: users and teams first, then projects
+// and the task list and avatar of every user, then the tasks of every project,
+// modified since the project's entry in since if it has one, and the status
+// updates of projects, goals and portfolios, then the attachments of the
+// tasks walked. Custom fields and the window
+// of audit log events are read independently. Each phase reports its API
+// usage under its own name.
+func (e *Extractor) phases(usage *phaseUsage, results chan<- func(*Stats), since map[string]time.Time, audit auditWindow) []phase {
+	// Written by the projects, users and tasks phases, read by the phases
+	// after them
+	var projectGIDs []string
+	var users []userRef
+	var tasks *taskGIDs
+	if e.enabled(ResourceAttachments) {
+		tasks = &taskGIDs{}
+	}
+
+	return []phase{
+		{
+			name: ResourceUsers,
+			run: func(ctx context.Context) error {
+				var err error
+				users, err = e.extractUsers(usage.context(ctx, ResourceUsers), results)
+				return err
+			},
+		},
+		{
+			name: ResourceTeams,
+			run: func(ctx context.Context) error {
+				return e.extractTeams(usage.context(ctx, ResourceTeams), results)
+			},
+		},
+		{
+			name:  ResourceProjects,
+			after: []string{ResourceUsers, ResourceTeams},
+			run: func(ctx context.Context) error {
+				var err error
+				projectGIDs, err = e.extractProjects(usage.context(ctx, ResourceProjects), results)
+				return err
+			},
+		},
+		{
+			name:  ResourceTasks,
+			after: []string{ResourceProjects},
+			run: func(ctx context.Context) error {
+				return e.extractTasks(usage.context(ctx, ResourceTasks), results, projectGIDs, since, tasks)
+			},
+		},
+		{
+			name:  ResourceAttachments,
+			after: []string{ResourceTasks},
+			run: func(ctx context.Context) error {
+				return e.extractAttachments(usage.context(ctx, ResourceAttachments), results, tasks.list())
+			},
+		},
+		{
+			name:  ResourceStatusUpdates,
+			after: []string{ResourceProjects},
+			run: func(ctx context.Context) error {
+				return e.extractStatusUpdates(usage.context(ctx, ResourceStatusUpdates), results, projectGIDs)
+			},
+		},
+		{
+			name:  ResourceUserTaskLists,
+			after: []string{ResourceUsers},
+			run: func(ctx context.Context) error {
+				gids := make([]string, len(users))
+				for i, user := range users {
+					gids[i] = user.gid
+				}
+				return e.extractUserTaskLists(usage.context(ctx, ResourceUserTaskLists), results, gids)
+			},
+		},
+		{
+			name:  ResourceAvatars,
+			after: []string{ResourceUsers},
+			run: func(ctx context.Context) error {
+				return e.extractAvatars(usage.context(ctx, ResourceAvatars), results, users)
+			},
+		},
+		{
+			name: ResourceCustomFields,
+			run: func(ctx context.Context) error {
+				return e.extractCustomFields(usage.context(ctx, ResourceCustomFields), results)
+			},
+		},
+		{
+			name: ResourceAuditLogEvents,
+			run: func(ctx context.Context) error {
+				return e.extractAuditEvents(usage.context(ctx, ResourceAuditLogEvents), results, audit)
+			},
+		},
+	}
+}
+
+// userRef is what the user task lists and avatars phases need of a user
+type userRef struct {
+	gid      string
+	photoURL string
+}
+
+// extractUsers streams users into storage when that phase is selected and
+// returns the users whose task lists and avatars should be extracted, as
+// fetched. It collects nothing unless one of those phases is selected.
+func (e *Extractor) extractUsers(ctx context.Context, results chan<- func(*Stats)) ([]userRef, error) {
+	ctx, span := tracer.Start(ctx, "extractor.users")
+	defer span.End()
+
+	collect := e.enabled(ResourceAvatars) || e.enabled(ResourceUserTaskLists)
+	var users []userRef
+	batch := e.userBatch(ctx, results)
+	err := e.asanaClient.StreamUsers(ctx, func(user asana.User) error {
+		if !e.validate(ctx, results, ResourceUsers, &user) {
+			return nil
+		}
+		ref := userRef{gid: user.GID, photoURL: user.Photo.Largest()}
+		// A filtered user's task list and avatar are skipped with it
+		if !e.cfg.Filters.keepUser(user) {
+			results <- func(s *Stats) { s.recordSkip(ResourceUsers) }
+			return nil
 		}
 
-		for _, user := range users {
-			// THE WRITE HAPPENS HERE
-			if err := e.storage.WriteUser(user); err != nil {
-				log.Printf("Error writing user %s: %v", user.GID, err)
-				results <- func(s *Stats) { s.Errors++ }
-				continue
-			}
-			results <- func(s *Stats) { s.UsersExtracted++ }
+		if e.enabled(ResourceUsers) && transform(ctx, &user, e.cfg.Transformers.Users, ResourceUsers, user.GID, results) {
+			// THE WRITE HAPPENS HERE, as each page arrives
+			batch.add(user)
 		}
-	}()
 
-	// 3. WORKER: Project Extraction & Storage
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		projects, err := e.asanaClient.GetAllProjects(ctx)
-		if err != nil {
-			errChan <- fmt.Errorf("project API failure: %w", err)
-			return
+		if collect {
+			users = append(users, ref)
 		}
+		return nil
+	})
+	batch.flush()
+	if err != nil {
+		return nil, fmt.Errorf("user API failure: %w", err)
+	}
+	return users, nil
+}
 
-		for _, project := range projects {
-			// THE WRITE HAPPENS HERE
-			if err := e.storage.WriteProject(project); err != nil {
-				log.Printf("Error writing project %s: %v", project.GID, err)
-				results <- func(s *Stats) { s.Errors++ }
-				continue
-			}
-			results <- func(s *Stats) { s.ProjectsExtracted++ }
+// extractTeams streams teams into storage
+func (e *Extractor) extractTeams(ctx context.Context, results chan<- func(*Stats)) error {
+	ctx, span := tracer.Start(ctx, "extractor.teams")
+	defer span.End()
+	batch := e.teamBatch(ctx, results)
+	err := e.asanaClient.StreamTeams(ctx, func(team asana.Team) error {
+		if e.validate(ctx, results, ResourceTeams, &team) && transform(ctx, &team, e.cfg.Transformers.Teams, ResourceTeams, team.GID, results) {
+			batch.add(team)
 		}
-	}()
+		return nil
+	})
+	batch.flush()
+	if err != nil {
+		return fmt.Errorf("team API failure: %w", err)
+	}
+	return nil
+}
 
-	// 4. COORDINATION
-	// Wait for workers in the background so we can check errChan immediately
-	go func() {
-		wg.Wait()
-		close(results)
-		close(errChan)
-	}()
+// extractProjects streams projects into storage when that phase is selected
+// and returns the GIDs of the projects whose tasks should be extracted
+func (e *Extractor) extractProjects(ctx context.Context, results chan<- func(*Stats)) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "extractor.projects")
+	defer span.End()
 
-	// Check if any worker sent a fatal API error
-	for err := range errChan {
-		if err != nil {
-			stats.Duration = time.Since(startTime)
-			return stats, err
+	var gids []string
+	batch := e.projectBatch(ctx, results)
+	err := e.asanaClient.StreamProjects(ctx, func(project asana.Project) error {
+		if !e.validate(ctx, results, ResourceProjects, &project) {
+			return nil
+		}
+		gid := project.GID
+		// A filtered project's tasks are skipped with it
+		if !e.cfg.Filters.keepProject(project) {
+			results <- func(s *Stats) { s.recordSkip(ResourceProjects) }
+			return nil
+		}
+
+		if e.enabled(ResourceProjects) && transform(ctx, &project, e.cfg.Transformers.Projects, ResourceProjects, project.GID, results) {
+			// THE WRITE HAPPENS HERE, as each page arrives
+			batch.add(project)
 		}
+
+		// Extract the project's tasks even if its own transform or write
+		// failed
+		gids = append(gids, gid)
+		return nil
+	})
+	batch.flush()
+	if err != nil {
+		return nil, fmt.Errorf("project API failure: %w", err)
 	}
+	return gids, nil
+}
 
-	// Wait for the stats collector to finish processing the last updates
-	<-doneProcessing
+// extractTasks streams the tasks of every project into storage, fetching
+// Config.Concurrency projects in parallel. The first fatal error stops the
+// other workers and is returned.
+func (e *Extractor) extractTasks(ctx context.Context, results chan<- func(*Stats), projectGIDs []string, since map[string]time.Time, walked *taskGIDs) error {
+	// Tasks walked only for their attachments are listed in full, since no
+	// watermark says which of them were seen before
+	if !e.enabled(ResourceTasks) {
+		since = nil
+	}
+	return e.fanOut(ctx, projectGIDs, func(ctx context.Context, projectGID string) error {
+		return e.extractProjectTasks(ctx, results, projectGID, since[projectGID], walked)
+	})
+}
 
-	stats.Duration = time.Since(startTime)
-	return stats, nil
+// fanOut calls fn for every GID, Config.Concurrency at a time. The first
+// error stops the other workers and is returned.
+func (e *Extractor) fanOut(ctx context.Context, gids []string, fn func(ctx context.Context, gid string) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	queue := make(chan string)
+	errChan := make(chan error, 1)
+	var wg sync.WaitGroup
+	for i := 0; i < e.cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for gid := range queue {
+				if err := fn(ctx, gid); err != nil {
+					select {
+					case errChan <- err:
+					default:
+					}
+					cancel()
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, gid := range gids {
+		select {
+		case queue <- gid:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	select {
+	case err := <-errChan:
+		return err
+	default:
+		return nil
+	}
+}
+
+// extractProjectTasks streams one project's tasks into storage: all of
+// them, or only those modified since a non-zero since. In incremental runs
+// a project whose tasks were all stored gets a new watermark.
+func (e *Extractor) extractProjectTasks(ctx context.Context, results chan<- func(*Stats), projectGID string, since time.Time, walked *taskGIDs) error {
+	mark, start := since, time.Now()
+	batch := e.taskBatch(ctx, results)
+	onTask := func(task asana.Task) error {
+		if task.ModifiedAt.After(mark) {
+			mark = task.ModifiedAt
+		}
+		if !e.validate(ctx, results, ResourceTasks, &task) {
+			return nil
+		}
+		walked.add(task.GID)
+		if e.enabled(ResourceTasks) && transform(ctx, &task, e.cfg.Transformers.Tasks, ResourceTasks, task.GID, results) {
+			batch.add(task)
+		}
+		return nil
+	}
+
+	var err error
+	if since.IsZero() {
+		err = e.asanaClient.StreamTasks(ctx, projectGID, onTask)
+	} else {
+		err = e.asanaClient.(IncrementalTaskClient).StreamTasksModifiedSince(ctx, projectGID, since, onTask)
+	}
+	batch.flush()
+	// A task that failed to store must be fetched again next time, so the
+	// watermark only moves when every task was stored
+	if err == nil && e.incremental() && e.enabled(ResourceTasks) {
+		if batch.failed {
+			mark = since
+		}
+		if limit := start.Add(-watermarkOverlap); mark.After(limit) {
+			mark = limit
+		}
+		results <- func(s *Stats) { s.setWatermark(projectGID, mark) }
+	}
+	// A project deleted or made private since it was listed only
+	// loses its own tasks; any other failure aborts the run
+	if errors.Is(err, asana.ErrNotFound) || errors.Is(err, asana.ErrForbidden) {
+		logf(ctx, "Skipping tasks of project %s: %v", projectGID, err)
+		results <- func(s *Stats) { s.recordError(ResourceTasks); s.partial = true }
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("task API failure for project %s: %w", projectGID, err)
+	}
+	return nil
 }
@@ -2,30 +2,83 @@ package extractor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/ioanzicu/asana-extractor/pkg/asana"
+	"github.com/ioanzicu/asana-extractor/pkg/client"
 )
 
 type mockAsanaClient struct {
 	users    []asana.User
 	projects []asana.Project
+	tasks    map[string][]asana.Task
+	teams    []asana.Team
 	err      error
+	taskErr  error
 }
 
-func (m *mockAsanaClient) GetAllUsers(ctx context.Context) ([]asana.User, error) {
-	return m.users, m.err
+func (m *mockAsanaClient) StreamUsers(ctx context.Context, fn func(asana.User) error) error {
+	if m.err != nil {
+		return m.err
+	}
+	for _, u := range m.users {
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockAsanaClient) StreamProjects(ctx context.Context, fn func(asana.Project) error) error {
+	if m.err != nil {
+		return m.err
+	}
+	for _, p := range m.projects {
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
 }
-func (m *mockAsanaClient) GetAllProjects(ctx context.Context) ([]asana.Project, error) {
-	return m.projects, m.err
+
+func (m *mockAsanaClient) StreamTasks(ctx context.Context, projectGID string, fn func(asana.Task) error) error {
+	if m.taskErr != nil {
+		return m.taskErr
+	}
+	for _, task := range m.tasks[projectGID] {
+		if err := fn(task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockAsanaClient) StreamTeams(ctx context.Context, fn func(asana.Team) error) error {
+	if m.err != nil {
+		return m.err
+	}
+	for _, team := range m.teams {
+		if err := fn(team); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 type mockStorage struct {
 	mu        sync.Mutex
 	users     []asana.User
 	projects  []asana.Project
+	tasks     []asana.Task
+	teams     []asana.Team
 	failWrite bool
 }
 
@@ -48,16 +101,78 @@ func (m *mockStorage) WriteProject(p asana.Project) error {
 	m.projects = append(m.projects, p)
 	return nil
 }
+
+func (m *mockStorage) WriteTask(task asana.Task) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failWrite {
+		return fmt.Errorf("disk error")
+	}
+	m.tasks = append(m.tasks, task)
+	return nil
+}
+
+func (m *mockStorage) WriteTeam(team asana.Team) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failWrite {
+		return fmt.Errorf("disk error")
+	}
+	m.teams = append(m.teams, team)
+	return nil
+}
+
+// blockingAsanaClient streams nothing until its context is done
+type blockingAsanaClient struct{}
+
+func (blockingAsanaClient) StreamUsers(ctx context.Context, fn func(asana.User) error) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+func (blockingAsanaClient) StreamProjects(ctx context.Context, fn func(asana.Project) error) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+func (blockingAsanaClient) StreamTasks(ctx context.Context, projectGID string, fn func(asana.Task) error) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+func (blockingAsanaClient) StreamTeams(ctx context.Context, fn func(asana.Team) error) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestExtractor_ExtractTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	e := New(blockingAsanaClient{}, &mockStorage{}, Config{Concurrency: 2})
+	stats, err := e.Extract(ctx)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+	if !stats.TimedOut {
+		t.Error("expected stats to record the timeout")
+	}
+}
+
 func TestExtractor_Extract(t *testing.T) {
 	tests := []struct {
 		name             string
 		mockUsers        []asana.User
 		mockProjects     []asana.Project
+		mockTasks        map[string][]asana.Task
+		mockTeams        []asana.Team
+		resources        []string
 		apiError         error
+		taskError        error
 		storageFail      bool
 		expectErr        bool
 		expectedUsers    int
 		expectedProjects int
+		expectedTasks    int
+		expectedTeams    int
 		expectedErrors   int
 	}{
 		{
@@ -68,6 +183,67 @@ func TestExtractor_Extract(t *testing.T) {
 			expectedUsers:    2,
 			expectedProjects: 1,
 		},
+		{
+			name:      "Tasks fanned out across projects",
+			mockUsers: []asana.User{{GID: "u1"}},
+			mockProjects: []asana.Project{
+				{GID: "p1"}, {GID: "p2"}, {GID: "p3"},
+			},
+			mockTasks: map[string][]asana.Task{
+				"p1": {{GID: "t1"}, {GID: "t2"}},
+				"p2": {{GID: "t3"}},
+				"p3": {},
+			},
+			expectErr:        false,
+			expectedUsers:    1,
+			expectedProjects: 3,
+			expectedTasks:    3,
+		},
+		{
+			name:          "Teams extracted alongside users",
+			mockUsers:     []asana.User{{GID: "u1"}},
+			mockTeams:     []asana.Team{{GID: "team1"}, {GID: "team2"}},
+			expectErr:     false,
+			expectedUsers: 1,
+			expectedTeams: 2,
+		},
+		{
+			name:             "Only selected resources are extracted",
+			mockUsers:        []asana.User{{GID: "u1"}},
+			mockProjects:     []asana.Project{{GID: "p1"}},
+			mockTasks:        map[string][]asana.Task{"p1": {{GID: "t1"}}},
+			mockTeams:        []asana.Team{{GID: "team1"}},
+			resources:        []string{ResourceUsers},
+			expectErr:        false,
+			expectedUsers:    1,
+			expectedProjects: 0,
+			expectedTasks:    0,
+			expectedTeams:    0,
+		},
+		{
+			name:             "Tasks without projects still walk projects but skip writing them",
+			mockProjects:     []asana.Project{{GID: "p1"}},
+			mockTasks:        map[string][]asana.Task{"p1": {{GID: "t1"}, {GID: "t2"}}},
+			resources:        []string{ResourceTasks},
+			expectErr:        false,
+			expectedProjects: 0,
+			expectedTasks:    2,
+		},
+		{
+			name:         "Task API failure aborts the run",
+			mockProjects: []asana.Project{{GID: "p1"}},
+			taskError:    fmt.Errorf("connection reset"),
+			expectErr:    true,
+		},
+		{
+			name:             "Inaccessible project is skipped",
+			mockProjects:     []asana.Project{{GID: "p1"}},
+			taskError:        fmt.Errorf("failed to get tasks: %w", asana.ErrForbidden),
+			resources:        []string{ResourceProjects, ResourceTasks},
+			expectErr:        false,
+			expectedProjects: 1,
+			expectedErrors:   1,
+		},
 		{
 			name:      "API failure returns error immediately",
 			apiError:  fmt.Errorf("unauthorized"),
@@ -90,11 +266,14 @@ func TestExtractor_Extract(t *testing.T) {
 			mockClient := &mockAsanaClient{
 				users:    tc.mockUsers,
 				projects: tc.mockProjects,
+				tasks:    tc.mockTasks,
+				teams:    tc.mockTeams,
 				err:      tc.apiError,
+				taskErr:  tc.taskError,
 			}
 			mockStore := &mockStorage{failWrite: tc.storageFail}
 
-			e := New(mockClient, mockStore)
+			e := New(mockClient, mockStore, Config{Concurrency: 2, Resources: tc.resources})
 			stats, err := e.Extract(context.Background())
 
 			if (err != nil) != tc.expectErr {
@@ -108,6 +287,12 @@ func TestExtractor_Extract(t *testing.T) {
 				if stats.ProjectsExtracted != tc.expectedProjects {
 					t.Errorf("expected %d projects, got %d", tc.expectedProjects, stats.ProjectsExtracted)
 				}
+				if stats.TasksExtracted != tc.expectedTasks {
+					t.Errorf("expected %d tasks, got %d", tc.expectedTasks, stats.TasksExtracted)
+				}
+				if stats.TeamsExtracted != tc.expectedTeams {
+					t.Errorf("expected %d teams, got %d", tc.expectedTeams, stats.TeamsExtracted)
+				}
 				if stats.Errors != tc.expectedErrors {
 					t.Errorf("expected %d errors, got %d", tc.expectedErrors, stats.Errors)
 				}
@@ -118,3 +303,265 @@ func TestExtractor_Extract(t *testing.T) {
 		})
 	}
 }
+
+// manifestStorage records manifests and run history in addition to entities
+type manifestStorage struct {
+	mockStorage
+	manifests []Manifest
+	history   []Manifest
+}
+
+func (m *manifestStorage) WriteManifest(manifest any) error {
+	m.manifests = append(m.manifests, manifest.(Manifest))
+	return nil
+}
+
+func (m *manifestStorage) AppendRun(record any) error {
+	m.history = append(m.history, record.(Manifest))
+	return nil
+}
+
+func TestExtractor_Manifest(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientErr    error
+		expectStatus string
+	}{
+		{name: "Succeeded Run", expectStatus: StatusSucceeded},
+		{name: "Failed Run", clientErr: fmt.Errorf("api down"), expectStatus: StatusFailed},
+		{name: "Partial Run", clientErr: fmt.Errorf("user API failure: %w", client.ErrCallBudgetExhausted), expectStatus: StatusPartial},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &manifestStorage{}
+			mockClient := &mockAsanaClient{users: []asana.User{{GID: "u1"}}, err: tc.clientErr}
+
+			e := New(mockClient, store, Config{Resources: []string{ResourceUsers}, ConfigSnapshot: "cfg"})
+			stats, _ := e.Extract(context.Background())
+
+			if len(store.manifests) != 1 {
+				t.Fatalf("expected 1 manifest, got %d", len(store.manifests))
+			}
+			m := store.manifests[0]
+			if m.Status != tc.expectStatus {
+				t.Errorf("expected status %s, got %s", tc.expectStatus, m.Status)
+			}
+			if m.RunID == "" || m.RunID != stats.RunID {
+				t.Errorf("manifest run ID %q does not match stats %q", m.RunID, stats.RunID)
+			}
+			if m.Config != "cfg" {
+				t.Errorf("expected config snapshot to be recorded, got %v", m.Config)
+			}
+			if tc.clientErr == nil && m.Counts[ResourceUsers] != 1 {
+				t.Errorf("expected 1 user in counts, got %d", m.Counts[ResourceUsers])
+			}
+			if tc.expectStatus == StatusFailed && m.Error == "" {
+				t.Error("expected failure reason in manifest")
+			}
+
+			if len(store.history) != 1 || store.history[0].RunID != m.RunID {
+				t.Fatalf("expected run in history, got %+v", store.history)
+			}
+			if store.history[0].Status != tc.expectStatus || store.history[0].Config != nil {
+				t.Errorf("unexpected history record: %+v", store.history[0])
+			}
+		})
+	}
+}
+
+// reconcilingStorage records the live sets handed to Reconcile
+type reconcilingStorage struct {
+	mockStorage
+	live map[string]map[string]struct{}
+}
+
+func (m *reconcilingStorage) Reconcile(resource string, live map[string]struct{}, tombstone bool) (int, error) {
+	if m.live == nil {
+		m.live = make(map[string]map[string]struct{})
+	}
+	m.live[resource] = live
+	return 1, nil
+}
+
+func TestExtractor_Reconcile(t *testing.T) {
+	tests := []struct {
+		name            string
+		mode            string
+		clientErr       error
+		expectReconcile bool
+	}{
+		{name: "Disabled By Default", mode: "", expectReconcile: false},
+		{name: "Tombstone After Complete Run", mode: ReconcileTombstone, expectReconcile: true},
+		{name: "Skipped After Failed Run", mode: ReconcileDelete, clientErr: fmt.Errorf("api down"), expectReconcile: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &reconcilingStorage{}
+			mockClient := &mockAsanaClient{
+				users: []asana.User{{GID: "u1"}, {GID: "u2"}},
+				teams: []asana.Team{{GID: "t1"}},
+				err:   tc.clientErr,
+			}
+
+			e := New(mockClient, store, Config{Resources: []string{ResourceUsers, ResourceTeams}, Reconcile: tc.mode})
+			stats, _ := e.Extract(context.Background())
+
+			if (store.live != nil) != tc.expectReconcile {
+				t.Fatalf("expected reconcile: %v, got live sets %v", tc.expectReconcile, store.live)
+			}
+			if !tc.expectReconcile {
+				return
+			}
+			if len(store.live[ResourceUsers]) != 2 || len(store.live[ResourceTeams]) != 1 {
+				t.Errorf("unexpected live sets: %v", store.live)
+			}
+			if _, ok := store.live[ResourceProjects]; ok {
+				t.Error("unselected resources must not be reconciled")
+			}
+			if stats.Orphaned != 2 {
+				t.Errorf("expected 2 orphans, got %d", stats.Orphaned)
+			}
+		})
+	}
+}
+
+func TestExtractor_ErrorBudget(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxErrorRate float64
+		expectErr    bool
+	}{
+		{name: "Disabled", maxErrorRate: 0, expectErr: false},
+		{name: "Within budget", maxErrorRate: 1, expectErr: false},
+		{name: "Exceeded", maxErrorRate: 0.5, expectErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient := &mockAsanaClient{users: []asana.User{{GID: "u1"}, {GID: "u2"}}}
+			store := &manifestStorage{mockStorage: mockStorage{failWrite: true}}
+
+			e := New(mockClient, store, Config{Resources: []string{ResourceUsers}, MaxErrorRate: tc.maxErrorRate})
+			stats, err := e.Extract(context.Background())
+
+			if errors.Is(err, ErrErrorBudgetExceeded) != tc.expectErr {
+				t.Fatalf("expected budget error: %v, got: %v", tc.expectErr, err)
+			}
+			if stats.ErrorRate() != 1 {
+				t.Errorf("expected error rate 1, got %v", stats.ErrorRate())
+			}
+			if tc.expectErr && store.manifests[0].Status != StatusFailed {
+				t.Error("expected manifest to record the failed run")
+			}
+		})
+	}
+}
+
+func TestExtractor_AuditLog(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "audit")
+	mockClient := &mockAsanaClient{users: []asana.User{{GID: "u1"}}}
+
+	e := New(mockClient, &mockStorage{}, Config{Resources: []string{ResourceUsers}, AuditDir: dir})
+	stats, err := e.Extract(context.Background())
+	if err != nil {
+		t.Fatalf("Extract() failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, stats.RunID+".jsonl")); err != nil {
+		t.Errorf("expected audit log for run %s: %v", stats.RunID, err)
+	}
+}
+
+func TestExtractor_WithRunID(t *testing.T) {
+	mockClient := &mockAsanaClient{users: []asana.User{{GID: "u1"}}}
+	e := New(mockClient, &mockStorage{}, Config{Resources: []string{ResourceUsers}})
+
+	runID := NewRunID()
+	stats, err := e.Extract(WithRunID(context.Background(), runID))
+	if err != nil {
+		t.Fatalf("Extract() failed: %v", err)
+	}
+	if stats.RunID != runID {
+		t.Errorf("expected run ID %s, got %s", runID, stats.RunID)
+	}
+
+	stats, _ = e.Extract(context.Background())
+	if stats.RunID == "" || stats.RunID == runID {
+		t.Errorf("expected a fresh run ID, got %q", stats.RunID)
+	}
+}
+
+func TestExtractor_ExtractUsersCollects(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources []string
+		expect    []userRef
+	}{
+		{name: "Users only", resources: []string{ResourceUsers}, expect: nil},
+		{name: "Avatars", resources: []string{ResourceAvatars}, expect: []userRef{{gid: "u1", photoURL: "https://cdn/u1_1024.png"}, {gid: "u2"}}},
+		{name: "User task lists", resources: []string{ResourceUsers, ResourceUserTaskLists}, expect: []userRef{{gid: "u1", photoURL: "https://cdn/u1_1024.png"}, {gid: "u2"}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient := &mockAsanaClient{users: []asana.User{
+				{GID: "u1", Name: "Ada", Photo: &asana.Photo{Image128x128: "https://cdn/u1_128.png", Image1024x1024: "https://cdn/u1_1024.png"}},
+				{GID: "u2", Name: "Grace"},
+			}}
+			e := New(mockClient, &mockStorage{}, Config{Resources: tc.resources})
+
+			results := make(chan func(*Stats), 10)
+			users, err := e.extractUsers(context.Background(), results)
+			if err != nil {
+				t.Fatalf("extractUsers() failed: %v", err)
+			}
+			if !reflect.DeepEqual(users, tc.expect) {
+				t.Errorf("expected %+v, got %+v", tc.expect, users)
+			}
+		})
+	}
+}
+
+// stampingStorage records the run IDs it is started with
+type stampingStorage struct {
+	*mockStorage
+	runs []string
+}
+
+func (s *stampingStorage) StartRun(runID string) {
+	s.runs = append(s.runs, runID)
+}
+
+func TestExtractor_RunStamper(t *testing.T) {
+	mockClient := &mockAsanaClient{users: []asana.User{{GID: "u1"}}}
+	store := &stampingStorage{mockStorage: &mockStorage{}}
+	e := New(mockClient, store, Config{Resources: []string{ResourceUsers}})
+
+	stats, err := e.Extract(context.Background())
+	if err != nil {
+		t.Fatalf("Extract() failed: %v", err)
+	}
+	if len(store.runs) != 1 || store.runs[0] != stats.RunID {
+		t.Errorf("expected the storage to be started with run %s, got %v", stats.RunID, store.runs)
+	}
+}
+
+func TestNewRunID(t *testing.T) {
+	// The example of the ULID specification
+	now := time.UnixMilli(1469918176385)
+	id := newRunID(now)
+	if len(id) != 26 || id[:10] != "01ARYZ6S41" {
+		t.Errorf("newRunID() = %s, want 26 characters starting with 01ARYZ6S41", id)
+	}
+	if strings.Trim(id, crockford) != "" {
+		t.Errorf("newRunID() = %s, want only Crockford base32", id)
+	}
+	if later := newRunID(now.Add(time.Millisecond)); later <= id {
+		t.Errorf("expected %s to sort after %s", later, id)
+	}
+	if other := newRunID(now); other == id {
+		t.Errorf("expected distinct IDs in the same millisecond, got %s twice", id)
+	}
+}
@@ -0,0 +1,57 @@
+package extractor
+
+import (
+	"strings"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// Filters select which entities are stored. Rejected entities are not
+// written and are counted as skipped in Stats. When reconciling, entities
+// a filter starts rejecting are treated as gone from Asana.
+type Filters struct {
+	// SkipArchivedProjects drops archived projects along with their tasks
+	SkipArchivedProjects bool
+	// ProjectTeams keeps only projects of these teams, given by GID or
+	// case-insensitive name, along with their tasks. Empty keeps all.
+	ProjectTeams []string
+	// UserEmailDomains keeps only users with an email address in one of
+	// these domains, such as "example.com". Empty keeps all.
+	UserEmailDomains []string
+}
+
+// keepProject reports whether project passes the filters
+func (f Filters) keepProject(project asana.Project) bool {
+	if f.SkipArchivedProjects && project.Archived {
+		return false
+	}
+	if len(f.ProjectTeams) == 0 {
+		return true
+	}
+	if project.Team == nil {
+		return false
+	}
+	for _, team := range f.ProjectTeams {
+		if team == project.Team.GID || strings.EqualFold(team, project.Team.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// keepUser reports whether user passes the filters
+func (f Filters) keepUser(user asana.User) bool {
+	if len(f.UserEmailDomains) == 0 {
+		return true
+	}
+	_, domain, ok := strings.Cut(user.Email, "@")
+	if !ok {
+		return false
+	}
+	for _, d := range f.UserEmailDomains {
+		if strings.EqualFold(strings.TrimPrefix(d, "@"), domain) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package extractor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestFilters(t *testing.T) {
+	eng := &asana.Team{GID: "t1", Name: "Engineering"}
+
+	tests := []struct {
+		name    string
+		filters Filters
+		project asana.Project
+		user    asana.User
+		keep    bool
+	}{
+		{name: "No filters keep projects", project: asana.Project{Archived: true}, keep: true},
+		{name: "Archived project skipped", filters: Filters{SkipArchivedProjects: true}, project: asana.Project{Archived: true}, keep: false},
+		{name: "Active project kept", filters: Filters{SkipArchivedProjects: true}, project: asana.Project{}, keep: true},
+		{name: "Team by GID", filters: Filters{ProjectTeams: []string{"t1"}}, project: asana.Project{Team: eng}, keep: true},
+		{name: "Team by name", filters: Filters{ProjectTeams: []string{"engineering"}}, project: asana.Project{Team: eng}, keep: true},
+		{name: "Other team", filters: Filters{ProjectTeams: []string{"t2"}}, project: asana.Project{Team: eng}, keep: false},
+		{name: "Project without team", filters: Filters{ProjectTeams: []string{"t1"}}, project: asana.Project{}, keep: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.filters.keepProject(tc.project); got != tc.keep {
+				t.Errorf("keepProject() = %t, want %t", got, tc.keep)
+			}
+		})
+	}
+
+	userTests := []struct {
+		name    string
+		domains []string
+		email   string
+		keep    bool
+	}{
+		{name: "No domains keep all", email: "ada@gmail.com", keep: true},
+		{name: "Company domain", domains: []string{"example.com"}, email: "Ada@Example.com", keep: true},
+		{name: "Leading at sign", domains: []string{"@example.com"}, email: "ada@example.com", keep: true},
+		{name: "Other domain", domains: []string{"example.com"}, email: "ada@gmail.com", keep: false},
+		{name: "Subdomain is not the domain", domains: []string{"example.com"}, email: "ada@mail.example.com", keep: false},
+		{name: "Hidden email", domains: []string{"example.com"}, email: "", keep: false},
+	}
+
+	for _, tc := range userTests {
+		t.Run(tc.name, func(t *testing.T) {
+			f := Filters{UserEmailDomains: tc.domains}
+			if got := f.keepUser(asana.User{Email: tc.email}); got != tc.keep {
+				t.Errorf("keepUser(%q) = %t, want %t", tc.email, got, tc.keep)
+			}
+		})
+	}
+}
+
+func TestExtractor_Filters(t *testing.T) {
+	client := &mockAsanaClient{
+		users: []asana.User{{GID: "u1", Email: "ada@example.com"}, {GID: "u2", Email: "bob@gmail.com"}},
+		projects: []asana.Project{
+			{GID: "p1"},
+			{GID: "p2", Archived: true},
+		},
+		tasks: map[string][]asana.Task{
+			"p1": {{GID: "t1"}},
+			"p2": {{GID: "t2"}, {GID: "t3"}},
+		},
+	}
+	store := &mockStorage{}
+	ext := New(client, store, Config{
+		Resources: []string{ResourceUsers, ResourceProjects, ResourceTasks},
+		Filters:   Filters{SkipArchivedProjects: true, UserEmailDomains: []string{"example.com"}},
+	})
+
+	stats, err := ext.Extract(context.Background())
+	if err != nil {
+		t.Fatalf("Extract() failed: %v", err)
+	}
+
+	if len(store.users) != 1 || len(store.projects) != 1 || len(store.tasks) != 1 {
+		t.Errorf("Expected 1 user, project and task stored, got %d, %d, %d", len(store.users), len(store.projects), len(store.tasks))
+	}
+	if stats.Skipped != 2 || stats.Resources[ResourceUsers].Skipped != 1 || stats.Resources[ResourceProjects].Skipped != 1 {
+		t.Errorf("Expected 1 user and 1 project skipped, got %d (%+v)", stats.Skipped, stats.Resources)
+	}
+}
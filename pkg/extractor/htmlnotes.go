@@ -0,0 +1,326 @@
+package extractor
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// allowedTags are the rich text tags of Asana's html_notes dialect kept by
+// SanitizeHTMLNotes. Other tags are dropped, keeping their text.
+var allowedTags = map[string]bool{
+	"body": true, "strong": true, "em": true, "u": true, "s": true, "code": true, "pre": true,
+	"h1": true, "h2": true, "ol": true, "ul": true, "li": true, "a": true, "blockquote": true, "hr": true,
+}
+
+// droppedTags are dropped together with their content
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "object": true, "iframe": true, "embed": true, "template": true,
+}
+
+// SanitizeHTMLNotes is a Transformer reducing a task's html_notes to
+// Asana's rich text tags, without attributes other than the http, https or
+// mailto href of links. Scripts, styles and embeds are removed with their
+// content, and unclosed tags are closed.
+func SanitizeHTMLNotes(task asana.Task) (asana.Task, error) {
+	if task.HTMLNotes != "" {
+		task.HTMLNotes = sanitizeHTML(task.HTMLNotes)
+	}
+	return task, nil
+}
+
+// MarkdownHTMLNotes is a Transformer replacing a task's html_notes with
+// notes_markdown, the same rich text as CommonMark
+func MarkdownHTMLNotes(task asana.Task) (asana.Task, error) {
+	if task.HTMLNotes != "" {
+		task.NotesMarkdown = htmlToMarkdown(task.HTMLNotes)
+		task.HTMLNotes = ""
+	}
+	return task, nil
+}
+
+// sanitizeHTML returns s with only allowedTags and safe links
+func sanitizeHTML(s string) string {
+	var b strings.Builder
+	var open []string
+	skip := 0
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			for i := len(open) - 1; i >= 0; i-- {
+				b.WriteString("</" + open[i] + ">")
+			}
+			return b.String()
+		case html.TextToken:
+			if skip == 0 {
+				b.WriteString(html.EscapeString(string(z.Text())))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if droppedTags[tok.Data] {
+				if tt == html.StartTagToken {
+					skip++
+				}
+				continue
+			}
+			if skip > 0 || !allowedTags[tok.Data] {
+				continue
+			}
+			if tok.Data == "hr" {
+				b.WriteString("<hr/>")
+				continue
+			}
+			b.WriteString("<" + tok.Data)
+			if href, ok := linkTarget(tok); ok {
+				b.WriteString(` href="` + html.EscapeString(href) + `"`)
+			}
+			b.WriteString(">")
+			open = append(open, tok.Data)
+		case html.EndTagToken:
+			tok := z.Token()
+			if droppedTags[tok.Data] {
+				skip = max(skip-1, 0)
+				continue
+			}
+			if skip > 0 {
+				continue
+			}
+			// Close the tag and any left open inside it
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] == tok.Data {
+					for j := len(open) - 1; j >= i; j-- {
+						b.WriteString("</" + open[j] + ">")
+					}
+					open = open[:i]
+					break
+				}
+			}
+		}
+	}
+}
+
+// linkTarget returns the href of an a tag when it is an http, https or
+// mailto URL
+func linkTarget(tok html.Token) (string, bool) {
+	if tok.Data != "a" {
+		return "", false
+	}
+	for _, attr := range tok.Attr {
+		if attr.Key != "href" {
+			continue
+		}
+		u, err := url.Parse(strings.TrimSpace(attr.Val))
+		if err != nil {
+			return "", false
+		}
+		switch strings.ToLower(u.Scheme) {
+		case "http", "https", "mailto":
+			return u.String(), true
+		}
+		return "", false
+	}
+	return "", false
+}
+
+// markdownEscaper escapes the text characters Markdown would interpret,
+// including raw HTML
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "~", `\~`,
+)
+
+// blankLines matches runs of blank lines, collapsed to one
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// markdownWriter builds Markdown line by line, prefixing every line with
+// the open block quotes
+type markdownWriter struct {
+	b         strings.Builder
+	quote     int
+	lists     []int // per open list: 0 for bullets, else the next number
+	links     []string
+	pre       int
+	lineEmpty bool
+}
+
+func (w *markdownWriter) write(s string) {
+	w.b.WriteString(s)
+	w.lineEmpty = false
+}
+
+func (w *markdownWriter) newline() {
+	w.b.WriteString("\n" + strings.Repeat("> ", w.quote))
+	w.lineEmpty = true
+}
+
+// breakLine starts a new line unless the current one is empty
+func (w *markdownWriter) breakLine() {
+	if !w.lineEmpty {
+		w.newline()
+	}
+}
+
+// htmlToMarkdown converts Asana rich text to CommonMark. Tags without a
+// Markdown equivalent keep only their text.
+func htmlToMarkdown(s string) string {
+	w := &markdownWriter{lineEmpty: true}
+	skip := 0
+	z := html.NewTokenizer(strings.NewReader(s))
+	for tt := z.Next(); tt != html.ErrorToken; tt = z.Next() {
+		switch tt {
+		case html.TextToken:
+			if skip == 0 {
+				w.text(string(z.Text()))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if droppedTags[tok.Data] {
+				if tt == html.StartTagToken {
+					skip++
+				}
+				continue
+			}
+			if skip == 0 {
+				w.start(tok)
+			}
+		case html.EndTagToken:
+			tok := z.Token()
+			if droppedTags[tok.Data] {
+				skip = max(skip-1, 0)
+				continue
+			}
+			if skip == 0 {
+				w.end(tok.Data)
+			}
+		}
+	}
+
+	lines := strings.Split(w.b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// text writes text, escaped outside code blocks
+func (w *markdownWriter) text(s string) {
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			w.newline()
+		}
+		if line == "" {
+			continue
+		}
+		if w.pre > 0 {
+			w.write(line)
+		} else {
+			w.write(markdownEscaper.Replace(line))
+		}
+	}
+}
+
+func (w *markdownWriter) start(tok html.Token) {
+	switch tok.Data {
+	case "strong", "b":
+		w.write("**")
+	case "em", "i":
+		w.write("_")
+	case "s", "strike", "del":
+		w.write("~~")
+	case "code":
+		if w.pre == 0 {
+			w.write("`")
+		}
+	case "pre":
+		w.breakLine()
+		w.write("```")
+		w.newline()
+		w.pre++
+	case "h1":
+		w.breakLine()
+		w.write("# ")
+	case "h2":
+		w.breakLine()
+		w.write("## ")
+	case "blockquote":
+		w.breakLine()
+		w.quote++
+		w.b.WriteString("> ")
+	case "ul":
+		w.breakLine()
+		w.lists = append(w.lists, 0)
+	case "ol":
+		w.breakLine()
+		w.lists = append(w.lists, 1)
+	case "li":
+		w.breakLine()
+		marker := "- "
+		if n := len(w.lists); n > 0 && w.lists[n-1] > 0 {
+			marker = strconv.Itoa(w.lists[n-1]) + ". "
+			w.lists[n-1]++
+		}
+		w.write(strings.Repeat("  ", max(len(w.lists)-1, 0)) + marker)
+	case "a":
+		href, _ := linkTarget(tok)
+		w.links = append(w.links, href)
+		if href != "" {
+			w.write("[")
+		}
+	case "hr":
+		w.breakLine()
+		w.write("---")
+		w.newline()
+	case "br":
+		w.newline()
+	}
+}
+
+func (w *markdownWriter) end(tag string) {
+	switch tag {
+	case "strong", "b":
+		w.write("**")
+	case "em", "i":
+		w.write("_")
+	case "s", "strike", "del":
+		w.write("~~")
+	case "code":
+		if w.pre == 0 {
+			w.write("`")
+		}
+	case "pre":
+		if w.pre > 0 {
+			w.pre--
+			w.breakLine()
+			w.write("```")
+			w.newline()
+		}
+	case "h1", "h2":
+		w.newline()
+	case "blockquote":
+		if w.quote > 0 {
+			w.quote--
+			w.newline()
+		}
+	case "ul", "ol":
+		if n := len(w.lists); n > 0 {
+			w.lists = w.lists[:n-1]
+		}
+		if len(w.lists) == 0 {
+			w.newline()
+		}
+	case "a":
+		if n := len(w.links); n > 0 {
+			href := w.links[n-1]
+			w.links = w.links[:n-1]
+			if href != "" {
+				w.write("](" + strings.NewReplacer("(", "%28", ")", "%29", " ", "%20").Replace(href) + ")")
+			}
+		}
+	}
+}
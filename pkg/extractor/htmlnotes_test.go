@@ -0,0 +1,107 @@
+package extractor
+
+import (
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestSanitizeHTMLNotes(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "rich text is kept",
+			in:   "<body>Ship <strong>it</strong>\n<ul><li>one</li></ul></body>",
+			want: "<body>Ship <strong>it</strong>\n<ul><li>one</li></ul></body>",
+		},
+		{
+			name: "mention attributes are dropped",
+			in:   `<body><a href="https://app.asana.com/0/1/2" data-asana-gid="2" data-asana-type="user">@Ann</a></body>`,
+			want: `<body><a href="https://app.asana.com/0/1/2">@Ann</a></body>`,
+		},
+		{
+			name: "unsafe links lose their target",
+			in:   `<body><a href="javascript:alert(1)">x</a></body>`,
+			want: `<body><a>x</a></body>`,
+		},
+		{
+			name: "scripts and embeds are removed",
+			in:   `<body>a<script>alert("x")</script><object data="y">z</object><img src="i.png" onerror="x">b</body>`,
+			want: "<body>ab</body>",
+		},
+		{
+			name: "unknown tags keep their text",
+			in:   `<body><span style="color:red">red &amp; bold</span></body>`,
+			want: "<body>red &amp; bold</body>",
+		},
+		{
+			name: "unclosed tags are closed",
+			in:   "<body><em>open <strong>nested</body>",
+			want: "<body><em>open <strong>nested</strong></em></body>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := SanitizeHTMLNotes(asana.Task{HTMLNotes: tt.in})
+			if err != nil {
+				t.Fatalf("SanitizeHTMLNotes() failed: %v", err)
+			}
+			if task.HTMLNotes != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, task.HTMLNotes)
+			}
+		})
+	}
+}
+
+func TestMarkdownHTMLNotes(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "inline formatting",
+			in:   "<body>Ship <strong>it</strong>, <em>now</em> <s>later</s> with <code>make</code></body>",
+			want: "Ship **it**, _now_ ~~later~~ with `make`",
+		},
+		{
+			name: "headings and lists",
+			in:   "<body><h1>Plan</h1>Steps\n<ol><li>one</li><li>two<ul><li>nested</li></ul></li></ol>Done</body>",
+			want: "# Plan\nSteps\n1. one\n2. two\n  - nested\nDone",
+		},
+		{
+			name: "links",
+			in:   `<body>See <a href="https://example.com/a b">docs</a> or <a href="javascript:x">this</a></body>`,
+			want: "See [docs](https://example.com/a%20b) or this",
+		},
+		{
+			name: "quotes and code blocks",
+			in:   "<body><blockquote>quoted\nline</blockquote><pre>x := *p\n</pre></body>",
+			want: "> quoted\n> line\n```\nx := *p\n```",
+		},
+		{
+			name: "text is escaped",
+			in:   "<body>a_b *c* &lt;script&gt;</body>",
+			want: `a\_b \*c\* \<script\>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := MarkdownHTMLNotes(asana.Task{HTMLNotes: tt.in})
+			if err != nil {
+				t.Fatalf("MarkdownHTMLNotes() failed: %v", err)
+			}
+			if task.NotesMarkdown != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, task.NotesMarkdown)
+			}
+			if task.HTMLNotes != "" {
+				t.Error("Expected html_notes to be replaced")
+			}
+		})
+	}
+}
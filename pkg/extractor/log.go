@@ -0,0 +1,15 @@
+package extractor
+
+import (
+	"context"
+	"log"
+)
+
+// logf logs a line of the run in ctx, prefixed with its run ID so the lines
+// of overlapping runs, as in serve mode, can be told apart
+func logf(ctx context.Context, format string, args ...any) {
+	if id, ok := ctx.Value(runIDKey{}).(string); ok && id != "" {
+		format = "[run " + id + "] " + format
+	}
+	log.Printf(format, args...)
+}
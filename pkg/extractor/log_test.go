@@ -0,0 +1,26 @@
+package extractor
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestLogf(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	flags := log.Flags()
+	log.SetFlags(0)
+	defer log.SetFlags(flags)
+
+	logf(context.Background(), "plain %d", 1)
+	logf(WithRunID(context.Background(), "r1"), "stamped %d", 2)
+
+	want := "plain 1\n[run r1] stamped 2\n"
+	if got := buf.String(); got != want {
+		t.Errorf("logf() wrote %q, want %q", got, want)
+	}
+}
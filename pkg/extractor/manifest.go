@@ -0,0 +1,187 @@
+package extractor
+
+import (
+	"context"
+	"crypto/rand"
+	"time"
+)
+
+// Run outcomes recorded in the manifest
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	// StatusPartial marks a run stopped by Config.MaxAPICalls: what it
+	// stored is kept, but it did not see everything
+	StatusPartial = "partial"
+)
+
+// Manifest describes a single extraction run so downstream jobs can verify a
+// snapshot's completeness before loading it
+type Manifest struct {
+	RunID      string         `json:"run_id"`
+	Status     string         `json:"status"`
+	StartedAt  time.Time      `json:"started_at"`
+	FinishedAt time.Time      `json:"finished_at"`
+	Duration   string         `json:"duration"`
+	Resources  []string       `json:"resources"`
+	Counts     map[string]int `json:"counts"`
+	APICalls   int64          `json:"api_calls"`
+	Unchanged  int64          `json:"unchanged"`
+	Orphaned   int            `json:"orphaned"`
+	Errors     int            `json:"errors"`
+	Skipped    int            `json:"skipped"`
+	Error      string         `json:"error,omitempty"`
+	TimedOut   bool           `json:"timed_out,omitempty"`
+	Config     any            `json:"config,omitempty"`
+	// Suspect flags a snapshot whose counts diverge from Asana; see
+	// Verification for the checks
+	Suspect      bool          `json:"suspect"`
+	Verification *Verification `json:"verification,omitempty"`
+	// Estimate is the pre-flight estimate, to compare with the actual
+	// counts and API calls
+	Estimate *Estimate `json:"estimate,omitempty"`
+	// Quota reports the run's use of the API quota
+	Quota Quota `json:"quota"`
+
+	// Phases is the per-phase breakdown of the run
+	Phases map[string]*ResourceStats `json:"phases"`
+}
+
+// ManifestWriter is implemented by storage backends that can persist run
+// manifests. Backends without it simply skip the manifest.
+type ManifestWriter interface {
+	WriteManifest(manifest any) error
+}
+
+// RunStamper is implemented by storage backends that stamp every record
+// they write with the ID of the run writing it, so rows loaded elsewhere can
+// be traced back to that run. StartRun is called before the first write.
+type RunStamper interface {
+	StartRun(runID string)
+}
+
+// RunRecorder is implemented by storage backends that keep a history of
+// runs. Each finished run is appended as one record.
+type RunRecorder interface {
+	AppendRun(record any) error
+}
+
+// recordRun persists the manifest and appends the run to the history log,
+// for backends that support them. Failures are logged, not returned, so they
+// never mask the outcome of the run itself.
+func (e *Extractor) recordRun(ctx context.Context, m Manifest) {
+	if mw, ok := e.storage.(ManifestWriter); ok {
+		if err := mw.WriteManifest(m); err != nil {
+			logf(ctx, "Error writing manifest: %v", err)
+		}
+	}
+
+	if rr, ok := e.storage.(RunRecorder); ok {
+		// The configuration rarely changes between runs; keep history compact
+		m.Config = nil
+		if err := rr.AppendRun(m); err != nil {
+			logf(ctx, "Error recording run in history: %v", err)
+		}
+	}
+}
+
+// newManifest builds the manifest for a finished run
+func (e *Extractor) newManifest(stats *Stats, runErr error) Manifest {
+	m := Manifest{
+		RunID:      stats.RunID,
+		Status:     StatusSucceeded,
+		StartedAt:  stats.StartedAt,
+		FinishedAt: stats.StartedAt.Add(stats.Duration),
+		Duration:   stats.Duration.String(),
+		Resources:  e.cfg.Resources,
+		Counts: map[string]int{
+			ResourceUsers:    stats.UsersExtracted,
+			ResourceProjects: stats.ProjectsExtracted,
+			ResourceTasks:    stats.TasksExtracted,
+			ResourceTeams:    stats.TeamsExtracted,
+		},
+		APICalls:  stats.APICalls,
+		Unchanged: stats.Unchanged,
+		Orphaned:  stats.Orphaned,
+		Errors:    stats.Errors,
+		Skipped:   stats.Skipped,
+		TimedOut:  stats.TimedOut,
+		Config:    e.cfg.ConfigSnapshot,
+		Phases:    stats.Resources,
+	}
+
+	// Optional resources are only counted when selected
+	for _, resource := range []string{ResourceUserTaskLists, ResourceAvatars, ResourceAttachments, ResourceStatusUpdates, ResourceCustomFields, ResourceAuditLogEvents} {
+		if e.enabled(resource) {
+			m.Counts[resource] = extractedCount(stats, resource)
+		}
+	}
+
+	if stats.Verification != nil {
+		m.Suspect = stats.Verification.Suspect
+		m.Verification = stats.Verification
+	}
+
+	m.Estimate = stats.Estimate
+	m.Quota = newQuota(stats)
+
+	if stats.APIBudgetExhausted {
+		m.Status = StatusPartial
+	}
+	if runErr != nil {
+		m.Status = StatusFailed
+		m.Error = runErr.Error()
+	}
+
+	return m
+}
+
+// runIDKey is the context key for a run ID assigned by the caller
+type runIDKey struct{}
+
+// WithRunID returns a context under which Extract uses id, from NewRunID,
+// as its run ID. Callers starting a run asynchronously can report its ID
+// before it begins.
+func WithRunID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, runIDKey{}, id)
+}
+
+// NewRunID returns a new run ID for use with WithRunID
+func NewRunID() string {
+	return newRunID(time.Now())
+}
+
+// runIDFor returns the run ID assigned through ctx, or a new one
+func runIDFor(ctx context.Context, now time.Time) string {
+	if id, ok := ctx.Value(runIDKey{}).(string); ok && id != "" {
+		return id
+	}
+	return newRunID(now)
+}
+
+// crockford is the Crockford base32 alphabet ULIDs are written in
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newRunID returns a ULID for a run: 26 characters encoding the start time
+// in milliseconds followed by 80 random bits, so IDs sort by start time
+func newRunID(now time.Time) string {
+	var b [16]byte
+	ms := uint64(now.UnixMilli())
+	for i := range 6 {
+		b[i] = byte(ms >> (40 - 8*i))
+	}
+	rand.Read(b[6:])
+
+	// 128 bits in 26 characters of 5 bits leave 2 leading zero bits
+	hi := uint64(b[0])<<56 | uint64(b[1])<<48 | uint64(b[2])<<40 | uint64(b[3])<<32 |
+		uint64(b[4])<<24 | uint64(b[5])<<16 | uint64(b[6])<<8 | uint64(b[7])
+	lo := uint64(b[8])<<56 | uint64(b[9])<<48 | uint64(b[10])<<40 | uint64(b[11])<<32 |
+		uint64(b[12])<<24 | uint64(b[13])<<16 | uint64(b[14])<<8 | uint64(b[15])
+	var id [26]byte
+	for i := 25; i >= 0; i-- {
+		id[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(id[:])
+}
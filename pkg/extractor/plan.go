@@ -0,0 +1,107 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// phase is one node of the extraction plan. It starts once every phase
+// listed in after has finished and runs in parallel with the phases it does
+// not depend on. Dependencies on phases left out of a run are ignored.
+type phase struct {
+	name  string
+	after []string
+	run   func(ctx context.Context) error
+}
+
+// planPhases orders phases so that each comes after its dependencies,
+// keeping the given order among independent phases. It rejects duplicate
+// names, dependencies on phases not in the list and cycles.
+func planPhases(phases []phase) ([]phase, error) {
+	byName := make(map[string]int, len(phases))
+	for i, p := range phases {
+		if _, dup := byName[p.name]; dup {
+			return nil, fmt.Errorf("duplicate phase %q", p.name)
+		}
+		byName[p.name] = i
+	}
+	for _, p := range phases {
+		for _, dep := range p.after {
+			if _, ok := byName[dep]; !ok {
+				return nil, fmt.Errorf("phase %q depends on unknown phase %q", p.name, dep)
+			}
+		}
+	}
+
+	planned := make([]phase, 0, len(phases))
+	placed := make(map[string]bool, len(phases))
+	for len(planned) < len(phases) {
+		progress := false
+		for _, p := range phases {
+			if placed[p.name] || !depsPlaced(p, placed) {
+				continue
+			}
+			planned = append(planned, p)
+			placed[p.name] = true
+			progress = true
+		}
+		if !progress {
+			return nil, fmt.Errorf("extraction phases have a dependency cycle")
+		}
+	}
+	return planned, nil
+}
+
+// depsPlaced reports whether every dependency of p has been placed
+func depsPlaced(p phase, placed map[string]bool) bool {
+	for _, dep := range p.after {
+		if !placed[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+// selectPhases keeps the phases for which keep returns true, in order
+func selectPhases(phases []phase, keep func(name string) bool) []phase {
+	var selected []phase
+	for _, p := range phases {
+		if keep(p.name) {
+			selected = append(selected, p)
+		}
+	}
+	return selected
+}
+
+// runPlan runs every phase as soon as the phases it comes after have
+// finished and returns once all are done. A phase error is passed to fail,
+// which is expected to cancel ctx; phases not yet started then never run.
+func runPlan(ctx context.Context, phases []phase, fail func(error)) {
+	done := make(map[string]chan struct{}, len(phases))
+	for _, p := range phases {
+		done[p.name] = make(chan struct{})
+	}
+
+	var wg sync.WaitGroup
+	for _, p := range phases {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[p.name])
+
+			for _, dep := range p.after {
+				if ch, ok := done[dep]; ok {
+					<-ch
+				}
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if err := p.run(ctx); err != nil {
+				fail(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,127 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestPlanPhases(t *testing.T) {
+	tests := []struct {
+		name    string
+		phases  []phase
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "Independent phases keep their order",
+			phases: []phase{{name: "users"}, {name: "teams"}},
+			want:   []string{"users", "teams"},
+		},
+		{
+			name: "Dependencies come first",
+			phases: []phase{
+				{name: "tasks", after: []string{"projects"}},
+				{name: "projects", after: []string{"users", "teams"}},
+				{name: "users"},
+				{name: "teams"},
+			},
+			want: []string{"users", "teams", "projects", "tasks"},
+		},
+		{
+			name:    "Unknown dependency",
+			phases:  []phase{{name: "tasks", after: []string{"projects"}}},
+			wantErr: true,
+		},
+		{
+			name:    "Cycle",
+			phases:  []phase{{name: "a", after: []string{"b"}}, {name: "b", after: []string{"a"}}},
+			wantErr: true,
+		},
+		{
+			name:    "Duplicate",
+			phases:  []phase{{name: "users"}, {name: "users"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			planned, err := planPhases(tc.phases)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("planPhases() error = %v, wantErr %t", err, tc.wantErr)
+			}
+			var got []string
+			for _, p := range planned {
+				got = append(got, p.name)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("planPhases() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunPlan(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	step := func(name string, err error) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return err
+		}
+	}
+
+	t.Run("Dependents run after their dependencies", func(t *testing.T) {
+		order = nil
+		phases := []phase{
+			{name: "users", run: step("users", nil)},
+			{name: "teams", run: step("teams", nil)},
+			{name: "projects", after: []string{"users", "teams"}, run: step("projects", nil)},
+			{name: "tasks", after: []string{"projects"}, run: step("tasks", nil)},
+		}
+		runPlan(context.Background(), phases, func(err error) { t.Errorf("unexpected error: %v", err) })
+
+		if len(order) != 4 || order[2] != "projects" || order[3] != "tasks" {
+			t.Errorf("phases ran in order %v", order)
+		}
+	})
+
+	t.Run("Dependencies left out of the plan are ignored", func(t *testing.T) {
+		order = nil
+		phases := selectPhases([]phase{
+			{name: "users", run: step("users", nil)},
+			{name: "projects", after: []string{"users"}, run: step("projects", nil)},
+		}, func(name string) bool { return name == "projects" })
+		runPlan(context.Background(), phases, func(err error) { t.Errorf("unexpected error: %v", err) })
+
+		if !slices.Equal(order, []string{"projects"}) {
+			t.Errorf("phases ran = %v, want [projects]", order)
+		}
+	})
+
+	t.Run("A failure stops dependent phases", func(t *testing.T) {
+		order = nil
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		boom := errors.New("boom")
+
+		var failed error
+		phases := []phase{
+			{name: "projects", run: step("projects", boom)},
+			{name: "tasks", after: []string{"projects"}, run: step("tasks", nil)},
+		}
+		runPlan(ctx, phases, func(err error) { failed = err; cancel() })
+
+		if !errors.Is(failed, boom) {
+			t.Errorf("fail() got %v, want %v", failed, boom)
+		}
+		if !slices.Equal(order, []string{"projects"}) {
+			t.Errorf("phases ran = %v, want [projects]", order)
+		}
+	})
+}
@@ -0,0 +1,222 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+	"github.com/ioanzicu/asana-extractor/pkg/client"
+)
+
+// preflightSampleSize is how many projects the pre-flight counts the tasks
+// of; the task total is extrapolated from them
+const preflightSampleSize = 20
+
+// Preflight configures the estimate of a run's size made before it starts
+type Preflight struct {
+	// Enabled logs the estimate and records it in the manifest
+	Enabled bool
+	// MaxAPICalls aborts the run before anything is extracted when its
+	// estimated API calls exceed it. Zero means no limit; any other value
+	// enables the pre-flight.
+	MaxAPICalls int64
+}
+
+// enabled reports whether the pre-flight runs
+func (p Preflight) enabled() bool {
+	return p.Enabled || p.MaxAPICalls > 0
+}
+
+// ErrPreflightBudgetExceeded is returned, before anything is extracted,
+// when a run is estimated to need more API calls than
+// Preflight.MaxAPICalls
+var ErrPreflightBudgetExceeded = errors.New("estimated API calls exceed the budget")
+
+// PreflightClient is implemented by Asana clients that can size a run
+// without listing every task
+type PreflightClient interface {
+	GetProjectTaskCount(ctx context.Context, projectGID string) (int, error)
+	PageSize(resource string) int
+}
+
+// Estimate is the pre-flight estimate of a run. Users, projects and teams
+// are listed, so their counts are exact; tasks are extrapolated from a
+// sample of projects, and optional resources from the entities they belong
+// to. Incremental runs fetch fewer tasks than estimated.
+type Estimate struct {
+	// Counts is the estimated number of entities per resource, for the
+	// resources that can be counted up front
+	Counts map[string]int `json:"counts"`
+	// Calls is the estimated number of API calls per resource
+	Calls map[string]int64 `json:"calls"`
+	// APICalls is the estimated total
+	APICalls int64 `json:"api_calls"`
+	// PreflightCalls is what the estimate itself cost
+	PreflightCalls int64 `json:"preflight_calls"`
+}
+
+// String summarizes the estimate on one line, resources in name order
+func (est *Estimate) String() string {
+	resources := make([]string, 0, len(est.Calls))
+	for resource := range est.Calls {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	parts := make([]string, len(resources))
+	for i, resource := range resources {
+		if n, ok := est.Counts[resource]; ok {
+			parts[i] = fmt.Sprintf("%s=~%d (%d calls)", resource, n, est.Calls[resource])
+		} else {
+			parts[i] = fmt.Sprintf("%s=? (%d calls)", resource, est.Calls[resource])
+		}
+	}
+	return fmt.Sprintf("~%d API calls: %s", est.APICalls, strings.Join(parts, ", "))
+}
+
+// add records the estimate of one resource
+func (est *Estimate) add(resource string, calls int64) {
+	est.Calls[resource] = calls
+	est.APICalls += calls
+}
+
+// preflight estimates the entities and API calls of the run and checks the
+// estimate against the budget
+func (e *Extractor) preflight(ctx context.Context) (*Estimate, error) {
+	ctx, span := tracer.Start(ctx, "extractor.preflight")
+	defer span.End()
+
+	usage := &client.Usage{}
+	ctx = client.WithUsage(ctx, usage)
+	est := &Estimate{Counts: make(map[string]int), Calls: make(map[string]int64)}
+
+	// Listing costs the same pages the run will fetch
+	list := func(resource string, stream func() (int, error)) (int, error) {
+		before := usage.Pages()
+		n, err := stream()
+		if err != nil {
+			return 0, fmt.Errorf("failed to count %s: %w", resource, err)
+		}
+		est.Counts[resource] = n
+		est.add(resource, usage.Pages()-before)
+		return n, nil
+	}
+
+	var users int
+	if e.walks(ResourceUsers) {
+		var err error
+		users, err = list(ResourceUsers, func() (int, error) {
+			n := 0
+			err := e.asanaClient.StreamUsers(ctx, func(user asana.User) error {
+				if e.cfg.Filters.keepUser(user) {
+					n++
+				}
+				return nil
+			})
+			return n, err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if e.walks(ResourceTeams) {
+		_, err := list(ResourceTeams, func() (int, error) {
+			n := 0
+			err := e.asanaClient.StreamTeams(ctx, func(asana.Team) error { n++; return nil })
+			return n, err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	var projects []string
+	if e.walks(ResourceProjects) {
+		_, err := list(ResourceProjects, func() (int, error) {
+			err := e.asanaClient.StreamProjects(ctx, func(project asana.Project) error {
+				if e.cfg.Filters.keepProject(project) {
+					projects = append(projects, project.GID)
+				}
+				return nil
+			})
+			return len(projects), err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var tasks int
+	if e.walks(ResourceTasks) {
+		var err error
+		if tasks, err = e.estimateTasks(ctx, est, projects); err != nil {
+			return nil, err
+		}
+	}
+
+	// Optional resources cost about one call per entity they belong to
+	if e.enabled(ResourceUserTaskLists) {
+		est.Counts[ResourceUserTaskLists] = users
+		est.add(ResourceUserTaskLists, 2*int64(users))
+	}
+	if e.enabled(ResourceAttachments) {
+		est.add(ResourceAttachments, int64(tasks))
+	}
+	if e.enabled(ResourceStatusUpdates) {
+		// Plus the goal and portfolio listings
+		est.add(ResourceStatusUpdates, int64(len(projects))+2)
+	}
+	if e.enabled(ResourceCustomFields) {
+		est.add(ResourceCustomFields, 1)
+	}
+
+	est.PreflightCalls = usage.Requests()
+	logf(ctx, "Pre-flight estimate: %s", est)
+	if budget := e.cfg.Preflight.MaxAPICalls; budget > 0 && est.APICalls > budget {
+		return est, fmt.Errorf("%w: ~%d API calls estimated, the budget is %d; narrow EXTRACT_RESOURCES or the filters, or raise the budget",
+			ErrPreflightBudgetExceeded, est.APICalls, budget)
+	}
+	return est, nil
+}
+
+// estimateTasks counts the tasks of an evenly spaced sample of projects and
+// extrapolates the tasks and task pages of all of them
+func (e *Extractor) estimateTasks(ctx context.Context, est *Estimate, projects []string) (int, error) {
+	counter, ok := e.asanaClient.(PreflightClient)
+	if !ok {
+		return 0, fmt.Errorf("the pre-flight is not supported by this client")
+	}
+	if len(projects) == 0 {
+		est.Counts[ResourceTasks] = 0
+		est.add(ResourceTasks, 0)
+		return 0, nil
+	}
+
+	sample := projects
+	if len(sample) > preflightSampleSize {
+		sample = make([]string, preflightSampleSize)
+		for i := range sample {
+			sample[i] = projects[i*len(projects)/preflightSampleSize]
+		}
+	}
+
+	size := max(counter.PageSize(ResourceTasks), 1)
+	var tasks, pages int
+	for _, gid := range sample {
+		n, err := counter.GetProjectTaskCount(ctx, gid)
+		if err != nil {
+			return 0, fmt.Errorf("failed to count tasks: %w", err)
+		}
+		tasks += n
+		// Even an empty project costs a page
+		pages += max((n+size-1)/size, 1)
+	}
+
+	scale := float64(len(projects)) / float64(len(sample))
+	total := int(float64(tasks) * scale)
+	est.Counts[ResourceTasks] = total
+	est.add(ResourceTasks, int64(float64(pages)*scale))
+	return total, nil
+}
@@ -0,0 +1,164 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+	"github.com/ioanzicu/asana-extractor/pkg/client"
+)
+
+// countingAsanaClient serves task counts per project and records one page
+// per listing, as the Asana client does for a short listing
+type countingAsanaClient struct {
+	*mockAsanaClient
+	pageSize int
+	counted  []string
+}
+
+func (c *countingAsanaClient) StreamUsers(ctx context.Context, fn func(asana.User) error) error {
+	client.RecordPages(ctx, 1)
+	return c.mockAsanaClient.StreamUsers(ctx, fn)
+}
+
+func (c *countingAsanaClient) StreamProjects(ctx context.Context, fn func(asana.Project) error) error {
+	client.RecordPages(ctx, 1)
+	return c.mockAsanaClient.StreamProjects(ctx, fn)
+}
+
+func (c *countingAsanaClient) StreamTeams(ctx context.Context, fn func(asana.Team) error) error {
+	client.RecordPages(ctx, 1)
+	return c.mockAsanaClient.StreamTeams(ctx, fn)
+}
+
+func (c *countingAsanaClient) GetProjectTaskCount(ctx context.Context, projectGID string) (int, error) {
+	c.counted = append(c.counted, projectGID)
+	return len(c.tasks[projectGID]), nil
+}
+
+func (c *countingAsanaClient) PageSize(resource string) int {
+	return c.pageSize
+}
+
+func TestExtractor_Preflight(t *testing.T) {
+	tasks := map[string][]asana.Task{
+		"p1": {{GID: "t1"}, {GID: "t2"}, {GID: "t3"}},
+		"p2": {},
+	}
+
+	tests := []struct {
+		name      string
+		preflight Preflight
+		wantErr   error
+		wantTasks int
+	}{
+		{
+			name:      "Estimate only",
+			preflight: Preflight{Enabled: true},
+			wantTasks: 3,
+		},
+		{
+			name:      "Within the budget",
+			preflight: Preflight{MaxAPICalls: 6},
+			wantTasks: 3,
+		},
+		{
+			name:      "Over the budget",
+			preflight: Preflight{MaxAPICalls: 5},
+			wantErr:   ErrPreflightBudgetExceeded,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			asanaClient := &countingAsanaClient{
+				mockAsanaClient: &mockAsanaClient{
+					users:    []asana.User{{GID: "u1"}},
+					projects: []asana.Project{{GID: "p1"}, {GID: "p2"}},
+					teams:    []asana.Team{{GID: "team1"}},
+					tasks:    tasks,
+				},
+				pageSize: 2,
+			}
+			store := &manifestStorage{}
+			ext := New(asanaClient, store, Config{Preflight: tc.preflight})
+
+			stats, err := ext.Extract(context.Background())
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Extract() error = %v, want %v", err, tc.wantErr)
+			}
+
+			est := stats.Estimate
+			if est == nil {
+				t.Fatal("Expected an estimate")
+			}
+			// One page each of users, teams and projects; two pages of p1's
+			// tasks and one of p2's empty listing
+			if est.APICalls != 6 || est.Calls[ResourceTasks] != 3 {
+				t.Errorf("Expected 6 API calls, 3 of them for tasks, got %+v", est)
+			}
+			if est.Counts[ResourceTasks] != 3 || est.Counts[ResourceProjects] != 2 {
+				t.Errorf("Unexpected counts: %v", est.Counts)
+			}
+			if est.PreflightCalls != 0 {
+				t.Errorf("Expected the mock to make no requests, got %d", est.PreflightCalls)
+			}
+			if !strings.Contains(est.String(), "tasks=~3 (3 calls)") {
+				t.Errorf("Unexpected summary: %s", est)
+			}
+			if m := store.manifests[0]; m.Estimate != est {
+				t.Error("Expected the manifest to carry the estimate")
+			}
+
+			if tc.wantErr != nil {
+				if stats.UsersExtracted != 0 || len(store.users) != 0 {
+					t.Error("Expected nothing to be extracted over the budget")
+				}
+				if m := store.manifests[0]; m.Status != StatusFailed {
+					t.Errorf("Expected a failed manifest, got %s", m.Status)
+				}
+				return
+			}
+			if stats.TasksExtracted != tc.wantTasks {
+				t.Errorf("Expected %d tasks extracted, got %d", tc.wantTasks, stats.TasksExtracted)
+			}
+		})
+	}
+}
+
+func TestExtractor_PreflightSample(t *testing.T) {
+	var projects []asana.Project
+	tasks := make(map[string][]asana.Task)
+	for i := range 100 {
+		gid := "p" + strings.Repeat("x", i)
+		projects = append(projects, asana.Project{GID: gid})
+		tasks[gid] = []asana.Task{{GID: "t" + gid}}
+	}
+	asanaClient := &countingAsanaClient{
+		mockAsanaClient: &mockAsanaClient{projects: projects, tasks: tasks},
+		pageSize:        100,
+	}
+	ext := New(asanaClient, &mockStorage{}, Config{Resources: []string{ResourceProjects, ResourceTasks}})
+
+	est, err := ext.preflight(context.Background())
+	if err != nil {
+		t.Fatalf("preflight() failed: %v", err)
+	}
+	if len(asanaClient.counted) != preflightSampleSize {
+		t.Errorf("Expected %d projects counted, got %d", preflightSampleSize, len(asanaClient.counted))
+	}
+	if est.Counts[ResourceTasks] != 100 || est.Calls[ResourceTasks] != 100 {
+		t.Errorf("Expected 100 tasks in 100 calls extrapolated, got %+v", est)
+	}
+}
+
+func TestExtractor_PreflightUnsupported(t *testing.T) {
+	client := &mockAsanaClient{projects: []asana.Project{{GID: "p1"}}}
+	ext := New(client, &mockStorage{}, Config{Preflight: Preflight{Enabled: true}})
+
+	if _, err := ext.Extract(context.Background()); err == nil {
+		t.Fatal("Expected an error from a client that cannot count tasks")
+	}
+}
@@ -0,0 +1,187 @@
+package extractor
+
+import (
+	"expvar"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultProgressInterval is how often progress is reported when
+// Config.Progress is set without an interval
+const DefaultProgressInterval = 30 * time.Second
+
+// currentProgress holds the latest progress of a running extraction,
+// published as the extractor_progress expvar
+var currentProgress atomic.Pointer[Progress]
+
+func init() {
+	expvar.Publish("extractor_progress", expvar.Func(func() any {
+		return currentProgress.Load()
+	}))
+}
+
+// Progress is a snapshot of a running extraction
+type Progress struct {
+	RunID   string        `json:"run_id"`
+	Elapsed time.Duration `json:"elapsed_ns"`
+	// Pages counts result pages fetched so far
+	Pages int64 `json:"pages"`
+	// Entities counts entities written so far; Errors those that failed
+	Entities int `json:"entities"`
+	Errors   int `json:"errors"`
+	// Expected is the estimated number of entities in the run, from the
+	// counts of a previous run. Zero means unknown.
+	Expected int `json:"expected,omitempty"`
+	// Remaining estimates the time left at the rate so far. Zero means
+	// unknown.
+	Remaining time.Duration `json:"remaining_ns,omitempty"`
+	// Done is set on the final report of a run
+	Done bool `json:"done"`
+}
+
+// Percent returns how much of Expected has been processed, capped at 100,
+// or -1 if Expected is unknown
+func (p Progress) Percent() float64 {
+	if p.Expected <= 0 {
+		return -1
+	}
+	return min(100, float64(p.Entities+p.Errors)*100/float64(p.Expected))
+}
+
+// ProgressSink receives progress reports of a run: one every
+// Config.ProgressInterval and a final one with Done set. Reports come from
+// a single goroutine and should return quickly.
+type ProgressSink interface {
+	Report(p Progress)
+}
+
+// ProgressFunc adapts a function to a ProgressSink
+type ProgressFunc func(Progress)
+
+// Report calls f(p)
+func (f ProgressFunc) Report(p Progress) {
+	f(p)
+}
+
+// LogProgress is a ProgressSink that logs every report
+var LogProgress ProgressSink = ProgressFunc(func(p Progress) {
+	switch {
+	case p.Done:
+		log.Printf("Run %s finished: %d entities, %d errors, %d pages in %v", p.RunID, p.Entities, p.Errors, p.Pages, p.Elapsed.Round(time.Second))
+	case p.Percent() >= 0:
+		log.Printf("Run %s: %d entities (%.0f%% of ~%d), %d errors, %d pages in %v, ~%v remaining",
+			p.RunID, p.Entities, p.Percent(), p.Expected, p.Errors, p.Pages, p.Elapsed.Round(time.Second), p.Remaining.Round(time.Second))
+	default:
+		log.Printf("Run %s: %d entities, %d errors, %d pages in %v", p.RunID, p.Entities, p.Errors, p.Pages, p.Elapsed.Round(time.Second))
+	}
+})
+
+// progressReporter periodically snapshots a run's stats for a ProgressSink.
+// Snapshots are taken by the stats collector, so they are sent through the
+// results channel like any other update.
+type progressReporter struct {
+	sink     ProgressSink
+	expected int
+	pages    func() int64
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// startProgress starts reporting every interval until stop is called. It
+// returns nil when no sink is configured.
+func (e *Extractor) startProgress(results chan<- func(*Stats), expected int, pages func() int64) *progressReporter {
+	if e.cfg.Progress == nil {
+		return nil
+	}
+	interval := e.cfg.ProgressInterval
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+
+	r := &progressReporter{sink: e.cfg.Progress, expected: expected, pages: pages, stop: make(chan struct{})}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				results <- func(s *Stats) { r.report(s, false) }
+			}
+		}
+	}()
+	return r
+}
+
+// stopTicking stops the periodic reports. It must be called before the
+// results channel is closed.
+func (r *progressReporter) stopTicking() {
+	if r == nil {
+		return
+	}
+	close(r.stop)
+	r.wg.Wait()
+}
+
+// report sends a snapshot of s to the sink and publishes it
+func (r *progressReporter) report(s *Stats, done bool) {
+	if r == nil {
+		return
+	}
+
+	p := Progress{
+		RunID:    s.RunID,
+		Elapsed:  time.Since(s.StartedAt),
+		Pages:    r.pages(),
+		Entities: s.UsersExtracted + s.ProjectsExtracted + s.TasksExtracted + s.TeamsExtracted + s.UserTaskListsExtracted + s.AvatarsExtracted + s.AttachmentsExtracted + s.StatusUpdatesExtracted + s.CustomFieldsExtracted + s.AuditLogEventsExtracted,
+		Errors:   s.Errors,
+		Expected: r.expected,
+		Done:     done,
+	}
+	if done {
+		p.Elapsed = s.Duration
+	}
+	if processed := p.Entities + p.Errors; !done && processed > 0 && p.Expected > processed {
+		p.Remaining = time.Duration(float64(p.Elapsed) * float64(p.Expected-processed) / float64(processed))
+	}
+
+	currentProgress.Store(&p)
+	r.sink.Report(p)
+}
+
+// expectedEntities estimates the entities a run will process from
+// Config.ExpectedCounts or, failing that, the previous run of this process
+func (e *Extractor) expectedEntities() int {
+	counts := e.cfg.ExpectedCounts
+	if counts == nil {
+		if prev := lastRun.Load(); prev != nil {
+			counts = map[string]int{
+				ResourceUsers:    prev.UsersExtracted,
+				ResourceProjects: prev.ProjectsExtracted,
+				ResourceTasks:    prev.TasksExtracted,
+				ResourceTeams:    prev.TeamsExtracted,
+
+				ResourceUserTaskLists:  prev.UserTaskListsExtracted,
+				ResourceAvatars:        prev.AvatarsExtracted,
+				ResourceAttachments:    prev.AttachmentsExtracted,
+				ResourceStatusUpdates:  prev.StatusUpdatesExtracted,
+				ResourceCustomFields:   prev.CustomFieldsExtracted,
+				ResourceAuditLogEvents: prev.AuditLogEventsExtracted,
+			}
+		}
+	}
+
+	total := 0
+	for resource, n := range counts {
+		if e.enabled(resource) {
+			total += n
+		}
+	}
+	return total
+}
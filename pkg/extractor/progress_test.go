@@ -0,0 +1,98 @@
+package extractor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// slowStorage delays every task write so a run spans several progress ticks
+type slowStorage struct {
+	mockStorage
+	delay time.Duration
+}
+
+func (s *slowStorage) WriteTask(task asana.Task) error {
+	time.Sleep(s.delay)
+	return s.mockStorage.WriteTask(task)
+}
+
+func TestExtractor_Progress(t *testing.T) {
+	client := &mockAsanaClient{
+		projects: []asana.Project{{GID: "p1"}},
+		tasks:    map[string][]asana.Task{"p1": {{GID: "t1"}, {GID: "t2"}, {GID: "t3"}, {GID: "t4"}}},
+	}
+
+	var mu sync.Mutex
+	var reports []Progress
+	ext := New(client, &slowStorage{delay: 20 * time.Millisecond}, Config{
+		Resources:        []string{ResourceProjects, ResourceTasks},
+		Progress:         ProgressFunc(func(p Progress) { mu.Lock(); reports = append(reports, p); mu.Unlock() }),
+		ProgressInterval: 10 * time.Millisecond,
+		ExpectedCounts:   map[string]int{ResourceProjects: 1, ResourceTasks: 9, ResourceUsers: 100},
+	})
+
+	stats, err := ext.Extract(context.Background())
+	if err != nil {
+		t.Fatalf("Extract() failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reports) < 2 {
+		t.Fatalf("Expected periodic reports and a final one, got %d", len(reports))
+	}
+	for _, p := range reports[:len(reports)-1] {
+		if p.Done || p.RunID != stats.RunID || p.Expected != 10 {
+			t.Errorf("Unexpected periodic report %+v", p)
+		}
+	}
+
+	final := reports[len(reports)-1]
+	if !final.Done || final.Entities != 5 || final.Elapsed != stats.Duration {
+		t.Errorf("Expected a final report of 5 entities, got %+v", final)
+	}
+	if got := currentProgress.Load(); got == nil || !got.Done {
+		t.Errorf("Expected extractor_progress to hold the final report, got %+v", got)
+	}
+}
+
+func TestProgress_Estimates(t *testing.T) {
+	tests := []struct {
+		name            string
+		stats           Stats
+		expected        int
+		expectPercent   float64
+		expectRemaining time.Duration
+	}{
+		{name: "Unknown total", stats: Stats{TasksExtracted: 10}, expected: 0, expectPercent: -1},
+		{name: "Quarter done", stats: Stats{TasksExtracted: 20, Errors: 5}, expected: 100, expectPercent: 25, expectRemaining: 3 * time.Minute},
+		{name: "Beyond estimate", stats: Stats{TasksExtracted: 150}, expected: 100, expectPercent: 100},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got Progress
+			r := &progressReporter{
+				sink:     ProgressFunc(func(p Progress) { got = p }),
+				expected: tc.expected,
+				pages:    func() int64 { return 3 },
+			}
+			tc.stats.StartedAt = time.Now().Add(-time.Minute)
+			r.report(&tc.stats, false)
+
+			if got.Percent() != tc.expectPercent {
+				t.Errorf("Expected %v%%, got %v%%", tc.expectPercent, got.Percent())
+			}
+			if diff := got.Remaining - tc.expectRemaining; diff < -time.Second || diff > time.Second {
+				t.Errorf("Expected ~%v remaining, got %v", tc.expectRemaining, got.Remaining)
+			}
+			if got.Pages != 3 {
+				t.Errorf("Expected 3 pages, got %d", got.Pages)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package extractor
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// quotaTopEndpoints is how many endpoints the quota log line lists
+const quotaTopEndpoints = 5
+
+// Quota reports how much of Asana's API quota a run used, to see how close
+// runs come to its rate limits
+type Quota struct {
+	APICalls int64 `json:"api_calls"`
+	// CallsPerMinute is the average rate over the run, to compare with the
+	// workspace's requests-per-minute limit
+	CallsPerMinute float64 `json:"calls_per_minute"`
+	// Endpoints counts API calls per endpoint, GIDs replaced by {gid}
+	Endpoints map[string]int64 `json:"endpoints"`
+	Retries   int64            `json:"retries"`
+	// RateLimited counts calls Asana answered 429 Too Many Requests
+	RateLimited int64 `json:"rate_limited"`
+	// RateLimitWait is the time requests spent waiting for the rate limiter
+	RateLimitWait time.Duration `json:"rate_limit_wait_ns"`
+}
+
+// newQuota builds the quota report of a finished run
+func newQuota(stats *Stats) Quota {
+	q := Quota{
+		APICalls:      stats.APICalls,
+		Endpoints:     stats.Endpoints,
+		Retries:       stats.Retries,
+		RateLimited:   stats.RateLimited,
+		RateLimitWait: stats.RateLimitWait,
+	}
+	if minutes := stats.Duration.Minutes(); minutes > 0 {
+		q.CallsPerMinute = float64(stats.APICalls) / minutes
+	}
+	return q
+}
+
+// String summarizes the report on one line, with the busiest endpoints
+func (q Quota) String() string {
+	endpoints := make([]string, 0, len(q.Endpoints))
+	for endpoint := range q.Endpoints {
+		endpoints = append(endpoints, endpoint)
+	}
+	slices.SortFunc(endpoints, func(a, b string) int {
+		return cmp.Or(cmp.Compare(q.Endpoints[b], q.Endpoints[a]), strings.Compare(a, b))
+	})
+
+	top := make([]string, 0, quotaTopEndpoints)
+	for _, endpoint := range endpoints[:min(len(endpoints), quotaTopEndpoints)] {
+		top = append(top, fmt.Sprintf("%s=%d", endpoint, q.Endpoints[endpoint]))
+	}
+	if more := len(endpoints) - len(top); more > 0 {
+		top = append(top, fmt.Sprintf("%d more", more))
+	}
+	return fmt.Sprintf("%d calls (%.0f/min), %d retries, %d rate limited, %v waiting; %s",
+		q.APICalls, q.CallsPerMinute, q.Retries, q.RateLimited, q.RateLimitWait.Round(time.Millisecond), strings.Join(top, ", "))
+}
@@ -0,0 +1,40 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuota(t *testing.T) {
+	stats := &Stats{
+		APICalls:      120,
+		Retries:       4,
+		RateLimited:   2,
+		RateLimitWait: 1500 * time.Millisecond,
+		Duration:      2 * time.Minute,
+		Endpoints: map[string]int64{
+			"GET /projects/{gid}/tasks": 90,
+			"GET /users":                10,
+			"GET /teams":                10,
+			"GET /projects":             4,
+			"GET /custom_fields":        3,
+			"GET /workspaces/{gid}":     3,
+		},
+	}
+
+	q := newQuota(stats)
+	if q.CallsPerMinute != 60 {
+		t.Errorf("Expected 60 calls per minute, got %v", q.CallsPerMinute)
+	}
+
+	want := "120 calls (60/min), 4 retries, 2 rate limited, 1.5s waiting; " +
+		"GET /projects/{gid}/tasks=90, GET /teams=10, GET /users=10, GET /projects=4, GET /custom_fields=3, 1 more"
+	if got := q.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	if q := newQuota(&Stats{}); q.CallsPerMinute != 0 || !strings.HasPrefix(q.String(), "0 calls") {
+		t.Errorf("Unexpected report of an empty run: %s", q)
+	}
+}
@@ -0,0 +1,59 @@
+package extractor
+
+import "context"
+
+// Reconciliation modes accepted in Config.Reconcile
+const (
+	ReconcileOff       = "off"
+	ReconcileDelete    = "delete"
+	ReconcileTombstone = "tombstone"
+)
+
+// Reconciler is implemented by storage backends that can drop entities no
+// longer present in Asana. It returns how many stored entities were orphaned.
+type Reconciler interface {
+	Reconcile(resource string, live map[string]struct{}, tombstone bool) (int, error)
+}
+
+// reconciling reports whether this run should track live GIDs
+func (e *Extractor) reconciling() bool {
+	if e.cfg.Reconcile != ReconcileDelete && e.cfg.Reconcile != ReconcileTombstone {
+		return false
+	}
+	_, ok := e.storage.(Reconciler)
+	return ok
+}
+
+// markLive records that gid was returned by Asana during this run.
+// It must only be called from the stats collector.
+func (s *Stats) markLive(resource, gid string) {
+	s.sample.add(resource, gid)
+	if s.live == nil {
+		return
+	}
+	set, ok := s.live[resource]
+	if !ok {
+		set = make(map[string]struct{})
+		s.live[resource] = set
+	}
+	set[gid] = struct{}{}
+}
+
+// reconcile removes or tombstones stored entities that were not seen in a
+// complete run. Only resources written by this run are considered.
+func (e *Extractor) reconcile(ctx context.Context, stats *Stats) {
+	r := e.storage.(Reconciler)
+	tombstone := e.cfg.Reconcile == ReconcileTombstone
+
+	for _, resource := range e.cfg.Resources {
+		if (resource == ResourceTasks && stats.watermarked) || resource == ResourceAuditLogEvents || resource == ResourceAvatars || resource == ResourceAttachments {
+			continue
+		}
+		orphans, err := r.Reconcile(resource, stats.live[resource], tombstone)
+		if err != nil {
+			logf(ctx, "Error reconciling %s: %v", resource, err)
+			stats.recordError(resource)
+		}
+		stats.Orphaned += orphans
+	}
+}
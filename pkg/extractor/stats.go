@@ -0,0 +1,257 @@
+package extractor
+
+import (
+	"context"
+	"expvar"
+	"sync/atomic"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/client"
+)
+
+// lastRun holds the stats of the most recently finished run, published as
+// the extractor_last_run expvar
+var lastRun atomic.Pointer[Stats]
+
+func init() {
+	expvar.Publish("extractor_last_run", expvar.Func(func() any {
+		return lastRun.Load()
+	}))
+}
+
+// Stats holds extraction statistics. It marshals to JSON as the run report.
+type Stats struct {
+	RunID             string        `json:"run_id"`
+	StartedAt         time.Time     `json:"started_at"`
+	UsersExtracted    int           `json:"users_extracted"`
+	ProjectsExtracted int           `json:"projects_extracted"`
+	TasksExtracted    int           `json:"tasks_extracted"`
+	TeamsExtracted    int           `json:"teams_extracted"`
+	Errors            int           `json:"errors"`
+	Duration          time.Duration `json:"duration_ns"`
+	// UserTaskListsExtracted counts user task lists stored, when selected
+	UserTaskListsExtracted int `json:"user_task_lists_extracted"`
+	// AvatarsExtracted counts avatars stored or already current, when
+	// selected
+	AvatarsExtracted int `json:"avatars_extracted"`
+	// AttachmentsExtracted counts attachment files stored or already
+	// stored, when selected
+	AttachmentsExtracted int `json:"attachments_extracted"`
+	// StatusUpdatesExtracted counts status updates stored, when selected
+	StatusUpdatesExtracted int `json:"status_updates_extracted"`
+	// CustomFieldsExtracted counts custom field definitions stored, when
+	// selected
+	CustomFieldsExtracted int `json:"custom_fields_extracted"`
+	// AuditLogEventsExtracted counts audit log events stored, when selected
+	AuditLogEventsExtracted int `json:"audit_log_events_extracted"`
+	// Skipped counts entities dropped by Config.Filters
+	Skipped int `json:"skipped"`
+	// Rejected counts entities that failed validation, quarantined instead
+	// of stored
+	Rejected int `json:"rejected"`
+	// APICalls counts HTTP attempts sent to Asana during the run
+	APICalls int64 `json:"api_calls"`
+	// Retries counts attempts beyond the first for each request
+	Retries int64 `json:"retries"`
+	// RateLimitWait is the total time requests spent waiting for a slot
+	RateLimitWait time.Duration `json:"rate_limit_wait_ns"`
+	// RateLimited counts attempts Asana answered 429 Too Many Requests
+	RateLimited int64 `json:"rate_limited"`
+	// Endpoints counts HTTP attempts per endpoint, such as
+	// "GET /projects/{gid}/tasks"
+	Endpoints map[string]int64 `json:"endpoints,omitempty"`
+	// Pages counts result pages fetched
+	Pages int64 `json:"pages"`
+	// CacheHits counts requests served from the HTTP response cache
+	CacheHits int64 `json:"cache_hits"`
+	// BytesSaved counts response bytes not transferred thanks to gzip
+	BytesSaved int64 `json:"bytes_saved"`
+	// BytesWritten counts bytes written by storage backends that report it
+	BytesWritten int64 `json:"bytes_written"`
+	// Unchanged counts entities whose write was skipped because storage
+	// already held an identical copy
+	Unchanged int64 `json:"unchanged"`
+	// Orphaned counts stored entities deleted or tombstoned because they no
+	// longer exist in Asana
+	Orphaned int `json:"orphaned"`
+	// TimedOut is set when the caller's context deadline cut the run short
+	TimedOut bool `json:"timed_out"`
+	// APIBudgetExhausted is set when Config.MaxAPICalls stopped the run
+	// before it finished
+	APIBudgetExhausted bool `json:"api_budget_exhausted,omitempty"`
+	// Verification holds the cross-check against Asana, when configured
+	Verification *Verification `json:"verification,omitempty"`
+	// Estimate is the pre-flight estimate of the run, when configured
+	Estimate *Estimate `json:"estimate,omitempty"`
+
+	// Resources breaks the run down by extraction phase
+	Resources map[string]*ResourceStats `json:"resources"`
+
+	// live holds the GIDs returned per resource when reconciling
+	live map[string]map[string]struct{}
+	// sample holds random listed GIDs per resource for spot checks
+	sample *sampler
+	// partial is set when some entities were skipped, so the live sets
+	// cannot be trusted for reconciliation
+	partial bool
+	// watermarks holds the task watermark per project in incremental runs
+	watermarks map[string]time.Time
+	// watermarked is set when tasks were fetched by watermark, so unchanged
+	// tasks are missing from the live set
+	watermarked bool
+	// auditEventsEnd is the end of the audit log window once all its events
+	// were stored
+	auditEventsEnd time.Time
+}
+
+// ResourceStats holds the activity of one extraction phase. The projects
+// phase also runs, without writing, when only tasks or status updates are
+// selected, and so does the users phase for user task lists.
+type ResourceStats struct {
+	Extracted     int           `json:"extracted"`
+	Errors        int           `json:"errors"`
+	Skipped       int           `json:"skipped"`
+	Rejected      int           `json:"rejected"`
+	APICalls      int64         `json:"api_calls"`
+	Retries       int64         `json:"retries"`
+	RateLimitWait time.Duration `json:"rate_limit_wait_ns"`
+	RateLimited   int64         `json:"rate_limited"`
+	Pages         int64         `json:"pages"`
+	CacheHits     int64         `json:"cache_hits"`
+	BytesSaved    int64         `json:"bytes_saved"`
+	BytesWritten  int64         `json:"bytes_written"`
+}
+
+// ByteCounter is implemented by storage backends that report the bytes
+// written per resource
+type ByteCounter interface {
+	BytesWritten(resource string) int64
+}
+
+// ErrorRate returns the share of processed entities that failed
+func (s *Stats) ErrorRate() float64 {
+	total := s.UsersExtracted + s.ProjectsExtracted + s.TasksExtracted + s.TeamsExtracted + s.UserTaskListsExtracted + s.AvatarsExtracted + s.AttachmentsExtracted + s.StatusUpdatesExtracted + s.CustomFieldsExtracted + s.AuditLogEventsExtracted + s.Errors
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(total)
+}
+
+// resource returns the breakdown for a phase, creating it on first use.
+// It must only be called from the stats collector.
+func (s *Stats) resource(name string) *ResourceStats {
+	if s.Resources == nil {
+		s.Resources = make(map[string]*ResourceStats)
+	}
+	r, ok := s.Resources[name]
+	if !ok {
+		r = &ResourceStats{}
+		s.Resources[name] = r
+	}
+	return r
+}
+
+// recordError counts a failed entity against a phase
+func (s *Stats) recordError(resource string) {
+	s.Errors++
+	s.resource(resource).Errors++
+}
+
+// recordSkip counts an entity dropped by a filter against a phase
+func (s *Stats) recordSkip(resource string) {
+	s.Skipped++
+	s.resource(resource).Skipped++
+}
+
+// recordReject counts an entity that failed validation against a phase
+func (s *Stats) recordReject(resource string) {
+	s.Rejected++
+	s.resource(resource).Rejected++
+}
+
+// phaseUsage gives every extraction phase its own client.Usage and remembers
+// the storage byte counters at the start of the run
+type phaseUsage struct {
+	usage       map[string]*client.Usage
+	bytes       ByteCounter
+	bytesBefore map[string]int64
+}
+
+// newPhaseUsage prepares usage tracking for the given phases
+func newPhaseUsage(storage Storage, phases []string) *phaseUsage {
+	p := &phaseUsage{
+		usage:       make(map[string]*client.Usage, len(phases)),
+		bytesBefore: make(map[string]int64, len(phases)),
+	}
+	p.bytes, _ = storage.(ByteCounter)
+
+	for _, phase := range phases {
+		p.usage[phase] = &client.Usage{}
+		if p.bytes != nil {
+			p.bytesBefore[phase] = p.bytes.BytesWritten(phase)
+		}
+	}
+	return p
+}
+
+// context returns ctx with the phase's Usage attached
+func (p *phaseUsage) context(ctx context.Context, phase string) context.Context {
+	return client.WithUsage(ctx, p.usage[phase])
+}
+
+// pages returns the result pages fetched so far across all phases
+func (p *phaseUsage) pages() int64 {
+	var n int64
+	for _, u := range p.usage {
+		n += u.Pages()
+	}
+	return n
+}
+
+// apply copies per-phase usage into stats and computes the run totals
+func (p *phaseUsage) apply(stats *Stats) {
+	extracted := map[string]int{
+		ResourceUsers:    stats.UsersExtracted,
+		ResourceProjects: stats.ProjectsExtracted,
+		ResourceTasks:    stats.TasksExtracted,
+		ResourceTeams:    stats.TeamsExtracted,
+
+		ResourceUserTaskLists:  stats.UserTaskListsExtracted,
+		ResourceAvatars:        stats.AvatarsExtracted,
+		ResourceAttachments:    stats.AttachmentsExtracted,
+		ResourceStatusUpdates:  stats.StatusUpdatesExtracted,
+		ResourceCustomFields:   stats.CustomFieldsExtracted,
+		ResourceAuditLogEvents: stats.AuditLogEventsExtracted,
+	}
+
+	for phase, u := range p.usage {
+		r := stats.resource(phase)
+		r.Extracted = extracted[phase]
+		r.APICalls = u.Requests()
+		r.Retries = u.Retries()
+		r.RateLimitWait = u.RateLimitWait()
+		r.RateLimited = u.RateLimited()
+		r.Pages = u.Pages()
+		r.CacheHits = u.CacheHits()
+		r.BytesSaved = u.BytesSaved()
+		if p.bytes != nil {
+			r.BytesWritten = p.bytes.BytesWritten(phase) - p.bytesBefore[phase]
+		}
+
+		stats.APICalls += r.APICalls
+		stats.Retries += r.Retries
+		stats.RateLimitWait += r.RateLimitWait
+		stats.RateLimited += r.RateLimited
+		stats.Pages += r.Pages
+		stats.CacheHits += r.CacheHits
+		stats.BytesSaved += r.BytesSaved
+		stats.BytesWritten += r.BytesWritten
+
+		for endpoint, n := range u.Endpoints() {
+			if stats.Endpoints == nil {
+				stats.Endpoints = make(map[string]int64)
+			}
+			stats.Endpoints[endpoint] += n
+		}
+	}
+}
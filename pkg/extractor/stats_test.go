@@ -0,0 +1,93 @@
+package extractor
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// byteStorage reports 10 bytes per stored user
+type byteStorage struct {
+	mockStorage
+}
+
+func (m *byteStorage) BytesWritten(resource string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if resource == ResourceUsers {
+		return int64(len(m.users) * 10)
+	}
+	return 0
+}
+
+func TestExtractor_ResourceStats(t *testing.T) {
+	tests := []struct {
+		name         string
+		resources    []string
+		expectPhases []string
+	}{
+		{name: "Users only", resources: []string{ResourceUsers}, expectPhases: []string{ResourceUsers}},
+		{name: "Tasks walk projects", resources: []string{ResourceTasks}, expectPhases: []string{ResourceProjects, ResourceTasks}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient := &mockAsanaClient{
+				users:    []asana.User{{GID: "u1"}, {GID: "u2"}},
+				projects: []asana.Project{{GID: "p1"}},
+				tasks:    map[string][]asana.Task{"p1": {{GID: "t1"}}},
+			}
+			store := &byteStorage{}
+
+			stats, err := New(mockClient, store, Config{Resources: tc.resources}).Extract(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(stats.Resources) != len(tc.expectPhases) {
+				t.Fatalf("expected phases %v, got %v", tc.expectPhases, stats.Resources)
+			}
+			for _, phase := range tc.expectPhases {
+				if _, ok := stats.Resources[phase]; !ok {
+					t.Errorf("missing phase %s", phase)
+				}
+			}
+
+			if users, ok := stats.Resources[ResourceUsers]; ok {
+				if users.Extracted != 2 || users.BytesWritten != 20 {
+					t.Errorf("unexpected users breakdown: %+v", users)
+				}
+				if stats.BytesWritten != 20 {
+					t.Errorf("expected 20 bytes in total, got %d", stats.BytesWritten)
+				}
+			}
+
+			if lastRun.Load() != stats {
+				t.Error("expected stats to be published as the last run")
+			}
+
+			data, err := json.Marshal(stats)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var report map[string]any
+			json.Unmarshal(data, &report)
+			if _, ok := report["resources"]; !ok {
+				t.Errorf("report missing resources: %s", data)
+			}
+		})
+	}
+}
+
+func TestStats_RecordError(t *testing.T) {
+	s := &Stats{}
+	s.recordError(ResourceTasks)
+	s.recordError(ResourceTasks)
+	s.recordError(ResourceUsers)
+
+	if s.Errors != 3 || s.Resources[ResourceTasks].Errors != 2 || s.Resources[ResourceUsers].Errors != 1 {
+		t.Errorf("unexpected error counts: total=%d, resources=%+v", s.Errors, s.Resources)
+	}
+}
@@ -0,0 +1,95 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// StatusUpdateClient is implemented by Asana clients that can list goals,
+// portfolios and the status updates of a project, goal or portfolio
+type StatusUpdateClient interface {
+	StreamGoals(ctx context.Context, fn func(asana.Goal) error) error
+	StreamPortfolios(ctx context.Context, fn func(asana.Portfolio) error) error
+	StreamStatusUpdates(ctx context.Context, parentGID string, fn func(asana.StatusUpdate) error) error
+}
+
+// StatusUpdateWriter is implemented by storage backends that can store
+// status updates
+type StatusUpdateWriter interface {
+	WriteStatusUpdate(update asana.StatusUpdate) error
+}
+
+// extractStatusUpdates stores the status updates of every project, goal and
+// portfolio, fetching Config.Concurrency parents in parallel. Goals and
+// portfolios are skipped when the workspace's plan or the token cannot
+// list them.
+func (e *Extractor) extractStatusUpdates(ctx context.Context, results chan<- func(*Stats), projectGIDs []string) error {
+	ctx, span := tracer.Start(ctx, "extractor.status_updates")
+	defer span.End()
+
+	client, canRead := e.asanaClient.(StatusUpdateClient)
+	writer, canWrite := e.storage.(StatusUpdateWriter)
+	if !canRead || !canWrite {
+		return fmt.Errorf("status updates are not supported by this client or storage backend")
+	}
+
+	parents := append([]string(nil), projectGIDs...)
+	err := client.StreamGoals(ctx, func(goal asana.Goal) error {
+		parents = append(parents, goal.GID)
+		return nil
+	})
+	if err = e.skipUnavailable(ctx, "goal", err, results); err != nil {
+		return err
+	}
+	err = client.StreamPortfolios(ctx, func(portfolio asana.Portfolio) error {
+		parents = append(parents, portfolio.GID)
+		return nil
+	})
+	if err = e.skipUnavailable(ctx, "portfolio", err, results); err != nil {
+		return err
+	}
+
+	return e.fanOut(ctx, parents, func(ctx context.Context, parentGID string) error {
+		err := client.StreamStatusUpdates(ctx, parentGID, func(update asana.StatusUpdate) error {
+			if update.Parent == nil {
+				update.Parent = &asana.ParentRef{GID: parentGID}
+			}
+			if err := writer.WriteStatusUpdate(update); err != nil {
+				logf(ctx, "Error writing status update %s: %v", update.GID, err)
+				results <- func(s *Stats) { s.recordError(ResourceStatusUpdates); s.markLive(ResourceStatusUpdates, update.GID) }
+				return nil
+			}
+			results <- func(s *Stats) { s.StatusUpdatesExtracted++; s.markLive(ResourceStatusUpdates, update.GID) }
+			return nil
+		})
+		// A parent deleted or made private since it was listed only loses
+		// its own status updates
+		if errors.Is(err, asana.ErrNotFound) || errors.Is(err, asana.ErrForbidden) {
+			logf(ctx, "Skipping status updates of %s: %v", parentGID, err)
+			results <- func(s *Stats) { s.recordError(ResourceStatusUpdates); s.partial = true }
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("status update API failure for %s: %w", parentGID, err)
+		}
+		return nil
+	})
+}
+
+// skipUnavailable logs and drops an error listing the parents of kind when
+// the plan or token does not allow it, so the status updates of other
+// parents are still stored. Other errors are returned.
+func (e *Extractor) skipUnavailable(ctx context.Context, kind string, err error, results chan<- func(*Stats)) error {
+	if errors.Is(err, asana.ErrPaymentRequired) || errors.Is(err, asana.ErrForbidden) {
+		logf(ctx, "Skipping %s status updates: %v", kind, err)
+		results <- func(s *Stats) { s.partial = true }
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s API failure: %w", kind, err)
+	}
+	return nil
+}
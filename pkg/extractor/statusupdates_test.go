@@ -0,0 +1,116 @@
+package extractor
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// statusClient serves goals, portfolios and status updates by parent GID.
+// goalsErr simulates a plan without goals.
+type statusClient struct {
+	mockAsanaClient
+	goals      []asana.Goal
+	portfolios []asana.Portfolio
+	updates    map[string][]asana.StatusUpdate
+	goalsErr   error
+}
+
+func (m *statusClient) StreamGoals(ctx context.Context, fn func(asana.Goal) error) error {
+	if m.goalsErr != nil {
+		return m.goalsErr
+	}
+	for _, g := range m.goals {
+		if err := fn(g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *statusClient) StreamPortfolios(ctx context.Context, fn func(asana.Portfolio) error) error {
+	for _, p := range m.portfolios {
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *statusClient) StreamStatusUpdates(ctx context.Context, parentGID string, fn func(asana.StatusUpdate) error) error {
+	for _, u := range m.updates[parentGID] {
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statusStorage keeps the status updates it is given
+type statusStorage struct {
+	reconcilingStorage
+	updateMu sync.Mutex
+	updates  map[string]asana.StatusUpdate
+}
+
+func (m *statusStorage) WriteStatusUpdate(update asana.StatusUpdate) error {
+	m.updateMu.Lock()
+	defer m.updateMu.Unlock()
+	m.updates[update.GID] = update
+	return nil
+}
+
+func TestExtractor_StatusUpdates(t *testing.T) {
+	newClient := func() *statusClient {
+		return &statusClient{
+			mockAsanaClient: mockAsanaClient{projects: []asana.Project{{GID: "p1"}}},
+			goals:           []asana.Goal{{GID: "g1"}},
+			portfolios:      []asana.Portfolio{{GID: "pf1"}},
+			updates: map[string][]asana.StatusUpdate{
+				"p1":  {{GID: "s1", Parent: &asana.ParentRef{GID: "p1", ResourceType: "project"}}},
+				"g1":  {{GID: "s2"}},
+				"pf1": {{GID: "s3", Parent: &asana.ParentRef{GID: "pf1", ResourceType: "portfolio"}}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		goalsErr      error
+		wantUpdates   int
+		wantReconcile bool
+	}{
+		{name: "projects, goals and portfolios", wantUpdates: 3, wantReconcile: true},
+		{name: "plan without goals", goalsErr: asana.ErrPaymentRequired, wantUpdates: 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newClient()
+			client.goalsErr = tc.goalsErr
+			store := &statusStorage{updates: make(map[string]asana.StatusUpdate)}
+
+			cfg := Config{Resources: []string{ResourceStatusUpdates}, Concurrency: 2, Reconcile: ReconcileDelete}
+			stats, err := New(client, store, cfg).Extract(context.Background())
+			if err != nil {
+				t.Fatalf("Extract() failed: %v", err)
+			}
+
+			if stats.StatusUpdatesExtracted != tc.wantUpdates || len(store.updates) != tc.wantUpdates {
+				t.Fatalf("Expected %d status updates, got %d", tc.wantUpdates, stats.StatusUpdatesExtracted)
+			}
+			if stats.ProjectsExtracted != 0 {
+				t.Error("Expected projects to be walked without being written")
+			}
+			if _, reconciled := store.live[ResourceStatusUpdates]; reconciled != tc.wantReconcile {
+				t.Errorf("Expected reconciliation %v after a run skipping goals: %v", tc.wantReconcile, tc.goalsErr)
+			}
+			if tc.goalsErr == nil {
+				if p := store.updates["s2"].Parent; p == nil || p.GID != "g1" {
+					t.Errorf("Expected a parent reference on every update, got %+v", p)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// ErrSkipEntity, returned by a Transformer, drops the entity without
+// counting an error. It is counted as skipped, like a filtered entity.
+var ErrSkipEntity = errors.New("skip entity")
+
+// Transformer rewrites an entity between fetch and store, e.g. to normalize
+// fields, enrich it or redact it. Any error other than ErrSkipEntity keeps
+// the entity from being stored and counts as an error for its resource.
+type Transformer[T any] func(T) (T, error)
+
+// Transformers run on every entity of their resource, in order, after the
+// Filters and before the write. They may be called from several goroutines
+// at once.
+type Transformers struct {
+	Users    []Transformer[asana.User]
+	Projects []Transformer[asana.Project]
+	Tasks    []Transformer[asana.Task]
+	Teams    []Transformer[asana.Team]
+}
+
+// transform runs fns over *v and reports whether the result should be
+// stored. A dropped or failed entity is recorded in the stats.
+func transform[T any](ctx context.Context, v *T, fns []Transformer[T], resource, gid string, results chan<- func(*Stats)) bool {
+	out := *v
+	for _, fn := range fns {
+		var err error
+		out, err = fn(out)
+		if errors.Is(err, ErrSkipEntity) {
+			results <- func(s *Stats) { s.recordSkip(resource) }
+			return false
+		}
+		if err != nil {
+			logf(ctx, "Error transforming %s %s: %v", resource, gid, err)
+			results <- func(s *Stats) { s.recordError(resource); s.markLive(resource, gid) }
+			return false
+		}
+	}
+	*v = out
+	return true
+}
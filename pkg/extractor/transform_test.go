@@ -0,0 +1,76 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestExtractor_Transformers(t *testing.T) {
+	client := &mockAsanaClient{
+		users:    []asana.User{{GID: "u1", Email: "Ada@Example.com"}, {GID: "u2", Email: "bot@example.com"}},
+		projects: []asana.Project{{GID: "p1"}, {GID: "p2"}},
+		tasks: map[string][]asana.Task{
+			"p1": {{GID: "t1", Name: "secret"}},
+			"p2": {{GID: "t2", Name: "secret"}},
+		},
+		teams: []asana.Team{{GID: "tm1", Name: "Engineering"}},
+	}
+	store := &mockStorage{}
+	ext := New(client, store, Config{
+		Transformers: Transformers{
+			Users: []Transformer[asana.User]{
+				func(u asana.User) (asana.User, error) {
+					if strings.HasPrefix(u.Email, "bot@") {
+						return u, ErrSkipEntity
+					}
+					return u, nil
+				},
+				func(u asana.User) (asana.User, error) {
+					u.Email = strings.ToLower(u.Email)
+					return u, nil
+				},
+			},
+			Projects: []Transformer[asana.Project]{func(p asana.Project) (asana.Project, error) {
+				if p.GID == "p2" {
+					return p, errors.New("lookup failed")
+				}
+				return p, nil
+			}},
+			Tasks: []Transformer[asana.Task]{func(task asana.Task) (asana.Task, error) {
+				task.Name = "[redacted]"
+				return task, nil
+			}},
+		},
+	})
+
+	stats, err := ext.Extract(context.Background())
+	if err != nil {
+		t.Fatalf("Extract() failed: %v", err)
+	}
+
+	if len(store.users) != 1 || store.users[0].Email != "ada@example.com" {
+		t.Errorf("Expected the normalized user only, got %+v", store.users)
+	}
+	if len(store.projects) != 1 || store.projects[0].GID != "p1" {
+		t.Errorf("Expected only p1 stored, got %+v", store.projects)
+	}
+	// A project whose transform failed still has its tasks extracted
+	if len(store.tasks) != 2 {
+		t.Fatalf("Expected 2 tasks stored, got %d", len(store.tasks))
+	}
+	for _, task := range store.tasks {
+		if task.Name != "[redacted]" {
+			t.Errorf("Task %s stored with name %q", task.GID, task.Name)
+		}
+	}
+	if len(store.teams) != 1 {
+		t.Errorf("Expected the team stored untouched, got %+v", store.teams)
+	}
+	if stats.Resources[ResourceUsers].Skipped != 1 || stats.Resources[ResourceProjects].Errors != 1 {
+		t.Errorf("Expected 1 user skipped and 1 project error, got %+v", stats.Resources)
+	}
+}
@@ -0,0 +1,66 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// UserTaskListClient is implemented by Asana clients that can read each
+// user's My Tasks list
+type UserTaskListClient interface {
+	GetUserTaskList(ctx context.Context, userGID string) (*asana.UserTaskList, error)
+	StreamUserTaskListTasks(ctx context.Context, listGID string, fn func(asana.Task) error) error
+}
+
+// UserTaskListWriter is implemented by storage backends that can store user
+// task lists
+type UserTaskListWriter interface {
+	WriteUserTaskList(list asana.UserTaskList) error
+}
+
+// extractUserTaskLists stores the task list of every user, with its
+// incomplete tasks, fetching Config.Concurrency users in parallel
+func (e *Extractor) extractUserTaskLists(ctx context.Context, results chan<- func(*Stats), userGIDs []string) error {
+	ctx, span := tracer.Start(ctx, "extractor.user_task_lists")
+	defer span.End()
+
+	client, canRead := e.asanaClient.(UserTaskListClient)
+	writer, canWrite := e.storage.(UserTaskListWriter)
+	if !canRead || !canWrite {
+		return fmt.Errorf("user task lists are not supported by this client or storage backend")
+	}
+
+	return e.fanOut(ctx, userGIDs, func(ctx context.Context, userGID string) error {
+		list, err := client.GetUserTaskList(ctx, userGID)
+		if err == nil {
+			err = client.StreamUserTaskListTasks(ctx, list.GID, func(task asana.Task) error {
+				list.Tasks = append(list.Tasks, task)
+				return nil
+			})
+		}
+		// A user removed since they were listed, or one without a task
+		// list in this workspace such as a guest, only loses their own list
+		if errors.Is(err, asana.ErrNotFound) || errors.Is(err, asana.ErrForbidden) {
+			logf(ctx, "Skipping task list of user %s: %v", userGID, err)
+			results <- func(s *Stats) { s.recordError(ResourceUserTaskLists); s.partial = true }
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("user task list API failure for user %s: %w", userGID, err)
+		}
+
+		if list.Tasks == nil {
+			list.Tasks = []asana.Task{}
+		}
+		if err := writer.WriteUserTaskList(*list); err != nil {
+			logf(ctx, "Error writing task list of user %s: %v", userGID, err)
+			results <- func(s *Stats) { s.recordError(ResourceUserTaskLists); s.markLive(ResourceUserTaskLists, list.GID) }
+			return nil
+		}
+		results <- func(s *Stats) { s.UserTaskListsExtracted++; s.markLive(ResourceUserTaskLists, list.GID) }
+		return nil
+	})
+}
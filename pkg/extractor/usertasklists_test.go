@@ -0,0 +1,84 @@
+package extractor
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// taskListClient serves a task list per user; users without one get
+// asana.ErrForbidden, like guests
+type taskListClient struct {
+	mockAsanaClient
+	lists map[string][]asana.Task
+}
+
+func (m *taskListClient) GetUserTaskList(ctx context.Context, userGID string) (*asana.UserTaskList, error) {
+	if _, ok := m.lists[userGID]; !ok {
+		return nil, asana.ErrForbidden
+	}
+	return &asana.UserTaskList{GID: "list-" + userGID, Owner: &asana.User{GID: userGID}}, nil
+}
+
+func (m *taskListClient) StreamUserTaskListTasks(ctx context.Context, listGID string, fn func(asana.Task) error) error {
+	for _, task := range m.lists[listGID[len("list-"):]] {
+		if err := fn(task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// taskListStorage keeps the user task lists it is given
+type taskListStorage struct {
+	mockStorage
+	listMu sync.Mutex
+	lists  map[string]asana.UserTaskList
+}
+
+func (m *taskListStorage) WriteUserTaskList(list asana.UserTaskList) error {
+	m.listMu.Lock()
+	defer m.listMu.Unlock()
+	m.lists[list.Owner.GID] = list
+	return nil
+}
+
+func TestExtractor_UserTaskLists(t *testing.T) {
+	client := &taskListClient{
+		mockAsanaClient: mockAsanaClient{users: []asana.User{{GID: "u1"}, {GID: "u2"}, {GID: "guest"}}},
+		lists: map[string][]asana.Task{
+			"u1": {{GID: "t1"}, {GID: "t2"}},
+			"u2": nil,
+		},
+	}
+	store := &taskListStorage{lists: make(map[string]asana.UserTaskList)}
+
+	cfg := Config{Resources: []string{ResourceUserTaskLists}, Concurrency: 2}
+	stats, err := New(client, store, cfg).Extract(context.Background())
+	if err != nil {
+		t.Fatalf("Extract() failed: %v", err)
+	}
+
+	if stats.UserTaskListsExtracted != 2 || len(store.lists) != 2 {
+		t.Fatalf("Expected 2 task lists, got %d", stats.UserTaskListsExtracted)
+	}
+	if len(store.lists["u1"].Tasks) != 2 || store.lists["u2"].Tasks == nil {
+		t.Errorf("Expected each list with its tasks, got %+v", store.lists)
+	}
+	if stats.UsersExtracted != 0 || len(store.users) != 0 {
+		t.Error("Expected users to be walked without being written")
+	}
+	if r := stats.Resources[ResourceUserTaskLists]; r == nil || r.Errors != 1 {
+		t.Errorf("Expected the guest without a task list to count as an error, got %+v", r)
+	}
+}
+
+func TestExtractor_UserTaskListsUnsupported(t *testing.T) {
+	client := &taskListClient{mockAsanaClient: mockAsanaClient{users: []asana.User{{GID: "u1"}}}}
+	cfg := Config{Resources: []string{ResourceUsers, ResourceUserTaskLists}}
+	if _, err := New(client, &mockStorage{}, cfg).Extract(context.Background()); err == nil {
+		t.Error("Expected an error when storage cannot write user task lists")
+	}
+}
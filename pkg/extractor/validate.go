@@ -0,0 +1,93 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// RejectWriter is implemented by storage backends that can quarantine
+// entities failing validation, to be inspected instead of stored
+type RejectWriter interface {
+	WriteReject(resource string, record any, reason string) error
+}
+
+// errInvalidGID is the reason an entity whose GID cannot name a file is
+// rejected
+var errInvalidGID = errors.New("invalid GID")
+
+// validGID reports whether gid is non-empty and made only of letters,
+// digits, '-' and '_', so it is safe as a file name and in API paths
+func validGID(gid string) bool {
+	if gid == "" {
+		return false
+	}
+	for _, r := range gid {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// checkDate returns an error unless value is empty or a YYYY-MM-DD date
+func checkDate(field, value string) error {
+	if value == "" {
+		return nil
+	}
+	if _, err := time.Parse(time.DateOnly, value); err != nil {
+		return fmt.Errorf("invalid %s %q", field, value)
+	}
+	return nil
+}
+
+// validate normalizes the GID of *entity, a pointer to a user, team,
+// project or task, and checks its required fields. It reports whether the
+// entity may be processed; one that may not is written to the storage's
+// rejects, when it has them, and counted as rejected.
+func (e *Extractor) validate(ctx context.Context, results chan<- func(*Stats), resource string, entity any) bool {
+	var gid *string
+	var err error
+	switch v := entity.(type) {
+	case *asana.User:
+		gid = &v.GID
+	case *asana.Team:
+		gid = &v.GID
+	case *asana.Project:
+		gid = &v.GID
+	case *asana.Task:
+		gid = &v.GID
+		err = errors.Join(checkDate("due_on", v.DueOn), checkDate("start_on", v.StartOn))
+	default:
+		panic(fmt.Sprintf("validate: unsupported entity %T", entity))
+	}
+
+	*gid = strings.TrimSpace(*gid)
+	valid := validGID(*gid)
+	if !valid {
+		err = fmt.Errorf("%w %q", errInvalidGID, *gid)
+	}
+	if err == nil {
+		return true
+	}
+
+	id := *gid
+	logf(ctx, "Rejecting %s %q: %v", resource, id, err)
+	if w, ok := e.storage.(RejectWriter); ok {
+		if werr := w.WriteReject(resource, entity, err.Error()); werr != nil {
+			logf(ctx, "Error quarantining %s %q: %v", resource, id, werr)
+		}
+	}
+	results <- func(s *Stats) {
+		s.recordReject(resource)
+		// A stored copy of an entity with a valid GID is not orphaned
+		if valid {
+			s.markLive(resource, id)
+		}
+	}
+	return false
+}
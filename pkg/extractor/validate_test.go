@@ -0,0 +1,85 @@
+package extractor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// rejectStorage records the entities quarantined by the extractor
+type rejectStorage struct {
+	mockStorage
+	reasons map[string][]string
+}
+
+func (r *rejectStorage) WriteReject(resource string, record any, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.reasons == nil {
+		r.reasons = make(map[string][]string)
+	}
+	r.reasons[resource] = append(r.reasons[resource], reason)
+	return nil
+}
+
+func TestValidGID(t *testing.T) {
+	tests := []struct {
+		gid  string
+		want bool
+	}{
+		{gid: "1201234567890", want: true},
+		{gid: "team_1-a", want: true},
+		{gid: "", want: false},
+		{gid: "../etc", want: false},
+		{gid: "a/b", want: false},
+		{gid: "12 34", want: false},
+		{gid: "12\x00", want: false},
+	}
+
+	for _, tc := range tests {
+		if got := validGID(tc.gid); got != tc.want {
+			t.Errorf("validGID(%q) = %v, want %v", tc.gid, got, tc.want)
+		}
+	}
+}
+
+func TestExtractor_Validate(t *testing.T) {
+	client := &mockAsanaClient{
+		users:    []asana.User{{GID: " u1 "}, {GID: ""}},
+		projects: []asana.Project{{GID: "p1"}, {GID: "../p2"}},
+		teams:    []asana.Team{{GID: "team1"}},
+		tasks: map[string][]asana.Task{
+			"p1": {{GID: "t1", DueOn: "2026-03-01"}, {GID: "t2", DueOn: "03/01/2026"}, {GID: "t3", StartOn: "2026-02-30"}},
+		},
+	}
+	store := &rejectStorage{}
+	ext := New(client, store, Config{})
+
+	stats, err := ext.Extract(context.Background())
+	if err != nil {
+		t.Fatalf("Extract() failed: %v", err)
+	}
+
+	if len(store.users) != 1 || store.users[0].GID != "u1" {
+		t.Errorf("Expected the user's GID to be trimmed, got %+v", store.users)
+	}
+	if len(store.projects) != 1 || len(store.tasks) != 1 || store.tasks[0].GID != "t1" {
+		t.Errorf("Expected only valid projects and tasks stored, got %+v and %+v", store.projects, store.tasks)
+	}
+	if stats.Rejected != 4 || stats.Errors != 0 {
+		t.Errorf("Expected 4 rejected and no errors, got %d and %d", stats.Rejected, stats.Errors)
+	}
+	if got := stats.Resources[ResourceTasks].Rejected; got != 2 {
+		t.Errorf("Expected 2 rejected tasks, got %d", got)
+	}
+
+	if len(store.reasons[ResourceUsers]) != 1 || len(store.reasons[ResourceProjects]) != 1 {
+		t.Errorf("Expected the user and project to be quarantined, got %v", store.reasons)
+	}
+	tasks := store.reasons[ResourceTasks]
+	if len(tasks) != 2 || !strings.Contains(tasks[0], "due_on") || !strings.Contains(tasks[1], "start_on") {
+		t.Errorf("Unexpected task reasons: %v", tasks)
+	}
+}
@@ -0,0 +1,206 @@
+package extractor
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// DefaultVerifyThreshold is the divergence above which a snapshot is
+// flagged as suspect when Verify.Threshold is unset
+const DefaultVerifyThreshold = 0.01
+
+// EntityChecker is implemented by Asana clients that can look up a single
+// entity, which Verify.SampleSize needs
+type EntityChecker interface {
+	Exists(ctx context.Context, resource, gid string) (bool, error)
+}
+
+// Verify configures the cross-check of a successful run against Asana.
+// A snapshot whose counts diverge by more than Threshold is flagged as
+// suspect in the manifest; the run itself still succeeds.
+type Verify struct {
+	// Recount lists users, teams and projects again after the run and
+	// compares the totals with what the run saw. Tasks are only sampled,
+	// since recounting them means walking every project again.
+	Recount bool
+	// SampleSize spot-checks this many random GIDs per resource, looking
+	// each up in Asana. Zero disables sampling.
+	SampleSize int
+	// Threshold is the largest tolerated divergence, as a share (0-1) of
+	// the Asana total or of the sample
+	Threshold float64
+}
+
+// enabled reports whether any check is configured
+func (v Verify) enabled() bool {
+	return v.Recount || v.SampleSize > 0
+}
+
+// Verification is the outcome of the checks of one run
+type Verification struct {
+	Suspect bool                     `json:"suspect"`
+	Checks  map[string]*VerifyResult `json:"checks"`
+}
+
+// VerifyResult holds the checks of one resource
+type VerifyResult struct {
+	// Seen counts entities the run listed: stored, failed or skipped
+	Seen int `json:"seen"`
+	// Asana is the recounted total, when recounting
+	Asana *int `json:"asana,omitempty"`
+	// Sampled and Missing count spot-checked GIDs and those Asana no
+	// longer returns
+	Sampled int `json:"sampled,omitempty"`
+	Missing int `json:"missing,omitempty"`
+	// Divergence is the larger of the count and sample divergences
+	Divergence float64 `json:"divergence"`
+	Suspect    bool    `json:"suspect"`
+	// Error is set when a check could not complete
+	Error string `json:"error,omitempty"`
+}
+
+// sampler keeps a uniform random sample of the GIDs listed per resource
+type sampler struct {
+	size  int
+	seen  map[string]int
+	picks map[string][]string
+}
+
+// add offers gid to the resource's sample (reservoir sampling)
+func (s *sampler) add(resource, gid string) {
+	if s == nil {
+		return
+	}
+	s.seen[resource]++
+	picks := s.picks[resource]
+	if len(picks) < s.size {
+		s.picks[resource] = append(picks, gid)
+		return
+	}
+	if i := rand.IntN(s.seen[resource]); i < s.size {
+		picks[i] = gid
+	}
+}
+
+// newSampler returns a sampler for the configured sample size, or nil
+func (e *Extractor) newSampler() *sampler {
+	if e.cfg.Verify.SampleSize <= 0 {
+		return nil
+	}
+	return &sampler{size: e.cfg.Verify.SampleSize, seen: make(map[string]int), picks: make(map[string][]string)}
+}
+
+// verify cross-checks the resources written by a successful run against
+// Asana. API usage is counted against each resource's phase.
+func (e *Extractor) verify(ctx context.Context, stats *Stats, usage *phaseUsage) *Verification {
+	threshold := e.cfg.Verify.Threshold
+	if threshold <= 0 {
+		threshold = DefaultVerifyThreshold
+	}
+	checker, canSample := e.asanaClient.(EntityChecker)
+
+	v := &Verification{Checks: make(map[string]*VerifyResult)}
+	for _, resource := range e.cfg.Resources {
+		// Audit log events are a window of history, not a listing, and
+		// avatars and attachments are files rather than entities
+		if resource == ResourceAuditLogEvents || resource == ResourceAvatars || resource == ResourceAttachments {
+			continue
+		}
+		r := stats.resource(resource)
+		res := &VerifyResult{Seen: extractedCount(stats, resource) + r.Errors + r.Skipped}
+		phaseCtx := usage.context(ctx, resource)
+
+		// Tasks, user task lists and status updates cannot be listed
+		// workspace-wide
+		if e.cfg.Verify.Recount && recountable(resource) {
+			total, err := e.recount(phaseCtx, resource)
+			if err != nil {
+				logf(ctx, "Error recounting %s: %v", resource, err)
+				res.Error = err.Error()
+			} else {
+				res.Asana = &total
+				res.Divergence = math.Abs(float64(res.Seen-total)) / float64(max(total, 1))
+			}
+		}
+
+		if canSample && stats.sample != nil {
+			for _, gid := range stats.sample.picks[resource] {
+				ok, err := checker.Exists(phaseCtx, resource, gid)
+				if err != nil {
+					logf(ctx, "Error spot-checking %s %s: %v", resource, gid, err)
+					res.Error = err.Error()
+					break
+				}
+				res.Sampled++
+				if !ok {
+					res.Missing++
+				}
+			}
+			if res.Sampled > 0 {
+				res.Divergence = max(res.Divergence, float64(res.Missing)/float64(res.Sampled))
+			}
+		}
+
+		if res.Divergence > threshold {
+			res.Suspect = true
+			v.Suspect = true
+			logf(ctx, "Snapshot looks incomplete: %s diverge from Asana by %.1f%%", resource, res.Divergence*100)
+		}
+		v.Checks[resource] = res
+	}
+	return v
+}
+
+// extractedCount returns the entities of resource written so far
+func extractedCount(stats *Stats, resource string) int {
+	switch resource {
+	case ResourceUsers:
+		return stats.UsersExtracted
+	case ResourceProjects:
+		return stats.ProjectsExtracted
+	case ResourceTasks:
+		return stats.TasksExtracted
+	case ResourceTeams:
+		return stats.TeamsExtracted
+	case ResourceUserTaskLists:
+		return stats.UserTaskListsExtracted
+	case ResourceAvatars:
+		return stats.AvatarsExtracted
+	case ResourceAttachments:
+		return stats.AttachmentsExtracted
+	case ResourceStatusUpdates:
+		return stats.StatusUpdatesExtracted
+	case ResourceCustomFields:
+		return stats.CustomFieldsExtracted
+	case ResourceAuditLogEvents:
+		return stats.AuditLogEventsExtracted
+	}
+	return 0
+}
+
+// recountable reports whether recount can list resource
+func recountable(resource string) bool {
+	switch resource {
+	case ResourceUsers, ResourceProjects, ResourceTeams:
+		return true
+	}
+	return false
+}
+
+// recount lists resource again and returns how many entities Asana has
+func (e *Extractor) recount(ctx context.Context, resource string) (int, error) {
+	n := 0
+	var err error
+	switch resource {
+	case ResourceUsers:
+		err = e.asanaClient.StreamUsers(ctx, func(asana.User) error { n++; return nil })
+	case ResourceProjects:
+		err = e.asanaClient.StreamProjects(ctx, func(asana.Project) error { n++; return nil })
+	case ResourceTeams:
+		err = e.asanaClient.StreamTeams(ctx, func(asana.Team) error { n++; return nil })
+	}
+	return n, err
+}
@@ -0,0 +1,131 @@
+package extractor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// checkingAsanaClient looks entities up for spot checks and returns
+// lateUsers from every listing of users after the first
+type checkingAsanaClient struct {
+	*mockAsanaClient
+	gone      map[string]bool
+	lateUsers []asana.User
+	listings  int
+}
+
+func (c *checkingAsanaClient) StreamUsers(ctx context.Context, fn func(asana.User) error) error {
+	c.listings++
+	if c.listings > 1 {
+		for _, u := range c.lateUsers {
+			if err := fn(u); err != nil {
+				return err
+			}
+		}
+	}
+	return c.mockAsanaClient.StreamUsers(ctx, fn)
+}
+
+func (c *checkingAsanaClient) Exists(ctx context.Context, resource, gid string) (bool, error) {
+	return !c.gone[gid], nil
+}
+
+func TestExtractor_Verify(t *testing.T) {
+	users := []asana.User{{GID: "u1"}, {GID: "u2"}, {GID: "u3"}, {GID: "u4"}}
+	tasks := map[string][]asana.Task{"p1": {{GID: "t1"}, {GID: "t2"}}}
+
+	tests := []struct {
+		name        string
+		verify      Verify
+		gone        map[string]bool
+		lateUsers   []asana.User
+		wantSuspect bool
+		check       func(t *testing.T, v *Verification)
+	}{
+		{
+			name:   "Matching recount",
+			verify: Verify{Recount: true},
+			check: func(t *testing.T, v *Verification) {
+				if c := v.Checks[ResourceUsers]; c.Asana == nil || *c.Asana != 4 || c.Seen != 4 {
+					t.Errorf("Expected 4 users seen and recounted, got %+v", c)
+				}
+				if c := v.Checks[ResourceTasks]; c.Asana != nil {
+					t.Errorf("Expected tasks not to be recounted, got %+v", c)
+				}
+			},
+		},
+		{
+			name:        "Users added since the listing",
+			verify:      Verify{Recount: true, Threshold: 0.1},
+			lateUsers:   []asana.User{{GID: "u5"}},
+			wantSuspect: true,
+			check: func(t *testing.T, v *Verification) {
+				if c := v.Checks[ResourceUsers]; !c.Suspect || c.Divergence != 0.2 {
+					t.Errorf("Expected users to diverge by 20%%, got %+v", c)
+				}
+			},
+		},
+		{
+			name:        "Tolerated divergence",
+			verify:      Verify{Recount: true, Threshold: 0.5},
+			lateUsers:   []asana.User{{GID: "u5"}},
+			wantSuspect: false,
+		},
+		{
+			name:        "Sampled task gone",
+			verify:      Verify{SampleSize: 10},
+			gone:        map[string]bool{"t2": true},
+			wantSuspect: true,
+			check: func(t *testing.T, v *Verification) {
+				if c := v.Checks[ResourceTasks]; c.Sampled != 2 || c.Missing != 1 {
+					t.Errorf("Expected 1 of 2 sampled tasks missing, got %+v", c)
+				}
+				if c := v.Checks[ResourceUsers]; c.Sampled != 4 || c.Missing != 0 {
+					t.Errorf("Expected 4 users sampled, got %+v", c)
+				}
+			},
+		},
+		{
+			name:   "Sample smaller than the listing",
+			verify: Verify{SampleSize: 2},
+			check: func(t *testing.T, v *Verification) {
+				if c := v.Checks[ResourceUsers]; c.Sampled != 2 {
+					t.Errorf("Expected 2 users sampled, got %+v", c)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &checkingAsanaClient{
+				mockAsanaClient: &mockAsanaClient{users: users, projects: []asana.Project{{GID: "p1"}}, tasks: tasks},
+				gone:            tc.gone,
+				lateUsers:       tc.lateUsers,
+			}
+			store := &manifestStorage{}
+			ext := New(client, store, Config{Verify: tc.verify})
+
+			stats, err := ext.Extract(context.Background())
+			if err != nil {
+				t.Fatalf("Extract() failed: %v", err)
+			}
+
+			v := stats.Verification
+			if v == nil {
+				t.Fatal("Expected a verification")
+			}
+			if v.Suspect != tc.wantSuspect {
+				t.Errorf("Suspect = %t, want %t (%+v)", v.Suspect, tc.wantSuspect, v.Checks)
+			}
+			if m := store.manifests[0]; m.Suspect != tc.wantSuspect || m.Verification != v {
+				t.Errorf("Expected the manifest to carry the verification, got suspect=%t", m.Suspect)
+			}
+			if tc.check != nil {
+				tc.check(t, v)
+			}
+		})
+	}
+}
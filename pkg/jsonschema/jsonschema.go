@@ -0,0 +1,137 @@
+// Package jsonschema derives JSON Schema (draft 2020-12) documents from Go
+// structs. Schemas follow encoding/json's rules for the structs' JSON tags,
+// so they describe exactly the JSON the extractor writes.
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft is the meta-schema of every generated schema
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document or subschema. Only the keywords needed
+// to describe Go structs are supported.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Generate returns the schema of v's struct type. Every struct type is
+// defined once in $defs, named after the Go type, and referred to with
+// $ref, so recursive types work; the root refers to v's own definition.
+// Fields map as follows: strings, booleans, integers and floats to their
+// JSON types; time.Time to a date-time string; slices to arrays, []byte to
+// a base64 string; string-keyed maps to objects; pointers, and slices and
+// maps without omitempty, also allow null. Fields without omitempty are
+// required. Types with their own JSON encoding accept any value.
+func Generate(v any) (*Schema, error) {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("JSON schemas need a struct, got %T", v)
+	}
+
+	defs := make(map[string]*Schema)
+	root := build(t, defs)
+	root.Schema = Draft
+	root.Defs = defs
+	return root, nil
+}
+
+// build returns the schema of t, adding struct definitions to defs
+func build(t reflect.Type, defs map[string]*Schema) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Implements(marshalerType) || t.Implements(textMarshalerType):
+		return &Schema{}
+	case t.Kind() == reflect.String:
+		return &Schema{Type: "string"}
+	case t.Kind() == reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return &Schema{Type: "number"}
+	case t.Kind() == reflect.Pointer:
+		return nullable(build(t.Elem(), defs))
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return &Schema{Type: "string", ContentEncoding: "base64"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return &Schema{Type: "array", Items: build(t.Elem(), defs)}
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+		return &Schema{Type: "object", AdditionalProperties: build(t.Elem(), defs)}
+	case t.Kind() == reflect.Struct:
+		return object(t, defs)
+	default:
+		return &Schema{}
+	}
+}
+
+// object defines the struct type t in defs, unless it already is, and
+// returns a reference to it
+func object(t reflect.Type, defs map[string]*Schema) *Schema {
+	ref := &Schema{Ref: "#/$defs/" + t.Name()}
+	if _, ok := defs[t.Name()]; ok {
+		return ref
+	}
+	def := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	defs[t.Name()] = def
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		name, opts, _ := strings.Cut(tag, ",")
+		if !f.IsExported() || name == "-" && opts == "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		omitempty := strings.Contains(","+opts+",", ",omitempty,")
+
+		schema := build(f.Type, defs)
+		if !omitempty && (f.Type.Kind() == reflect.Slice || f.Type.Kind() == reflect.Map) {
+			schema = nullable(schema)
+		}
+		def.Properties[name] = schema
+		if !omitempty {
+			def.Required = append(def.Required, name)
+		}
+	}
+	return ref
+}
+
+// nullable returns s also accepting null
+func nullable(s *Schema) *Schema {
+	if typ, ok := s.Type.(string); ok && s.Ref == "" {
+		s.Type = []string{typ, "null"}
+		return s
+	}
+	if s.Type == nil && s.Ref == "" {
+		// Accepts anything already
+		return s
+	}
+	return &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+}
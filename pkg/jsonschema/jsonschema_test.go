@@ -0,0 +1,82 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestGenerate(t *testing.T) {
+	type node struct {
+		Name     string            `json:"name"`
+		Count    int               `json:"count,omitempty"`
+		Ratio    *float64          `json:"ratio"`
+		At       time.Time         `json:"at"`
+		Tags     []string          `json:"tags"`
+		Labels   map[string]string `json:"labels,omitempty"`
+		Raw      []byte            `json:"raw,omitempty"`
+		Parent   *node             `json:"parent,omitempty"`
+		Any      any               `json:"any,omitempty"`
+		Skipped  string            `json:"-"`
+		internal string
+	}
+
+	schema, err := Generate(node{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if schema.Schema != Draft || schema.Ref != "#/$defs/node" {
+		t.Fatalf("unexpected root: %+v", schema)
+	}
+
+	def := schema.Defs["node"]
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{field: "name", want: `{"type":"string"}`},
+		{field: "count", want: `{"type":"integer"}`},
+		{field: "ratio", want: `{"type":["number","null"]}`},
+		{field: "at", want: `{"type":"string","format":"date-time"}`},
+		{field: "tags", want: `{"type":["array","null"],"items":{"type":"string"}}`},
+		{field: "labels", want: `{"type":"object","additionalProperties":{"type":"string"}}`},
+		{field: "raw", want: `{"type":"string","contentEncoding":"base64"}`},
+		{field: "parent", want: `{"anyOf":[{"$ref":"#/$defs/node"},{"type":"null"}]}`},
+		{field: "any", want: `{}`},
+	}
+	for _, tc := range tests {
+		got, err := json.Marshal(def.Properties[tc.field])
+		if err != nil || string(got) != tc.want {
+			t.Errorf("%s = %s, want %s", tc.field, got, tc.want)
+		}
+	}
+
+	if len(def.Properties) != len(tests) {
+		t.Errorf("expected %d properties, got %d", len(tests), len(def.Properties))
+	}
+	if want := []string{"name", "ratio", "at", "tags"}; !reflect.DeepEqual(def.Required, want) {
+		t.Errorf("required = %v, want %v", def.Required, want)
+	}
+}
+
+func TestGenerate_Asana(t *testing.T) {
+	schema, err := Generate(asana.Task{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Task", "Project", "User", "Membership", "CustomFieldValue", "EnumOption"} {
+		if _, ok := schema.Defs[name]; !ok {
+			t.Errorf("expected %s in $defs", name)
+		}
+	}
+	if _, err := json.Marshal(schema); err != nil {
+		t.Errorf("failed to marshal schema: %v", err)
+	}
+
+	if _, err := Generate("not a struct"); err == nil {
+		t.Error("expected an error for a non-struct value")
+	}
+}
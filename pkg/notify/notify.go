@@ -0,0 +1,182 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Event kinds
+const (
+	KindFailure     = "failure"
+	KindErrorBudget = "error_budget"
+	KindStale       = "stale"
+)
+
+// Event describes something operators should know about
+type Event struct {
+	Kind    string    `json:"kind"`
+	Job     string    `json:"job,omitempty"`
+	RunID   string    `json:"run_id,omitempty"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+// Config holds notifier configuration. Empty URLs disable their target.
+type Config struct {
+	// SlackWebhookURL receives {"text": ...} messages
+	SlackWebhookURL string
+	// WebhookURL receives the Event as JSON
+	WebhookURL string
+	// StaleAfter alerts when no run has succeeded for this long; zero
+	// disables the check
+	StaleAfter time.Duration
+	// Timeout bounds each delivery (default 10s)
+	Timeout time.Duration
+}
+
+// Notifier delivers events to the configured targets. A nil *Notifier is
+// valid and discards everything.
+type Notifier struct {
+	cfg        Config
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	lastSuccess time.Time
+	staleSent   bool
+}
+
+// New creates a notifier, or returns nil when no target is configured
+func New(cfg Config) *Notifier {
+	if cfg.SlackWebhookURL == "" && cfg.WebhookURL == "" {
+		return nil
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	return &Notifier{
+		cfg:         cfg,
+		httpClient:  &http.Client{Timeout: cfg.Timeout},
+		lastSuccess: time.Now(),
+	}
+}
+
+// RunSucceeded records a successful run, resetting the staleness clock
+func (n *Notifier) RunSucceeded() {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	n.lastSuccess = time.Now()
+	n.staleSent = false
+	n.mu.Unlock()
+}
+
+// RunFailed sends a failure event. kind distinguishes plain failures from
+// runs that exceeded their error budget.
+func (n *Notifier) RunFailed(ctx context.Context, kind, job, runID string, err error) {
+	if n == nil {
+		return
+	}
+	n.send(ctx, Event{
+		Kind:    kind,
+		Job:     job,
+		RunID:   runID,
+		Message: fmt.Sprintf("Asana extraction %s failed: %v", job, err),
+		Time:    time.Now().UTC(),
+	})
+}
+
+// Watch checks for staleness until ctx is done. It alerts once per stale
+// period; the next successful run re-arms it.
+func (n *Notifier) Watch(ctx context.Context) {
+	if n == nil || n.cfg.StaleAfter <= 0 {
+		return
+	}
+
+	interval := n.cfg.StaleAfter / 4
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n.checkStale(ctx, time.Now())
+		}
+	}
+}
+
+// checkStale sends a stale event if no run has succeeded within StaleAfter
+func (n *Notifier) checkStale(ctx context.Context, now time.Time) {
+	n.mu.Lock()
+	last := n.lastSuccess
+	stale := !n.staleSent && now.Sub(last) > n.cfg.StaleAfter
+	if stale {
+		n.staleSent = true
+	}
+	n.mu.Unlock()
+
+	if stale {
+		n.send(ctx, Event{
+			Kind:    KindStale,
+			Message: fmt.Sprintf("No successful Asana extraction since %s (more than %v ago)", last.UTC().Format(time.RFC3339), n.cfg.StaleAfter),
+			Time:    now.UTC(),
+		})
+	}
+}
+
+// send delivers event to every target, logging failures
+func (n *Notifier) send(ctx context.Context, event Event) {
+	if n.cfg.SlackWebhookURL != "" {
+		if err := n.post(ctx, n.cfg.SlackWebhookURL, map[string]string{"text": event.Message}); err != nil {
+			log.Printf("Failed to notify Slack: %v", err)
+		}
+	}
+	if n.cfg.WebhookURL != "" {
+		if err := n.post(ctx, n.cfg.WebhookURL, event); err != nil {
+			log.Printf("Failed to notify webhook: %v", err)
+		}
+	}
+}
+
+// post sends payload as JSON to url
+func (n *Notifier) post(ctx context.Context, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	// Deliver even when the run's own context has been cancelled
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), n.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder collects the JSON bodies posted to a test server
+type recorder struct {
+	mu     sync.Mutex
+	bodies []map[string]any
+}
+
+func (r *recorder) handler(w http.ResponseWriter, req *http.Request) {
+	var body map[string]any
+	json.NewDecoder(req.Body).Decode(&body)
+	r.mu.Lock()
+	r.bodies = append(r.bodies, body)
+	r.mu.Unlock()
+}
+
+func (r *recorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.bodies)
+}
+
+func TestNew_DisabledWithoutTargets(t *testing.T) {
+	n := New(Config{StaleAfter: time.Hour})
+	if n != nil {
+		t.Fatal("Expected nil notifier without targets")
+	}
+
+	// A nil notifier must be safe to use
+	n.RunSucceeded()
+	n.RunFailed(context.Background(), KindFailure, "job", "run", errors.New("boom"))
+	n.Watch(context.Background())
+}
+
+func TestNotifier_RunFailed(t *testing.T) {
+	slack, hook := &recorder{}, &recorder{}
+	slackServer := httptest.NewServer(http.HandlerFunc(slack.handler))
+	defer slackServer.Close()
+	hookServer := httptest.NewServer(http.HandlerFunc(hook.handler))
+	defer hookServer.Close()
+
+	n := New(Config{SlackWebhookURL: slackServer.URL, WebhookURL: hookServer.URL})
+	n.RunFailed(context.Background(), KindErrorBudget, "default", "run-1", errors.New("too many errors"))
+
+	if slack.count() != 1 || hook.count() != 1 {
+		t.Fatalf("Expected one delivery per target, got slack=%d webhook=%d", slack.count(), hook.count())
+	}
+	if text, _ := slack.bodies[0]["text"].(string); !strings.Contains(text, "too many errors") {
+		t.Errorf("Expected Slack text to include the error, got %q", text)
+	}
+	if hook.bodies[0]["kind"] != KindErrorBudget || hook.bodies[0]["run_id"] != "run-1" {
+		t.Errorf("Unexpected webhook payload: %v", hook.bodies[0])
+	}
+}
+
+func TestNotifier_CheckStale(t *testing.T) {
+	hook := &recorder{}
+	server := httptest.NewServer(http.HandlerFunc(hook.handler))
+	defer server.Close()
+
+	n := New(Config{WebhookURL: server.URL, StaleAfter: time.Hour})
+	ctx := context.Background()
+	now := time.Now()
+
+	tests := []struct {
+		name  string
+		setup func()
+		at    time.Time
+		want  int
+	}{
+		{name: "Fresh", at: now, want: 0},
+		{name: "Stale", at: now.Add(2 * time.Hour), want: 1},
+		{name: "Alerts once per stale period", at: now.Add(3 * time.Hour), want: 1},
+		{name: "Success re-arms", setup: n.RunSucceeded, at: time.Now().Add(2 * time.Hour), want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+			n.checkStale(ctx, tt.at)
+			if got := hook.count(); got != tt.want {
+				t.Errorf("Expected %d notifications, got %d", tt.want, got)
+			}
+		})
+	}
+
+	if hook.bodies[0]["kind"] != KindStale {
+		t.Errorf("Expected stale kind, got %v", hook.bodies[0]["kind"])
+	}
+}
+
+func TestNotifier_PostRejectsNon2xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	n := New(Config{WebhookURL: server.URL})
+	if err := n.post(context.Background(), server.URL, Event{}); err == nil {
+		t.Error("Expected error on non-2xx response")
+	}
+}
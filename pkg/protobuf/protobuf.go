@@ -0,0 +1,376 @@
+// Package protobuf encodes Go structs as Protocol Buffers (proto3)
+// messages and writes length-delimited streams of them. Message definitions
+// are derived from the structs' JSON tags, as pkg/avro derives its schemas,
+// so fields carry the same names as the JSON output; Proto renders them as
+// a .proto file. Field numbers follow the order the fields are declared
+// in, so fields must only ever be added at the end of a struct.
+package protobuf
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Package is the package of every generated message
+const Package = "asana"
+
+// timestampType is the well-known type times are encoded as
+const timestampType = "google.protobuf.Timestamp"
+
+// Wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	anyType  = reflect.TypeOf((*any)(nil)).Elem()
+)
+
+// Codec encodes values of one struct type as a protobuf message
+type Codec struct {
+	typ reflect.Type
+	// record is the name of the message Append encodes
+	record   string
+	messages []message
+	enc      func(b []byte, v reflect.Value) []byte
+	extra    int
+}
+
+// message is the definition of one message type
+type message struct {
+	name   string
+	fields []string
+}
+
+// scalar describes how values of one Go type are encoded
+type scalar struct {
+	// name is the protobuf type, such as "int64" or a message name
+	name string
+	wire int
+	// message is set for types encoded as messages, which have presence
+	message bool
+	// append appends the encoding of v, without a tag
+	append func(b []byte, v reflect.Value) []byte
+}
+
+// NewCodec derives the message definitions and encoder of v's struct type.
+// Fields map as follows: strings, booleans, integers and floats to string,
+// bool, int64, uint64 and double; time.Time to google.protobuf.Timestamp;
+// structs to messages; slices to repeated fields, []byte to bytes;
+// string-keyed maps to maps; pointers to scalars to optional fields;
+// anything else to its JSON encoding as a string. With extra fields, Append
+// encodes a <Type>Record message holding v as its first field, then a
+// string field for each of extra, for values that are not part of v, such
+// as the run that wrote the record.
+func NewCodec(v any, extra ...string) (*Codec, error) {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("protobuf codecs need a struct, got %T", v)
+	}
+
+	b := &builder{encoders: make(map[reflect.Type]*func([]byte, reflect.Value) []byte)}
+	body := b.message(t)
+	c := &Codec{typ: t, record: t.Name(), enc: body, extra: len(extra)}
+	if len(extra) > 0 {
+		c.record = t.Name() + "Record"
+		fields := []string{fmt.Sprintf("%s %s = 1;", t.Name(), snakeCase(t.Name()))}
+		for i, name := range extra {
+			fields = append(fields, fmt.Sprintf("string %s = %d;", name, i+2))
+		}
+		b.messages = append(b.messages, message{name: c.record, fields: fields})
+		c.enc = func(buf []byte, v reflect.Value) []byte {
+			return appendBytes(appendTag(buf, 1, wireBytes), body(nil, v))
+		}
+	}
+	c.messages = b.messages
+	return c, nil
+}
+
+// Message returns the name of the message Append encodes
+func (c *Codec) Message() string {
+	return c.record
+}
+
+// Append appends the protobuf encoding of v, which must be of the codec's
+// type, with the values of the codec's extra fields. Missing values are
+// empty strings.
+func (c *Codec) Append(b []byte, v any, extra ...string) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Type() != c.typ {
+		return b, fmt.Errorf("protobuf codec for %s cannot encode %T", c.typ, v)
+	}
+	if len(extra) > c.extra {
+		return b, fmt.Errorf("protobuf codec for %s has %d extra fields, got %d values", c.typ, c.extra, len(extra))
+	}
+	b = c.enc(b, rv)
+	for i, value := range extra {
+		if value != "" {
+			b = appendBytes(appendTag(b, i+2, wireBytes), []byte(value))
+		}
+	}
+	return b, nil
+}
+
+// Proto renders the messages of codecs as a .proto file. Messages used by
+// several codecs are defined once.
+func Proto(codecs ...*Codec) string {
+	var body strings.Builder
+	defined := make(map[string]bool)
+	timestamps := false
+	for _, c := range codecs {
+		for _, m := range c.messages {
+			if defined[m.name] {
+				continue
+			}
+			defined[m.name] = true
+			fmt.Fprintf(&body, "\nmessage %s {\n", m.name)
+			for _, f := range m.fields {
+				timestamps = timestamps || strings.Contains(f, timestampType)
+				fmt.Fprintf(&body, "  %s\n", f)
+			}
+			body.WriteString("}\n")
+		}
+	}
+
+	header := "syntax = \"proto3\";\n\npackage " + Package + ";\n"
+	if timestamps {
+		header += "\nimport \"google/protobuf/timestamp.proto\";\n"
+	}
+	return header + body.String()
+}
+
+// builder derives message definitions and encoders. A message is defined
+// the first time its type is used, so recursive types work.
+type builder struct {
+	// encoders holds the body encoder of each struct type defined so far.
+	// It is filled in once the message is built, so recursive references
+	// look it up when encoding.
+	encoders map[reflect.Type]*func([]byte, reflect.Value) []byte
+	messages []message
+}
+
+// scalar returns the encoding of t as a single, non-repeated value
+func (b *builder) scalar(t reflect.Type) scalar {
+	switch {
+	case t == timeType:
+		return scalar{name: timestampType, wire: wireBytes, message: true, append: func(buf []byte, v reflect.Value) []byte {
+			ts := v.Interface().(time.Time)
+			var body []byte
+			if s := ts.Unix(); s != 0 {
+				body = binary.AppendUvarint(appendTag(body, 1, wireVarint), uint64(s))
+			}
+			if n := ts.Nanosecond(); n != 0 {
+				body = binary.AppendUvarint(appendTag(body, 2, wireVarint), uint64(n))
+			}
+			return appendBytes(buf, body)
+		}}
+	case t.Kind() == reflect.String:
+		return scalar{name: "string", wire: wireBytes, append: func(buf []byte, v reflect.Value) []byte {
+			return appendBytes(buf, []byte(v.String()))
+		}}
+	case t.Kind() == reflect.Bool:
+		return scalar{name: "bool", wire: wireVarint, append: func(buf []byte, v reflect.Value) []byte {
+			if v.Bool() {
+				return append(buf, 1)
+			}
+			return append(buf, 0)
+		}}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		return scalar{name: "int64", wire: wireVarint, append: func(buf []byte, v reflect.Value) []byte {
+			return binary.AppendUvarint(buf, uint64(v.Int()))
+		}}
+	case t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint64:
+		return scalar{name: "uint64", wire: wireVarint, append: func(buf []byte, v reflect.Value) []byte {
+			return binary.AppendUvarint(buf, v.Uint())
+		}}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return scalar{name: "double", wire: wireFixed64, append: func(buf []byte, v reflect.Value) []byte {
+			return binary.LittleEndian.AppendUint64(buf, math.Float64bits(v.Float()))
+		}}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return scalar{name: "bytes", wire: wireBytes, append: func(buf []byte, v reflect.Value) []byte {
+			return appendBytes(buf, v.Bytes())
+		}}
+	case t.Kind() == reflect.Struct:
+		body := b.message(t)
+		return scalar{name: t.Name(), wire: wireBytes, message: true, append: func(buf []byte, v reflect.Value) []byte {
+			return appendBytes(buf, body(nil, v))
+		}}
+	default:
+		return scalar{name: "string", wire: wireBytes, append: func(buf []byte, v reflect.Value) []byte {
+			data, err := json.Marshal(v.Interface())
+			if err != nil {
+				data = nil
+			}
+			return appendBytes(buf, data)
+		}}
+	}
+}
+
+// field returns the definition and encoder of a struct field of type t
+func (b *builder) field(t reflect.Type, name string, num int) (string, func([]byte, reflect.Value) []byte) {
+	switch {
+	case t.Kind() == reflect.Pointer && !collection(t.Elem()) && t.Elem().Kind() != reflect.Pointer:
+		s := b.scalar(t.Elem())
+		def := fmt.Sprintf("optional %s %s = %d;", s.name, name, num)
+		if s.message {
+			def = fmt.Sprintf("%s %s = %d;", s.name, name, num)
+		}
+		return def, func(buf []byte, v reflect.Value) []byte {
+			if v.IsNil() {
+				return buf
+			}
+			return s.append(appendTag(buf, num, s.wire), v.Elem())
+		}
+
+	case t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8:
+		s := b.element(t.Elem())
+		def := fmt.Sprintf("repeated %s %s = %d;", s.name, name, num)
+		if s.wire != wireBytes {
+			// Numeric repeated fields are packed
+			return def, func(buf []byte, v reflect.Value) []byte {
+				if v.Len() == 0 {
+					return buf
+				}
+				var packed []byte
+				for i := 0; i < v.Len(); i++ {
+					packed = s.append(packed, v.Index(i))
+				}
+				return appendBytes(appendTag(buf, num, wireBytes), packed)
+			}
+		}
+		return def, func(buf []byte, v reflect.Value) []byte {
+			for i := 0; i < v.Len(); i++ {
+				buf = s.append(appendTag(buf, num, wireBytes), v.Index(i))
+			}
+			return buf
+		}
+
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+		s := b.element(t.Elem())
+		return fmt.Sprintf("map<string, %s> %s = %d;", s.name, name, num), func(buf []byte, v reflect.Value) []byte {
+			iter := v.MapRange()
+			for iter.Next() {
+				entry := appendBytes(appendTag(nil, 1, wireBytes), []byte(iter.Key().String()))
+				entry = s.append(appendTag(entry, 2, s.wire), iter.Value())
+				buf = appendBytes(appendTag(buf, num, wireBytes), entry)
+			}
+			return buf
+		}
+
+	default:
+		s := b.scalar(t)
+		return fmt.Sprintf("%s %s = %d;", s.name, name, num), func(buf []byte, v reflect.Value) []byte {
+			// Proto3 leaves out scalars holding their default value
+			if (!s.message || t == timeType) && v.IsZero() {
+				return buf
+			}
+			return s.append(appendTag(buf, num, s.wire), v)
+		}
+	}
+}
+
+// element returns the encoding of the elements of a repeated or map field.
+// Pointers are followed, a nil one encoding as the zero value; nested
+// lists and maps, which protobuf cannot repeat, as their JSON encoding.
+func (b *builder) element(t reflect.Type) scalar {
+	if t.Kind() == reflect.Pointer {
+		s := b.scalar(t.Elem())
+		inner := s.append
+		s.append = func(buf []byte, v reflect.Value) []byte {
+			if v.IsNil() {
+				v = reflect.Zero(t.Elem())
+			} else {
+				v = v.Elem()
+			}
+			return inner(buf, v)
+		}
+		return s
+	}
+	if collection(t) {
+		return b.scalar(anyType)
+	}
+	return b.scalar(t)
+}
+
+// collection reports whether t is encoded as a repeated or map field
+func collection(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 || t.Kind() == reflect.Map
+}
+
+// message defines the message of the struct type t, unless it already is,
+// and returns the encoder of its body
+func (b *builder) message(t reflect.Type) func([]byte, reflect.Value) []byte {
+	if enc, ok := b.encoders[t]; ok {
+		return func(buf []byte, v reflect.Value) []byte {
+			return (*enc)(buf, v)
+		}
+	}
+	enc := new(func([]byte, reflect.Value) []byte)
+	b.encoders[t] = enc
+	index := len(b.messages)
+	b.messages = append(b.messages, message{name: t.Name()})
+
+	type field struct {
+		index int
+		enc   func([]byte, reflect.Value) []byte
+	}
+	var fields []field
+	var defs []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		def, fenc := b.field(f.Type, name, len(fields)+1)
+		defs = append(defs, def)
+		fields = append(fields, field{index: i, enc: fenc})
+	}
+	b.messages[index].fields = defs
+
+	*enc = func(buf []byte, v reflect.Value) []byte {
+		for _, f := range fields {
+			buf = f.enc(buf, v.Field(f.index))
+		}
+		return buf
+	}
+	return *enc
+}
+
+// appendTag appends the key of field num with the given wire type
+func appendTag(b []byte, num, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(num)<<3|uint64(wire))
+}
+
+// appendBytes appends data with its length
+func appendBytes(b, data []byte) []byte {
+	return append(binary.AppendUvarint(b, uint64(len(data))), data...)
+}
+
+// snakeCase converts a Go type name such as UserTaskList to user_task_list
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
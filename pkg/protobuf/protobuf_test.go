@@ -0,0 +1,193 @@
+package protobuf
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestCodec_Append(t *testing.T) {
+	type inner struct {
+		N int `json:"n"`
+	}
+	type record struct {
+		Name   string         `json:"name"`
+		Done   bool           `json:"done"`
+		Count  int            `json:"count"`
+		Ratio  *float64       `json:"ratio"`
+		At     time.Time      `json:"at"`
+		Parent *inner         `json:"parent,omitempty"`
+		Tags   []string       `json:"tags"`
+		Nums   []int          `json:"nums"`
+		Extra  map[string]int `json:"extra"`
+		Any    any            `json:"any"`
+		hidden string
+	}
+
+	codec, err := NewCodec(record{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zero := 0.0
+	tests := []struct {
+		name string
+		v    record
+		want []byte
+	}{
+		{
+			name: "Defaults Left Out",
+			v:    record{},
+			want: nil,
+		},
+		{
+			name: "Every Field",
+			v: record{
+				Name:   "ab",
+				Done:   true,
+				Count:  150,
+				Ratio:  &zero,
+				At:     time.Unix(1, 5),
+				Parent: &inner{N: 1},
+				Tags:   []string{"x", "y"},
+				Nums:   []int{1, -1},
+				Extra:  map[string]int{"k": 1},
+				Any:    true,
+			},
+			want: []byte{
+				0x0a, 2, 'a', 'b', // name
+				0x10, 1, // done
+				0x18, 0x96, 0x01, // count 150
+				0x21, 0, 0, 0, 0, 0, 0, 0, 0, // ratio present though zero
+				0x2a, 4, 0x08, 1, 0x10, 5, // at {seconds: 1, nanos: 5}
+				0x32, 2, 0x08, 1, // parent {n: 1}
+				0x3a, 1, 'x', 0x3a, 1, 'y', // tags
+				0x42, 11, 1, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01, // nums packed
+				0x4a, 5, 0x0a, 1, 'k', 0x10, 1, // extra entry
+				0x52, 4, 't', 'r', 'u', 'e', // any as JSON
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := codec.Append(nil, tc.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tc.want) {
+				t.Errorf("Append() = %x, want %x", got, tc.want)
+			}
+		})
+	}
+
+	if _, err := codec.Append(nil, inner{}); err == nil {
+		t.Error("Expected an error for a value of another type")
+	}
+	if _, err := NewCodec(1); err == nil {
+		t.Error("Expected an error for a non-struct value")
+	}
+}
+
+func TestCodec_Extra(t *testing.T) {
+	type record struct {
+		N int `json:"n"`
+	}
+	codec, err := NewCodec(record{}, "run_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codec.Message() != "recordRecord" {
+		t.Errorf("Message() = %s", codec.Message())
+	}
+
+	got, err := codec.Append(nil, record{N: 1}, "r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0x0a, 2, 0x08, 1, 0x12, 1, 'r'}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Append() = %x, want %x", got, want)
+	}
+	if _, err := codec.Append(nil, record{}, "r", "s"); err == nil {
+		t.Error("Expected an error for too many extra values")
+	}
+}
+
+func TestProto(t *testing.T) {
+	tasks, err := NewCodec(asana.Task{}, "run_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	projects, err := NewCodec(asana.Project{}, "run_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proto := Proto(tasks, projects)
+	for _, want := range []string{
+		"syntax = \"proto3\";",
+		"package asana;",
+		"import \"google/protobuf/timestamp.proto\";",
+		"message Task {\n  string gid = 1;",
+		"  google.protobuf.Timestamp completed_at = 6;",
+		"  repeated Project projects = 11;",
+		"  optional double number_value = 6;",
+		"message TaskRecord {\n  Task task = 1;\n  string run_id = 2;\n}",
+		"message ProjectRecord {\n  Project project = 1;",
+	} {
+		if !strings.Contains(proto, want) {
+			t.Errorf("Expected the proto file to contain %q:\n%s", want, proto)
+		}
+	}
+	if n := strings.Count(proto, "message Project {"); n != 1 {
+		t.Errorf("Expected Project to be defined once, got %d", n)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	type record struct {
+		Name string `json:"name"`
+	}
+	codec, err := NewCodec(record{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, codec)
+	for _, name := range []string{"a", "", "bc"} {
+		if err := w.Write(record{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := bufio.NewReader(&buf)
+	var sizes []int
+	for {
+		msg, err := ReadDelimited(r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes = append(sizes, len(msg))
+	}
+	if len(sizes) != 3 || sizes[0] != 3 || sizes[1] != 0 || sizes[2] != 4 {
+		t.Errorf("Unexpected message sizes %v", sizes)
+	}
+
+	if _, err := ReadDelimited(bufio.NewReader(bytes.NewReader([]byte{5, 1}))); err == nil || errors.Is(err, io.EOF) {
+		t.Errorf("Expected a truncated message error, got %v", err)
+	}
+}
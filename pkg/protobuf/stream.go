@@ -0,0 +1,67 @@
+package protobuf
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Writer writes a length-delimited stream of messages: each is preceded by
+// its size as a varint, the framing of Java's writeDelimitedTo and Go's
+// protodelim. Messages are buffered; Flush writes them out.
+type Writer struct {
+	w     *bufio.Writer
+	codec *Codec
+	buf   []byte
+}
+
+// NewWriter returns a writer of codec's messages to w
+func NewWriter(w io.Writer, codec *Codec) *Writer {
+	return &Writer{w: bufio.NewWriter(w), codec: codec}
+}
+
+// Write appends v, which must be of the codec's type, and the values of
+// the codec's extra fields as one message
+func (pw *Writer) Write(v any, extra ...string) error {
+	msg, err := pw.codec.Append(pw.buf[:0], v, extra...)
+	if err != nil {
+		return err
+	}
+	pw.buf = msg
+
+	var size [binary.MaxVarintLen64]byte
+	if _, err := pw.w.Write(size[:binary.PutUvarint(size[:], uint64(len(msg)))]); err != nil {
+		return fmt.Errorf("failed to write protobuf message: %w", err)
+	}
+	if _, err := pw.w.Write(msg); err != nil {
+		return fmt.Errorf("failed to write protobuf message: %w", err)
+	}
+	return nil
+}
+
+// Flush writes the buffered messages
+func (pw *Writer) Flush() error {
+	if err := pw.w.Flush(); err != nil {
+		return fmt.Errorf("failed to flush protobuf messages: %w", err)
+	}
+	return nil
+}
+
+// ReadDelimited reads the next message of a length-delimited stream. It
+// returns io.EOF at the end of the stream.
+func ReadDelimited(r *bufio.Reader) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("failed to read protobuf message: %w", err)
+	}
+	return msg, nil
+}
@@ -29,6 +29,12 @@ type Limiter struct {
 	currentWrites      int
 	maxConcurrentRead  int
 	maxConcurrentWrite int
+	waits              waitStats
+
+	// pausedUntil holds back every Acquire until it passes; resumed is
+	// closed by Resume to wake the waiters early
+	pausedUntil time.Time
+	resumed     chan struct{}
 }
 
 // Config holds configuration for the rate limiter
@@ -36,15 +42,30 @@ type Config struct {
 	RequestsPerMinute  int
 	MaxConcurrentRead  int
 	MaxConcurrentWrite int
+	// Burst is how many requests may be sent back to back while the bucket
+	// is full. Zero means DefaultBurst; it never exceeds RequestsPerMinute
+	// but is at least 1.
+	Burst int
 }
 
+// DefaultBurst keeps the initial spike small enough not to trip Asana's
+// short-window limits
+const DefaultBurst = 10
+
 // NewLimiter creates a new rate limiter with the specified configuration
 func NewLimiter(cfg Config) *Limiter {
 	// Convert requests per minute to requests per second for token bucket
 	requestsPerSecond := float64(cfg.RequestsPerMinute) / 60.0
 
+	burst := cfg.Burst
+	if burst <= 0 {
+		burst = DefaultBurst
+	}
+	// A zero burst would fail every Wait
+	burst = max(min(burst, cfg.RequestsPerMinute), 1)
+
 	return &Limiter{
-		rateLimiter:        rate.NewLimiter(rate.Limit(requestsPerSecond), cfg.RequestsPerMinute),
+		rateLimiter:        rate.NewLimiter(rate.Limit(requestsPerSecond), burst),
 		maxConcurrentRead:  cfg.MaxConcurrentRead,
 		maxConcurrentWrite: cfg.MaxConcurrentWrite,
 	}
@@ -53,6 +74,20 @@ func NewLimiter(cfg Config) *Limiter {
 // Acquire blocks until a request can be made according to rate limits
 // Returns an error if context is cancelled
 func (l *Limiter) Acquire(ctx context.Context, reqType RequestType) error {
+	start := time.Now()
+	l.mu.Lock()
+	l.waits.waiting++
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		l.waits.waiting--
+		l.mu.Unlock()
+	}()
+
+	if err := l.waitPause(ctx); err != nil {
+		return err
+	}
+
 	// First, wait for token bucket
 	if err := l.rateLimiter.Wait(ctx); err != nil {
 		return err
@@ -76,6 +111,9 @@ func (l *Limiter) Acquire(ctx context.Context, reqType RequestType) error {
 			}
 		}
 
+		if canProceed {
+			l.waits.record(time.Since(start))
+		}
 		l.mu.Unlock()
 
 		if canProceed {
@@ -92,6 +130,81 @@ func (l *Limiter) Acquire(ctx context.Context, reqType RequestType) error {
 	}
 }
 
+// TryAcquire takes a token and a concurrent request slot if both are free
+// right now, without waiting, for requests that are only worth sending
+// when they cost no one else a slot. It reports whether it did; a granted
+// slot must be released like one from Acquire.
+func (l *Limiter) TryAcquire(reqType RequestType) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if time.Now().Before(l.pausedUntil) || l.waits.waiting > 0 {
+		return false
+	}
+	switch reqType {
+	case RequestTypeRead:
+		if l.currentReads >= l.maxConcurrentRead || !l.rateLimiter.Allow() {
+			return false
+		}
+		l.currentReads++
+	case RequestTypeWrite:
+		if l.currentWrites >= l.maxConcurrentWrite || !l.rateLimiter.Allow() {
+			return false
+		}
+		l.currentWrites++
+	}
+	l.waits.record(0)
+	return true
+}
+
+// Pause stops Acquire from granting any request for d, e.g. while Asana's
+// Retry-After penalty runs. Pausing never shortens a pause in effect.
+func (l *Limiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+	if l.resumed == nil {
+		l.resumed = make(chan struct{})
+	}
+}
+
+// Resume ends a pause early
+func (l *Limiter) Resume() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pausedUntil = time.Time{}
+	if l.resumed != nil {
+		close(l.resumed)
+		l.resumed = nil
+	}
+}
+
+// waitPause blocks while the limiter is paused
+func (l *Limiter) waitPause(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		remaining := time.Until(l.pausedUntil)
+		resumed := l.resumed
+		l.mu.Unlock()
+
+		if remaining <= 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-resumed:
+		case <-time.After(remaining):
+			// Pause may have been extended meanwhile; check again
+		}
+	}
+}
+
 // Release releases a concurrent request slot
 func (l *Limiter) Release(reqType RequestType) {
 	l.mu.Lock()
@@ -108,10 +221,3 @@ func (l *Limiter) Release(reqType RequestType) {
 		}
 	}
 }
-
-// Stats returns current rate limiter statistics
-func (l *Limiter) Stats() (currentReads, currentWrites int) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-	return l.currentReads, l.currentWrites
-}
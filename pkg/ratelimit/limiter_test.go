@@ -49,7 +49,7 @@ func TestLimiter_ConcurrentReadLimit(t *testing.T) {
 	}
 
 	// Verify we have 2 concurrent reads
-	reads, _ := limiter.Stats()
+	reads := limiter.Stats().CurrentReads
 	if reads != 2 {
 		t.Errorf("Expected 2 concurrent reads, got %d", reads)
 	}
@@ -94,7 +94,7 @@ func TestLimiter_ConcurrentWriteLimit(t *testing.T) {
 	}
 
 	// Verify we have 1 concurrent write
-	_, writes := limiter.Stats()
+	writes := limiter.Stats().CurrentWrites
 	if writes != 1 {
 		t.Errorf("Expected 1 concurrent write, got %d", writes)
 	}
@@ -152,7 +152,8 @@ func TestLimiter_ThreadSafety(t *testing.T) {
 	wg.Wait()
 
 	// Verify all slots are released
-	reads, writes := limiter.Stats()
+	stats := limiter.Stats()
+	reads, writes := stats.CurrentReads, stats.CurrentWrites
 	if reads != 0 || writes != 0 {
 		t.Errorf("Expected 0 concurrent requests, got reads=%d, writes=%d", reads, writes)
 	}
@@ -190,3 +191,117 @@ func TestLimiter_RateLimit(t *testing.T) {
 		t.Errorf("Rate limiting not working correctly, took %v (expected >= 2s)", elapsed)
 	}
 }
+
+func TestNewLimiter_Burst(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    Config
+		expect int
+	}{
+		{name: "Default", cfg: Config{RequestsPerMinute: 150}, expect: DefaultBurst},
+		{name: "Configured", cfg: Config{RequestsPerMinute: 150, Burst: 25}, expect: 25},
+		{name: "Capped At Rate", cfg: Config{RequestsPerMinute: 5, Burst: 25}, expect: 5},
+		{name: "Default Capped At Rate", cfg: Config{RequestsPerMinute: 3}, expect: 3},
+		{name: "At Least One", cfg: Config{RequestsPerMinute: 0}, expect: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewLimiter(tt.cfg)
+			if got := limiter.rateLimiter.Burst(); got != tt.expect {
+				t.Errorf("Burst() = %d, want %d", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestLimiter_Pause(t *testing.T) {
+	tests := []struct {
+		name      string
+		pause     time.Duration
+		shorter   time.Duration
+		resume    bool
+		minWait   time.Duration
+		maxWait   time.Duration
+		cancelled bool
+	}{
+		{name: "Waits Out Pause", pause: 200 * time.Millisecond, minWait: 150 * time.Millisecond, maxWait: time.Second},
+		{name: "Shorter Pause Does Not Shorten", pause: 200 * time.Millisecond, shorter: 10 * time.Millisecond, minWait: 150 * time.Millisecond, maxWait: time.Second},
+		{name: "Resume Ends Pause", pause: time.Minute, resume: true, maxWait: time.Second},
+		{name: "Context Cancelled", pause: time.Minute, maxWait: time.Second, cancelled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewLimiter(Config{RequestsPerMinute: 6000, MaxConcurrentRead: 5, MaxConcurrentWrite: 5})
+			limiter.Pause(tt.pause)
+			if tt.shorter > 0 {
+				limiter.Pause(tt.shorter)
+			}
+			if limiter.Stats().PausedFor <= 0 {
+				t.Error("expected Stats to report the pause")
+			}
+
+			timeout := 5 * time.Second
+			if tt.cancelled {
+				timeout = 100 * time.Millisecond
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			if tt.resume {
+				time.AfterFunc(50*time.Millisecond, limiter.Resume)
+			}
+
+			start := time.Now()
+			err := limiter.Acquire(ctx, RequestTypeRead)
+			elapsed := time.Since(start)
+
+			if tt.cancelled {
+				if err == nil {
+					t.Fatal("expected context error during pause")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Acquire() failed: %v", err)
+			}
+			if elapsed < tt.minWait || elapsed > tt.maxWait {
+				t.Errorf("Acquire() waited %v, want between %v and %v", elapsed, tt.minWait, tt.maxWait)
+			}
+		})
+	}
+}
+
+func TestLimiter_TryAcquire(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(l *Limiter)
+		want  bool
+	}{
+		{name: "Free Slot", setup: func(l *Limiter) {}, want: true},
+		{name: "Slots Taken", setup: func(l *Limiter) {
+			l.Acquire(context.Background(), RequestTypeRead)
+		}, want: false},
+		{name: "Bucket Empty", setup: func(l *Limiter) {
+			l.Acquire(context.Background(), RequestTypeWrite)
+			l.Acquire(context.Background(), RequestTypeWrite)
+		}, want: false},
+		{name: "Paused", setup: func(l *Limiter) { l.Pause(time.Minute) }, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// One read slot, and a burst of two tokens refilled every second
+			limiter := NewLimiter(Config{RequestsPerMinute: 60, MaxConcurrentRead: 1, MaxConcurrentWrite: 5, Burst: 2})
+			tt.setup(limiter)
+
+			if got := limiter.TryAcquire(RequestTypeRead); got != tt.want {
+				t.Errorf("TryAcquire() = %t, want %t", got, tt.want)
+			}
+			if tt.want && limiter.Stats().CurrentReads != 1 {
+				t.Error("expected the slot to be held until released")
+			}
+		})
+	}
+}
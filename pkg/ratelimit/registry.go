@@ -0,0 +1,42 @@
+package ratelimit
+
+import "sync"
+
+// Registry holds one Limiter per key, such as a workspace or token, so each
+// quota is tracked where Asana applies it and one busy key cannot starve
+// the others. It is safe for concurrent use.
+type Registry struct {
+	mu       sync.Mutex
+	limiters map[string]*Limiter
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{limiters: make(map[string]*Limiter)}
+}
+
+// Get returns the limiter for key, creating it from cfg on first use. Later
+// calls share that limiter and ignore cfg.
+func (r *Registry) Get(key string, cfg Config) *Limiter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l, ok := r.limiters[key]
+	if !ok {
+		l = NewLimiter(cfg)
+		r.limiters[key] = l
+	}
+	return l
+}
+
+// Stats returns a snapshot of every limiter by key
+func (r *Registry) Stats() map[string]Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make(map[string]Stats, len(r.limiters))
+	for key, l := range r.limiters {
+		stats[key] = l.Stats()
+	}
+	return stats
+}
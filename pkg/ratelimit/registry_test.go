@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	cfg := Config{RequestsPerMinute: 60, MaxConcurrentRead: 1, MaxConcurrentWrite: 1}
+
+	a := r.Get("ws-a", cfg)
+	if r.Get("ws-a", Config{RequestsPerMinute: 1}) != a {
+		t.Error("expected the same limiter for the same key")
+	}
+	b := r.Get("ws-b", cfg)
+	if a == b {
+		t.Error("expected separate limiters per key")
+	}
+
+	// Exhausting one key's slots leaves the other untouched
+	if err := a.Acquire(t.Context(), RequestTypeRead); err != nil {
+		t.Fatal(err)
+	}
+	stats := r.Stats()
+	if len(stats) != 2 || stats["ws-a"].CurrentReads != 1 || stats["ws-b"].CurrentReads != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestRegistry_ConcurrentGet(t *testing.T) {
+	r := NewRegistry()
+	cfg := Config{RequestsPerMinute: 60}
+
+	var wg sync.WaitGroup
+	limiters := make([]*Limiter, 10)
+	for i := range limiters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limiters[i] = r.Get("ws", cfg)
+		}()
+	}
+	wg.Wait()
+
+	for _, l := range limiters {
+		if l != limiters[0] {
+			t.Fatal("expected every caller to share one limiter")
+		}
+	}
+}
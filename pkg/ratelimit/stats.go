@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"slices"
+	"time"
+)
+
+// waitSamples is how many recent Acquire waits feed the percentile
+const waitSamples = 1024
+
+// Stats is a snapshot of the limiter. Comparing TotalWait with the run
+// duration tells whether a run was limited by the API or by the limiter.
+type Stats struct {
+	// CurrentReads and CurrentWrites are the requests holding a slot
+	CurrentReads  int `json:"current_reads"`
+	CurrentWrites int `json:"current_writes"`
+	// Waiting is the number of callers blocked in Acquire
+	Waiting int `json:"waiting"`
+	// TokensAvailable is the token bucket's current level
+	TokensAvailable float64 `json:"tokens_available"`
+	// Acquired counts successful Acquire calls
+	Acquired int64 `json:"acquired"`
+	// TotalWait is the time spent in successful Acquire calls
+	TotalWait time.Duration `json:"total_wait_ns"`
+	// P95Wait is the 95th percentile of the most recent waits
+	P95Wait time.Duration `json:"p95_wait_ns"`
+	// PausedFor is the time left of a pause, if any
+	PausedFor time.Duration `json:"paused_for_ns"`
+}
+
+// waitStats accumulates Acquire waits; guarded by Limiter.mu
+type waitStats struct {
+	waiting  int
+	acquired int64
+	total    time.Duration
+	// recent is a ring of the last waitSamples waits
+	recent []time.Duration
+	next   int
+}
+
+// record adds a successful wait
+func (w *waitStats) record(d time.Duration) {
+	w.acquired++
+	w.total += d
+	if len(w.recent) < waitSamples {
+		w.recent = append(w.recent, d)
+		return
+	}
+	w.recent[w.next] = d
+	w.next = (w.next + 1) % waitSamples
+}
+
+// p95 returns the 95th percentile of the recent waits
+func (w *waitStats) p95() time.Duration {
+	if len(w.recent) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(w.recent)
+	slices.Sort(sorted)
+	return sorted[(len(sorted)*95+99)/100-1]
+}
+
+// Stats returns current rate limiter statistics
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return Stats{
+		CurrentReads:    l.currentReads,
+		CurrentWrites:   l.currentWrites,
+		Waiting:         l.waits.waiting,
+		TokensAvailable: l.rateLimiter.Tokens(),
+		Acquired:        l.waits.acquired,
+		TotalWait:       l.waits.total,
+		P95Wait:         l.waits.p95(),
+		PausedFor:       max(time.Until(l.pausedUntil), 0),
+	}
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestWaitStats_P95(t *testing.T) {
+	tests := []struct {
+		name    string
+		waits   int
+		expect  time.Duration
+		samples int
+	}{
+		{name: "Empty", waits: 0, expect: 0, samples: 0},
+		{name: "Single", waits: 1, expect: time.Millisecond, samples: 1},
+		{name: "Hundred", waits: 100, expect: 95 * time.Millisecond, samples: 100},
+		{name: "Ring Wraps", waits: waitSamples + 100, expect: time.Duration(waitSamples+100-51) * time.Millisecond, samples: waitSamples},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w waitStats
+			for i := 1; i <= tt.waits; i++ {
+				w.record(time.Duration(i) * time.Millisecond)
+			}
+			if got := w.p95(); got != tt.expect {
+				t.Errorf("p95() = %v, want %v", got, tt.expect)
+			}
+			if len(w.recent) != tt.samples || w.acquired != int64(tt.waits) {
+				t.Errorf("kept %d samples of %d waits, want %d", len(w.recent), w.acquired, tt.samples)
+			}
+		})
+	}
+}
+
+func TestLimiter_Stats(t *testing.T) {
+	limiter := &Limiter{
+		rateLimiter:        rate.NewLimiter(rate.Limit(20), 1),
+		maxConcurrentRead:  1,
+		maxConcurrentWrite: 1,
+	}
+	ctx := context.Background()
+
+	if err := limiter.Acquire(ctx, RequestTypeRead); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second read waits for a token and then for the slot
+	done := make(chan error)
+	go func() { done <- limiter.Acquire(ctx, RequestTypeRead) }()
+
+	deadline := time.Now().Add(time.Second)
+	for limiter.Stats().Waiting != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected one waiter")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	limiter.Release(RequestTypeRead)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	stats := limiter.Stats()
+	if stats.Waiting != 0 || stats.Acquired != 2 || stats.CurrentReads != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if stats.TotalWait < 100*time.Millisecond || stats.P95Wait < 100*time.Millisecond {
+		t.Errorf("expected the blocked wait to be recorded, got %+v", stats)
+	}
+	if stats.TokensAvailable > 1 {
+		t.Errorf("expected at most the burst of tokens, got %v", stats.TokensAvailable)
+	}
+}
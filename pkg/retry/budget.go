@@ -0,0 +1,29 @@
+package retry
+
+import (
+	"errors"
+
+	"golang.org/x/time/rate"
+)
+
+// ErrBudgetExhausted is returned by Do when a retry is denied by Config.Budget
+var ErrBudgetExhausted = errors.New("retry budget exhausted")
+
+// Budget caps retries across every request sharing it. During a brownout
+// each request would otherwise retry independently and multiply the load;
+// once the budget is spent, failures are returned instead of retried until
+// it refills.
+type Budget struct {
+	limiter *rate.Limiter
+}
+
+// NewBudget allows perMinute retries a minute, with bursts of up to perMinute
+func NewBudget(perMinute int) *Budget {
+	perSecond := float64(perMinute) / 60.0
+	return &Budget{limiter: rate.NewLimiter(rate.Limit(perSecond), perMinute)}
+}
+
+// Allow reports whether a retry may proceed, spending one token if so
+func (b *Budget) Allow() bool {
+	return b.limiter.Allow()
+}
@@ -0,0 +1,94 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBudget_Allow(t *testing.T) {
+	b := NewBudget(3)
+	for i := 0; i < 3; i++ {
+		if !b.Allow() {
+			t.Fatalf("Allow() = false on retry %d, want burst of 3", i+1)
+		}
+	}
+	if b.Allow() {
+		t.Error("Allow() = true, want budget exhausted")
+	}
+}
+
+func TestDo_Budget(t *testing.T) {
+	tests := []struct {
+		name       string
+		resp       *http.Response
+		err        error
+		expectResp bool
+	}{
+		{name: "Status", resp: &http.Response{StatusCode: http.StatusServiceUnavailable}, expectResp: true},
+		{name: "Network Error", err: errors.New("connection reset")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Two requests share a budget of two retries
+			cfg := Config{
+				MaxRetries:     5,
+				InitialBackoff: time.Millisecond,
+				MaxBackoff:     time.Millisecond,
+				Budget:         NewBudget(2),
+			}
+
+			callCount := 0
+			fn := func() (*http.Response, error) {
+				callCount++
+				return tt.resp, tt.err
+			}
+
+			for i := 0; i < 2; i++ {
+				resp, err := Do(context.Background(), cfg, fn)
+				if !errors.Is(err, ErrBudgetExhausted) {
+					t.Fatalf("Do() error = %v, want ErrBudgetExhausted", err)
+				}
+				if (resp != nil) != tt.expectResp {
+					t.Errorf("Do() resp = %v, want resp %v", resp, tt.expectResp)
+				}
+				if tt.err != nil && !errors.Is(err, tt.err) {
+					t.Errorf("Do() error = %v, want it to wrap %v", err, tt.err)
+				}
+			}
+
+			// First request: 1 call + 2 retries; second: 1 call, no retry
+			if callCount != 4 {
+				t.Errorf("Function called %d times, want 4", callCount)
+			}
+		})
+	}
+}
+
+func TestDo_BudgetNotSpentPastDeadline(t *testing.T) {
+	budget := NewBudget(1)
+	cfg := Config{
+		MaxRetries:     3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Second,
+		Budget:         budget,
+	}
+	fn := func() (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusServiceUnavailable}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var deadlineErr *DeadlineError
+	if _, err := Do(ctx, cfg, fn); !errors.As(err, &deadlineErr) {
+		t.Fatalf("Do() error = %v, want *DeadlineError", err)
+	}
+
+	// The retry that was never made left its token
+	if !budget.Allow() {
+		t.Error("Expected the budget to be untouched by a retry past the deadline")
+	}
+}
@@ -0,0 +1,16 @@
+package retry
+
+import "io"
+
+// maxDrain bounds how much of an unread body DrainBody reads. Past it,
+// dropping the connection is cheaper than reading on.
+const maxDrain = 64 << 10
+
+// DrainBody reads what is left of a response body, up to maxDrain bytes,
+// and closes it. Go only returns a keep-alive connection to the pool once
+// its body was read to EOF, so closing an unread body costs a new
+// connection, and a TLS handshake, on the next request.
+func DrainBody(body io.ReadCloser) error {
+	io.CopyN(io.Discard, body, maxDrain)
+	return body.Close()
+}
@@ -0,0 +1,52 @@
+package retry
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// trackingBody records how much was read and whether it was closed
+type trackingBody struct {
+	io.Reader
+	read   int
+	closed bool
+}
+
+func (b *trackingBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	b.read += n
+	return n, err
+}
+
+func (b *trackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestDrainBody(t *testing.T) {
+	tests := []struct {
+		name       string
+		size       int
+		expectRead int
+	}{
+		{name: "Empty", size: 0, expectRead: 0},
+		{name: "Small", size: 1024, expectRead: 1024},
+		{name: "Larger Than Limit", size: maxDrain * 2, expectRead: maxDrain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &trackingBody{Reader: strings.NewReader(strings.Repeat("x", tt.size))}
+			if err := DrainBody(body); err != nil {
+				t.Fatalf("DrainBody() error = %v", err)
+			}
+			if body.read != tt.expectRead {
+				t.Errorf("read %d bytes, want %d", body.read, tt.expectRead)
+			}
+			if !body.closed {
+				t.Error("body not closed")
+			}
+		})
+	}
+}
@@ -15,6 +15,24 @@ type Config struct {
 	MaxRetries     int
 	InitialBackoff time.Duration
 	MaxBackoff     time.Duration
+	// OnRetry, when set, is called before every retry's backoff. It runs on
+	// the retrying goroutine, so it must be quick and safe for concurrent use.
+	OnRetry func(Attempt)
+	// Budget, when set, is consulted before every retry. It is shared by
+	// all requests using it, so retries back off collectively.
+	Budget *Budget
+}
+
+// Attempt describes a failed attempt that is about to be retried
+type Attempt struct {
+	// Number is the 1-based number of the failed attempt
+	Number int
+	// Status is the response status, or 0 for a network error
+	Status int
+	// Delay is the backoff before the next attempt
+	Delay time.Duration
+	// Err is the network error, if any
+	Err error
 }
 
 // DefaultConfig returns sensible default retry configuration
@@ -95,8 +113,39 @@ func CalculateBackoff(attempt int, cfg Config, retryAfter time.Duration) time.Du
 	return time.Duration(backoff)
 }
 
+// DeadlineError is returned by Do when the next backoff would outlast the
+// context deadline. It wraps context.DeadlineExceeded.
+type DeadlineError struct {
+	// Attempts is the number of attempts made
+	Attempts int
+	// Backoff is the wait the next attempt would have needed
+	Backoff time.Duration
+	// Last is the failure of the final attempt
+	Last error
+}
+
+// Error implements the error interface
+func (e *DeadlineError) Error() string {
+	return fmt.Sprintf("deadline too close to retry after %d attempts (backoff %v): %v", e.Attempts, e.Backoff, e.Last)
+}
+
+// Unwrap returns context.DeadlineExceeded and the last failure
+func (e *DeadlineError) Unwrap() []error {
+	return []error{context.DeadlineExceeded, e.Last}
+}
+
+// lastFailure describes why an attempt is being retried
+func lastFailure(resp *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("status %d", resp.StatusCode)
+}
+
 // Do executes a function with retry logic
-// The function should return the HTTP response and any error
+// The function should return the HTTP response and any error.
+// When ctx has a deadline that the next backoff would pass, Do returns a
+// *DeadlineError immediately instead of sleeping until the deadline.
 func Do(ctx context.Context, cfg Config, fn func() (*http.Response, error)) (*http.Response, error) {
 	var resp *http.Response
 	var err error
@@ -123,9 +172,34 @@ func Do(ctx context.Context, cfg Config, fn func() (*http.Response, error)) (*ht
 		retryAfter := GetRetryAfter(resp)
 		backoff := CalculateBackoff(attempt, cfg, retryAfter)
 
-		// Close the response body if present
+		// Fail now rather than sleep past the deadline only to fail then.
+		// This comes before the budget so a retry that will never be made
+		// does not spend a token.
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff {
+			if resp != nil && resp.Body != nil {
+				DrainBody(resp.Body)
+			}
+			return nil, &DeadlineError{Attempts: attempt + 1, Backoff: backoff, Last: lastFailure(resp, err)}
+		}
+
+		if cfg.Budget != nil && !cfg.Budget.Allow() {
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrBudgetExhausted, err)
+			}
+			return resp, fmt.Errorf("%w, last status: %d", ErrBudgetExhausted, resp.StatusCode)
+		}
+
+		// Release the response, keeping its connection reusable
 		if resp != nil && resp.Body != nil {
-			resp.Body.Close()
+			DrainBody(resp.Body)
+		}
+
+		if cfg.OnRetry != nil {
+			a := Attempt{Number: attempt + 1, Delay: backoff, Err: err}
+			if resp != nil {
+				a.Status = resp.StatusCode
+			}
+			cfg.OnRetry(a)
 		}
 
 		// Wait before retrying
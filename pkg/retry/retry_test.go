@@ -305,3 +305,124 @@ func TestDo_ContextCancellation(t *testing.T) {
 		t.Errorf("Do() error = %v, want context.Canceled", err)
 	}
 }
+
+func TestDo_DeadlineAwareBackoff(t *testing.T) {
+	tests := []struct {
+		name       string
+		timeout    time.Duration
+		resp       *http.Response
+		err        error
+		expectLast string
+	}{
+		{
+			name:       "Backoff Past Deadline",
+			timeout:    200 * time.Millisecond,
+			resp:       &http.Response{StatusCode: http.StatusServiceUnavailable},
+			expectLast: "status 503",
+		},
+		{
+			name:       "Retry-After Past Deadline",
+			timeout:    2 * time.Second,
+			resp:       &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"30"}}},
+			expectLast: "status 429",
+		},
+		{
+			name:       "Network Error",
+			timeout:    200 * time.Millisecond,
+			err:        errors.New("connection reset"),
+			expectLast: "connection reset",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{MaxRetries: 5, InitialBackoff: 10 * time.Second, MaxBackoff: time.Minute}
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			callCount := 0
+			start := time.Now()
+			_, err := Do(ctx, cfg, func() (*http.Response, error) {
+				callCount++
+				return tt.resp, tt.err
+			})
+
+			if elapsed := time.Since(start); elapsed >= tt.timeout {
+				t.Errorf("Do() took %v, want it to return before the %v deadline", elapsed, tt.timeout)
+			}
+			if callCount != 1 {
+				t.Errorf("Function called %d times, want 1", callCount)
+			}
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Do() error = %v, want context.DeadlineExceeded", err)
+			}
+			var deadlineErr *DeadlineError
+			if !errors.As(err, &deadlineErr) || deadlineErr.Attempts != 1 || deadlineErr.Last.Error() != tt.expectLast {
+				t.Errorf("Do() error = %#v, want DeadlineError after 1 attempt with %q", err, tt.expectLast)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("Do() error = %v, want it to wrap %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDo_DeadlineAllowsShortBackoff(t *testing.T) {
+	cfg := Config{MaxRetries: 3, InitialBackoff: 10 * time.Millisecond, MaxBackoff: 20 * time.Millisecond}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	callCount := 0
+	resp, err := Do(ctx, cfg, func() (*http.Response, error) {
+		callCount++
+		if callCount < 3 {
+			return &http.Response{StatusCode: http.StatusServiceUnavailable}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Do() = %v, %v, want success", resp, err)
+	}
+	if callCount != 3 {
+		t.Errorf("Function called %d times, want 3", callCount)
+	}
+}
+
+func TestDo_OnRetry(t *testing.T) {
+	netErr := errors.New("connection reset")
+	responses := []struct {
+		resp *http.Response
+		err  error
+	}{
+		{resp: &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"0"}}}},
+		{err: netErr},
+		{resp: &http.Response{StatusCode: http.StatusOK}},
+	}
+
+	var attempts []Attempt
+	cfg := Config{
+		MaxRetries:     3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		OnRetry:        func(a Attempt) { attempts = append(attempts, a) },
+	}
+
+	callCount := 0
+	if _, err := Do(context.Background(), cfg, func() (*http.Response, error) {
+		r := responses[callCount]
+		callCount++
+		return r.resp, r.err
+	}); err != nil {
+		t.Fatalf("Do() error = %v, want nil", err)
+	}
+
+	if len(attempts) != 2 {
+		t.Fatalf("OnRetry called %d times, want 2", len(attempts))
+	}
+	if a := attempts[0]; a.Number != 1 || a.Status != http.StatusTooManyRequests || a.Err != nil {
+		t.Errorf("first retry = %+v, want attempt 1 with status 429", a)
+	}
+	if a := attempts[1]; a.Number != 2 || a.Status != 0 || !errors.Is(a.Err, netErr) || a.Delay <= 0 {
+		t.Errorf("second retry = %+v, want attempt 2 with the network error", a)
+	}
+}
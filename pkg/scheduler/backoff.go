@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"expvar"
+	"log"
+	"sync"
+	"time"
+)
+
+// consecutiveFailures holds the current failure streak of every job by name
+var consecutiveFailures = expvar.NewMap("scheduler_consecutive_failures")
+
+// failureStreak tracks a job's consecutive failures and holds back its
+// scheduled runs with exponential backoff until one succeeds
+type failureStreak struct {
+	mu    sync.Mutex
+	count int
+	until time.Time
+}
+
+// record notes the outcome of a run. After the nth consecutive failure,
+// scheduled runs are held back for base * 2^(n-1), capped at maxBackoff.
+// A base of zero disables backoff but the streak is still counted.
+func (f *failureStreak) record(name string, err error, base, maxBackoff time.Duration, now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err == nil {
+		if f.count > 0 {
+			log.Printf("Job %s recovered after %d consecutive failures", name, f.count)
+		}
+		f.count = 0
+		f.until = time.Time{}
+		consecutiveFailures.Set(name, new(expvar.Int))
+		return
+	}
+
+	f.count++
+	consecutiveFailures.Add(name, 1)
+	if base <= 0 {
+		return
+	}
+
+	backoff := base
+	for i := 1; i < f.count && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	f.until = now.Add(min(backoff, maxBackoff))
+	log.Printf("Job %s failed %d times in a row; holding back scheduled runs until %s", name, f.count, f.until.Format(time.RFC3339))
+}
+
+// backingOff reports whether scheduled runs are held back at now
+func (f *failureStreak) backingOff(now time.Time) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return now.Before(f.until)
+}
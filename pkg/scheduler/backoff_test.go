@@ -0,0 +1,49 @@
+package scheduler
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestFailureStreak_Backoff(t *testing.T) {
+	failed := errors.New("unauthorized")
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		base          time.Duration
+		outcomes      []error
+		expectCount   int
+		expectHeldFor time.Duration
+	}{
+		{name: "First failure waits the base", base: time.Minute, outcomes: []error{failed}, expectCount: 1, expectHeldFor: time.Minute},
+		{name: "Doubles per failure", base: time.Minute, outcomes: []error{failed, failed, failed}, expectCount: 3, expectHeldFor: 4 * time.Minute},
+		{name: "Capped at the maximum", base: time.Minute, outcomes: []error{failed, failed, failed, failed, failed, failed, failed}, expectCount: 7, expectHeldFor: 10 * time.Minute},
+		{name: "Success resets the streak", base: time.Minute, outcomes: []error{failed, failed, nil}, expectCount: 0, expectHeldFor: 0},
+		{name: "Zero base only counts", base: 0, outcomes: []error{failed, failed}, expectCount: 2, expectHeldFor: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var f failureStreak
+			for _, err := range tc.outcomes {
+				f.record(tc.name, err, tc.base, 10*time.Minute, now)
+			}
+
+			if f.count != tc.expectCount {
+				t.Errorf("Expected streak %d, got %d", tc.expectCount, f.count)
+			}
+			if got := consecutiveFailures.Get(tc.name).String(); got != strconv.Itoa(tc.expectCount) {
+				t.Errorf("Expected metric %d, got %s", tc.expectCount, got)
+			}
+			if tc.expectHeldFor > 0 && (!f.backingOff(now.Add(tc.expectHeldFor-time.Second)) || f.backingOff(now.Add(tc.expectHeldFor))) {
+				t.Errorf("Expected runs to be held back for %s, until %s", tc.expectHeldFor, f.until)
+			}
+			if tc.expectHeldFor == 0 && f.backingOff(now) {
+				t.Error("Expected runs not to be held back")
+			}
+		})
+	}
+}
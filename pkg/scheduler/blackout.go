@@ -0,0 +1,180 @@
+package scheduler
+
+import (
+	"expvar"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// blackoutSkips counts scheduled runs skipped inside a blackout window
+// across all schedulers
+var blackoutSkips = expvar.NewInt("scheduler_blackout_skips")
+
+// Window is a recurring period, such as a warehouse maintenance slot,
+// during which scheduled runs are skipped. A window whose End is not after
+// its Start runs past midnight into the next day.
+type Window struct {
+	// Days the window starts on; empty means every day
+	Days []time.Weekday
+	// Start and End are offsets from midnight
+	Start, End time.Duration
+	// Location the times are in; nil means UTC
+	Location *time.Location
+
+	spec string
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseWindows parses a comma-separated list of windows, each written as
+// "[DAYS ]HH:MM-HH:MM[ ZONE]": for example "Sun 01:00-03:00 UTC" or
+// "Mon-Fri 22:00-02:00 Europe/Berlin". DAYS is a weekday or a range of
+// weekdays; ZONE is an IANA time zone and defaults to UTC.
+func ParseWindows(specs string) ([]Window, error) {
+	var windows []Window
+	for _, spec := range strings.Split(specs, ",") {
+		if spec = strings.TrimSpace(spec); spec == "" {
+			continue
+		}
+		w, err := ParseWindow(spec)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// ParseWindow parses a single window; see ParseWindows for the syntax
+func ParseWindow(spec string) (Window, error) {
+	w := Window{Location: time.UTC, spec: spec}
+
+	fields := strings.Fields(spec)
+	i := 0
+	if len(fields) > 0 && !strings.Contains(fields[0], ":") {
+		days, err := parseDays(fields[0])
+		if err != nil {
+			return Window{}, fmt.Errorf("invalid blackout window %q: %w", spec, err)
+		}
+		w.Days = days
+		i++
+	}
+	if i >= len(fields) {
+		return Window{}, fmt.Errorf("invalid blackout window %q: missing HH:MM-HH:MM", spec)
+	}
+
+	from, to, ok := strings.Cut(fields[i], "-")
+	if !ok {
+		return Window{}, fmt.Errorf("invalid blackout window %q: expected HH:MM-HH:MM", spec)
+	}
+	var err error
+	if w.Start, err = parseClock(from); err != nil {
+		return Window{}, fmt.Errorf("invalid blackout window %q: %w", spec, err)
+	}
+	if w.End, err = parseClock(to); err != nil {
+		return Window{}, fmt.Errorf("invalid blackout window %q: %w", spec, err)
+	}
+	if w.Start == w.End {
+		return Window{}, fmt.Errorf("invalid blackout window %q: start and end are equal", spec)
+	}
+	i++
+
+	if i < len(fields) {
+		if w.Location, err = time.LoadLocation(fields[i]); err != nil {
+			return Window{}, fmt.Errorf("invalid blackout window %q: %w", spec, err)
+		}
+		i++
+	}
+	if i < len(fields) {
+		return Window{}, fmt.Errorf("invalid blackout window %q: unexpected %q", spec, fields[i])
+	}
+	return w, nil
+}
+
+// parseDays parses a weekday ("Sun") or a range of weekdays ("Mon-Fri",
+// "Sat-Sun"), wrapping past Saturday
+func parseDays(s string) ([]time.Weekday, error) {
+	from, to, isRange := strings.Cut(strings.ToLower(s), "-")
+	first, ok := weekdays[from]
+	if !ok {
+		return nil, fmt.Errorf("unknown weekday %q", from)
+	}
+	if !isRange {
+		return []time.Weekday{first}, nil
+	}
+	last, ok := weekdays[to]
+	if !ok {
+		return nil, fmt.Errorf("unknown weekday %q", to)
+	}
+
+	days := []time.Weekday{first}
+	for d := first; d != last; {
+		d = (d + 1) % 7
+		days = append(days, d)
+	}
+	return days, nil
+}
+
+// parseClock parses HH:MM into an offset from midnight; 24:00 is allowed
+// as the end of the day
+func parseClock(s string) (time.Duration, error) {
+	var h, m int
+	if n, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil || n != 2 || len(s) != 5 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	if h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// Contains reports whether t falls inside the window
+func (w Window) Contains(t time.Time) bool {
+	loc := w.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+
+	if w.Start < w.End {
+		return offset >= w.Start && offset < w.End && w.onDay(t.Weekday())
+	}
+	// Past midnight: the part before midnight belongs to today's window,
+	// the part after to yesterday's
+	return (offset >= w.Start && w.onDay(t.Weekday())) ||
+		(offset < w.End && w.onDay((t.Weekday()+6)%7))
+}
+
+// onDay reports whether the window starts on day
+func (w Window) onDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the window as it was written
+func (w Window) String() string {
+	return w.spec
+}
+
+// inBlackout returns the first window containing t
+func inBlackout(windows []Window, t time.Time) (Window, bool) {
+	for _, w := range windows {
+		if w.Contains(t) {
+			return w, true
+		}
+	}
+	return Window{}, false
+}
@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        string
+		expectDays  int
+		expectStart time.Duration
+		expectEnd   time.Duration
+		expectError bool
+	}{
+		{name: "Every day", spec: "01:00-03:00", expectStart: time.Hour, expectEnd: 3 * time.Hour},
+		{name: "Single day with zone", spec: "Sun 01:00-03:00 UTC", expectDays: 1, expectStart: time.Hour, expectEnd: 3 * time.Hour},
+		{name: "Day range", spec: "mon-fri 22:00-02:00 Europe/Berlin", expectDays: 5, expectStart: 22 * time.Hour, expectEnd: 2 * time.Hour},
+		{name: "Range wrapping the week", spec: "Sat-Sun 00:00-24:00", expectDays: 2, expectEnd: 24 * time.Hour},
+		{name: "Unknown day", spec: "Funday 01:00-03:00", expectError: true},
+		{name: "Missing range", spec: "Sun", expectError: true},
+		{name: "Bad time", spec: "25:00-03:00", expectError: true},
+		{name: "Empty window", spec: "01:00-01:00", expectError: true},
+		{name: "Unknown zone", spec: "01:00-03:00 Mars/Olympus", expectError: true},
+		{name: "Trailing field", spec: "01:00-03:00 UTC extra", expectError: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := ParseWindow(tc.spec)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error for %q, got %+v", tc.spec, w)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseWindow(%q) failed: %v", tc.spec, err)
+			}
+			if len(w.Days) != tc.expectDays || w.Start != tc.expectStart || w.End != tc.expectEnd {
+				t.Errorf("Expected %d days %v-%v, got %v %v-%v", tc.expectDays, tc.expectStart, tc.expectEnd, w.Days, w.Start, w.End)
+			}
+		})
+	}
+}
+
+func TestWindow_Contains(t *testing.T) {
+	// 2026-01-04 is a Sunday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 1, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name   string
+		spec   string
+		t      time.Time
+		expect bool
+	}{
+		{name: "Inside", spec: "Sun 01:00-03:00", t: at(4, 2, 0), expect: true},
+		{name: "Start is inclusive", spec: "Sun 01:00-03:00", t: at(4, 1, 0), expect: true},
+		{name: "End is exclusive", spec: "Sun 01:00-03:00", t: at(4, 3, 0), expect: false},
+		{name: "Other day", spec: "Sun 01:00-03:00", t: at(5, 2, 0), expect: false},
+		{name: "Past midnight, before", spec: "Sun 23:00-02:00", t: at(4, 23, 30), expect: true},
+		{name: "Past midnight, after", spec: "Sun 23:00-02:00", t: at(5, 1, 30), expect: true},
+		{name: "Past midnight, wrong start day", spec: "Sun 23:00-02:00", t: at(4, 1, 30), expect: false},
+		{name: "Every day", spec: "01:00-03:00", t: at(7, 1, 15), expect: true},
+		{name: "Zone", spec: "Sun 01:00-03:00 Asia/Tokyo", t: time.Date(2026, 1, 3, 17, 0, 0, 0, time.UTC), expect: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := ParseWindow(tc.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := w.Contains(tc.t); got != tc.expect {
+				t.Errorf("%q.Contains(%v) = %t, want %t", tc.spec, tc.t, got, tc.expect)
+			}
+		})
+	}
+}
+
+func TestParseWindows(t *testing.T) {
+	windows, err := ParseWindows("Sun 01:00-03:00, Sat 22:00-23:00 ,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 2 || windows[1].String() != "Sat 22:00-23:00" {
+		t.Errorf("Expected 2 windows, got %v", windows)
+	}
+
+	if _, err := ParseWindows("Sun 01:00-03:00, bogus"); err == nil {
+		t.Error("Expected error for an invalid window")
+	}
+}
+
+func TestCronScheduler_BlackoutSkipsScheduledRuns(t *testing.T) {
+	always, _ := ParseWindow("00:00-24:00")
+	s := NewCronScheduler("0 0 0 1 1 *", Config{Blackouts: []Window{always}})
+
+	runs := 0
+	scheduled := s.register("blackout", func(context.Context) error {
+		runs++
+		return nil
+	})
+
+	before := blackoutSkips.Value()
+	scheduled()
+	if runs != 0 {
+		t.Errorf("Expected the scheduled run to be skipped, got %d runs", runs)
+	}
+	if blackoutSkips.Value() != before+1 {
+		t.Error("Expected the skip to be counted in scheduler_blackout_skips")
+	}
+}
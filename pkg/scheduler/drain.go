@@ -0,0 +1,41 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// WithDrain returns a context for a job run that outlives the cancellation
+// of parent by up to drain, so an in-flight run can flush its output when
+// shutdown is requested. The returned context keeps parent's values and is
+// cancelled drain after parent is done, or when cancel is called.
+func WithDrain(parent context.Context, drain time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(parent))
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-parent.Done():
+		}
+
+		if drain <= 0 {
+			cancel()
+			return
+		}
+
+		log.Printf("Shutdown requested; waiting up to %v for the running job to finish", drain)
+		timer := time.NewTimer(drain)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+			log.Printf("Running job did not finish within %v; cancelling it", drain)
+			cancel()
+		}
+	}()
+
+	return ctx, cancel
+}
@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type ctxKey struct{}
+
+func TestWithDrain(t *testing.T) {
+	tests := []struct {
+		name       string
+		drain      time.Duration
+		expectDone bool
+	}{
+		{name: "Job outlives parent within drain", drain: time.Second, expectDone: false},
+		{name: "Zero drain cancels immediately", drain: 0, expectDone: true},
+		{name: "Drain expires", drain: 20 * time.Millisecond, expectDone: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			parent, cancelParent := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "v"))
+			ctx, cancel := WithDrain(parent, tc.drain)
+			defer cancel()
+
+			if ctx.Value(ctxKey{}) != "v" {
+				t.Error("Expected parent values to be kept")
+			}
+
+			cancelParent()
+
+			select {
+			case <-ctx.Done():
+				if !tc.expectDone {
+					t.Error("Expected job context to survive parent cancellation")
+				}
+			case <-time.After(100 * time.Millisecond):
+				if tc.expectDone {
+					t.Error("Expected job context to be cancelled")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// IntervalScheduler implements Scheduler by running the job a fixed interval
+// after its previous run completes, so a slow run pushes the next one back
+// instead of piling up behind it.
+type IntervalScheduler struct {
+	*runner
+	interval time.Duration
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	// next is guarded by the runner's mu
+	next time.Time
+}
+
+// NewIntervalScheduler creates a scheduler that runs its job every interval
+func NewIntervalScheduler(interval time.Duration, cfg Config) *IntervalScheduler {
+	return &IntervalScheduler{
+		runner:   newRunner(cfg),
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start runs job one interval after Start, and then one interval after each
+// run completes. Once ctx is cancelled or Stop is called, Start waits for
+// in-flight runs (bounded by DrainTimeout) before returning.
+func (s *IntervalScheduler) Start(ctx context.Context, job Job) error {
+	if s.interval <= 0 {
+		return fmt.Errorf("invalid interval %v: must be positive", s.interval)
+	}
+
+	var guarded func()
+	if job != nil {
+		guarded = s.register("default", job)
+	}
+
+	s.accept(ctx)
+	log.Printf("Scheduler started with interval: %v", s.interval)
+
+	for s.wait(ctx, guarded != nil) {
+		log.Printf("Running scheduled job...")
+		guarded()
+	}
+
+	s.Stop()
+	s.drain()
+	return nil
+}
+
+// wait blocks until the next run is due and reports whether it should go
+// ahead, or false once the scheduler stops. Without a scheduled job it only
+// waits for the scheduler to stop.
+func (s *IntervalScheduler) wait(ctx context.Context, scheduled bool) bool {
+	var fire <-chan time.Time
+	if scheduled {
+		timer := time.NewTimer(s.interval)
+		defer timer.Stop()
+		fire = timer.C
+		s.setNext(time.Now().Add(s.interval))
+	}
+	defer s.setNext(time.Time{})
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-s.stop:
+		return false
+	case <-fire:
+		return true
+	}
+}
+
+// setNext records when the next run fires
+func (s *IntervalScheduler) setNext(next time.Time) {
+	s.mu.Lock()
+	s.next = next
+	s.mu.Unlock()
+}
+
+// NextRun returns when the next run fires. It is zero before Start and
+// while a run is in progress, as the next one is scheduled from its end.
+// Runs held back by the failure backoff are still reported.
+func (s *IntervalScheduler) NextRun() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.next
+}
+
+// Stop stops scheduling new runs
+func (s *IntervalScheduler) Stop() {
+	s.stopOnce.Do(func() {
+		log.Println("Stopping scheduler...")
+		close(s.stop)
+	})
+}
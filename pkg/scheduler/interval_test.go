@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIntervalScheduler_RunsAfterPreviousCompletes(t *testing.T) {
+	var _ Scheduler = (*IntervalScheduler)(nil)
+
+	const interval = 50 * time.Millisecond
+	s := NewIntervalScheduler(interval, Config{})
+
+	var mu sync.Mutex
+	var starts, ends []time.Time
+	job := func(context.Context) error {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+		time.Sleep(30 * time.Millisecond)
+		mu.Lock()
+		ends = append(ends, time.Now())
+		mu.Unlock()
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errChan := make(chan error, 1)
+	go func() { errChan <- s.Start(ctx, job) }()
+
+	time.Sleep(280 * time.Millisecond)
+	cancel()
+	if err := <-errChan; err != nil {
+		t.Fatalf("Start() returned error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(starts) < 2 {
+		t.Fatalf("Expected at least 2 runs, got %d", len(starts))
+	}
+	for i := 1; i < len(starts); i++ {
+		if gap := starts[i].Sub(ends[i-1]); gap < interval {
+			t.Errorf("Run %d started %v after the previous one ended, want at least %v", i, gap, interval)
+		}
+	}
+	if !s.NextRun().IsZero() {
+		t.Errorf("Expected no next run after shutdown, got %v", s.NextRun())
+	}
+}
+
+func TestIntervalScheduler_NextRunAndStop(t *testing.T) {
+	s := NewIntervalScheduler(time.Hour, Config{})
+	if !s.NextRun().IsZero() {
+		t.Fatal("Expected no next run before Start")
+	}
+
+	errChan := make(chan error, 1)
+	before := time.Now()
+	go func() {
+		errChan <- s.Start(context.Background(), func(context.Context) error { return nil })
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for s.NextRun().IsZero() {
+		if time.Now().After(deadline) {
+			t.Fatal("scheduler did not start")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if next := s.NextRun(); next.Before(before.Add(time.Hour)) || next.After(time.Now().Add(time.Hour)) {
+		t.Errorf("Expected the next run an hour after Start, got %v", next)
+	}
+
+	s.Stop()
+	select {
+	case err := <-errChan:
+		if err != nil {
+			t.Errorf("Start() returned error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Start() did not return after Stop()")
+	}
+}
+
+func TestIntervalScheduler_InvalidInterval(t *testing.T) {
+	s := NewIntervalScheduler(0, Config{})
+	if err := s.Start(context.Background(), func(context.Context) error { return nil }); err == nil {
+		t.Error("Expected error for a zero interval, got nil")
+	}
+}
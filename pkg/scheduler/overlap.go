@@ -0,0 +1,103 @@
+package scheduler
+
+import (
+	"expvar"
+	"log"
+	"sync/atomic"
+)
+
+// OverlapPolicy decides what happens when a job is triggered while its
+// previous run is still in progress.
+type OverlapPolicy string
+
+const (
+	// OverlapSkip drops the new run if the previous one is still going
+	OverlapSkip OverlapPolicy = "skip"
+	// OverlapQueue holds at most one pending run until the current one ends;
+	// further triggers while one is already queued are skipped
+	OverlapQueue OverlapPolicy = "queue"
+	// OverlapAllow lets runs execute concurrently
+	OverlapAllow OverlapPolicy = "allow"
+)
+
+// skippedRuns counts runs dropped by the overlap policy across all schedulers
+var skippedRuns = expvar.NewInt("scheduler_skipped_runs")
+
+// overlapGuard serializes the runs of a single job according to a policy
+type overlapGuard struct {
+	name    string
+	policy  OverlapPolicy
+	running chan struct{}
+	pending chan struct{}
+	skipped *atomic.Int64
+}
+
+// newOverlapGuard creates a guard for one job. skipped is shared with the
+// owning scheduler so it can report its own count.
+func newOverlapGuard(name string, policy OverlapPolicy, skipped *atomic.Int64) *overlapGuard {
+	return &overlapGuard{
+		name:    name,
+		policy:  policy,
+		running: make(chan struct{}, 1),
+		pending: make(chan struct{}, 1),
+		skipped: skipped,
+	}
+}
+
+// wrap returns job guarded by the overlap policy
+func (g *overlapGuard) wrap(job func()) func() {
+	return func() {
+		if !g.admit() {
+			g.skip()
+			return
+		}
+		g.start()
+		defer g.done()
+		job()
+	}
+}
+
+// admit decides without blocking whether a new run is accepted. An accepted
+// run must call start before running and done afterwards.
+func (g *overlapGuard) admit() bool {
+	switch g.policy {
+	case OverlapAllow:
+		return true
+	case OverlapQueue:
+		select {
+		case g.pending <- struct{}{}:
+			return true
+		default:
+			return false
+		}
+	default:
+		select {
+		case g.running <- struct{}{}:
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// start blocks a queued run until the current one ends
+func (g *overlapGuard) start() {
+	if g.policy == OverlapQueue {
+		g.running <- struct{}{}
+		<-g.pending
+	}
+}
+
+// done releases the run slot taken by admit or start
+func (g *overlapGuard) done() {
+	if g.policy != OverlapAllow {
+		<-g.running
+	}
+}
+
+// skip records a dropped run
+func (g *overlapGuard) skip() {
+	g.skipped.Add(1)
+	skippedRuns.Add(1)
+	log.Printf("Skipping scheduled job %s: previous run still in progress (policy=%s)", g.name, g.policy)
+}
@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOverlapGuard_Policies(t *testing.T) {
+	tests := []struct {
+		name            string
+		policy          OverlapPolicy
+		triggers        int
+		expectedRuns    int64
+		expectedSkipped int64
+	}{
+		{name: "Skip drops runs while one is active", policy: OverlapSkip, triggers: 3, expectedRuns: 1, expectedSkipped: 2},
+		{name: "Queue keeps one pending run", policy: OverlapQueue, triggers: 3, expectedRuns: 2, expectedSkipped: 1},
+		{name: "Allow runs everything concurrently", policy: OverlapAllow, triggers: 3, expectedRuns: 3, expectedSkipped: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var skipped, runs atomic.Int64
+			release := make(chan struct{})
+			started := make(chan struct{}, tc.triggers)
+
+			job := newOverlapGuard("test", tc.policy, &skipped).wrap(func() {
+				runs.Add(1)
+				started <- struct{}{}
+				<-release
+			})
+
+			var wg sync.WaitGroup
+
+			// First run occupies the guard until released
+			wg.Add(1)
+			go func() { defer wg.Done(); job() }()
+			<-started
+
+			for i := 1; i < tc.triggers; i++ {
+				wg.Add(1)
+				go func() { defer wg.Done(); job() }()
+				// Let each trigger reach the guard before the next one
+				time.Sleep(20 * time.Millisecond)
+			}
+
+			close(release)
+			wg.Wait()
+
+			if runs.Load() != tc.expectedRuns {
+				t.Errorf("expected %d runs, got %d", tc.expectedRuns, runs.Load())
+			}
+			if skipped.Load() != tc.expectedSkipped {
+				t.Errorf("expected %d skipped, got %d", tc.expectedSkipped, skipped.Load())
+			}
+		})
+	}
+}
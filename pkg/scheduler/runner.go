@@ -0,0 +1,253 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// timedOutRuns counts runs cancelled by JobTimeout across all schedulers
+var timedOutRuns = expvar.NewInt("scheduler_timed_out_runs")
+
+// Errors returned by Trigger
+var (
+	ErrUnknownJob = errors.New("unknown job")
+	ErrRunSkipped = errors.New("previous run still in progress")
+	ErrNotRunning = errors.New("scheduler is not running")
+)
+
+// runner runs the jobs of a scheduler: it applies blackouts, the overlap
+// policy, timeouts, draining and failure backoff, and tracks the runs in
+// flight. Schedulers embed it and decide when the jobs fire.
+type runner struct {
+	cfg     Config
+	skipped atomic.Int64
+
+	// mu guards ctx, closed, jobs, lastRun and active; running tracks
+	// in-flight runs so Start can wait for them during shutdown
+	mu sync.Mutex
+	// ctx is the context passed to Start, used for scheduled runs
+	ctx     context.Context
+	closed  bool
+	jobs    map[string]*entry
+	streaks map[string]*failureStreak
+	lastRun time.Time
+	active  int
+	running sync.WaitGroup
+}
+
+// entry is a registered job with the guard enforcing its overlap policy
+type entry struct {
+	job   Job
+	guard *overlapGuard
+}
+
+// newRunner applies the Config defaults and creates a runner
+func newRunner(cfg Config) *runner {
+	if cfg.OverlapPolicy == "" {
+		cfg.OverlapPolicy = OverlapSkip
+	}
+	if cfg.MaxFailureBackoff <= 0 {
+		cfg.MaxFailureBackoff = time.Hour
+	}
+
+	return &runner{
+		cfg:     cfg,
+		jobs:    make(map[string]*entry),
+		streaks: make(map[string]*failureStreak),
+	}
+}
+
+// register records a job for Trigger and returns its guarded scheduled run
+func (s *runner) register(name string, job Job) func() {
+	e := &entry{job: job, guard: newOverlapGuard(name, s.cfg.OverlapPolicy, &s.skipped)}
+
+	s.mu.Lock()
+	s.jobs[name] = e
+	s.mu.Unlock()
+
+	guarded := e.guard.wrap(func() {
+		s.mu.Lock()
+		ctx := s.ctx
+		s.mu.Unlock()
+		s.RunNow(ctx, name, job)
+	})
+	return func() {
+		now := time.Now()
+		if w, ok := inBlackout(s.cfg.Blackouts, now); ok {
+			blackoutSkips.Add(1)
+			log.Printf("Skipping scheduled job %s: inside blackout window %s", name, w)
+			return
+		}
+		if s.streak(name).backingOff(now) {
+			log.Printf("Skipping scheduled job %s: backing off after consecutive failures", name)
+			return
+		}
+		guarded()
+	}
+}
+
+// streak returns the failure streak of the named job
+func (s *runner) streak(name string) *failureStreak {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.streaks[name]
+	if !ok {
+		f = &failureStreak{}
+		s.streaks[name] = f
+	}
+	return f
+}
+
+// accept makes ctx the context of scheduled runs and starts accepting triggers
+func (s *runner) accept(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+}
+
+// drain stops accepting runs and waits for the ones in flight
+func (s *runner) drain() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.running.Wait()
+}
+
+// Trigger starts an out-of-schedule run of the named job in the background.
+// The job's overlap policy applies as if its schedule had fired, and
+// ErrRunSkipped reports a run the policy dropped. The run sees the values
+// of ctx but lives as long as the scheduler, so ctx may be short-lived,
+// such as an HTTP request's.
+func (s *runner) Trigger(ctx context.Context, name string) error {
+	s.mu.Lock()
+	e, ok := s.jobs[name]
+	s.mu.Unlock()
+
+	if !ok || e.job == nil {
+		if err := s.accepting(); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	_, err := s.trigger(ctx, name, e.guard, e.job)
+	return err
+}
+
+// TriggerJob is Trigger for a job not registered ahead of time, such as one
+// whose resources vary per run. Runs triggered under the same name share an
+// overlap guard, created on first use. The returned channel is closed once
+// the run ends or is dropped by shutdown.
+func (s *runner) TriggerJob(ctx context.Context, name string, job Job) (<-chan struct{}, error) {
+	s.mu.Lock()
+	e, ok := s.jobs[name]
+	if !ok {
+		e = &entry{guard: newOverlapGuard(name, s.cfg.OverlapPolicy, &s.skipped)}
+		s.jobs[name] = e
+	}
+	s.mu.Unlock()
+	return s.trigger(ctx, name, e.guard, job)
+}
+
+// accepting returns ErrNotRunning unless the scheduler has started and is
+// not shutting down
+func (s *runner) accepting() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx == nil || s.closed {
+		return ErrNotRunning
+	}
+	return nil
+}
+
+// trigger starts job in the background under guard
+func (s *runner) trigger(ctx context.Context, name string, guard *overlapGuard, job Job) (<-chan struct{}, error) {
+	s.mu.Lock()
+	schedCtx, closed := s.ctx, s.closed
+	s.mu.Unlock()
+
+	if schedCtx == nil || closed {
+		return nil, ErrNotRunning
+	}
+	if !guard.admit() {
+		guard.skip()
+		return nil, ErrRunSkipped
+	}
+
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(schedCtx, cancel)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer cancel()
+		defer stop()
+		guard.start()
+		defer guard.done()
+		s.RunNow(runCtx, name, job)
+	}()
+	return done, nil
+}
+
+// RunNow runs job synchronously under the configured JobTimeout.
+// It is used by scheduled entries and for out-of-schedule runs. The job's
+// context derives from ctx; once ctx is cancelled the job gets DrainTimeout
+// to finish. Runs requested after shutdown has begun are dropped.
+func (s *runner) RunNow(ctx context.Context, name string, job Job) {
+	s.mu.Lock()
+	if s.closed || ctx.Err() != nil {
+		s.mu.Unlock()
+		log.Printf("Not running job %s: scheduler is shutting down", name)
+		return
+	}
+	s.running.Add(1)
+	s.lastRun = time.Now()
+	s.active++
+	s.mu.Unlock()
+	defer s.running.Done()
+	defer func() {
+		s.mu.Lock()
+		s.active--
+		s.mu.Unlock()
+	}()
+
+	ctx, cancelDrain := WithDrain(ctx, s.cfg.DrainTimeout)
+	defer cancelDrain()
+	if s.cfg.JobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.JobTimeout)
+		defer cancel()
+	}
+
+	err := job(ctx)
+	s.streak(name).record(name, err, s.cfg.FailureBackoff, s.cfg.MaxFailureBackoff, time.Now())
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		timedOutRuns.Add(1)
+		log.Printf("Job %s exceeded its timeout of %v and was cancelled", name, s.cfg.JobTimeout)
+	}
+}
+
+// LastRun returns when the most recent run of any job started
+func (s *runner) LastRun() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastRun
+}
+
+// IsRunning reports whether a run of any job is in progress
+func (s *runner) IsRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active > 0
+}
+
+// SkippedRuns returns how many runs this scheduler dropped due to overlap
+func (s *runner) SkippedRuns() int64 {
+	return s.skipped.Load()
+}
@@ -3,52 +3,142 @@ package scheduler
 import (
 	"context"
 	"log"
+	"time"
 
 	"github.com/robfig/cron/v3"
 )
 
+// Job is a unit of scheduled work. Its context is cancelled once the job
+// exceeds Config.JobTimeout, or Config.DrainTimeout after shutdown begins.
+// A returned error counts towards the job's failure streak.
+type Job func(ctx context.Context) error
+
 // Scheduler defines the interface for job scheduling
 type Scheduler interface {
-	Start(ctx context.Context, job func()) error
+	Start(ctx context.Context, job Job) error
 	Stop()
+
+	// NextRun returns when the next scheduled run of any job fires, or the
+	// zero time if none is scheduled
+	NextRun() time.Time
+	// LastRun returns when the most recent run of any job started, or the
+	// zero time if none has run yet
+	LastRun() time.Time
+	// IsRunning reports whether a run is in progress
+	IsRunning() bool
+}
+
+// Config holds scheduler configuration
+type Config struct {
+	// OverlapPolicy applies to every job registered on the scheduler.
+	// Defaults to OverlapSkip.
+	OverlapPolicy OverlapPolicy
+
+	// JobTimeout bounds a single run of any job. Zero disables the timeout.
+	JobTimeout time.Duration
+
+	// DrainTimeout is how long a running job may keep going once shutdown
+	// begins before its context is cancelled. Zero cancels it immediately.
+	DrainTimeout time.Duration
+
+	// FailureBackoff holds back the scheduled runs of a failing job: for
+	// FailureBackoff after its first consecutive failure, doubling with each
+	// further one up to MaxFailureBackoff. A success resets it. Triggered
+	// runs are not held back. Zero disables the backoff; MaxFailureBackoff
+	// defaults to an hour.
+	FailureBackoff    time.Duration
+	MaxFailureBackoff time.Duration
+
+	// Blackouts are windows during which scheduled runs are skipped.
+	// Triggered runs still go ahead.
+	Blackouts []Window
+}
+
+// cronParser parses the 6-field (with seconds) expressions used by CronScheduler
+var cronParser = cron.NewParser(
+	cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// ValidateExpression reports whether expr is a cron expression CronScheduler accepts
+func ValidateExpression(expr string) error {
+	_, err := cronParser.Parse(expr)
+	return err
 }
 
 // CronScheduler implements Scheduler using cron expressions
 type CronScheduler struct {
+	*runner
 	cronExpr string
 	cron     *cron.Cron
 }
 
 // NewCronScheduler creates a new cron-based scheduler
-func NewCronScheduler(cronExpr string) *CronScheduler {
+func NewCronScheduler(cronExpr string, cfg Config) *CronScheduler {
 	return &CronScheduler{
+		runner:   newRunner(cfg),
 		cronExpr: cronExpr,
 		cron:     cron.New(cron.WithSeconds()),
 	}
 }
 
-// Start starts the scheduler and runs the job according to the cron expression
-func (s *CronScheduler) Start(ctx context.Context, job func()) error {
-	// Add the job to the cron scheduler
-	_, err := s.cron.AddFunc(s.cronExpr, func() {
-		log.Printf("Running scheduled job...")
-		job()
+// AddJob registers an additional named job with its own cron expression.
+// Jobs must be added before Start; they share the scheduler's lifecycle.
+func (s *CronScheduler) AddJob(name, cronExpr string, job Job) error {
+	guarded := s.register(name, job)
+	_, err := s.cron.AddFunc(cronExpr, func() {
+		log.Printf("Running scheduled job %s...", name)
+		guarded()
 	})
 	if err != nil {
 		return err
 	}
 
-	// Start the cron scheduler
+	log.Printf("Registered job %s with cron expression: %s", name, cronExpr)
+	return nil
+}
+
+// Start starts the scheduler and runs the job according to the cron expression.
+// A nil job is allowed when every workload was registered through AddJob.
+// Once ctx is cancelled, Start stops scheduling and waits for in-flight runs
+// (bounded by DrainTimeout) before returning.
+func (s *CronScheduler) Start(ctx context.Context, job Job) error {
+	// Add the job to the cron scheduler
+	if job != nil {
+		guarded := s.register("default", job)
+		_, err := s.cron.AddFunc(s.cronExpr, func() {
+			log.Printf("Running scheduled job...")
+			guarded()
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Accept triggers and start the cron scheduler
+	s.accept(ctx)
 	s.cron.Start()
 	log.Printf("Scheduler started with cron expression: %s", s.cronExpr)
 
 	// Wait for context cancellation
 	<-ctx.Done()
 	s.Stop()
+	s.drain()
 
 	return nil
 }
 
+// NextRun returns when the next scheduled run of any job fires. Runs held
+// back by the failure backoff are still reported. It is zero before Start.
+func (s *CronScheduler) NextRun() time.Time {
+	var next time.Time
+	for _, e := range s.cron.Entries() {
+		if !e.Next.IsZero() && (next.IsZero() || e.Next.Before(next)) {
+			next = e.Next
+		}
+	}
+	return next
+}
+
 // Stop stops the scheduler
 func (s *CronScheduler) Stop() {
 	if s.cron != nil {
@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -13,7 +14,7 @@ func TestCronScheduler_StartStop(t *testing.T) {
 	// To use seconds, you'd need cron.WithSeconds(), but we'll stick to
 	// standard and test the "Start/Done" lifecycle.
 	cronExpr := "* * * * * *"
-	s := NewCronScheduler(cronExpr)
+	s := NewCronScheduler(cronExpr, Config{})
 
 	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
 	defer cancel()
@@ -22,9 +23,10 @@ func TestCronScheduler_StartStop(t *testing.T) {
 	errChan := make(chan error, 1)
 
 	go func() {
-		errChan <- s.Start(ctx, func() {
+		errChan <- s.Start(ctx, func(context.Context) error {
 			// This might not even trigger given the 100ms timeout
 			// and minute-level precision, which is fine for this lifecycle test.
+			return nil
 		})
 	}()
 
@@ -40,10 +42,10 @@ func TestCronScheduler_StartStop(t *testing.T) {
 }
 
 func TestCronScheduler_InvalidExpression(t *testing.T) {
-	s := NewCronScheduler("invalid-cron-expr")
+	s := NewCronScheduler("invalid-cron-expr", Config{})
 
 	// Start should return an error immediately if the cron expression is bad
-	err := s.Start(context.Background(), func() {})
+	err := s.Start(context.Background(), func(context.Context) error { return nil })
 	if err == nil {
 		t.Error("Expected error for invalid cron expression, got nil")
 	}
@@ -51,13 +53,14 @@ func TestCronScheduler_InvalidExpression(t *testing.T) {
 
 func TestCronScheduler_JobExecution(t *testing.T) {
 	// 1. Every second (Requires WithSeconds() in constructor)
-	s := NewCronScheduler("*/1 * * * * *")
+	s := NewCronScheduler("*/1 * * * * *", Config{})
 
 	var wg sync.WaitGroup
 	wg.Add(1)
 
-	job := func() {
+	job := func(context.Context) error {
 		wg.Done()
+		return nil
 	}
 
 	// 2. Use a context we can cancel
@@ -85,3 +88,332 @@ func TestCronScheduler_JobExecution(t *testing.T) {
 		t.Fatal("Job was not called within 2.5 seconds")
 	}
 }
+
+func TestValidateExpression(t *testing.T) {
+	tests := []struct {
+		expr      string
+		expectErr bool
+	}{
+		{expr: "0 */5 * * * *", expectErr: false},
+		{expr: "@hourly", expectErr: false},
+		{expr: "*/5 * * * *", expectErr: true}, // 5 fields: seconds are required
+		{expr: "invalid", expectErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.expr, func(t *testing.T) {
+			if err := ValidateExpression(tc.expr); (err != nil) != tc.expectErr {
+				t.Errorf("ValidateExpression(%q) error = %v, expectErr %v", tc.expr, err, tc.expectErr)
+			}
+		})
+	}
+}
+
+func TestCronScheduler_AddJob(t *testing.T) {
+	tests := []struct {
+		name      string
+		cronExpr  string
+		expectErr bool
+	}{
+		{name: "Valid expression", cronExpr: "*/1 * * * * *", expectErr: false},
+		{name: "Invalid expression", cronExpr: "not-a-cron", expectErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewCronScheduler("0 0 0 1 1 *", Config{})
+			err := s.AddJob("users", tc.cronExpr, func(context.Context) error { return nil })
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expectErr %v, got %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestCronScheduler_IndependentJobs(t *testing.T) {
+	// Default job never fires during the test; only the added job should run
+	s := NewCronScheduler("0 0 0 1 1 *", Config{})
+
+	called := make(chan struct{}, 1)
+	if err := s.AddJob("users", "*/1 * * * * *", func(context.Context) error {
+		select {
+		case called <- struct{}{}:
+		default:
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("AddJob() failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go s.Start(ctx, nil)
+
+	select {
+	case <-called:
+		// Success!
+	case <-time.After(2500 * time.Millisecond):
+		t.Fatal("Added job was not called within 2.5 seconds")
+	}
+}
+
+func TestCronScheduler_RunNowTimeout(t *testing.T) {
+	tests := []struct {
+		name          string
+		timeout       time.Duration
+		expectTimeout bool
+	}{
+		{name: "Hung job is cancelled", timeout: 50 * time.Millisecond, expectTimeout: true},
+		{name: "No timeout configured", timeout: 0, expectTimeout: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewCronScheduler("0 0 0 1 1 *", Config{JobTimeout: tc.timeout})
+
+			var jobErr error
+			s.RunNow(context.Background(), "hung", func(ctx context.Context) error {
+				select {
+				case <-ctx.Done():
+					jobErr = ctx.Err()
+				case <-time.After(200 * time.Millisecond):
+				}
+				return jobErr
+			})
+
+			if tc.expectTimeout && jobErr != context.DeadlineExceeded {
+				t.Errorf("expected job context to hit its deadline, got %v", jobErr)
+			}
+			if !tc.expectTimeout && jobErr != nil {
+				t.Errorf("expected job to run to completion, got %v", jobErr)
+			}
+		})
+	}
+}
+
+func TestCronScheduler_ShutdownDrainsRunningJob(t *testing.T) {
+	s := NewCronScheduler("0 0 0 1 1 *", Config{DrainTimeout: time.Second})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started := make(chan struct{})
+	finished := make(chan error, 1)
+	go s.RunNow(ctx, "webhook", func(jobCtx context.Context) error {
+		close(started)
+		// Simulate a run that is still flushing when shutdown begins
+		select {
+		case <-jobCtx.Done():
+			finished <- jobCtx.Err()
+		case <-time.After(100 * time.Millisecond):
+			finished <- nil
+		}
+		return nil
+	})
+	<-started
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- s.Start(ctx, nil) }()
+	cancel()
+
+	select {
+	case <-errChan:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Start() did not return after draining")
+	}
+
+	select {
+	case err := <-finished:
+		if err != nil {
+			t.Errorf("Expected job to finish within the drain timeout, got %v", err)
+		}
+	default:
+		t.Error("Start() returned before the running job finished")
+	}
+
+	// Runs requested after shutdown are dropped
+	ran := false
+	s.RunNow(ctx, "late", func(context.Context) error { ran = true; return nil })
+	if ran {
+		t.Error("Expected run after shutdown to be dropped")
+	}
+}
+
+func TestCronScheduler_Trigger(t *testing.T) {
+	s := NewCronScheduler("0 0 0 1 1 *", Config{OverlapPolicy: OverlapSkip})
+
+	if err := s.Trigger(context.Background(), "default"); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected ErrNotRunning before Start, got %v", err)
+	}
+
+	type key struct{}
+	release := make(chan struct{})
+	runs := make(chan any, 2)
+	job := func(ctx context.Context) error {
+		runs <- ctx.Value(key{})
+		<-release
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errChan := make(chan error, 1)
+	go func() { errChan <- s.Start(ctx, job) }()
+
+	// Wait for Start to accept triggers
+	deadline := time.Now().Add(time.Second)
+	for errors.Is(s.Trigger(context.Background(), "missing"), ErrNotRunning) {
+		if time.Now().After(deadline) {
+			t.Fatal("scheduler did not start")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := s.Trigger(context.Background(), "missing"); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("Expected ErrUnknownJob, got %v", err)
+	}
+
+	// The run outlives the caller's context but keeps its values
+	reqCtx, cancelReq := context.WithCancel(context.WithValue(context.Background(), key{}, "run-1"))
+	if err := s.Trigger(reqCtx, "default"); err != nil {
+		t.Fatalf("Trigger() failed: %v", err)
+	}
+	cancelReq()
+	if v := <-runs; v != "run-1" {
+		t.Errorf("Expected the run to see the trigger's values, got %v", v)
+	}
+
+	// The overlap policy applies to triggered runs
+	if err := s.Trigger(context.Background(), "default"); !errors.Is(err, ErrRunSkipped) {
+		t.Errorf("Expected ErrRunSkipped while a run is in progress, got %v", err)
+	}
+
+	close(release)
+	cancel()
+	<-errChan
+
+	if err := s.Trigger(context.Background(), "default"); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected ErrNotRunning after shutdown, got %v", err)
+	}
+}
+
+func TestCronScheduler_FailureBackoff(t *testing.T) {
+	s := NewCronScheduler("0 0 0 1 1 *", Config{FailureBackoff: time.Hour})
+
+	runs := 0
+	job := func(context.Context) error {
+		runs++
+		return errors.New("unauthorized")
+	}
+	scheduled := s.register("backoff", job)
+
+	s.RunNow(context.Background(), "backoff", job)
+	if runs != 1 {
+		t.Fatalf("Expected the first run to execute, got %d runs", runs)
+	}
+
+	// The next scheduled fire is held back after the failure
+	scheduled()
+	if runs != 1 {
+		t.Errorf("Expected the scheduled run to be skipped, got %d runs", runs)
+	}
+}
+
+func TestCronScheduler_Introspection(t *testing.T) {
+	var _ Scheduler = (*CronScheduler)(nil)
+
+	s := NewCronScheduler("0 0 0 1 1 *", Config{})
+	if !s.NextRun().IsZero() || !s.LastRun().IsZero() || s.IsRunning() {
+		t.Fatal("Expected no next run, last run or running job before Start")
+	}
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	job := func(context.Context) error {
+		close(started)
+		<-release
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Start(ctx, job)
+
+	deadline := time.Now().Add(time.Second)
+	for s.NextRun().IsZero() {
+		if time.Now().After(deadline) {
+			t.Fatal("scheduler did not start")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if next := s.NextRun(); next.Month() != time.January || next.Day() != 1 {
+		t.Errorf("Expected the next run on January 1st, got %v", next)
+	}
+
+	before := time.Now()
+	go s.RunNow(ctx, "manual", job)
+	<-started
+	if !s.IsRunning() {
+		t.Error("Expected IsRunning() while the job runs")
+	}
+	if s.LastRun().Before(before) {
+		t.Errorf("Expected LastRun() after %v, got %v", before, s.LastRun())
+	}
+
+	close(release)
+	for s.IsRunning() {
+		if time.Now().After(deadline.Add(time.Second)) {
+			t.Fatal("Expected IsRunning() to clear once the job finished")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCronScheduler_TriggerJob(t *testing.T) {
+	s := NewCronScheduler("0 0 0 1 1 *", Config{OverlapPolicy: OverlapSkip})
+
+	job := func(ctx context.Context) error { return nil }
+	if _, err := s.TriggerJob(context.Background(), "webhook", job); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected ErrNotRunning before Start, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errChan := make(chan error, 1)
+	go func() { errChan <- s.Start(ctx, nil) }()
+
+	release := make(chan struct{})
+	blocking := func(ctx context.Context) error {
+		<-release
+		return nil
+	}
+	var done <-chan struct{}
+	deadline := time.Now().Add(time.Second)
+	for {
+		var err error
+		done, err = s.TriggerJob(context.Background(), "webhook", blocking)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, ErrNotRunning) || time.Now().After(deadline) {
+			t.Fatalf("TriggerJob() failed: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Runs under the same name share the overlap guard
+	if _, err := s.TriggerJob(context.Background(), "webhook", job); !errors.Is(err, ErrRunSkipped) {
+		t.Errorf("Expected ErrRunSkipped while a run is in progress, got %v", err)
+	}
+	// but are not jobs Trigger knows about
+	if err := s.Trigger(context.Background(), "webhook"); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("Expected ErrUnknownJob, got %v", err)
+	}
+
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("done was not closed after the run")
+	}
+	cancel()
+	<-errChan
+}
@@ -0,0 +1,313 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// AttachmentsDir is the directory of the output root holding attachment
+// files. Each file is stored once, however many tasks it is attached to, as
+// blobs/<first two hex digits>/<sha256>. AttachmentRefsFile maps every
+// attachment GID to its blob.
+const AttachmentsDir = "attachments"
+
+// AttachmentRefsFile is the reference map of AttachmentsDir
+const AttachmentRefsFile = "refs.json"
+
+// AttachmentRef describes one attachment and the blob holding its file
+type AttachmentRef struct {
+	SHA256    string    `json:"sha256"`
+	Name      string    `json:"name"`
+	Task      string    `json:"task,omitempty"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AttachmentReport is the result of VerifyAttachments
+type AttachmentReport struct {
+	Attachments int `json:"attachments"`
+	Blobs       int `json:"blobs"`
+	// Missing and Corrupt list the GIDs of attachments whose blob is
+	// absent or no longer matches its hash
+	Missing []string `json:"missing,omitempty"`
+	Corrupt []string `json:"corrupt,omitempty"`
+}
+
+// OK reports whether every attachment's blob is present and intact
+func (r AttachmentReport) OK() bool {
+	return len(r.Missing) == 0 && len(r.Corrupt) == 0
+}
+
+// attachmentRefs is the reference map, loaded on first use and written
+// with the manifest
+type attachmentRefs struct {
+	load  sync.Once
+	err   error
+	mu    sync.Mutex
+	refs  map[string]AttachmentRef
+	dirty bool
+	// blobs holds the lock of every blob being published, guarded by mu
+	blobs map[string]*blobLock
+}
+
+// blobLock serializes the publishing of one blob. It is dropped from
+// attachmentRefs.blobs once nobody holds or waits for it.
+type blobLock struct {
+	mu    sync.Mutex
+	users int
+}
+
+// lockBlob locks the blob with the given hash and returns its unlock
+func (r *attachmentRefs) lockBlob(hash string) func() {
+	r.mu.Lock()
+	if r.blobs == nil {
+		r.blobs = make(map[string]*blobLock)
+	}
+	l, ok := r.blobs[hash]
+	if !ok {
+		l = &blobLock{}
+		r.blobs[hash] = l
+	}
+	l.users++
+	r.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		r.mu.Lock()
+		if l.users--; l.users == 0 {
+			delete(r.blobs, hash)
+		}
+		r.mu.Unlock()
+	}
+}
+
+// AttachmentStored reports whether the file of the attachment is stored
+func (s *JSONStorage) AttachmentStored(gid string) bool {
+	refs, err := s.attachmentRefs()
+	if err != nil {
+		return false
+	}
+	refs.mu.Lock()
+	ref, ok := refs.refs[gid]
+	refs.mu.Unlock()
+	if !ok {
+		return false
+	}
+	_, err = os.Stat(s.blobPath(ref.SHA256))
+	return err == nil
+}
+
+// WriteAttachment stores an attachment's file under its content hash,
+// unless an identical file is already stored, and references it. Files are
+// neither compressed nor encrypted, so they can be served as is.
+//
+// The file is streamed to a temporary file while it is hashed, then renamed
+// to its blob. Only the rename is serialized, per blob, so attachments are
+// written in parallel and two with the same content never publish it at
+// once.
+func (s *JSONStorage) WriteAttachment(attachment asana.Attachment, file io.Reader) error {
+	refs, err := s.attachmentRefs()
+	if err != nil {
+		return err
+	}
+	tempFile, hash, size, err := s.stageBlob(file)
+	if err != nil {
+		return err
+	}
+	// Left behind only if the blob was already stored or publishing failed
+	defer os.Remove(tempFile)
+
+	if err := s.publishBlob(refs, tempFile, hash, size); err != nil {
+		return err
+	}
+
+	ref := AttachmentRef{SHA256: hash, Name: attachment.Name, Size: size, CreatedAt: attachment.CreatedAt}
+	if attachment.Parent != nil {
+		ref.Task = attachment.Parent.GID
+	}
+	refs.mu.Lock()
+	defer refs.mu.Unlock()
+	refs.refs[attachment.GID] = ref
+	refs.dirty = true
+	return nil
+}
+
+// stageBlob copies file to a temporary file of AttachmentsDir, hashing it
+// on the way, and returns the temporary file with the hex SHA-256 and size
+// of its content
+func (s *JSONStorage) stageBlob(file io.Reader) (tempFile, hash string, size int64, err error) {
+	dir := filepath.Join(s.baseDir, AttachmentsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", 0, fmt.Errorf("failed to create attachments directory: %w", err)
+	}
+	// Journaled so a crash mid-download leaves no temporary file behind
+	staging := filepath.Join(dir, "blob")
+	if err := s.journal.intent(JournalPut, staging); err != nil {
+		return "", "", 0, err
+	}
+
+	f, err := os.CreateTemp(dir, filepath.Base(staging)+tempPattern)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	tempFile = f.Name()
+	h := sha256.New()
+	err = f.Chmod(0644)
+	if err == nil {
+		size, err = io.Copy(io.MultiWriter(f, h), file)
+	}
+	if err == nil && s.durable {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tempFile)
+		return "", "", 0, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	return tempFile, hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// publishBlob renames tempFile to the blob with the given hash, unless that
+// blob is already stored
+func (s *JSONStorage) publishBlob(refs *attachmentRefs, tempFile, hash string, size int64) error {
+	blob := s.blobPath(hash)
+	unlock := refs.lockBlob(hash)
+	defer unlock()
+
+	if _, err := os.Stat(blob); !errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(blob), 0755); err != nil {
+		return fmt.Errorf("failed to create attachments directory: %w", err)
+	}
+	if err := s.journal.intent(JournalPut, blob); err != nil {
+		return err
+	}
+	if err := os.Rename(tempFile, blob); err != nil {
+		return fmt.Errorf("failed to rename file: %w", err)
+	}
+	if s.durable {
+		if err := syncDir(filepath.Dir(blob)); err != nil {
+			return err
+		}
+	}
+	s.written[AttachmentsDir].Add(size)
+	return nil
+}
+
+// ReadAttachmentRefs returns the reference map, empty before the first
+// attachment is stored
+func (s *JSONStorage) ReadAttachmentRefs() (map[string]AttachmentRef, error) {
+	refs, err := s.attachmentRefs()
+	if err != nil {
+		return nil, err
+	}
+	refs.mu.Lock()
+	defer refs.mu.Unlock()
+
+	out := make(map[string]AttachmentRef, len(refs.refs))
+	for gid, ref := range refs.refs {
+		out[gid] = ref
+	}
+	return out, nil
+}
+
+// VerifyAttachments hashes every referenced blob again and reports the
+// attachments whose file is missing or corrupt
+func (s *JSONStorage) VerifyAttachments() (AttachmentReport, error) {
+	refs, err := s.ReadAttachmentRefs()
+	if err != nil {
+		return AttachmentReport{}, err
+	}
+
+	report := AttachmentReport{Attachments: len(refs)}
+	hashes := make(map[string]error)
+	for gid, ref := range refs {
+		err, checked := hashes[ref.SHA256]
+		if !checked {
+			var hash string
+			hash, err = fileHash(s.blobPath(ref.SHA256))
+			if err == nil && hash != ref.SHA256 {
+				err = errCorruptBlob
+			}
+			hashes[ref.SHA256] = err
+			if err == nil || errors.Is(err, errCorruptBlob) {
+				report.Blobs++
+			}
+		}
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			report.Missing = append(report.Missing, gid)
+		case errors.Is(err, errCorruptBlob):
+			report.Corrupt = append(report.Corrupt, gid)
+		case err != nil:
+			return report, fmt.Errorf("failed to hash attachment %s: %w", gid, err)
+		}
+	}
+	sort.Strings(report.Missing)
+	sort.Strings(report.Corrupt)
+	return report, nil
+}
+
+// errCorruptBlob marks a blob whose content does not match its name
+var errCorruptBlob = errors.New("blob does not match its hash")
+
+// attachmentRefs returns the reference map, reading it on first use
+func (s *JSONStorage) attachmentRefs() (*attachmentRefs, error) {
+	r := &s.attachments
+	r.load.Do(func() {
+		r.refs = make(map[string]AttachmentRef)
+		data, err := os.ReadFile(filepath.Join(s.baseDir, AttachmentsDir, AttachmentRefsFile))
+		if errors.Is(err, os.ErrNotExist) {
+			return
+		}
+		if err != nil {
+			r.err = fmt.Errorf("failed to read attachment references: %w", err)
+			return
+		}
+		if err := json.Unmarshal(data, &r.refs); err != nil {
+			r.err = fmt.Errorf("failed to unmarshal attachment references: %w", err)
+		}
+	})
+	return r, r.err
+}
+
+// writeAttachmentRefs writes the reference map if attachments were stored
+// since it was last written
+func (s *JSONStorage) writeAttachmentRefs() error {
+	r := &s.attachments
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.dirty {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(r.refs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal attachment references: %w", err)
+	}
+	if err := s.replaceFile(filepath.Join(s.baseDir, AttachmentsDir, AttachmentRefsFile), data); err != nil {
+		return err
+	}
+	r.dirty = false
+	return nil
+}
+
+// blobPath returns the file of the blob with the given hex SHA-256
+func (s *JSONStorage) blobPath(hash string) string {
+	return filepath.Join(s.baseDir, AttachmentsDir, "blobs", hash[:2], hash)
+}
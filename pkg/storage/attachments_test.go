@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestWriteAttachment(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.AttachmentStored("a1") {
+		t.Fatal("expected no attachment before the first write")
+	}
+	task := &asana.ParentRef{GID: "t1", ResourceType: "task"}
+	if err := s.WriteAttachment(asana.Attachment{GID: "a1", Name: "spec.pdf", Parent: task}, strings.NewReader("%PDF")); err != nil {
+		t.Fatalf("WriteAttachment() error = %v", err)
+	}
+	// The same file attached elsewhere is stored once
+	if err := s.WriteAttachment(asana.Attachment{GID: "a2", Name: "copy.pdf"}, strings.NewReader("%PDF")); err != nil {
+		t.Fatalf("WriteAttachment() error = %v", err)
+	}
+	if !s.AttachmentStored("a1") || !s.AttachmentStored("a2") {
+		t.Error("expected both attachments to be stored")
+	}
+	if s.BytesWritten(AttachmentsDir) != 4 {
+		t.Errorf("expected one 4-byte blob written, got %d bytes", s.BytesWritten(AttachmentsDir))
+	}
+
+	refs, _ := s.ReadAttachmentRefs()
+	ref := refs["a1"]
+	if ref.SHA256 != refs["a2"].SHA256 || ref.Task != "t1" || ref.Name != "spec.pdf" || ref.Size != 4 {
+		t.Fatalf("unexpected references %+v", refs)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, AttachmentsDir, "blobs", ref.SHA256[:2], ref.SHA256))
+	if err != nil || string(data) != "%PDF" {
+		t.Fatalf("blob not written: %q, %v", data, err)
+	}
+
+	// The reference map is written with the manifest and read back
+	if err := s.WriteManifest(map[string]any{"run_id": "r1"}); err != nil {
+		t.Fatal(err)
+	}
+	s, err = NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.ReadAttachmentRefs(); !reflect.DeepEqual(got, refs) {
+		t.Errorf("expected the references to be persisted, got %+v", got)
+	}
+}
+
+func TestWriteAttachment_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.WriteAttachment(asana.Attachment{GID: fmt.Sprintf("a%d", i)}, strings.NewReader("%PDF")); err != nil {
+				t.Errorf("WriteAttachment() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Attachments with the same content publish one blob, once
+	if s.BytesWritten(AttachmentsDir) != 4 {
+		t.Errorf("expected one 4-byte blob written, got %d bytes", s.BytesWritten(AttachmentsDir))
+	}
+	if refs, _ := s.ReadAttachmentRefs(); len(refs) != 8 {
+		t.Errorf("expected 8 references, got %d", len(refs))
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(dir, AttachmentsDir, "*"+tempPattern)); len(tmps) > 0 {
+		t.Errorf("expected no temporary file to be left behind, found %v", tmps)
+	}
+}
+
+func TestWriteAttachment_FailedRead(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := io.MultiReader(strings.NewReader("%PDF"), iotest.ErrReader(errors.New("connection reset")))
+	if err := s.WriteAttachment(asana.Attachment{GID: "a1"}, file); err == nil {
+		t.Fatal("expected a failed read to fail the write")
+	}
+	if s.AttachmentStored("a1") {
+		t.Error("expected a partly read attachment not to be stored")
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(dir, AttachmentsDir, "*"+tempPattern)); len(tmps) > 0 {
+		t.Errorf("expected no temporary file to be left behind, found %v", tmps)
+	}
+}
+
+func TestVerifyAttachments(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.WriteAttachment(asana.Attachment{GID: "a1"}, strings.NewReader("one"))
+	s.WriteAttachment(asana.Attachment{GID: "a2"}, strings.NewReader("one"))
+	s.WriteAttachment(asana.Attachment{GID: "a3"}, strings.NewReader("two"))
+	s.WriteAttachment(asana.Attachment{GID: "a4"}, strings.NewReader("three"))
+
+	report, err := s.VerifyAttachments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() || report.Attachments != 4 || report.Blobs != 3 {
+		t.Fatalf("unexpected report for intact blobs %+v", report)
+	}
+
+	refs, _ := s.ReadAttachmentRefs()
+	os.WriteFile(s.blobPath(refs["a1"].SHA256), []byte("tampered"), 0644)
+	os.Remove(s.blobPath(refs["a3"].SHA256))
+
+	report, err = s.VerifyAttachments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.OK() || !reflect.DeepEqual(report.Corrupt, []string{"a1", "a2"}) || !reflect.DeepEqual(report.Missing, []string{"a3"}) {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if s.AttachmentStored("a3") {
+		t.Error("expected an attachment whose blob is gone to need a download")
+	}
+}
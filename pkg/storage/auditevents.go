@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// AuditEventsDir is the directory of the output root holding the audit log
+// events fetched by each run, named <run_id>.ndjson
+const AuditEventsDir = "audit_log_events"
+
+// WriteAuditLogEvents appends events to the run's audit log events file,
+// one JSON object per line. The file is neither compressed nor encrypted,
+// so SIEM shippers can tail it.
+func (s *JSONStorage) WriteAuditLogEvents(runID string, events []asana.AuditLogEvent) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			return fmt.Errorf("failed to marshal audit log event %s: %w", event.GID, err)
+		}
+	}
+
+	dir := filepath.Join(s.baseDir, AuditEventsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create audit log events directory: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, runID+".ndjson"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log events file: %w", err)
+	}
+	_, err = f.Write(buf.Bytes())
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to append audit log events: %w", err)
+	}
+	s.written[AuditEventsDir].Add(int64(buf.Len()))
+	return nil
+}
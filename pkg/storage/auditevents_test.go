@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestWriteAuditLogEvents(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewJSONStorageWithOptions(dir, Options{Compression: CompressionGzip})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batches := [][]asana.AuditLogEvent{
+		{{GID: "e1", EventType: "user_login_succeeded"}, {GID: "e2"}},
+		{{GID: "e3", Details: map[string]any{"old_value": "a"}}},
+	}
+	for _, batch := range batches {
+		if err := s.WriteAuditLogEvents("run1", batch); err != nil {
+			t.Fatalf("WriteAuditLogEvents() error = %v", err)
+		}
+	}
+
+	f, err := os.Open(filepath.Join(dir, AuditEventsDir, "run1.ndjson"))
+	if err != nil {
+		t.Fatalf("audit log events file not written: %v", err)
+	}
+	defer f.Close()
+
+	var gids []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event asana.AuditLogEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("line %q is not a JSON event: %v", scanner.Text(), err)
+		}
+		gids = append(gids, event.GID)
+	}
+	if len(gids) != 3 || gids[2] != "e3" {
+		t.Errorf("Expected e1-e3 in order, uncompressed, got %v", gids)
+	}
+	if s.BytesWritten(AuditEventsDir) == 0 {
+		t.Error("Expected the bytes written to be counted")
+	}
+}
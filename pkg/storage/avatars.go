@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// AvatarsDir is the directory of the output root holding user avatars,
+// named <user gid>.png. Next to each, <user gid>.sha256 holds the SHA-256
+// of the photo URL it was downloaded from.
+const AvatarsDir = "avatars"
+
+// AvatarCurrent reports whether the stored avatar of the user was
+// downloaded from photoURL, so it need not be downloaded again
+func (s *JSONStorage) AvatarCurrent(userGID, photoURL string) bool {
+	hash, err := os.ReadFile(s.avatarPath(userGID, ".sha256"))
+	if err != nil || string(hash) != urlHash(photoURL) {
+		return false
+	}
+	_, err = os.Stat(s.avatarPath(userGID, ".png"))
+	return err == nil
+}
+
+// WriteAvatar writes a user's avatar, then the hash of the URL it came
+// from. The image is neither compressed nor encrypted, so it can be served
+// as is.
+func (s *JSONStorage) WriteAvatar(userGID, photoURL string, image []byte) error {
+	if err := os.MkdirAll(filepath.Join(s.baseDir, AvatarsDir), 0755); err != nil {
+		return fmt.Errorf("failed to create avatars directory: %w", err)
+	}
+	if err := s.writeIfChanged(s.avatarPath(userGID, ".png"), image); err != nil {
+		return err
+	}
+	// Written last: an avatar whose hash is missing or stale is downloaded
+	// again
+	if err := s.replaceFile(s.avatarPath(userGID, ".sha256"), []byte(urlHash(photoURL))); err != nil {
+		return err
+	}
+	s.written[AvatarsDir].Add(int64(len(image)))
+	return nil
+}
+
+// avatarPath returns the file of a user's avatar with the given extension
+func (s *JSONStorage) avatarPath(userGID, ext string) string {
+	return filepath.Join(s.baseDir, AvatarsDir, userGID+ext)
+}
+
+// urlHash returns the hex SHA-256 of url
+func urlHash(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteAvatar(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const url = "https://s3.amazonaws.com/profile_photos/u1_1024x1024.png"
+	if s.AvatarCurrent("u1", url) {
+		t.Fatal("expected no avatar before the first write")
+	}
+	if err := s.WriteAvatar("u1", url, []byte("\x89PNG")); err != nil {
+		t.Fatalf("WriteAvatar() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, AvatarsDir, "u1.png"))
+	if err != nil || string(data) != "\x89PNG" {
+		t.Fatalf("avatar not written: %q, %v", data, err)
+	}
+	if !s.AvatarCurrent("u1", url) {
+		t.Error("expected the avatar to be current for the same URL")
+	}
+	if s.AvatarCurrent("u1", url+"?v=2") {
+		t.Error("expected a new photo URL to need a download")
+	}
+
+	os.Remove(filepath.Join(dir, AvatarsDir, "u1.png"))
+	if s.AvatarCurrent("u1", url) {
+		t.Error("expected a deleted avatar to need a download")
+	}
+	if s.BytesWritten(AvatarsDir) != 4 {
+		t.Errorf("expected 4 avatar bytes written, got %d", s.BytesWritten(AvatarsDir))
+	}
+}
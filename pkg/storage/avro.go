@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+	"github.com/ioanzicu/asana-extractor/pkg/avro"
+)
+
+// AvroStorage writes one Avro object container file per resource,
+// <resource>.avro, with the schema derived from the pkg/asana types. Like
+// the csv backend, records end with a run_id field holding the run that
+// wrote them, and each run writes fresh files, which replace the previous
+// ones only when the run succeeds.
+type AvroStorage struct {
+	runStamp
+	codecs    map[string]*avro.Codec
+	schemaIDs map[string]int
+
+	mu    sync.Mutex
+	files *runFiles[*avro.Writer]
+}
+
+func init() {
+	Register("avro", func(s Settings) (Backend, error) {
+		if len(s.Options.EncryptionKey) > 0 || (s.Options.Compression != "" && s.Options.Compression != CompressionNone) {
+			return nil, fmt.Errorf("the avro backend supports neither compression nor encryption")
+		}
+		st, err := NewAvroStorage(s.Dir, s.Params)
+		if err != nil {
+			return nil, err
+		}
+		st.files.durable = s.Options.DurableWrites
+		return st, nil
+	})
+}
+
+// NewAvroStorage creates an Avro storage in dir. When params sets
+// registry_url, the schema of every resource is registered with that
+// Confluent Schema Registry under <subject_prefix><resource>-value, the
+// subject a Kafka topic named after the resource uses for its values.
+// subject_prefix defaults to "asana.".
+func NewAvroStorage(dir string, params map[string]string) (*AvroStorage, error) {
+	for key := range params {
+		if key != "registry_url" && key != "subject_prefix" {
+			return nil, fmt.Errorf("unknown avro parameter %q", key)
+		}
+	}
+
+	s := &AvroStorage{codecs: make(map[string]*avro.Codec), schemaIDs: make(map[string]int)}
+	for resource, v := range map[string]any{
+		"users":    asana.User{},
+		"projects": asana.Project{},
+		"tasks":    asana.Task{},
+		"teams":    asana.Team{},
+	} {
+		codec, err := avro.NewCodec(v, RunIDField)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive %s schema: %w", resource, err)
+		}
+		s.codecs[resource] = codec
+	}
+
+	if url := params["registry_url"]; url != "" {
+		prefix, ok := params["subject_prefix"]
+		if !ok {
+			prefix = "asana."
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		registry := avro.NewRegistry(url)
+		for resource, codec := range s.codecs {
+			id, err := registry.Register(ctx, prefix+resource+"-value", codec.Schema())
+			if err != nil {
+				return nil, err
+			}
+			s.schemaIDs[resource] = id
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	s.files = newRunFiles(dir, ".avro", func(resource string, f io.Writer) (*avro.Writer, error) {
+		return avro.NewWriter(f, s.codecs[resource])
+	})
+	return s, nil
+}
+
+// Codec returns the codec of resource, or nil for an unknown resource
+func (s *AvroStorage) Codec(resource string) *avro.Codec {
+	return s.codecs[resource]
+}
+
+// SchemaID returns the registry ID of resource's schema, or zero when no
+// registry is configured
+func (s *AvroStorage) SchemaID(resource string) int {
+	return s.schemaIDs[resource]
+}
+
+// WriteUser appends user to users.avro
+func (s *AvroStorage) WriteUser(user asana.User) error {
+	return s.write("users", user)
+}
+
+// WriteProject appends project to projects.avro
+func (s *AvroStorage) WriteProject(project asana.Project) error {
+	return s.write("projects", project)
+}
+
+// WriteTask appends task to tasks.avro
+func (s *AvroStorage) WriteTask(task asana.Task) error {
+	return s.write("tasks", task)
+}
+
+// WriteTeam appends team to teams.avro
+func (s *AvroStorage) WriteTeam(team asana.Team) error {
+	return s.write("teams", team)
+}
+
+// WriteUsers appends users to users.avro
+func (s *AvroStorage) WriteUsers(users []asana.User) error {
+	return s.write("users", anys(users)...)
+}
+
+// WriteProjects appends projects to projects.avro
+func (s *AvroStorage) WriteProjects(projects []asana.Project) error {
+	return s.write("projects", anys(projects)...)
+}
+
+// WriteTasks appends tasks to tasks.avro
+func (s *AvroStorage) WriteTasks(tasks []asana.Task) error {
+	return s.write("tasks", anys(tasks)...)
+}
+
+// WriteTeams appends teams to teams.avro
+func (s *AvroStorage) WriteTeams(teams []asana.Team) error {
+	return s.write("teams", anys(teams)...)
+}
+
+// WriteManifest ends the run, publishing its files if it succeeded
+func (s *AvroStorage) WriteManifest(manifest any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.files.finish(manifest, func(resource string) bool {
+		_, ok := s.codecs[resource]
+		return ok
+	})
+}
+
+// write appends each of vs to resource's file
+func (s *AvroStorage) write(resource string, vs ...any) error {
+	if len(vs) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, err := s.files.get(resource)
+	if err != nil {
+		return err
+	}
+	runID := s.runID()
+	for _, v := range vs {
+		if err := w.Write(v, runID); err != nil {
+			return fmt.Errorf("failed to write %s record: %w", resource, err)
+		}
+	}
+	return nil
+}
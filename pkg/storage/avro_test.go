@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestAvroStorage(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewAvroStorage(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.WriteTask(asana.Task{GID: "t1", Name: "Close the books"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteTasks([]asana.Task{{GID: "t2", Name: "File taxes"}, {GID: "t3"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteManifest(map[string]any{"status": "succeeded", "resources": []string{"tasks", "teams"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "tasks.avro"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("Obj\x01")) {
+		t.Error("Expected an Avro object container file")
+	}
+	if !bytes.Contains(data, []byte(s.Codec("tasks").Schema())) || !bytes.Contains(data, []byte("Close the books")) || !bytes.Contains(data, []byte("File taxes")) {
+		t.Error("Expected the schema and the tasks in tasks.avro")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "teams.avro")); err != nil {
+		t.Errorf("Expected an empty teams.avro for a run without teams: %v", err)
+	}
+	if s.SchemaID("tasks") != 0 {
+		t.Error("Expected no schema ID without a registry")
+	}
+}
+
+func TestAvroStorage_Registry(t *testing.T) {
+	var mu sync.Mutex
+	subjects := make(map[string]bool)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		subjects[strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/subjects/"), "/versions")] = true
+		mu.Unlock()
+		w.Write([]byte(`{"id":3}`))
+	}))
+	defer srv.Close()
+
+	s, err := NewAvroStorage(t.TempDir(), map[string]string{"registry_url": srv.URL, "subject_prefix": "prod.asana."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, resource := range []string{"users", "projects", "tasks", "teams"} {
+		if !subjects["prod.asana."+resource+"-value"] {
+			t.Errorf("Expected the %s schema to be registered, got %v", resource, subjects)
+		}
+		if s.SchemaID(resource) != 3 {
+			t.Errorf("SchemaID(%s) = %d, want 3", resource, s.SchemaID(resource))
+		}
+	}
+}
+
+func TestNewAvroStorage_Invalid(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "incompatible schema", http.StatusConflict)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name   string
+		params map[string]string
+	}{
+		{name: "Unknown parameter", params: map[string]string{"topic": "asana"}},
+		{name: "Registry rejects the schema", params: map[string]string{"registry_url": srv.URL}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewAvroStorage(t.TempDir(), tc.params); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
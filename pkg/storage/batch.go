@@ -0,0 +1,11 @@
+package storage
+
+// anys converts a batch of entities for the variadic write helpers of the
+// backends that implement extractor.BatchWriter
+func anys[T any](items []T) []any {
+	vs := make([]any, len(items))
+	for i, item := range items {
+		vs[i] = item
+	}
+	return vs
+}
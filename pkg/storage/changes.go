@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ChangesDir is the directory of the output root holding one change log
+// per run, named <run_id>.jsonl
+const ChangesDir = "changes"
+
+// pendingChanges collects the changes of the run in progress
+const pendingChanges = ".pending.jsonl"
+
+// Change operations
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// Change is one record of a change log: an entity that was created,
+// updated or deleted in storage. Payload is the stored entity and is empty
+// for deletes.
+type Change struct {
+	Op         string          `json:"op"`
+	Resource   string          `json:"resource"`
+	GID        string          `json:"gid"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	ObservedAt time.Time       `json:"observed_at"`
+}
+
+// changeLog appends changes to the pending file until publish moves it to
+// the run's change log
+type changeLog struct {
+	mu      sync.Mutex
+	dir     string
+	durable bool
+	f       *os.File
+}
+
+// newChangeLog prepares the changes directory under baseDir. With durable,
+// the pending file is synced before it is published.
+func newChangeLog(baseDir string, durable bool) (*changeLog, error) {
+	dir := filepath.Join(baseDir, ChangesDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create changes directory: %w", err)
+	}
+	return &changeLog{dir: dir, durable: durable}, nil
+}
+
+// record appends a change. It is a no-op on a nil changeLog.
+func (c *changeLog) record(op, resource, gid string, payload []byte) error {
+	if c == nil {
+		return nil
+	}
+
+	change := Change{Op: op, Resource: resource, GID: gid, ObservedAt: time.Now().UTC()}
+	if payload != nil {
+		// Compact the indented entity onto the record's line
+		var compact json.RawMessage
+		if err := json.Unmarshal(payload, &compact); err != nil {
+			return fmt.Errorf("failed to read %s %s for the change log: %w", resource, gid, err)
+		}
+		change.Payload = compact
+	}
+	line, err := json.Marshal(change)
+	if err != nil {
+		return fmt.Errorf("failed to marshal change: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.f == nil {
+		// Changes left pending by a crashed run are carried into this one
+		f, err := os.OpenFile(filepath.Join(c.dir, pendingChanges), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open change log: %w", err)
+		}
+		c.f = f
+	}
+	if _, err := c.f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to append to change log: %w", err)
+	}
+	return nil
+}
+
+// publish moves the pending changes to <runID>.jsonl. A run without changes
+// gets an empty log, so consumers can tell it apart from a missing run.
+func (c *changeLog) publish(runID string) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.f != nil {
+		var err error
+		if c.durable {
+			err = c.f.Sync()
+		}
+		if closeErr := c.f.Close(); err == nil {
+			err = closeErr
+		}
+		c.f = nil
+		if err != nil {
+			return fmt.Errorf("failed to close change log: %w", err)
+		}
+	}
+
+	pending := filepath.Join(c.dir, pendingChanges)
+	target := filepath.Join(c.dir, runID+".jsonl")
+	if _, err := os.Stat(pending); os.IsNotExist(err) {
+		return writeFile(target, nil, c.durable)
+	}
+	if err := os.Rename(pending, target); err != nil {
+		return fmt.Errorf("failed to publish change log: %w", err)
+	}
+	if c.durable {
+		return syncDir(c.dir)
+	}
+	return nil
+}
+
+// manifestRunID returns the run_id of a run manifest
+func manifestRunID(manifest any) (string, error) {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	var m struct {
+		RunID string `json:"run_id"`
+	}
+	if err := json.Unmarshal(data, &m); err != nil || m.RunID == "" {
+		return "", fmt.Errorf("manifest has no run_id")
+	}
+	return m.RunID, nil
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// readChanges reads the change log of a run
+func readChanges(t *testing.T, dir, runID string) []Change {
+	t.Helper()
+	f, err := os.Open(filepath.Join(dir, ChangesDir, runID+".jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to open change log: %v", err)
+	}
+	defer f.Close()
+
+	var changes []Change
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var c Change
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+			t.Fatalf("Invalid change %q: %v", scanner.Text(), err)
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+func TestChangeLog(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewJSONStorageWithOptions(dir, Options{ChangeLog: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Run 1: two users created
+	s.WriteUser(asana.User{GID: "u1", Name: "Ada"})
+	s.WriteUser(asana.User{GID: "u2", Name: "Bob"})
+	if err := s.WriteManifest(map[string]any{"run_id": "run1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Run 2: u1 unchanged, u2 renamed, u3 created and u1 deleted by reconciliation
+	s.WriteUser(asana.User{GID: "u1", Name: "Ada"})
+	s.WriteUser(asana.User{GID: "u2", Name: "Bobby"})
+	s.WriteUser(asana.User{GID: "u3", Name: "Cy"})
+	if _, err := s.Reconcile("users", map[string]struct{}{"u2": {}, "u3": {}}, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteManifest(map[string]any{"run_id": "run2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Run 3: nothing changed, u1 comes back
+	s.WriteUser(asana.User{GID: "u1", Name: "Ada"})
+	if err := s.WriteManifest(map[string]any{"run_id": "run3"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteManifest(map[string]any{"run_id": "run4"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		runID string
+		want  []string
+	}{
+		{"run1", []string{"create users u1", "create users u2"}},
+		{"run2", []string{"update users u2", "create users u3", "delete users u1"}},
+		{"run3", []string{"create users u1"}},
+		{"run4", nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.runID, func(t *testing.T) {
+			changes := readChanges(t, dir, tc.runID)
+			var got []string
+			for _, c := range changes {
+				got = append(got, c.Op+" "+c.Resource+" "+c.GID)
+				if c.ObservedAt.IsZero() {
+					t.Errorf("Change %+v has no observed_at", c)
+				}
+				if (c.Op == OpDelete) != (c.Payload == nil) {
+					t.Errorf("Change %+v: only deletes come without payload", c)
+				}
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("Changes = %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("Changes = %v, want %v", got, tc.want)
+					break
+				}
+			}
+		})
+	}
+
+	var user asana.User
+	if err := json.Unmarshal(readChanges(t, dir, "run2")[0].Payload, &user); err != nil || user.Name != "Bobby" {
+		t.Errorf("Expected the updated user as payload, got %+v (%v)", user, err)
+	}
+}
+
+func TestChangeLog_RejectsEncryption(t *testing.T) {
+	_, err := NewJSONStorageWithOptions(t.TempDir(), Options{ChangeLog: true, EncryptionKey: make([]byte, 32)})
+	if err == nil {
+		t.Error("Expected an error combining the change log with encryption")
+	}
+}
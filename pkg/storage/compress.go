@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression selects how entity files are encoded on disk
+type Compression string
+
+// Supported compression formats
+const (
+	CompressionNone Compression = "none"
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+// ParseCompression validates a compression name. An empty name means none.
+func ParseCompression(name string) (Compression, error) {
+	switch c := Compression(name); c {
+	case "", CompressionNone:
+		return CompressionNone, nil
+	case CompressionGzip, CompressionZstd:
+		return c, nil
+	default:
+		return "", fmt.Errorf("unknown compression %q (supported: none, gzip, zstd)", name)
+	}
+}
+
+// Extension returns the file extension for entity files
+func (c Compression) Extension() string {
+	switch c {
+	case CompressionGzip:
+		return ".json.gz"
+	case CompressionZstd:
+		return ".json.zst"
+	default:
+		return ".json"
+	}
+}
+
+// compress encodes data in the given format. Output is deterministic for a
+// given input, which keeps change detection working on compressed files.
+func compress(c Compression, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	switch c {
+	case CompressionGzip:
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to gzip: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("failed to gzip: %w", err)
+		}
+	case CompressionZstd:
+		zw, err := zstd.NewWriter(&buf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
+		}
+		if _, err := zw.Write(data); err != nil {
+			zw.Close()
+			return nil, fmt.Errorf("failed to zstd: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("failed to zstd: %w", err)
+		}
+	default:
+		return data, nil
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decompress reverses compress
+func decompress(c Compression, data []byte) ([]byte, error) {
+	switch c {
+	case CompressionGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzip stream: %w", err)
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+	case CompressionZstd:
+		zr, err := zstd.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open zstd stream: %w", err)
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+	default:
+		return data, nil
+	}
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestParseCompression(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Compression
+		wantErr bool
+	}{
+		{name: "Empty means none", input: "", want: CompressionNone},
+		{name: "Gzip", input: "gzip", want: CompressionGzip},
+		{name: "Zstd", input: "zstd", want: CompressionZstd},
+		{name: "Unknown", input: "lz4", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCompression(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCompression() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseCompression() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompressedWrites(t *testing.T) {
+	tests := []struct {
+		name        string
+		compression Compression
+		wantFile    string
+	}{
+		{name: "Plain", compression: CompressionNone, wantFile: "u1.json"},
+		{name: "Gzip", compression: CompressionGzip, wantFile: "u1.json.gz"},
+		{name: "Zstd", compression: CompressionZstd, wantFile: "u1.json.zst"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			storage, err := NewJSONStorageWithOptions(tmpDir, Options{Compression: tt.compression})
+			if err != nil {
+				t.Fatalf("NewJSONStorageWithOptions() failed: %v", err)
+			}
+
+			user := asana.User{GID: "u1", Name: "Alice"}
+			if err := storage.WriteUser(user); err != nil {
+				t.Fatalf("WriteUser() failed: %v", err)
+			}
+
+			raw, err := os.ReadFile(filepath.Join(tmpDir, "users", tt.wantFile))
+			if err != nil {
+				t.Fatalf("expected %s: %v", tt.wantFile, err)
+			}
+			data, err := decompress(tt.compression, raw)
+			if err != nil {
+				t.Fatalf("decompress() failed: %v", err)
+			}
+
+			var saved asana.User
+			if err := json.Unmarshal(data, &saved); err != nil || saved.Name != "Alice" {
+				t.Errorf("round trip failed: %v, %+v", err, saved)
+			}
+
+			// Deterministic output keeps change detection working
+			storage.WriteUser(user)
+			if storage.Unchanged() != 1 {
+				t.Errorf("expected identical compressed write to be skipped")
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// CSV nesting modes, set with the nested parameter
+const (
+	// CSVNestedColumns flattens nested objects into dotted columns such as
+	// assignee.name
+	CSVNestedColumns = "columns"
+	// CSVNestedJSON keeps nested objects in one column as a JSON string
+	CSVNestedJSON = "json"
+)
+
+// CSVStorage writes one CSV file per resource, <resource>.csv, for loading
+// into spreadsheets. Lists are always written as JSON strings, and a last
+// run_id column holds the run that wrote each row. Each run
+// writes fresh files, which replace the previous ones only when the run
+// succeeds; a failed run leaves them untouched.
+type CSVStorage struct {
+	runStamp
+	columns map[string][]string
+
+	mu    sync.Mutex
+	files *runFiles[csvWriter]
+}
+
+// csvWriter adapts csv.Writer to runFiles
+type csvWriter struct {
+	*csv.Writer
+}
+
+// Flush writes any buffered rows and returns the first write error
+func (w csvWriter) Flush() error {
+	w.Writer.Flush()
+	return w.Error()
+}
+
+// csvTypes maps each resource to the type its rows are flattened from
+var csvTypes = map[string]reflect.Type{
+	"users":    reflect.TypeOf(asana.User{}),
+	"projects": reflect.TypeOf(asana.Project{}),
+	"tasks":    reflect.TypeOf(asana.Task{}),
+	"teams":    reflect.TypeOf(asana.Team{}),
+}
+
+func init() {
+	Register("csv", func(s Settings) (Backend, error) {
+		if len(s.Options.EncryptionKey) > 0 || (s.Options.Compression != "" && s.Options.Compression != CompressionNone) {
+			return nil, fmt.Errorf("the csv backend supports neither compression nor encryption")
+		}
+		st, err := NewCSVStorage(s.Dir, s.Params)
+		if err != nil {
+			return nil, err
+		}
+		st.files.durable = s.Options.DurableWrites
+		return st, nil
+	})
+}
+
+// NewCSVStorage creates a CSV storage in dir. params may set nested to
+// "columns" (the default) or "json", and select the columns of a resource
+// by its name, as a space-separated list such as "gid name assignee.name".
+// Resources without a selection get every column.
+func NewCSVStorage(dir string, params map[string]string) (*CSVStorage, error) {
+	nested := params["nested"]
+	if nested == "" {
+		nested = CSVNestedColumns
+	}
+	if nested != CSVNestedColumns && nested != CSVNestedJSON {
+		return nil, fmt.Errorf("csv nested must be %s or %s (got %q)", CSVNestedColumns, CSVNestedJSON, nested)
+	}
+	for key := range params {
+		if _, ok := csvTypes[key]; !ok && key != "nested" {
+			return nil, fmt.Errorf("unknown csv parameter %q", key)
+		}
+	}
+
+	columns := make(map[string][]string, len(csvTypes))
+	for resource, t := range csvTypes {
+		all := append(csvColumns(t, "", nested == CSVNestedColumns), RunIDField)
+		selected := strings.Fields(params[resource])
+		if len(selected) == 0 {
+			columns[resource] = all
+			continue
+		}
+		for _, col := range selected {
+			if !slices.Contains(all, col) {
+				return nil, fmt.Errorf("unknown %s column %q (available: %s)", resource, col, strings.Join(all, " "))
+			}
+		}
+		columns[resource] = selected
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	s := &CSVStorage{columns: columns}
+	s.files = newRunFiles(dir, ".csv", func(resource string, f io.Writer) (csvWriter, error) {
+		w := csvWriter{csv.NewWriter(f)}
+		return w, w.Write(columns[resource])
+	})
+	return s, nil
+}
+
+// Columns returns the columns written for resource
+func (s *CSVStorage) Columns(resource string) []string {
+	return s.columns[resource]
+}
+
+// WriteUser appends user to users.csv
+func (s *CSVStorage) WriteUser(user asana.User) error {
+	return s.writeRow("users", user)
+}
+
+// WriteProject appends project to projects.csv
+func (s *CSVStorage) WriteProject(project asana.Project) error {
+	return s.writeRow("projects", project)
+}
+
+// WriteTask appends task to tasks.csv
+func (s *CSVStorage) WriteTask(task asana.Task) error {
+	return s.writeRow("tasks", task)
+}
+
+// WriteTeam appends team to teams.csv
+func (s *CSVStorage) WriteTeam(team asana.Team) error {
+	return s.writeRow("teams", team)
+}
+
+// WriteUsers appends users to users.csv
+func (s *CSVStorage) WriteUsers(users []asana.User) error {
+	return s.writeRow("users", anys(users)...)
+}
+
+// WriteProjects appends projects to projects.csv
+func (s *CSVStorage) WriteProjects(projects []asana.Project) error {
+	return s.writeRow("projects", anys(projects)...)
+}
+
+// WriteTasks appends tasks to tasks.csv
+func (s *CSVStorage) WriteTasks(tasks []asana.Task) error {
+	return s.writeRow("tasks", anys(tasks)...)
+}
+
+// WriteTeams appends teams to teams.csv
+func (s *CSVStorage) WriteTeams(teams []asana.Team) error {
+	return s.writeRow("teams", anys(teams)...)
+}
+
+// WriteManifest ends the run. If it succeeded, the files it wrote replace
+// the previous ones, and extracted resources without entities get a file
+// with just the header; otherwise they are discarded.
+func (s *CSVStorage) WriteManifest(manifest any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.files.finish(manifest, func(resource string) bool {
+		_, ok := s.columns[resource]
+		return ok
+	})
+}
+
+// writeRow appends each of vs as a row of resource's file, opening it with
+// a header on the first row of the run. Rows are encoded before any is
+// written, so a value that cannot be encoded writes none of them.
+func (s *CSVStorage) writeRow(resource string, vs ...any) error {
+	rows := make([][]string, len(vs))
+	for i, v := range vs {
+		row, err := s.row(resource, v)
+		if err != nil {
+			return err
+		}
+		rows[i] = row
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, err := s.files.get(resource)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			return fmt.Errorf("failed to write %s row: %w", resource, err)
+		}
+	}
+	return nil
+}
+
+// row encodes v as the columns of resource
+func (s *CSVStorage) row(resource string, v any) ([]string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", resource, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var fields map[string]any
+	if err := dec.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", resource, err)
+	}
+	fields[RunIDField] = s.runID()
+
+	columns := s.columns[resource]
+	row := make([]string, len(columns))
+	for i, col := range columns {
+		if row[i], err = csvValue(fields, col); err != nil {
+			return nil, fmt.Errorf("failed to encode %s column %s: %w", resource, col, err)
+		}
+	}
+	return row, nil
+}
+
+// csvColumns lists the columns of t in field order, named after the JSON
+// fields. With flatten, nested objects expand into dotted columns; lists
+// and times are single columns either way.
+func csvColumns(t reflect.Type, prefix string, flatten bool) []string {
+	var columns []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if flatten && ft.Kind() == reflect.Struct && ft != timeType {
+			columns = append(columns, csvColumns(ft, prefix+name+".", flatten)...)
+			continue
+		}
+		columns = append(columns, prefix+name)
+	}
+	return columns
+}
+
+// csvValue formats the value at the dotted path col of a decoded entity.
+// Missing and null values are empty; objects and lists are JSON strings.
+func csvValue(fields map[string]any, col string) (string, error) {
+	var v any = fields
+	for _, key := range strings.Split(col, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return "", nil
+		}
+		v = m[key]
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool, json.Number:
+		return fmt.Sprint(v), nil
+	default:
+		data, err := json.Marshal(v)
+		return string(data), err
+	}
+}
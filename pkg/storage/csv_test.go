@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// readCSV reads every record of dir/name
+func readCSV(t *testing.T, dir, name string) [][]string {
+	t.Helper()
+	f, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestCSVStorage(t *testing.T) {
+	completed := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	task := asana.Task{
+		GID:         "t1",
+		Name:        "Close the books",
+		Completed:   true,
+		CompletedAt: &completed,
+		Assignee:    &asana.User{GID: "u1", Name: "Ada"},
+		Projects:    []asana.Project{{GID: "p1"}},
+	}
+
+	tests := []struct {
+		name   string
+		params map[string]string
+		want   [][]string
+	}{
+		{
+			name:   "Dotted columns",
+			params: map[string]string{"tasks": "gid assignee.name completed_at projects"},
+			want: [][]string{
+				{"gid", "assignee.name", "completed_at", "projects"},
+				{"t1", "Ada", "2024-03-01T12:00:00Z", `[{"archived":false,"created_at":"0001-01-01T00:00:00Z","gid":"p1","modified_at":"0001-01-01T00:00:00Z","name":"","public":false,"resource_type":""}]`},
+			},
+		},
+		{
+			name:   "Nested objects as JSON",
+			params: map[string]string{"nested": "json", "tasks": "gid completed assignee"},
+			want: [][]string{
+				{"gid", "completed", "assignee"},
+				{"t1", "true", `{"gid":"u1","name":"Ada","resource_type":""}`},
+			},
+		},
+		{
+			name:   "Missing nested objects are empty",
+			params: map[string]string{"tasks": "gid assignee.email"},
+			want:   [][]string{{"gid", "assignee.email"}, {"t1", ""}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s, err := NewCSVStorage(dir, tc.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.WriteTask(task); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.WriteManifest(map[string]any{"status": "succeeded", "resources": []string{"tasks"}}); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := readCSV(t, dir, "tasks.csv"); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("tasks.csv = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCSVStorage_Runs(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewCSVStorage(dir, map[string]string{"users": "gid name", "teams": "gid"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.WriteUser(asana.User{GID: "u1", Name: "Ada"})
+	if err := s.WriteManifest(map[string]any{"status": "succeeded", "resources": []string{"users", "teams"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := readCSV(t, dir, "teams.csv"); !reflect.DeepEqual(got, [][]string{{"gid"}}) {
+		t.Errorf("Expected a header-only teams.csv for a run without teams, got %q", got)
+	}
+
+	// A failed run must leave the previous files in place
+	s.WriteUser(asana.User{GID: "u2", Name: "Bob"})
+	if err := s.WriteManifest(map[string]any{"status": "failed", "resources": []string{"users"}}); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"gid", "name"}, {"u1", "Ada"}}
+	if got := readCSV(t, dir, "users.csv"); !reflect.DeepEqual(got, want) {
+		t.Errorf("users.csv after a failed run = %q, want %q", got, want)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("Expected only users.csv and teams.csv, got %d entries", len(entries))
+	}
+}
+
+func TestCSVStorage_WriteBatch(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewCSVStorage(dir, map[string]string{"users": "gid name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.WriteUsers([]asana.User{{GID: "u1", Name: "Ada"}, {GID: "u2", Name: "Bob"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteUsers(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteUser(asana.User{GID: "u3", Name: "Cy"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteManifest(map[string]any{"status": "succeeded", "resources": []string{"users"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{{"gid", "name"}, {"u1", "Ada"}, {"u2", "Bob"}, {"u3", "Cy"}}
+	if got := readCSV(t, dir, "users.csv"); !reflect.DeepEqual(got, want) {
+		t.Errorf("users.csv = %q, want %q", got, want)
+	}
+}
+
+func TestNewCSVStorage_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]string
+	}{
+		{name: "Unknown column", params: map[string]string{"tasks": "gid budget"}},
+		{name: "Dotted column with JSON nesting", params: map[string]string{"nested": "json", "tasks": "assignee.name"}},
+		{name: "Unknown nesting", params: map[string]string{"nested": "xml"}},
+		{name: "Unknown parameter", params: map[string]string{"goals": "gid"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewCSVStorage(t.TempDir(), tc.params); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestCSVColumns(t *testing.T) {
+	got := csvColumns(reflect.TypeOf(asana.Project{}), "", true)
+	want := []string{
+		"gid", "resource_type", "name", "archived", "color", "created_at", "modified_at",
+		"owner.gid", "owner.resource_type", "owner.name", "owner.email", "owner.workspaces",
+		"owner.photo.image_21x21", "owner.photo.image_27x27", "owner.photo.image_36x36",
+		"owner.photo.image_60x60", "owner.photo.image_128x128", "owner.photo.image_1024x1024",
+		"public",
+		"workspace.gid", "workspace.resource_type", "workspace.name",
+		"team.gid", "team.resource_type", "team.name",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("csvColumns() = %v, want %v", got, want)
+	}
+}
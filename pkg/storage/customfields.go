@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// CustomFieldOptionsFile is the file of the output root holding the enum
+// option lookup, keyed by custom field GID, then option GID. Its extension
+// follows the configured compression and encryption.
+const CustomFieldOptionsFile = "custom_field_options"
+
+// WriteCustomField writes a custom field definition, with its enum options,
+// to a JSON file
+func (s *JSONStorage) WriteCustomField(field asana.CustomField) error {
+	return s.writeEntity("custom_fields", field.GID, field)
+}
+
+// WriteCustomFieldOptions replaces the enum option lookup of the output
+// root with options
+func (s *JSONStorage) WriteCustomFieldOptions(options map[string]map[string]asana.EnumOption) error {
+	jsonData, err := json.MarshalIndent(options, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal custom field options: %w", err)
+	}
+	encoded, err := s.encode(jsonData)
+	if err != nil {
+		return err
+	}
+	if err := s.writeIfChanged(filepath.Join(s.baseDir, CustomFieldOptionsFile+s.extension()), encoded); err != nil {
+		return err
+	}
+	s.written["custom_fields"].Add(int64(len(encoded)))
+	return nil
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestWriteCustomFieldOptions(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enabled := false
+	field := asana.CustomField{GID: "f1", ResourceSubtype: "enum", EnumOptions: []asana.EnumOption{{GID: "o1", Name: "Low", Enabled: &enabled}}}
+	if err := s.WriteCustomField(field); err != nil {
+		t.Fatalf("WriteCustomField() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "custom_fields", "f1.json")); err != nil {
+		t.Errorf("custom field file not written: %v", err)
+	}
+
+	options := map[string]map[string]asana.EnumOption{"f1": {"o1": field.EnumOptions[0]}}
+	if err := s.WriteCustomFieldOptions(options); err != nil {
+		t.Fatalf("WriteCustomFieldOptions() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, CustomFieldOptionsFile+".json"))
+	if err != nil {
+		t.Fatalf("custom field options not written: %v", err)
+	}
+	var saved map[string]map[string]asana.EnumOption
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if o := saved["f1"]["o1"]; o.Name != "Low" || o.Enabled == nil || *o.Enabled {
+		t.Errorf("unexpected option in lookup: %+v", o)
+	}
+	if s.BytesWritten("custom_fields") == 0 {
+		t.Error("expected the lookup to count towards the custom fields bytes")
+	}
+}
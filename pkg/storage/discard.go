@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"io"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// Discard is a Backend that drops every entity. It backs dry runs, which
+// walk the API without writing anything.
+type Discard struct{}
+
+func init() {
+	Register("discard", func(Settings) (Backend, error) {
+		return Discard{}, nil
+	})
+}
+
+// WriteUser discards user
+func (Discard) WriteUser(asana.User) error { return nil }
+
+// WriteProject discards project
+func (Discard) WriteProject(asana.Project) error { return nil }
+
+// WriteTask discards task
+func (Discard) WriteTask(asana.Task) error { return nil }
+
+// WriteTeam discards team
+func (Discard) WriteTeam(asana.Team) error { return nil }
+
+// WriteUserTaskList discards list
+func (Discard) WriteUserTaskList(asana.UserTaskList) error { return nil }
+
+// AvatarCurrent reports every avatar as current, so dry runs download none
+func (Discard) AvatarCurrent(string, string) bool { return true }
+
+// WriteAvatar discards image
+func (Discard) WriteAvatar(string, string, []byte) error { return nil }
+
+// AttachmentStored reports every attachment as stored, so dry runs
+// download none
+func (Discard) AttachmentStored(string) bool { return true }
+
+// WriteAttachment discards the file
+func (Discard) WriteAttachment(asana.Attachment, io.Reader) error { return nil }
+
+// WriteAuditLogEvents discards events
+func (Discard) WriteAuditLogEvents(string, []asana.AuditLogEvent) error { return nil }
+
+// WriteStatusUpdate discards update
+func (Discard) WriteStatusUpdate(asana.StatusUpdate) error { return nil }
+
+// WriteCustomField discards field
+func (Discard) WriteCustomField(asana.CustomField) error { return nil }
+
+// WriteCustomFieldOptions discards options
+func (Discard) WriteCustomFieldOptions(map[string]map[string]asana.EnumOption) error { return nil }
@@ -0,0 +1,284 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// DefaultBulkSize is how many documents the elasticsearch backend sends
+// per bulk request unless bulk_size is set
+const DefaultBulkSize = 500
+
+// defaultMappings are the index mappings used when mappings_dir has no
+// file for a resource: names and notes are analyzed for full-text search,
+// identifiers are exact keywords
+var defaultMappings = map[string]string{
+	"tasks": `{"properties":{
+		"gid":{"type":"keyword"},"resource_type":{"type":"keyword"},"run_id":{"type":"keyword"},
+		"name":{"type":"text","fields":{"keyword":{"type":"keyword","ignore_above":256}}},
+		"notes":{"type":"text"},
+		"completed":{"type":"boolean"},"completed_at":{"type":"date"},
+		"created_at":{"type":"date"},"modified_at":{"type":"date"},"due_on":{"type":"date"},
+		"assignee":{"properties":{"gid":{"type":"keyword"},"name":{"type":"text"},"email":{"type":"keyword"}}},
+		"projects":{"properties":{"gid":{"type":"keyword"},"name":{"type":"text"}}}}}`,
+	"projects": `{"properties":{
+		"gid":{"type":"keyword"},"resource_type":{"type":"keyword"},"run_id":{"type":"keyword"},
+		"name":{"type":"text","fields":{"keyword":{"type":"keyword","ignore_above":256}}},
+		"archived":{"type":"boolean"},"public":{"type":"boolean"},"color":{"type":"keyword"},
+		"created_at":{"type":"date"},"modified_at":{"type":"date"},
+		"owner":{"properties":{"gid":{"type":"keyword"},"name":{"type":"text"}}},
+		"team":{"properties":{"gid":{"type":"keyword"},"name":{"type":"text"}}}}}`,
+}
+
+// ElasticsearchStorage bulk-indexes tasks and projects into Elasticsearch
+// or OpenSearch for full-text search, one index per resource, with the GID
+// as document ID so every run updates documents in place, stamping them
+// with its run_id. Users and teams are not indexed.
+type ElasticsearchStorage struct {
+	runStamp
+	url        string
+	apiKey     string
+	prefix     string
+	bulkSize   int
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	pending bytes.Buffer
+	count   int
+}
+
+func init() {
+	Register("elasticsearch", func(s Settings) (Backend, error) {
+		return NewElasticsearchStorage(s.Params)
+	})
+}
+
+// NewElasticsearchStorage creates the backend from its STORAGE_PARAMS:
+//
+//   - url: the cluster, with credentials for basic auth if needed (required)
+//   - api_key: an Elasticsearch API key, sent instead of basic auth
+//   - index_prefix: prefix of the index names (default "asana-")
+//   - mappings_dir: directory of <resource>.json files replacing the
+//     default mappings of the tasks and projects indices
+//   - bulk_size: documents per bulk request (default DefaultBulkSize)
+//
+// Missing indices are created with their mappings; existing ones are used
+// as they are.
+func NewElasticsearchStorage(params map[string]string) (*ElasticsearchStorage, error) {
+	for key := range params {
+		switch key {
+		case "url", "api_key", "index_prefix", "mappings_dir", "bulk_size":
+		default:
+			return nil, fmt.Errorf("unknown elasticsearch parameter %q", key)
+		}
+	}
+	if params["url"] == "" {
+		return nil, fmt.Errorf("the elasticsearch backend requires url in STORAGE_PARAMS")
+	}
+
+	s := &ElasticsearchStorage{
+		url:        strings.TrimSuffix(params["url"], "/"),
+		apiKey:     params["api_key"],
+		prefix:     "asana-",
+		bulkSize:   DefaultBulkSize,
+		httpClient: &http.Client{Timeout: time.Minute},
+	}
+	if prefix, ok := params["index_prefix"]; ok {
+		s.prefix = prefix
+	}
+	if size := params["bulk_size"]; size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("elasticsearch bulk_size must be a positive integer (got %q)", size)
+		}
+		s.bulkSize = n
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	for _, resource := range []string{"tasks", "projects"} {
+		mappings := defaultMappings[resource]
+		if dir := params["mappings_dir"]; dir != "" {
+			data, err := os.ReadFile(filepath.Join(dir, resource+".json"))
+			if err == nil {
+				mappings = string(data)
+			} else if !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to read %s mappings: %w", resource, err)
+			}
+		}
+		if err := s.ensureIndex(ctx, s.prefix+resource, mappings); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// WriteUser does nothing; users are not indexed
+func (s *ElasticsearchStorage) WriteUser(user asana.User) error {
+	return nil
+}
+
+// WriteProject queues project for the projects index
+func (s *ElasticsearchStorage) WriteProject(project asana.Project) error {
+	return s.index("projects", project.GID, project)
+}
+
+// WriteTask queues task for the tasks index
+func (s *ElasticsearchStorage) WriteTask(task asana.Task) error {
+	return s.index("tasks", task.GID, task)
+}
+
+// WriteTeam does nothing; teams are not indexed
+func (s *ElasticsearchStorage) WriteTeam(team asana.Team) error {
+	return nil
+}
+
+// WriteManifest sends the documents still queued at the end of a run
+func (s *ElasticsearchStorage) WriteManifest(manifest any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flush(context.Background())
+}
+
+// index queues v as document gid of resource's index, sending the queue
+// once it holds bulkSize documents
+func (s *ElasticsearchStorage) index(resource, gid string, v any) error {
+	doc, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", resource, err)
+	}
+	if runID := s.runID(); runID != "" {
+		doc = withRunID(doc, runID)
+	}
+	action, err := json.Marshal(map[string]any{"index": map[string]string{"_index": s.prefix + resource, "_id": gid}})
+	if err != nil {
+		return fmt.Errorf("failed to marshal bulk action: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending.Write(action)
+	s.pending.WriteByte('\n')
+	s.pending.Write(doc)
+	s.pending.WriteByte('\n')
+	s.count++
+	if s.count < s.bulkSize {
+		return nil
+	}
+	return s.flush(context.Background())
+}
+
+// flush sends the queued documents as one bulk request. It must be called
+// with mu held. The queue is cleared even if the request fails, so a
+// rejected batch is reported once rather than on every later write.
+func (s *ElasticsearchStorage) flush(ctx context.Context) error {
+	if s.count == 0 {
+		return nil
+	}
+	body := bytes.NewReader(s.pending.Bytes())
+	count := s.count
+	defer func() {
+		s.pending.Reset()
+		s.count = 0
+	}()
+
+	resp, err := s.do(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", body)
+	if err != nil {
+		return fmt.Errorf("failed to bulk index %d documents: %w", count, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to bulk index %d documents: %s", count, responseError(resp))
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID    string          `json:"_id"`
+			Error json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode bulk response: %w", err)
+	}
+	if !result.Errors {
+		return nil
+	}
+
+	failed := 0
+	var first string
+	for _, item := range result.Items {
+		for _, op := range item {
+			if len(op.Error) > 0 && string(op.Error) != "null" {
+				if failed == 0 {
+					first = fmt.Sprintf("document %s: %s", op.ID, op.Error)
+				}
+				failed++
+			}
+		}
+	}
+	return fmt.Errorf("bulk indexing rejected %d of %d documents, first %s", failed, count, first)
+}
+
+// ensureIndex creates index with mappings unless it exists
+func (s *ElasticsearchStorage) ensureIndex(ctx context.Context, index, mappings string) error {
+	resp, err := s.do(ctx, http.MethodHead, "/"+index, "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to check index %s: %w", index, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to check index %s: status %d", index, resp.StatusCode)
+	}
+
+	body := `{"mappings":` + mappings + `}`
+	if !json.Valid([]byte(body)) {
+		return fmt.Errorf("mappings of index %s are not valid JSON", index)
+	}
+	resp, err = s.do(ctx, http.MethodPut, "/"+index, "application/json", strings.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create index %s: %w", index, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to create index %s: %s", index, responseError(resp))
+	}
+	return nil
+}
+
+// do sends a request to the cluster
+func (s *ElasticsearchStorage) do(ctx context.Context, method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.url+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+s.apiKey)
+	}
+	return s.httpClient.Do(req)
+}
+
+// responseError describes a failed response by its status and body
+func responseError(resp *http.Response) string {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+	return fmt.Sprintf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+}
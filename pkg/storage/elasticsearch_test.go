@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// fakeCluster is an Elasticsearch stand-in recording created indices and
+// bulk-indexed documents
+type fakeCluster struct {
+	mu       sync.Mutex
+	existing map[string]bool
+	created  map[string]string
+	docs     map[string]map[string]json.RawMessage
+	bulks    int
+	auth     string
+	reject   string
+}
+
+func newFakeCluster(t *testing.T, existing ...string) (*fakeCluster, *httptest.Server) {
+	c := &fakeCluster{existing: make(map[string]bool), created: make(map[string]string), docs: make(map[string]map[string]json.RawMessage)}
+	for _, index := range existing {
+		c.existing[index] = true
+	}
+	srv := httptest.NewServer(http.HandlerFunc(c.serve))
+	t.Cleanup(srv.Close)
+	return c, srv
+}
+
+func (c *fakeCluster) serve(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.auth = r.Header.Get("Authorization")
+	index := strings.TrimPrefix(r.URL.Path, "/")
+
+	switch {
+	case r.Method == http.MethodHead:
+		if !c.existing[index] {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case r.Method == http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		c.created[index] = string(body)
+		c.existing[index] = true
+	case r.URL.Path == "/_bulk":
+		c.bulks++
+		type item struct {
+			ID    string `json:"_id"`
+			Error any    `json:"error,omitempty"`
+		}
+		var items []map[string]item
+		failed := false
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var action struct {
+				Index struct {
+					Index string `json:"_index"`
+					ID    string `json:"_id"`
+				} `json:"index"`
+			}
+			json.Unmarshal(scanner.Bytes(), &action)
+			scanner.Scan()
+			it := item{ID: action.Index.ID}
+			if it.ID == c.reject {
+				it.Error = map[string]string{"type": "mapper_parsing_exception"}
+				failed = true
+			} else {
+				if c.docs[action.Index.Index] == nil {
+					c.docs[action.Index.Index] = make(map[string]json.RawMessage)
+				}
+				c.docs[action.Index.Index][it.ID] = append(json.RawMessage(nil), scanner.Bytes()...)
+			}
+			items = append(items, map[string]item{"index": it})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"errors": failed, "items": items})
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestElasticsearchStorage(t *testing.T) {
+	cluster, srv := newFakeCluster(t, "asana-projects")
+	s, err := NewElasticsearchStorage(map[string]string{"url": srv.URL, "bulk_size": "2", "api_key": "k3y"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := cluster.created["asana-tasks"]; !ok {
+		t.Error("Expected the tasks index to be created")
+	}
+	if _, ok := cluster.created["asana-projects"]; ok {
+		t.Error("Expected the existing projects index to be left alone")
+	}
+
+	s.WriteTask(asana.Task{GID: "t1", Name: "Plan the offsite"})
+	s.WriteUser(asana.User{GID: "u1"})
+	if cluster.bulks != 0 {
+		t.Error("Expected documents to be queued until the bulk size is reached")
+	}
+	s.WriteTask(asana.Task{GID: "t2"})
+	if cluster.bulks != 1 || len(cluster.docs["asana-tasks"]) != 2 {
+		t.Errorf("Expected one bulk request with both tasks, got %d requests and %v", cluster.bulks, cluster.docs)
+	}
+
+	s.WriteProject(asana.Project{GID: "p1"})
+	if err := s.WriteManifest(map[string]any{"status": "succeeded"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cluster.docs["asana-projects"]["p1"]; !ok {
+		t.Error("Expected the manifest to flush the queued project")
+	}
+	if !strings.Contains(string(cluster.docs["asana-tasks"]["t1"]), "Plan the offsite") {
+		t.Errorf("Unexpected task document %s", cluster.docs["asana-tasks"]["t1"])
+	}
+	if cluster.auth != "ApiKey k3y" {
+		t.Errorf("Authorization = %q, want the API key", cluster.auth)
+	}
+}
+
+func TestElasticsearchStorage_Rejected(t *testing.T) {
+	cluster, srv := newFakeCluster(t)
+	cluster.reject = "t2"
+	s, err := NewElasticsearchStorage(map[string]string{"url": srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.WriteTask(asana.Task{GID: "t1"})
+	s.WriteTask(asana.Task{GID: "t2"})
+	err = s.WriteManifest(nil)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2") || !strings.Contains(err.Error(), "t2") {
+		t.Errorf("Expected the rejected document to be reported, got %v", err)
+	}
+	if err := s.WriteManifest(nil); err != nil {
+		t.Errorf("Expected the failed batch to be dropped, got %v", err)
+	}
+}
+
+func TestNewElasticsearchStorage_Mappings(t *testing.T) {
+	cluster, srv := newFakeCluster(t)
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "tasks.json"), []byte(`{"properties":{"notes":{"type":"text","analyzer":"english"}}}`), 0644)
+
+	if _, err := NewElasticsearchStorage(map[string]string{"url": srv.URL, "mappings_dir": dir, "index_prefix": "acme-"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(cluster.created["acme-tasks"], `"analyzer":"english"`) {
+		t.Errorf("Expected the custom tasks mappings, got %s", cluster.created["acme-tasks"])
+	}
+	if !strings.Contains(cluster.created["acme-projects"], `"owner"`) {
+		t.Errorf("Expected the default projects mappings, got %s", cluster.created["acme-projects"])
+	}
+
+	os.WriteFile(filepath.Join(dir, "projects.json"), []byte(`{"properties":`), 0644)
+	if _, err := NewElasticsearchStorage(map[string]string{"url": srv.URL, "mappings_dir": dir, "index_prefix": "bad-"}); err == nil {
+		t.Error("Expected an error for invalid mappings")
+	}
+}
+
+func TestNewElasticsearchStorage_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]string
+	}{
+		{name: "No URL", params: nil},
+		{name: "Unknown parameter", params: map[string]string{"url": "http://localhost:9200", "shards": "3"}},
+		{name: "Bad bulk size", params: map[string]string{"url": "http://localhost:9200", "bulk_size": "0"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewElasticsearchStorage(tc.params); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
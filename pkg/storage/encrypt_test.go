@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantLen int
+		wantErr bool
+	}{
+		{name: "AES-256", input: base64.StdEncoding.EncodeToString(make([]byte, 32)), wantLen: 32},
+		{name: "AES-128 with newline", input: base64.StdEncoding.EncodeToString(make([]byte, 16)) + "\n", wantLen: 16},
+		{name: "Wrong length", input: base64.StdEncoding.EncodeToString(make([]byte, 10)), wantErr: true},
+		{name: "Not base64", input: "not-a-key!", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseKey(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(key) != tt.wantLen {
+				t.Errorf("expected %d byte key, got %d", tt.wantLen, len(key))
+			}
+		})
+	}
+}
+
+func TestEncryptedWrites(t *testing.T) {
+	tests := []struct {
+		name        string
+		compression Compression
+		wantFile    string
+	}{
+		{name: "Plain JSON", compression: CompressionNone, wantFile: "u1.json.enc"},
+		{name: "Gzip", compression: CompressionGzip, wantFile: "u1.json.gz.enc"},
+	}
+
+	key := bytes.Repeat([]byte{7}, 32)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			storage, err := NewJSONStorageWithOptions(tmpDir, Options{Compression: tt.compression, EncryptionKey: key})
+			if err != nil {
+				t.Fatalf("NewJSONStorageWithOptions() failed: %v", err)
+			}
+
+			if err := storage.WriteUser(asana.User{GID: "u1", Name: "Alice"}); err != nil {
+				t.Fatalf("WriteUser() failed: %v", err)
+			}
+
+			path := filepath.Join(tmpDir, "users", tt.wantFile)
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("expected %s: %v", tt.wantFile, err)
+			}
+			if bytes.Contains(raw, []byte("Alice")) {
+				t.Error("file contains plaintext")
+			}
+
+			plain, err := storage.readEntityFile(path)
+			if err != nil || !bytes.Contains(plain, []byte("Alice")) {
+				t.Errorf("round trip failed: %v, %s", err, plain)
+			}
+
+			// Change detection compares plaintext despite random nonces
+			storage.WriteUser(asana.User{GID: "u1", Name: "Alice"})
+			if storage.Unchanged() != 1 {
+				t.Error("expected identical encrypted write to be skipped")
+			}
+
+			// A different key cannot read the file
+			other, _ := NewJSONStorageWithOptions(t.TempDir(), Options{Compression: tt.compression, EncryptionKey: bytes.Repeat([]byte{8}, 32)})
+			if _, err := other.readEntityFile(path); err == nil {
+				t.Error("expected decryption with the wrong key to fail")
+			}
+		})
+	}
+}
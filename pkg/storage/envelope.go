@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SchemaVersion is stamped in every envelope. It is bumped when a stored
+// field is renamed, removed or changes type; new fields keep the version.
+const SchemaVersion = 1
+
+// Envelope wraps an entity file when Options.Envelope is set, so consumers
+// can tell which schema and workspace the record came from
+type Envelope struct {
+	SchemaVersion int `json:"schema_version"`
+	// ExtractedAt is when the file was last written, that is when the
+	// entity was last seen changed
+	ExtractedAt  time.Time       `json:"extracted_at"`
+	WorkspaceGID string          `json:"workspace_gid,omitempty"`
+	Data         json.RawMessage `json:"data"`
+}
+
+// wrap returns the entity payload in an envelope, or as is when envelopes
+// are disabled
+func (s *JSONStorage) wrap(payload []byte) ([]byte, error) {
+	if !s.envelope {
+		return payload, nil
+	}
+	data, err := json.MarshalIndent(Envelope{
+		SchemaVersion: SchemaVersion,
+		ExtractedAt:   time.Now().UTC(),
+		WorkspaceGID:  s.workspaceGID,
+		Data:          payload,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+	return data, nil
+}
+
+// unwrap returns the entity payload of a file and whether it was written in
+// an envelope, so outputs mixing both stay readable
+func unwrap(data []byte) ([]byte, bool) {
+	var envelope struct {
+		SchemaVersion int             `json:"schema_version"`
+		Data          json.RawMessage `json:"data"`
+	}
+	if json.Unmarshal(data, &envelope) == nil && envelope.SchemaVersion > 0 && len(envelope.Data) > 0 {
+		return envelope.Data, true
+	}
+	return data, false
+}
+
+// samePayload reports whether two entity payloads hold the same JSON,
+// whatever their indentation
+func samePayload(a, b []byte) bool {
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return false
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
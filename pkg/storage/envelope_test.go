@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestEnvelope(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewJSONStorageWithOptions(dir, Options{Envelope: true, WorkspaceGID: "w1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task := asana.Task{GID: "t1", Name: "Ship v2"}
+	if err := s.WriteTask(task); err != nil {
+		t.Fatalf("WriteTask() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "tasks", "t1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var envelope Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		t.Fatalf("invalid envelope: %v", err)
+	}
+	if envelope.SchemaVersion != SchemaVersion || envelope.WorkspaceGID != "w1" || envelope.ExtractedAt.IsZero() {
+		t.Errorf("unexpected envelope: %+v", envelope)
+	}
+
+	got, err := s.ReadTask("t1")
+	if err != nil || got.Name != "Ship v2" {
+		t.Errorf("ReadTask() = %+v, %v", got, err)
+	}
+
+	// The timestamp alone does not make the entity changed
+	if err := s.WriteTask(task); err != nil {
+		t.Fatal(err)
+	}
+	if s.Unchanged() != 1 {
+		t.Errorf("expected the rewrite to be skipped, got %d unchanged", s.Unchanged())
+	}
+}
+
+func TestEnvelope_Switch(t *testing.T) {
+	dir := t.TempDir()
+	plain, err := NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := asana.Task{GID: "t1", Name: "Ship v2"}
+	if err := plain.WriteTask(task); err != nil {
+		t.Fatal(err)
+	}
+
+	wrapped, err := NewJSONStorageWithOptions(dir, Options{Envelope: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := wrapped.ReadTask("t1"); err != nil || got.Name != "Ship v2" {
+		t.Errorf("expected a plain file to be readable, got %+v, %v", got, err)
+	}
+	if err := wrapped.WriteTask(task); err != nil {
+		t.Fatal(err)
+	}
+	if wrapped.Unchanged() != 0 {
+		t.Error("expected a plain file to be rewritten in an envelope")
+	}
+	if _, err := wrapped.Reconcile("tasks", map[string]struct{}{}, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plain.ReadTask("t1"); !errors.Is(err, ErrDeleted) {
+		t.Errorf("expected an enveloped tombstone to read as deleted, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// HistoryFile is the append-only log of runs kept in the output root
+const HistoryFile = "runs.jsonl"
+
+// historyMu serializes appends from concurrent runs within the process
+var historyMu sync.Mutex
+
+// AppendRun appends a run record as one JSON line to runs.jsonl
+func (s *JSONStorage) AppendRun(record any) error {
+	return appendJSONLine(filepath.Join(s.baseDir, HistoryFile), record)
+}
+
+// AppendRun records the run in the output root rather than the snapshot, so
+// the history spans every snapshot
+func (s *Snapshot) AppendRun(record any) error {
+	return appendJSONLine(filepath.Join(s.rootDir, HistoryFile), record)
+}
+
+// appendJSONLine marshals record onto a single line and appends it to filename
+func appendJSONLine(filename string, record any) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	line = append(line, '\n')
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to append to history file: %w", err)
+	}
+	return f.Close()
+}
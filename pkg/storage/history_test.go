@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppendRun(t *testing.T) {
+	rootDir := t.TempDir()
+	plain, _ := NewJSONStorage(rootDir)
+	snap, err := NewSnapshot(rootDir, time.Now(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		recorder interface{ AppendRun(any) error }
+		runID    string
+	}{
+		{name: "Plain storage", recorder: plain, runID: "r1"},
+		{name: "Snapshot records in the root", recorder: snap, runID: "r2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.recorder.AppendRun(map[string]string{"run_id": tt.runID}); err != nil {
+				t.Fatalf("AppendRun() failed: %v", err)
+			}
+		})
+	}
+
+	f, err := os.Open(filepath.Join(rootDir, HistoryFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var runIDs []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record map[string]string
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+		}
+		runIDs = append(runIDs, record["run_id"])
+	}
+
+	if len(runIDs) != 2 || runIDs[0] != "r1" || runIDs[1] != "r2" {
+		t.Errorf("expected runs [r1 r2] in order, got %v", runIDs)
+	}
+	if _, err := os.Stat(filepath.Join(snap.Dir(), HistoryFile)); !os.IsNotExist(err) {
+		t.Error("snapshot must not keep its own history file")
+	}
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// IndexFile is written to every entity directory after each run. It lists
+// the entities stored there, so consumers can discover and diff them
+// without listing and opening every file. It is plain JSON whatever the
+// compression and encryption of the entity files.
+const IndexFile = "index.json"
+
+// indexedResources are the directories of the output root that get an
+// IndexFile
+var indexedResources = []string{"users", "projects", "tasks", "teams", "user_task_lists", "status_updates", "custom_fields"}
+
+// Index is the content of an IndexFile
+type Index struct {
+	// RunID is the run after which the index was generated
+	RunID       string    `json:"run_id,omitempty"`
+	GeneratedAt time.Time `json:"generated_at"`
+	// Entities maps the GID of every stored entity, tombstones included,
+	// to its file
+	Entities map[string]IndexEntry `json:"entities"`
+}
+
+// IndexEntry describes the file of one entity. SHA256 is the hash of the
+// file as stored, so it changes exactly when the file does.
+type IndexEntry struct {
+	File       string    `json:"file"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+	SHA256     string    `json:"sha256"`
+}
+
+// ReadIndex returns the index of resource's directory. It returns
+// ErrNotFound before the first run.
+func (s *JSONStorage) ReadIndex(resource string) (Index, error) {
+	var index Index
+	data, err := os.ReadFile(filepath.Join(s.baseDir, resource, IndexFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return index, fmt.Errorf("%s index: %w", resource, ErrNotFound)
+	}
+	if err != nil {
+		return index, fmt.Errorf("failed to read %s index: %w", resource, err)
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return index, fmt.Errorf("failed to unmarshal %s index: %w", resource, err)
+	}
+	return index, nil
+}
+
+// writeIndexes regenerates the index of every entity directory
+func (s *JSONStorage) writeIndexes(runID string) error {
+	now := time.Now().UTC()
+	for _, resource := range indexedResources {
+		if _, err := os.Stat(filepath.Join(s.baseDir, resource)); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		index, err := s.buildIndex(resource)
+		if err != nil {
+			return err
+		}
+		index.RunID, index.GeneratedAt = runID, now
+
+		data, err := json.MarshalIndent(index, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s index: %w", resource, err)
+		}
+		if err := s.replaceFile(filepath.Join(s.baseDir, resource, IndexFile), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildIndex lists the entity files of resource, in subdirectories too
+// for a partitioned layout. Files whose size and modification time match
+// the previous index keep its hash; only new and rewritten files are read.
+func (s *JSONStorage) buildIndex(resource string) (Index, error) {
+	prev, err := s.ReadIndex(resource)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		// A corrupt index is rebuilt from scratch
+		prev = Index{}
+	}
+
+	dir := filepath.Join(s.baseDir, resource)
+	index := Index{Entities: make(map[string]IndexEntry)}
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		gid, ok := strings.CutSuffix(entry.Name(), s.extension())
+		if !ok || entry.IsDir() || path == filepath.Join(dir, IndexFile) {
+			return nil
+		}
+		info, err := entry.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to stat %s %s: %w", resource, gid, err)
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		e := IndexEntry{File: filepath.ToSlash(rel), Size: info.Size(), ModifiedAt: info.ModTime().UTC()}
+		if old, ok := prev.Entities[gid]; ok && old.File == e.File && old.Size == e.Size && old.ModifiedAt.Equal(e.ModifiedAt) {
+			e.SHA256 = old.SHA256
+		} else if e.SHA256, err = fileHash(path); err != nil {
+			return fmt.Errorf("failed to hash %s %s: %w", resource, gid, err)
+		}
+		index.Entities[gid] = e
+		return nil
+	})
+	if err != nil {
+		return Index{}, fmt.Errorf("failed to list %s: %w", resource, err)
+	}
+	return index, nil
+}
+
+// fileHash returns the hex SHA-256 of a file's content
+func fileHash(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestIndex(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ReadIndex("users"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound before the first run, got %v", err)
+	}
+
+	s.WriteUser(asana.User{GID: "u1", Name: "Ada"})
+	s.WriteUser(asana.User{GID: "u2", Name: "Bob"})
+	if err := s.WriteManifest(map[string]any{"run_id": "r1", "status": "succeeded"}); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := s.ReadIndex("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.RunID != "r1" || len(index.Entities) != 2 {
+		t.Fatalf("Unexpected index %+v", index)
+	}
+	u1 := index.Entities["u1"]
+	hash, _ := fileHash(filepath.Join(dir, "users", "u1.json"))
+	if u1.File != "u1.json" || u1.SHA256 != hash || u1.Size == 0 || u1.ModifiedAt.IsZero() {
+		t.Errorf("Unexpected entry %+v", u1)
+	}
+	if gids, _ := s.ListGIDs("users"); !reflect.DeepEqual(gids, []string{"u1", "u2"}) {
+		t.Errorf("Expected the index to be left out of the GIDs, got %v", gids)
+	}
+
+	// A rewritten file is hashed afresh and a deleted one dropped
+	s.WriteUser(asana.User{GID: "u2", Name: "Bob Jr"})
+	s.Reconcile("users", map[string]struct{}{"u2": {}}, false)
+	if err := s.WriteManifest(map[string]any{"run_id": "r2", "status": "succeeded"}); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err = s.ReadIndex("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, _ = fileHash(filepath.Join(dir, "users", "u2.json"))
+	if _, ok := index.Entities["u1"]; ok {
+		t.Error("Expected the deleted u1 to leave the index")
+	}
+	if index.RunID != "r2" || index.Entities["u2"].SHA256 != hash {
+		t.Errorf("Expected the rewritten u2 to be hashed again, got %+v", index)
+	}
+}
+
+func TestIndex_Reuse(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.WriteTask(asana.Task{GID: "t1"})
+	if err := s.WriteManifest(map[string]any{"run_id": "r1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unchanged files keep the hash of the previous index without being
+	// read again
+	index, _ := s.ReadIndex("tasks")
+	entry := index.Entities["t1"]
+	entry.SHA256 = "kept"
+	index.Entities["t1"] = entry
+	data, _ := json.Marshal(index)
+	os.WriteFile(filepath.Join(dir, "tasks", IndexFile), data, 0644)
+
+	if err := s.WriteManifest(map[string]any{"run_id": "r2"}); err != nil {
+		t.Fatal(err)
+	}
+	if index, _ = s.ReadIndex("tasks"); index.Entities["t1"].SHA256 != "kept" {
+		t.Errorf("Expected the unchanged t1 to keep its indexed hash, got %+v", index.Entities["t1"])
+	}
+}
@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// JournalFile is the file of the output root a run records every file it is
+// about to replace or delete in, before touching it. While it exists the
+// output is a mix of the previous run and one in progress or crashed; it
+// is removed once the run's manifest is written.
+const JournalFile = "journal.jsonl"
+
+// Journal operations
+const (
+	// JournalBegin opens the journal of a run
+	JournalBegin = "begin"
+	// JournalPut precedes replacing a file with a new version
+	JournalPut = "put"
+	// JournalDelete precedes deleting a file
+	JournalDelete = "delete"
+	// JournalRecover marks where a run found the journal of a crashed one
+	JournalRecover = "recover"
+)
+
+// JournalEntry is one record of the journal. Path is relative to the
+// output root.
+type JournalEntry struct {
+	Op   string    `json:"op"`
+	Path string    `json:"path,omitempty"`
+	Time time.Time `json:"time"`
+}
+
+// journal appends intents to JournalFile, creating it on the first one
+type journal struct {
+	mu      sync.Mutex
+	baseDir string
+	f       *os.File
+}
+
+// openJournal prepares the journal of baseDir. A journal left by a crashed
+// run is recovered: the temporary files of the writes it interrupted are
+// removed, and the journal is kept, so the output stays marked incomplete
+// until a run writes its manifest.
+func openJournal(baseDir string) (*journal, error) {
+	j := &journal{baseDir: baseDir}
+	entries, err := ReadJournal(baseDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if e.Op != JournalPut {
+			continue
+		}
+		tmps, err := filepath.Glob(filepath.Join(baseDir, e.Path) + tempPattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find interrupted writes of %s: %w", e.Path, err)
+		}
+		for _, tmp := range tmps {
+			if err := os.Remove(tmp); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("failed to remove interrupted write %s: %w", tmp, err)
+			}
+		}
+	}
+	if err := j.intent(JournalRecover, ""); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// ReadJournal returns the entries of the journal in baseDir, or an error
+// wrapping os.ErrNotExist when no run is modifying the output. A line cut
+// short by a crash is ignored.
+func ReadJournal(baseDir string) ([]JournalEntry, error) {
+	f, err := os.Open(filepath.Join(baseDir, JournalFile))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return entries, nil
+}
+
+// intent records that filename, or a file relative to the output root, is
+// about to be replaced or deleted. It is a no-op on a nil journal.
+func (j *journal) intent(op, filename string) error {
+	if j == nil {
+		return nil
+	}
+	entry := JournalEntry{Op: op, Time: time.Now().UTC()}
+	if filename != "" {
+		rel, err := filepath.Rel(j.baseDir, filename)
+		if err != nil {
+			rel = filename
+		}
+		entry.Path = filepath.ToSlash(rel)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.f == nil {
+		if err := j.begin(); err != nil {
+			return err
+		}
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal entry: %w", err)
+	}
+	if _, err := j.f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// begin opens the journal and syncs its begin record, with the directory
+// entry, so the output is marked incomplete before any file changes even
+// if power is lost. It must be called with mu held.
+func (j *journal) begin() error {
+	path := filepath.Join(j.baseDir, JournalFile)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	line, err := json.Marshal(JournalEntry{Op: JournalBegin, Time: time.Now().UTC()})
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to marshal journal entry: %w", err)
+	}
+	// Start on a new line after a record cut short by a crash
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			line = append([]byte{'\n'}, line...)
+		}
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync journal: %w", err)
+	}
+	if err := syncDir(j.baseDir); err != nil {
+		f.Close()
+		return err
+	}
+	j.f = f
+	return nil
+}
+
+// close removes the journal, marking the output complete. It is a no-op
+// on a nil journal or before the first intent.
+func (j *journal) close() error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.f == nil {
+		return nil
+	}
+	if err := j.f.Close(); err != nil {
+		return fmt.Errorf("failed to close journal: %w", err)
+	}
+	j.f = nil
+	if err := os.Remove(filepath.Join(j.baseDir, JournalFile)); err != nil {
+		return fmt.Errorf("failed to remove journal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// journalOps returns the operation and path of every journal entry
+func journalOps(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := ReadJournal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ops []string
+	for _, e := range entries {
+		ops = append(ops, e.Op+" "+e.Path)
+	}
+	return ops
+}
+
+func TestJournal(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Incomplete() {
+		t.Fatal("Expected fresh output to be complete")
+	}
+
+	if err := s.WriteUser(asana.User{GID: "u1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteUser(asana.User{GID: "u1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Reconcile("users", map[string]struct{}{}, false); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Incomplete() {
+		t.Error("Expected the output to be incomplete during a run")
+	}
+	want := []string{"begin ", "put users/u1.json", "delete users/u1.json"}
+	if got := journalOps(t, dir); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Journal = %q, want %q (unchanged writes are not journaled)", got, want)
+	}
+
+	if err := s.WriteManifest(map[string]any{"status": "failed"}); err != nil {
+		t.Fatal(err)
+	}
+	if s.Incomplete() {
+		t.Error("Expected the manifest to end the journal")
+	}
+
+	// The next run starts a new journal
+	if err := s.WriteTeam(asana.Team{GID: "team1"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := journalOps(t, dir); len(got) != 2 || got[1] != "put teams/team1.json" {
+		t.Errorf("Expected a fresh journal, got %q", got)
+	}
+}
+
+func TestJournal_Recover(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteUser(asana.User{GID: "u1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Crash between journaling u2 and renaming its file into place, with
+	// the last journal line cut short
+	if err := s.journal.intent(JournalPut, filepath.Join(dir, "users", "u2.json")); err != nil {
+		t.Fatal(err)
+	}
+	tmp := filepath.Join(dir, "users", "u2.json.123456.tmp")
+	if err := os.WriteFile(tmp, []byte(`{"gid":`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s.journal.f.WriteString(`{"op":"pu`)
+	s.journal.f.Close()
+
+	s, err = NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(tmp); !errors.Is(err, os.ErrNotExist) {
+		t.Error("Expected the interrupted write to be cleaned up")
+	}
+	if !s.Incomplete() {
+		t.Error("Expected recovered output to stay incomplete until a manifest is written")
+	}
+	want := []string{"begin ", "put users/u1.json", "put users/u2.json", "begin ", "recover "}
+	if got := journalOps(t, dir); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Journal = %q, want %q", got, want)
+	}
+
+	if err := s.WriteManifest(map[string]any{"status": "succeeded"}); err != nil {
+		t.Fatal(err)
+	}
+	if s.Incomplete() {
+		t.Error("Expected the manifest to end the recovered journal")
+	}
+}
@@ -1,21 +1,124 @@
 package storage
 
 import (
+	"crypto/cipher"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 
 	"github.com/ioanzicu/asana-extractor/pkg/asana"
 )
 
-// JSONStorage implements Storage by writing individual JSON files
+// JSONStorage implements Storage by writing individual JSON files.
+// Entities whose serialized payload matches what is already on disk are not
+// rewritten, so unchanged files keep their modification time.
 type JSONStorage struct {
-	baseDir string
+	baseDir     string
+	compression Compression
+	aead        cipher.AEAD
+	unchanged   atomic.Int64
+	// written counts bytes written per resource directory; the map itself
+	// is never modified after construction
+	written map[string]*atomic.Int64
+	// changes records created, updated and deleted entities when enabled
+	changes *changeLog
+	// journal records the files a run is about to change; nil for storage
+	// opened for reading
+	journal *journal
+	durable bool
+	// layouts holds the resources with a partitioned layout
+	layouts map[string]*layout
+	// attachments is the reference map of AttachmentsDir
+	attachments attachmentRefs
+	// envelope wraps entity files in an Envelope stamped with workspaceGID
+	envelope     bool
+	workspaceGID string
+}
+
+// Options holds optional JSONStorage settings. Both apply to entity files
+// only; manifests stay plain JSON.
+type Options struct {
+	Compression Compression
+	// EncryptionKey enables AES-GCM encryption of entity files, applied
+	// after compression. See ParseKey.
+	EncryptionKey []byte
+	// ChangeLog records every created, updated and deleted entity in
+	// changes/<run_id>.jsonl. Payloads are plain JSON, so it cannot be
+	// combined with encryption.
+	ChangeLog bool
+	// Layout maps resources to templates placing their entity files in
+	// subdirectories, such as "dt={date}/{gid}"; see ParseLayout. Others
+	// keep one flat directory.
+	Layout map[string]string
+	// DurableWrites syncs every file to disk before renaming it into place,
+	// and its directory after, so a write that returned survives a power
+	// loss. It applies to the json, csv and avro backends.
+	DurableWrites bool
+	// Envelope wraps every entity file, tombstones included, in an
+	// Envelope carrying SchemaVersion, the write time and WorkspaceGID.
+	// Readers accept files with and without one.
+	Envelope     bool
+	WorkspaceGID string
 }
 
 // NewJSONStorage creates a new JSON storage instance
 func NewJSONStorage(baseDir string) (*JSONStorage, error) {
+	return NewJSONStorageWithOptions(baseDir, Options{})
+}
+
+// OpenJSONStorage opens an existing output directory for reading, with the
+// options it was written with. Unlike NewJSONStorageWithOptions it creates
+// nothing, so it is safe to point at a snapshot's latest link.
+func OpenJSONStorage(baseDir string, opts Options) (*JSONStorage, error) {
+	compression, err := ParseCompression(string(opts.Compression))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(baseDir); err != nil {
+		return nil, fmt.Errorf("failed to open output directory: %w", err)
+	}
+
+	layouts, err := newLayouts(opts.Layout)
+	if err != nil {
+		return nil, err
+	}
+	s := &JSONStorage{baseDir: baseDir, compression: compression, written: newByteCounters(), layouts: layouts}
+	if len(opts.EncryptionKey) > 0 {
+		if s.aead, err = newAEAD(opts.EncryptionKey); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// NewJSONStorageWithOptions creates a new JSON storage instance with the
+// given options
+func NewJSONStorageWithOptions(baseDir string, opts Options) (*JSONStorage, error) {
+	compression, err := ParseCompression(string(opts.Compression))
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.ChangeLog && len(opts.EncryptionKey) > 0 {
+		return nil, fmt.Errorf("the change log cannot be combined with encryption")
+	}
+
+	layouts, err := newLayouts(opts.Layout)
+	if err != nil {
+		return nil, err
+	}
+
+	var aead cipher.AEAD
+	if len(opts.EncryptionKey) > 0 {
+		if aead, err = newAEAD(opts.EncryptionKey); err != nil {
+			return nil, err
+		}
+	}
+
 	// Create base directory if it doesn't exist
 	if err := os.MkdirAll(baseDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create base directory: %w", err)
@@ -24,6 +127,11 @@ func NewJSONStorage(baseDir string) (*JSONStorage, error) {
 	// Create subdirectories
 	usersDir := filepath.Join(baseDir, "users")
 	projectsDir := filepath.Join(baseDir, "projects")
+	tasksDir := filepath.Join(baseDir, "tasks")
+	teamsDir := filepath.Join(baseDir, "teams")
+	userTaskListsDir := filepath.Join(baseDir, "user_task_lists")
+	statusUpdatesDir := filepath.Join(baseDir, "status_updates")
+	customFieldsDir := filepath.Join(baseDir, "custom_fields")
 
 	if err := os.MkdirAll(usersDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create users directory: %w", err)
@@ -33,21 +141,304 @@ func NewJSONStorage(baseDir string) (*JSONStorage, error) {
 		return nil, fmt.Errorf("failed to create projects directory: %w", err)
 	}
 
+	if err := os.MkdirAll(tasksDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create tasks directory: %w", err)
+	}
+
+	if err := os.MkdirAll(teamsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create teams directory: %w", err)
+	}
+
+	if err := os.MkdirAll(userTaskListsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create user task lists directory: %w", err)
+	}
+
+	if err := os.MkdirAll(statusUpdatesDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create status updates directory: %w", err)
+	}
+
+	if err := os.MkdirAll(customFieldsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create custom fields directory: %w", err)
+	}
+
+	if opts.DurableWrites {
+		if err := syncDir(baseDir); err != nil {
+			return nil, err
+		}
+	}
+
+	var changes *changeLog
+	if opts.ChangeLog {
+		if changes, err = newChangeLog(baseDir, opts.DurableWrites); err != nil {
+			return nil, err
+		}
+	}
+
+	journal, err := openJournal(baseDir)
+	if err != nil {
+		return nil, err
+	}
+
 	return &JSONStorage{
-		baseDir: baseDir,
+		baseDir:      baseDir,
+		compression:  compression,
+		aead:         aead,
+		written:      newByteCounters(),
+		changes:      changes,
+		journal:      journal,
+		durable:      opts.DurableWrites,
+		layouts:      layouts,
+		envelope:     opts.Envelope,
+		workspaceGID: opts.WorkspaceGID,
 	}, nil
 }
 
+// newByteCounters returns a zeroed byte counter per resource directory
+func newByteCounters() map[string]*atomic.Int64 {
+	return map[string]*atomic.Int64{
+		"users":    {},
+		"projects": {},
+		"tasks":    {},
+		"teams":    {},
+
+		"user_task_lists":  {},
+		"avatars":          {},
+		"attachments":      {},
+		"status_updates":   {},
+		"custom_fields":    {},
+		"audit_log_events": {},
+		"rejects":          {},
+	}
+}
+
 // WriteUser writes a user to a JSON file
 func (s *JSONStorage) WriteUser(user asana.User) error {
-	filename := filepath.Join(s.baseDir, "users", fmt.Sprintf("%s.json", user.GID))
-	return s.writeJSON(filename, user)
+	return s.writeEntity("users", user.GID, user)
 }
 
 // WriteProject writes a project to a JSON file
 func (s *JSONStorage) WriteProject(project asana.Project) error {
-	filename := filepath.Join(s.baseDir, "projects", fmt.Sprintf("%s.json", project.GID))
-	return s.writeJSON(filename, project)
+	return s.writeEntity("projects", project.GID, project)
+}
+
+// WriteTask writes a task to a JSON file
+func (s *JSONStorage) WriteTask(task asana.Task) error {
+	return s.writeEntity("tasks", task.GID, task)
+}
+
+// WriteTeam writes a team to a JSON file
+func (s *JSONStorage) WriteTeam(team asana.Team) error {
+	return s.writeEntity("teams", team.GID, team)
+}
+
+// WriteStatusUpdate writes a status update, with its parent reference, to a
+// JSON file
+func (s *JSONStorage) WriteStatusUpdate(update asana.StatusUpdate) error {
+	return s.writeEntity("status_updates", update.GID, update)
+}
+
+// WriteUserTaskList writes a user task list, with its tasks, to a JSON file
+func (s *JSONStorage) WriteUserTaskList(list asana.UserTaskList) error {
+	return s.writeEntity("user_task_lists", list.GID, list)
+}
+
+// extension returns the file extension of entity files
+func (s *JSONStorage) extension() string {
+	if s.aead != nil {
+		return s.compression.Extension() + encryptedExtension
+	}
+	return s.compression.Extension()
+}
+
+// entityPath returns the file holding the entity with the given GID. An
+// entity not stored yet, or whose layout cannot be listed, gets the flat
+// path.
+func (s *JSONStorage) entityPath(resource, gid string) string {
+	if l, err := s.layoutOf(resource); err == nil && l != nil {
+		if rel, ok := l.path(gid); ok {
+			return filepath.Join(s.baseDir, resource, rel+s.extension())
+		}
+	}
+	return filepath.Join(s.baseDir, resource, gid+s.extension())
+}
+
+// writeEntity writes an entity file and records it in the change log
+func (s *JSONStorage) writeEntity(resource, gid string, data interface{}) error {
+	op, payload, err := s.putEntity(resource, gid, data)
+	if err != nil || op == "" {
+		return err
+	}
+	return s.changes.record(op, resource, gid, payload)
+}
+
+// putEntity writes an entity file, compressed and encrypted if configured.
+// It returns OpCreate or OpUpdate with the JSON payload, or an empty op when
+// the file already held the entity.
+func (s *JSONStorage) putEntity(resource, gid string, data interface{}) (string, []byte, error) {
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	filename := s.entityPath(resource, gid)
+
+	// With a layout the entity may belong in another partition than the
+	// one holding it. Tombstones stay where the entity was.
+	target, rel := filename, ""
+	l, err := s.layoutOf(resource)
+	if err != nil {
+		return "", nil, err
+	}
+	if l != nil {
+		if _, tombstone := data.(Tombstone); tombstone {
+			rel, _ = l.path(gid)
+		} else if rel, err = l.place(gid, jsonData); err != nil {
+			return "", nil, err
+		}
+		if rel != "" {
+			target = filepath.Join(s.baseDir, resource, rel+s.extension())
+		}
+	}
+	moved := target != filename
+
+	// Ciphertext differs on every write, and so does an envelope's
+	// timestamp, so compare the decoded payload. A file in the other format
+	// is rewritten.
+	if (s.aead != nil || s.envelope) && !moved {
+		if existing, err := s.readEntityFile(filename); err == nil {
+			if payload, wrapped := unwrap(existing); wrapped == s.envelope && samePayload(payload, jsonData) {
+				s.unchanged.Add(1)
+				return "", nil, nil
+			}
+		}
+	}
+
+	wrapped, err := s.wrap(jsonData)
+	if err != nil {
+		return "", nil, err
+	}
+	encoded, err := s.encode(wrapped)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if s.aead == nil && !s.envelope && !moved && isUnchanged(filename, encoded) {
+		s.unchanged.Add(1)
+		return "", nil, nil
+	}
+
+	// An entity coming back after a tombstone is created again
+	op := OpUpdate
+	if s.changes != nil {
+		if err := s.readEntity(resource, gid, &Tombstone{}); errors.Is(err, ErrNotFound) || errors.Is(err, ErrDeleted) {
+			op = OpCreate
+		}
+	}
+
+	if l != nil {
+		if err := s.ensureDir(target); err != nil {
+			return "", nil, err
+		}
+	}
+	if err := s.replaceFile(target, encoded); err != nil {
+		return "", nil, err
+	}
+	if moved {
+		if err := s.removeFile(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", nil, err
+		}
+	}
+	if l != nil && rel != "" {
+		l.set(gid, rel)
+	}
+	s.written[resource].Add(int64(len(encoded)))
+	return op, jsonData, nil
+}
+
+// encode compresses, then encrypts, an entity payload
+func (s *JSONStorage) encode(data []byte) ([]byte, error) {
+	encoded, err := compress(s.compression, data)
+	if err != nil {
+		return nil, err
+	}
+	if s.aead != nil {
+		return seal(s.aead, encoded)
+	}
+	return encoded, nil
+}
+
+// readEntityFile reads an entity file and reverses encode
+func (s *JSONStorage) readEntityFile(filename string) ([]byte, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if s.aead != nil {
+		if data, err = open(s.aead, data); err != nil {
+			return nil, err
+		}
+	}
+	return decompress(s.compression, data)
+}
+
+// WriteManifest regenerates the index of every entity directory, writes
+// the run manifest to manifest.json in the base directory and publishes the
+// run's change log, when enabled. The journal goes last: until then the
+// output is marked incomplete.
+func (s *JSONStorage) WriteManifest(manifest any) error {
+	// A manifest without a run ID still gets indexes, just unattributed
+	runID, _ := manifestRunID(manifest)
+	if err := s.writeAttachmentRefs(); err != nil {
+		return err
+	}
+	if err := s.writeIndexes(runID); err != nil {
+		return err
+	}
+	if err := s.writeJSON(filepath.Join(s.baseDir, "manifest.json"), manifest); err != nil {
+		return err
+	}
+	if s.changes != nil {
+		runID, err := manifestRunID(manifest)
+		if err != nil {
+			return err
+		}
+		if err := s.changes.publish(runID); err != nil {
+			return err
+		}
+	}
+	return s.journal.close()
+}
+
+// Incomplete reports whether a run is modifying the output, or crashed
+// before writing its manifest, so entity files may come from two runs
+func (s *JSONStorage) Incomplete() bool {
+	_, err := os.Stat(filepath.Join(s.baseDir, JournalFile))
+	return err == nil
+}
+
+// LoadCheckpoint decodes checkpoint.json in the base directory into v,
+// leaving v untouched when there is none yet
+func (s *JSONStorage) LoadCheckpoint(v any) error {
+	data, err := os.ReadFile(filepath.Join(s.baseDir, "checkpoint.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+	return nil
+}
+
+// SaveCheckpoint writes v to checkpoint.json in the base directory
+func (s *JSONStorage) SaveCheckpoint(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+	return writeFile(filepath.Join(s.baseDir, "checkpoint.json"), data, s.durable)
 }
 
 // writeJSON writes data to a JSON file atomically
@@ -58,9 +449,99 @@ func (s *JSONStorage) writeJSON(filename string, data interface{}) error {
 		return fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 
+	return s.writeIfChanged(filename, jsonData)
+}
+
+// writeIfChanged writes data atomically unless filename already holds it
+func (s *JSONStorage) writeIfChanged(filename string, data []byte) error {
+	if isUnchanged(filename, data) {
+		s.unchanged.Add(1)
+		return nil
+	}
+
+	return s.replaceFile(filename, data)
+}
+
+// replaceFile journals filename, then writes data to it atomically
+func (s *JSONStorage) replaceFile(filename string, data []byte) error {
+	if err := s.journal.intent(JournalPut, filename); err != nil {
+		return err
+	}
+	return writeFile(filename, data, s.durable)
+}
+
+// removeFile journals filename, then deletes it
+func (s *JSONStorage) removeFile(filename string) error {
+	if err := s.journal.intent(JournalDelete, filename); err != nil {
+		return err
+	}
+	if err := os.Remove(filename); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", filename, err)
+	}
+	if s.durable {
+		return syncDir(filepath.Dir(filename))
+	}
+	return nil
+}
+
+// BytesWritten returns the bytes written for a resource since the storage
+// was created. Skipped unchanged writes are not counted.
+func (s *JSONStorage) BytesWritten(resource string) int64 {
+	if counter, ok := s.written[resource]; ok {
+		return counter.Load()
+	}
+	return 0
+}
+
+// Unchanged returns the number of writes skipped because the payload was
+// identical to the file on disk
+func (s *JSONStorage) Unchanged() int64 {
+	return s.unchanged.Load()
+}
+
+// isUnchanged reports whether filename already holds exactly data, comparing
+// content hashes. A missing or unreadable file counts as changed.
+func isUnchanged(filename string, data []byte) bool {
+	existing, err := os.ReadFile(filename)
+	if err != nil {
+		return false
+	}
+	return sha256.Sum256(existing) == sha256.Sum256(data)
+}
+
+// tempPattern is appended to a file's name to name its temporary files
+const tempPattern = ".*.tmp"
+
+// writeFileAtomic writes data to a temporary file and renames it into place
+func writeFileAtomic(filename string, data []byte) error {
+	return writeFile(filename, data, false)
+}
+
+// writeFile is writeFileAtomic, which with durable also syncs the temporary
+// file before the rename and the directory after it. A crash can otherwise
+// persist the rename without the data, leaving an empty file. Every call
+// gets its own temporary file, so concurrent writes of the same file, such
+// as a task shared by projects extracted in parallel, cannot clobber each
+// other's.
+func writeFile(filename string, data []byte, durable bool) error {
 	// Write to temporary file first
-	tempFile := filename + ".tmp"
-	if err := os.WriteFile(tempFile, jsonData, 0644); err != nil {
+	f, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+tempPattern)
+	if err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	tempFile := f.Name()
+	err = f.Chmod(0644)
+	if err == nil {
+		_, err = f.Write(data)
+	}
+	if err == nil && durable {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tempFile)
 		return fmt.Errorf("failed to write temporary file: %w", err)
 	}
 
@@ -70,5 +551,8 @@ func (s *JSONStorage) writeJSON(filename string, data interface{}) error {
 		return fmt.Errorf("failed to rename file: %w", err)
 	}
 
+	if durable {
+		return syncDir(filepath.Dir(filename))
+	}
 	return nil
 }
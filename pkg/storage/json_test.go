@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/ioanzicu/asana-extractor/pkg/asana"
 )
@@ -40,7 +42,7 @@ func TestNewJSONStorage(t *testing.T) {
 
 			if !tt.wantErr {
 				// Verify structure
-				for _, sub := range []string{"users", "projects"} {
+				for _, sub := range []string{"users", "projects", "tasks", "teams", "user_task_lists", "status_updates", "custom_fields"} {
 					path := filepath.Join(tt.baseDir, sub)
 					if _, err := os.Stat(path); os.IsNotExist(err) {
 						t.Errorf("directory %s was not created", sub)
@@ -117,6 +119,155 @@ func TestWriteOperations(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("WriteTask", func(t *testing.T) {
+		task := asana.Task{GID: "t1", Name: "Ship it", Completed: true}
+		if err := storage.WriteTask(task); err != nil {
+			t.Fatalf("WriteTask() failed: %v", err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(tmpDir, "tasks", "t1.json"))
+		if err != nil {
+			t.Fatalf("task file not written: %v", err)
+		}
+		var saved asana.Task
+		json.Unmarshal(data, &saved)
+		if saved.Name != task.Name || !saved.Completed {
+			t.Errorf("unexpected task content: %+v", saved)
+		}
+	})
+
+	t.Run("WriteTeam", func(t *testing.T) {
+		if err := storage.WriteTeam(asana.Team{GID: "team1", Name: "Platform"}); err != nil {
+			t.Fatalf("WriteTeam() failed: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(tmpDir, "teams", "team1.json")); err != nil {
+			t.Errorf("team file not written: %v", err)
+		}
+	})
+
+	t.Run("WriteStatusUpdate", func(t *testing.T) {
+		update := asana.StatusUpdate{GID: "s1", StatusType: "at_risk", Parent: &asana.ParentRef{GID: "g1", ResourceType: "goal"}}
+		if err := storage.WriteStatusUpdate(update); err != nil {
+			t.Fatalf("WriteStatusUpdate() failed: %v", err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(tmpDir, "status_updates", "s1.json"))
+		if err != nil {
+			t.Fatalf("status update file not written: %v", err)
+		}
+		var saved asana.StatusUpdate
+		json.Unmarshal(data, &saved)
+		if saved.Parent == nil || saved.Parent.ResourceType != "goal" || saved.StatusType != "at_risk" {
+			t.Errorf("unexpected status update content: %+v", saved)
+		}
+	})
+
+	t.Run("WriteUserTaskList", func(t *testing.T) {
+		list := asana.UserTaskList{GID: "l1", Owner: &asana.User{GID: "123"}, Tasks: []asana.Task{{GID: "t1"}}}
+		if err := storage.WriteUserTaskList(list); err != nil {
+			t.Fatalf("WriteUserTaskList() failed: %v", err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(tmpDir, "user_task_lists", "l1.json"))
+		if err != nil {
+			t.Fatalf("user task list file not written: %v", err)
+		}
+		var saved asana.UserTaskList
+		json.Unmarshal(data, &saved)
+		if saved.Owner == nil || saved.Owner.GID != "123" || len(saved.Tasks) != 1 {
+			t.Errorf("unexpected user task list content: %+v", saved)
+		}
+	})
+}
+
+func TestWriteJSON_SkipsUnchanged(t *testing.T) {
+	tmpDir := t.TempDir()
+	storage, _ := NewJSONStorage(tmpDir)
+	path := filepath.Join(tmpDir, "users", "u1.json")
+
+	tests := []struct {
+		name            string
+		user            asana.User
+		expectUnchanged int64
+		expectRewrite   bool
+	}{
+		{name: "First write", user: asana.User{GID: "u1", Name: "Alice"}, expectUnchanged: 0, expectRewrite: true},
+		{name: "Identical write is skipped", user: asana.User{GID: "u1", Name: "Alice"}, expectUnchanged: 1, expectRewrite: false},
+		{name: "Changed payload is written", user: asana.User{GID: "u1", Name: "Alicia"}, expectUnchanged: 1, expectRewrite: true},
+	}
+
+	var bytesBefore int64
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Backdate the file so a rewrite is observable through mtime
+			old := time.Now().Add(-time.Hour)
+			os.Chtimes(path, old, old)
+
+			if err := storage.WriteUser(tt.user); err != nil {
+				t.Fatalf("WriteUser() failed: %v", err)
+			}
+			if got := storage.Unchanged(); got != tt.expectUnchanged {
+				t.Errorf("Unchanged() = %d, want %d", got, tt.expectUnchanged)
+			}
+
+			written := storage.BytesWritten("users")
+			if wantGrowth := tt.expectRewrite; (written > bytesBefore) != wantGrowth {
+				t.Errorf("BytesWritten grew from %d to %d, expected growth: %v", bytesBefore, written, wantGrowth)
+			}
+			bytesBefore = written
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rewritten := info.ModTime().After(old.Add(time.Minute)); rewritten != tt.expectRewrite {
+				t.Errorf("file rewritten = %v, want %v", rewritten, tt.expectRewrite)
+			}
+		})
+	}
+}
+
+func TestWriteManifest(t *testing.T) {
+	tmpDir := t.TempDir()
+	storage, _ := NewJSONStorage(tmpDir)
+
+	if err := storage.WriteManifest(map[string]any{"run_id": "r1", "status": "succeeded"}); err != nil {
+		t.Fatalf("WriteManifest() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "manifest.json"))
+	if err != nil {
+		t.Fatalf("manifest not written: %v", err)
+	}
+	var saved map[string]any
+	json.Unmarshal(data, &saved)
+	if saved["run_id"] != "r1" {
+		t.Errorf("unexpected manifest content: %v", saved)
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	tmpDir := t.TempDir()
+	storage, _ := NewJSONStorage(tmpDir)
+
+	cp := map[string]string{"kept": "yes"}
+	if err := storage.LoadCheckpoint(&cp); err != nil || cp["kept"] != "yes" {
+		t.Fatalf("Expected a missing checkpoint to leave v untouched, got %v, %v", cp, err)
+	}
+
+	if err := storage.SaveCheckpoint(map[string]string{"p1": "2024-01-02T03:04:05Z"}); err != nil {
+		t.Fatalf("SaveCheckpoint() failed: %v", err)
+	}
+	var loaded map[string]string
+	if err := storage.LoadCheckpoint(&loaded); err != nil || loaded["p1"] != "2024-01-02T03:04:05Z" {
+		t.Errorf("LoadCheckpoint() = %v, %v", loaded, err)
+	}
+
+	os.WriteFile(filepath.Join(tmpDir, "checkpoint.json"), []byte("{"), 0644)
+	if err := storage.LoadCheckpoint(&loaded); err == nil {
+		t.Error("Expected an error for a corrupt checkpoint")
+	}
 }
 
 func TestWriteJSON_Errors(t *testing.T) {
@@ -149,3 +300,97 @@ func TestWriteJSON_Errors(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		filename string
+		durable  bool
+		wantErr  bool
+	}{
+		{name: "Plain", filename: filepath.Join(tmpDir, "plain.json")},
+		{name: "Durable", filename: filepath.Join(tmpDir, "durable.json"), durable: true},
+		{name: "Missing directory", filename: filepath.Join(tmpDir, "missing", "x.json"), durable: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := writeFile(tt.filename, []byte(`{"gid":"1"}`), tt.durable)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("writeFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tmps, _ := filepath.Glob(tt.filename + tempPattern); len(tmps) > 0 {
+				t.Errorf("Expected no temporary file to be left behind, found %v", tmps)
+			}
+			if tt.wantErr {
+				return
+			}
+			if data, err := os.ReadFile(tt.filename); err != nil || string(data) != `{"gid":"1"}` {
+				t.Errorf("Read back %q, %v", data, err)
+			}
+		})
+	}
+}
+
+func TestWriteFile_Concurrent(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "task.json")
+	data := []byte(`{"gid":"1","name":"shared by several projects"}`)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- writeFile(filename, data, false)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("writeFile() failed: %v", err)
+		}
+	}
+	if got, err := os.ReadFile(filename); err != nil || string(got) != string(data) {
+		t.Errorf("Read back %q, %v", got, err)
+	}
+	if tmps, _ := filepath.Glob(filename + tempPattern); len(tmps) > 0 {
+		t.Errorf("Expected no temporary file to be left behind, found %v", tmps)
+	}
+}
+
+func TestDurableWrites(t *testing.T) {
+	tmpDir := t.TempDir()
+	s, err := NewJSONStorageWithOptions(tmpDir, Options{DurableWrites: true, ChangeLog: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.WriteTask(asana.Task{GID: "t1", Name: "Sync me"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteTask(asana.Task{GID: "t2"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Reconcile("tasks", map[string]struct{}{"t1": {}}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteManifest(map[string]any{"run_id": "r1", "status": "succeeded"}); err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := s.ReadTask("t1")
+	if err != nil || task.Name != "Sync me" {
+		t.Errorf("ReadTask() = %+v, %v", task, err)
+	}
+	if _, err := s.ReadTask("t2"); err == nil {
+		t.Error("Expected t2 to be deleted")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, ChangesDir, "r1.jsonl")); err != nil {
+		t.Errorf("Expected the change log to be published: %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// layoutEmpty names the partition of entities without a value for it
+const layoutEmpty = "_none"
+
+// layoutFields resolves the placeholders of a layout template from an
+// entity's JSON fields. Dates come from created_at, which never changes, so
+// entities stay in their partition.
+var layoutFields = map[string]func(fields map[string]any) string{
+	"team_gid":      func(f map[string]any) string { return nestedGID(f["team"]) },
+	"workspace_gid": func(f map[string]any) string { return nestedGID(f["workspace"]) },
+	"assignee_gid":  func(f map[string]any) string { return nestedGID(f["assignee"]) },
+	"project_gid": func(f map[string]any) string {
+		if projects, ok := f["projects"].([]any); ok && len(projects) > 0 {
+			return nestedGID(projects[0])
+		}
+		return ""
+	},
+	"date": func(f map[string]any) string { return createdDate(f, 10) },
+	"year": func(f map[string]any) string { return createdDate(f, 4) },
+	"month": func(f map[string]any) string {
+		if ym := createdDate(f, 7); ym != "" {
+			return ym[5:]
+		}
+		return ""
+	},
+}
+
+var placeholderPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// layout places the entity files of one resource in subdirectories built
+// from a template such as "dt={date}/{gid}", and remembers where each
+// entity is so it can be read back by GID alone
+type layout struct {
+	dirs []string // template segments before the file name
+
+	load  sync.Once
+	err   error
+	mu    sync.Mutex
+	paths map[string]string // GID → path relative to the resource directory, without extension
+}
+
+// ParseLayout checks a layout template: slash-separated directory
+// segments with placeholders, ending in the file name {gid}
+func ParseLayout(template string) error {
+	_, err := newLayout(template)
+	return err
+}
+
+func newLayout(template string) (*layout, error) {
+	segments := strings.Split(template, "/")
+	if segments[len(segments)-1] != "{gid}" {
+		return nil, fmt.Errorf("layout %q must end with {gid}", template)
+	}
+	l := &layout{dirs: segments[:len(segments)-1]}
+	for _, dir := range l.dirs {
+		if dir == "" || dir == "." || dir == ".." {
+			return nil, fmt.Errorf("layout %q has an invalid directory %q", template, dir)
+		}
+		for _, m := range placeholderPattern.FindAllStringSubmatch(dir, -1) {
+			if _, ok := layoutFields[m[1]]; !ok {
+				return nil, fmt.Errorf("layout %q has an unknown placeholder {%s} (available: %s)", template, m[1], strings.Join(layoutPlaceholders(), " "))
+			}
+		}
+		if strings.ContainsAny(placeholderPattern.ReplaceAllString(dir, ""), "{}") {
+			return nil, fmt.Errorf("layout %q has an unclosed placeholder in %q", template, dir)
+		}
+	}
+	return l, nil
+}
+
+// layoutPlaceholders returns the sorted placeholder names a layout accepts
+func layoutPlaceholders() []string {
+	names := []string{"{gid}"}
+	for name := range layoutFields {
+		names = append(names, "{"+name+"}")
+	}
+	sort.Strings(names)
+	return names
+}
+
+// place returns the path of an entity relative to the resource directory,
+// without extension
+func (l *layout) place(gid string, payload []byte) (string, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return "", fmt.Errorf("failed to read %s for its layout: %w", gid, err)
+	}
+
+	parts := make([]string, 0, len(l.dirs)+1)
+	for _, dir := range l.dirs {
+		parts = append(parts, placeholderPattern.ReplaceAllStringFunc(dir, func(m string) string {
+			return partitionValue(layoutFields[m[1:len(m)-1]](fields))
+		}))
+	}
+	return filepath.Join(append(parts, gid)...), nil
+}
+
+// ensure loads the paths of the entities already stored under dir, once
+func (l *layout) ensure(dir, ext string) error {
+	l.load.Do(func() {
+		l.paths = make(map[string]string)
+		l.err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			name := d.Name()
+			gid, ok := strings.CutSuffix(name, ext)
+			if !ok || d.IsDir() || (name == IndexFile && filepath.Dir(path) == dir) {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			l.paths[gid] = strings.TrimSuffix(rel, ext)
+			return nil
+		})
+		if l.err != nil {
+			l.err = fmt.Errorf("failed to list %s: %w", dir, l.err)
+		}
+	})
+	return l.err
+}
+
+// path returns where an entity is stored
+func (l *layout) path(gid string) (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rel, ok := l.paths[gid]
+	return rel, ok
+}
+
+// set records where an entity is stored, or that it is gone for an empty
+// rel
+func (l *layout) set(gid, rel string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if rel == "" {
+		delete(l.paths, gid)
+		return
+	}
+	l.paths[gid] = rel
+}
+
+// gids returns the sorted GIDs of the stored entities
+func (l *layout) gids() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	gids := make([]string, 0, len(l.paths))
+	for gid := range l.paths {
+		gids = append(gids, gid)
+	}
+	sort.Strings(gids)
+	return gids
+}
+
+// newLayouts builds the layout of every resource in templates
+func newLayouts(templates map[string]string) (map[string]*layout, error) {
+	if len(templates) == 0 {
+		return nil, nil
+	}
+	layouts := make(map[string]*layout, len(templates))
+	for resource, template := range templates {
+		if !slices.Contains(indexedResources, resource) {
+			return nil, fmt.Errorf("unknown layout resource %q (available: %s)", resource, strings.Join(indexedResources, " "))
+		}
+		l, err := newLayout(template)
+		if err != nil {
+			return nil, err
+		}
+		if len(l.dirs) > 0 {
+			layouts[resource] = l
+		}
+	}
+	return layouts, nil
+}
+
+// nestedGID returns the gid of a nested JSON object
+func nestedGID(v any) string {
+	obj, _ := v.(map[string]any)
+	gid, _ := obj["gid"].(string)
+	return gid
+}
+
+// createdDate returns the first n characters of created_at, such as the
+// date for n = 10, or "" when it is unset
+func createdDate(fields map[string]any, n int) string {
+	created, _ := fields["created_at"].(string)
+	if len(created) < n || strings.HasPrefix(created, "0001-") {
+		return ""
+	}
+	return created[:n]
+}
+
+// partitionValue makes a placeholder value safe as part of a directory name
+func partitionValue(v string) string {
+	if v == "" {
+		return layoutEmpty
+	}
+	v = strings.NewReplacer("/", "_", `\`, "_").Replace(v)
+	if strings.HasPrefix(v, ".") {
+		v = "_" + v
+	}
+	return v
+}
+
+// layoutOf returns the layout of resource, its stored paths loaded, or nil
+// for the flat default
+func (s *JSONStorage) layoutOf(resource string) (*layout, error) {
+	l := s.layouts[resource]
+	if l == nil {
+		return nil, nil
+	}
+	if err := l.ensure(filepath.Join(s.baseDir, resource), s.extension()); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// ensureDir creates the partition directory of filename if it is missing.
+// With durable writes the new directories are synced into their parents.
+func (s *JSONStorage) ensureDir(filename string) error {
+	dir := filepath.Dir(filename)
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	if !s.durable {
+		return nil
+	}
+	for ; dir != s.baseDir && strings.HasPrefix(dir, s.baseDir); dir = filepath.Dir(dir) {
+		if err := syncDir(filepath.Dir(dir)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestParseLayout(t *testing.T) {
+	tests := []struct {
+		template string
+		wantErr  bool
+	}{
+		{"{gid}", false},
+		{"dt={date}/{gid}", false},
+		{"team={team_gid}/{year}-{month}/{gid}", false},
+		{"dt={date}", true},
+		{"{gid}/{date}", true},
+		{"dt={date}//{gid}", true},
+		{"../{gid}", true},
+		{"owner={owner}/{gid}", true},
+		{"dt={date/{gid}", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			if err := ParseLayout(tt.template); (err != nil) != tt.wantErr {
+				t.Errorf("ParseLayout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLayout(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{Layout: map[string]string{
+		"tasks":    "dt={date}/{gid}",
+		"projects": "team={team_gid}/{gid}",
+	}}
+	s, err := NewJSONStorageWithOptions(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := s.WriteTask(asana.Task{GID: "t1", Name: "Dated", CreatedAt: created}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteTask(asana.Task{GID: "t2", Name: "Undated"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteProject(asana.Project{GID: "p1", Team: &asana.Team{GID: "team1"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"tasks/dt=2024-01-02/t1.json", "tasks/dt=_none/t2.json", "projects/team=team1/p1.json"} {
+		if _, err := os.Stat(filepath.Join(dir, path)); err != nil {
+			t.Errorf("Expected %s: %v", path, err)
+		}
+	}
+	if task, err := s.ReadTask("t1"); err != nil || task.Name != "Dated" {
+		t.Errorf("Expected to read t1 back, got %+v, %v", task, err)
+	}
+
+	// A project moved to another team follows it
+	if err := s.WriteProject(asana.Project{GID: "p1", Team: &asana.Team{GID: "team2"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "projects", "team=team1", "p1.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected the old partition's file to be removed, got %v", err)
+	}
+	if project, err := s.ReadProject("p1"); err != nil || project.Team.GID != "team2" {
+		t.Errorf("Expected p1 in its new partition, got %+v, %v", project, err)
+	}
+
+	if err := s.WriteManifest(map[string]any{"run_id": "r1"}); err != nil {
+		t.Fatal(err)
+	}
+	index, err := s.ReadIndex("tasks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := index.Entities["t1"].File; got != "dt=2024-01-02/t1.json" {
+		t.Errorf("Expected the index to hold the partitioned path, got %q", got)
+	}
+
+	// A new instance finds the stored entities by walking the partitions
+	s, err = NewJSONStorageWithOptions(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gids, err := s.ListGIDs("tasks"); err != nil || !reflect.DeepEqual(gids, []string{"t1", "t2"}) {
+		t.Errorf("Unexpected GIDs %v, %v", gids, err)
+	}
+	if _, err := s.Reconcile("tasks", map[string]struct{}{"t2": {}}, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tasks", "dt=2024-01-02", "t1.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected reconcile to remove t1, got %v", err)
+	}
+	if gids, _ := s.ListGIDs("tasks"); !reflect.DeepEqual(gids, []string{"t2"}) {
+		t.Errorf("Expected only t2 after reconcile, got %v", gids)
+	}
+}
+
+func TestLayout_UnknownResource(t *testing.T) {
+	if _, err := NewJSONStorageWithOptions(t.TempDir(), Options{Layout: map[string]string{"goals": "{gid}"}}); err == nil {
+		t.Error("Expected error for a layout of an unknown resource")
+	}
+}
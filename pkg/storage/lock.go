@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LockFile is the name of the lock file held in the output root while an
+// extractor instance is running
+const LockFile = ".lock"
+
+// ErrLocked is returned by Lock when another instance holds the lock
+var ErrLocked = errors.New("output directory is locked by another extractor")
+
+// Lock is an exclusive, process-wide lock on an output directory
+type Lock struct {
+	file *os.File
+	path string
+}
+
+// AcquireLock takes the lock on dir, creating the directory if needed. It
+// fails immediately with ErrLocked if another instance holds it.
+func AcquireLock(dir string) (*Lock, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create base directory: %w", err)
+	}
+
+	path := filepath.Join(dir, LockFile)
+	f, err := lockFile(path)
+	if errors.Is(err, ErrLocked) {
+		return nil, fmt.Errorf("%w: %s (held by pid %s; remove %s if that process is gone)",
+			ErrLocked, dir, lockHolder(path), path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock output directory: %w", err)
+	}
+
+	// Record the holder so a blocked instance can report it
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+
+	return &Lock{file: f, path: path}, nil
+}
+
+// Release gives up the lock
+func (l *Lock) Release() error {
+	if err := unlockFile(l.file, l.path); err != nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	return nil
+}
+
+// lockHolder returns the PID recorded in the lock file, or "unknown"
+func lockHolder(path string) string {
+	data, err := os.ReadFile(path)
+	if pid := strings.TrimSpace(string(data)); err == nil && pid != "" {
+		return pid
+	}
+	return "unknown"
+}
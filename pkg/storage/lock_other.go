@@ -0,0 +1,24 @@
+//go:build !unix
+
+package storage
+
+import (
+	"errors"
+	"os"
+)
+
+// lockFile creates path exclusively, acting as a PID file. Unlike flock it
+// survives a crash, in which case the file must be removed by hand.
+func lockFile(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return nil, ErrLocked
+	}
+	return f, err
+}
+
+// unlockFile closes and removes the PID file
+func unlockFile(f *os.File, path string) error {
+	f.Close()
+	return os.Remove(path)
+}
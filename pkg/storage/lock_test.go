@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAcquireLock(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "output")
+
+	lock, err := AcquireLock(dir)
+	if err != nil {
+		t.Fatalf("AcquireLock() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, LockFile))
+	if err != nil {
+		t.Fatalf("Failed to read lock file: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != strconv.Itoa(os.Getpid()) {
+		t.Errorf("Expected lock file to hold our pid, got %q", data)
+	}
+
+	// A second instance must be refused while the lock is held
+	_, err = AcquireLock(dir)
+	if !errors.Is(err, ErrLocked) {
+		t.Fatalf("Expected ErrLocked, got %v", err)
+	}
+	if !strings.Contains(err.Error(), strconv.Itoa(os.Getpid())) {
+		t.Errorf("Expected error to name the holder, got %v", err)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() failed: %v", err)
+	}
+
+	// Once released the lock can be taken again
+	lock, err = AcquireLock(dir)
+	if err != nil {
+		t.Fatalf("AcquireLock() after release failed: %v", err)
+	}
+	lock.Release()
+}
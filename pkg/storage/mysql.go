@@ -0,0 +1,241 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// mysqlMaxRows caps the rows of one INSERT, well below MySQL's limit of
+// 65535 placeholders per statement
+const mysqlMaxRows = 1000
+
+// mysqlTableName matches the table names accepted in STORAGE_PARAMS, which
+// are quoted but not escaped
+var mysqlTableName = regexp.MustCompile(`^[A-Za-z0-9_$]{1,64}$`)
+
+// mysqlTableSchema is the definition of every entity table, created unless
+// create_tables is false
+const mysqlTableSchema = "CREATE TABLE IF NOT EXISTS `%s` (" +
+	"gid VARCHAR(64) NOT NULL PRIMARY KEY, " +
+	"data JSON NOT NULL, " +
+	"run_id VARCHAR(32) NOT NULL, " +
+	"updated_at DATETIME(6) NOT NULL)"
+
+// MySQLStorage upserts users, projects, tasks and teams into one MySQL
+// table per resource, keyed by GID, holding each entity as a JSON document
+// with the run_id of the run that last wrote it. Entities deleted in Asana
+// are not removed. It connects through the database/sql driver registered
+// as "mysql", or the one named by the driver parameter.
+type MySQLStorage struct {
+	runStamp
+	db     *sql.DB
+	tables map[string]string
+}
+
+func init() {
+	Register("mysql", func(s Settings) (Backend, error) {
+		return NewMySQLStorage(s.Params)
+	})
+}
+
+// NewMySQLStorage creates the backend from its STORAGE_PARAMS:
+//
+//   - dsn: the data source name, such as
+//     user:password@tcp(db:3306)/asana (required)
+//   - driver: the database/sql driver (default "mysql")
+//   - table_prefix: prefix of the table names (default "asana_")
+//   - users_table, projects_table, tasks_table, teams_table: the full
+//     name of a resource's table, replacing <table_prefix><resource>
+//   - create_tables: create missing tables at startup (default true)
+//
+// The connection is checked at startup.
+func NewMySQLStorage(params map[string]string) (*MySQLStorage, error) {
+	for key := range params {
+		switch key {
+		case "dsn", "driver", "table_prefix", "users_table", "projects_table", "tasks_table", "teams_table", "create_tables":
+		default:
+			return nil, fmt.Errorf("unknown mysql parameter %q", key)
+		}
+	}
+	if params["dsn"] == "" {
+		return nil, fmt.Errorf("the mysql backend requires dsn in STORAGE_PARAMS")
+	}
+
+	prefix, ok := params["table_prefix"]
+	if !ok {
+		prefix = "asana_"
+	}
+	tables := make(map[string]string)
+	for _, resource := range []string{"users", "projects", "tasks", "teams"} {
+		name := params[resource+"_table"]
+		if name == "" {
+			name = prefix + resource
+		}
+		if !mysqlTableName.MatchString(name) {
+			return nil, fmt.Errorf("invalid mysql table name %q for %s", name, resource)
+		}
+		tables[resource] = name
+	}
+
+	createTables := true
+	if v, ok := params["create_tables"]; ok {
+		switch v {
+		case "true":
+		case "false":
+			createTables = false
+		default:
+			return nil, fmt.Errorf("mysql create_tables must be true or false (got %q)", v)
+		}
+	}
+
+	driver := params["driver"]
+	if driver == "" {
+		driver = "mysql"
+	}
+	if !sqlDriverRegistered(driver) {
+		return nil, fmt.Errorf("no database/sql driver %q is registered", driver)
+	}
+	db, err := sql.Open(driver, params["dsn"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mysql database: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to mysql: %w", err)
+	}
+	if createTables {
+		for resource, table := range tables {
+			if _, err := db.ExecContext(ctx, fmt.Sprintf(mysqlTableSchema, table)); err != nil {
+				db.Close()
+				return nil, fmt.Errorf("failed to create %s table %s: %w", resource, table, err)
+			}
+		}
+	}
+	return &MySQLStorage{db: db, tables: tables}, nil
+}
+
+// sqlDriverRegistered reports whether a database/sql driver is registered
+// under name
+func sqlDriverRegistered(name string) bool {
+	for _, driver := range sql.Drivers() {
+		if driver == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Table returns the table of resource, or "" for an unknown resource
+func (s *MySQLStorage) Table(resource string) string {
+	return s.tables[resource]
+}
+
+// WriteUser upserts user into the users table
+func (s *MySQLStorage) WriteUser(user asana.User) error {
+	return s.upsert("users", mysqlRow{user.GID, user})
+}
+
+// WriteProject upserts project into the projects table
+func (s *MySQLStorage) WriteProject(project asana.Project) error {
+	return s.upsert("projects", mysqlRow{project.GID, project})
+}
+
+// WriteTask upserts task into the tasks table
+func (s *MySQLStorage) WriteTask(task asana.Task) error {
+	return s.upsert("tasks", mysqlRow{task.GID, task})
+}
+
+// WriteTeam upserts team into the teams table
+func (s *MySQLStorage) WriteTeam(team asana.Team) error {
+	return s.upsert("teams", mysqlRow{team.GID, team})
+}
+
+// WriteUsers upserts users in one statement
+func (s *MySQLStorage) WriteUsers(users []asana.User) error {
+	return s.upsert("users", mysqlRows(users, func(u asana.User) string { return u.GID })...)
+}
+
+// WriteProjects upserts projects in one statement
+func (s *MySQLStorage) WriteProjects(projects []asana.Project) error {
+	return s.upsert("projects", mysqlRows(projects, func(p asana.Project) string { return p.GID })...)
+}
+
+// WriteTasks upserts tasks in one statement
+func (s *MySQLStorage) WriteTasks(tasks []asana.Task) error {
+	return s.upsert("tasks", mysqlRows(tasks, func(t asana.Task) string { return t.GID })...)
+}
+
+// WriteTeams upserts teams in one statement
+func (s *MySQLStorage) WriteTeams(teams []asana.Team) error {
+	return s.upsert("teams", mysqlRows(teams, func(t asana.Team) string { return t.GID })...)
+}
+
+// mysqlRow is an entity to upsert with its GID
+type mysqlRow struct {
+	gid string
+	v   any
+}
+
+// mysqlRows pairs items with their GIDs
+func mysqlRows[T any](items []T, gid func(T) string) []mysqlRow {
+	rows := make([]mysqlRow, len(items))
+	for i, item := range items {
+		rows[i] = mysqlRow{gid(item), item}
+	}
+	return rows
+}
+
+// upsert inserts rows into resource's table, replacing the rows of GIDs
+// already there, mysqlMaxRows per statement, in one transaction so a failed
+// batch leaves no partial write. It uses VALUES() in ON DUPLICATE KEY
+// UPDATE, which MySQL 8.0.20 and later deprecate but every MySQL and
+// MariaDB version supports.
+func (s *MySQLStorage) upsert(resource string, rows ...mysqlRow) (err error) {
+	now := time.Now().UTC()
+	runID := s.runID()
+
+	ctx := context.Background()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin %s upsert: %w", resource, err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for start := 0; start < len(rows); start += mysqlMaxRows {
+		chunk := rows[start:min(start+mysqlMaxRows, len(rows))]
+
+		args := make([]any, 0, 4*len(chunk))
+		for _, row := range chunk {
+			data, err := json.Marshal(row.v)
+			if err != nil {
+				return fmt.Errorf("failed to marshal %s %s: %w", resource, row.gid, err)
+			}
+			args = append(args, row.gid, string(data), runID, now)
+		}
+		query := fmt.Sprintf("INSERT INTO `%s` (gid, data, run_id, updated_at) VALUES %s "+
+			"ON DUPLICATE KEY UPDATE data = VALUES(data), run_id = VALUES(run_id), updated_at = VALUES(updated_at)",
+			s.tables[resource], strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?), ", len(chunk)), ", "))
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to upsert %d %s: %w", len(chunk), resource, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit %s upsert: %w", resource, err)
+	}
+	return nil
+}
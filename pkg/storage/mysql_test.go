@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// recordingDriver is a database/sql driver recording the statements
+// executed through it, including COMMIT and ROLLBACK. With fail set,
+// inserts fail.
+type recordingDriver struct {
+	mu      sync.Mutex
+	queries []string
+	args    [][]driver.NamedValue
+	fail    bool
+}
+
+// reset forgets the recorded statements and stops failing
+func (d *recordingDriver) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries, d.args, d.fail = nil, nil, false
+}
+
+func (d *recordingDriver) record(query string, args []driver.NamedValue) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries = append(d.queries, query)
+	d.args = append(d.args, args)
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) {
+	return &recordingConn{d: d}, nil
+}
+
+type recordingConn struct {
+	d *recordingDriver
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *recordingConn) Close() error {
+	return nil
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return recordingTx{d: c.d}, nil
+}
+
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.d.record(query, args)
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	if c.d.fail && strings.HasPrefix(query, "INSERT") {
+		return nil, errors.New("deadlock found")
+	}
+	return driver.RowsAffected(1), nil
+}
+
+type recordingTx struct {
+	d *recordingDriver
+}
+
+func (tx recordingTx) Commit() error {
+	tx.d.record("COMMIT", nil)
+	return nil
+}
+
+func (tx recordingTx) Rollback() error {
+	tx.d.record("ROLLBACK", nil)
+	return nil
+}
+
+var testMySQLDriver = &recordingDriver{}
+
+func init() {
+	sql.Register("mysql-test", testMySQLDriver)
+}
+
+func TestMySQLStorage(t *testing.T) {
+	testMySQLDriver.reset()
+	s, err := NewMySQLStorage(map[string]string{"dsn": "test", "driver": "mysql-test", "tasks_table": "work_items"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(testMySQLDriver.queries) != 4 || !strings.Contains(strings.Join(testMySQLDriver.queries, "\n"), "CREATE TABLE IF NOT EXISTS `work_items`") {
+		t.Fatalf("Expected the four tables to be created, got %v", testMySQLDriver.queries)
+	}
+	if s.Table("users") != "asana_users" || s.Table("tasks") != "work_items" {
+		t.Errorf("Unexpected tables %v", s.tables)
+	}
+
+	s.StartRun("r1")
+	testMySQLDriver.reset()
+	if err := s.WriteTasks([]asana.Task{{GID: "t1", Name: "Close the books"}, {GID: "t2"}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(testMySQLDriver.queries) != 2 || testMySQLDriver.queries[1] != "COMMIT" {
+		t.Fatalf("Expected one committed statement for the batch, got %q", testMySQLDriver.queries)
+	}
+	query := testMySQLDriver.queries[0]
+	if !strings.HasPrefix(query, "INSERT INTO `work_items` (gid, data, run_id, updated_at) VALUES (?, ?, ?, ?), (?, ?, ?, ?) ON DUPLICATE KEY UPDATE data = VALUES(data)") {
+		t.Errorf("Unexpected upsert %q", query)
+	}
+	args := testMySQLDriver.args[0]
+	if len(args) != 8 || args[0].Value != "t1" || !strings.Contains(args[1].Value.(string), "Close the books") || args[2].Value != "r1" {
+		t.Errorf("Unexpected arguments %v", args)
+	}
+}
+
+func TestMySQLStorage_Transaction(t *testing.T) {
+	testMySQLDriver.reset()
+	s, err := NewMySQLStorage(map[string]string{"dsn": "test", "driver": "mysql-test", "create_tables": "false"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := make([]asana.User, mysqlMaxRows+1)
+	for i := range users {
+		users[i].GID = fmt.Sprint(i + 1)
+	}
+
+	tests := []struct {
+		name    string
+		fail    bool
+		wantErr bool
+		want    []string
+	}{
+		{name: "Chunks Commit Together", want: []string{"INSERT", "INSERT", "COMMIT"}},
+		{name: "Failed Chunk Rolls Back", fail: true, wantErr: true, want: []string{"INSERT", "ROLLBACK"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testMySQLDriver.reset()
+			testMySQLDriver.fail = tc.fail
+			if err := s.WriteUsers(users); (err != nil) != tc.wantErr {
+				t.Fatalf("WriteUsers() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			var got []string
+			for _, query := range testMySQLDriver.queries {
+				got = append(got, strings.Fields(query)[0])
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("Statements = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMySQLStorage_Params(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]string
+	}{
+		{name: "Missing DSN", params: map[string]string{"driver": "mysql-test"}},
+		{name: "Unknown Parameter", params: map[string]string{"dsn": "test", "driver": "mysql-test", "table": "x"}},
+		{name: "Invalid Table", params: map[string]string{"dsn": "test", "driver": "mysql-test", "tasks_table": "tasks; DROP TABLE users"}},
+		{name: "Invalid create_tables", params: map[string]string{"dsn": "test", "driver": "mysql-test", "create_tables": "yes"}},
+		{name: "Driver Not Linked", params: map[string]string{"dsn": "test", "driver": "mysql-missing"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewMySQLStorage(tc.params); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
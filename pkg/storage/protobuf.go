@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+	"github.com/ioanzicu/asana-extractor/pkg/protobuf"
+)
+
+// ProtoFile is the file of the output directory holding the message
+// definitions of the protobuf backend
+const ProtoFile = "asana.proto"
+
+// ProtobufStorage writes one length-delimited protobuf file per resource,
+// <resource>.pb, with the messages derived from the pkg/asana types. Each
+// message is a <Type>Record holding the entity and the run_id of the run
+// that wrote it. Like the avro backend, each run writes fresh files, which
+// replace the previous ones only when the run succeeds.
+type ProtobufStorage struct {
+	runStamp
+	codecs map[string]*protobuf.Codec
+
+	mu    sync.Mutex
+	files *runFiles[*protobuf.Writer]
+}
+
+func init() {
+	Register("protobuf", func(s Settings) (Backend, error) {
+		if len(s.Options.EncryptionKey) > 0 || (s.Options.Compression != "" && s.Options.Compression != CompressionNone) {
+			return nil, fmt.Errorf("the protobuf backend supports neither compression nor encryption")
+		}
+		if len(s.Params) > 0 {
+			return nil, fmt.Errorf("the protobuf backend takes no parameters")
+		}
+		st, err := NewProtobufStorage(s.Dir)
+		if err != nil {
+			return nil, err
+		}
+		st.files.durable = s.Options.DurableWrites
+		return st, nil
+	})
+}
+
+// NewProtobufStorage creates a protobuf storage in dir and writes the
+// message definitions to dir/asana.proto, so consumers can generate their
+// decoders from the files next to the data
+func NewProtobufStorage(dir string) (*ProtobufStorage, error) {
+	s := &ProtobufStorage{codecs: make(map[string]*protobuf.Codec)}
+	var codecs []*protobuf.Codec
+	for _, r := range []struct {
+		resource string
+		v        any
+	}{
+		{"users", asana.User{}},
+		{"projects", asana.Project{}},
+		{"tasks", asana.Task{}},
+		{"teams", asana.Team{}},
+	} {
+		codec, err := protobuf.NewCodec(r.v, RunIDField)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive %s messages: %w", r.resource, err)
+		}
+		s.codecs[r.resource] = codec
+		codecs = append(codecs, codec)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, ProtoFile), []byte(protobuf.Proto(codecs...))); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", ProtoFile, err)
+	}
+	s.files = newRunFiles(dir, ".pb", func(resource string, f io.Writer) (*protobuf.Writer, error) {
+		return protobuf.NewWriter(f, s.codecs[resource]), nil
+	})
+	return s, nil
+}
+
+// Codec returns the codec of resource, or nil for an unknown resource
+func (s *ProtobufStorage) Codec(resource string) *protobuf.Codec {
+	return s.codecs[resource]
+}
+
+// WriteUser appends user to users.pb
+func (s *ProtobufStorage) WriteUser(user asana.User) error {
+	return s.write("users", user)
+}
+
+// WriteProject appends project to projects.pb
+func (s *ProtobufStorage) WriteProject(project asana.Project) error {
+	return s.write("projects", project)
+}
+
+// WriteTask appends task to tasks.pb
+func (s *ProtobufStorage) WriteTask(task asana.Task) error {
+	return s.write("tasks", task)
+}
+
+// WriteTeam appends team to teams.pb
+func (s *ProtobufStorage) WriteTeam(team asana.Team) error {
+	return s.write("teams", team)
+}
+
+// WriteUsers appends users to users.pb
+func (s *ProtobufStorage) WriteUsers(users []asana.User) error {
+	return s.write("users", anys(users)...)
+}
+
+// WriteProjects appends projects to projects.pb
+func (s *ProtobufStorage) WriteProjects(projects []asana.Project) error {
+	return s.write("projects", anys(projects)...)
+}
+
+// WriteTasks appends tasks to tasks.pb
+func (s *ProtobufStorage) WriteTasks(tasks []asana.Task) error {
+	return s.write("tasks", anys(tasks)...)
+}
+
+// WriteTeams appends teams to teams.pb
+func (s *ProtobufStorage) WriteTeams(teams []asana.Team) error {
+	return s.write("teams", anys(teams)...)
+}
+
+// WriteManifest ends the run, publishing its files if it succeeded
+func (s *ProtobufStorage) WriteManifest(manifest any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.files.finish(manifest, func(resource string) bool {
+		_, ok := s.codecs[resource]
+		return ok
+	})
+}
+
+// write appends each of vs to resource's file
+func (s *ProtobufStorage) write(resource string, vs ...any) error {
+	if len(vs) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, err := s.files.get(resource)
+	if err != nil {
+		return err
+	}
+	runID := s.runID()
+	for _, v := range vs {
+		if err := w.Write(v, runID); err != nil {
+			return fmt.Errorf("failed to write %s record: %w", resource, err)
+		}
+	}
+	return nil
+}
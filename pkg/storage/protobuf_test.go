@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+	"github.com/ioanzicu/asana-extractor/pkg/protobuf"
+)
+
+func TestProtobufStorage(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewProtobufStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.StartRun("r1")
+
+	if err := s.WriteTask(asana.Task{GID: "t1", Name: "Close the books"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteTasks([]asana.Task{{GID: "t2", Name: "File taxes"}, {GID: "t3"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteManifest(map[string]any{"status": "succeeded", "resources": []string{"tasks", "teams"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "tasks.pb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	var messages [][]byte
+	for {
+		msg, err := protobuf.ReadDelimited(r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, msg)
+	}
+	if len(messages) != 3 {
+		t.Fatalf("Expected 3 messages in tasks.pb, got %d", len(messages))
+	}
+	want, _ := s.Codec("tasks").Append(nil, asana.Task{GID: "t1", Name: "Close the books"}, "r1")
+	if !bytes.Equal(messages[0], want) {
+		t.Errorf("Unexpected first message %x", messages[0])
+	}
+
+	if info, err := os.Stat(filepath.Join(dir, "teams.pb")); err != nil || info.Size() != 0 {
+		t.Errorf("Expected an empty teams.pb for a run without teams: %v", err)
+	}
+	proto, err := os.ReadFile(filepath.Join(dir, ProtoFile))
+	if err != nil || !strings.Contains(string(proto), "message TaskRecord {") {
+		t.Errorf("Expected the message definitions in %s: %v", ProtoFile, err)
+	}
+}
+
+func TestProtobufStorage_Settings(t *testing.T) {
+	if _, err := Open("protobuf", Settings{Dir: t.TempDir(), Options: Options{Compression: CompressionGzip}}); err == nil {
+		t.Error("Expected an error for compressed protobuf files")
+	}
+	if _, err := Open("protobuf", Settings{Dir: t.TempDir(), Params: map[string]string{"x": "y"}}); err == nil {
+		t.Error("Expected an error for parameters")
+	}
+}
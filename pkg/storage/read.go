@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+var (
+	// ErrNotFound is returned when no file exists for the requested GID
+	ErrNotFound = errors.New("entity not found")
+	// ErrDeleted is returned when the entity has been replaced by a tombstone
+	ErrDeleted = errors.New("entity deleted")
+)
+
+// Reader gives access to previously extracted data
+type Reader interface {
+	ReadUser(gid string) (asana.User, error)
+	ReadProject(gid string) (asana.Project, error)
+	ReadTask(gid string) (asana.Task, error)
+	ReadTeam(gid string) (asana.Team, error)
+	ListGIDs(resource string) ([]string, error)
+}
+
+// ReadUser reads a stored user
+func (s *JSONStorage) ReadUser(gid string) (asana.User, error) {
+	var user asana.User
+	err := s.readEntity("users", gid, &user)
+	return user, err
+}
+
+// ReadProject reads a stored project
+func (s *JSONStorage) ReadProject(gid string) (asana.Project, error) {
+	var project asana.Project
+	err := s.readEntity("projects", gid, &project)
+	return project, err
+}
+
+// ReadTask reads a stored task
+func (s *JSONStorage) ReadTask(gid string) (asana.Task, error) {
+	var task asana.Task
+	err := s.readEntity("tasks", gid, &task)
+	return task, err
+}
+
+// ReadTeam reads a stored team
+func (s *JSONStorage) ReadTeam(gid string) (asana.Team, error) {
+	var team asana.Team
+	err := s.readEntity("teams", gid, &team)
+	return team, err
+}
+
+// ListGIDs returns the sorted GIDs stored for a resource, tombstones included
+func (s *JSONStorage) ListGIDs(resource string) ([]string, error) {
+	if l, err := s.layoutOf(resource); err != nil || l != nil {
+		if err != nil {
+			return nil, err
+		}
+		return l.gids(), nil
+	}
+
+	entries, err := os.ReadDir(filepath.Join(s.baseDir, resource))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", resource, err)
+	}
+
+	var gids []string
+	for _, entry := range entries {
+		if gid, ok := strings.CutSuffix(entry.Name(), s.extension()); ok && !entry.IsDir() && entry.Name() != IndexFile {
+			gids = append(gids, gid)
+		}
+	}
+	sort.Strings(gids)
+	return gids, nil
+}
+
+// ReadManifest decodes the manifest of the last run into v. It returns
+// ErrNotFound before the first run.
+func (s *JSONStorage) ReadManifest(v any) error {
+	data, err := os.ReadFile(filepath.Join(s.baseDir, "manifest.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("manifest: %w", ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+	return nil
+}
+
+// EachUser calls fn for every stored user, skipping tombstones
+func (s *JSONStorage) EachUser(fn func(asana.User) error) error {
+	return each(s, "users", s.ReadUser, fn)
+}
+
+// EachProject calls fn for every stored project, skipping tombstones
+func (s *JSONStorage) EachProject(fn func(asana.Project) error) error {
+	return each(s, "projects", s.ReadProject, fn)
+}
+
+// EachTask calls fn for every stored task, skipping tombstones
+func (s *JSONStorage) EachTask(fn func(asana.Task) error) error {
+	return each(s, "tasks", s.ReadTask, fn)
+}
+
+// EachTeam calls fn for every stored team, skipping tombstones
+func (s *JSONStorage) EachTeam(fn func(asana.Team) error) error {
+	return each(s, "teams", s.ReadTeam, fn)
+}
+
+// each walks a resource in GID order, stopping at the first error from read
+// or fn
+func each[T any](s *JSONStorage, resource string, read func(string) (T, error), fn func(T) error) error {
+	gids, err := s.ListGIDs(resource)
+	if err != nil {
+		return err
+	}
+
+	for _, gid := range gids {
+		item, err := read(gid)
+		if errors.Is(err, ErrDeleted) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readEntity decodes the stored entity into v, reporting tombstones as
+// ErrDeleted
+func (s *JSONStorage) readEntity(resource, gid string, v any) error {
+	data, err := s.readEntityFile(s.entityPath(resource, gid))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%s %s: %w", resource, gid, ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s %s: %w", resource, gid, err)
+	}
+	data, _ = unwrap(data)
+
+	var tombstone Tombstone
+	if json.Unmarshal(data, &tombstone) == nil && tombstone.Deleted {
+		return fmt.Errorf("%s %s: %w", resource, gid, ErrDeleted)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to unmarshal %s %s: %w", resource, gid, err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestReadOperations(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "Plain", opts: Options{}},
+		{name: "Compressed and encrypted", opts: Options{Compression: CompressionZstd, EncryptionKey: make([]byte, 32)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage, err := NewJSONStorageWithOptions(t.TempDir(), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			storage.WriteUser(asana.User{GID: "u2", Name: "Bob"})
+			storage.WriteUser(asana.User{GID: "u1", Name: "Alice"})
+			storage.WriteUser(asana.User{GID: "u3", Name: "Carol"})
+			storage.WriteProject(asana.Project{GID: "p1", Name: "Roadmap"})
+			storage.Reconcile("users", map[string]struct{}{"u1": {}, "u2": {}}, true)
+
+			user, err := storage.ReadUser("u1")
+			if err != nil || user.Name != "Alice" {
+				t.Errorf("ReadUser() = %+v, %v", user, err)
+			}
+			project, err := storage.ReadProject("p1")
+			if err != nil || project.Name != "Roadmap" {
+				t.Errorf("ReadProject() = %+v, %v", project, err)
+			}
+			if _, err := storage.ReadUser("missing"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+			if _, err := storage.ReadUser("u3"); !errors.Is(err, ErrDeleted) {
+				t.Errorf("expected ErrDeleted, got %v", err)
+			}
+
+			gids, err := storage.ListGIDs("users")
+			if err != nil || len(gids) != 3 || gids[0] != "u1" {
+				t.Errorf("ListGIDs() = %v, %v", gids, err)
+			}
+
+			var names []string
+			err = storage.EachUser(func(u asana.User) error {
+				names = append(names, u.Name)
+				return nil
+			})
+			if err != nil || len(names) != 2 || names[0] != "Alice" || names[1] != "Bob" {
+				t.Errorf("EachUser() visited %v, %v", names, err)
+			}
+		})
+	}
+}
+
+func TestEach_StopsOnError(t *testing.T) {
+	storage, _ := NewJSONStorage(t.TempDir())
+	storage.WriteTeam(asana.Team{GID: "t1"})
+	storage.WriteTeam(asana.Team{GID: "t2"})
+
+	stop := errors.New("stop")
+	visited := 0
+	err := storage.EachTeam(func(asana.Team) error {
+		visited++
+		return stop
+	})
+	if !errors.Is(err, stop) || visited != 1 {
+		t.Errorf("expected to stop after first team, visited %d, err %v", visited, err)
+	}
+}
+
+func TestOpenJSONStorage(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{Compression: CompressionGzip}
+	written, err := NewJSONStorageWithOptions(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	written.WriteTeam(asana.Team{GID: "t1", Name: "Platform"})
+	written.WriteManifest(map[string]string{"run_id": "r1"})
+
+	store, err := OpenJSONStorage(dir, opts)
+	if err != nil {
+		t.Fatalf("OpenJSONStorage() failed: %v", err)
+	}
+	if team, err := store.ReadTeam("t1"); err != nil || team.Name != "Platform" {
+		t.Errorf("ReadTeam() = %+v, %v", team, err)
+	}
+	var manifest map[string]string
+	if err := store.ReadManifest(&manifest); err != nil || manifest["run_id"] != "r1" {
+		t.Errorf("ReadManifest() = %v, %v", manifest, err)
+	}
+
+	missing := filepath.Join(dir, "latest")
+	if _, err := OpenJSONStorage(missing, opts); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("Expected OpenJSONStorage not to create %s", missing)
+	}
+
+	empty, _ := OpenJSONStorage(t.TempDir(), opts)
+	if err := empty.ReadManifest(&manifest); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound before the first run, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Tombstone replaces the file of an entity that no longer exists in Asana
+type Tombstone struct {
+	GID       string    `json:"gid"`
+	Deleted   bool      `json:"deleted"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// Reconcile deletes, or replaces with a Tombstone, every stored entity of the
+// given resource whose GID is not in live. Existing tombstones are left alone
+// so deleted_at keeps the time the deletion was first observed.
+func (s *JSONStorage) Reconcile(resource string, live map[string]struct{}, tombstone bool) (int, error) {
+	gids, err := s.ListGIDs(resource)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	orphans := 0
+	for _, gid := range gids {
+		if _, found := live[gid]; found {
+			continue
+		}
+
+		filename := s.entityPath(resource, gid)
+		if !tombstone {
+			if err := s.removeFile(filename); err != nil {
+				return orphans, err
+			}
+			if l := s.layouts[resource]; l != nil {
+				l.set(gid, "")
+			}
+			orphans++
+			if err := s.changes.record(OpDelete, resource, gid, nil); err != nil {
+				return orphans, err
+			}
+			continue
+		}
+
+		if err := s.readEntity(resource, gid, &Tombstone{}); errors.Is(err, ErrDeleted) {
+			continue
+		}
+		if _, _, err := s.putEntity(resource, gid, Tombstone{GID: gid, Deleted: true, DeletedAt: now}); err != nil {
+			return orphans, fmt.Errorf("failed to write tombstone for %s: %w", gid, err)
+		}
+		orphans++
+		if err := s.changes.record(OpDelete, resource, gid, nil); err != nil {
+			return orphans, err
+		}
+	}
+
+	return orphans, nil
+}
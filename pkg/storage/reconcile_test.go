@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name          string
+		tombstone     bool
+		expectOrphans int
+	}{
+		{name: "Delete orphans", tombstone: false, expectOrphans: 1},
+		{name: "Tombstone orphans", tombstone: true, expectOrphans: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			storage, _ := NewJSONStorage(tmpDir)
+			storage.WriteUser(asana.User{GID: "u1"})
+			storage.WriteUser(asana.User{GID: "u2"})
+
+			live := map[string]struct{}{"u1": {}}
+			orphans, err := storage.Reconcile("users", live, tt.tombstone)
+			if err != nil {
+				t.Fatalf("Reconcile() failed: %v", err)
+			}
+			if orphans != tt.expectOrphans {
+				t.Errorf("expected %d orphans, got %d", tt.expectOrphans, orphans)
+			}
+
+			if _, err := os.Stat(filepath.Join(tmpDir, "users", "u1.json")); err != nil {
+				t.Errorf("live user was removed: %v", err)
+			}
+
+			orphanPath := filepath.Join(tmpDir, "users", "u2.json")
+			if tt.tombstone {
+				if _, err := storage.ReadUser("u2"); !errors.Is(err, ErrDeleted) {
+					t.Error("expected u2 to be tombstoned")
+				}
+				// A second pass leaves the existing tombstone untouched
+				if again, _ := storage.Reconcile("users", live, true); again != 0 {
+					t.Errorf("expected tombstone to be reused, got %d new orphans", again)
+				}
+			} else if _, err := os.Stat(orphanPath); !os.IsNotExist(err) {
+				t.Errorf("expected u2 to be deleted, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// Backend is the write interface every storage backend implements. Backends
+// may also implement the optional interfaces the extractor detects, such as
+// manifest writing or change counting.
+type Backend interface {
+	WriteUser(user asana.User) error
+	WriteProject(project asana.Project) error
+	WriteTask(task asana.Task) error
+	WriteTeam(team asana.Team) error
+}
+
+// Settings is what a Factory receives to build a backend
+type Settings struct {
+	// Dir is the configured output directory
+	Dir string
+	// Options holds compression and encryption settings
+	Options Options
+	// Params holds backend-specific settings (STORAGE_PARAMS)
+	Params map[string]string
+}
+
+// Factory builds a backend from settings
+type Factory func(settings Settings) (Backend, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+func init() {
+	Register("json", func(s Settings) (Backend, error) {
+		return NewJSONStorageWithOptions(s.Dir, s.Options)
+	})
+}
+
+// Register makes a backend available under name. It is meant to be called
+// from a backend package's init function and panics if name is taken.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if factory == nil {
+		panic("storage: Register factory is nil")
+	}
+	if _, dup := registry[name]; dup {
+		panic("storage: Register called twice for backend " + name)
+	}
+	registry[name] = factory
+}
+
+// Open builds the backend registered under name
+func Open(name string, settings Settings) (Backend, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown storage backend %q (registered: %s)", name, strings.Join(Backends(), ","))
+	}
+
+	backend, err := factory(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s storage: %w", name, err)
+	}
+	return backend, nil
+}
+
+// Backends returns the sorted names of the registered backends
+func Backends() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
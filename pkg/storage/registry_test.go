@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// memoryBackend is a minimal third-party style backend
+type memoryBackend struct {
+	params map[string]string
+	users  []asana.User
+}
+
+func (m *memoryBackend) WriteUser(user asana.User) error          { m.users = append(m.users, user); return nil }
+func (m *memoryBackend) WriteProject(project asana.Project) error { return nil }
+func (m *memoryBackend) WriteTask(task asana.Task) error          { return nil }
+func (m *memoryBackend) WriteTeam(team asana.Team) error          { return nil }
+
+func TestRegistry(t *testing.T) {
+	Register("memory-test", func(s Settings) (Backend, error) {
+		return &memoryBackend{params: s.Params}, nil
+	})
+	Register("broken-test", func(s Settings) (Backend, error) {
+		return nil, errors.New("no connection")
+	})
+
+	t.Run("Builtin json backend", func(t *testing.T) {
+		backend, err := Open("json", Settings{Dir: t.TempDir()})
+		if err != nil {
+			t.Fatalf("Open() failed: %v", err)
+		}
+		if _, ok := backend.(*JSONStorage); !ok {
+			t.Errorf("Expected *JSONStorage, got %T", backend)
+		}
+	})
+
+	t.Run("Builtin discard backend", func(t *testing.T) {
+		backend, err := Open("discard", Settings{})
+		if err != nil {
+			t.Fatalf("Open() failed: %v", err)
+		}
+		if err := backend.WriteTask(asana.Task{GID: "1"}); err != nil {
+			t.Errorf("Expected writes to be discarded, got %v", err)
+		}
+	})
+
+	t.Run("Registered backend receives params", func(t *testing.T) {
+		backend, err := Open("memory-test", Settings{Params: map[string]string{"bucket": "b"}})
+		if err != nil {
+			t.Fatalf("Open() failed: %v", err)
+		}
+		if got := backend.(*memoryBackend).params["bucket"]; got != "b" {
+			t.Errorf("Expected params to be passed through, got %q", got)
+		}
+	})
+
+	t.Run("Factory error is wrapped", func(t *testing.T) {
+		if _, err := Open("broken-test", Settings{}); err == nil || !strings.Contains(err.Error(), "no connection") {
+			t.Errorf("Expected factory error, got %v", err)
+		}
+	})
+
+	t.Run("Unknown backend lists registered ones", func(t *testing.T) {
+		_, err := Open("s3", Settings{})
+		if err == nil || !strings.Contains(err.Error(), "json") {
+			t.Errorf("Expected error listing backends, got %v", err)
+		}
+	})
+
+	t.Run("Duplicate registration panics", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic on duplicate Register")
+			}
+		}()
+		Register("json", func(s Settings) (Backend, error) { return nil, nil })
+	})
+}
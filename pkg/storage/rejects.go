@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RejectsDir is the directory of the output root holding entities that
+// failed validation, under one subdirectory per resource
+const RejectsDir = "rejects"
+
+// Reject is the file written for an entity that failed validation
+type Reject struct {
+	Resource   string          `json:"resource"`
+	Reason     string          `json:"reason"`
+	RejectedAt time.Time       `json:"rejected_at"`
+	Record     json.RawMessage `json:"record"`
+}
+
+// WriteReject quarantines an entity that failed validation in
+// rejects/<resource>/<hash>.json, named by the SHA-256 of the record since
+// its GID cannot be trusted. The same record rejected again overwrites its
+// file. Rejects are compressed and encrypted like entity files.
+func (s *JSONStorage) WriteReject(resource string, record any, reason string) error {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rejected %s: %w", resource, err)
+	}
+	data, err := json.MarshalIndent(Reject{Resource: resource, Reason: reason, RejectedAt: time.Now().UTC(), Record: raw}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal reject: %w", err)
+	}
+	encoded, err := s.encode(data)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(s.baseDir, RejectsDir, resource)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create rejects directory: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	if err := s.replaceFile(filepath.Join(dir, hex.EncodeToString(sum[:8])+s.extension()), encoded); err != nil {
+		return err
+	}
+	s.written[RejectsDir].Add(int64(len(encoded)))
+	return nil
+}
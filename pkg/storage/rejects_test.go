@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestWriteReject(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task := asana.Task{Name: "No GID"}
+	for range 2 {
+		if err := s.WriteReject("tasks", task, "empty GID"); err != nil {
+			t.Fatalf("WriteReject() error = %v", err)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, RejectsDir, "tasks", "*.json"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one reject file for the same record, got %v (%v)", files, err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reject Reject
+	if err := json.Unmarshal(data, &reject); err != nil {
+		t.Fatalf("invalid reject file: %v", err)
+	}
+	var got asana.Task
+	if err := json.Unmarshal(reject.Record, &got); err != nil || got.Name != "No GID" {
+		t.Errorf("expected the record to round-trip, got %+v (%v)", got, err)
+	}
+	if reject.Resource != "tasks" || reject.Reason != "empty GID" || reject.RejectedAt.IsZero() {
+		t.Errorf("unexpected reject: %+v", reject)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "tasks", ".json")); !os.IsNotExist(err) {
+		t.Error("expected no entity file named after an empty GID")
+	}
+	if s.BytesWritten(RejectsDir) == 0 {
+		t.Error("expected reject bytes to be counted")
+	}
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// runFiles holds the single file per resource that file-per-resource
+// backends write each run. A run writes fresh partial files, which replace
+// the previous run's files only if it succeeds.
+type runFiles[W interface{ Flush() error }] struct {
+	dir string
+	// ext is the extension of the published files, such as ".csv"
+	ext string
+	// create starts the file of resource, writing any header
+	create func(resource string, w io.Writer) (W, error)
+	files  map[string]*runFile[W]
+	// durable syncs the partial files before they are published, and the
+	// directory after
+	durable bool
+}
+
+// runFile is the partial file of one resource in the current run
+type runFile[W any] struct {
+	f *os.File
+	w W
+}
+
+func newRunFiles[W interface{ Flush() error }](dir, ext string, create func(string, io.Writer) (W, error)) *runFiles[W] {
+	return &runFiles[W]{dir: dir, ext: ext, create: create, files: make(map[string]*runFile[W])}
+}
+
+// get returns the writer of resource for this run, creating its partial
+// file on first use
+func (r *runFiles[W]) get(resource string) (W, error) {
+	if file, ok := r.files[resource]; ok {
+		return file.w, nil
+	}
+
+	f, err := os.Create(filepath.Join(r.dir, "."+resource+r.ext+".partial"))
+	if err != nil {
+		var zero W
+		return zero, fmt.Errorf("failed to create %s%s: %w", resource, r.ext, err)
+	}
+	w, err := r.create(resource, f)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return w, fmt.Errorf("failed to start %s%s: %w", resource, r.ext, err)
+	}
+	r.files[resource] = &runFile[W]{f: f, w: w}
+	return w, nil
+}
+
+// finish ends the run described by manifest. If it succeeded, every
+// resource it extracted that has a writer in known is published, empty
+// ones with just their header; otherwise the partial files are discarded.
+func (r *runFiles[W]) finish(manifest any, known func(resource string) bool) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	var run struct {
+		Status    string   `json:"status"`
+		Resources []string `json:"resources"`
+	}
+	if err := json.Unmarshal(data, &run); err != nil {
+		return fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	if run.Status != "succeeded" {
+		r.discard()
+		return nil
+	}
+	for _, resource := range run.Resources {
+		if !known(resource) {
+			continue
+		}
+		if _, err := r.get(resource); err != nil {
+			r.discard()
+			return err
+		}
+	}
+
+	var firstErr error
+	for resource, file := range r.files {
+		partial := file.f.Name()
+		err := file.w.Flush()
+		if err == nil && r.durable {
+			err = file.f.Sync()
+		}
+		if closeErr := file.f.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(partial, filepath.Join(r.dir, resource+r.ext))
+		}
+		if err != nil {
+			os.Remove(partial)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to publish %s%s: %w", resource, r.ext, err)
+			}
+		}
+		delete(r.files, resource)
+	}
+	if firstErr == nil && r.durable {
+		return syncDir(r.dir)
+	}
+	return firstErr
+}
+
+// discard removes the partial files of the current run
+func (r *runFiles[W]) discard() {
+	for resource, file := range r.files {
+		file.f.Close()
+		os.Remove(file.f.Name())
+		delete(r.files, resource)
+	}
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// lineWriter buffers a line and writes it on Flush, failing if told to
+type lineWriter struct {
+	w    io.Writer
+	line string
+	fail bool
+}
+
+func (l *lineWriter) Flush() error {
+	if l.fail {
+		return errors.New("disk full")
+	}
+	_, err := io.WriteString(l.w, l.line)
+	return err
+}
+
+func TestRunFiles(t *testing.T) {
+	dir := t.TempDir()
+	writers := make(map[string]*lineWriter)
+	files := newRunFiles(dir, ".txt", func(resource string, w io.Writer) (*lineWriter, error) {
+		writers[resource] = &lineWriter{w: w, line: resource}
+		return writers[resource], nil
+	})
+	known := func(resource string) bool { return resource != "goals" }
+
+	files.get("users")
+	files.get("tasks")
+	writers["tasks"].fail = true
+	err := files.finish(map[string]any{"status": "succeeded", "resources": []string{"users", "tasks", "teams", "goals"}}, known)
+	if err == nil {
+		t.Error("Expected the failed flush of tasks to be reported")
+	}
+
+	for name, want := range map[string]string{"users.txt": "users", "teams.txt": "teams"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", name, data, err, want)
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("Expected only users.txt and teams.txt, got %d entries", len(entries))
+	}
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"encoding/json"
+	"sync/atomic"
+)
+
+// RunIDField is the field or column in which the csv, avro, elasticsearch
+// and singer backends stamp every record with the ID of the run that wrote
+// it
+const RunIDField = "run_id"
+
+// runStamp holds the ID of the run in progress for backends that stamp
+// their records with it. Its StartRun implements extractor.RunStamper.
+type runStamp struct {
+	id atomic.Pointer[string]
+}
+
+// StartRun stamps the records written from now on with runID
+func (r *runStamp) StartRun(runID string) {
+	r.id.Store(&runID)
+}
+
+// runID returns the ID of the run in progress, empty before StartRun
+func (r *runStamp) runID() string {
+	if id := r.id.Load(); id != nil {
+		return *id
+	}
+	return ""
+}
+
+// withRunID adds the RunIDField field to doc, a JSON object
+func withRunID(doc []byte, runID string) []byte {
+	value, _ := json.Marshal(runID)
+	stamped := make([]byte, 0, len(doc)+len(RunIDField)+len(value)+4)
+	stamped = append(stamped, doc[:len(doc)-1]...)
+	if len(doc) > 2 {
+		stamped = append(stamped, ',')
+	}
+	stamped = append(stamped, `"`+RunIDField+`":`...)
+	stamped = append(stamped, value...)
+	return append(stamped, '}')
+}
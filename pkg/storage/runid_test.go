@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestWithRunID(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want string
+	}{
+		{`{}`, `{"run_id":"r1"}`},
+		{`{"gid":"1"}`, `{"gid":"1","run_id":"r1"}`},
+	}
+	for _, tt := range tests {
+		if got := string(withRunID([]byte(tt.doc), "r1")); got != tt.want {
+			t.Errorf("withRunID(%s) = %s, want %s", tt.doc, got, tt.want)
+		}
+	}
+}
+
+func TestRunStamp_CSV(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewCSVStorage(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.StartRun("r1")
+	if err := s.WriteTeam(asana.Team{GID: "t1", Name: "Core"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteManifest(map[string]any{"status": "succeeded", "resources": []string{"teams"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "teams.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], ",run_id") || !strings.HasSuffix(lines[1], ",r1") {
+		t.Errorf("Expected a run_id column holding r1, got %q", data)
+	}
+}
+
+func TestRunStamp_Singer(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewSingerStorage(&buf)
+	s.StartRun("r1")
+	if err := s.WriteTeam(asana.Team{GID: "t1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var schema struct {
+		Schema struct {
+			Properties map[string]any `json:"properties"`
+		} `json:"schema"`
+	}
+	var record struct {
+		Record map[string]any `json:"record"`
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected a schema and a record, got %q", buf.String())
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &schema); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := schema.Schema.Properties["run_id"]; !ok {
+		t.Errorf("Expected run_id in the schema, got %v", schema.Schema.Properties)
+	}
+	if record.Record["run_id"] != "r1" || record.Record["gid"] != "t1" {
+		t.Errorf("Expected the record stamped with r1, got %v", record.Record)
+	}
+}
@@ -0,0 +1,245 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// SingerStorage turns the extractor into a Singer tap: it writes one JSON
+// message per line, a SCHEMA message before the first record of each stream,
+// a RECORD message per entity and a STATE message per finished run. Streams
+// are named after the resources and keyed by gid. Records written during a
+// run carry its run_id.
+type SingerStorage struct {
+	runStamp
+	mu      sync.Mutex
+	w       io.Writer
+	schemas map[string]bool
+	now     func() time.Time
+}
+
+func init() {
+	Register("singer", func(Settings) (Backend, error) {
+		return NewSingerStorage(os.Stdout), nil
+	})
+}
+
+// NewSingerStorage creates a Singer tap writing messages to w
+func NewSingerStorage(w io.Writer) *SingerStorage {
+	return &SingerStorage{w: w, schemas: make(map[string]bool), now: time.Now}
+}
+
+// singerSchema is a Singer SCHEMA message
+type singerSchema struct {
+	Type          string         `json:"type"`
+	Stream        string         `json:"stream"`
+	Schema        map[string]any `json:"schema"`
+	KeyProperties []string       `json:"key_properties"`
+}
+
+// singerRecord is a Singer RECORD message
+type singerRecord struct {
+	Type          string    `json:"type"`
+	Stream        string    `json:"stream"`
+	Record        any       `json:"record"`
+	TimeExtracted time.Time `json:"time_extracted"`
+}
+
+// singerState is a Singer STATE message
+type singerState struct {
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
+
+// WriteUser emits user on the users stream
+func (s *SingerStorage) WriteUser(user asana.User) error {
+	return s.writeRecord("users", user)
+}
+
+// WriteProject emits project on the projects stream
+func (s *SingerStorage) WriteProject(project asana.Project) error {
+	return s.writeRecord("projects", project)
+}
+
+// WriteTask emits task on the tasks stream
+func (s *SingerStorage) WriteTask(task asana.Task) error {
+	return s.writeRecord("tasks", task)
+}
+
+// WriteTeam emits team on the teams stream
+func (s *SingerStorage) WriteTeam(team asana.Team) error {
+	return s.writeRecord("teams", team)
+}
+
+// WriteUsers emits users on the users stream
+func (s *SingerStorage) WriteUsers(users []asana.User) error {
+	return s.writeRecord("users", anys(users)...)
+}
+
+// WriteProjects emits projects on the projects stream
+func (s *SingerStorage) WriteProjects(projects []asana.Project) error {
+	return s.writeRecord("projects", anys(projects)...)
+}
+
+// WriteTasks emits tasks on the tasks stream
+func (s *SingerStorage) WriteTasks(tasks []asana.Task) error {
+	return s.writeRecord("tasks", anys(tasks)...)
+}
+
+// WriteTeams emits teams on the teams stream
+func (s *SingerStorage) WriteTeams(teams []asana.Team) error {
+	return s.writeRecord("teams", anys(teams)...)
+}
+
+// WriteManifest emits a STATE message recording the finished run, so a
+// Singer target can persist how far the tap got
+func (s *SingerStorage) WriteManifest(manifest any) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	var run struct {
+		RunID      string         `json:"run_id"`
+		Status     string         `json:"status"`
+		FinishedAt time.Time      `json:"finished_at"`
+		Counts     map[string]int `json:"counts"`
+	}
+	if err := json.Unmarshal(data, &run); err != nil {
+		return fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.emit(singerState{Type: "STATE", Value: map[string]any{"last_run": run}})
+}
+
+// writeRecord emits each of vs on stream, preceded by the stream's schema
+// the first time the stream is written. A batch goes out in one write.
+func (s *SingerStorage) writeRecord(stream string, vs ...any) error {
+	if len(vs) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runID := s.runID()
+	msgs := make([]any, 0, len(vs)+1)
+	if !s.schemas[stream] {
+		schema := jsonSchema(reflect.TypeOf(vs[0]))
+		if runID != "" {
+			schema["properties"].(map[string]any)[RunIDField] = map[string]any{"type": "string"}
+		}
+		msgs = append(msgs, singerSchema{Type: "SCHEMA", Stream: stream, Schema: schema, KeyProperties: []string{"gid"}})
+	}
+	now := s.now().UTC()
+	for _, v := range vs {
+		record := v
+		if runID != "" {
+			doc, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("failed to marshal %s record: %w", stream, err)
+			}
+			record = json.RawMessage(withRunID(doc, runID))
+		}
+		msgs = append(msgs, singerRecord{Type: "RECORD", Stream: stream, Record: record, TimeExtracted: now})
+	}
+	if err := s.emit(msgs...); err != nil {
+		return err
+	}
+	s.schemas[stream] = true
+	return nil
+}
+
+// emit writes each of msgs as one line, all in a single write. It must be
+// called with mu held, so messages from concurrent writers never
+// interleave.
+func (s *SingerStorage) emit(msgs ...any) error {
+	var buf []byte
+	for _, msg := range msgs {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("failed to marshal singer message: %w", err)
+		}
+		buf = append(append(buf, data...), '\n')
+	}
+	if _, err := s.w.Write(buf); err != nil {
+		return fmt.Errorf("failed to write singer message: %w", err)
+	}
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// jsonSchema describes how t marshals to JSON. Pointers and omitempty
+// fields may be null.
+func jsonSchema(t reflect.Type) map[string]any {
+	nullable := false
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+
+	var schema map[string]any
+	switch {
+	case t == timeType:
+		schema = map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.String:
+		schema = map[string]any{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		schema = map[string]any{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		schema = map[string]any{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		schema = map[string]any{"type": "number"}
+	case t.Kind() == reflect.Slice:
+		schema = map[string]any{"type": "array", "items": jsonSchema(t.Elem())}
+		nullable = true
+	case t.Kind() == reflect.Struct:
+		props := make(map[string]any)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			prop := jsonSchema(f.Type)
+			if strings.Contains(opts, "omitempty") {
+				prop = nullableSchema(prop)
+			}
+			props[name] = prop
+		}
+		schema = map[string]any{"type": "object", "properties": props}
+	default:
+		schema = map[string]any{}
+	}
+
+	if nullable {
+		schema = nullableSchema(schema)
+	}
+	return schema
+}
+
+// nullableSchema allows null in addition to the schema's type
+func nullableSchema(schema map[string]any) map[string]any {
+	typ, ok := schema["type"].(string)
+	if !ok {
+		return schema
+	}
+	out := make(map[string]any, len(schema))
+	for k, v := range schema {
+		out[k] = v
+	}
+	out["type"] = []string{"null", typ}
+	return out
+}
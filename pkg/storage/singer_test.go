@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestSingerStorage(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewSingerStorage(&buf)
+	s.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	if err := s.WriteUser(asana.User{GID: "u1", Name: "Ada"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteUser(asana.User{GID: "u2", Name: "Bob"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteTask(asana.Task{GID: "t1"}); err != nil {
+		t.Fatal(err)
+	}
+	manifest := map[string]any{"run_id": "r1", "status": "succeeded", "counts": map[string]int{"users": 2}, "config": "secret"}
+	if err := s.WriteManifest(manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	var msgs []map[string]any
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var msg map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			t.Fatalf("Invalid message %q: %v", scanner.Text(), err)
+		}
+		msgs = append(msgs, msg)
+	}
+
+	var kinds []string
+	for _, msg := range msgs {
+		kinds = append(kinds, msg["type"].(string)+":"+str(msg["stream"]))
+	}
+	want := []string{"SCHEMA:users", "RECORD:users", "RECORD:users", "SCHEMA:tasks", "RECORD:tasks", "STATE:"}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("Messages = %v, want %v", kinds, want)
+	}
+
+	if keys := msgs[0]["key_properties"].([]any); len(keys) != 1 || keys[0] != "gid" {
+		t.Errorf("Expected gid as key property, got %v", keys)
+	}
+	if rec := msgs[1]["record"].(map[string]any); rec["gid"] != "u1" || msgs[1]["time_extracted"] != "2024-01-02T03:04:05Z" {
+		t.Errorf("Unexpected record message %v", msgs[1])
+	}
+	run := msgs[5]["value"].(map[string]any)["last_run"].(map[string]any)
+	if run["run_id"] != "r1" || run["config"] != nil {
+		t.Errorf("Expected the run without its config in the state, got %v", run)
+	}
+}
+
+// countingWriter counts the writes it receives
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestSingerStorage_WriteBatch(t *testing.T) {
+	var w countingWriter
+	s := NewSingerStorage(&w)
+
+	if err := s.WriteTasks([]asana.Task{{GID: "t1"}, {GID: "t2"}, {GID: "t3"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteTasks(nil); err != nil {
+		t.Fatal(err)
+	}
+	if w.writes != 1 {
+		t.Errorf("Expected the batch in a single write, got %d", w.writes)
+	}
+	if lines := strings.Count(w.String(), "\n"); lines != 4 {
+		t.Errorf("Expected a schema and 3 records, got %d lines", lines)
+	}
+
+	if err := s.WriteTask(asana.Task{GID: "t4"}); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(w.String(), "\n"); lines != 5 {
+		t.Errorf("Expected the schema only once, got %d lines", lines)
+	}
+}
+
+func str(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+func TestJSONSchema(t *testing.T) {
+	schema := jsonSchema(reflect.TypeOf(asana.Task{}))
+	props := schema["properties"].(map[string]any)
+
+	tests := []struct {
+		field string
+		want  any
+	}{
+		{"gid", "string"},
+		{"completed", "boolean"},
+		{"notes", []string{"null", "string"}},
+		{"completed_at", []string{"null", "string"}},
+		{"assignee", []string{"null", "object"}},
+		{"projects", []string{"null", "array"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.field, func(t *testing.T) {
+			prop := props[tc.field].(map[string]any)
+			if !reflect.DeepEqual(prop["type"], tc.want) {
+				t.Errorf("type = %v, want %v", prop["type"], tc.want)
+			}
+		})
+	}
+
+	if format := props["created_at"].(map[string]any)["format"]; format != "date-time" {
+		t.Errorf("Expected created_at to be a date-time, got %v", format)
+	}
+	assignee := props["assignee"].(map[string]any)["properties"].(map[string]any)
+	if _, ok := assignee["email"]; !ok {
+		t.Error("Expected the nested user schema")
+	}
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// LatestLink is the symlink in the output root pointing at the most
+	// recent completed snapshot
+	LatestLink = "latest"
+	// LatestMarker is a plain-text file holding the name of the most recent
+	// completed snapshot, for consumers that cannot follow symlinks
+	LatestMarker = "LATEST"
+
+	snapshotLayout = "20060102T150405.000Z"
+)
+
+// Snapshot is a JSONStorage writing into its own timestamped directory under
+// the output root. The directory is staged under a hidden name, and only
+// appears under its timestamp once Commit has published it, so readers never
+// see a partial snapshot, even without following the latest symlink.
+type Snapshot struct {
+	*JSONStorage
+	rootDir string
+	name    string
+}
+
+// NewSnapshot creates the hidden staging directory rootDir/.<timestamp>.partial/
+// and returns storage writing into it
+func NewSnapshot(rootDir string, now time.Time, opts Options) (*Snapshot, error) {
+	if err := os.MkdirAll(rootDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create base directory: %w", err)
+	}
+
+	name := now.UTC().Format(snapshotLayout)
+	if _, err := os.Lstat(filepath.Join(rootDir, name)); err == nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %s already exists", name)
+	}
+	dir := filepath.Join(rootDir, stagingName(name))
+
+	// Mkdir rather than MkdirAll so two runs never share a snapshot
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	js, err := NewJSONStorageWithOptions(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{
+		JSONStorage: js,
+		rootDir:     rootDir,
+		name:        name,
+	}, nil
+}
+
+// Dir returns the snapshot directory: the staging directory until Commit,
+// the published one after
+func (s *Snapshot) Dir() string {
+	return s.baseDir
+}
+
+// Commit publishes the snapshot by atomically renaming its staging directory
+// to its timestamp, then repointing the latest symlink and marker at it. Call
+// it only after the run has completed successfully.
+func (s *Snapshot) Commit() error {
+	if s.durable {
+		// Entity files are synced as they are written; their directories
+		// must reach the disk before the snapshot is published
+		if err := syncDir(s.baseDir); err != nil {
+			return err
+		}
+	}
+
+	dir := filepath.Join(s.rootDir, s.name)
+	if err := os.Rename(s.baseDir, dir); err != nil {
+		return fmt.Errorf("failed to publish snapshot: %w", err)
+	}
+	s.baseDir = dir
+	if s.durable {
+		if err := syncDir(s.rootDir); err != nil {
+			return err
+		}
+	}
+
+	marker := filepath.Join(s.rootDir, LatestMarker)
+	if err := writeFile(marker, []byte(s.name+"\n"), s.durable); err != nil {
+		return fmt.Errorf("failed to update latest marker: %w", err)
+	}
+
+	// Build the new link beside the old one and rename over it, so readers
+	// never observe a missing or dangling link
+	link := filepath.Join(s.rootDir, LatestLink)
+	tmpLink := link + ".tmp"
+	os.Remove(tmpLink)
+	if err := os.Symlink(s.name, tmpLink); err != nil {
+		return fmt.Errorf("failed to create latest symlink: %w", err)
+	}
+	if err := os.Rename(tmpLink, link); err != nil {
+		os.Remove(tmpLink)
+		return fmt.Errorf("failed to update latest symlink: %w", err)
+	}
+
+	if s.durable {
+		return syncDir(s.rootDir)
+	}
+	return nil
+}
+
+// stagingName is the hidden name a snapshot is written under before it is
+// published. A failed run leaves it in place for inspection.
+func stagingName(name string) string {
+	return "." + name + ".partial"
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+func TestSnapshot(t *testing.T) {
+	rootDir := t.TempDir()
+	first := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	snap, err := NewSnapshot(rootDir, first, Options{})
+	if err != nil {
+		t.Fatalf("NewSnapshot() failed: %v", err)
+	}
+	if err := snap.WriteUser(asana.User{GID: "u1"}); err != nil {
+		t.Fatalf("WriteUser() failed: %v", err)
+	}
+
+	// Nothing is published until Commit, not even the snapshot directory
+	if _, err := os.Lstat(filepath.Join(rootDir, LatestLink)); !os.IsNotExist(err) {
+		t.Fatalf("latest link should not exist before commit, got %v", err)
+	}
+	name := first.Format(snapshotLayout)
+	if _, err := os.Stat(filepath.Join(rootDir, name)); !os.IsNotExist(err) {
+		t.Fatalf("snapshot directory should not exist before commit, got %v", err)
+	}
+	if filepath.Base(snap.Dir()) != "."+name+".partial" {
+		t.Errorf("expected a hidden staging directory, got %s", snap.Dir())
+	}
+
+	if err := snap.Commit(); err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(rootDir, LatestLink, "users", "u1.json")); err != nil {
+		t.Errorf("user not reachable through latest link: %v", err)
+	}
+	if snap.Dir() != filepath.Join(rootDir, name) {
+		t.Errorf("expected Dir() to be the published directory, got %s", snap.Dir())
+	}
+	if _, err := os.Stat(filepath.Join(rootDir, "."+name+".partial")); !os.IsNotExist(err) {
+		t.Errorf("staging directory should be gone after commit, got %v", err)
+	}
+	if user, err := snap.ReadUser("u1"); err != nil || user.GID != "u1" {
+		t.Errorf("expected to read the published snapshot, got %+v, %v", user, err)
+	}
+
+	// A second snapshot repoints latest only once committed
+	second, err := NewSnapshot(rootDir, first.Add(time.Minute), Options{})
+	if err != nil {
+		t.Fatalf("NewSnapshot() failed: %v", err)
+	}
+	if target, _ := os.Readlink(filepath.Join(rootDir, LatestLink)); target != filepath.Base(snap.Dir()) {
+		t.Errorf("latest moved before commit: %s", target)
+	}
+	if err := second.Commit(); err != nil {
+		t.Fatalf("Commit() failed: %v", err)
+	}
+
+	target, _ := os.Readlink(filepath.Join(rootDir, LatestLink))
+	if target != filepath.Base(second.Dir()) {
+		t.Errorf("expected latest -> %s, got %s", filepath.Base(second.Dir()), target)
+	}
+	marker, _ := os.ReadFile(filepath.Join(rootDir, LatestMarker))
+	if strings.TrimSpace(string(marker)) != target {
+		t.Errorf("marker %q does not match link %q", marker, target)
+	}
+}
+
+func TestNewSnapshot_Collision(t *testing.T) {
+	rootDir := t.TempDir()
+	now := time.Now()
+
+	if _, err := NewSnapshot(rootDir, now, Options{}); err != nil {
+		t.Fatalf("NewSnapshot() failed: %v", err)
+	}
+	if _, err := NewSnapshot(rootDir, now, Options{}); err == nil {
+		t.Error("expected error reusing an existing snapshot directory")
+	}
+
+	// Nor does a run reuse a published one
+	later := now.Add(time.Second)
+	snap, err := NewSnapshot(rootDir, later, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := snap.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSnapshot(rootDir, later, Options{}); err == nil {
+		t.Error("expected error reusing a published snapshot directory")
+	}
+}
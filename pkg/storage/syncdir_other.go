@@ -0,0 +1,9 @@
+//go:build !unix
+
+package storage
+
+// syncDir does nothing: directories cannot be opened for syncing here, and
+// file metadata is written through on rename
+func syncDir(dir string) error {
+	return nil
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package storage
+
+import (
+	"fmt"
+	"os"
+)
+
+// syncDir flushes the entries of dir, such as a file just created or
+// renamed into it, to disk
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", dir, err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("failed to sync %s: %w", dir, err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup_Table(t *testing.T) {
+	tests := []struct {
+		name           string
+		cfg            Config
+		expectProvider bool
+	}{
+		{
+			name:           "Disabled keeps the no-op provider",
+			cfg:            Config{Enabled: false},
+			expectProvider: false,
+		},
+		{
+			name:           "Enabled installs an SDK provider",
+			cfg:            Config{Enabled: true, ServiceName: "asana-extractor-test", Version: "test"},
+			expectProvider: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Point the exporter at an unused local port; nothing is sent
+			// because no spans are recorded before shutdown.
+			t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://127.0.0.1:1")
+
+			before := otel.GetTracerProvider()
+
+			shutdown, err := Setup(context.Background(), tc.cfg)
+			if err != nil {
+				t.Fatalf("Setup() error = %v", err)
+			}
+			defer shutdown(context.Background())
+
+			changed := otel.GetTracerProvider() != before
+			if changed != tc.expectProvider {
+				t.Errorf("expected provider installed = %v, got %v", tc.expectProvider, changed)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ioanzicu/asana-extractor/pkg/asana"
+)
+
+// maxBodySize bounds an event delivery; Asana batches stay far below it
+const maxBodySize = 1 << 20
+
+// resourceTypes maps Asana resource types to extractor resource names.
+// Events on other types (stories, attachments) fall back to their parent.
+var resourceTypes = map[string]string{
+	"user":    "users",
+	"project": "projects",
+	"task":    "tasks",
+	"team":    "teams",
+}
+
+// Config holds receiver configuration
+type Config struct {
+	// Trigger runs a targeted extraction of the given resources. Calls are
+	// serialized: events arriving meanwhile are batched into the next call.
+	Trigger func(resources []string)
+	// Debounce collects events for this long before triggering, so a burst
+	// of edits causes a single extraction
+	Debounce time.Duration
+}
+
+// Receiver is an http.Handler accepting Asana webhook deliveries. It answers
+// the X-Hook-Secret handshake of the webhook being registered through
+// Register, verifies X-Hook-Signature on every delivery against its secret
+// and turns events into debounced extraction triggers.
+type Receiver struct {
+	cfg Config
+
+	mu          sync.Mutex
+	registering bool
+	secret      []byte
+	pending     map[string]struct{}
+	timer       *time.Timer
+	running     bool
+	closed      bool
+}
+
+// NewReceiver creates a new webhook receiver
+func NewReceiver(cfg Config) *Receiver {
+	return &Receiver{
+		cfg:     cfg,
+		pending: make(map[string]struct{}),
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Handshake: remember the secret and echo it back, but only while our
+	// own registration waits for it
+	if secret := req.Header.Get("X-Hook-Secret"); secret != "" {
+		r.mu.Lock()
+		registering := r.registering
+		if registering {
+			r.secret = []byte(secret)
+		}
+		r.mu.Unlock()
+		if !registering {
+			log.Println("Rejected webhook handshake: no registration in progress")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("X-Hook-Secret", secret)
+		w.WriteHeader(http.StatusOK)
+		log.Println("Webhook handshake completed")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxBodySize))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if !r.verify(req.Header.Get("X-Hook-Signature"), body) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var payload asana.EventsPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	r.enqueue(payload.Events)
+	w.WriteHeader(http.StatusOK)
+}
+
+// Register runs register, which should create the webhook targeting this
+// receiver, and accepts the handshake Asana sends meanwhile. Its secret
+// replaces any previous one; it is dropped again if register fails.
+func (r *Receiver) Register(register func() error) error {
+	r.mu.Lock()
+	r.registering = true
+	r.mu.Unlock()
+
+	err := register()
+
+	r.mu.Lock()
+	r.registering = false
+	if err != nil {
+		r.secret = nil
+	}
+	r.mu.Unlock()
+	return err
+}
+
+// Close stops any pending trigger
+func (r *Receiver) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+}
+
+// verify checks the hex HMAC-SHA256 signature of body against the secret
+// received through the handshake
+func (r *Receiver) verify(signature string, body []byte) bool {
+	got, err := hex.DecodeString(signature)
+	if err != nil || len(got) == 0 {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.secret == nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, r.secret)
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// enqueue records the resources touched by events and arms the debounce timer
+func (r *Receiver) enqueue(events []asana.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, event := range events {
+		if resource, ok := resourceFor(event); ok {
+			r.pending[resource] = struct{}{}
+		}
+	}
+
+	r.schedule()
+}
+
+// schedule arms the timer when work is pending and no trigger is in flight.
+// Callers must hold r.mu.
+func (r *Receiver) schedule() {
+	if r.closed || r.running || r.timer != nil || len(r.pending) == 0 {
+		return
+	}
+	r.timer = time.AfterFunc(r.cfg.Debounce, r.fire)
+}
+
+// fire hands the pending resources to Trigger
+func (r *Receiver) fire() {
+	r.mu.Lock()
+	r.timer = nil
+	if r.closed || len(r.pending) == 0 {
+		r.mu.Unlock()
+		return
+	}
+	resources := make([]string, 0, len(r.pending))
+	for resource := range r.pending {
+		resources = append(resources, resource)
+	}
+	r.pending = make(map[string]struct{})
+	r.running = true
+	r.mu.Unlock()
+
+	sort.Strings(resources)
+	log.Printf("Webhook events triggered extraction of %v", resources)
+	r.cfg.Trigger(resources)
+
+	r.mu.Lock()
+	r.running = false
+	r.schedule()
+	r.mu.Unlock()
+}
+
+// resourceFor maps an event to the extractor resource to refresh
+func resourceFor(event asana.Event) (string, bool) {
+	if resource, ok := resourceTypes[event.Resource.ResourceType]; ok {
+		return resource, true
+	}
+	if event.Parent != nil {
+		resource, ok := resourceTypes[event.Parent.ResourceType]
+		return resource, ok
+	}
+	return "", false
+}
@@ -0,0 +1,193 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func post(r *Receiver, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+// handshake registers r, answering the handshake with secret meanwhile
+func handshake(t *testing.T, r *Receiver, secret string) {
+	t.Helper()
+	err := r.Register(func() error {
+		rec := post(r, "", map[string]string{"X-Hook-Secret": secret})
+		if rec.Code != http.StatusOK || rec.Header().Get("X-Hook-Secret") != secret {
+			t.Fatalf("handshake failed: %d %v", rec.Code, rec.Header())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Register() failed: %v", err)
+	}
+}
+
+func TestReceiver_Table(t *testing.T) {
+	const secret = "s3cret"
+	events := `{"events":[
+		{"action":"changed","resource":{"gid":"1","resource_type":"task"}},
+		{"action":"added","resource":{"gid":"2","resource_type":"story"},"parent":{"gid":"3","resource_type":"project"}},
+		{"action":"changed","resource":{"gid":"4","resource_type":"attachment"}}
+	]}`
+
+	tests := []struct {
+		name            string
+		handshake       bool
+		unsolicited     bool
+		signature       string
+		expectStatus    int
+		expectResources []string
+	}{
+		{
+			name:            "Signed delivery triggers extraction",
+			handshake:       true,
+			signature:       sign(secret, events),
+			expectStatus:    http.StatusOK,
+			expectResources: []string{"projects", "tasks"},
+		},
+		{
+			name:         "Bad signature is rejected",
+			handshake:    true,
+			signature:    sign("other", events),
+			expectStatus: http.StatusUnauthorized,
+		},
+		{
+			name:         "Delivery before handshake is rejected",
+			signature:    sign(secret, events),
+			expectStatus: http.StatusUnauthorized,
+		},
+		{
+			name:         "Handshake outside registration is ignored",
+			unsolicited:  true,
+			signature:    sign(secret, events),
+			expectStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			triggered := make(chan []string, 1)
+			r := NewReceiver(Config{
+				Debounce: time.Millisecond,
+				Trigger:  func(resources []string) { triggered <- resources },
+			})
+			defer r.Close()
+
+			if tt.handshake {
+				handshake(t, r, secret)
+			}
+			if tt.unsolicited {
+				rec := post(r, "", map[string]string{"X-Hook-Secret": secret})
+				if rec.Code != http.StatusForbidden || rec.Header().Get("X-Hook-Secret") != "" {
+					t.Fatalf("expected unsolicited handshake to be rejected, got %d %v", rec.Code, rec.Header())
+				}
+			}
+
+			rec := post(r, events, map[string]string{"X-Hook-Signature": tt.signature})
+			if rec.Code != tt.expectStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectStatus, rec.Code)
+			}
+
+			select {
+			case resources := <-triggered:
+				if !reflect.DeepEqual(resources, tt.expectResources) {
+					t.Errorf("expected trigger for %v, got %v", tt.expectResources, resources)
+				}
+			case <-time.After(200 * time.Millisecond):
+				if tt.expectResources != nil {
+					t.Error("expected extraction to be triggered")
+				}
+			}
+		})
+	}
+}
+
+func TestReceiver_BatchesWhileRunning(t *testing.T) {
+	const secret = "s3cret"
+	release := make(chan struct{})
+	calls := make(chan []string, 4)
+
+	r := NewReceiver(Config{
+		Debounce: time.Millisecond,
+		Trigger: func(resources []string) {
+			calls <- resources
+			<-release
+		},
+	})
+	defer r.Close()
+	handshake(t, r, secret)
+
+	deliver := func(resourceType string) {
+		body := `{"events":[{"action":"changed","resource":{"gid":"1","resource_type":"` + resourceType + `"}}]}`
+		post(r, body, map[string]string{"X-Hook-Signature": sign(secret, body)})
+	}
+
+	deliver("task")
+	if got := <-calls; !reflect.DeepEqual(got, []string{"tasks"}) {
+		t.Fatalf("unexpected first trigger %v", got)
+	}
+
+	// Events arriving during a run are held until it finishes
+	deliver("user")
+	deliver("team")
+	select {
+	case got := <-calls:
+		t.Fatalf("trigger overlapped a running extraction: %v", got)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	if got := <-calls; !reflect.DeepEqual(got, []string{"teams", "users"}) {
+		t.Errorf("expected batched trigger, got %v", got)
+	}
+}
+
+func TestReceiver_Register(t *testing.T) {
+	r := NewReceiver(Config{Debounce: time.Hour, Trigger: func([]string) {}})
+	defer r.Close()
+	body := `{"events":[]}`
+
+	// A later registration's secret replaces the earlier one
+	handshake(t, r, "first")
+	handshake(t, r, "second")
+	if rec := post(r, body, map[string]string{"X-Hook-Signature": sign("first", body)}); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected the replaced secret to be rejected, got %d", rec.Code)
+	}
+	if rec := post(r, body, map[string]string{"X-Hook-Signature": sign("second", body)}); rec.Code != http.StatusOK {
+		t.Errorf("expected the current secret to be accepted, got %d", rec.Code)
+	}
+
+	// A failed registration drops the secret it received
+	err := r.Register(func() error {
+		post(r, "", map[string]string{"X-Hook-Secret": "third"})
+		return errors.New("registration failed")
+	})
+	if err == nil {
+		t.Fatal("expected Register() to return the registration error")
+	}
+	if rec := post(r, body, map[string]string{"X-Hook-Signature": sign("third", body)}); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected the failed registration's secret to be rejected, got %d", rec.Code)
+	}
+}